@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"service-boilerplate/internal/app"
+	"service-boilerplate/internal/audit"
+	"service-boilerplate/internal/i18n"
+)
+
+// newAuditCmd создает команду audit
+func newAuditCmd(opts *rootOptions) *cobra.Command {
+	var count int
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Show the operator actions audit trail",
+		Long: "Show the latest operator actions (admin API, gRPC and CLI service commands) " +
+			"from the audit log: who did what, when and with which result.\n\n" +
+			"The log is read from disk, so it is available while the service is stopped. " +
+			"Remote instances expose the same entries in GET /audit.",
+		Example: `  service-boilerplate audit -n 50
+  service-boilerplate audit --json`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, _, err := loadConfig(opts)
+			if err != nil {
+				return err
+			}
+			entries, err := audit.New(app.AuditPath(cfg)).Recent(count)
+			if err != nil {
+				return err
+			}
+
+			if opts.json {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(entries)
+			}
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, i18n.T(i18n.AuditHeader))
+			for _, e := range entries {
+				target := e.Target
+				if target == "" {
+					target = "-"
+				}
+				result := e.Result
+				if e.Error != "" {
+					result += ": " + e.Error
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", formatTime(&e.Time), e.Actor, e.Source, e.Action, target, result)
+			}
+			return tw.Flush()
+		},
+	}
+
+	cmd.Flags().IntVarP(&count, "count", "n", 20, "number of latest entries (0 for all)")
+	return cmd
+}
+
+// recordAudit записывает действие команды CLI над сервисом в журнал
+// действий. Ошибка записи только логируется: действие уже выполнено
+func recordAudit(env *environment, action string, err error) {
+	entry := audit.Entry{
+		Actor:  audit.CurrentActor(),
+		Source: audit.SourceCLI,
+		Action: action,
+		Target: env.cfg.Service.Name,
+		Result: audit.Result(err),
+		Error:  audit.ErrorText(err),
+	}
+	if err := audit.New(app.AuditPath(env.cfg)).Record(entry); err != nil {
+		env.log.Warn("Failed to write audit record", map[string]interface{}{
+			"action": action,
+			"error":  err.Error(),
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"service-boilerplate/internal/app"
+	"service-boilerplate/internal/apperr"
+	"service-boilerplate/internal/config"
+	"service-boilerplate/internal/i18n"
+	"service-boilerplate/internal/platform"
+)
+
+// Статусы шагов bootstrap и uninstall --purge
+const (
+	stepOK      = "ok"
+	stepCreated = "created"
+	stepRemoved = "removed"
+	stepSkipped = "skipped"
+	stepWarning = "warning"
+	stepFailed  = "failed"
+)
+
+// envStep результат одного шага подготовки или очистки окружения
+type envStep struct {
+	Step   string `json:"step"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+
+	err error
+}
+
+// bootstrapOptions флаги команды bootstrap
+type bootstrapOptions struct {
+	unitDir string
+	noUnit  bool
+}
+
+// newBootstrapCmd создает команду bootstrap
+func newBootstrapCmd(opts *rootOptions) *cobra.Command {
+	var bopts bootstrapOptions
+
+	cmd := &cobra.Command{
+		Use:   "bootstrap",
+		Short: "Prepare the environment for the service",
+		Long: `Prepare the environment for the service:
+
+  - write a default config if it does not exist
+  - create the log directory and verify it is writable
+  - register the Windows event source / generate the systemd unit
+
+Steps are idempotent: existing files are left untouched.`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			steps := bootstrap(opts, bopts)
+			if err := writeSteps(cmd.OutOrStdout(), steps, opts.json); err != nil {
+				return err
+			}
+			return stepsError(steps)
+		},
+	}
+
+	cmd.Flags().StringVar(&bopts.unitDir, "unit-dir", platform.DefaultUnitDir, "directory for the generated systemd unit (Linux only)")
+	cmd.Flags().BoolVar(&bopts.noUnit, "no-unit", false, "skip event source registration / systemd unit generation")
+	return cmd
+}
+
+// bootstrap выполняет шаги подготовки окружения. Шаги после неудачного
+// выполняются, если не зависят от него
+func bootstrap(opts *rootOptions, bopts bootstrapOptions) []envStep {
+	var steps []envStep
+
+	execPath, err := os.Executable()
+	if err != nil {
+		return append(steps, failedStep("executable", err))
+	}
+
+	configStep := ensureConfig(opts)
+	steps = append(steps, configStep)
+	if configStep.err != nil {
+		return steps
+	}
+
+	cfg, configPath, err := loadConfig(opts)
+	if err != nil {
+		return append(steps, failedStep("config", err))
+	}
+	if err := cfg.Validate(); err != nil {
+		return append(steps, failedStep("config", &apperr.ConfigError{Path: configPath, Err: err}))
+	}
+
+	steps = append(steps, ensureDir("log_dir", cfg.Service.LogDir))
+	steps = append(steps, ensureDir("state_dir", cfg.Service.StateDir))
+
+	if bopts.noUnit {
+		return append(steps, envStep{Step: serviceManagerStep, Status: stepSkipped, Detail: "--no-unit"})
+	}
+	return append(steps, bootstrapServiceManager(cfg, execPath, configPath, bopts)...)
+}
+
+// ensureConfig записывает конфиг по умолчанию, если файла нет
+func ensureConfig(opts *rootOptions) envStep {
+	configPath, err := resolveConfigPath(opts)
+	if err != nil {
+		return failedStep("config", err)
+	}
+
+	if _, err := os.Stat(configPath); err == nil {
+		return envStep{Step: "config", Status: stepOK, Detail: configPath}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return failedStep("config", err)
+	}
+
+	cfg := config.Default()
+	cfg.Service.Name = app.ServiceName
+	if opts.name != "" {
+		cfg.Service.Name = opts.name
+	}
+	cfg.Service.DisplayName = app.ServiceDisplayName
+	cfg.Service.Description = app.ServiceDescription
+	if err := config.Save(configPath, cfg); err != nil {
+		return failedStep("config", err)
+	}
+	return envStep{Step: "config", Status: stepCreated, Detail: configPath}
+}
+
+// ensureDir создает директорию сервиса и проверяет права на запись
+func ensureDir(step, path string) envStep {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return failedStep(step, err)
+	}
+
+	status := stepOK
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		status = stepCreated
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return failedStep(step, err)
+	}
+
+	probe, err := os.CreateTemp(dir, ".bootstrap-*")
+	if err != nil {
+		return failedStep(step, fmt.Errorf("directory %s is not writable: %w", dir, err))
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	return envStep{Step: step, Status: status, Detail: dir}
+}
+
+// writeSteps выводит результаты шагов таблицей или в JSON
+func writeSteps(w io.Writer, steps []envStep, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(steps)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, s := range steps {
+		fmt.Fprintf(tw, "[%s]\t%s\t%s\n", stepStatusText(s.Status), s.Step, s.Detail)
+	}
+	return tw.Flush()
+}
+
+// stepStatusText возвращает статус шага на языке вывода
+func stepStatusText(status string) string {
+	switch status {
+	case stepOK:
+		return i18n.T(i18n.StepOK)
+	case stepCreated:
+		return i18n.T(i18n.StepCreated)
+	case stepRemoved:
+		return i18n.T(i18n.StepRemoved)
+	case stepSkipped:
+		return i18n.T(i18n.StepSkipped)
+	case stepWarning:
+		return i18n.T(i18n.StepWarning)
+	case stepFailed:
+		return i18n.T(i18n.StepFailed)
+	default:
+		return status
+	}
+}
+
+// stepsError объединяет ошибки неудачных шагов
+func stepsError(steps []envStep) error {
+	var errs []error
+	for _, s := range steps {
+		if s.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Step, s.err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// failedStep формирует результат неудачного шага
+func failedStep(step string, err error) envStep {
+	return envStep{Step: step, Status: stepFailed, Detail: err.Error(), err: err}
+}
@@ -0,0 +1,46 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"service-boilerplate/internal/config"
+	"service-boilerplate/internal/platform"
+)
+
+// serviceManagerStep имя шага регистрации в менеджере сервисов
+const serviceManagerStep = "systemd_unit"
+
+// bootstrapServiceManager генерирует systemd unit и перечитывает конфигурацию systemd
+func bootstrapServiceManager(cfg *config.Config, execPath, configPath string, bopts bootstrapOptions) []envStep {
+	unitPath := platform.UnitPath(bopts.unitDir, cfg.Service.Name)
+	unit := []byte(platform.UnitFile(serviceRegistration(cfg, execPath, configPath)))
+
+	existing, err := os.ReadFile(unitPath)
+	switch {
+	case err == nil && bytes.Equal(existing, unit):
+		return []envStep{{Step: serviceManagerStep, Status: stepOK, Detail: unitPath}}
+	case err == nil:
+		// Не перезаписываем unit, измененный вручную
+		return []envStep{{Step: serviceManagerStep, Status: stepWarning, Detail: unitPath + " differs from generated unit, left untouched (use reconfigure to overwrite)"}}
+	case !errors.Is(err, os.ErrNotExist):
+		return []envStep{failedStep(serviceManagerStep, err)}
+	}
+
+	if err := os.WriteFile(unitPath, unit, 0644); err != nil {
+		return []envStep{failedStep(serviceManagerStep, fmt.Errorf("failed to write unit file: %w", err))}
+	}
+	steps := []envStep{{Step: serviceManagerStep, Status: stepCreated, Detail: unitPath}}
+
+	if err := platform.DaemonReload(); err != nil {
+		steps = append(steps, envStep{Step: "systemd_reload", Status: stepWarning, Detail: err.Error()})
+	} else {
+		steps = append(steps, envStep{Step: "systemd_reload", Status: stepOK})
+	}
+	return steps
+}
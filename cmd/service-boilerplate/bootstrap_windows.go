@@ -0,0 +1,32 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"fmt"
+
+	"service-boilerplate/internal/config"
+	"service-boilerplate/internal/logger"
+)
+
+// serviceManagerStep имя шага регистрации в менеджере сервисов
+const serviceManagerStep = "event_source"
+
+// bootstrapServiceManager регистрирует источник событий Windows Event Log
+func bootstrapServiceManager(cfg *config.Config, execPath, configPath string, bopts bootstrapOptions) []envStep {
+	name := cfg.Service.Name
+
+	registered, err := logger.EventSourceRegistered(name)
+	if err != nil {
+		return []envStep{failedStep(serviceManagerStep, err)}
+	}
+	if registered {
+		return []envStep{{Step: serviceManagerStep, Status: stepOK, Detail: name}}
+	}
+
+	if err := logger.RegisterEventSource(name); err != nil {
+		return []envStep{failedStep(serviceManagerStep, fmt.Errorf("failed to register event source: %w", err))}
+	}
+	return []envStep{{Step: serviceManagerStep, Status: stepCreated, Detail: name}}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// newCompletionCmd создает команду completion
+func newCompletionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion <bash|zsh|fish|powershell>",
+		Short: "Generate a shell completion script",
+		Long: `Generate a shell completion script.
+
+  bash:       source <(service-boilerplate completion bash)
+  zsh:        service-boilerplate completion zsh > "${fpath[1]}/_service-boilerplate"
+  fish:       service-boilerplate completion fish > ~/.config/fish/completions/service-boilerplate.fish
+  powershell: service-boilerplate completion powershell | Out-String | Invoke-Expression
+
+Timer names for "trigger" are completed dynamically from the running instance.`,
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		Args:                  usageArgs(cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs)),
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			out := cmd.OutOrStdout()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(out, true)
+			case "zsh":
+				return root.GenZshCompletion(out)
+			case "fish":
+				return root.GenFishCompletion(out, true)
+			case "powershell":
+				return root.GenPowerShellCompletionWithDesc(out)
+			}
+			return withCode(exitUsage, fmt.Errorf("unsupported shell %q", args[0]))
+		},
+	}
+}
+
+// completeTimerNames дополняет имена таймеров запущенного экземпляра
+func completeTimerNames(opts *rootOptions) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		client, err := newAdminClient(opts, 2*time.Second)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		timers, err := client.ListTimers(cmd.Context())
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		names := make([]string, 0, len(timers))
+		for _, t := range timers {
+			if strings.HasPrefix(t.Name, toComplete) {
+				names = append(names, t.Name+"\tevery "+t.Interval)
+			}
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"service-boilerplate/internal/app"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/scheduler"
+)
+
+// registerComponents добавляет таймеры и задачи приложения. Команда
+// scaffold заменяет этот файл в новом проекте примером таймера и задачи
+func registerComponents(application *app.App, log *logger.Logger) {
+	registerTimers(application.GetScheduler(), log)
+}
+
+// registerTimers добавляет таймеры приложения. Принимает scheduler.Runner,
+// чтобы регистрацию можно было проверить с mocks.MockScheduler
+func registerTimers(sched scheduler.Runner, log *logger.Logger) {
+	// Добавляем таймеры согласно ТЗ
+	// Таймер 1: каждые 5 секунд
+	sched.AddTimer("every_5s", 5*time.Second, func(ctx context.Context) {
+		log.Info("Timer executed: every_5s", map[string]interface{}{
+			"timer": "every_5s",
+		})
+	})
+
+	// Таймер 2: каждые 30 секунд
+	sched.AddTimer("every_30s", 30*time.Second, func(ctx context.Context) {
+		log.Info("Timer executed: every_30s", map[string]interface{}{
+			"timer": "every_30s",
+		})
+	})
+
+	// Таймер 3: каждые 15 минут
+	sched.AddTimer("every_15m", 15*time.Minute, func(ctx context.Context) {
+		log.Info("Timer executed: every_15m", map[string]interface{}{
+			"timer": "every_15m",
+		})
+	})
+
+	// Таймер 4: каждые 3 часа
+	sched.AddTimer("every_3h", 3*time.Hour, func(ctx context.Context) {
+		log.Info("Timer executed: every_3h", map[string]interface{}{
+			"timer": "every_3h",
+		})
+	})
+}
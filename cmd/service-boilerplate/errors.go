@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"service-boilerplate/internal/apperr"
+	"service-boilerplate/internal/i18n"
+	"service-boilerplate/internal/platform"
+)
+
+// Коды выхода CLI
+const (
+	exitOK             = 0
+	exitError          = 1
+	exitUsage          = 2
+	exitConfig         = 3
+	exitServiceManager = 4
+	exitAlreadyRunning = 5
+	exitPermission     = 6
+	exitUnavailable    = 7
+	exitDependency     = 8
+)
+
+// exitKinds машиночитаемые названия кодов выхода
+var exitKinds = map[int]string{
+	exitOK:             "ok",
+	exitError:          "error",
+	exitUsage:          "usage",
+	exitConfig:         "config",
+	exitServiceManager: "service_manager",
+	exitAlreadyRunning: "already_running",
+	exitPermission:     "permission_denied",
+	exitUnavailable:    "unavailable",
+	exitDependency:     "dependency_unavailable",
+}
+
+// cliError ошибка с кодом выхода
+type cliError struct {
+	code int
+	err  error
+}
+
+// Error реализует интерфейс error
+func (e *cliError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap возвращает исходную ошибку
+func (e *cliError) Unwrap() error {
+	return e.err
+}
+
+// withCode связывает ошибку с кодом выхода
+func withCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &cliError{code: code, err: err}
+}
+
+// usageArgs оборачивает валидатор аргументов cobra, помечая ошибки как usage
+func usageArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		return withCode(exitUsage, validate(cmd, args))
+	}
+}
+
+// exitCode определяет код выхода для ошибки. Специфичные причины
+// (нет прав, уже запущен, недоступна зависимость, ошибка конфигурации)
+// имеют приоритет над кодом команды
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	var (
+		permErr *apperr.PermissionError
+		portErr *apperr.PortInUseError
+		depErr  *apperr.DependencyUnavailableError
+		cfgErr  *apperr.ConfigError
+	)
+	if errors.As(err, &permErr) || errors.Is(err, os.ErrPermission) {
+		return exitPermission
+	}
+	if errors.As(err, &portErr) || errors.Is(err, platform.ErrAlreadyRunning) || apperr.IsAddrInUse(err) {
+		return exitAlreadyRunning
+	}
+	if errors.As(err, &depErr) {
+		return exitDependency
+	}
+	if errors.As(err, &cfgErr) {
+		return exitConfig
+	}
+	var ce *cliError
+	if errors.As(err, &ce) {
+		return ce.code
+	}
+	return exitError
+}
+
+// errorHint возвращает подсказку оператору для типизированной ошибки
+// запуска или пустую строку
+func errorHint(err error) string {
+	var (
+		permErr *apperr.PermissionError
+		portErr *apperr.PortInUseError
+		depErr  *apperr.DependencyUnavailableError
+		cfgErr  *apperr.ConfigError
+	)
+	switch {
+	case errors.As(err, &permErr):
+		return i18n.T(i18n.HintPermission, permErr.Resource)
+	case errors.As(err, &portErr):
+		return i18n.T(i18n.HintPortInUse, portErr.Component, portErr.Addr)
+	case errors.As(err, &depErr):
+		return i18n.T(i18n.HintDependency, depErr.Task, depErr.Dependency)
+	case errors.As(err, &cfgErr):
+		return i18n.T(i18n.HintConfig)
+	}
+	return ""
+}
+
+// errorReport машиночитаемое описание ошибки
+type errorReport struct {
+	Error string `json:"error"`
+	Code  int    `json:"code"`
+	Kind  string `json:"kind"`
+	Hint  string `json:"hint,omitempty"`
+}
+
+// reportError выводит ошибку с подсказкой в w и возвращает код выхода
+func reportError(w io.Writer, err error, asJSON bool) int {
+	code := exitCode(err)
+	hint := errorHint(err)
+	if asJSON {
+		json.NewEncoder(w).Encode(errorReport{
+			Error: err.Error(),
+			Code:  code,
+			Kind:  exitKinds[code],
+			Hint:  hint,
+		})
+		return code
+	}
+	fmt.Fprintln(w, i18n.T(i18n.CLIError, err))
+	if hint != "" {
+		fmt.Fprintln(w, hint)
+	}
+	return code
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// newHealthcheckCmd создает команду healthcheck для Docker/Kubernetes проб
+func newHealthcheckCmd(opts *rootOptions) *cobra.Command {
+	var (
+		url     string
+		path    string
+		timeout time.Duration
+		quiet   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "healthcheck",
+		Short: "Probe the local health endpoint and exit 0 (healthy) or 1 (unhealthy)",
+		Example: `  # Dockerfile
+  HEALTHCHECK CMD ["/app/service-boilerplate", "healthcheck"]`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if url == "" {
+				cfg, _, err := loadConfig(opts)
+				if err != nil {
+					return err
+				}
+				if !cfg.Metrics.Enabled {
+					return withCode(exitConfig, fmt.Errorf("metrics server is disabled in config, use --url"))
+				}
+				url = "http://" + localAddr(cfg.Metrics.Listen) + path
+			}
+
+			client := &http.Client{Timeout: timeout}
+			resp, err := client.Get(url)
+			if err != nil {
+				return fmt.Errorf("health check failed: %w", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("health check failed: %s: %s", resp.Status, body)
+			}
+			if !quiet {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\n", body)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&url, "url", "", "full URL to probe (default: derived from metrics.listen)")
+	cmd.Flags().StringVar(&path, "path", "/health", "endpoint path when URL is derived from config")
+	cmd.Flags().DurationVar(&timeout, "timeout", 3*time.Second, "request timeout")
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "do not print the response body")
+	return cmd
+}
+
+// localAddr преобразует адрес прослушивания в адрес для локального подключения
+func localAddr(listen string) string {
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return listen
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"service-boilerplate/internal/i18n"
+)
+
+// newHistoryCmd создает команду history
+func newHistoryCmd(opts *rootOptions) *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "history <timer-name>",
+		Short: "Show recent runs of a timer of the running service",
+		Long: "Show the last runs of a timer (newest first) with start time, duration,\n" +
+			"outcome and error text. The number of kept runs is scheduler.history_size;\n" +
+			"with scheduler.persist_history the history survives restarts.",
+		Example:           `  service-boilerplate history nightly_report`,
+		Args:              usageArgs(cobra.ExactArgs(1)),
+		ValidArgsFunction: completeTimerNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newAdminClient(opts, timeout)
+			if err != nil {
+				return err
+			}
+
+			runs, err := client.TimerHistory(cmd.Context(), args[0])
+			if err != nil {
+				return withCode(exitUnavailable, err)
+			}
+
+			if opts.json {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(runs)
+			}
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, i18n.T(i18n.HistoryHeader))
+			for _, r := range runs {
+				errText := r.Error
+				if errText == "" {
+					errText = "-"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", formatTime(&r.Start), r.Duration, r.Outcome, errText)
+			}
+			return tw.Flush()
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "request timeout")
+	return cmd
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"service-boilerplate/internal/i18n"
+)
+
+// newListTimersCmd создает команду list-timers
+func newListTimersCmd(opts *rootOptions) *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "list-timers",
+		Short: "List timers of the running service",
+		Args:  usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newAdminClient(opts, timeout)
+			if err != nil {
+				return err
+			}
+
+			timers, err := client.ListTimers(cmd.Context())
+			if err != nil {
+				return withCode(exitUnavailable, err)
+			}
+
+			if opts.json {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(timers)
+			}
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, i18n.T(i18n.TimersHeader))
+			for _, t := range timers {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n",
+					t.Name, t.Interval, formatTime(t.LastRun), formatTime(t.NextRun), t.PanicCount, t.State)
+			}
+			return tw.Flush()
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "request timeout")
+	return cmd
+}
+
+// formatTime форматирует время для табличного вывода
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"service-boilerplate/internal/admin"
+)
+
+// newLogLevelCmd создает команду loglevel
+func newLogLevelCmd(opts *rootOptions) *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "loglevel [level]",
+		Short: "Show or change the log level of the running service",
+		Long: "Show the log level of the running service or change it without a restart.\n" +
+			"The change is not persisted to the config.",
+		Example: `  service-boilerplate loglevel
+  service-boilerplate loglevel debug`,
+		Args:      usageArgs(cobra.MaximumNArgs(1)),
+		ValidArgs: []string{"debug", "info", "warn", "error"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newAdminClient(opts, timeout)
+			if err != nil {
+				return err
+			}
+
+			if len(args) > 0 {
+				err = client.SetLogLevel(cmd.Context(), args[0])
+			}
+			var level string
+			if err == nil {
+				level, err = client.LogLevel(cmd.Context())
+			}
+			if err != nil {
+				return withCode(exitUnavailable, err)
+			}
+
+			if opts.json {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(admin.LogLevel{Level: level})
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), level)
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "request timeout")
+	return cmd
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/logview"
+)
+
+// newLogsCmd создает команду logs
+func newLogsCmd(opts *rootOptions) *cobra.Command {
+	var (
+		follow bool
+		level  string
+		since  time.Duration
+		file   string
+		raw    bool
+		recent bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Pretty-print and follow the service log file",
+		Long: "Pretty-print and follow the service log file.\n\n" +
+			"--recent reads the in-memory buffer of the running instance through " +
+			"the admin API instead, for hosts where the log file is not accessible.",
+		Example: `  service-boilerplate logs --level error --since 1h
+  service-boilerplate logs -f
+  service-boilerplate logs --recent --level error`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			minLevel, err := logger.ParseLevel(level)
+			if err != nil {
+				return err
+			}
+
+			filter := logview.Filter{MinLevel: minLevel}
+			if since > 0 {
+				filter.Since = time.Now().Add(-since)
+			}
+
+			out := cmd.OutOrStdout()
+			print := func(e logger.LogEntry) {
+				if raw {
+					data, _ := json.Marshal(e)
+					fmt.Fprintln(out, string(data))
+					return
+				}
+				fmt.Fprintln(out, logview.Format(e))
+			}
+
+			if recent {
+				if follow || file != "" {
+					return withCode(exitUsage, fmt.Errorf("--recent cannot be combined with --follow or --file"))
+				}
+				client, err := newAdminClient(opts, 10*time.Second)
+				if err != nil {
+					return err
+				}
+				entries, err := client.RecentLogs(cmd.Context(), minLevel.String(), 0)
+				if err != nil {
+					return withCode(exitUnavailable, err)
+				}
+				for _, e := range entries {
+					if filter.Match(e) {
+						print(e)
+					}
+				}
+				return nil
+			}
+
+			if file == "" {
+				cfg, _, err := loadConfig(opts)
+				if err != nil {
+					return err
+				}
+				if cfg.Service.LogEncoding != logger.JSONEncoding {
+					return withCode(exitUsage, fmt.Errorf("log file is written with service.log_encoding %q, only json can be read; use --recent", cfg.Service.LogEncoding))
+				}
+				file = logger.FilePath(cfg.Service.LogDir, cfg.Service.Name)
+			}
+
+			if follow {
+				ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+				defer stop()
+				return logview.Follow(ctx, file, filter, 250*time.Millisecond, print)
+			}
+
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			// Большой файл не читается с начала: индекс времени рядом с логом
+			// указывает смещение, с которого начинаются записи за --since
+			if !filter.Since.IsZero() {
+				if _, err := logview.SeekSince(f, filter.Since); err != nil {
+					return err
+				}
+			}
+			return logview.Read(f, filter, print)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "keep reading as new entries are written")
+	cmd.Flags().StringVar(&level, "level", "debug", "minimum level to show (debug, info, warn, error, fatal)")
+	cmd.Flags().DurationVar(&since, "since", 0, "show entries newer than this duration (e.g. 30m, 1h)")
+	cmd.Flags().StringVar(&file, "file", "", "log file path (default: derived from config)")
+	cmd.Flags().BoolVar(&raw, "raw", false, "print raw JSON lines instead of pretty output")
+	cmd.Flags().BoolVar(&recent, "recent", false, "read recent entries from the running instance via the admin API")
+	return cmd
+}
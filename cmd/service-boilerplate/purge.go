@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"service-boilerplate/internal/app"
+	"service-boilerplate/internal/config"
+	"service-boilerplate/internal/logger"
+)
+
+// purgeEnvironment удаляет файлы, оставшиеся после сервиса: лог и его
+// индекс, журнал аудита, отчеты о падении, профили, хранилище состояния,
+// манифест и директорию временных артефактов, сгенерированный конфиг
+// и опустевшие директории. Ошибка одного шага не прерывает остальные.
+// Блокировка выборов и снимок для следующего лидера лежат на общем диске
+// экземпляров и не удаляются, как и ключ шифрования секретов
+func purgeEnvironment(cfg *config.Config, configPath string) []envStep {
+	var steps []envStep
+
+	logDir, err := filepath.Abs(cfg.Service.LogDir)
+	if err != nil {
+		return append(steps, failedStep("log_file", err))
+	}
+	logFile := logger.FilePath(logDir, cfg.Service.Name)
+	steps = append(steps, removeFile("log_file", logFile))
+	steps = append(steps, removeFile("log_index", logger.IndexPath(logFile)))
+	steps = append(steps, removeFile("audit_log", app.AuditPath(cfg)))
+	steps = append(steps, removeDir("crash_dir", crashDir(cfg)))
+	steps = append(steps, removeDir("profile_dir", profileDir(cfg)))
+	steps = append(steps, removeFile("store", cfg.Store.Path))
+	steps = append(steps, removeFile("cleanup_manifest", app.CleanupManifestPath(cfg)))
+	steps = append(steps, removeDir("temp_dir", filepath.Join(cfg.Service.CacheDir, "tmp")))
+
+	generated, err := config.IsGenerated(configPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		steps = append(steps, envStep{Step: "config", Status: stepSkipped, Detail: configPath + " does not exist"})
+	case err != nil:
+		steps = append(steps, failedStep("config", err))
+	case !generated:
+		steps = append(steps, envStep{Step: "config", Status: stepSkipped, Detail: configPath + " was not generated, left untouched"})
+	default:
+		steps = append(steps, removeFile("config", configPath))
+		steps = append(steps, removeEmptyDir("config_dir", filepath.Dir(configPath)))
+	}
+
+	steps = append(steps, removeEmptyDir("log_dir", logDir))
+	for _, dir := range []struct{ step, path string }{
+		{"state_dir", cfg.Service.StateDir},
+		{"cache_dir", cfg.Service.CacheDir},
+		{"data_dir", cfg.Service.DataDir},
+	} {
+		if dir.path != "" && dir.path != logDir {
+			steps = append(steps, removeEmptyDir(dir.step, dir.path))
+		}
+	}
+	return steps
+}
+
+// profileDir возвращает директорию pprof профилей: profiling.dir
+// или <log_dir>/profiles
+func profileDir(cfg *config.Config) string {
+	if cfg.Profiling.Dir != "" {
+		return cfg.Profiling.Dir
+	}
+	return filepath.Join(cfg.Service.LogDir, "profiles")
+}
+
+// removeDir удаляет директорию, принадлежащую сервису, вместе с содержимым;
+// отсутствующая директория не считается ошибкой
+func removeDir(step, dir string) envStep {
+	if _, err := os.Stat(dir); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return envStep{Step: step, Status: stepSkipped, Detail: dir + " does not exist"}
+		}
+		return failedStep(step, err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return failedStep(step, fmt.Errorf("failed to remove %s: %w", dir, err))
+	}
+	return envStep{Step: step, Status: stepRemoved, Detail: dir}
+}
+
+// removeFile удаляет файл; отсутствующий файл не считается ошибкой
+func removeFile(step, path string) envStep {
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return envStep{Step: step, Status: stepSkipped, Detail: path + " does not exist"}
+		}
+		return failedStep(step, fmt.Errorf("failed to remove %s: %w", path, err))
+	}
+	return envStep{Step: step, Status: stepRemoved, Detail: path}
+}
+
+// removeEmptyDir удаляет директорию, только если она пуста. Директория
+// может быть общей для нескольких экземпляров, поэтому содержимое не трогаем
+func removeEmptyDir(step, dir string) envStep {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return envStep{Step: step, Status: stepSkipped, Detail: dir + " does not exist"}
+		}
+		return failedStep(step, err)
+	}
+	if len(entries) > 0 {
+		return envStep{Step: step, Status: stepSkipped, Detail: dir + " is not empty"}
+	}
+	return removeFile(step, dir)
+}
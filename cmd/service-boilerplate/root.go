@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"service-boilerplate/internal/app"
+	"service-boilerplate/internal/apperr"
+	"service-boilerplate/internal/config"
+	"service-boilerplate/internal/i18n"
+	"service-boilerplate/internal/logger"
+)
+
+// rootOptions содержит глобальные флаги CLI
+type rootOptions struct {
+	configPath string
+	name       string
+	json       bool
+	// readOnly включает service.read_only
+	readOnly bool
+	// k8s включает kubernetes.enabled
+	k8s bool
+	// logLevel переопределяет service.log_level (флаги run)
+	logLevel string
+}
+
+// environment содержит загруженные конфигурацию и логгер
+type environment struct {
+	configPath string
+	cfg        *config.Config
+	log        *logger.Logger
+	// configTime и loggerTime длительность загрузки конфигурации
+	// и инициализации логгера для отчета о запуске
+	configTime time.Duration
+	loggerTime time.Duration
+}
+
+// newRootCmd создает корневую команду CLI
+func newRootCmd() (*cobra.Command, *rootOptions) {
+	opts := &rootOptions{}
+
+	cmd := &cobra.Command{
+		Use:   app.ServiceName,
+		Short: app.ServiceDescription,
+		Long: app.ServiceDisplayName + " - " + app.ServiceDescription + ".\n\n" +
+			"Without a subcommand the binary runs as a service (Windows SCM or systemd).",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// По умолчанию запускаем как сервис
+			return runService(opts, false)
+		},
+	}
+
+	// Используем собственную команду completion
+	cmd.CompletionOptions.DisableDefaultCmd = true
+
+	cmd.PersistentFlags().StringVarP(&opts.configPath, "config", "c", "", "path to config file (default: <exec dir>/configs/config.yaml)")
+	cmd.PersistentFlags().StringVar(&opts.name, "name", "", "service instance name (default: service.name from config or "+app.ServiceName+")")
+	cmd.PersistentFlags().BoolVar(&opts.json, "json", false, "machine-readable JSON output (including errors)")
+	cmd.PersistentFlags().BoolVar(&opts.readOnly, "read-only", false, "run with state-mutating timers and tasks disabled (same as service.read_only)")
+	cmd.PersistentFlags().BoolVar(&opts.k8s, "k8s", false, "run in Kubernetes mode: pod labels, stdout logging, /ready and SIGTERM draining (same as kubernetes.enabled)")
+	cmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return withCode(exitUsage, err)
+	})
+
+	cmd.AddCommand(
+		newRunCmd(opts),
+		newCheckCmd(opts),
+		newBootstrapCmd(opts),
+		newInstallCmd(opts),
+		newReconfigureCmd(opts),
+		newUninstallCmd(opts),
+		newStartCmd(opts),
+		newStopCmd(opts),
+		newVersionCmd(opts),
+		newHealthcheckCmd(opts),
+		newStatusCmd(opts),
+		newLogLevelCmd(opts),
+		newLogsCmd(opts),
+		newTriggerCmd(opts),
+		newListTimersCmd(opts),
+		newHistoryCmd(opts),
+		newScheduleCmd(opts),
+		newAuditCmd(opts),
+		newEncryptCmd(opts),
+		newDecryptCmd(opts),
+		newScaffoldCmd(opts),
+		newCompletionCmd(),
+	)
+
+	return cmd, opts
+}
+
+// resolveConfigPath возвращает путь к конфигу из флага или путь по умолчанию
+func resolveConfigPath(opts *rootOptions) (string, error) {
+	if opts.configPath != "" {
+		return filepath.Abs(opts.configPath)
+	}
+	execPath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to get executable path: %w", err)
+	}
+	return filepath.Join(filepath.Dir(execPath), "configs", "config.yaml"), nil
+}
+
+// loadConfig загружает конфигурацию без инициализации логгера.
+// Идентичность сервиса дополняется значениями по умолчанию и флагом --name
+func loadConfig(opts *rootOptions) (*config.Config, string, error) {
+	configPath, err := resolveConfigPath(opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Загружаем конфигурацию
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if opts.name != "" {
+		cfg.Service.Name = opts.name
+	}
+	if opts.logLevel != "" {
+		cfg.Service.LogLevel = opts.logLevel
+	}
+	if opts.readOnly {
+		cfg.Service.ReadOnly = true
+	}
+	if opts.k8s {
+		cfg.Kubernetes.Enabled = true
+	}
+	if cfg.Service.Name == "" {
+		cfg.Service.Name = app.ServiceName
+	}
+	if cfg.Service.DisplayName == "" {
+		cfg.Service.DisplayName = app.ServiceDisplayName
+	}
+	if cfg.Service.Description == "" {
+		cfg.Service.Description = app.ServiceDescription
+	}
+	cfg.ResolvePaths()
+	if cfg.Service.Locale != "" {
+		locale, err := i18n.Parse(cfg.Service.Locale)
+		if err != nil {
+			return nil, "", &apperr.ConfigError{Path: configPath, Key: "service.locale", Err: err}
+		}
+		i18n.SetLocale(locale)
+	}
+	return cfg, configPath, nil
+}
+
+// loadEnvironment загружает конфигурацию и инициализирует логгер
+func loadEnvironment(opts *rootOptions) (*environment, error) {
+	start := time.Now()
+	cfg, configPath, err := loadConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	configured := time.Now()
+
+	level, err := logger.ParseLevel(cfg.Service.LogLevel)
+	if err != nil {
+		return nil, &apperr.ConfigError{Path: configPath, Key: "service.log_level", Err: err}
+	}
+	encoder, err := logger.LookupEncoder(cfg.Service.LogEncoding)
+	if err != nil {
+		return nil, &apperr.ConfigError{Path: configPath, Key: "service.log_encoding", Err: err}
+	}
+
+	// Инициализируем логгер. В Kubernetes логи собираются из stdout,
+	// файл пишется, только если включен kubernetes.file_logging
+	var log *logger.Logger
+	if cfg.Kubernetes.Enabled && !cfg.Kubernetes.FileLogging {
+		log = logger.NewStdout(cfg.Service.Name)
+	} else if log, err = logger.New(cfg.Service.Name, cfg.Service.LogDir); err != nil {
+		return nil, apperr.Access(cfg.Service.LogDir, fmt.Errorf("failed to initialize logger: %w", err))
+	}
+	log.SetLevel(level)
+	if cfg.Service.LogEncoding != logger.JSONEncoding {
+		log.SetEncoder(encoder)
+	}
+	log.SetRing(logger.NewRing(cfg.Service.LogBufferSize))
+	log.SetEventTranslator(i18n.Event)
+
+	return &environment{
+		configPath: configPath,
+		cfg:        cfg,
+		log:        log,
+		configTime: configured.Sub(start),
+		loggerTime: time.Since(configured),
+	}, nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"service-boilerplate/internal/app"
+	"service-boilerplate/internal/config"
+	"service-boilerplate/internal/crash"
+	"service-boilerplate/internal/i18n"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/platform"
+)
+
+// newRunCmd создает команду run
+func newRunCmd(opts *rootOptions) *cobra.Command {
+	var dryRun, verbose bool
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run the service in console mode",
+		Long: "Run the service in console mode.\n\n" +
+			"-v/--log-level override service.log_level and switch console output " +
+			"to a human-readable format; the log file stays JSON.",
+		Example: `  service-boilerplate run -v
+  service-boilerplate run --log-level warn`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if verbose && opts.logLevel == "" {
+				opts.logLevel = logger.DebugLevel.String()
+			}
+			if _, err := logger.ParseLevel(opts.logLevel); err != nil {
+				return withCode(exitUsage, err)
+			}
+
+			if dryRun {
+				return checkService(cmd, opts)
+			}
+			return runService(opts, true)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "initialize and validate everything, then exit without starting timers")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "debug logging with human-readable console output (same as --log-level debug)")
+	cmd.Flags().StringVar(&opts.logLevel, "log-level", "", "override service.log_level (debug, info, warn, error) with human-readable console output")
+	return cmd
+}
+
+// newCheckCmd создает команду check (аналог run --dry-run)
+func newCheckCmd(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "check",
+		Short: "Validate config and run startup pre-checks without starting the service",
+		Args:  usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return checkService(cmd, opts)
+		},
+	}
+}
+
+// checkService инициализирует приложение и выполняет dry-run проверку
+func checkService(cmd *cobra.Command, opts *rootOptions) error {
+	env, err := loadEnvironment(opts)
+	if err != nil {
+		return err
+	}
+	defer env.log.Close()
+
+	application := app.New(env.cfg, env.log)
+	registerComponents(application, env.log)
+
+	if err := application.Check(cmd.Context()); err != nil {
+		return fmt.Errorf("startup check failed: %w", err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), i18n.T(i18n.StartupCheckPassed))
+	return nil
+}
+
+// runService создает приложение и запускает его через платформенный слой
+func runService(opts *rootOptions, console bool) error {
+	env, err := loadEnvironment(opts)
+	if err != nil {
+		return err
+	}
+	defer env.log.Close()
+
+	// Уровень, заданный флагом, означает локальную отладку: выводим читаемый текст
+	if console && opts.logLevel != "" {
+		env.log.SetPrettyConsole(os.Stdout)
+	}
+
+	// Создаем приложение
+	start := time.Now()
+	application := app.New(env.cfg, env.log)
+	application.SetReloader(func() (*config.Config, error) {
+		cfg, _, err := loadConfig(opts)
+		return cfg, err
+	})
+	reporter := newCrashReporter(env, application)
+	defer reporter.Recover()
+	registerComponents(application, env.log)
+	application.RecordStartupPhase("config", env.configTime)
+	application.RecordStartupPhase("logger", env.loggerTime)
+	application.RecordStartupPhase("init", time.Since(start))
+
+	if console {
+		env.log.Info("Running in console mode", map[string]interface{}{"log_level": env.cfg.Service.LogLevel})
+	}
+	if err := platform.Run(env.log, application); err != nil {
+		env.log.Error("Application error", map[string]interface{}{"error": err.Error()})
+		env.log.Flush()
+		return fmt.Errorf("application error: %w", err)
+	}
+	return nil
+}
+
+// crashDir возвращает директорию отчетов о падении: service.crash_dir
+// или <log_dir>/crashes
+func crashDir(cfg *config.Config) string {
+	if cfg.Service.CrashDir != "" {
+		return cfg.Service.CrashDir
+	}
+	return filepath.Join(cfg.Service.LogDir, "crashes")
+}
+
+// newCrashReporter включает запись отчетов о падении при Fatal, panic
+// в текущей горутине и неперехваченных panic в остальных горутинах
+func newCrashReporter(env *environment, application *app.App) *crash.Reporter {
+	dir := crashDir(env.cfg)
+	view, err := env.cfg.View()
+	if err != nil {
+		env.log.Warn("Failed to prepare config summary for crash reports", map[string]interface{}{"error": err.Error()})
+	}
+	identity := application.Identity()
+	reporter := crash.New(dir, crash.Info{
+		Service:    identity.Service,
+		Version:    identity.Version,
+		InstanceID: identity.InstanceID,
+		Config:     view,
+		Recent:     env.log.Ring(),
+		LogFile:    logger.FilePath(env.cfg.Service.LogDir, env.cfg.Service.Name),
+	})
+
+	env.log.SetFatalHook(reporter.FatalHook())
+	if err := reporter.CaptureRuntimeCrashes(); err != nil {
+		env.log.Warn("Failed to capture runtime crashes", map[string]interface{}{"error": err.Error()})
+	}
+	return reporter
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"service-boilerplate/internal/app"
+	"service-boilerplate/internal/i18n"
+	"service-boilerplate/internal/scaffold"
+)
+
+// scaffoldOptions флаги команды scaffold
+type scaffoldOptions struct {
+	source      string
+	module      string
+	serviceName string
+	displayName string
+}
+
+// newScaffoldCmd создает команду scaffold
+func newScaffoldCmd(opts *rootOptions) *cobra.Command {
+	var sopts scaffoldOptions
+
+	cmd := &cobra.Command{
+		Use:   "scaffold <target-dir>",
+		Short: "Generate a new service project from this boilerplate",
+		Long: `Generate a new service project from the boilerplate sources:
+
+  - copy the sources except .git, build artifacts and .gitignore'd files
+  - rewrite the module path in go.mod and imports
+  - replace the service name and display name (constants, configs, scripts)
+  - rename cmd/` + app.ServiceName + ` to cmd/<service-name>
+  - add an example timer and task in internal/example
+
+The target directory must not exist or be empty.`,
+		Example: `  service-boilerplate scaffold ../billing --module github.com/acme/billing
+  service-boilerplate scaffold ../billing --from ~/src/service-boilerplate --display-name "Billing Service"`,
+		Args: usageArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target := args[0]
+			name := sopts.serviceName
+			if name == "" {
+				name = filepath.Base(filepath.Clean(target))
+			}
+			module := sopts.module
+			if module == "" {
+				module = name
+			}
+			displayName := sopts.displayName
+			if displayName == "" {
+				displayName = titleName(name)
+			}
+
+			result, err := scaffold.Generate(scaffold.Options{
+				Source:            sopts.source,
+				Target:            target,
+				Module:            module,
+				Name:              name,
+				DisplayName:       displayName,
+				SourceName:        app.ServiceName,
+				SourceDisplayName: app.ServiceDisplayName,
+			})
+			if err != nil {
+				return withCode(exitUsage, err)
+			}
+
+			if opts.json {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(map[string]interface{}{
+					"target":        target,
+					"module":        module,
+					"name":          name,
+					"display_name":  displayName,
+					"source_module": result.SourceModule,
+					"files":         result.Files,
+				})
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), i18n.T(i18n.ScaffoldCreated, name, target, result.Files, target))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&sopts.source, "from", ".", "boilerplate source tree (directory with go.mod)")
+	cmd.Flags().StringVar(&sopts.module, "module", "", "Go module path of the new project (default: service name)")
+	cmd.Flags().StringVar(&sopts.serviceName, "service-name", "", "service, binary and cmd directory name (default: base name of target-dir)")
+	cmd.Flags().StringVar(&sopts.displayName, "display-name", "", "service display name (default: service name in title case)")
+	return cmd
+}
+
+// titleName возвращает отображаемое имя из имени сервиса: billing-api -> Billing Api
+func titleName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' })
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"service-boilerplate/internal/i18n"
+)
+
+// newScheduleCmd создает команду schedule
+func newScheduleCmd(opts *rootOptions) *cobra.Command {
+	var (
+		count   int
+		ical    bool
+		timeout time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Show upcoming timer runs of the running service",
+		Long: "Show the next runs of every timer of the running service as a timeline.\n\n" +
+			"--ical prints an iCalendar file that can be imported into a calendar next to\n" +
+			"maintenance windows. Paused, disabled and standby timers have no upcoming runs.",
+		Example: `  service-boilerplate schedule --count 3
+  service-boilerplate schedule --count 100 --ical > schedule.ics`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newAdminClient(opts, timeout)
+			if err != nil {
+				return err
+			}
+
+			if ical {
+				data, err := client.ScheduleICal(cmd.Context(), count)
+				if err != nil {
+					return withCode(exitUnavailable, err)
+				}
+				_, err = cmd.OutOrStdout().Write(data)
+				return err
+			}
+
+			entries, err := client.Schedule(cmd.Context(), count)
+			if err != nil {
+				return withCode(exitUnavailable, err)
+			}
+			if opts.json {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(entries)
+			}
+
+			type run struct {
+				at       time.Time
+				timer    string
+				interval string
+			}
+			var runs []run
+			for _, e := range entries {
+				for _, at := range e.FireTimes {
+					runs = append(runs, run{at: at, timer: e.Timer, interval: e.Interval})
+				}
+			}
+			sort.SliceStable(runs, func(i, j int) bool { return runs[i].at.Before(runs[j].at) })
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, i18n.T(i18n.ScheduleHeader))
+			for _, r := range runs {
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", formatTime(&r.at), r.timer, r.interval)
+			}
+			return tw.Flush()
+		},
+	}
+
+	cmd.Flags().IntVarP(&count, "count", "n", 5, "number of upcoming runs per timer")
+	cmd.Flags().BoolVar(&ical, "ical", false, "print the schedule as an iCalendar file")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "request timeout")
+	return cmd
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"service-boilerplate/internal/i18n"
+	"service-boilerplate/internal/secrets"
+)
+
+// newEncryptCmd создает команду encrypt
+func newEncryptCmd(opts *rootOptions) *cobra.Command {
+	var generateKey bool
+
+	cmd := &cobra.Command{
+		Use:   "encrypt [value]",
+		Short: "Encrypt a value for use as !encrypted in the config",
+		Long: "Encrypt a value with AES-GCM for use in config.yaml as `key: !encrypted <value>`.\n" +
+			"Without an argument the value is read from stdin.\n\n" +
+			"The key is taken from " + secrets.KeyEnv + " (base64) or from " + secrets.KeyFileName + "\n" +
+			"next to the config (protected by DPAPI on Windows). --generate-key creates the key file if\n" +
+			"no key is set.",
+		Example: `  service-boilerplate encrypt --generate-key 's3cret'
+  echo -n 's3cret' | service-boilerplate encrypt`,
+		Args: usageArgs(cobra.MaximumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keyFile, err := secretKeyPath(opts)
+			if err != nil {
+				return err
+			}
+			key, err := secrets.LoadKey(keyFile)
+			if errors.Is(err, secrets.ErrNoKey) && generateKey {
+				if key, err = secrets.GenerateKey(); err == nil {
+					err = secrets.SaveKey(keyFile, key)
+				}
+				if err == nil {
+					fmt.Fprintln(cmd.ErrOrStderr(), i18n.T(i18n.SecretKeyCreated, keyFile))
+				}
+			}
+			if err != nil {
+				return withCode(exitConfig, err)
+			}
+
+			value, err := secretArg(cmd, args)
+			if err != nil {
+				return err
+			}
+			encrypted, err := secrets.Encrypt(key, []byte(value))
+			if err != nil {
+				return err
+			}
+
+			if opts.json {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]string{"value": secrets.Tag + " " + encrypted})
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), secrets.Tag+" "+encrypted)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&generateKey, "generate-key", false, "create the key file if no key is set")
+	return cmd
+}
+
+// newDecryptCmd создает команду decrypt
+func newDecryptCmd(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "decrypt [value]",
+		Short: "Decrypt an !encrypted config value",
+		Long: "Decrypt a value produced by encrypt (with or without the " + secrets.Tag + " prefix).\n" +
+			"Without an argument the value is read from stdin.",
+		Args: usageArgs(cobra.MaximumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keyFile, err := secretKeyPath(opts)
+			if err != nil {
+				return err
+			}
+			key, err := secrets.LoadKey(keyFile)
+			if err != nil {
+				return withCode(exitConfig, err)
+			}
+
+			value, err := secretArg(cmd, args)
+			if err != nil {
+				return err
+			}
+			plaintext, err := secrets.Decrypt(key, value)
+			if err != nil {
+				return err
+			}
+
+			if opts.json {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]string{"value": string(plaintext)})
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(plaintext))
+			return nil
+		},
+	}
+}
+
+// secretKeyPath возвращает путь к файлу ключа рядом с конфигом
+func secretKeyPath(opts *rootOptions) (string, error) {
+	configPath, err := resolveConfigPath(opts)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), secrets.KeyFileName), nil
+}
+
+// secretArg возвращает значение из аргумента или stdin без завершающего перевода строки
+func secretArg(cmd *cobra.Command, args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	data, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return "", fmt.Errorf("failed to read value from stdin: %w", err)
+	}
+	value := strings.TrimRight(string(data), "\r\n")
+	if value == "" {
+		return "", withCode(exitUsage, errors.New("value is empty"))
+	}
+	return value, nil
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"service-boilerplate/internal/app"
+	"service-boilerplate/internal/apperr"
+	"service-boilerplate/internal/audit"
+	"service-boilerplate/internal/config"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/perfcounters"
+	"service-boilerplate/internal/platform"
+)
+
+// newInstallCmd создает команду install
+func newInstallCmd(opts *rootOptions) *cobra.Command {
+	var displayName, description string
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install the service (Windows SCM)",
+		Long: "Install the service (Windows SCM).\n\n" +
+			"Use --name, --display-name and --description (or service.* in config) " +
+			"to install several instances of the same binary side by side.",
+		Example: `  service-boilerplate install --name worker-a --config C:\svc\a.yaml
+  service-boilerplate install --name worker-b --display-name "Worker B" --config C:\svc\b.yaml`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := loadEnvironment(opts)
+			if err != nil {
+				return err
+			}
+			defer env.log.Close()
+
+			if displayName != "" {
+				env.cfg.Service.DisplayName = displayName
+			}
+			if description != "" {
+				env.cfg.Service.Description = description
+			}
+
+			execPath, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("failed to get executable path: %w", err)
+			}
+
+			err = installService(env.cfg, execPath, env.configPath)
+			recordAudit(env, audit.ActionInstall, err)
+			if err != nil {
+				env.log.Error("Failed to install service", map[string]interface{}{"error": err.Error()})
+				return withCode(exitServiceManager, err)
+			}
+			env.log.Info("Service installed successfully", map[string]interface{}{
+				"name":         env.cfg.Service.Name,
+				"display_name": env.cfg.Service.DisplayName,
+			})
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&displayName, "display-name", "", "service display name (default: service.display_name from config)")
+	cmd.Flags().StringVar(&description, "description", "", "service description (default: service.description from config)")
+	return cmd
+}
+
+// newReconfigureCmd создает команду reconfigure
+func newReconfigureCmd(opts *rootOptions) *cobra.Command {
+	var displayName, description, startType, unitDir string
+
+	cmd := &cobra.Command{
+		Use:   "reconfigure",
+		Short: "Update the installed service registration (Windows SCM / systemd unit)",
+		Long: "Update the installed service registration (Windows SCM / systemd unit) without reinstalling.\n\n" +
+			"Display name, description, start type, recovery actions and command line arguments are taken " +
+			"from service.* in config and the flags below. The executable stays the one the service " +
+			"was installed with, even when reconfigure runs from another copy. A running service keeps running; " +
+			"the new command line applies on the next start.",
+		Example: `  service-boilerplate reconfigure --start-type delayed
+  service-boilerplate reconfigure --name worker-a --config C:\svc\a.yaml --display-name "Worker A"`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := loadEnvironment(opts)
+			if err != nil {
+				return err
+			}
+			defer env.log.Close()
+
+			if displayName != "" {
+				env.cfg.Service.DisplayName = displayName
+			}
+			if description != "" {
+				env.cfg.Service.Description = description
+			}
+			if startType != "" {
+				env.cfg.Service.StartType = startType
+			}
+			if err := env.cfg.Validate(); err != nil {
+				return &apperr.ConfigError{Path: env.configPath, Err: err}
+			}
+
+			err = reconfigureService(env.cfg, env.configPath, unitDir)
+			recordAudit(env, audit.ActionReconfigure, err)
+			if err != nil {
+				env.log.Error("Failed to reconfigure service", map[string]interface{}{"error": err.Error()})
+				return withCode(exitServiceManager, err)
+			}
+			env.log.Info("Service reconfigured successfully", map[string]interface{}{
+				"name":         env.cfg.Service.Name,
+				"display_name": env.cfg.Service.DisplayName,
+				"start_type":   env.cfg.Service.StartType,
+				"restart":      env.cfg.Service.Recovery.Restart,
+			})
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&displayName, "display-name", "", "service display name (default: service.display_name from config)")
+	cmd.Flags().StringVar(&description, "description", "", "service description (default: service.description from config)")
+	cmd.Flags().StringVar(&startType, "start-type", "", "start type: auto, delayed, manual or disabled (default: service.start_type from config)")
+	cmd.Flags().StringVar(&unitDir, "unit-dir", "", "directory of the systemd unit (Linux only, default: where systemd loaded it from)")
+	return cmd
+}
+
+// newUninstallCmd создает команду uninstall
+func newUninstallCmd(opts *rootOptions) *cobra.Command {
+	var purge bool
+	var unitDir string
+
+	cmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Uninstall the service (Windows SCM / systemd unit)",
+		Long: "Uninstall the service (Windows SCM / systemd unit).\n\n" +
+			"With --purge also delete the files the service created: the log and its index, the audit log, " +
+			"crash reports, profiles, the state store, temporary artifacts and the config generated by bootstrap, " +
+			"then the service directories if they are empty, so decommissioning a host leaves nothing behind. " +
+			"Hand-written configs, the secret key and the shared election lock are kept.",
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := loadEnvironment(opts)
+			if err != nil {
+				return err
+			}
+
+			err = uninstallService(env.cfg, unitDir)
+			recordAudit(env, audit.ActionUninstall, err)
+			if err != nil {
+				env.log.Error("Failed to uninstall service", map[string]interface{}{"error": err.Error()})
+			} else {
+				env.log.Info("Service uninstalled successfully")
+			}
+			// Закрываем логгер до очистки, чтобы файл лога можно было удалить
+			env.log.Close()
+			if err != nil {
+				return withCode(exitServiceManager, err)
+			}
+
+			if !purge {
+				return nil
+			}
+			steps := purgeEnvironment(env.cfg, env.configPath)
+			if err := writeSteps(cmd.OutOrStdout(), steps, opts.json); err != nil {
+				return err
+			}
+			return stepsError(steps)
+		},
+	}
+
+	cmd.Flags().BoolVar(&purge, "purge", false, "also delete logs, state and generated config after removing the service")
+	cmd.Flags().StringVar(&unitDir, "unit-dir", "", "directory of the systemd unit (Linux only, default: where systemd loaded it from)")
+	return cmd
+}
+
+// newStartCmd создает команду start
+func newStartCmd(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "start",
+		Short: "Start the installed service",
+		Args:  usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := loadEnvironment(opts)
+			if err != nil {
+				return err
+			}
+			defer env.log.Close()
+
+			err = platform.Start(env.cfg.Service.Name)
+			recordAudit(env, audit.ActionStart, err)
+			if err != nil {
+				env.log.Error("Failed to start service", map[string]interface{}{"error": err.Error()})
+				return withCode(exitServiceManager, err)
+			}
+			env.log.Info("Service started successfully")
+			return nil
+		},
+	}
+}
+
+// newStopCmd создает команду stop
+func newStopCmd(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the running service",
+		Args:  usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := loadEnvironment(opts)
+			if err != nil {
+				return err
+			}
+			defer env.log.Close()
+
+			err = platform.Stop(env.cfg.Service.Name)
+			recordAudit(env, audit.ActionStop, err)
+			if err != nil {
+				env.log.Error("Failed to stop service", map[string]interface{}{"error": err.Error()})
+				return withCode(exitServiceManager, err)
+			}
+			env.log.Info("Service stopped successfully")
+			return nil
+		},
+	}
+}
+
+// installService устанавливает Windows сервис. Сервис регистрируется
+// с аргументами --config и --name, чтобы экземпляр при запуске SCM
+// использовал тот же конфиг и то же имя
+func installService(cfg *config.Config, execPath, configPath string) error {
+	name := cfg.Service.Name
+
+	// Регистрируем источник событий, если его еще не создал bootstrap
+	registered, err := logger.EventSourceRegistered(name)
+	if err != nil {
+		return fmt.Errorf("failed to check event source: %w", err)
+	}
+	if !registered {
+		if err := logger.RegisterEventSource(name); err != nil {
+			return fmt.Errorf("failed to register event source: %w", err)
+		}
+	}
+
+	// Регистрируем набор счетчиков производительности
+	if cfg.Metrics.PerfCounters.Enabled {
+		if err := perfcounters.Register(name, execPath); err != nil {
+			if !registered {
+				logger.UnregisterEventSource(name)
+			}
+			return fmt.Errorf("failed to register performance counters: %w", err)
+		}
+	}
+
+	// Устанавливаем сервис
+	if err := platform.Install(serviceRegistration(cfg, execPath, configPath)); err != nil {
+		if cfg.Metrics.PerfCounters.Enabled {
+			perfcounters.Unregister(name, execPath)
+		}
+		if !registered {
+			logger.UnregisterEventSource(name)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// reconfigureService обновляет регистрацию установленного сервиса.
+// Бинарник остается тем, с которым сервис установлен. Источник событий
+// перерегистрируется, если он указывает на другой бинарник, набор
+// счетчиков производительности - если он включен в конфиге
+func reconfigureService(cfg *config.Config, configPath, unitDir string) error {
+	name := cfg.Service.Name
+	reg := serviceRegistration(cfg, "", configPath)
+	reg.UnitDir = unitDir
+	execPath, err := platform.ExecPath(reg)
+	if err != nil {
+		return err
+	}
+	reg.ExecPath = execPath
+
+	registered, err := logger.EventSourceRegistered(name)
+	if err != nil {
+		return fmt.Errorf("failed to check event source: %w", err)
+	}
+	if !registered {
+		if err := logger.RegisterEventSource(name); err != nil {
+			return fmt.Errorf("failed to register event source: %w", err)
+		}
+	}
+	if cfg.Metrics.PerfCounters.Enabled {
+		if err := perfcounters.Register(name, execPath); err != nil {
+			return fmt.Errorf("failed to register performance counters: %w", err)
+		}
+	}
+
+	return platform.Reconfigure(reg)
+}
+
+// serviceRegistration возвращает параметры регистрации сервиса в менеджере сервисов
+func serviceRegistration(cfg *config.Config, execPath, configPath string) platform.Registration {
+	return platform.Registration{
+		Name:         cfg.Service.Name,
+		DisplayName:  cfg.Service.DisplayName,
+		Description:  cfg.Service.Description,
+		ExecPath:     execPath,
+		Args:         serviceArgs(cfg, configPath),
+		StartType:    cfg.Service.StartType,
+		Restart:      cfg.Service.Recovery.Restart,
+		RestartDelay: time.Duration(cfg.Service.Recovery.DelaySeconds) * time.Second,
+		ResetPeriod:  time.Duration(cfg.Service.Recovery.ResetSeconds) * time.Second,
+	}
+}
+
+// serviceArgs возвращает аргументы, с которыми сервис запускается менеджером сервисов
+func serviceArgs(cfg *config.Config, configPath string) []string {
+	args := []string{"--config", configPath}
+	if cfg.Service.Name != app.ServiceName {
+		args = append(args, "--name", cfg.Service.Name)
+	}
+	return args
+}
+
+// uninstallService удаляет сервис из менеджера сервисов, источник событий
+// и счетчики производительности
+func uninstallService(cfg *config.Config, unitDir string) error {
+	name := cfg.Service.Name
+	reg := platform.Registration{Name: name, UnitDir: unitDir}
+
+	// Счетчики зарегистрированы для установленного бинарника,
+	// поэтому путь берется до удаления сервиса
+	var execPath string
+	if cfg.Metrics.PerfCounters.Enabled {
+		var err error
+		if execPath, err = platform.ExecPath(reg); err != nil {
+			return err
+		}
+	}
+
+	// Удаляем сервис
+	if err := platform.Uninstall(reg); err != nil {
+		return err
+	}
+
+	// Удаляем источник событий
+	if err := logger.UnregisterEventSource(name); err != nil {
+		return fmt.Errorf("failed to unregister event source: %w", err)
+	}
+
+	// Удаляем набор счетчиков производительности
+	if cfg.Metrics.PerfCounters.Enabled {
+		if err := perfcounters.Unregister(name, execPath); err != nil {
+			return fmt.Errorf("failed to unregister performance counters: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"service-boilerplate/internal/i18n"
+)
+
+// newStatusCmd создает команду status
+func newStatusCmd(opts *rootOptions) *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the state of the running service",
+		Long: "Show the state of the running service: version, PID, uptime, log level, timers\n" +
+			"and the result of the last config reload.\n\n" +
+			"Uses the local control socket (named pipe on Windows) if admin.socket is enabled,\n" +
+			"otherwise the admin API address.",
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newAdminClient(opts, timeout)
+			if err != nil {
+				return err
+			}
+
+			status, err := client.Status(cmd.Context())
+			if err != nil {
+				return withCode(exitUnavailable, err)
+			}
+
+			if opts.json {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(status)
+			}
+
+			uptime := time.Duration(status.UptimeSeconds) * time.Second
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, i18n.T(i18n.StatusService, status.Service, status.Version))
+			if status.InstanceID != "" {
+				fmt.Fprintln(tw, i18n.T(i18n.StatusInstance, status.InstanceID, status.Hostname))
+			}
+			fmt.Fprintln(tw, i18n.T(i18n.StatusPID, status.PID))
+			fmt.Fprintln(tw, i18n.T(i18n.StatusUptime, uptime))
+			fmt.Fprintln(tw, i18n.T(i18n.StatusLogLevel, status.LogLevel))
+			fmt.Fprintln(tw, i18n.T(i18n.StatusTimers, status.Timers, status.PausedTimers))
+			if status.LastReload != nil {
+				fmt.Fprintln(tw, i18n.T(i18n.StatusReload, formatTime(&status.LastReload.Time), status.LastReload.Result))
+			}
+			return tw.Flush()
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "request timeout")
+	return cmd
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"service-boilerplate/internal/admin"
+	"service-boilerplate/internal/app"
+	"service-boilerplate/internal/i18n"
+)
+
+// newTriggerCmd создает команду trigger
+func newTriggerCmd(opts *rootOptions) *cobra.Command {
+	var timeout time.Duration
+	var idempotencyKey string
+
+	cmd := &cobra.Command{
+		Use:               "trigger <timer-name>",
+		Short:             "Run a timer of the running service immediately",
+		Args:              usageArgs(cobra.ExactArgs(1)),
+		ValidArgsFunction: completeTimerNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newAdminClient(opts, timeout)
+			if err != nil {
+				return err
+			}
+
+			result, err := client.TriggerWithKey(cmd.Context(), args[0], idempotencyKey)
+			if err != nil {
+				return withCode(exitUnavailable, err)
+			}
+
+			if opts.json {
+				json.NewEncoder(cmd.OutOrStdout()).Encode(result)
+			} else {
+				fmt.Fprintln(cmd.OutOrStdout(), i18n.T(i18n.TimerResult, result.Timer, result.Status, result.DurationMs))
+			}
+			if result.Status != admin.StatusOK {
+				return fmt.Errorf("timer %s failed: %s", result.Timer, result.Error)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "maximum time to wait for the run to finish")
+	cmd.Flags().StringVar(&idempotencyKey, "idempotency-key", "", "run the timer once per key: a retry with the same key returns the first run's result")
+	return cmd
+}
+
+// newAdminClient создает клиент admin API запущенного экземпляра.
+// Локальный канал управления предпочтительнее TCP адреса
+func newAdminClient(opts *rootOptions, timeout time.Duration) (*admin.Client, error) {
+	cfg, _, err := loadConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case cfg.Admin.Socket:
+		return admin.NewSocketClient(app.ControlSocketPath(cfg), timeout), nil
+	case cfg.Admin.Enabled:
+		return admin.NewClient("http://"+localAddr(cfg.Admin.Listen), cfg.Admin.Token, timeout), nil
+	default:
+		return nil, withCode(exitConfig, fmt.Errorf("admin API and control socket are disabled in config"))
+	}
+}
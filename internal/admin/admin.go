@@ -0,0 +1,452 @@
+// Package admin предоставляет HTTP API управления запущенным сервисом
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"service-boilerplate/internal/appctx"
+	"service-boilerplate/internal/apperr"
+	"service-boilerplate/internal/audit"
+	"service-boilerplate/internal/config"
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/health"
+	"service-boilerplate/internal/httpmw"
+	"service-boilerplate/internal/jobs"
+	"service-boilerplate/internal/localsock"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/scheduler"
+)
+
+// TriggerResult результат ручного запуска таймера
+type TriggerResult struct {
+	Timer      string `json:"timer"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Статусы результата запуска
+const (
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+)
+
+// TimerStatus состояние таймера в ответе admin API
+type TimerStatus struct {
+	Name            string     `json:"name"`
+	Interval        string     `json:"interval"`
+	IntervalSeconds float64    `json:"interval_seconds"`
+	LastRun         *time.Time `json:"last_run,omitempty"`
+	NextRun         *time.Time `json:"next_run,omitempty"`
+	PanicCount      int        `json:"panic_count"`
+	MissedTicks     int        `json:"missed_ticks"`
+	ThrottleFactor  int        `json:"throttle_factor,omitempty"`
+	State           string     `json:"state"`
+}
+
+// errorResponse тело ответа с ошибкой
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server предоставляет HTTP сервер управления
+type Server struct {
+	log       *logger.Logger
+	scheduler *scheduler.Scheduler
+	jobs      *jobs.Queue
+	config    *config.Config
+	events    *events.Bus
+	shutdown  func(reason string)
+	stopping  chan struct{}
+	server    *http.Server
+	listener  net.Listener
+	enabled   bool
+	listen    string
+	token     string
+	started   time.Time
+	metrics   httpmw.Recorder
+	labels    LabelSource
+	reloader  Reloader
+	audit     *audit.Log
+	health    *health.Registry
+	identity  appctx.Identity
+	// idempotency результаты ручных запусков по Idempotency-Key
+	idempotency *idempotencyCache
+
+	// Локальный канал управления (Unix socket / named pipe)
+	socketPath     string
+	socketServer   *http.Server
+	socketListener net.Listener
+	stopOnce       sync.Once
+}
+
+// New создает новый admin сервер. Адрес, токен и признак включения берутся
+// из cfg.Admin, cfg отдается через GET /config. shutdown вызывается
+// по POST /shutdown и должен инициировать graceful остановку.
+// Если queue равна nil, маршруты /jobs не регистрируются
+func New(log *logger.Logger, sched *scheduler.Scheduler, queue *jobs.Queue, cfg *config.Config, shutdown func(reason string)) *Server {
+	s := &Server{
+		log:       log,
+		scheduler: sched,
+		jobs:      queue,
+		config:    cfg,
+		shutdown:  shutdown,
+		stopping:  make(chan struct{}),
+		enabled:   cfg.Admin.Enabled,
+		listen:    cfg.Admin.Listen,
+		token:     cfg.Admin.Token,
+		started:   time.Now(),
+	}
+	s.idempotency = newIdempotencyCache(time.Duration(cfg.Admin.IdempotencyWindowSeconds) * time.Second)
+
+	if s.enabled {
+		// Handler назначается в Start, чтобы учесть SetMetrics
+		s.server = &http.Server{}
+		// Потоки событий не завершаются сами и задерживали бы Shutdown
+		s.server.RegisterOnShutdown(s.closeStreams)
+	}
+
+	return s
+}
+
+// SetSocket включает локальный канал управления path (Unix socket на Linux,
+// named pipe на Windows) независимо от TCP адреса. Токен в канале
+// не проверяется: доступ ограничен правами ОС. Вызывается до Start
+func (s *Server) SetSocket(path string) {
+	s.socketPath = path
+	s.socketServer = &http.Server{}
+	s.socketServer.RegisterOnShutdown(s.closeStreams)
+}
+
+// SetMetrics включает метрики запросов admin API (метка server="admin").
+// Если recorder реализует LabelSource, включается маршрут GET /metrics/labels.
+// Вызывается до Start
+func (s *Server) SetMetrics(recorder httpmw.Recorder) {
+	s.metrics = recorder
+	s.labels, _ = recorder.(LabelSource)
+}
+
+// SetIdentity задает идентичность экземпляра для GET /status. Вызывается до Start
+func (s *Server) SetIdentity(id appctx.Identity) {
+	s.identity = id
+}
+
+// closeStreams завершает потоки событий при остановке любого из серверов
+func (s *Server) closeStreams() {
+	s.stopOnce.Do(func() { close(s.stopping) })
+}
+
+// Handler возвращает HTTP обработчик admin API
+func (s *Server) Handler() http.Handler {
+	return s.instrument(s.authenticate(s.routes()))
+}
+
+// instrument добавляет общие middleware: идентификатор запроса, журнал
+// доступа, метрики и перехват panic
+func (s *Server) instrument(next http.Handler) http.Handler {
+	return httpmw.Wrap(next, httpmw.Options{
+		Server:      "admin",
+		Log:         s.log,
+		Metrics:     s.metrics,
+		AccessLevel: logger.InfoLevel,
+	})
+}
+
+// routes возвращает маршруты admin API без проверки токена
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	for _, rt := range s.routeTable() {
+		mux.HandleFunc(rt.method+" "+rt.path, rt.handler)
+	}
+	mux.HandleFunc("GET /openapi.json", s.handleOpenAPI)
+	mux.HandleFunc("GET /docs", s.handleDocs)
+	mux.HandleFunc("GET /dashboard", s.handleDashboard)
+	return mux
+}
+
+// authenticate проверяет токен, если он задан в конфигурации.
+// Страницы GET /docs и GET /dashboard не содержат данных и отдаются без токена
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.token == "" {
+		return next
+	}
+	expected := []byte("Bearer " + s.token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && (r.URL.Path == "/docs" || r.URL.Path == "/dashboard") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			fields := httpmw.Fields(r)
+			fields["remote"] = r.RemoteAddr
+			s.log.Warn("Admin API unauthorized request", fields)
+			s.record(r, audit.ActionUnauthorized, r.Method+" "+r.URL.Path, nil, nil)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthorized"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GetAddress возвращает адрес сервера (полезно для тестов)
+func (s *Server) GetAddress() string {
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return s.listen
+}
+
+// GetSocketPath возвращает путь локального канала управления или пустую строку
+func (s *Server) GetSocketPath() string {
+	return s.socketPath
+}
+
+// Start запускает admin сервер и локальный канал управления
+func (s *Server) Start(ctx context.Context) error {
+	if s.socketServer != nil {
+		if err := s.startSocket(); err != nil {
+			return err
+		}
+	}
+	if !s.enabled {
+		s.log.Info("Admin server is disabled")
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", s.listen)
+	if err != nil {
+		return apperr.Listen("admin server", s.listen, err)
+	}
+	s.listener = listener
+	s.server.Handler = s.Handler()
+
+	s.log.Info("Starting admin server", map[string]interface{}{
+		"listen": s.GetAddress(),
+		"auth":   s.token != "",
+	})
+	if s.token == "" && !isLoopback(s.listen) {
+		s.log.Warn("Admin server listens on a non-loopback address without a token", map[string]interface{}{
+			"listen": s.listen,
+		})
+	}
+
+	go func() {
+		if err := s.server.Serve(s.listener); err != nil && err != http.ErrServerClosed {
+			s.log.Error("Admin server error", map[string]interface{}{"error": err.Error()})
+		}
+	}()
+
+	return nil
+}
+
+// startSocket запускает HTTP сервер admin API на локальном канале
+func (s *Server) startSocket() error {
+	listener, err := localsock.Listen(s.socketPath)
+	if err != nil {
+		return apperr.Listen("control socket", s.socketPath, err)
+	}
+	s.socketListener = listener
+	s.socketServer.Handler = s.instrument(markSocket(s.routes()))
+
+	s.log.Info("Starting control socket", map[string]interface{}{"path": s.socketPath})
+	go func() {
+		if err := s.socketServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			s.log.Error("Control socket error", map[string]interface{}{"error": err.Error()})
+		}
+	}()
+	return nil
+}
+
+// Stop останавливает admin сервер и локальный канал управления
+func (s *Server) Stop(ctx context.Context) error {
+	var errs []error
+	if s.socketListener != nil {
+		s.log.Info("Stopping control socket")
+		errs = append(errs, s.socketServer.Shutdown(ctx))
+	}
+	if s.enabled && s.server != nil {
+		s.log.Info("Stopping admin server")
+		errs = append(errs, s.server.Shutdown(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// handleListTimers обрабатывает GET /timers
+func (s *Server) handleListTimers(w http.ResponseWriter, r *http.Request) {
+	infos := s.scheduler.ListTimers()
+	timers := make([]TimerStatus, 0, len(infos))
+	for _, info := range infos {
+		timers = append(timers, timerStatus(info))
+	}
+	writeJSON(w, http.StatusOK, timers)
+}
+
+// timerStatus преобразует scheduler.TimerInfo в TimerStatus
+func timerStatus(info scheduler.TimerInfo) TimerStatus {
+	return TimerStatus{
+		Name:            info.Name,
+		Interval:        info.Interval.String(),
+		IntervalSeconds: info.Interval.Seconds(),
+		LastRun:         timePtr(info.LastRun),
+		NextRun:         timePtr(info.NextRun),
+		PanicCount:      info.PanicCount,
+		MissedTicks:     info.MissedTicks,
+		ThrottleFactor:  info.Throttle,
+		State:           info.State,
+	}
+}
+
+// timePtr возвращает nil для нулевого времени
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// handleTrigger обрабатывает POST /timers/{name}/trigger. С заголовком
+// Idempotency-Key таймер запускается один раз в пределах окна
+// admin.idempotency_window_seconds: повторные запросы с тем же ключом
+// ждут первый запуск и получают его результат
+func (s *Server) handleTrigger(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	key := r.Header.Get(IdempotencyKeyHeader)
+	if key == "" {
+		code, body, _ := s.trigger(r, name, nil)
+		writeJSON(w, code, body)
+		return
+	}
+
+	for {
+		run, first, err := s.idempotency.begin(key, name)
+		if err != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: err.Error()})
+			return
+		}
+		if first {
+			code, body, result := s.trigger(r, name, map[string]interface{}{"idempotency_key": key})
+			s.idempotency.finish(run, result)
+			writeJSON(w, code, body)
+			return
+		}
+		select {
+		case <-run.done:
+		case <-r.Context().Done():
+			return
+		}
+		// Первый запрос не запустил таймер: ключ освобожден, пробуем сами
+		if !run.kept {
+			continue
+		}
+		s.log.Info("Admin action: trigger timer replayed", map[string]interface{}{
+			"timer":           name,
+			"idempotency_key": key,
+			"remote":          r.RemoteAddr,
+		})
+		w.Header().Set(ReplayedHeader, "true")
+		writeJSON(w, http.StatusOK, run.result)
+		return
+	}
+}
+
+// trigger запускает таймер name и возвращает код и тело ответа.
+// result nil, если таймер не запускался. details дополняют запись аудита
+func (s *Server) trigger(r *http.Request, name string, details map[string]interface{}) (int, interface{}, *TriggerResult) {
+	start := time.Now()
+	err := s.scheduler.Trigger(name)
+	result := TriggerResult{
+		Timer:      name,
+		Status:     StatusOK,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if details == nil {
+		details = make(map[string]interface{})
+	}
+	details["duration_ms"] = result.DurationMs
+	s.record(r, audit.ActionTriggerTimer, name, err, details)
+
+	switch {
+	case errors.Is(err, scheduler.ErrTimerNotFound):
+		return http.StatusNotFound, errorResponse{Error: err.Error()}, nil
+	case errors.Is(err, scheduler.ErrNotRunning):
+		return http.StatusServiceUnavailable, errorResponse{Error: err.Error()}, nil
+	case errors.Is(err, scheduler.ErrReadOnly), errors.Is(err, scheduler.ErrNamespaceDisabled):
+		return http.StatusForbidden, errorResponse{Error: err.Error()}, nil
+	case err != nil:
+		result.Status = StatusFailed
+		result.Error = err.Error()
+	}
+
+	s.log.Info("Admin action: trigger timer", map[string]interface{}{
+		"timer":  name,
+		"status": result.Status,
+		"remote": r.RemoteAddr,
+	})
+	return http.StatusOK, result, &result
+}
+
+// handlePause обрабатывает POST /timers/{name}/pause
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	s.setPaused(w, r, s.scheduler.Pause, "pause", audit.ActionPauseTimer)
+}
+
+// handleResume обрабатывает POST /timers/{name}/resume
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	s.setPaused(w, r, s.scheduler.Resume, "resume", audit.ActionResumeTimer)
+}
+
+// setPaused выполняет pause/resume и возвращает новое состояние таймера
+func (s *Server) setPaused(w http.ResponseWriter, r *http.Request, action func(string) error, actionName, auditAction string) {
+	name := r.PathValue("name")
+	err := action(name)
+	s.record(r, auditAction, name, err, nil)
+	if err != nil {
+		if errors.Is(err, scheduler.ErrTimerNotFound) {
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+
+	s.log.Info("Admin action: "+actionName+" timer", map[string]interface{}{
+		"timer":  name,
+		"remote": r.RemoteAddr,
+	})
+	for _, info := range s.scheduler.ListTimers() {
+		if info.Name == name {
+			writeJSON(w, http.StatusOK, timerStatus(info))
+			return
+		}
+	}
+	writeJSON(w, http.StatusNotFound, errorResponse{Error: scheduler.ErrTimerNotFound.Error()})
+}
+
+// isLoopback проверяет, что адрес прослушивания доступен только локально
+func isLoopback(listen string) bool {
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// writeJSON записывает JSON ответ
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,937 @@
+package admin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/appctx"
+	"service-boilerplate/internal/audit"
+	"service-boilerplate/internal/config"
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/health"
+	"service-boilerplate/internal/jobs"
+	"service-boilerplate/internal/localsock"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+	"service-boilerplate/internal/scheduler"
+)
+
+// setupTestAdmin создает тестовый admin сервер с запущенным планировщиком
+func setupTestAdmin(t *testing.T) (*Client, *scheduler.Scheduler, func()) {
+	cfg := &config.Config{Admin: config.AdminConfig{Enabled: true, Listen: "127.0.0.1:0"}}
+	return setupTestAdminWith(t, cfg, nil)
+}
+
+// setupTestAdminWith создает тестовый admin сервер с заданной конфигурацией.
+// Клиент использует токен из конфигурации
+func setupTestAdminWith(t *testing.T, cfg *config.Config, shutdown func(string)) (*Client, *scheduler.Scheduler, func()) {
+	tmpDir := t.TempDir()
+	log, err := logger.New("test-admin", tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	log.SetRing(logger.NewRing(100))
+
+	metricsServer := metrics.New(log, true, "127.0.0.1:0")
+	sched := scheduler.New(log, metricsServer, 3, 0)
+	sched.AddTimer("ok-timer", time.Hour, func(ctx context.Context) {})
+	sched.AddTimer("panic-timer", time.Hour, func(ctx context.Context) { panic("boom") })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := sched.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	queue := jobs.New(log, nil, jobs.Config{Workers: 1, QueueSize: 10, DeadLetterSize: 10})
+	queue.Register("echo", func(ctx context.Context, job *jobs.Job) error {
+		var msg struct{ Fail bool }
+		json.Unmarshal(job.Payload.(json.RawMessage), &msg)
+		if msg.Fail {
+			return errors.New("failed on request")
+		}
+		return nil
+	})
+	queue.AfterStart(ctx)
+
+	srv := New(log, sched, queue, cfg, shutdown)
+	srv.SetAudit(audit.New(filepath.Join(tmpDir, "audit.log")))
+	srv.SetMetrics(metricsServer)
+	registry := health.New(time.Second, 0)
+	registry.Register("db", func(ctx context.Context) error { return nil })
+	srv.SetHealth(registry)
+	bus := events.New()
+	sched.SetEvents(bus)
+	srv.SetEvents(bus)
+	ts := httptest.NewServer(srv.Handler())
+
+	cleanup := func() {
+		ts.Close()
+		queue.BeforeStop(context.Background())
+		cancel()
+		sched.Stop(context.Background())
+		log.Close()
+	}
+	return NewClient(ts.URL, cfg.Admin.Token, time.Second), sched, cleanup
+}
+
+// TestTrigger_Success проверяет успешный ручной запуск
+func TestTrigger_Success(t *testing.T) {
+	client, _, cleanup := setupTestAdmin(t)
+	defer cleanup()
+
+	result, err := client.Trigger(context.Background(), "ok-timer")
+	if err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+	if result.Status != StatusOK || result.Timer != "ok-timer" {
+		t.Errorf("Trigger() = %+v", result)
+	}
+}
+
+// TestTrigger_Panic проверяет отчет о неудачном запуске
+func TestTrigger_Panic(t *testing.T) {
+	client, _, cleanup := setupTestAdmin(t)
+	defer cleanup()
+
+	result, err := client.Trigger(context.Background(), "panic-timer")
+	if err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+	if result.Status != StatusFailed || !strings.Contains(result.Error, "boom") {
+		t.Errorf("Trigger() = %+v", result)
+	}
+}
+
+// TestTrigger_NotFound проверяет ошибку для несуществующего таймера
+func TestTrigger_NotFound(t *testing.T) {
+	client, _, cleanup := setupTestAdmin(t)
+	defer cleanup()
+
+	_, err := client.Trigger(context.Background(), "missing")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Trigger() error = %v, want 404", err)
+	}
+}
+
+// TestTrigger_Idempotency проверяет, что повторные запросы с тем же
+// ключом идемпотентности не запускают таймер и получают результат первого
+func TestTrigger_Idempotency(t *testing.T) {
+	client, sched, cleanup := setupTestAdmin(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	results := make(chan *TriggerResult, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			result, err := client.TriggerWithKey(ctx, "panic-timer", "key-1")
+			if err != nil {
+				t.Errorf("TriggerWithKey() error = %v", err)
+			}
+			results <- result
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		if result := <-results; result == nil || result.Status != StatusFailed || !strings.Contains(result.Error, "boom") {
+			t.Errorf("TriggerWithKey() = %+v", result)
+		}
+	}
+	if _, err := client.TriggerWithKey(ctx, "panic-timer", "key-1"); err != nil {
+		t.Fatalf("TriggerWithKey() repeat error = %v", err)
+	}
+	if history, _ := sched.GetTimerHistory("panic-timer"); len(history) != 1 {
+		t.Errorf("runs with one key = %d, want 1", len(history))
+	}
+
+	// Ключ другого таймера - 422, не запущенный таймер ключ не занимает
+	if _, err := client.TriggerWithKey(ctx, "ok-timer", "key-1"); err == nil || !strings.Contains(err.Error(), "422") {
+		t.Errorf("TriggerWithKey(other timer) error = %v, want 422", err)
+	}
+	if _, err := client.TriggerWithKey(ctx, "missing", "key-2"); err == nil {
+		t.Error("TriggerWithKey(missing) error = nil")
+	}
+	if _, err := client.TriggerWithKey(ctx, "ok-timer", "key-2"); err != nil {
+		t.Errorf("TriggerWithKey() after not found error = %v", err)
+	}
+
+	// Без ключа каждый запрос запускает таймер
+	client.Trigger(ctx, "panic-timer")
+	if history, _ := sched.GetTimerHistory("panic-timer"); len(history) != 2 {
+		t.Errorf("runs = %d, want 2", len(history))
+	}
+}
+
+// TestStartStop_Disabled проверяет отключенный сервер
+func TestStartStop_Disabled(t *testing.T) {
+	log, err := logger.New("test-admin", t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	defer log.Close()
+
+	srv := New(log, nil, nil, &config.Config{}, nil)
+	if err := srv.Start(context.Background()); err != nil {
+		t.Errorf("Start() error = %v", err)
+	}
+	if err := srv.Stop(context.Background()); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+}
+
+// TestSocket проверяет admin API через локальный канал без TCP адреса и токена
+func TestSocket(t *testing.T) {
+	log, err := logger.New("test-admin", t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	defer log.Close()
+
+	sched := scheduler.New(log, nil, 3, 0)
+	sched.AddTimer("ok-timer", time.Hour, func(ctx context.Context) {})
+	sched.AddTimer("idle-timer", time.Hour, func(ctx context.Context) {})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sched.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer sched.Stop(context.Background())
+	sched.Pause("idle-timer")
+
+	cfg := &config.Config{Service: config.ServiceConfig{Name: "svc"}, Admin: config.AdminConfig{Token: "secret"}}
+	srv := New(log, sched, nil, cfg, nil)
+	srv.SetIdentity(appctx.Identity{Service: "svc", InstanceID: "abc", Hostname: "node-1"})
+	path := filepath.Join(t.TempDir(), "control.sock")
+	if runtime.GOOS == "windows" {
+		path = localsock.DefaultPath("admin-test-" + filepath.Base(t.TempDir()))
+	}
+	srv.SetSocket(path)
+	if err := srv.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer srv.Stop(context.Background())
+
+	client := NewSocketClient(path, 5*time.Second)
+	status, err := client.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.Service != "svc" || status.Timers != 2 || status.PausedTimers != 1 || status.LogLevel != "info" ||
+		status.InstanceID != "abc" || status.Hostname != "node-1" {
+		t.Errorf("Status() = %+v", status)
+	}
+	if result, err := client.Trigger(ctx, "ok-timer"); err != nil || result.Status != StatusOK {
+		t.Errorf("Trigger() = %+v, %v", result, err)
+	}
+	if err := client.SetLogLevel(ctx, "debug"); err != nil {
+		t.Fatalf("SetLogLevel() error = %v", err)
+	}
+	if level, err := client.LogLevel(ctx); err != nil || level != "debug" {
+		t.Errorf("LogLevel() = %q, %v", level, err)
+	}
+}
+
+// TestListTimers проверяет список таймеров
+func TestListTimers(t *testing.T) {
+	client, _, cleanup := setupTestAdmin(t)
+	defer cleanup()
+
+	if _, err := client.Trigger(context.Background(), "ok-timer"); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+
+	timers, err := client.ListTimers(context.Background())
+	if err != nil {
+		t.Fatalf("ListTimers() error = %v", err)
+	}
+	if len(timers) != 2 {
+		t.Fatalf("len(timers) = %d, want 2", len(timers))
+	}
+	// Отсортированы по имени
+	ok := timers[0]
+	if ok.Name != "ok-timer" || ok.Interval != "1h0m0s" || ok.LastRun == nil || ok.State == "" {
+		t.Errorf("timers[0] = %+v", ok)
+	}
+}
+
+// TestTimerHistory проверяет историю запусков таймера
+func TestTimerHistory(t *testing.T) {
+	client, _, cleanup := setupTestAdmin(t)
+	defer cleanup()
+
+	client.Trigger(context.Background(), "panic-timer")
+	runs, err := client.TimerHistory(context.Background(), "panic-timer")
+	if err != nil {
+		t.Fatalf("TimerHistory() error = %v", err)
+	}
+	if len(runs) != 1 || runs[0].Outcome != scheduler.OutcomePanic || !strings.Contains(runs[0].Error, "boom") || runs[0].RunID == "" {
+		t.Errorf("TimerHistory() = %+v", runs)
+	}
+
+	if _, err := client.TimerHistory(context.Background(), "missing"); err == nil {
+		t.Error("TimerHistory(missing) error = nil, want 404")
+	}
+}
+
+// TestSchedule проверяет ближайшие запуски таймеров в JSON и iCalendar
+func TestSchedule(t *testing.T) {
+	client, sched, cleanup := setupTestAdmin(t)
+	defer cleanup()
+	sched.Pause("panic-timer")
+
+	entries, err := client.Schedule(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("len(entries) = %d, want 2", len(entries))
+	}
+	ok := entries[0]
+	if ok.Timer != "ok-timer" || len(ok.FireTimes) != 3 || ok.FireTimes[1].Sub(ok.FireTimes[0]) != time.Hour {
+		t.Errorf("entries[0] = %+v", ok)
+	}
+	if paused := entries[1]; paused.State != scheduler.StatePaused || len(paused.FireTimes) != 0 {
+		t.Errorf("paused timer entry = %+v", paused)
+	}
+
+	ical, err := client.ScheduleICal(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("ScheduleICal() error = %v", err)
+	}
+	text := string(ical)
+	if !strings.HasPrefix(text, "BEGIN:VCALENDAR\r\n") || strings.Count(text, "BEGIN:VEVENT") != 3 ||
+		!strings.Contains(text, "SUMMARY:timer ok-timer") {
+		t.Errorf("ScheduleICal() = %q", text)
+	}
+
+	if _, err := client.Schedule(context.Background(), maxScheduleCount+1); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Schedule() with too large count error = %v, want 400", err)
+	}
+}
+
+// TestAuth проверяет авторизацию по токену
+func TestAuth(t *testing.T) {
+	cfg := &config.Config{Admin: config.AdminConfig{Enabled: true, Listen: "127.0.0.1:0", Token: "secret"}}
+	client, _, cleanup := setupTestAdminWith(t, cfg, nil)
+	defer cleanup()
+
+	if _, err := client.ListTimers(context.Background()); err != nil {
+		t.Errorf("ListTimers() with token error = %v", err)
+	}
+
+	for _, token := range []string{"", "wrong"} {
+		anon := NewClient(client.baseURL, token, time.Second)
+		if _, err := anon.ListTimers(context.Background()); err == nil || !strings.Contains(err.Error(), "401") {
+			t.Errorf("ListTimers() with token %q error = %v, want 401", token, err)
+		}
+	}
+}
+
+// TestDashboard проверяет, что страница /dashboard отдается без токена,
+// а данные для нее, включая GET /health, требуют токен
+func TestDashboard(t *testing.T) {
+	cfg := &config.Config{Admin: config.AdminConfig{Enabled: true, Listen: "127.0.0.1:0", Token: "secret"}}
+	client, _, cleanup := setupTestAdminWith(t, cfg, nil)
+	defer cleanup()
+
+	resp, err := http.Get(client.baseURL + "/dashboard")
+	if err != nil {
+		t.Fatalf("GET /dashboard error = %v", err)
+	}
+	defer resp.Body.Close()
+	page, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(page), "logs/recent?level=error") {
+		t.Errorf("GET /dashboard without token = %d", resp.StatusCode)
+	}
+
+	report, err := client.Health(context.Background())
+	if err != nil {
+		t.Fatalf("Health() error = %v", err)
+	}
+	if report.Status != health.StatusHealthy || len(report.Checks) != 1 || report.Checks[0].Name != "db" {
+		t.Errorf("Health() = %+v", report)
+	}
+	anon := NewClient(client.baseURL, "", time.Second)
+	if _, err := anon.Health(context.Background()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Health() without token error = %v, want 401", err)
+	}
+}
+
+// TestPauseResume проверяет приостановку и возобновление таймера
+func TestPauseResume(t *testing.T) {
+	client, _, cleanup := setupTestAdmin(t)
+	defer cleanup()
+
+	status, err := client.Pause(context.Background(), "ok-timer")
+	if err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	if status.State != scheduler.StatePaused {
+		t.Errorf("Pause() state = %s, want %s", status.State, scheduler.StatePaused)
+	}
+
+	status, err = client.Resume(context.Background(), "ok-timer")
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if status.State == scheduler.StatePaused {
+		t.Errorf("Resume() state = %s", status.State)
+	}
+
+	if _, err := client.Pause(context.Background(), "missing"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Pause() missing error = %v, want 404", err)
+	}
+}
+
+// TestNamespaces проверяет список пространств имен, их отключение
+// и отказ в ручном запуске таймера отключенного пространства
+func TestNamespaces(t *testing.T) {
+	client, sched, cleanup := setupTestAdmin(t)
+	defer cleanup()
+	sched.AddTimer("tenant/report", time.Hour, func(ctx context.Context) {})
+
+	ctx := context.Background()
+	namespaces, err := client.Namespaces(ctx)
+	if err != nil {
+		t.Fatalf("Namespaces() error = %v", err)
+	}
+	if len(namespaces) != 1 || namespaces[0].Name != "tenant" || namespaces[0].Timers != 1 || !namespaces[0].Enabled {
+		t.Errorf("Namespaces() = %+v, want enabled tenant with 1 timer", namespaces)
+	}
+
+	status, err := client.DisableNamespace(ctx, "tenant")
+	if err != nil {
+		t.Fatalf("DisableNamespace() error = %v", err)
+	}
+	if status.Enabled {
+		t.Error("DisableNamespace() namespace still enabled")
+	}
+	if _, err := client.Trigger(ctx, "tenant/report"); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Trigger() in disabled namespace error = %v, want 403", err)
+	}
+
+	if status, err = client.EnableNamespace(ctx, "tenant"); err != nil || !status.Enabled {
+		t.Fatalf("EnableNamespace() = %+v, %v", status, err)
+	}
+	if result, err := client.Trigger(ctx, "tenant/report"); err != nil || result.Status != StatusOK {
+		t.Errorf("Trigger() = %+v, %v", result, err)
+	}
+	if _, err := client.EnableNamespace(ctx, "missing"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("EnableNamespace() missing error = %v, want 404", err)
+	}
+}
+
+// TestLogLevel проверяет чтение и изменение уровня логирования
+func TestLogLevel(t *testing.T) {
+	client, _, cleanup := setupTestAdmin(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if level, err := client.LogLevel(ctx); err != nil || level != "info" {
+		t.Errorf("LogLevel() = %q, %v, want info", level, err)
+	}
+	if err := client.SetLogLevel(ctx, "debug"); err != nil {
+		t.Fatalf("SetLogLevel() error = %v", err)
+	}
+	if level, err := client.LogLevel(ctx); err != nil || level != "debug" {
+		t.Errorf("LogLevel() = %q, %v, want debug", level, err)
+	}
+	if err := client.SetLogLevel(ctx, "verbose"); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("SetLogLevel(verbose) error = %v, want 400", err)
+	}
+}
+
+// TestRecentLogs проверяет чтение последних записей лога с фильтром по уровню
+func TestRecentLogs(t *testing.T) {
+	client, _, cleanup := setupTestAdmin(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if _, err := client.Trigger(ctx, "panic-timer"); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+
+	all, err := client.RecentLogs(ctx, "", 0)
+	if err != nil {
+		t.Fatalf("RecentLogs() error = %v", err)
+	}
+	errs, err := client.RecentLogs(ctx, "error", 0)
+	if err != nil {
+		t.Fatalf("RecentLogs(error) error = %v", err)
+	}
+	if len(errs) == 0 || len(errs) >= len(all) {
+		t.Fatalf("RecentLogs(error) = %d entries, all = %d", len(errs), len(all))
+	}
+	for _, e := range errs {
+		if level, _ := logger.ParseLevel(e.Level); level < logger.ErrorLevel {
+			t.Errorf("RecentLogs(error) returned %s entry %q", e.Level, e.Message)
+		}
+	}
+
+	last, err := client.RecentLogs(ctx, "", 1)
+	if err != nil || len(last) != 1 || last[0].Message != all[len(all)-1].Message {
+		t.Errorf("RecentLogs(limit=1) = %+v, %v", last, err)
+	}
+	if _, err := client.RecentLogs(ctx, "verbose", 0); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("RecentLogs(verbose) error = %v, want 400", err)
+	}
+}
+
+// TestConfig проверяет вывод конфигурации со скрытым токеном
+func TestConfig(t *testing.T) {
+	cfg := &config.Config{
+		Service:  config.ServiceConfig{Name: "svc", LogDir: "./logs"},
+		Admin:    config.AdminConfig{Enabled: true, Listen: "127.0.0.1:0", Token: "secret"},
+		Database: config.DatabaseConfig{DSN: "postgres://user:secret@db/app"},
+		Redis:    config.RedisConfig{Password: "secret"},
+		Alerting: config.AlertingConfig{Email: config.AlertEmailConfig{Password: "secret", From: "svc@example.com"}},
+	}
+	client, _, cleanup := setupTestAdminWith(t, cfg, nil)
+	defer cleanup()
+
+	view, err := client.Config(context.Background())
+	if err != nil {
+		t.Fatalf("Config() error = %v", err)
+	}
+	service, _ := view["service"].(map[string]interface{})
+	if service["name"] != "svc" || service["log_dir"] != "./logs" {
+		t.Errorf("Config() service = %v", service)
+	}
+	adminView, _ := view["admin"].(map[string]interface{})
+	if adminView["token"] != config.Redacted {
+		t.Errorf("Config() admin.token = %v, want redacted", adminView["token"])
+	}
+	databaseView, _ := view["database"].(map[string]interface{})
+	redisView, _ := view["redis"].(map[string]interface{})
+	if databaseView["dsn"] != config.Redacted || redisView["password"] != config.Redacted {
+		t.Errorf("Config() database.dsn = %v, redis.password = %v, want redacted", databaseView["dsn"], redisView["password"])
+	}
+	alertingView, _ := view["alerting"].(map[string]interface{})
+	emailView, _ := alertingView["email"].(map[string]interface{})
+	if emailView["password"] != config.Redacted || emailView["from"] != "svc@example.com" {
+		t.Errorf("Config() alerting.email = %v, want redacted password", emailView)
+	}
+}
+
+// stubReloader Reloader с заданной действующей конфигурацией
+type stubReloader struct {
+	cfg *config.Config
+}
+
+func (r *stubReloader) Reload() (config.ReloadStatus, error) {
+	return config.ReloadStatus{Result: config.ReloadSuccess}, nil
+}
+
+func (r *stubReloader) LastReload() (config.ReloadStatus, bool) {
+	return config.ReloadStatus{}, false
+}
+
+func (r *stubReloader) Config() *config.Config {
+	return r.cfg
+}
+
+// TestConfig_AfterReload проверяет, что GET /config возвращает
+// конфигурацию, примененную перезагрузкой
+func TestConfig_AfterReload(t *testing.T) {
+	log, err := logger.New("test-admin", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	cfg := &config.Config{
+		Service: config.ServiceConfig{Name: "svc", LogLevel: "info"},
+		Admin:   config.AdminConfig{Enabled: true, Listen: "127.0.0.1:0"},
+	}
+	reloaded := *cfg
+	reloaded.Service.LogLevel = "debug"
+
+	srv := New(log, scheduler.New(log, nil, 3, 0), nil, cfg, nil)
+	srv.SetReloader(&stubReloader{cfg: &reloaded})
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	view, err := NewClient(ts.URL, "", time.Second).Config(context.Background())
+	if err != nil {
+		t.Fatalf("Config() error = %v", err)
+	}
+	service, _ := view["service"].(map[string]interface{})
+	if service["log_level"] != "debug" {
+		t.Errorf("Config() service.log_level = %v, want debug", service["log_level"])
+	}
+}
+
+// TestMetricLabels проверяет статистику значений меток метрик
+func TestMetricLabels(t *testing.T) {
+	client, _, cleanup := setupTestAdmin(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if _, err := client.Trigger(ctx, "ok-timer"); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+	stats, err := client.MetricLabels(ctx)
+	if err != nil {
+		t.Fatalf("MetricLabels() error = %v", err)
+	}
+	found := make(map[string]metrics.LabelCardinality)
+	for _, st := range stats {
+		found[st.Metric] = st
+	}
+	if st := found["timer_runs_total"]; st.Values != 1 || st.Limit != metrics.DefaultMaxLabelValues || st.Overflow != 0 {
+		t.Errorf("timer_runs_total stats = %+v, want 1 value", st)
+	}
+	if _, ok := found["http_requests_total"]; !ok {
+		t.Errorf("MetricLabels() = %+v, want admin requests counted", stats)
+	}
+}
+
+// TestAudit проверяет запись действий операторов и GET /audit
+func TestAudit(t *testing.T) {
+	client, _, cleanup := setupTestAdmin(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if _, err := client.Trigger(ctx, "ok-timer"); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+	if _, err := client.Pause(ctx, "missing"); err == nil {
+		t.Fatal("Pause(missing) expected error")
+	}
+	client.Status(ctx)
+
+	entries, err := client.Audit(ctx, 0)
+	if err != nil {
+		t.Fatalf("Audit() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Audit() = %+v, want 2 entries (read-only requests are not recorded)", entries)
+	}
+	trigger, pause := entries[0], entries[1]
+	if trigger.Action != audit.ActionTriggerTimer || trigger.Target != "ok-timer" || trigger.Result != audit.ResultOK ||
+		trigger.Actor != audit.CurrentActor() || trigger.Source != audit.SourceAdmin || trigger.RequestID == "" {
+		t.Errorf("trigger entry = %+v", trigger)
+	}
+	if pause.Action != audit.ActionPauseTimer || pause.Result != audit.ResultFailed || pause.Error == "" {
+		t.Errorf("pause entry = %+v", pause)
+	}
+
+	if last, err := client.Audit(ctx, 1); err != nil || len(last) != 1 || last[0].Action != audit.ActionPauseTimer {
+		t.Errorf("Audit(1) = %+v, %v, want the pause entry", last, err)
+	}
+}
+
+// TestShutdown проверяет запрос graceful остановки
+func TestShutdown(t *testing.T) {
+	var reason string
+	cfg := &config.Config{Admin: config.AdminConfig{Enabled: true, Listen: "127.0.0.1:0"}}
+	client, _, cleanup := setupTestAdminWith(t, cfg, func(r string) { reason = r })
+	defer cleanup()
+
+	if err := client.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if reason == "" {
+		t.Error("shutdown callback was not called")
+	}
+}
+
+// TestJobs проверяет постановку заданий и dead-letter список
+func TestJobs(t *testing.T) {
+	client, _, cleanup := setupTestAdmin(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	result, err := client.EnqueueJob(ctx, "echo", map[string]bool{"fail": true})
+	if err != nil {
+		t.Fatalf("EnqueueJob() error = %v", err)
+	}
+	if result.ID == "" || result.Type != "echo" {
+		t.Errorf("EnqueueJob() = %+v", result)
+	}
+
+	if _, err := client.EnqueueJob(ctx, "missing", nil); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("EnqueueJob(missing) error = %v, want 404", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		status, err := client.Jobs(ctx)
+		if err != nil {
+			t.Fatalf("Jobs() error = %v", err)
+		}
+		if len(status.DeadLetters) == 1 {
+			dl := status.DeadLetters[0]
+			if dl.ID != result.ID || dl.LastError != "failed on request" {
+				t.Errorf("DeadLetters[0] = %+v", dl)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Jobs() = %+v, want one dead letter", status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestEvents проверяет поток событий: запуск таймера и смена уровня логирования
+func TestEvents(t *testing.T) {
+	client, _, cleanup := setupTestAdmin(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, client.baseURL+"/events?type=timer,log", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /events error = %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	lines := bufio.NewScanner(resp.Body)
+	// Подписка создана до первого комментария потока
+	lines.Scan()
+
+	client.Trigger(ctx, "ok-timer")
+	client.SetLogLevel(ctx, "debug")
+
+	var got []string
+	for len(got) < 2 && lines.Scan() {
+		if typ, ok := strings.CutPrefix(lines.Text(), "event: "); ok {
+			got = append(got, typ)
+		}
+	}
+	if len(got) != 2 || got[0] != events.TypeTimerRun || got[1] != events.TypeLogLevel {
+		t.Errorf("events = %v, want [%s %s]", got, events.TypeTimerRun, events.TypeLogLevel)
+	}
+}
+
+// TestOpenAPI проверяет, что описание OpenAPI содержит все маршруты
+// экземпляра со схемами ответов, а страница /docs отдается без токена
+func TestOpenAPI(t *testing.T) {
+	cfg := &config.Config{Admin: config.AdminConfig{Enabled: true, Listen: "127.0.0.1:0", Token: "secret"}}
+	client, _, cleanup := setupTestAdminWith(t, cfg, nil)
+	defer cleanup()
+
+	data, err := client.OpenAPI(context.Background())
+	if err != nil {
+		t.Fatalf("OpenAPI() error = %v", err)
+	}
+	var spec struct {
+		OpenAPI    string                                       `json:"openapi"`
+		Paths      map[string]map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas         map[string]map[string]interface{} `json:"schemas"`
+			SecuritySchemes map[string]interface{}            `json:"securitySchemes"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+	if spec.OpenAPI != "3.0.3" || spec.Components.SecuritySchemes["bearer"] == nil {
+		t.Errorf("openapi = %s, security schemes = %v", spec.OpenAPI, spec.Components.SecuritySchemes)
+	}
+
+	// Маршруты тестового сервера: без Reloader маршрута /config/reload нет
+	for _, route := range []string{"GET /status", "GET /timers", "GET /schedule", "POST /timers/{name}/trigger",
+		"GET /timers/{name}/history", "PUT /log/level", "GET /config", "GET /events", "GET /audit",
+		"GET /metrics/labels", "GET /health", "POST /jobs/{type}"} {
+		method, path, _ := strings.Cut(route, " ")
+		if spec.Paths[path][strings.ToLower(method)] == nil {
+			t.Errorf("openapi.json has no %s", route)
+		}
+	}
+	if _, ok := spec.Paths["/config/reload"]; ok {
+		t.Error("openapi.json describes /config/reload without a reloader")
+	}
+
+	timer := spec.Components.Schemas["TimerStatus"]
+	properties, _ := timer["properties"].(map[string]interface{})
+	if properties["last_run"] == nil || properties["throttle_factor"] == nil {
+		t.Errorf("TimerStatus schema = %v", timer)
+	}
+	service, _ := spec.Components.Schemas["ServiceConfig"]["properties"].(map[string]interface{})
+	if service["log_dir"] == nil {
+		t.Errorf("ServiceConfig schema uses yaml keys, got %v", service)
+	}
+
+	resp, err := http.Get(client.baseURL + "/docs")
+	if err != nil {
+		t.Fatalf("GET /docs error = %v", err)
+	}
+	defer resp.Body.Close()
+	page, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(page), "openapi.json") {
+		t.Errorf("GET /docs without token = %d", resp.StatusCode)
+	}
+}
+
+// TestExportImportState проверяет перенос пауз таймеров, пространств имен
+// и уровня логирования между экземплярами
+func TestExportImportState(t *testing.T) {
+	source, sourceSched, cleanupSource := setupTestAdmin(t)
+	defer cleanupSource()
+	sourceSched.AddTimer("tenant/report", time.Hour, func(ctx context.Context) {})
+
+	ctx := context.Background()
+	if _, err := source.Pause(ctx, "ok-timer"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.DisableNamespace(ctx, "tenant"); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.SetLogLevel(ctx, "debug"); err != nil {
+		t.Fatal(err)
+	}
+	state, err := source.ExportState(ctx)
+	if err != nil {
+		t.Fatalf("ExportState() error = %v", err)
+	}
+	state.Timers = append(state.Timers, TimerState{Name: "removed-timer", Paused: true})
+
+	target, targetSched, cleanupTarget := setupTestAdmin(t)
+	defer cleanupTarget()
+	targetSched.AddTimer("tenant/report", time.Hour, func(ctx context.Context) {})
+	result, err := target.ImportState(ctx, state)
+	if err != nil {
+		t.Fatalf("ImportState() error = %v", err)
+	}
+	if result.Timers != 3 || result.Namespaces != 1 || result.LogLevel != "debug" {
+		t.Errorf("ImportState() = %+v", result)
+	}
+	if strings.Join(result.Skipped, ",") != "timer:removed-timer" {
+		t.Errorf("Skipped = %v, want timer:removed-timer", result.Skipped)
+	}
+	for _, info := range targetSched.ListTimers() {
+		if paused := info.State == scheduler.StatePaused; paused != (info.Name == "ok-timer") {
+			t.Errorf("timer %s state = %s after import", info.Name, info.State)
+		}
+	}
+	if ns := targetSched.ListNamespaces(); len(ns) != 1 || ns[0].Enabled {
+		t.Errorf("namespaces after import = %+v, want tenant disabled", ns)
+	}
+	if level, _ := target.LogLevel(ctx); level != "debug" {
+		t.Errorf("log level after import = %s, want debug", level)
+	}
+
+	// Экспорт в YAML и импорт YAML документа
+	yamlState, err := source.doRaw(ctx, http.MethodGet, "/state?format=yaml", nil)
+	if err != nil || !strings.Contains(string(yamlState), "paused: true") {
+		t.Fatalf("YAML export = %s, %v", yamlState, err)
+	}
+	req, _ := http.NewRequest(http.MethodPost, target.baseURL+"/state", strings.NewReader(string(yamlState)))
+	req.Header.Set("Content-Type", "application/yaml")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("YAML import status = %d, want 200", resp.StatusCode)
+	}
+
+	state.LogLevel = "verbose"
+	if _, err := target.ImportState(ctx, state); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("ImportState() invalid log level error = %v, want 400", err)
+	}
+
+	// Более раннее время последнего запуска не заменяет текущее
+	if _, err := target.Trigger(ctx, "ok-timer"); err != nil {
+		t.Fatal(err)
+	}
+	lastRun := func() time.Time {
+		for _, info := range targetSched.ListTimers() {
+			if info.Name == "ok-timer" {
+				return info.LastRun
+			}
+		}
+		return time.Time{}
+	}
+	before := lastRun()
+	earlier := before.Add(-time.Hour)
+	old := RuntimeState{Timers: []TimerState{{Name: "ok-timer", LastRun: &earlier}}}
+	if _, err := target.ImportState(ctx, &old); err != nil {
+		t.Fatalf("ImportState() error = %v", err)
+	}
+	if after := lastRun(); !after.Equal(before) {
+		t.Errorf("LastRun after importing an earlier time = %v, want %v", after, before)
+	}
+}
+
+// TestExportImportState_PausedDisabled проверяет, что пауза таймера
+// в отключенном пространстве имен переносится экспортом и импортом
+func TestExportImportState_PausedDisabled(t *testing.T) {
+	source, sourceSched, cleanupSource := setupTestAdmin(t)
+	defer cleanupSource()
+	sourceSched.AddTimer("tenant/report", time.Hour, func(ctx context.Context) {})
+
+	ctx := context.Background()
+	if _, err := source.Pause(ctx, "tenant/report"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.DisableNamespace(ctx, "tenant"); err != nil {
+		t.Fatal(err)
+	}
+	status, err := source.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.PausedTimers != 1 {
+		t.Errorf("PausedTimers = %d, want 1", status.PausedTimers)
+	}
+	state, err := source.ExportState(ctx)
+	if err != nil {
+		t.Fatalf("ExportState() error = %v", err)
+	}
+	for _, timer := range state.Timers {
+		if timer.Paused != (timer.Name == "tenant/report") {
+			t.Errorf("exported timer %s paused = %v", timer.Name, timer.Paused)
+		}
+	}
+
+	target, targetSched, cleanupTarget := setupTestAdmin(t)
+	defer cleanupTarget()
+	targetSched.AddTimer("tenant/report", time.Hour, func(ctx context.Context) {})
+	if _, err := target.ImportState(ctx, state); err != nil {
+		t.Fatalf("ImportState() error = %v", err)
+	}
+	if _, err := target.EnableNamespace(ctx, "tenant"); err != nil {
+		t.Fatal(err)
+	}
+	for _, timer := range targetSched.Snapshot().Timers {
+		if timer.Paused != (timer.Name == "tenant/report") {
+			t.Errorf("timer %s paused = %v after import", timer.Name, timer.Paused)
+		}
+	}
+}
+
+// TestImportState_ReadOnly проверяет, что в режиме только для чтения
+// состояние не импортируется
+func TestImportState_ReadOnly(t *testing.T) {
+	cfg := &config.Config{
+		Service: config.ServiceConfig{ReadOnly: true},
+		Admin:   config.AdminConfig{Enabled: true, Listen: "127.0.0.1:0"},
+	}
+	client, sched, cleanup := setupTestAdminWith(t, cfg, nil)
+	defer cleanup()
+
+	state := RuntimeState{Timers: []TimerState{{Name: "ok-timer", Paused: true}}}
+	if _, err := client.ImportState(context.Background(), &state); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("ImportState() in read-only mode error = %v, want 403", err)
+	}
+	for _, info := range sched.ListTimers() {
+		if info.State == scheduler.StatePaused {
+			t.Errorf("timer %s paused by a rejected import", info.Name)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"service-boilerplate/internal/audit"
+	"service-boilerplate/internal/httpmw"
+)
+
+// defaultAuditLimit количество записей GET /audit по умолчанию
+const defaultAuditLimit = 100
+
+// socketKey ключ контекста запросов локального канала управления
+type socketKey struct{}
+
+// SetAudit включает запись действий операторов в журнал действий
+// и маршрут GET /audit. Вызывается до Start
+func (s *Server) SetAudit(log *audit.Log) {
+	s.audit = log
+}
+
+// markSocket отмечает запросы локального канала управления, чтобы
+// журнал действий отличал их от запросов по TCP
+func markSocket(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), socketKey{}, true)))
+	})
+}
+
+// record записывает действие оператора в журнал действий. Ошибка записи
+// не влияет на ответ: действие уже выполнено
+func (s *Server) record(r *http.Request, action, target string, err error, details map[string]interface{}) {
+	if s.audit == nil {
+		return
+	}
+	entry := audit.Entry{
+		Actor:     audit.Actor(r.Header.Get(audit.ActorHeader)),
+		Source:    audit.SourceAdmin,
+		Action:    action,
+		Target:    target,
+		Result:    audit.Result(err),
+		Error:     audit.ErrorText(err),
+		Remote:    r.RemoteAddr,
+		RequestID: httpmw.RequestID(r.Context()),
+		Details:   details,
+	}
+	if socket, _ := r.Context().Value(socketKey{}).(bool); socket {
+		entry.Source = audit.SourceSocket
+	}
+	if entry.Actor == "" {
+		entry.Actor = "anonymous"
+	}
+	if action == audit.ActionUnauthorized {
+		entry.Result = audit.ResultDenied
+	}
+	if err := s.audit.Record(entry); err != nil {
+		fields := httpmw.Fields(r)
+		fields["action"] = action
+		fields["error"] = err.Error()
+		s.log.Warn("Failed to write audit record", fields)
+	}
+}
+
+// handleAudit обрабатывает GET /audit: последние записи журнала действий.
+// Параметр limit задает количество записей (0 - все)
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	limit := defaultAuditLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid limit: " + v})
+			return
+		}
+		limit = n
+	}
+	entries, err := s.audit.Recent(limit)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
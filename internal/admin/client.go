@@ -0,0 +1,381 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"service-boilerplate/internal/audit"
+	"service-boilerplate/internal/config"
+	"service-boilerplate/internal/health"
+	"service-boilerplate/internal/localsock"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+)
+
+// Client клиент admin API для CLI команд. Запросы передают имя
+// пользователя ОС в audit.ActorHeader для журнала действий
+type Client struct {
+	baseURL string
+	token   string
+	actor   string
+	http    *http.Client
+}
+
+// NewClient создает клиент admin API. Пустой token означает работу без авторизации
+func NewClient(baseURL, token string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL: baseURL,
+		token:   token,
+		actor:   audit.CurrentActor(),
+		http:    &http.Client{Timeout: timeout},
+	}
+}
+
+// NewSocketClient создает клиент admin API, подключающийся через локальный
+// канал управления path (Unix socket / named pipe)
+func NewSocketClient(path string, timeout time.Duration) *Client {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return localsock.Dial(ctx, path)
+		},
+	}
+	return &Client{
+		baseURL: "http://localsock",
+		actor:   audit.CurrentActor(),
+		http:    &http.Client{Timeout: timeout, Transport: transport},
+	}
+}
+
+// Status возвращает состояние удаленного экземпляра
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var status Status
+	if err := c.do(ctx, http.MethodGet, "/status", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Trigger запускает таймер на удаленном экземпляре
+func (c *Client) Trigger(ctx context.Context, name string) (*TriggerResult, error) {
+	return c.TriggerWithKey(ctx, name, "")
+}
+
+// TriggerWithKey запускает таймер с ключом идемпотентности key: повторный
+// вызов с тем же ключом в пределах окна сервера не запускает таймер,
+// а возвращает результат первого запуска. Пустой key - как Trigger
+func (c *Client) TriggerWithKey(ctx context.Context, name, key string) (*TriggerResult, error) {
+	var header http.Header
+	if key != "" {
+		header = http.Header{IdempotencyKeyHeader: []string{key}}
+	}
+	data, err := c.request(ctx, http.MethodPost, "/timers/"+url.PathEscape(name)+"/trigger", header, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result TriggerResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListTimers возвращает состояние таймеров удаленного экземпляра
+func (c *Client) ListTimers(ctx context.Context) ([]TimerStatus, error) {
+	var timers []TimerStatus
+	if err := c.do(ctx, http.MethodGet, "/timers", nil, &timers); err != nil {
+		return nil, err
+	}
+	return timers, nil
+}
+
+// Schedule возвращает ближайшие count запусков каждого таймера
+// удаленного экземпляра (0 - значение по умолчанию сервера)
+func (c *Client) Schedule(ctx context.Context, count int) ([]ScheduleEntry, error) {
+	var entries []ScheduleEntry
+	if err := c.do(ctx, http.MethodGet, schedulePath(count, false), nil, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ScheduleICal возвращает ближайшие запуски таймеров удаленного экземпляра
+// в формате iCalendar
+func (c *Client) ScheduleICal(ctx context.Context, count int) ([]byte, error) {
+	return c.doRaw(ctx, http.MethodGet, schedulePath(count, true), nil)
+}
+
+// schedulePath возвращает путь GET /schedule с параметрами
+func schedulePath(count int, ical bool) string {
+	query := url.Values{}
+	if count > 0 {
+		query.Set("count", strconv.Itoa(count))
+	}
+	if ical {
+		query.Set("format", "ical")
+	}
+	if len(query) == 0 {
+		return "/schedule"
+	}
+	return "/schedule?" + query.Encode()
+}
+
+// Pause приостанавливает таймер удаленного экземпляра
+func (c *Client) Pause(ctx context.Context, name string) (*TimerStatus, error) {
+	var status TimerStatus
+	if err := c.do(ctx, http.MethodPost, "/timers/"+url.PathEscape(name)+"/pause", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Resume возобновляет таймер удаленного экземпляра
+func (c *Client) Resume(ctx context.Context, name string) (*TimerStatus, error) {
+	var status TimerStatus
+	if err := c.do(ctx, http.MethodPost, "/timers/"+url.PathEscape(name)+"/resume", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Namespaces возвращает пространства имен таймеров удаленного экземпляра
+func (c *Client) Namespaces(ctx context.Context) ([]NamespaceStatus, error) {
+	var namespaces []NamespaceStatus
+	if err := c.do(ctx, http.MethodGet, "/namespaces", nil, &namespaces); err != nil {
+		return nil, err
+	}
+	return namespaces, nil
+}
+
+// EnableNamespace включает пространство имен удаленного экземпляра
+func (c *Client) EnableNamespace(ctx context.Context, name string) (*NamespaceStatus, error) {
+	var status NamespaceStatus
+	if err := c.do(ctx, http.MethodPost, "/namespaces/"+url.PathEscape(name)+"/enable", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// DisableNamespace отключает пространство имен удаленного экземпляра
+func (c *Client) DisableNamespace(ctx context.Context, name string) (*NamespaceStatus, error) {
+	var status NamespaceStatus
+	if err := c.do(ctx, http.MethodPost, "/namespaces/"+url.PathEscape(name)+"/disable", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// TimerHistory возвращает последние запуски таймера удаленного экземпляра
+func (c *Client) TimerHistory(ctx context.Context, name string) ([]RunStatus, error) {
+	var runs []RunStatus
+	if err := c.do(ctx, http.MethodGet, "/timers/"+url.PathEscape(name)+"/history", nil, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// LogLevel возвращает текущий уровень логирования удаленного экземпляра
+func (c *Client) LogLevel(ctx context.Context) (string, error) {
+	var resp LogLevel
+	if err := c.do(ctx, http.MethodGet, "/log/level", nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.Level, nil
+}
+
+// SetLogLevel меняет уровень логирования удаленного экземпляра
+func (c *Client) SetLogLevel(ctx context.Context, level string) error {
+	return c.do(ctx, http.MethodPut, "/log/level", LogLevel{Level: level}, nil)
+}
+
+// RecentLogs возвращает последние записи лога удаленного экземпляра
+// с уровнем не ниже level (пустой - все); limit 0 означает все записи буфера
+func (c *Client) RecentLogs(ctx context.Context, level string, limit int) ([]logger.LogEntry, error) {
+	query := url.Values{}
+	if level != "" {
+		query.Set("level", level)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	path := "/logs/recent"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var entries []logger.LogEntry
+	if err := c.do(ctx, http.MethodGet, path, nil, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Config возвращает разрешенную конфигурацию удаленного экземпляра
+func (c *Client) Config(ctx context.Context) (map[string]interface{}, error) {
+	var cfg map[string]interface{}
+	if err := c.do(ctx, http.MethodGet, "/config", nil, &cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ReloadConfig перезагружает конфигурацию удаленного экземпляра
+func (c *Client) ReloadConfig(ctx context.Context) (*config.ReloadStatus, error) {
+	var status config.ReloadStatus
+	if err := c.do(ctx, http.MethodPost, "/config/reload", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// ExportState возвращает runtime-состояние удаленного экземпляра
+func (c *Client) ExportState(ctx context.Context) (*RuntimeState, error) {
+	var state RuntimeState
+	if err := c.do(ctx, http.MethodGet, "/state", nil, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// ImportState применяет runtime-состояние к удаленному экземпляру
+func (c *Client) ImportState(ctx context.Context, state *RuntimeState) (*ImportResult, error) {
+	var result ImportResult
+	if err := c.do(ctx, http.MethodPost, "/state", state, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Shutdown запрашивает graceful остановку удаленного экземпляра
+func (c *Client) Shutdown(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/shutdown", nil, nil)
+}
+
+// EnqueueJob ставит задание в очередь удаленного экземпляра. payload
+// передается обработчику как json.RawMessage
+func (c *Client) EnqueueJob(ctx context.Context, jobType string, payload interface{}) (*EnqueueResult, error) {
+	var result EnqueueResult
+	if err := c.do(ctx, http.MethodPost, "/jobs/"+url.PathEscape(jobType), payload, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Jobs возвращает состояние очереди заданий удаленного экземпляра
+func (c *Client) Jobs(ctx context.Context) (*JobsStatus, error) {
+	var status JobsStatus
+	if err := c.do(ctx, http.MethodGet, "/jobs", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Audit возвращает последние limit записей журнала действий удаленного
+// экземпляра (0 - значение по умолчанию сервера)
+func (c *Client) Audit(ctx context.Context, limit int) ([]audit.Entry, error) {
+	path := "/audit"
+	if limit > 0 {
+		path += "?limit=" + strconv.Itoa(limit)
+	}
+	var entries []audit.Entry
+	if err := c.do(ctx, http.MethodGet, path, nil, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Health возвращает результаты проверок состояния удаленного экземпляра
+func (c *Client) Health(ctx context.Context) (*health.Report, error) {
+	var report health.Report
+	if err := c.do(ctx, http.MethodGet, "/health", nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// MetricLabels возвращает количество значений метки имени по метрикам
+func (c *Client) MetricLabels(ctx context.Context) ([]metrics.LabelCardinality, error) {
+	var stats []metrics.LabelCardinality
+	if err := c.do(ctx, http.MethodGet, "/metrics/labels", nil, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// OpenAPI возвращает описание OpenAPI маршрутов удаленного экземпляра
+func (c *Client) OpenAPI(ctx context.Context) ([]byte, error) {
+	return c.doRaw(ctx, http.MethodGet, "/openapi.json", nil)
+}
+
+// do выполняет запрос с JSON телом in (если задано) и декодирует JSON ответ в out
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	data, err := c.doRaw(ctx, method, path, in)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// doRaw выполняет запрос с JSON телом in (если задано) и возвращает тело ответа
+func (c *Client) doRaw(ctx context.Context, method, path string, in interface{}) ([]byte, error) {
+	return c.request(ctx, method, path, nil, in)
+}
+
+// request выполняет запрос с дополнительными заголовками header
+func (c *Client) request(ctx context.Context, method, path string, header http.Header, in interface{}) ([]byte, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.actor != "" {
+		req.Header.Set(audit.ActorHeader, c.actor)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to contact service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		var e errorResponse
+		if json.Unmarshal(data, &e) == nil && e.Error != "" {
+			return nil, fmt.Errorf("%s: %s", resp.Status, e.Error)
+		}
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	return data, nil
+}
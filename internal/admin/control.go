@@ -0,0 +1,173 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"service-boilerplate/internal/audit"
+	"service-boilerplate/internal/buildinfo"
+	"service-boilerplate/internal/config"
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/logger"
+)
+
+// LogLevel тело запроса и ответа /log/level
+type LogLevel struct {
+	Level string `json:"level"`
+}
+
+// ShutdownResult ответ на запрос остановки
+type ShutdownResult struct {
+	Status string `json:"status"`
+}
+
+// StatusShuttingDown статус принятого запроса остановки
+const StatusShuttingDown = "shutting_down"
+
+// Status состояние экземпляра в ответе GET /status
+type Status struct {
+	Service       string    `json:"service"`
+	Version       string    `json:"version"`
+	InstanceID    string    `json:"instance_id,omitempty"`
+	Hostname      string    `json:"hostname,omitempty"`
+	IP            string    `json:"ip,omitempty"`
+	PID           int       `json:"pid"`
+	StartedAt     time.Time `json:"started_at"`
+	UptimeSeconds float64   `json:"uptime_seconds"`
+	LogLevel      string    `json:"log_level"`
+	Timers        int       `json:"timers"`
+	PausedTimers  int       `json:"paused_timers"`
+	ReadOnly      bool      `json:"read_only"`
+	// LastReload результат последней перезагрузки конфигурации
+	LastReload *config.ReloadStatus `json:"last_reload,omitempty"`
+}
+
+// handleStatus обрабатывает GET /status
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status := Status{
+		Service:       s.config.Service.Name,
+		Version:       buildinfo.Version,
+		InstanceID:    s.identity.InstanceID,
+		Hostname:      s.identity.Hostname,
+		IP:            s.identity.IP,
+		PID:           os.Getpid(),
+		StartedAt:     s.started.UTC(),
+		UptimeSeconds: time.Since(s.started).Seconds(),
+		LogLevel:      s.log.GetLevel().String(),
+		ReadOnly:      s.config.Service.ReadOnly,
+	}
+	if s.reloader != nil {
+		if last, ok := s.reloader.LastReload(); ok {
+			status.LastReload = &last
+		}
+	}
+	for _, timer := range s.scheduler.Snapshot().Timers {
+		status.Timers++
+		if timer.Paused {
+			status.PausedTimers++
+		}
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleGetLogLevel обрабатывает GET /log/level
+func (s *Server) handleGetLogLevel(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, LogLevel{Level: s.log.GetLevel().String()})
+}
+
+// handleSetLogLevel обрабатывает PUT /log/level
+func (s *Server) handleSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req LogLevel
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request body: " + err.Error()})
+		return
+	}
+	level, err := logger.ParseLevel(req.Level)
+	if err != nil || req.Level == "" {
+		s.record(r, audit.ActionSetLogLevel, req.Level, fmt.Errorf("invalid log level: %s", req.Level), nil)
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid log level: " + req.Level})
+		return
+	}
+
+	previous := s.log.GetLevel()
+	s.log.SetLevel(level)
+	s.record(r, audit.ActionSetLogLevel, level.String(), nil, map[string]interface{}{"previous": previous.String()})
+	s.log.Info("Admin action: set log level", map[string]interface{}{
+		"previous": previous.String(),
+		"level":    level.String(),
+		"remote":   r.RemoteAddr,
+	})
+	if s.events != nil {
+		s.events.Publish(events.TypeLogLevel, map[string]interface{}{
+			"previous": previous.String(),
+			"level":    level.String(),
+			"source":   "admin",
+		})
+	}
+	writeJSON(w, http.StatusOK, LogLevel{Level: level.String()})
+}
+
+// handleRecentLogs обрабатывает GET /logs/recent: последние записи из буфера
+// логгера. Параметр level задает минимальный уровень, limit - количество записей
+func (s *Server) handleRecentLogs(w http.ResponseWriter, r *http.Request) {
+	ring := s.log.Ring()
+	if ring == nil {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "recent logs are not available"})
+		return
+	}
+
+	query := r.URL.Query()
+	level := logger.DebugLevel
+	if v := query.Get("level"); v != "" {
+		var err error
+		if level, err = logger.ParseLevel(v); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid log level: " + v})
+			return
+		}
+	}
+	limit := 0
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid limit: " + v})
+			return
+		}
+		limit = n
+	}
+	writeJSON(w, http.StatusOK, ring.Entries(level, limit))
+}
+
+// handleConfig обрабатывает GET /config: действующая конфигурация
+// с ключами как в YAML и скрытыми секретами. После перезагрузки
+// возвращается примененная конфигурация, а не загруженная при запуске
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	cfg := s.config
+	if s.reloader != nil {
+		cfg = s.reloader.Config()
+	}
+	view, err := cfg.View()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+// handleShutdown обрабатывает POST /shutdown
+func (s *Server) handleShutdown(w http.ResponseWriter, r *http.Request) {
+	if s.shutdown == nil {
+		s.record(r, audit.ActionShutdown, "", errors.New("shutdown is not supported"), nil)
+		writeJSON(w, http.StatusNotImplemented, errorResponse{Error: "shutdown is not supported"})
+		return
+	}
+
+	s.log.Warn("Admin action: shutdown requested", map[string]interface{}{"remote": r.RemoteAddr})
+	s.record(r, audit.ActionShutdown, "", nil, nil)
+	writeJSON(w, http.StatusAccepted, ShutdownResult{Status: StatusShuttingDown})
+	s.shutdown("admin API request")
+}
@@ -0,0 +1,35 @@
+package admin
+
+import (
+	_ "embed"
+	"net/http"
+
+	"service-boilerplate/internal/health"
+)
+
+// dashboardPage страница GET /dashboard: состояние экземпляра, проверки,
+// таймеры и последние ошибки с кнопками паузы и запуска таймеров
+//
+//go:embed dashboard.html
+var dashboardPage []byte
+
+// SetHealth включает маршрут GET /health с результатами проверок состояния
+// для страницы /dashboard. Вызывается до Start
+func (s *Server) SetHealth(registry *health.Registry) {
+	s.health = registry
+}
+
+// handleHealth обрабатывает GET /health: результаты проверок состояния.
+// В отличие от /health сервера метрик ответ всегда 200, состояние - в теле
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.health.Run(r.Context()))
+}
+
+// handleDashboard обрабатывает GET /dashboard. Как и /docs, страница
+// не содержит данных экземпляра и отдается без токена: данные и действия
+// запрашиваются с токеном, введенным на странице
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; style-src 'unsafe-inline'")
+	w.Write(dashboardPage)
+}
@@ -0,0 +1,84 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"service-boilerplate/internal/events"
+)
+
+// eventBuffer буфер событий одного подключения; события сверх него отбрасываются
+const eventBuffer = 256
+
+// eventKeepAlive период комментариев, не дающих прокси закрыть простаивающий поток
+const eventKeepAlive = 15 * time.Second
+
+// SetEvents включает поток событий GET /events и публикацию изменений уровня логирования
+func (s *Server) SetEvents(bus *events.Bus) {
+	s.events = bus
+}
+
+// handleEvents обрабатывает GET /events: поток Server-Sent Events.
+// Параметр type (через запятую или повторенный) фильтрует события
+// по типу или префиксу типа, например ?type=timer,health
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if s.events == nil {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "event stream is not available"})
+		return
+	}
+
+	var types []string
+	for _, v := range r.URL.Query()["type"] {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, t)
+			}
+		}
+	}
+	sub := s.events.Subscribe(eventBuffer, types...)
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	s.log.Info("Admin event stream opened", map[string]interface{}{
+		"remote": r.RemoteAddr,
+		"types":  strings.Join(types, ","),
+	})
+	defer s.log.Info("Admin event stream closed", map[string]interface{}{
+		"remote":  r.RemoteAddr,
+		"dropped": sub.Dropped(),
+	})
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.stopping:
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case ev := <-sub.C:
+			data, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
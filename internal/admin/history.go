@@ -0,0 +1,46 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"service-boilerplate/internal/scheduler"
+)
+
+// RunStatus запуск таймера в ответе GET /timers/{name}/history
+type RunStatus struct {
+	RunID      string    `json:"run_id"`
+	Start      time.Time `json:"start"`
+	Duration   string    `json:"duration"`
+	DurationMs int64     `json:"duration_ms"`
+	Outcome    string    `json:"outcome"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// handleTimerHistory обрабатывает GET /timers/{name}/history: последние
+// запуски таймера, начиная с самого нового
+func (s *Server) handleTimerHistory(w http.ResponseWriter, r *http.Request) {
+	history, err := s.scheduler.GetTimerHistory(r.PathValue("name"))
+	if err != nil {
+		if errors.Is(err, scheduler.ErrTimerNotFound) {
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+
+	runs := make([]RunStatus, 0, len(history))
+	for _, rec := range history {
+		runs = append(runs, RunStatus{
+			RunID:      rec.RunID,
+			Start:      rec.Start.UTC(),
+			Duration:   rec.Duration.String(),
+			DurationMs: rec.Duration.Milliseconds(),
+			Outcome:    rec.Outcome,
+			Error:      rec.Error,
+		})
+	}
+	writeJSON(w, http.StatusOK, runs)
+}
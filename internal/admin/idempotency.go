@@ -0,0 +1,83 @@
+package admin
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// IdempotencyKeyHeader заголовок ключа идемпотентности POST /timers/{name}/trigger:
+// повторный запрос с тем же ключом не запускает таймер, а получает результат первого
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// ReplayedHeader отмечает ответ с результатом запуска по ранее полученному ключу
+const ReplayedHeader = "Idempotent-Replayed"
+
+// DefaultIdempotencyWindow сколько хранится результат запуска по ключу по умолчанию
+const DefaultIdempotencyWindow = 10 * time.Minute
+
+// idempotentRun запуск таймера по ключу идемпотентности. done закрывается,
+// когда запуск завершен; kept - результат сохранен для повторных запросов
+type idempotentRun struct {
+	key     string
+	timer   string
+	done    chan struct{}
+	kept    bool
+	result  TriggerResult
+	expires time.Time
+}
+
+// idempotencyCache результаты запусков по ключам идемпотентности
+type idempotencyCache struct {
+	window time.Duration
+
+	mu   sync.Mutex
+	runs map[string]*idempotentRun
+}
+
+// newIdempotencyCache создает кэш с временем хранения window
+// (<= 0 - DefaultIdempotencyWindow)
+func newIdempotencyCache(window time.Duration) *idempotencyCache {
+	if window <= 0 {
+		window = DefaultIdempotencyWindow
+	}
+	return &idempotencyCache{window: window, runs: make(map[string]*idempotentRun)}
+}
+
+// begin возвращает запуск по ключу key. true - запуска еще нет: он
+// зарегистрирован и должен быть выполнен вызывающим с последующим finish.
+// Ключ, использованный для другого таймера, - ошибка
+func (c *idempotencyCache) begin(key, timer string) (*idempotentRun, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, run := range c.runs {
+		if run.kept && now.After(run.expires) {
+			delete(c.runs, k)
+		}
+	}
+	if run, ok := c.runs[key]; ok {
+		if run.timer != timer {
+			return nil, false, fmt.Errorf("idempotency key %q was used for timer %s", key, run.timer)
+		}
+		return run, false, nil
+	}
+	run := &idempotentRun{key: key, timer: timer, done: make(chan struct{})}
+	c.runs[key] = run
+	return run, true, nil
+}
+
+// finish завершает запуск. result nil - таймер не запускался (не найден,
+// планировщик не работает): ключ освобождается, чтобы повтор запустил таймер
+func (c *idempotencyCache) finish(run *idempotentRun, result *TriggerResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if result != nil {
+		run.result = *result
+		run.kept = true
+		run.expires = time.Now().Add(c.window)
+	} else if c.runs[run.key] == run {
+		delete(c.runs, run.key)
+	}
+	close(run.done)
+}
@@ -0,0 +1,115 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"service-boilerplate/internal/audit"
+	"service-boilerplate/internal/jobs"
+)
+
+// maxJobPayload ограничивает размер тела POST /jobs/{type}
+const maxJobPayload = 1 << 20
+
+// EnqueueResult ответ на постановку задания в очередь
+type EnqueueResult struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// DeadLetter задание из dead-letter списка
+type DeadLetter struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Attempts   int       `json:"attempts"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	LastError  string    `json:"last_error"`
+}
+
+// JobsStatus состояние очереди заданий
+type JobsStatus struct {
+	Queued      int          `json:"queued"`
+	InFlight    int          `json:"in_flight"`
+	Retrying    int          `json:"retrying"`
+	DeadLetters []DeadLetter `json:"dead_letters"`
+}
+
+// handleJobs обрабатывает GET /jobs
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	stats := s.jobs.Stats()
+	status := JobsStatus{
+		Queued:      stats.Queued,
+		InFlight:    stats.InFlight,
+		Retrying:    stats.Retrying,
+		DeadLetters: []DeadLetter{},
+	}
+	for _, job := range s.jobs.DeadLetters() {
+		status.DeadLetters = append(status.DeadLetters, DeadLetter{
+			ID:         job.ID,
+			Type:       job.Type,
+			Attempts:   job.Attempt,
+			EnqueuedAt: job.EnqueuedAt.UTC(),
+			LastError:  job.LastError,
+		})
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleEnqueueJob обрабатывает POST /jobs/{type}. Тело запроса (JSON)
+// передается обработчику как json.RawMessage. В режиме только для чтения
+// очередь не запущена и задания не принимаются
+func (s *Server) handleEnqueueJob(w http.ResponseWriter, r *http.Request) {
+	jobType := r.PathValue("type")
+	if s.config.Service.ReadOnly {
+		err := errors.New("jobs are disabled in read-only mode")
+		s.record(r, audit.ActionEnqueueJob, jobType, err, nil)
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: err.Error()})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxJobPayload+1))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request body: " + err.Error()})
+		return
+	}
+	if len(body) > maxJobPayload {
+		writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{Error: "payload too large"})
+		return
+	}
+	var payload json.RawMessage
+	if len(body) > 0 {
+		if !json.Valid(body) {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "payload must be valid JSON"})
+			return
+		}
+		payload = body
+	}
+
+	id, err := s.jobs.Enqueue(jobType, payload)
+	var details map[string]interface{}
+	if id != "" {
+		details = map[string]interface{}{"job_id": id}
+	}
+	s.record(r, audit.ActionEnqueueJob, jobType, err, details)
+	switch {
+	case errors.Is(err, jobs.ErrUnknownType):
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+		return
+	case errors.Is(err, jobs.ErrQueueFull), errors.Is(err, jobs.ErrClosed):
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{Error: err.Error()})
+		return
+	case err != nil:
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+
+	s.log.Info("Admin action: enqueue job", map[string]interface{}{
+		"job_id":   id,
+		"job_type": jobType,
+		"remote":   r.RemoteAddr,
+	})
+	writeJSON(w, http.StatusAccepted, EnqueueResult{ID: id, Type: jobType})
+}
@@ -0,0 +1,19 @@
+package admin
+
+import (
+	"net/http"
+
+	"service-boilerplate/internal/metrics"
+)
+
+// LabelSource статистика значений меток метрик (реализуется *metrics.Server)
+type LabelSource interface {
+	// LabelCardinality возвращает количество значений метки имени по метрикам
+	LabelCardinality() []metrics.LabelCardinality
+}
+
+// handleMetricLabels обрабатывает GET /metrics/labels: количество значений
+// метки имени каждой метрики, лимит и число наблюдений сверх лимита
+func (s *Server) handleMetricLabels(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.labels.LabelCardinality())
+}
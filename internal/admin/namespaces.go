@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"service-boilerplate/internal/audit"
+	"service-boilerplate/internal/scheduler"
+)
+
+// NamespaceStatus пространство имен таймеров в ответе GET /namespaces
+type NamespaceStatus struct {
+	Name              string `json:"name"`
+	Timers            int    `json:"timers"`
+	Running           int    `json:"running"`
+	MaxConcurrentRuns int    `json:"max_concurrent_runs"`
+	Enabled           bool   `json:"enabled"`
+}
+
+// handleNamespaces обрабатывает GET /namespaces
+func (s *Server) handleNamespaces(w http.ResponseWriter, r *http.Request) {
+	infos := s.scheduler.ListNamespaces()
+	namespaces := make([]NamespaceStatus, 0, len(infos))
+	for _, info := range infos {
+		namespaces = append(namespaces, namespaceStatus(info))
+	}
+	writeJSON(w, http.StatusOK, namespaces)
+}
+
+// namespaceStatus преобразует scheduler.NamespaceInfo в NamespaceStatus
+func namespaceStatus(info scheduler.NamespaceInfo) NamespaceStatus {
+	return NamespaceStatus{
+		Name:              info.Name,
+		Timers:            info.Timers,
+		Running:           info.Running,
+		MaxConcurrentRuns: info.MaxConcurrentRuns,
+		Enabled:           info.Enabled,
+	}
+}
+
+// handleEnableNamespace обрабатывает POST /namespaces/{name}/enable
+func (s *Server) handleEnableNamespace(w http.ResponseWriter, r *http.Request) {
+	s.setNamespaceEnabled(w, r, s.scheduler.EnableNamespace, "enable", audit.ActionEnableNamespace)
+}
+
+// handleDisableNamespace обрабатывает POST /namespaces/{name}/disable
+func (s *Server) handleDisableNamespace(w http.ResponseWriter, r *http.Request) {
+	s.setNamespaceEnabled(w, r, s.scheduler.DisableNamespace, "disable", audit.ActionDisableNamespace)
+}
+
+// setNamespaceEnabled выполняет enable/disable и возвращает новое
+// состояние пространства имен
+func (s *Server) setNamespaceEnabled(w http.ResponseWriter, r *http.Request, action func(string) error, actionName, auditAction string) {
+	name := r.PathValue("name")
+	err := action(name)
+	s.record(r, auditAction, name, err, nil)
+	if err != nil {
+		if errors.Is(err, scheduler.ErrNamespaceNotFound) {
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+
+	s.log.Info("Admin action: "+actionName+" namespace", map[string]interface{}{
+		"namespace": name,
+		"remote":    r.RemoteAddr,
+	})
+	for _, info := range s.scheduler.ListNamespaces() {
+		if info.Name == name {
+			writeJSON(w, http.StatusOK, namespaceStatus(info))
+			return
+		}
+	}
+	writeJSON(w, http.StatusNotFound, errorResponse{Error: scheduler.ErrNamespaceNotFound.Error()})
+}
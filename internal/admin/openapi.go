@@ -0,0 +1,366 @@
+package admin
+
+import (
+	_ "embed"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"service-boilerplate/internal/audit"
+	"service-boilerplate/internal/buildinfo"
+	"service-boilerplate/internal/config"
+	"service-boilerplate/internal/health"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+)
+
+// docsPage страница GET /docs: список операций из GET /openapi.json
+// с формой запроса
+//
+//go:embed docs.html
+var docsPage []byte
+
+// route маршрут admin API и его описание для GET /openapi.json
+type route struct {
+	method  string
+	path    string
+	handler http.HandlerFunc
+	summary string
+	// query параметры строки запроса, headers - заголовки запроса
+	query   []param
+	headers []param
+	// request тип JSON тела запроса (nil - без тела)
+	request reflect.Type
+	// status код успешного ответа, response - тип его JSON тела
+	status   int
+	response reflect.Type
+	// stream тип содержимого потокового или не JSON ответа
+	stream string
+	// errors коды ответов с errorResponse
+	errors []int
+}
+
+// param параметр строки или заголовок запроса
+type param struct {
+	name        string
+	typ         string
+	description string
+}
+
+// typeOf возвращает тип значения v для описания маршрута
+func typeOf(v interface{}) reflect.Type {
+	return reflect.TypeOf(v)
+}
+
+// anyJSON тип произвольного JSON значения
+var anyJSON = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// routeTable возвращает маршруты admin API с учетом включенных компонентов.
+// По таблице регистрируются обработчики и строится описание OpenAPI,
+// поэтому описание не расходится с маршрутами
+func (s *Server) routeTable() []route {
+	routes := []route{
+		{method: "GET", path: "/status", handler: s.handleStatus, summary: "Instance status",
+			status: http.StatusOK, response: typeOf(Status{})},
+		{method: "GET", path: "/timers", handler: s.handleListTimers, summary: "List timers",
+			status: http.StatusOK, response: typeOf([]TimerStatus{})},
+		{method: "GET", path: "/schedule", handler: s.handleSchedule, summary: "Effective schedule: next fire times of every timer",
+			query: []param{
+				{"count", "integer", "fire times per timer (1-1000, default 10)"},
+				{"format", "string", "ical for an iCalendar file (text/calendar)"},
+			},
+			status: http.StatusOK, response: typeOf([]ScheduleEntry{}), errors: []int{http.StatusBadRequest}},
+		{method: "POST", path: "/timers/{name}/trigger", handler: s.handleTrigger, summary: "Run a timer now and wait for it to finish",
+			headers: []param{
+				{IdempotencyKeyHeader, "string", "repeated requests with the same key get the result of the first run"},
+			},
+			status: http.StatusOK, response: typeOf(TriggerResult{}),
+			errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusServiceUnavailable}},
+		{method: "POST", path: "/timers/{name}/pause", handler: s.handlePause, summary: "Pause scheduled runs of a timer",
+			status: http.StatusOK, response: typeOf(TimerStatus{}), errors: []int{http.StatusNotFound}},
+		{method: "POST", path: "/timers/{name}/resume", handler: s.handleResume, summary: "Resume scheduled runs of a timer",
+			status: http.StatusOK, response: typeOf(TimerStatus{}), errors: []int{http.StatusNotFound}},
+		{method: "GET", path: "/timers/{name}/history", handler: s.handleTimerHistory, summary: "Recent runs of a timer, newest first",
+			status: http.StatusOK, response: typeOf([]RunStatus{}), errors: []int{http.StatusNotFound}},
+		{method: "GET", path: "/namespaces", handler: s.handleNamespaces, summary: "List timer namespaces",
+			status: http.StatusOK, response: typeOf([]NamespaceStatus{})},
+		{method: "POST", path: "/namespaces/{name}/enable", handler: s.handleEnableNamespace, summary: "Resume runs of all timers in a namespace",
+			status: http.StatusOK, response: typeOf(NamespaceStatus{}), errors: []int{http.StatusNotFound}},
+		{method: "POST", path: "/namespaces/{name}/disable", handler: s.handleDisableNamespace, summary: "Stop runs of all timers in a namespace",
+			status: http.StatusOK, response: typeOf(NamespaceStatus{}), errors: []int{http.StatusNotFound}},
+		{method: "GET", path: "/log/level", handler: s.handleGetLogLevel, summary: "Current log level",
+			status: http.StatusOK, response: typeOf(LogLevel{})},
+		{method: "PUT", path: "/log/level", handler: s.handleSetLogLevel, summary: "Change log level",
+			request: typeOf(LogLevel{}), status: http.StatusOK, response: typeOf(LogLevel{}), errors: []int{http.StatusBadRequest}},
+		{method: "GET", path: "/logs/recent", handler: s.handleRecentLogs, summary: "Recent log entries from the in-memory buffer",
+			query: []param{
+				{"level", "string", "minimal level"},
+				{"limit", "integer", "number of entries (0 - all)"},
+			},
+			status: http.StatusOK, response: typeOf([]logger.LogEntry{}), errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{method: "GET", path: "/config", handler: s.handleConfig, summary: "Effective configuration with YAML keys and redacted secrets",
+			status: http.StatusOK, response: typeOf(config.Config{})},
+		{method: "GET", path: "/state", handler: s.handleExportState, summary: "Export runtime state: timer pauses and last runs, namespaces, log level",
+			query:  []param{{"format", "string", "yaml for a YAML document (application/yaml)"}},
+			status: http.StatusOK, response: typeOf(RuntimeState{})},
+		{method: "POST", path: "/state", handler: s.handleImportState, summary: "Import runtime state exported by GET /state (JSON or YAML body)",
+			request: typeOf(RuntimeState{}), status: http.StatusOK, response: typeOf(ImportResult{}),
+			errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusRequestEntityTooLarge}},
+	}
+	if s.reloader != nil {
+		routes = append(routes, route{method: "POST", path: "/config/reload", handler: s.handleReloadConfig, summary: "Reload configuration file",
+			status: http.StatusOK, response: typeOf(config.ReloadStatus{}),
+			errors: []int{http.StatusUnprocessableEntity, http.StatusServiceUnavailable}})
+	}
+	routes = append(routes,
+		route{method: "POST", path: "/shutdown", handler: s.handleShutdown, summary: "Start graceful shutdown",
+			status: http.StatusAccepted, response: typeOf(ShutdownResult{}), errors: []int{http.StatusNotImplemented}},
+		route{method: "GET", path: "/events", handler: s.handleEvents, summary: "Server-Sent Events stream",
+			query:  []param{{"type", "string", "comma-separated event types or prefixes"}},
+			status: http.StatusOK, stream: "text/event-stream", errors: []int{http.StatusNotFound}},
+	)
+	if s.audit != nil {
+		routes = append(routes, route{method: "GET", path: "/audit", handler: s.handleAudit, summary: "Recent operator actions",
+			query:  []param{{"limit", "integer", "number of entries (0 - all, default 100)"}},
+			status: http.StatusOK, response: typeOf([]audit.Entry{}), errors: []int{http.StatusBadRequest}})
+	}
+	if s.health != nil {
+		routes = append(routes, route{method: "GET", path: "/health", handler: s.handleHealth, summary: "Health check results",
+			status: http.StatusOK, response: typeOf(health.Report{})})
+	}
+	if s.labels != nil {
+		routes = append(routes, route{method: "GET", path: "/metrics/labels", handler: s.handleMetricLabels, summary: "Name label cardinality of metrics",
+			status: http.StatusOK, response: typeOf([]metrics.LabelCardinality{})})
+	}
+	if s.jobs != nil {
+		routes = append(routes,
+			route{method: "GET", path: "/jobs", handler: s.handleJobs, summary: "Job queue status and dead letters",
+				status: http.StatusOK, response: typeOf(JobsStatus{})},
+			route{method: "POST", path: "/jobs/{type}", handler: s.handleEnqueueJob, summary: "Enqueue a job with a JSON payload",
+				request: anyJSON, status: http.StatusAccepted, response: typeOf(EnqueueResult{}),
+				errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusRequestEntityTooLarge, http.StatusServiceUnavailable}},
+		)
+	}
+	return routes
+}
+
+// handleOpenAPI обрабатывает GET /openapi.json: описание OpenAPI 3.0
+// маршрутов экземпляра
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.openAPI())
+}
+
+// handleDocs обрабатывает GET /docs. Страница не содержит данных экземпляра
+// и отдается без токена: токен вводится на странице для запросов к API
+func (s *Server) handleDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; style-src 'unsafe-inline'")
+	w.Write(docsPage)
+}
+
+// pathParam имена параметров пути маршрута
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// openAPI строит описание OpenAPI по таблице маршрутов
+func (s *Server) openAPI() map[string]interface{} {
+	gen := &schemaGen{schemas: map[string]interface{}{}, names: map[string]reflect.Type{}}
+	errorRef := gen.schema(typeOf(errorResponse{}), "json")
+
+	paths := map[string]interface{}{}
+	for _, rt := range s.routeTable() {
+		op := map[string]interface{}{
+			"summary":     rt.summary,
+			"operationId": operationID(rt.method, rt.path),
+		}
+
+		var params []interface{}
+		for _, m := range pathParam.FindAllStringSubmatch(rt.path, -1) {
+			params = append(params, map[string]interface{}{
+				"name": m[1], "in": "path", "required": true,
+				"schema": map[string]interface{}{"type": "string"},
+			})
+		}
+		for _, q := range rt.query {
+			params = append(params, map[string]interface{}{
+				"name": q.name, "in": "query", "description": q.description,
+				"schema": map[string]interface{}{"type": q.typ},
+			})
+		}
+		for _, h := range rt.headers {
+			params = append(params, map[string]interface{}{
+				"name": h.name, "in": "header", "description": h.description,
+				"schema": map[string]interface{}{"type": h.typ},
+			})
+		}
+		if params != nil {
+			op["parameters"] = params
+		}
+		if rt.request != nil {
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  jsonContent(gen.schema(rt.request, "json")),
+			}
+		}
+
+		ok := map[string]interface{}{"description": http.StatusText(rt.status)}
+		switch {
+		case rt.stream != "":
+			ok["content"] = map[string]interface{}{rt.stream: map[string]interface{}{}}
+		case rt.response != nil:
+			// Конфигурация отдается с ключами YAML
+			tag := "json"
+			if rt.response == typeOf(config.Config{}) {
+				tag = "yaml"
+			}
+			ok["content"] = jsonContent(gen.schema(rt.response, tag))
+		}
+		responses := map[string]interface{}{strconv.Itoa(rt.status): ok}
+		codes := rt.errors
+		if s.token != "" {
+			codes = append(codes[:len(codes):len(codes)], http.StatusUnauthorized)
+		}
+		for _, code := range codes {
+			responses[strconv.Itoa(code)] = map[string]interface{}{
+				"description": http.StatusText(code),
+				"content":     jsonContent(errorRef),
+			}
+		}
+		op["responses"] = responses
+
+		item, _ := paths[rt.path].(map[string]interface{})
+		if item == nil {
+			item = map[string]interface{}{}
+			paths[rt.path] = item
+		}
+		item[strings.ToLower(rt.method)] = op
+	}
+
+	components := map[string]interface{}{"schemas": gen.schemas}
+	spec := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   s.config.Service.Name + " admin API",
+			"version": buildinfo.Version,
+		},
+		"paths":      paths,
+		"components": components,
+	}
+	if s.token != "" {
+		components["securitySchemes"] = map[string]interface{}{
+			"bearer": map[string]interface{}{"type": "http", "scheme": "bearer"},
+		}
+		spec["security"] = []interface{}{map[string]interface{}{"bearer": []string{}}}
+	}
+	return spec
+}
+
+// operationID возвращает идентификатор операции: POST /timers/{name}/trigger -
+// post_timers_name_trigger
+func operationID(method, path string) string {
+	return strings.ToLower(method) + strings.NewReplacer("/", "_", "{", "", "}", "").Replace(path)
+}
+
+// jsonContent возвращает content с JSON схемой
+func jsonContent(schema interface{}) map[string]interface{} {
+	return map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+}
+
+// schemaGen строит JSON схемы типов Go. Именованные структуры
+// выносятся в components/schemas и подставляются ссылкой
+type schemaGen struct {
+	schemas map[string]interface{}
+	names   map[string]reflect.Type
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schema возвращает схему типа t. tag - тег структуры с именами полей
+// (json или yaml)
+func (g *schemaGen) schema(t reflect.Type, tag string) map[string]interface{} {
+	switch {
+	case t == anyJSON:
+		return map[string]interface{}{}
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schema(t.Elem(), tag)
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem(), tag)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem(), tag)}
+	case reflect.Struct:
+		return g.structRef(t, tag)
+	}
+	return map[string]interface{}{}
+}
+
+// structRef добавляет схему структуры в components и возвращает ссылку на нее
+func (g *schemaGen) structRef(t reflect.Type, tag string) map[string]interface{} {
+	name := t.Name()
+	if name == "" {
+		return g.object(t, tag)
+	}
+	name = strings.ToUpper(name[:1]) + name[1:]
+	// Одноименные типы разных пакетов различаются префиксом пакета
+	if other, ok := g.names[name]; ok && other != t {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	ref := map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	if _, ok := g.names[name]; ok {
+		return ref
+	}
+	// Имя занимается до обхода полей: рекурсивные типы получают ссылку
+	g.names[name] = t
+	g.schemas[name] = g.object(t, tag)
+	return ref
+}
+
+// object возвращает схему полей структуры
+func (g *schemaGen) object(t reflect.Type, tag string) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+			if tag == "yaml" {
+				name = strings.ToLower(name)
+			}
+		}
+		properties[name] = g.schema(f.Type, tag)
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Ptr {
+			required = append(required, name)
+		}
+	}
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if required != nil {
+		schema["required"] = required
+	}
+	return schema
+}
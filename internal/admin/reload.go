@@ -0,0 +1,46 @@
+package admin
+
+import (
+	"net/http"
+
+	"service-boilerplate/internal/audit"
+	"service-boilerplate/internal/config"
+)
+
+// Reloader перезагрузка конфигурации запущенного экземпляра (реализуется *app.App)
+type Reloader interface {
+	// Reload загружает, проверяет и применяет конфигурацию
+	Reload() (config.ReloadStatus, error)
+	// LastReload возвращает результат последней перезагрузки
+	LastReload() (config.ReloadStatus, bool)
+	// Config возвращает действующую конфигурацию с учетом перезагрузок
+	Config() *config.Config
+}
+
+// SetReloader включает маршрут POST /config/reload и поле last_reload
+// в GET /status. Вызывается до Start
+func (s *Server) SetReloader(r Reloader) {
+	s.reloader = r
+}
+
+// handleReloadConfig обрабатывает POST /config/reload: перечитывает файл
+// конфигурации и возвращает измененные ключи. Конфигурация, не прошедшая
+// проверку, не применяется и возвращается 422
+func (s *Server) handleReloadConfig(w http.ResponseWriter, r *http.Request) {
+	status, err := s.reloader.Reload()
+	s.record(r, audit.ActionReloadConfig, "", err, map[string]interface{}{"changed": len(status.Changes)})
+	switch {
+	case err != nil && status.Result == "":
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{Error: err.Error()})
+		return
+	case err != nil:
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: err.Error()})
+		return
+	}
+
+	s.log.Info("Admin action: reload config", map[string]interface{}{
+		"changed": len(status.Changes),
+		"remote":  r.RemoteAddr,
+	})
+	writeJSON(w, http.StatusOK, status)
+}
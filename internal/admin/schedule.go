@@ -0,0 +1,112 @@
+package admin
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ограничения параметра count GET /schedule
+const (
+	defaultScheduleCount = 10
+	maxScheduleCount     = 1000
+)
+
+// ScheduleEntry ближайшие запуски таймера в ответе GET /schedule
+type ScheduleEntry struct {
+	Timer     string      `json:"timer"`
+	Interval  string      `json:"interval"`
+	State     string      `json:"state"`
+	FireTimes []time.Time `json:"fire_times"`
+}
+
+// handleSchedule обрабатывает GET /schedule: ближайшие count запусков
+// каждого таймера. format=ical (или Accept: text/calendar) возвращает
+// календарь iCalendar с событием на каждый запуск
+func (s *Server) handleSchedule(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	count := defaultScheduleCount
+	if v := query.Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxScheduleCount {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid count: %s (1-%d)", v, maxScheduleCount)})
+			return
+		}
+		count = n
+	}
+
+	infos := s.scheduler.ListTimers()
+	entries := make([]ScheduleEntry, 0, len(infos))
+	for _, info := range infos {
+		entry := ScheduleEntry{
+			Timer:     info.Name,
+			Interval:  info.Interval.String(),
+			State:     info.State,
+			FireTimes: []time.Time{},
+		}
+		for _, at := range info.FireTimes(count) {
+			entry.FireTimes = append(entry.FireTimes, at.UTC())
+		}
+		entries = append(entries, entry)
+	}
+
+	if query.Get("format") == "ical" || strings.Contains(r.Header.Get("Accept"), "text/calendar") {
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="schedule.ics"`)
+		writeICal(w, s.config.Service.Name, entries, time.Now())
+		return
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// writeICal записывает запуски таймеров в формате iCalendar (RFC 5545).
+// Длительность запуска заранее неизвестна, поэтому события без DTEND
+func writeICal(w io.Writer, service string, entries []ScheduleEntry, now time.Time) {
+	const layout = "20060102T150405Z"
+
+	type event struct {
+		timer    string
+		interval string
+		at       time.Time
+	}
+	var events []event
+	for _, e := range entries {
+		for _, at := range e.FireTimes {
+			events = append(events, event{timer: e.Timer, interval: e.Interval, at: at})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].at.Before(events[j].at) })
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//" + icalText(service) + "//schedule//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:" + icalText(service) + " timers",
+	}
+	for _, e := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:%s-%d@%s", icalText(e.timer), e.at.Unix(), icalText(service)),
+			"DTSTAMP:"+now.UTC().Format(layout),
+			"DTSTART:"+e.at.UTC().Format(layout),
+			"SUMMARY:"+icalText("timer "+e.timer),
+			"DESCRIPTION:"+icalText("every "+e.interval),
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		io.WriteString(w, line+"\r\n")
+	}
+}
+
+// icalText экранирует значение текстового свойства iCalendar
+func icalText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,227 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"service-boilerplate/internal/audit"
+	"service-boilerplate/internal/buildinfo"
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/scheduler"
+)
+
+// maxStateSize ограничение тела POST /state
+const maxStateSize = 1 << 20
+
+// RuntimeState runtime-состояние экземпляра в GET /state и POST /state:
+// паузы и время последнего запуска таймеров, включенные пространства
+// имен и уровень логирования. Переносится на другой экземпляр
+// при миграции или для воспроизведения окружения
+type RuntimeState struct {
+	Service    string           `json:"service" yaml:"service"`
+	Version    string           `json:"version,omitempty" yaml:"version,omitempty"`
+	ExportedAt time.Time        `json:"exported_at" yaml:"exported_at"`
+	LogLevel   string           `json:"log_level,omitempty" yaml:"log_level,omitempty"`
+	Timers     []TimerState     `json:"timers" yaml:"timers"`
+	Namespaces []NamespaceState `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+}
+
+// TimerState состояние таймера в RuntimeState. Interval справочный:
+// при импорте интервал не меняется
+type TimerState struct {
+	Name     string     `json:"name" yaml:"name"`
+	Interval string     `json:"interval,omitempty" yaml:"interval,omitempty"`
+	Paused   bool       `json:"paused" yaml:"paused"`
+	LastRun  *time.Time `json:"last_run,omitempty" yaml:"last_run,omitempty"`
+}
+
+// NamespaceState состояние пространства имен в RuntimeState
+type NamespaceState struct {
+	Name    string `json:"name" yaml:"name"`
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+}
+
+// ImportResult ответ POST /state. Skipped - таймеры и пространства имен,
+// которых нет в этом экземпляре (timer:<имя>, namespace:<имя>)
+type ImportResult struct {
+	Timers     int      `json:"timers"`
+	Namespaces int      `json:"namespaces"`
+	LogLevel   string   `json:"log_level,omitempty"`
+	Skipped    []string `json:"skipped,omitempty"`
+}
+
+// handleExportState обрабатывает GET /state. format=yaml (или Accept:
+// application/yaml) возвращает состояние в YAML
+func (s *Server) handleExportState(w http.ResponseWriter, r *http.Request) {
+	state := RuntimeState{
+		Service:    s.config.Service.Name,
+		Version:    buildinfo.Version,
+		ExportedAt: time.Now().UTC(),
+		LogLevel:   s.log.GetLevel().String(),
+		Timers:     []TimerState{},
+	}
+	// Пауза берется из снимка: в ListTimers выполняющийся или отключенный
+	// таймер показан в своем состоянии, даже если он на паузе
+	for _, timer := range s.scheduler.Snapshot().Timers {
+		state.Timers = append(state.Timers, TimerState{
+			Name:     timer.Name,
+			Interval: timer.Interval.String(),
+			Paused:   timer.Paused,
+			LastRun:  timePtr(timer.LastRun),
+		})
+	}
+	for _, info := range s.scheduler.ListNamespaces() {
+		state.Namespaces = append(state.Namespaces, NamespaceState{Name: info.Name, Enabled: info.Enabled})
+	}
+
+	if r.URL.Query().Get("format") == "yaml" || isYAML(r.Header.Get("Accept")) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.WriteHeader(http.StatusOK)
+		yaml.NewEncoder(w).Encode(state)
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+// handleImportState обрабатывает POST /state: применяет состояние,
+// экспортированное GET /state этого или другого экземпляра. Тело
+// в JSON или YAML (Content-Type: application/yaml). Пауза таймера
+// заменяется импортированной, время последнего запуска берется более
+// позднее, счетчики panic и пропущенных тиков не меняются. В режиме
+// только для чтения состояние не импортируется
+func (s *Server) handleImportState(w http.ResponseWriter, r *http.Request) {
+	if s.config.Service.ReadOnly {
+		err := errors.New("state import is disabled in read-only mode")
+		s.record(r, audit.ActionImportState, "", err, nil)
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: err.Error()})
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxStateSize+1))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request body: " + err.Error()})
+		return
+	}
+	if len(body) > maxStateSize {
+		writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{Error: "state too large"})
+		return
+	}
+	var state RuntimeState
+	if isYAML(r.Header.Get("Content-Type")) {
+		err = yaml.Unmarshal(body, &state)
+	} else {
+		err = json.Unmarshal(body, &state)
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid state: " + err.Error()})
+		return
+	}
+	var level logger.Level
+	if state.LogLevel != "" {
+		if level, err = logger.ParseLevel(state.LogLevel); err != nil {
+			s.record(r, audit.ActionImportState, state.Service, err, nil)
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid log level: " + state.LogLevel})
+			return
+		}
+	}
+
+	result, err := s.importState(state)
+	if err == nil && state.LogLevel != "" {
+		previous := s.log.GetLevel()
+		s.log.SetLevel(level)
+		result.LogLevel = level.String()
+		if s.events != nil {
+			s.events.Publish(events.TypeLogLevel, map[string]interface{}{
+				"previous": previous.String(),
+				"level":    level.String(),
+				"source":   "admin",
+			})
+		}
+	}
+	s.record(r, audit.ActionImportState, state.Service, err, map[string]interface{}{
+		"timers":     result.Timers,
+		"namespaces": result.Namespaces,
+		"skipped":    result.Skipped,
+	})
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+
+	s.log.Info("Admin action: import state", map[string]interface{}{
+		"source":      state.Service,
+		"exported_at": state.ExportedAt.Format(time.RFC3339),
+		"timers":      result.Timers,
+		"namespaces":  result.Namespaces,
+		"skipped":     result.Skipped,
+		"remote":      r.RemoteAddr,
+	})
+	writeJSON(w, http.StatusOK, result)
+}
+
+// importState применяет таймеры и пространства имен состояния
+func (s *Server) importState(state RuntimeState) (ImportResult, error) {
+	result := ImportResult{}
+	imported := make(map[string]TimerState, len(state.Timers))
+	for _, ts := range state.Timers {
+		imported[ts.Name] = ts
+	}
+
+	// Снимок строится от текущего состояния, чтобы Restore не менял счетчики
+	snap := s.scheduler.Snapshot()
+	snap.TakenAt = time.Now().UTC()
+	timers := snap.Timers[:0]
+	for _, t := range snap.Timers {
+		ts, ok := imported[t.Name]
+		if !ok {
+			continue
+		}
+		delete(imported, t.Name)
+		t.Paused = ts.Paused
+		if ts.LastRun != nil && ts.LastRun.After(t.LastRun) {
+			t.LastRun = *ts.LastRun
+		}
+		timers = append(timers, t)
+	}
+	snap.Timers = timers
+	for _, ts := range state.Timers {
+		if _, ok := imported[ts.Name]; ok {
+			result.Skipped = append(result.Skipped, "timer:"+ts.Name)
+		}
+	}
+	if len(timers) > 0 {
+		if err := s.scheduler.Restore(snap); err != nil {
+			return result, fmt.Errorf("failed to restore timers: %w", err)
+		}
+	}
+	result.Timers = len(timers)
+
+	for _, ns := range state.Namespaces {
+		action := s.scheduler.DisableNamespace
+		if ns.Enabled {
+			action = s.scheduler.EnableNamespace
+		}
+		err := action(ns.Name)
+		if errors.Is(err, scheduler.ErrNamespaceNotFound) {
+			result.Skipped = append(result.Skipped, "namespace:"+ns.Name)
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		result.Namespaces++
+	}
+	return result, nil
+}
+
+// isYAML проверяет, что тип содержимого - YAML
+func isYAML(contentType string) bool {
+	return strings.Contains(contentType, "yaml")
+}
@@ -0,0 +1,367 @@
+// Package alerting отправляет оповещения операторам (webhook, Slack, email)
+// о повторяющихся сбоях: таймер исчерпал лимит перезапусков после panic,
+// проверка здоровья не проходит дольше порога, сработал watchdog,
+// перезагруженная конфигурация откачена.
+// Повторные оповещения с тем же ключом подавляются на время cooldown
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/health"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+)
+
+// queueSize количество оповещений, ожидающих отправки
+const queueSize = 64
+
+// Alert оповещение. Key определяет, какие оповещения считаются повторными
+type Alert struct {
+	Key      string    `json:"key"`
+	Title    string    `json:"title"`
+	Text     string    `json:"text"`
+	Source   string    `json:"source,omitempty"`
+	Resolved bool      `json:"resolved,omitempty"`
+	Time     time.Time `json:"time"`
+	// Suppressed сколько повторов было подавлено с предыдущей отправки
+	Suppressed int `json:"suppressed,omitempty"`
+}
+
+// Subject возвращает однострочное описание оповещения для заголовков
+func (a Alert) Subject() string {
+	status := "ALERT"
+	if a.Resolved {
+		status = "RESOLVED"
+	}
+	if a.Source != "" {
+		return fmt.Sprintf("[%s] %s: %s", status, a.Source, a.Title)
+	}
+	return fmt.Sprintf("[%s] %s", status, a.Title)
+}
+
+// Body возвращает текст оповещения с числом подавленных повторов
+func (a Alert) Body() string {
+	if a.Suppressed > 0 {
+		return fmt.Sprintf("%s\n\n(%d similar alerts suppressed)", a.Text, a.Suppressed)
+	}
+	return a.Text
+}
+
+// Notifier канал доставки оповещений
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, a Alert) error
+}
+
+// Config содержит настройки оповещений
+type Config struct {
+	// Source имя экземпляра сервиса в оповещениях
+	Source string
+	// Cooldown минимальный интервал между оповещениями с одним ключом
+	Cooldown time.Duration
+	// HealthThreshold сколько проверка должна не проходить до оповещения
+	HealthThreshold time.Duration
+	// Timeout ограничивает отправку через один канал
+	Timeout time.Duration
+	// Notifiers каналы доставки
+	Notifiers []Notifier
+}
+
+// Manager подписывается на события сервиса и рассылает оповещения.
+// Реализует task.Task
+type Manager struct {
+	log     *logger.Logger
+	metrics *metrics.Server
+	bus     *events.Bus
+	cfg     Config
+	queue   chan Alert
+	now     func() time.Time
+
+	mu         sync.Mutex
+	sent       map[string]time.Time
+	suppressed map[string]int
+	failing    map[string]*time.Timer
+	alerted    map[string]bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New создает менеджер оповещений для событий шины bus
+func New(log *logger.Logger, metricsServer *metrics.Server, bus *events.Bus, cfg Config) *Manager {
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 15 * time.Minute
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &Manager{
+		log:        log,
+		metrics:    metricsServer,
+		bus:        bus,
+		cfg:        cfg,
+		queue:      make(chan Alert, queueSize),
+		now:        time.Now,
+		sent:       make(map[string]time.Time),
+		suppressed: make(map[string]int),
+		failing:    make(map[string]*time.Timer),
+		alerted:    make(map[string]bool),
+	}
+}
+
+// Name возвращает имя задачи
+func (m *Manager) Name() string {
+	return "alerting"
+}
+
+// AfterStart подписывается на события и запускает отправку оповещений
+func (m *Manager) AfterStart(ctx context.Context) error {
+	var sub *events.Subscription
+	if m.bus != nil {
+		sub = m.bus.Subscribe(queueSize, events.TypeTimerDisabled, events.TypeTimerThrottled, events.TypeHealthChanged, events.TypeWatchdogFired, events.TypeConfigRollback)
+	}
+
+	ctx, m.cancel = context.WithCancel(ctx)
+	m.done = make(chan struct{})
+	go m.loop(ctx, sub)
+
+	names := make([]string, 0, len(m.cfg.Notifiers))
+	for _, n := range m.cfg.Notifiers {
+		names = append(names, n.Name())
+	}
+	m.log.Info("Alerting started", map[string]interface{}{
+		"notifiers":        names,
+		"cooldown":         m.cfg.Cooldown.String(),
+		"health_threshold": m.cfg.HealthThreshold.String(),
+	})
+	return nil
+}
+
+// BeforeStop отправляет оповещения из очереди и останавливает рассылку
+func (m *Manager) BeforeStop(ctx context.Context) error {
+	if m.cancel == nil {
+		return nil
+	}
+	m.cancel()
+
+	m.mu.Lock()
+	for check, timer := range m.failing {
+		timer.Stop()
+		delete(m.failing, check)
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-m.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	for {
+		select {
+		case a := <-m.queue:
+			m.deliver(ctx, a)
+		default:
+			return nil
+		}
+	}
+}
+
+// Send ставит оповещение в очередь отправки. Возвращает false, если
+// оповещение с тем же ключом уже отправлялось в течение cooldown.
+// Оповещения о восстановлении не подавляются
+func (m *Manager) Send(a Alert) bool {
+	if a.Time.IsZero() {
+		a.Time = m.now()
+	}
+	if a.Source == "" {
+		a.Source = m.cfg.Source
+	}
+
+	m.mu.Lock()
+	if last, ok := m.sent[a.Key]; ok && !a.Resolved && a.Time.Sub(last) < m.cfg.Cooldown {
+		m.suppressed[a.Key]++
+		m.mu.Unlock()
+		if m.metrics != nil {
+			m.metrics.RecordAlertSuppressed()
+		}
+		m.log.Debug("Alert suppressed by cooldown", map[string]interface{}{"key": a.Key})
+		return false
+	}
+	if !a.Resolved {
+		m.sent[a.Key] = a.Time
+		a.Suppressed = m.suppressed[a.Key]
+		delete(m.suppressed, a.Key)
+	}
+	m.mu.Unlock()
+
+	select {
+	case m.queue <- a:
+		return true
+	default:
+		m.log.Warn("Alert queue is full, dropping alert", map[string]interface{}{
+			"key":   a.Key,
+			"title": a.Title,
+		})
+		return false
+	}
+}
+
+// loop обрабатывает события шины и отправляет оповещения из очереди
+func (m *Manager) loop(ctx context.Context, sub *events.Subscription) {
+	defer close(m.done)
+	var eventsC <-chan events.Event
+	if sub != nil {
+		defer sub.Close()
+		eventsC = sub.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-eventsC:
+			m.handle(ev)
+		case a := <-m.queue:
+			m.deliver(ctx, a)
+		}
+	}
+}
+
+// handle преобразует событие шины в оповещение
+func (m *Manager) handle(ev events.Event) {
+	data, _ := ev.Data.(map[string]interface{})
+	switch ev.Type {
+	case events.TypeTimerDisabled:
+		m.Send(Alert{
+			Key:   "timer.disabled:" + fmt.Sprint(data["timer"]),
+			Title: fmt.Sprintf("Timer %v disabled", data["timer"]),
+			Text: fmt.Sprintf("Timer %v exceeded its panic budget (%v restarts) and was disabled. Last error: %v",
+				data["timer"], data["max_restarts"], data["error"]),
+			Time: ev.Time,
+		})
+	case events.TypeTimerThrottled:
+		key := "timer.throttled:" + fmt.Sprint(data["timer"])
+		if throttled, _ := data["throttled"].(bool); !throttled {
+			m.Send(Alert{
+				Key:      key,
+				Title:    fmt.Sprintf("Timer %v is no longer throttled", data["timer"]),
+				Text:     fmt.Sprintf("Timer %v ran within its resource budget, interval restored to %v", data["timer"], data["interval"]),
+				Time:     ev.Time,
+				Resolved: true,
+			})
+			return
+		}
+		m.Send(Alert{
+			Key:   key,
+			Title: fmt.Sprintf("Timer %v throttled", data["timer"]),
+			Text: fmt.Sprintf("Timer %v repeatedly exceeded its resource budget (wall %vms, cpu %vms; last run wall %vms, cpu %vms), interval increased to %v",
+				data["timer"], data["budget_wall_ms"], data["budget_cpu_ms"], data["wall_ms"], data["cpu_ms"], data["interval"]),
+			Time: ev.Time,
+		})
+	case events.TypeWatchdogFired:
+		m.Send(Alert{
+			Key:   "watchdog",
+			Title: "Watchdog detected sustained resource growth",
+			Text: fmt.Sprintf("Goroutines: %v (limit %v), heap: %v bytes (limit %v), restart: %v",
+				data["goroutines"], data["max_goroutines"], data["heap_bytes"], data["max_heap_bytes"], data["restart"]),
+			Time: ev.Time,
+		})
+	case events.TypeConfigRollback:
+		m.Send(Alert{
+			Key:   "config.rolled_back",
+			Title: "Reloaded config rolled back",
+			Text: fmt.Sprintf("Config %v failed probation (%v) and was replaced by config %v",
+				data["failed_hash"], data["reason"], data["config_hash"]),
+			Time: ev.Time,
+		})
+	case events.TypeHealthChanged:
+		check, _ := data["check"].(string)
+		to, _ := data["to"].(string)
+		errText, _ := data["error"].(string)
+		// degraded - проверка еще не признана сбойной или еще не восстановилась:
+		// отсчет порога и отправленное оповещение не меняются
+		if to == health.StatusDegraded {
+			return
+		}
+		m.healthChanged(check, to == health.StatusHealthy, errText)
+	}
+}
+
+// healthChanged запускает отсчет порога для непрошедшей проверки
+// и сообщает о восстановлении, если по ней уже было оповещение
+func (m *Manager) healthChanged(check string, healthy bool, errText string) {
+	key := "health:" + check
+
+	m.mu.Lock()
+	if healthy {
+		if timer, ok := m.failing[check]; ok {
+			timer.Stop()
+			delete(m.failing, check)
+		}
+		recovered := m.alerted[check]
+		delete(m.alerted, check)
+		m.mu.Unlock()
+
+		if recovered {
+			m.Send(Alert{
+				Key:      key,
+				Title:    fmt.Sprintf("Health check %s recovered", check),
+				Text:     fmt.Sprintf("Health check %s is passing again", check),
+				Resolved: true,
+			})
+		}
+		return
+	}
+	defer m.mu.Unlock()
+	if _, ok := m.failing[check]; ok || m.alerted[check] {
+		return
+	}
+	m.failing[check] = time.AfterFunc(m.cfg.HealthThreshold, func() {
+		m.mu.Lock()
+		if _, ok := m.failing[check]; !ok {
+			m.mu.Unlock()
+			return
+		}
+		delete(m.failing, check)
+		m.alerted[check] = true
+		m.mu.Unlock()
+
+		m.Send(Alert{
+			Key:   key,
+			Title: fmt.Sprintf("Health check %s failing", check),
+			Text: fmt.Sprintf("Health check %s has been failing for more than %s. Last error: %s",
+				check, m.cfg.HealthThreshold, errText),
+		})
+	})
+}
+
+// deliver отправляет оповещение во все каналы
+func (m *Manager) deliver(ctx context.Context, a Alert) {
+	for _, n := range m.cfg.Notifiers {
+		notifyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), m.cfg.Timeout)
+		err := n.Notify(notifyCtx, a)
+		cancel()
+
+		if m.metrics != nil {
+			m.metrics.RecordAlert(n.Name(), err != nil)
+		}
+		fields := map[string]interface{}{
+			"notifier": n.Name(),
+			"key":      a.Key,
+			"title":    a.Title,
+			"resolved": a.Resolved,
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+			m.log.Error("Failed to send alert", fields)
+			continue
+		}
+		m.log.Info("Alert sent", fields)
+	}
+}
@@ -0,0 +1,194 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/logger"
+)
+
+// fakeNotifier сохраняет полученные оповещения
+type fakeNotifier struct {
+	alerts chan Alert
+	err    error
+}
+
+func (f *fakeNotifier) Name() string {
+	return "fake"
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, a Alert) error {
+	f.alerts <- a
+	return f.err
+}
+
+// receive ждет следующее оповещение
+func receive(t *testing.T, n *fakeNotifier) Alert {
+	t.Helper()
+	select {
+	case a := <-n.alerts:
+		return a
+	case <-time.After(5 * time.Second):
+		t.Fatal("alert was not delivered")
+		return Alert{}
+	}
+}
+
+// setupManager создает и запускает менеджер с fakeNotifier
+func setupManager(t *testing.T, bus *events.Bus, cfg Config) (*Manager, *fakeNotifier) {
+	t.Helper()
+	log, err := logger.New("test-alerting", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { log.Close() })
+
+	n := &fakeNotifier{alerts: make(chan Alert, 10)}
+	cfg.Notifiers = []Notifier{n}
+	m := New(log, nil, bus, cfg)
+	if err := m.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+	t.Cleanup(func() { m.BeforeStop(context.Background()) })
+	return m, n
+}
+
+// TestCooldown проверяет подавление повторных оповещений
+func TestCooldown(t *testing.T) {
+	m, n := setupManager(t, nil, Config{Source: "svc", Cooldown: time.Minute})
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	if !m.Send(Alert{Key: "disk", Title: "Disk full"}) {
+		t.Fatal("first Send() = false")
+	}
+	if a := receive(t, n); a.Source != "svc" || a.Subject() != "[ALERT] svc: Disk full" {
+		t.Errorf("alert = %+v, subject %q", a, a.Subject())
+	}
+	if m.Send(Alert{Key: "disk", Title: "Disk full"}) || m.Send(Alert{Key: "disk", Title: "Disk full"}) {
+		t.Error("Send() within cooldown = true")
+	}
+	if !m.Send(Alert{Key: "cpu", Title: "CPU"}) {
+		t.Error("Send() with another key = false")
+	}
+	receive(t, n)
+
+	now = now.Add(time.Minute)
+	if !m.Send(Alert{Key: "disk", Title: "Disk full", Text: "97%"}) {
+		t.Fatal("Send() after cooldown = false")
+	}
+	if a := receive(t, n); a.Suppressed != 2 || !strings.Contains(a.Body(), "2 similar alerts suppressed") {
+		t.Errorf("alert after cooldown = %+v", a)
+	}
+}
+
+// TestEvents проверяет оповещения об отключении и замедлении таймеров, watchdog,
+// откате конфигурации и проверок здоровья
+func TestEvents(t *testing.T) {
+	bus := events.New()
+	_, n := setupManager(t, bus, Config{Cooldown: time.Minute, HealthThreshold: 50 * time.Millisecond})
+
+	bus.Publish(events.TypeTimerDisabled, map[string]interface{}{"timer": "report", "max_restarts": 3, "error": "boom"})
+	if a := receive(t, n); a.Key != "timer.disabled:report" || !strings.Contains(a.Text, "boom") {
+		t.Errorf("timer alert = %+v", a)
+	}
+	bus.Publish(events.TypeTimerThrottled, map[string]interface{}{"timer": "report", "throttled": true, "interval": "2m0s"})
+	if a := receive(t, n); a.Key != "timer.throttled:report" || a.Resolved || !strings.Contains(a.Text, "2m0s") {
+		t.Errorf("throttle alert = %+v", a)
+	}
+	bus.Publish(events.TypeTimerThrottled, map[string]interface{}{"timer": "report", "throttled": false, "interval": "1m0s"})
+	if a := receive(t, n); a.Key != "timer.throttled:report" || !a.Resolved {
+		t.Errorf("throttle recovery alert = %+v", a)
+	}
+	bus.Publish(events.TypeWatchdogFired, map[string]interface{}{"goroutines": 20000, "max_goroutines": 10000})
+	if a := receive(t, n); a.Key != "watchdog" || !strings.Contains(a.Text, "20000") {
+		t.Errorf("watchdog alert = %+v", a)
+	}
+	bus.Publish(events.TypeConfigRollback, map[string]interface{}{"reason": "timer report panicked", "config_hash": "a1", "failed_hash": "b2"})
+	if a := receive(t, n); a.Key != "config.rolled_back" || !strings.Contains(a.Text, "timer report panicked") {
+		t.Errorf("rollback alert = %+v", a)
+	}
+
+	// Кратковременный сбой проверки не вызывает оповещения
+	bus.Publish(events.TypeHealthChanged, map[string]interface{}{"check": "db", "to": "unhealthy"})
+	bus.Publish(events.TypeHealthChanged, map[string]interface{}{"check": "db", "to": "healthy"})
+	select {
+	case a := <-n.alerts:
+		t.Errorf("unexpected alert for short failure: %+v", a)
+	case <-time.After(150 * time.Millisecond):
+	}
+
+	bus.Publish(events.TypeHealthChanged, map[string]interface{}{"check": "db", "to": "unhealthy", "error": "timeout"})
+	if a := receive(t, n); a.Key != "health:db" || a.Resolved || !strings.Contains(a.Text, "timeout") {
+		t.Errorf("health alert = %+v", a)
+	}
+	// degraded при восстановлении не считается восстановлением
+	bus.Publish(events.TypeHealthChanged, map[string]interface{}{"check": "db", "to": "degraded"})
+	select {
+	case a := <-n.alerts:
+		t.Errorf("unexpected alert for degraded check: %+v", a)
+	case <-time.After(100 * time.Millisecond):
+	}
+	bus.Publish(events.TypeHealthChanged, map[string]interface{}{"check": "db", "to": "healthy"})
+	if a := receive(t, n); a.Key != "health:db" || !a.Resolved {
+		t.Errorf("recovery alert = %+v", a)
+	}
+}
+
+// TestNotifiers проверяет формат запросов webhook и Slack
+func TestNotifiers(t *testing.T) {
+	var bodies []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		if r.URL.Path == "/fail" {
+			http.Error(w, "bad token", http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+
+	a := Alert{Key: "disk", Title: "Disk full", Text: "97%", Source: "svc", Time: time.Now()}
+	if err := (&Webhook{URL: srv.URL}).Notify(context.Background(), a); err != nil {
+		t.Fatalf("Webhook.Notify() error = %v", err)
+	}
+	if err := (&Slack{WebhookURL: srv.URL}).Notify(context.Background(), a); err != nil {
+		t.Fatalf("Slack.Notify() error = %v", err)
+	}
+	err := (&Webhook{URL: srv.URL + "/fail"}).Notify(context.Background(), a)
+	if err == nil || !strings.Contains(err.Error(), "bad token") {
+		t.Errorf("Notify() error = %v, want status error", err)
+	}
+
+	if bodies[0]["key"] != "disk" || bodies[0]["title"] != "Disk full" {
+		t.Errorf("webhook body = %v", bodies[0])
+	}
+	if bodies[1]["text"] != "*[ALERT] svc: Disk full*\n97%" {
+		t.Errorf("slack body = %v", bodies[1])
+	}
+
+	msg := string((&Email{From: "svc@example.com", To: []string{"ops@example.com"}}).message(a))
+	for _, want := range []string{"To: ops@example.com\r\n", "Subject: [ALERT] svc: Disk full\r\n", "\r\n\r\n97%"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("email message does not contain %q:\n%s", want, msg)
+		}
+	}
+}
+
+// TestDeliveryError проверяет, что ошибка канала не останавливает рассылку
+func TestDeliveryError(t *testing.T) {
+	m, n := setupManager(t, nil, Config{})
+	n.err = errors.New("unavailable")
+
+	m.Send(Alert{Key: "a"})
+	m.Send(Alert{Key: "b"})
+	receive(t, n)
+	receive(t, n)
+}
@@ -0,0 +1,133 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Webhook отправляет оповещение POST запросом с JSON телом Alert
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+// Name возвращает имя канала
+func (w *Webhook) Name() string {
+	return "webhook"
+}
+
+// Notify отправляет оповещение
+func (w *Webhook) Notify(ctx context.Context, a Alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	return post(ctx, w.Client, w.URL, body)
+}
+
+// Slack отправляет оповещение во входящий webhook Slack
+type Slack struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+// Name возвращает имя канала
+func (s *Slack) Name() string {
+	return "slack"
+}
+
+// Notify отправляет оповещение
+func (s *Slack) Notify(ctx context.Context, a Alert) error {
+	body, err := json.Marshal(map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", a.Subject(), a.Body()),
+	})
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.Client, s.WebhookURL, body)
+}
+
+// post отправляет JSON и проверяет код ответа
+func post(ctx context.Context, client *http.Client, url string, body []byte) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// Email отправляет оповещение письмом через SMTP сервер. Если Username
+// задан, используется аутентификация PLAIN (сервер должен поддерживать STARTTLS)
+type Email struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// Name возвращает имя канала
+func (e *Email) Name() string {
+	return "email"
+}
+
+// Notify отправляет письмо. net/smtp не поддерживает контекст, поэтому
+// отправка выполняется в фоне, а Notify возвращается по истечении ctx
+func (e *Email) Notify(ctx context.Context, a Alert) error {
+	var auth smtp.Auth
+	if e.Username != "" {
+		host, _, err := net.SplitHostPort(e.Addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", e.Username, e.Password, host)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(e.Addr, auth, e.From, e.To, e.message(a))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// message формирует письмо
+func (e *Email) message(a Alert) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", e.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", a.Subject()))
+	fmt.Fprintf(&buf, "Date: %s\r\n", a.Time.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	buf.WriteString(strings.ReplaceAll(a.Body(), "\n", "\r\n"))
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
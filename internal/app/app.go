@@ -3,15 +3,58 @@ package app
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+
+	"service-boilerplate/internal/admin"
+	"service-boilerplate/internal/alerting"
+	"service-boilerplate/internal/appctx"
+	"service-boilerplate/internal/apperr"
+	"service-boilerplate/internal/audit"
+	"service-boilerplate/internal/buildinfo"
+	"service-boilerplate/internal/cleanup"
+	"service-boilerplate/internal/clockcheck"
 	"service-boilerplate/internal/config"
+	"service-boilerplate/internal/control"
+	"service-boilerplate/internal/db"
+	"service-boilerplate/internal/election"
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/execenv"
+	"service-boilerplate/internal/health"
+	"service-boilerplate/internal/heartbeat"
+	"service-boilerplate/internal/hooks"
+	"service-boilerplate/internal/httpclient"
+	"service-boilerplate/internal/httpserver"
+	"service-boilerplate/internal/jobs"
+	"service-boilerplate/internal/k8s"
 	"service-boilerplate/internal/lifecycle"
+	"service-boilerplate/internal/localsock"
 	"service-boilerplate/internal/logger"
 	"service-boilerplate/internal/metrics"
+	"service-boilerplate/internal/perfcounters"
+	"service-boilerplate/internal/procman"
+	"service-boilerplate/internal/profiling"
+	"service-boilerplate/internal/ratelimit"
+	"service-boilerplate/internal/redisclient"
+	"service-boilerplate/internal/remotewrite"
+	"service-boilerplate/internal/resilience/breaker"
 	"service-boilerplate/internal/scheduler"
+	"service-boilerplate/internal/secrets"
+	"service-boilerplate/internal/store"
 	"service-boilerplate/internal/task"
+	"service-boilerplate/internal/tracing"
+	"service-boilerplate/internal/watchdog"
+	"service-boilerplate/internal/watcher"
 )
 
 // ServiceName определяет имя службы (константа, задается при компиляции)
@@ -23,6 +66,11 @@ const ServiceDisplayName = "Service Boilerplate"
 // ServiceDescription определяет описание службы
 const ServiceDescription = "Cross-platform service boilerplate"
 
+// ErrRestartRequested возвращается из Run, если приложение остановлено
+// для перезапуска (например, по сигналу watchdog). Ненулевой код выхода
+// позволяет systemd/SCM поднять сервис заново
+var ErrRestartRequested = errors.New("restart requested")
+
 // App представляет основное приложение
 type App struct {
 	config    *config.Config
@@ -30,25 +78,627 @@ type App struct {
 	lifecycle *lifecycle.Manager
 	scheduler *scheduler.Scheduler
 	metrics   *metrics.Server
+	jobs      *jobs.Queue
+	store     *store.Store
+	election  *election.Election
+	watcher   *watcher.Watcher
+	http      *httpserver.Server
+	events    *events.Bus
+	health    *health.Registry
+	db        *db.DB
+	redis     *redisclient.Client
+	secrets   *secrets.Rotator
+	cleanup   *cleanup.Registry
+	limits    *ratelimit.Registry
+	procs     *procman.Manager
+	hooks     *hooks.Runner
+	alerts    *alerting.Manager
+	admin     *admin.Server
+	control   *control.Server
+	identity  appctx.Identity
+
+	mu            sync.Mutex
+	cancel        context.CancelFunc
+	restartReason string
+	// phase фаза жизненного цикла для /ready
+	phase string
+	// pod под Kubernetes (kubernetes.enabled)
+	pod k8s.PodInfo
+	// startup получатель хода запуска (SetStartupReporter)
+	startup StartupReporter
+	// bootPhases этапы запуска до Run (RecordStartupPhase), runPhases -
+	// этапы последнего Run
+	bootPhases []StartupPhase
+	runPhases  []StartupPhase
+	// bootTotal длительность этапов до Run, startupTotal - всего запуска
+	bootTotal    time.Duration
+	startupTotal time.Duration
+
+	// Перезагрузка конфигурации: reloader загружает новую, active -
+	// последняя примененная (nil - исходная config)
+	reloadMu   sync.Mutex
+	reloader   func() (*config.Config, error)
+	active     *config.Config
+	lastReload *config.ReloadStatus
+	// knownGood последняя конфигурация, прошедшая испытательный срок
+	// (nil - исходная config), probation прерывает текущий срок
+	knownGood *config.Config
+	probation context.CancelFunc
 }
 
 // New создает новое приложение
 func New(cfg *config.Config, log *logger.Logger) *App {
+	return NewWithRegistry(cfg, log, nil)
+}
+
+// NewWithRegistry создает приложение, регистрирующее метрики в registry
+// (nil - собственный registry), например при встраивании в другой процесс
+func NewWithRegistry(cfg *config.Config, log *logger.Logger, registry *prometheus.Registry) *App {
 	// Создаем сервер метрик
-	metricsServer := metrics.New(log, cfg.Metrics.Enabled, cfg.Metrics.Listen)
+	metricsServer := metrics.NewWithRegistry(log, cfg.Metrics.Enabled, cfg.Metrics.Listen, registry)
+	metricsServer.SetLabelLimit(cfg.Metrics.MaxLabelValues, cfg.Metrics.LabelOverflow)
 
 	// Создаем планировщик
 	sched := scheduler.New(log, metricsServer, cfg.Scheduler.MaxPanicRestarts, cfg.Scheduler.BackoffSeconds)
+	sched.SetStarvationTimeout(time.Duration(cfg.Scheduler.StarvationSeconds) * time.Second)
+	sched.SetMaxConcurrentRuns(cfg.Scheduler.MaxConcurrentRuns)
+	sched.SetMaxConcurrentHandlers(cfg.Scheduler.MaxConcurrentHandlers)
+	sched.SetHistory(cfg.Scheduler.HistorySize, cfg.Scheduler.PersistHistory)
+	envs := make(map[string]execenv.Env, len(cfg.Scheduler.Timers))
+	priorities := make(map[string]int, len(cfg.Scheduler.Timers))
+	readOnlySafe := make(map[string]bool, len(cfg.Scheduler.Timers))
+	budgets := make(map[string]scheduler.Budget, len(cfg.Scheduler.Timers))
+	overlaps := make(map[string]scheduler.OverlapPolicy, len(cfg.Scheduler.Timers))
+	misfires := make(map[string]scheduler.MisfirePolicy, len(cfg.Scheduler.Timers))
+	for name, t := range cfg.Scheduler.Timers {
+		envs[name] = execenv.Env{Dir: t.Dir, Vars: t.Env}
+		priorities[name] = t.Priority
+		overlaps[name] = scheduler.OverlapPolicy(t.OverlapPolicy)
+		misfires[name] = scheduler.MisfirePolicy(t.MisfirePolicy)
+		readOnlySafe[name] = t.ReadOnly
+		budgets[name] = scheduler.Budget{
+			Wall:           time.Duration(t.Budget.WallSeconds) * time.Second,
+			CPU:            time.Duration(t.Budget.CPUSeconds) * time.Second,
+			Violations:     t.Budget.Violations,
+			ThrottleFactor: t.Budget.ThrottleFactor,
+		}
+	}
+	sched.SetEnvironments(envs)
+	sched.SetPriorities(priorities)
+	sched.SetBudgets(budgets)
+	sched.SetOverlapPolicy(scheduler.OverlapPolicy(cfg.Scheduler.OverlapPolicy), overlaps)
+	sched.SetMisfirePolicy(scheduler.MisfirePolicy(cfg.Scheduler.MisfirePolicy), misfires)
+	sched.SetTimerSettings(timerSettings(cfg))
+	namespaces := make(map[string]scheduler.NamespaceOptions, len(cfg.Scheduler.Namespaces))
+	for name, ns := range cfg.Scheduler.Namespaces {
+		namespaces[name] = scheduler.NamespaceOptions{
+			MaxConcurrentRuns: ns.MaxConcurrentRuns,
+			Disabled:          ns.Disabled,
+		}
+	}
+	sched.SetNamespaces(namespaces)
+	if cfg.Service.ReadOnly {
+		sched.SetReadOnly(readOnlySafe)
+	}
+
+	// Создаем очередь заданий
+	queue := jobs.New(log, metricsServer, jobs.Config{
+		Workers:        cfg.Jobs.Workers,
+		QueueSize:      cfg.Jobs.QueueSize,
+		MaxAttempts:    cfg.Jobs.MaxAttempts,
+		RetryBackoff:   time.Duration(cfg.Jobs.RetryBackoffSeconds) * time.Second,
+		DeadLetterSize: cfg.Jobs.DeadLetterSize,
+	})
+
+	// Шина событий для потока GET /events admin API
+	bus := events.New()
+	sched.SetEvents(bus)
 
 	// Создаем lifecycle менеджер
 	lc := lifecycle.New(log)
+	lc.SetReadOnly(cfg.Service.ReadOnly)
+	lc.SetDependencyWait(
+		time.Duration(cfg.Startup.DependencyTimeoutSeconds)*time.Second,
+		time.Duration(cfg.Startup.DependencyMaxBackoffSeconds)*time.Second)
+	lc.SetStopTimeout(time.Duration(cfg.Shutdown.TaskTimeoutSeconds) * time.Second)
+
+	// Хранилище создается до идентичности: в нем сохраняется идентификатор
+	// экземпляра. В режиме только для чтения хранилище не открывается:
+	// файл занят рабочим экземпляром, а сохраненное состояние не должно меняться
+	var st *store.Store
+	if cfg.Store.Enabled && !cfg.Service.ReadOnly {
+		st = store.New(log, cfg.Store.Path)
+	}
+	identity := newIdentity(cfg, log, st)
+	log.SetInstance(identity.InstanceID, identity.Hostname)
+	constLabels := map[string]string{
+		"instance_id": identity.InstanceID,
+		"hostname":    identity.Hostname,
+	}
+	// В Kubernetes записи и метрики помечаются подом, пространством имен и узлом
+	var pod k8s.PodInfo
+	if cfg.Kubernetes.Enabled {
+		pod = k8s.Detect(cfg.Kubernetes.PodInfoDir)
+		log.SetLabels(pod.Labels())
+		for name, value := range pod.Labels() {
+			constLabels[name] = value
+		}
+	}
+	metricsServer.SetConstLabels(constLabels)
 
-	return &App{
+	a := &App{
 		config:    cfg,
 		log:       log,
 		lifecycle: lc,
 		scheduler: sched,
 		metrics:   metricsServer,
+		jobs:      queue,
+		events:    bus,
+		health: health.New(
+			time.Duration(cfg.Health.TimeoutSeconds)*time.Second,
+			time.Duration(cfg.Health.IntervalSeconds)*time.Second),
+		identity: identity,
+		pod:      pod,
+	}
+
+	// Ограничители частоты запросов общие для всех таймеров и заданий
+	limits := make(map[string]ratelimit.Config, len(cfg.RateLimits))
+	for name, rl := range cfg.RateLimits {
+		limits[name] = ratelimit.Config{RequestsPerSecond: rl.RequestsPerSecond, Burst: rl.Burst}
+	}
+	a.limits = ratelimit.NewRegistry(log, metricsServer, limits)
+
+	// Создаем admin сервер
+	a.admin = admin.New(log, sched, queue, cfg, a.RequestShutdown)
+	if metricsServer != nil {
+		a.admin.SetMetrics(metricsServer)
+	}
+	a.admin.SetReloader(a)
+	a.admin.SetIdentity(a.identity)
+	a.admin.SetHealth(a.health)
+	if cfg.Admin.Socket {
+		a.admin.SetSocket(ControlSocketPath(cfg))
+	}
+
+	// Создаем gRPC сервер управления
+	a.control = control.New(log, sched, cfg, a.identity)
+	if cfg.GRPC.Socket {
+		a.control.SetSocket(GRPCSocketPath(cfg))
+	}
+
+	// Admin API отдает события в GET /events, оба сервера публикуют смену уровня логирования
+	a.admin.SetEvents(bus)
+	a.control.SetEvents(bus)
+
+	// Действия операторов через оба сервера пишутся в журнал действий
+	auditLog := audit.New(AuditPath(cfg))
+	a.admin.SetAudit(auditLog)
+	a.control.SetAudit(auditLog)
+
+	// Реестр временных артефактов регистрируется до всех задач: артефакты
+	// предыдущего аварийного запуска удаляются раньше, чем задачи создадут
+	// новые, а свои удаляются после остановки всех задач
+	a.cleanup = cleanup.New(log, CleanupManifestPath(cfg), filepath.Join(cfg.Service.CacheDir, "tmp"))
+	lc.Register(a.cleanup)
+	if cfg.Admin.Socket {
+		a.cleanup.Register("admin", cleanup.KindSocket, ControlSocketPath(cfg))
+	}
+	if cfg.GRPC.Socket {
+		a.cleanup.Register("control", cleanup.KindSocket, GRPCSocketPath(cfg))
+	}
+
+	// Хранилище регистрируется первым после реестра артефактов: открывается
+	// до остальных задач и закрывается последним
+	if st != nil {
+		a.store = st
+		lc.Register(a.store)
+		lc.SetStateLoader(a.store)
+		sched.SetStore(a.store)
+		queue.SetStore(a.store)
+	}
+
+	// Трассировка запусков таймеров; спаны отправляются до конца остановки
+	if cfg.Tracing.Enabled {
+		provider := tracing.New(log, tracing.Config{
+			Endpoint:    cfg.Tracing.Endpoint,
+			SampleRatio: cfg.Tracing.SampleRatio,
+			Timeout:     time.Duration(cfg.Tracing.TimeoutSeconds) * time.Second,
+			Identity:    a.identity,
+		})
+		lc.RegisterWithPhase(provider, task.PhaseRelease)
+		sched.SetTracer(provider.Tracer("service-boilerplate/scheduler"))
+	}
+
+	// Отчет проверок здоровья отдается сервером метрик на /health
+	a.health.SetEvents(bus)
+	a.health.SetMetrics(metricsServer)
+	a.health.SetThresholds(cfg.Health.FailureThreshold, cfg.Health.SuccessThreshold)
+	metricsServer.SetHealthHandler(a.health.Handler())
+	metricsServer.SetReadyHandler(http.HandlerFunc(a.handleReady))
+
+	// Оповещения о повторяющихся сбоях рассылаются до конца остановки
+	if cfg.Alerting.Enabled {
+		a.alerts = alerting.New(log, metricsServer, bus, alerting.Config{
+			Source:          a.identity.Service,
+			Cooldown:        time.Duration(cfg.Alerting.CooldownSeconds) * time.Second,
+			HealthThreshold: time.Duration(cfg.Alerting.HealthFailingSeconds) * time.Second,
+			Timeout:         time.Duration(cfg.Alerting.TimeoutSeconds) * time.Second,
+			Notifiers:       a.alertNotifiers(),
+		})
+		lc.RegisterWithPhase(a.alerts, task.PhaseRelease)
+	}
+
+	// Пул соединений открывается до задач, которые его используют
+	if cfg.Database.Enabled {
+		a.db = db.New(log, metricsServer, db.Config{
+			Driver:          cfg.Database.Driver,
+			DSN:             cfg.Database.DSN,
+			DSNEnv:          cfg.Database.DSNEnv,
+			DSNFile:         cfg.Database.DSNFile,
+			MaxOpenConns:    cfg.Database.MaxOpenConns,
+			MaxIdleConns:    cfg.Database.MaxIdleConns,
+			ConnMaxLifetime: time.Duration(cfg.Database.ConnMaxLifetimeSeconds) * time.Second,
+			ConnMaxIdleTime: time.Duration(cfg.Database.ConnMaxIdleTimeSeconds) * time.Second,
+			PingTimeout:     time.Duration(cfg.Database.PingTimeoutSeconds) * time.Second,
+		})
+		lc.Register(a.db)
+		a.health.Register("db", a.db.Ping)
+	}
+
+	// Клиент Redis подключается до задач, которые используют его для кэша и блокировок
+	if cfg.Redis.Enabled {
+		a.redis = redisclient.New(log, metricsServer, redisclient.Config{
+			Addr:         cfg.Redis.Addr,
+			Username:     cfg.Redis.Username,
+			Password:     cfg.Redis.Password,
+			PasswordEnv:  cfg.Redis.PasswordEnv,
+			PasswordFile: cfg.Redis.PasswordFile,
+			DB:           cfg.Redis.DB,
+			PoolSize:     cfg.Redis.PoolSize,
+			TLS:          cfg.Redis.TLS.Enabled,
+			CAFile:       cfg.Redis.TLS.CAFile,
+			DialTimeout:  time.Duration(cfg.Redis.DialTimeoutSeconds) * time.Second,
+			ReadTimeout:  time.Duration(cfg.Redis.ReadTimeoutSeconds) * time.Second,
+			WriteTimeout: time.Duration(cfg.Redis.WriteTimeoutSeconds) * time.Second,
+		})
+		lc.Register(a.redis)
+		a.health.Register("redis", a.redis.Ping)
+	}
+
+	// Внешние зависимости проверяются вместе с компонентами, некритичные
+	// не делают сервис unhealthy
+	for _, dep := range cfg.Health.Dependencies {
+		check, err := health.Probe(dep.Type, dep.Endpoint)
+		if err != nil {
+			log.Warn("Invalid health dependency", map[string]interface{}{
+				"dependency": dep.Name,
+				"error":      err.Error(),
+			})
+			continue
+		}
+		if dep.Critical {
+			a.health.Register(dep.Name, check)
+		} else {
+			a.health.RegisterOptional(dep.Name, check)
+		}
+	}
+
+	// Смена секретов: пул базы и клиент Redis переподключаются с новыми
+	// учетными данными без перезапуска
+	a.secrets = secrets.NewRotator(log, time.Duration(cfg.Secrets.RotationCheckSeconds)*time.Second)
+	a.secrets.SetEvents(bus)
+	if a.db != nil {
+		a.secrets.WatchFile("database", cfg.Database.DSNFile)
+		a.secrets.OnRotate("database", a.db.Rotate)
+	}
+	if a.redis != nil {
+		a.secrets.WatchFile("redis", cfg.Redis.PasswordFile)
+		a.secrets.OnRotate("redis", a.redis.Rotate)
+	}
+	lc.Register(a.secrets)
+
+	// Дочерние процессы из секции processes
+	procs := make([]procman.Process, 0, len(cfg.Processes))
+	for _, p := range cfg.Processes {
+		procs = append(procs, procman.Process{
+			Name:            p.Name,
+			Command:         p.Command,
+			Args:            p.Args,
+			Env:             execenv.Env{Vars: p.Env}.Pairs(),
+			Dir:             p.Dir,
+			Restart:         procman.RestartPolicy(p.Restart),
+			RestartDelay:    time.Duration(p.RestartDelaySeconds) * time.Second,
+			MaxRestartDelay: time.Duration(p.MaxRestartDelaySeconds) * time.Second,
+			StopTimeout:     time.Duration(p.StopTimeoutSeconds) * time.Second,
+			Limits:          procman.Limits{CPUPercent: p.CPUPercent, MemoryMB: p.MemoryMB},
+		})
+	}
+	a.procs = procman.New(log, metricsServer, procs)
+	lc.Register(a.procs)
+
+	// Внешние команды из секции hooks
+	a.hooks = hooks.New(log, metricsServer, bus, map[string][]hooks.Command{
+		hooks.PostStart:    hookCommands(cfg.Hooks.PostStart),
+		hooks.PreStop:      hookCommands(cfg.Hooks.PreStop),
+		hooks.TimerFailure: hookCommands(cfg.Hooks.TimerFailure),
+	})
+	a.hooks.SetVars(map[string]string{
+		"SERVICE_NAME":        a.identity.Service,
+		"SERVICE_INSTANCE_ID": a.identity.InstanceID,
+	})
+	lc.Register(a.hooks)
+
+	// Таймеры по расписанию выполняются только на лидере. Экземпляр
+	// только для чтения в выборах не участвует
+	if cfg.Election.Enabled && !cfg.Service.ReadOnly {
+		a.election = election.New(log, metricsServer,
+			election.NewFileLock(cfg.Election.LockFile),
+			time.Duration(cfg.Election.RetrySeconds)*time.Second)
+		lc.Register(a.election)
+		sched.SetGate(a.election.IsLeader)
+		// Новый лидер продолжает с паузами и временем запусков предыдущего
+		a.election.Bind(scheduler.NewHandoff(sched, cfg.Election.LockFile+".scheduler"))
+	}
+
+	// Создаем watcher директорий; обработчики регистрируются через GetWatcher
+	watches := make([]watcher.Watch, 0, len(cfg.Watcher.Watches))
+	for _, w := range cfg.Watcher.Watches {
+		watches = append(watches, watcher.Watch{
+			Name:     w.Name,
+			Dir:      w.Path,
+			Pattern:  w.Pattern,
+			Debounce: time.Duration(w.DebounceMs) * time.Millisecond,
+			Existing: w.Existing,
+		})
+	}
+	a.watcher = watcher.New(log, sched, metricsServer, watches)
+	lc.RegisterWithPhase(a.watcher, task.PhaseStopAccepting)
+
+	// Создаем HTTP сервер приложения; маршруты регистрируются через GetHTTP
+	a.http = httpserver.New(log, metricsServer, httpserver.Config{
+		Enabled:      cfg.HTTP.Enabled,
+		Listen:       cfg.HTTP.Listen,
+		CertFile:     cfg.HTTP.TLS.CertFile,
+		KeyFile:      cfg.HTTP.TLS.KeyFile,
+		ReadTimeout:  time.Duration(cfg.HTTP.ReadTimeoutSeconds) * time.Second,
+		WriteTimeout: time.Duration(cfg.HTTP.WriteTimeoutSeconds) * time.Second,
+		IdleTimeout:  time.Duration(cfg.HTTP.IdleTimeoutSeconds) * time.Second,
+	})
+	lc.Register(a.http)
+
+	// Очередь останавливается после задач, которые могут ставить задания
+	lc.RegisterWithPhase(queue, task.PhaseFlush)
+
+	// Регистрируем watchdog горутин и памяти
+	if cfg.Watchdog.Enabled {
+		wd := watchdog.New(log, watchdog.Config{
+			Interval:         time.Duration(cfg.Watchdog.IntervalSeconds) * time.Second,
+			MaxGoroutines:    cfg.Watchdog.MaxGoroutines,
+			MaxHeapBytes:     uint64(cfg.Watchdog.MaxHeapMB) * 1024 * 1024,
+			SustainedSamples: cfg.Watchdog.SustainedSamples,
+			Restart:          cfg.Watchdog.Restart,
+		}, a.RequestRestart)
+		wd.SetEvents(bus)
+		lc.Register(wd)
+	}
+
+	// Проверка системных часов: после скачка часов пересчитывается
+	// время следующего запуска таймеров
+	clock := clockcheck.New(log, metricsServer, clockcheck.Config{
+		Interval:  time.Duration(cfg.Clock.CheckIntervalSeconds) * time.Second,
+		Threshold: time.Duration(cfg.Clock.JumpThresholdSeconds) * time.Second,
+	})
+	clock.SetEvents(bus)
+	if a.store != nil {
+		clock.SetStore(a.store)
+	}
+	clock.OnJump(sched.ClockJumped)
+	lc.Register(clock)
+
+	// Периодическое снятие pprof профилей
+	if cfg.Profiling.Enabled {
+		dir := cfg.Profiling.Dir
+		if dir == "" {
+			dir = filepath.Join(cfg.Service.LogDir, "profiles")
+		}
+		appName := cfg.Profiling.Pyroscope.AppName
+		if appName == "" {
+			appName = a.identity.Service
+		}
+		lc.Register(profiling.New(log, metricsServer, profiling.Config{
+			Dir:          dir,
+			Interval:     time.Duration(cfg.Profiling.IntervalSeconds) * time.Second,
+			CPUDuration:  time.Duration(cfg.Profiling.CPUSeconds) * time.Second,
+			Profiles:     cfg.Profiling.Profiles,
+			Retention:    cfg.Profiling.Retention,
+			PyroscopeURL: cfg.Profiling.Pyroscope.URL,
+			AppName:      appName,
+			Client:       httpclient.New(log, metricsServer, httpclient.Config{Name: "pyroscope", Timeout: 30 * time.Second}),
+		}))
+	}
+
+	// Отправка метрик через Prometheus remote write
+	if rw := cfg.Metrics.RemoteWrite; rw.Enabled && metricsServer.Gatherer() != nil {
+		timeout := time.Duration(rw.TimeoutSeconds) * time.Second
+		lc.Register(remotewrite.New(log, metricsServer, metricsServer.Gatherer(), remotewrite.Config{
+			URL:         rw.URL,
+			Interval:    time.Duration(rw.IntervalSeconds) * time.Second,
+			Timeout:     timeout,
+			BufferSize:  rw.BufferSize,
+			Username:    rw.Username,
+			Password:    rw.Password,
+			BearerToken: rw.BearerToken,
+			Headers:     rw.Headers,
+			Client: httpclient.New(log, metricsServer, httpclient.Config{
+				Name:         "remote_write",
+				Timeout:      timeout,
+				MaxRetries:   rw.MaxRetries,
+				RetryBackoff: time.Duration(cfg.HTTPClient.RetryBackoffMs) * time.Millisecond,
+			}),
+		}))
+	}
+
+	// Публикация ключевых метрик как счетчиков производительности Windows
+	if pc := cfg.Metrics.PerfCounters; pc.Enabled && metricsServer.Gatherer() != nil {
+		lc.Register(perfcounters.New(log, metricsServer.Gatherer(), perfcounters.Config{
+			ServiceName: cfg.Service.Name,
+			Interval:    time.Duration(pc.IntervalSeconds) * time.Second,
+		}))
+	}
+
+	// Фоновые проверки здоровья запускаются после компонентов, которые они проверяют
+	lc.Register(a.health)
+
+	// Heartbeat в центральный endpoint для экземпляров, которые нельзя опрашивать
+	if hb := cfg.Heartbeat; hb.Enabled {
+		timeout := time.Duration(hb.TimeoutSeconds) * time.Second
+		publisher := heartbeat.New(log, metricsServer, sched, heartbeat.Config{
+			URL:         hb.URL,
+			Interval:    time.Duration(hb.IntervalSeconds) * time.Second,
+			Timeout:     timeout,
+			BearerToken: hb.BearerToken,
+			Headers:     hb.Headers,
+			Identity:    a.identity,
+			Client: httpclient.New(log, metricsServer, httpclient.Config{
+				Name:         "heartbeat",
+				Timeout:      timeout,
+				MaxRetries:   hb.MaxRetries,
+				RetryBackoff: time.Duration(cfg.HTTPClient.RetryBackoffMs) * time.Millisecond,
+			}),
+		})
+		publisher.SetHealth(a.health)
+		lc.Register(publisher)
+	}
+
+	return a
+}
+
+// InstanceName возвращает имя экземпляра сервиса из конфигурации
+// или ServiceName, если оно не задано
+func InstanceName(cfg *config.Config) string {
+	if cfg.Service.Name != "" {
+		return cfg.Service.Name
+	}
+	return ServiceName
+}
+
+// ControlSocketPath возвращает путь локального канала управления:
+// admin.socket_path или путь по умолчанию для имени экземпляра
+func ControlSocketPath(cfg *config.Config) string {
+	if cfg.Admin.SocketPath != "" {
+		return cfg.Admin.SocketPath
+	}
+	return localsock.DefaultPath(InstanceName(cfg))
+}
+
+// GRPCSocketPath возвращает путь локального канала gRPC управления:
+// grpc.socket_path или путь по умолчанию для <имя экземпляра>-grpc
+func GRPCSocketPath(cfg *config.Config) string {
+	if cfg.GRPC.SocketPath != "" {
+		return cfg.GRPC.SocketPath
+	}
+	return localsock.DefaultPath(InstanceName(cfg) + "-grpc")
+}
+
+// AuditPath возвращает путь журнала действий операторов:
+// service.audit_file или <log_dir>/<имя экземпляра>-audit.log
+func AuditPath(cfg *config.Config) string {
+	if cfg.Service.AuditFile != "" {
+		return cfg.Service.AuditFile
+	}
+	return filepath.Join(cfg.Service.LogDir, InstanceName(cfg)+"-audit.log")
+}
+
+// CleanupManifestPath возвращает путь манифеста временных артефактов:
+// <state_dir>/<имя экземпляра>-cleanup.json
+func CleanupManifestPath(cfg *config.Config) string {
+	return filepath.Join(cfg.Service.StateDir, InstanceName(cfg)+"-cleanup.json")
+}
+
+// Identity возвращает идентичность экземпляра сервиса
+func (a *App) Identity() appctx.Identity {
+	return a.identity
+}
+
+// newIdentity определяет идентичность экземпляра. Идентификатор берется
+// из service.instance_id, из хранилища или, если он еще не сохранен,
+// хранилище отключено или недоступно, генерируется заново. Хранилище
+// здесь только читается: новый идентификатор сохраняет Run
+// (persistInstanceID), а check и dry-run не меняют его
+func newIdentity(cfg *config.Config, log *logger.Logger, st *store.Store) appctx.Identity {
+	hostname, ip := appctx.DetectHost()
+	id := appctx.Identity{
+		Service:    InstanceName(cfg),
+		InstanceID: cfg.Service.InstanceID,
+		Version:    buildinfo.Version,
+		Hostname:   hostname,
+		IP:         ip,
+	}
+	if id.InstanceID == "" && st != nil {
+		instanceID, err := st.LookupInstanceID()
+		if err != nil {
+			log.Warn("Failed to load persisted instance ID, using a temporary one", map[string]interface{}{"error": err.Error()})
+		}
+		id.InstanceID = instanceID
+	}
+	if id.InstanceID == "" {
+		id.InstanceID = appctx.NewInstanceID()
+	}
+	return id
+}
+
+// persistInstanceID сохраняет идентификатор экземпляра в открытом
+// хранилище при первом запуске, чтобы он не менялся между перезапусками.
+// Идентификатор из service.instance_id не сохраняется
+func (a *App) persistInstanceID() {
+	if a.store == nil || a.config.Service.InstanceID != "" {
+		return
+	}
+	if _, err := a.store.InstanceID(func() string { return a.identity.InstanceID }); err != nil {
+		a.log.Warn("Failed to persist instance ID", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+// hookCommands преобразует команды точки вызова из конфигурации
+func hookCommands(cfgs []config.HookConfig) []hooks.Command {
+	cmds := make([]hooks.Command, 0, len(cfgs))
+	for _, h := range cfgs {
+		cmds = append(cmds, hooks.Command{
+			Command: h.Command,
+			Args:    h.Args,
+			Dir:     h.Dir,
+			Env:     h.Env,
+			Timeout: time.Duration(h.TimeoutSeconds) * time.Second,
+		})
+	}
+	return cmds
+}
+
+// RequestRestart инициирует graceful остановку с последующим перезапуском
+// силами менеджера сервисов
+func (a *App) RequestRestart(reason string) {
+	a.mu.Lock()
+	cancel := a.cancel
+	if a.restartReason == "" {
+		a.restartReason = reason
+	}
+	a.mu.Unlock()
+
+	a.log.Warn("Restart requested", map[string]interface{}{"reason": reason})
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// RequestShutdown инициирует graceful остановку без перезапуска
+func (a *App) RequestShutdown(reason string) {
+	a.mu.Lock()
+	cancel := a.cancel
+	a.mu.Unlock()
+
+	a.log.Warn("Shutdown requested", map[string]interface{}{"reason": reason})
+	if cancel != nil {
+		cancel()
 	}
 }
 
@@ -57,55 +707,374 @@ func (a *App) GetScheduler() *scheduler.Scheduler {
 	return a.scheduler
 }
 
+// GetJobs возвращает очередь заданий для регистрации обработчиков
+func (a *App) GetJobs() *jobs.Queue {
+	return a.jobs
+}
+
+// GetStore возвращает хранилище состояния для задач или nil,
+// если оно отключено в конфигурации. Хранилище доступно после запуска
+func (a *App) GetStore() *store.Store {
+	return a.store
+}
+
+// GetElection возвращает выборы лидера для привязки задач (Bind) или nil,
+// если выбор лидера отключен в конфигурации
+func (a *App) GetElection() *election.Election {
+	return a.election
+}
+
+// GetWatcher возвращает watcher директорий для регистрации обработчиков
+func (a *App) GetWatcher() *watcher.Watcher {
+	return a.watcher
+}
+
+// GetHTTP возвращает HTTP сервер приложения для регистрации маршрутов
+func (a *App) GetHTTP() *httpserver.Server {
+	return a.http
+}
+
+// GetEvents возвращает шину событий для публикации собственных событий
+// задач в поток GET /events
+func (a *App) GetEvents() *events.Bus {
+	return a.events
+}
+
+// GetHealth возвращает реестр проверок здоровья для регистрации
+// проверок зависимостей задач
+func (a *App) GetHealth() *health.Registry {
+	return a.health
+}
+
+// GetDB возвращает пул соединений с базой данных или nil, если база
+// отключена в конфигурации. Пул доступен после запуска
+func (a *App) GetDB() *sql.DB {
+	if a.db == nil {
+		return nil
+	}
+	return a.db.DB()
+}
+
+// GetRedis возвращает клиент Redis или nil, если Redis отключен
+// в конфигурации. Клиент доступен после запуска
+func (a *App) GetRedis() *redis.Client {
+	if a.redis == nil {
+		return nil
+	}
+	return a.redis.Client()
+}
+
+// GetSecrets возвращает отслеживание смены секретов для регистрации
+// собственных секретов и обработчиков (клиенты API, очереди)
+func (a *App) GetSecrets() *secrets.Rotator {
+	return a.secrets
+}
+
+// GetCleanup возвращает реестр временных артефактов для регистрации
+// файлов, сокетов и блокировок собственных компонентов
+func (a *App) GetCleanup() *cleanup.Registry {
+	return a.cleanup
+}
+
+// GetAlerting возвращает менеджер оповещений для отправки собственных
+// оповещений задач или nil, если оповещения отключены в конфигурации
+func (a *App) GetAlerting() *alerting.Manager {
+	return a.alerts
+}
+
+// alertNotifiers создает каналы оповещений из секции alerting
+func (a *App) alertNotifiers() []alerting.Notifier {
+	cfg := a.config.Alerting
+	client := httpclient.New(a.log, a.metrics, httpclient.Config{
+		Name:    "alerting",
+		Timeout: time.Duration(cfg.TimeoutSeconds) * time.Second,
+	})
+
+	var notifiers []alerting.Notifier
+	if cfg.Webhook.URL != "" {
+		notifiers = append(notifiers, &alerting.Webhook{URL: cfg.Webhook.URL, Client: client})
+	}
+	if cfg.Slack.WebhookURL != "" {
+		notifiers = append(notifiers, &alerting.Slack{WebhookURL: cfg.Slack.WebhookURL, Client: client})
+	}
+	if cfg.Email.SMTPAddr != "" {
+		password := cfg.Email.Password
+		if cfg.Email.PasswordEnv != "" {
+			password = os.Getenv(cfg.Email.PasswordEnv)
+		}
+		notifiers = append(notifiers, &alerting.Email{
+			Addr:     cfg.Email.SMTPAddr,
+			Username: cfg.Email.Username,
+			Password: password,
+			From:     cfg.Email.From,
+			To:       cfg.Email.To,
+		})
+	}
+	return notifiers
+}
+
+// GetProcesses возвращает менеджер дочерних процессов для просмотра их состояния
+func (a *App) GetProcesses() *procman.Manager {
+	return a.procs
+}
+
+// NewHTTPClient создает клиент для внешнего API name с таймаутом, повторами
+// и circuit breaker из секции http_client и ограничителем rate_limits.<name>,
+// если он настроен. Для других настроек используйте httpclient.New напрямую
+func (a *App) NewHTTPClient(name string) *http.Client {
+	cfg := httpclient.Config{
+		Name:         name,
+		Timeout:      time.Duration(a.config.HTTPClient.TimeoutSeconds) * time.Second,
+		MaxRetries:   a.config.HTTPClient.MaxRetries,
+		RetryBackoff: time.Duration(a.config.HTTPClient.RetryBackoffMs) * time.Millisecond,
+	}
+	if a.config.HTTPClient.Breaker.Enabled {
+		cfg.Breaker = a.NewBreaker(name)
+	}
+	// Ограничитель с тем же именем, если настроен, делит бюджет со всеми его пользователями
+	if limiter, err := a.limits.Get(name); err == nil {
+		cfg.Limiter = limiter
+	}
+	return httpclient.New(a.log, a.metrics, cfg)
+}
+
+// NewBreaker создает circuit breaker для зависимости name с настройками
+// из секции http_client.breaker (используется и без HTTP, через Breaker.Do)
+func (a *App) NewBreaker(name string) *breaker.Breaker {
+	return breaker.New(a.log, a.metrics, breaker.Config{
+		Name:             name,
+		FailureThreshold: a.config.HTTPClient.Breaker.FailureThreshold,
+		OpenTimeout:      time.Duration(a.config.HTTPClient.Breaker.OpenTimeoutSeconds) * time.Second,
+		HalfOpenProbes:   a.config.HTTPClient.Breaker.HalfOpenProbes,
+	})
+}
+
+// GetRateLimiter возвращает именованный ограничитель из секции rate_limits
+// или ratelimit.ErrUnknownLimiter
+func (a *App) GetRateLimiter(name string) (*ratelimit.Limiter, error) {
+	return a.limits.Get(name)
+}
+
 // RegisterTask регистрирует задачу в lifecycle
 func (a *App) RegisterTask(t task.Task) {
 	a.lifecycle.Register(t)
 }
 
+// Check выполняет dry-run проверку: валидирует конфигурацию, проверяет
+// доступность адресов прослушивания и вызывает предстартовые проверки задач.
+// Таймеры и задачи не запускаются
+func (a *App) Check(ctx context.Context) error {
+	ctx = appctx.WithIdentity(ctx, a.identity)
+	var errs []error
+
+	if err := a.config.Validate(); err != nil {
+		errs = append(errs, &apperr.ConfigError{Err: fmt.Errorf("invalid config: %w", err)})
+	}
+	if a.config.Metrics.Enabled {
+		if err := checkListen("metrics server", a.config.Metrics.Listen); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if a.config.Admin.Enabled {
+		if err := checkListen("admin server", a.config.Admin.Listen); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if a.config.GRPC.Enabled && !a.config.GRPC.Socket {
+		if err := checkListen("gRPC control server", a.config.GRPC.Listen); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if a.config.HTTP.Enabled {
+		if err := checkListen("HTTP server", a.config.HTTP.Listen); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := a.lifecycle.CheckAll(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("task checks: %w", err))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		a.log.Error("Startup check failed", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+
+	a.log.Info("Startup check passed", map[string]interface{}{
+		"timers": a.scheduler.GetTimerCount(),
+	})
+	return nil
+}
+
+// checkListen проверяет, что адрес компонента свободен для прослушивания
+func checkListen(component, addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return apperr.Listen(component, addr, err)
+	}
+	return l.Close()
+}
+
 // Run запускает приложение
 func (a *App) Run(ctx context.Context) error {
-	a.log.Info("Application starting", map[string]interface{}{
-		"service": ServiceName,
-		"version": "1.0.0",
-	})
+	// Прикрепляем идентичность сервиса к корневому контексту задач и таймеров
+	ctx = appctx.WithIdentity(ctx, a.identity)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	a.mu.Lock()
+	a.cancel = cancel
+	a.mu.Unlock()
+
+	a.log.Info("Application starting", appctx.Fields(ctx))
+	if a.config.Service.ReadOnly {
+		a.log.Warn("Read-only mode: state-mutating timers and tasks are disabled")
+	}
+	if a.config.Kubernetes.Enabled {
+		a.log.Info("Kubernetes mode", map[string]interface{}{
+			"pod":       a.pod.Name,
+			"namespace": a.pod.Namespace,
+			"node":      a.pod.Node,
+		})
+	}
+
+	// Длительность каждой задачи и сервера попадает в отчет о запуске
+	// и в startup_phase_duration_seconds
+	timing := a.startTiming()
 
-	// Запускаем все lifecycle задачи
-	if err := a.lifecycle.StartAll(ctx); err != nil {
+	// Запускаем все lifecycle задачи, сообщая менеджеру сервисов о каждой
+	tasks := 0
+	started := ""
+	a.lifecycle.SetProgress(func(step, total int, taskName string) {
+		tasks = total
+		if started != "" {
+			timing.phase("task:" + started)
+		} else {
+			timing.restart()
+		}
+		started = taskName
+		a.reportStarting(step, total, taskName)
+	})
+	// Задачи работают до своего BeforeStop: их контекст не отменяется
+	// с началом остановки, иначе очередь заданий, дочерние процессы
+	// и оповещения завершались бы раньше, чем StopAll даст им закончить
+	// работу в своей фазе. Отмена ctx во время запуска прерывает ожидание
+	// зависимостей
+	taskCtx, stopTasks := context.WithCancel(context.WithoutCancel(ctx))
+	defer stopTasks()
+	unwatch := context.AfterFunc(ctx, stopTasks)
+	err := a.lifecycle.StartAll(taskCtx)
+	unwatch()
+	if err != nil {
 		return fmt.Errorf("failed to start lifecycle tasks: %w", err)
 	}
+	if started != "" {
+		timing.phase("task:" + started)
+	}
+	a.persistInstanceID()
+
+	// Компоненты, запущенные после задач. При ошибке запуска следующего
+	// они останавливаются в обратном порядке вместе с задачами
+	var running []startedComponent
 
 	// Запускаем metrics сервер
+	a.reportStarting(tasks+1, tasks, "metrics")
 	if err := a.metrics.Start(ctx); err != nil {
-		return fmt.Errorf("failed to start metrics server: %w", err)
+		return a.abortStart(fmt.Errorf("failed to start metrics server: %w", err), running)
 	}
+	running = append(running, startedComponent{name: "metrics server", stop: a.metrics.Stop})
+	timing.phase("metrics")
 
 	// Запускаем планировщик
+	a.reportStarting(tasks+2, tasks, "scheduler")
 	if err := a.scheduler.Start(ctx); err != nil {
-		return fmt.Errorf("failed to start scheduler: %w", err)
+		return a.abortStart(fmt.Errorf("failed to start scheduler: %w", err), running)
 	}
+	running = append(running, startedComponent{name: "scheduler", stop: a.scheduler.Stop})
+	timing.phase("scheduler")
 
-	a.log.Info("Application started successfully")
+	// Запускаем admin сервер
+	a.reportStarting(tasks+3, tasks, "admin")
+	if err := a.admin.Start(ctx); err != nil {
+		return a.abortStart(fmt.Errorf("failed to start admin server: %w", err), running)
+	}
+	running = append(running, startedComponent{name: "admin server", stop: a.admin.Stop})
+	timing.phase("admin")
+
+	// Запускаем gRPC сервер управления
+	a.reportStarting(tasks+4, tasks, "control")
+	if err := a.control.Start(ctx); err != nil {
+		return a.abortStart(fmt.Errorf("failed to start gRPC control server: %w", err), running)
+	}
+	timing.phase("control")
+
+	// Открываем HTTP сервер приложения после запуска всех компонентов
+	a.http.SetReady(true)
+	a.setPhase(phaseRunning)
+
+	timing.finish()
+	a.log.Info("Service started", a.startupReport(ctx))
+	if r := a.startupReporter(); r != nil {
+		r.Started()
+	}
+	a.hooks.Run(ctx, hooks.PostStart, nil)
 
 	// Ждем отмены контекста
 	<-ctx.Done()
+	a.stopProbation()
 
 	a.log.Info("Application shutting down...")
+	// Длительность этапов остановки попадает в итоговый снимок метрик
+	shutdownStart := time.Now()
+	stageStart := shutdownStart
+	stage := func(name string) {
+		now := time.Now()
+		a.metrics.SetShutdownDuration(name, now.Sub(stageStart))
+		stageStart = now
+	}
+	a.setPhase(phaseStopping)
+	if r := a.startupReporter(); r != nil {
+		r.Stopping()
+	}
+	// /ready уже отвечает 503; запросы, пришедшие до исключения пода
+	// из endpoints, еще обслуживаются
+	a.drain()
+	a.http.SetReady(false)
+	stage("drain")
 
 	// Создаем контекст для graceful shutdown
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout())
 	defer cancel()
 
+	// Команды pre_stop выполняются, пока сервис еще работает
+	a.hooks.Run(shutdownCtx, hooks.PreStop, nil)
+	stage("pre_stop")
+
+	// Останавливаем gRPC сервер управления
+	if err := a.control.Stop(shutdownCtx); err != nil {
+		a.log.Error("Error stopping gRPC control server", map[string]interface{}{"error": err.Error()})
+	}
+
+	// Останавливаем admin сервер
+	if err := a.admin.Stop(shutdownCtx); err != nil {
+		a.log.Error("Error stopping admin server", map[string]interface{}{"error": err.Error()})
+	}
+	stage("servers")
+
 	// Останавливаем планировщик
 	if err := a.scheduler.Stop(shutdownCtx); err != nil {
 		a.log.Error("Error stopping scheduler", map[string]interface{}{"error": err.Error()})
 	}
+	stage("scheduler")
 
 	// Останавливаем lifecycle задачи
 	if err := a.lifecycle.StopAll(shutdownCtx); err != nil {
 		a.log.Error("Error stopping lifecycle tasks", map[string]interface{}{"error": err.Error()})
 	}
+	stage("tasks")
+	a.metrics.SetShutdownDuration("total", time.Since(shutdownStart))
 
-	// Останавливаем metrics сервер
+	// Сервер метрик еще отдает итоговый снимок, чтобы Prometheus забрал
+	// последние значения счетчиков, затем останавливается
+	a.metrics.FinalScrape(shutdownCtx, time.Duration(a.config.Metrics.FinalScrapeSeconds)*time.Second)
 	if err := a.metrics.Stop(shutdownCtx); err != nil {
 		a.log.Error("Error stopping metrics server", map[string]interface{}{"error": err.Error()})
 	}
@@ -114,5 +1083,37 @@ func (a *App) Run(ctx context.Context) error {
 	a.log.Info("Application stopped gracefully")
 	a.log.Flush()
 
+	a.mu.Lock()
+	reason := a.restartReason
+	a.mu.Unlock()
+	if reason != "" {
+		return fmt.Errorf("%w: %s", ErrRestartRequested, reason)
+	}
+
 	return nil
 }
+
+// startedComponent компонент, запущенный в Run после lifecycle задач
+type startedComponent struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// abortStart останавливает после ошибки запуска уже запущенные компоненты
+// в обратном порядке и lifecycle задачи. Возвращает ошибку запуска вместе
+// с ошибками остановки
+func (a *App) abortStart(err error, running []startedComponent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout())
+	defer cancel()
+
+	errs := []error{err}
+	for i := len(running) - 1; i >= 0; i-- {
+		if stopErr := running[i].stop(ctx); stopErr != nil {
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", running[i].name, stopErr))
+		}
+	}
+	if stopErr := a.lifecycle.StopAll(ctx); stopErr != nil {
+		errs = append(errs, fmt.Errorf("failed to stop lifecycle tasks: %w", stopErr))
+	}
+	return errors.Join(errs...)
+}
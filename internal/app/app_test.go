@@ -2,10 +2,27 @@ package app
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+
+	"service-boilerplate/internal/appctx"
+	"service-boilerplate/internal/buildinfo"
 	"service-boilerplate/internal/config"
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/jobs"
+	"service-boilerplate/internal/k8s"
 	"service-boilerplate/internal/logger"
 	"service-boilerplate/internal/task"
 )
@@ -88,6 +105,21 @@ func TestNew(t *testing.T) {
 	}
 }
 
+// TestNewWithRegistry проверяет создание двух приложений с общим registry метрик
+func TestNewWithRegistry(t *testing.T) {
+	_, cfg, log := setupTestApp(t)
+	defer log.Close()
+	cfg.Metrics.Enabled = true
+
+	registry := prometheus.NewRegistry()
+	first := NewWithRegistry(cfg, log, registry)
+	second := NewWithRegistry(cfg, log, registry)
+
+	if first.metrics.Registry() != registry || second.metrics.Registry() != registry {
+		t.Error("metrics server does not use the provided registry")
+	}
+}
+
 // TestGetScheduler возвращает планировщик
 func TestGetScheduler(t *testing.T) {
 	app, _, log := setupTestApp(t)
@@ -270,6 +302,166 @@ func TestGracefulShutdown(t *testing.T) {
 	}
 }
 
+// TestGracefulShutdown_DrainsJobs проверяет, что задания, выполняемое
+// и ожидающее в очереди, завершаются при остановке, а не прерываются
+// с ее началом
+func TestGracefulShutdown_DrainsJobs(t *testing.T) {
+	app, _, log := setupTestApp(t)
+	defer log.Close()
+
+	started := make(chan struct{}, 2)
+	var mu sync.Mutex
+	var done, canceled int
+	app.GetJobs().Register("work", func(ctx context.Context, job *jobs.Job) error {
+		started <- struct{}{}
+		select {
+		case <-time.After(200 * time.Millisecond):
+			mu.Lock()
+			done++
+			mu.Unlock()
+			return nil
+		case <-ctx.Done():
+			mu.Lock()
+			canceled++
+			mu.Unlock()
+			return ctx.Err()
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- app.Run(ctx)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for app.Phase() != phaseRunning && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := app.GetJobs().Enqueue("work", nil); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+	<-started
+	cancel()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not complete graceful shutdown in time")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if done != 2 || canceled != 0 {
+		t.Errorf("jobs done = %d, canceled = %d, want 2 and 0", done, canceled)
+	}
+}
+
+// ctxProbeTask запоминает контекст AfterStart и его состояние в BeforeStop
+type ctxProbeTask struct {
+	ctx        context.Context
+	errAtStop  error
+	stopCalled bool
+}
+
+func (p *ctxProbeTask) Name() string {
+	return "ctx-probe"
+}
+
+func (p *ctxProbeTask) AfterStart(ctx context.Context) error {
+	p.ctx = ctx
+	return nil
+}
+
+func (p *ctxProbeTask) BeforeStop(ctx context.Context) error {
+	p.stopCalled = true
+	p.errAtStop = p.ctx.Err()
+	return nil
+}
+
+// TestRun_TaskContextOutlivesShutdown проверяет, что контекст задач
+// не отменяется с началом остановки и завершается только после StopAll
+func TestRun_TaskContextOutlivesShutdown(t *testing.T) {
+	app, _, log := setupTestApp(t)
+	defer log.Close()
+
+	probe := &ctxProbeTask{}
+	app.RegisterTask(probe)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- app.Run(ctx)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for app.Phase() != phaseRunning && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not complete graceful shutdown in time")
+	}
+	if !probe.stopCalled {
+		t.Fatal("BeforeStop was not called")
+	}
+	if probe.errAtStop != nil {
+		t.Errorf("task context in BeforeStop = %v, want not canceled", probe.errAtStop)
+	}
+	if probe.ctx.Err() == nil {
+		t.Error("task context is not canceled after Run returned")
+	}
+}
+
+// TestRun_ServerStartFailure проверяет, что при ошибке запуска admin
+// сервера уже запущенные компоненты и задачи останавливаются
+func TestRun_ServerStartFailure(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+
+	tmpDir := t.TempDir()
+	log, err := logger.New("test-app", tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	defer log.Close()
+	cfg := &config.Config{
+		Service:   config.ServiceConfig{LogDir: tmpDir},
+		Scheduler: config.SchedulerConfig{MaxPanicRestarts: 3, BackoffSeconds: 1},
+		Metrics:   config.MetricsConfig{Enabled: true, Listen: "127.0.0.1:0"},
+		Admin:     config.AdminConfig{Enabled: true, Listen: busy.Addr().String()},
+	}
+	app := New(cfg, log)
+	task := &mockTask{name: "probe"}
+	app.RegisterTask(task)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = app.Run(ctx)
+	if err == nil || !strings.Contains(err.Error(), "failed to start admin server") {
+		t.Fatalf("Run() error = %v, want admin server start failure", err)
+	}
+	if !task.stopped {
+		t.Error("lifecycle task was not stopped after a start failure")
+	}
+	if conn, err := net.Dial("tcp", app.metrics.GetAddress()); err == nil {
+		conn.Close()
+		t.Errorf("metrics server still listens on %s", app.metrics.GetAddress())
+	}
+}
+
 // TestRun_WithMetricsEnabled запуск с включенными метриками
 func TestRun_WithMetricsEnabled(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -319,3 +511,541 @@ func TestApp_ImplementsTaskInterface(t *testing.T) {
 	// Этот тест проверяет что наши моки реализуют интерфейс
 	var _ task.Task = &mockTask{}
 }
+
+// TestRequestRestart проверяет остановку приложения с ErrRestartRequested
+func TestRequestRestart(t *testing.T) {
+	app, _, log := setupTestApp(t)
+	defer log.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run(context.Background())
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	app.RequestRestart("test")
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrRestartRequested) {
+			t.Errorf("Run() error = %v, want ErrRestartRequested", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Run() did not complete after restart request")
+	}
+}
+
+// TestRun_IdentityInContext проверяет передачу идентичности в контекст таймеров
+func TestRun_IdentityInContext(t *testing.T) {
+	app, _, log := setupTestApp(t)
+	defer log.Close()
+
+	got := make(chan appctx.Identity, 1)
+	app.GetScheduler().AddTimer("identity-timer", 50*time.Millisecond, func(ctx context.Context) {
+		id, _ := appctx.FromContext(ctx)
+		select {
+		case got <- id:
+		default:
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run(ctx)
+	}()
+
+	select {
+	case id := <-got:
+		if id.Service != ServiceName {
+			t.Errorf("Service = %q, want %q", id.Service, ServiceName)
+		}
+		if id.InstanceID == "" || id.InstanceID != app.Identity().InstanceID {
+			t.Errorf("InstanceID = %q, want %q", id.InstanceID, app.Identity().InstanceID)
+		}
+		if id.Version != buildinfo.Version {
+			t.Errorf("Version = %q, want %q", id.Version, buildinfo.Version)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timer was not executed")
+	}
+
+	cancel()
+	<-done
+}
+
+// TestIdentity_Persisted проверяет, что идентификатор экземпляра
+// сохраняется в хранилище только запуском и переопределяется
+// service.instance_id
+func TestIdentity_Persisted(t *testing.T) {
+	_, cfg, log := setupTestApp(t)
+	defer log.Close()
+	cfg.Store = config.StoreConfig{Enabled: true, Path: filepath.Join(cfg.Service.LogDir, "data", "state.db")}
+
+	// Проверка без запуска не создает хранилище
+	checked := New(cfg, log)
+	if err := checked.Check(context.Background()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if _, err := os.Stat(cfg.Store.Path); !os.IsNotExist(err) {
+		t.Fatalf("store file after Check() = %v, want not created", err)
+	}
+
+	app := New(cfg, log)
+	first := app.Identity()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run(ctx)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for app.Phase() != phaseRunning && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	second := New(cfg, log).Identity()
+	if first.InstanceID == "" || first.InstanceID != second.InstanceID {
+		t.Errorf("InstanceID = %q, then %q; want stable", first.InstanceID, second.InstanceID)
+	}
+	if host, _ := os.Hostname(); first.Hostname != host {
+		t.Errorf("Hostname = %q, want %q", first.Hostname, host)
+	}
+
+	cfg.Service.InstanceID = "node-a"
+	if got := New(cfg, log).Identity().InstanceID; got != "node-a" {
+		t.Errorf("InstanceID = %q, want node-a from config", got)
+	}
+}
+
+// checkTask задача с предстартовой проверкой
+type checkTask struct {
+	mockTask
+	checkError error
+}
+
+func (c *checkTask) Check(ctx context.Context) error {
+	return c.checkError
+}
+
+// TestCheck проверяет dry-run проверку без запуска компонентов
+func TestCheck(t *testing.T) {
+	app, _, log := setupTestApp(t)
+	defer log.Close()
+
+	ok := &checkTask{mockTask: mockTask{name: "ok"}}
+	app.RegisterTask(ok)
+
+	if err := app.Check(context.Background()); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+	if ok.started {
+		t.Error("Check() must not start tasks")
+	}
+
+	app.RegisterTask(&checkTask{mockTask: mockTask{name: "bad"}, checkError: errors.New("db unreachable")})
+	if err := app.Check(context.Background()); err == nil {
+		t.Error("Check() expected error from failing task check")
+	}
+}
+
+// TestRun_StartupReport проверяет запись о запуске с версией, хэшем
+// конфигурации, возможностями и количеством таймеров и задач
+func TestRun_StartupReport(t *testing.T) {
+	app, cfg, log := setupTestApp(t)
+	defer log.Close()
+	ring := logger.NewRing(100)
+	log.SetRing(ring)
+	cfg.Tracing.Enabled = true
+	app.GetScheduler().AddTimer("report-timer", time.Hour, func(ctx context.Context) {})
+	app.RegisterTask(&mockTask{name: "report-task"})
+	app.RecordStartupPhase("config", 5*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run(ctx)
+	}()
+
+	var entry *logger.LogEntry
+	for deadline := time.Now().Add(time.Second); entry == nil && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		for _, e := range ring.Entries(logger.InfoLevel, 0) {
+			if e.Message == "Service started" {
+				entry = &e
+				break
+			}
+		}
+	}
+	cancel()
+	<-done
+
+	if entry == nil {
+		t.Fatal("startup report was not logged")
+	}
+	for key, want := range map[string]interface{}{
+		"version":     buildinfo.Version,
+		"pid":         os.Getpid(),
+		"config_hash": cfg.Hash(),
+		"features":    "tracing",
+		"timers":      1,
+		"tasks":       app.lifecycle.Count(),
+	} {
+		if got := entry.Fields[key]; got != want {
+			t.Errorf("field %s = %v, want %v", key, got, want)
+		}
+	}
+	if entry.Fields["instance_id"] != app.Identity().InstanceID || entry.Fields["hostname"] == nil {
+		t.Errorf("startup report fields = %v", entry.Fields)
+	}
+
+	// Этапы: записанные до Run, затем каждая задача и серверы по порядку
+	var phases []string
+	for _, p := range app.StartupPhases() {
+		phases = append(phases, p.Name)
+	}
+	got := strings.Join(phases, ",")
+	if !strings.HasPrefix(got, "config,") || !strings.Contains(got, ",task:report-task,metrics,scheduler,admin,control") {
+		t.Errorf("StartupPhases() = %s", got)
+	}
+	if _, ok := entry.Fields["startup_ms"]; !ok || entry.Fields["startup_phases"] == nil {
+		t.Errorf("startup report has no phase timing: %v", entry.Fields)
+	}
+}
+
+// recordingReporter записывает ход запуска и остановки
+type recordingReporter struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recordingReporter) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recordingReporter) Starting(p StartupProgress) {
+	r.record(fmt.Sprintf("%d/%d %s", p.Step, p.Total, p.Task))
+}
+
+func (r *recordingReporter) Started()  { r.record("started") }
+func (r *recordingReporter) Stopping() { r.record("stopping") }
+
+// TestRun_StartupProgress проверяет шаги запуска, переданные менеджеру сервисов
+func TestRun_StartupProgress(t *testing.T) {
+	app, _, log := setupTestApp(t)
+	defer log.Close()
+	app.RegisterTask(&mockTask{name: "progress-task"})
+	reporter := &recordingReporter{}
+	app.SetStartupReporter(reporter)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run(ctx)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	n := app.lifecycle.Count()
+	if len(reporter.events) < 7 {
+		t.Fatalf("events = %v", reporter.events)
+	}
+	got := reporter.events[len(reporter.events)-7:]
+	want := []string{
+		fmt.Sprintf("%d/%d progress-task", n, n+4),
+		fmt.Sprintf("%d/%d metrics", n+1, n+4),
+		fmt.Sprintf("%d/%d scheduler", n+2, n+4),
+		fmt.Sprintf("%d/%d admin", n+3, n+4),
+		fmt.Sprintf("%d/%d control", n+4, n+4),
+		"started",
+		"stopping",
+	}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("events = %v, want ending with %v", reporter.events, want)
+	}
+}
+
+// TestReload проверяет применение уровня логирования при перезагрузке
+// и сохранение конфигурации, не прошедшей проверку
+func TestReload(t *testing.T) {
+	app, _, log := setupTestApp(t)
+	defer log.Close()
+
+	if _, err := app.Reload(); !errors.Is(err, ErrReloadUnavailable) {
+		t.Fatalf("Reload() without reloader error = %v, want ErrReloadUnavailable", err)
+	}
+
+	next := config.Default()
+	next.Service.LogLevel = "debug"
+	next.Scheduler.MaxConcurrentRuns = 2
+	app.SetReloader(func() (*config.Config, error) { return next, nil })
+
+	status, err := app.Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if status.Result != config.ReloadSuccess {
+		t.Errorf("Result = %q, want %q", status.Result, config.ReloadSuccess)
+	}
+	if log.GetLevel() != logger.DebugLevel {
+		t.Errorf("log level = %v, want debug", log.GetLevel())
+	}
+	restart := strings.Join(status.RestartRequired, ",")
+	if !strings.Contains(restart, "scheduler.max_concurrent_runs") || strings.Contains(restart, "service.log_level") {
+		t.Errorf("RestartRequired = %v", status.RestartRequired)
+	}
+
+	// Повторная перезагрузка той же конфигурации ничего не меняет
+	if status, _ := app.Reload(); len(status.Changes) != 0 {
+		t.Errorf("second Reload() changes = %v, want none", status.Changes)
+	}
+
+	invalid := config.Default()
+	invalid.Service.LogLevel = "verbose"
+	app.SetReloader(func() (*config.Config, error) { return invalid, nil })
+	status, err = app.Reload()
+	if err == nil || status.Result != config.ReloadValidationFailed {
+		t.Fatalf("Reload() invalid = %+v, %v, want validation failure", status, err)
+	}
+	if log.GetLevel() != logger.DebugLevel {
+		t.Errorf("log level changed by invalid config: %v", log.GetLevel())
+	}
+	if last, ok := app.LastReload(); !ok || last.Result != config.ReloadValidationFailed {
+		t.Errorf("LastReload() = %+v, %v", last, ok)
+	}
+}
+
+// TestReload_TimerSettings проверяет применение интервала таймера без перезапуска
+func TestReload_TimerSettings(t *testing.T) {
+	app, _, log := setupTestApp(t)
+	defer log.Close()
+
+	sched := app.GetScheduler()
+	if err := sched.AddTimer("report", time.Hour, func(ctx context.Context) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	next := config.Default()
+	next.Scheduler.Timers = map[string]config.TimerConfig{"report": {IntervalSeconds: 60, TimeoutSeconds: 30}}
+	app.SetReloader(func() (*config.Config, error) { return next, nil })
+	status, err := app.Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	for _, key := range status.RestartRequired {
+		if key == "scheduler.timers.report.interval_seconds" || key == "scheduler.timers.report.timeout_seconds" {
+			t.Errorf("RestartRequired contains live key %s", key)
+		}
+	}
+	if interval := sched.ListTimers()[0].Interval; interval != time.Minute {
+		t.Errorf("interval after reload = %v, want 1m", interval)
+	}
+
+	// Удаленные настройки возвращают интервал из кода
+	next = config.Default()
+	if _, err := app.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if interval := sched.ListTimers()[0].Interval; interval != time.Hour {
+		t.Errorf("interval after settings removed = %v, want 1h", interval)
+	}
+}
+
+// TestReload_Rollback проверяет откат конфигурации при сбое таймера
+// в испытательный срок и сохранение конфигурации без сбоев
+func TestReload_Rollback(t *testing.T) {
+	app, _, log := setupTestApp(t)
+	defer log.Close()
+	sub := app.events.Subscribe(10, events.TypeConfigRollback)
+	defer sub.Close()
+
+	bad := config.Default()
+	bad.Service.LogLevel = "debug"
+	bad.Reload.Rollback = true
+	app.SetReloader(func() (*config.Config, error) { return bad, nil })
+	if _, err := app.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if log.GetLevel() != logger.DebugLevel {
+		t.Fatalf("log level = %v, want debug", log.GetLevel())
+	}
+
+	// Успешный запуск таймера не считается сбоем
+	app.events.Publish(events.TypeTimerRun, map[string]interface{}{"timer": "report", "status": "ok"})
+	app.events.Publish(events.TypeTimerRun, map[string]interface{}{"timer": "report", "status": "panic"})
+
+	select {
+	case e := <-sub.C:
+		if data := e.Data.(map[string]interface{}); data["reason"] != "timer report panicked" {
+			t.Errorf("rollback event data = %v", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not rolled back")
+	}
+	if log.GetLevel() != logger.InfoLevel {
+		t.Errorf("log level after rollback = %v, want info", log.GetLevel())
+	}
+	if last, ok := app.LastReload(); !ok || last.Result != config.ReloadRolledBack {
+		t.Errorf("LastReload() = %+v, %v, want rolled_back", last, ok)
+	}
+	if app.current() != app.config {
+		t.Error("active config was not restored")
+	}
+
+	// Без reload.rollback сбой конфигурацию не меняет
+	next := config.Default()
+	next.Service.LogLevel = "warn"
+	app.SetReloader(func() (*config.Config, error) { return next, nil })
+	if _, err := app.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	app.events.Publish(events.TypeTimerDisabled, map[string]interface{}{"timer": "report"})
+	select {
+	case <-sub.C:
+		t.Error("config rolled back with reload.rollback disabled")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if log.GetLevel() != logger.WarnLevel {
+		t.Errorf("log level = %v, want warn", log.GetLevel())
+	}
+}
+
+// TestProbationFailure проверяет события, которые считаются сбоем
+// в испытательный срок
+func TestProbationFailure(t *testing.T) {
+	tests := []struct {
+		typ  string
+		data map[string]interface{}
+		want string
+	}{
+		{events.TypeTimerRun, map[string]interface{}{"timer": "report", "status": "ok"}, ""},
+		{events.TypeTimerRun, map[string]interface{}{"timer": "report", "status": "panic"}, "timer report panicked"},
+		{events.TypeTimerDisabled, map[string]interface{}{"timer": "report"}, "timer report disabled"},
+		{events.TypeHealthStatus, map[string]interface{}{"from": "degraded", "to": "healthy"}, ""},
+		{events.TypeHealthStatus, map[string]interface{}{"from": "healthy", "to": "degraded"}, "service health is degraded"},
+		{events.TypeHealthStatus, map[string]interface{}{"from": "degraded", "to": "unhealthy"}, "service health is unhealthy"},
+	}
+	for _, tt := range tests {
+		if got := probationFailure(events.Event{Type: tt.typ, Data: tt.data}); got != tt.want {
+			t.Errorf("probationFailure(%s, %v) = %q, want %q", tt.typ, tt.data, got, tt.want)
+		}
+	}
+}
+
+// TestReady_Dependencies проверяет, что /ready снимает готовность при
+// отказе критичной внешней зависимости и не снимает при отказе некритичной
+func TestReady_Dependencies(t *testing.T) {
+	tmpDir := t.TempDir()
+	log, err := logger.New("test-app", tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	missing := filepath.Join(tmpDir, "missing")
+	cfg := &config.Config{
+		Service: config.ServiceConfig{LogDir: tmpDir},
+		Health: config.HealthConfig{Dependencies: []config.DependencyConfig{
+			{Name: "share", Type: "file", Endpoint: tmpDir, Critical: true},
+			{Name: "crm", Type: "file", Endpoint: missing},
+		}},
+	}
+	app := New(cfg, log)
+	app.setPhase(phaseRunning)
+
+	ready := func() (int, Readiness) {
+		rec := httptest.NewRecorder()
+		app.handleReady(rec, httptest.NewRequest("GET", "/ready", nil))
+		var resp Readiness
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp
+	}
+	app.GetHealth().Run(context.Background())
+	if code, resp := ready(); code != http.StatusOK || len(resp.Dependencies) != 2 {
+		t.Errorf("/ready with optional dependency down = %d %+v, want 200", code, resp)
+	}
+
+	cfg.Health.Dependencies[0].Endpoint = missing
+	app = New(cfg, log)
+	app.setPhase(phaseRunning)
+	app.GetHealth().Run(context.Background())
+	if code, resp := ready(); code != http.StatusServiceUnavailable || resp.Status != "not_ready" {
+		t.Errorf("/ready with critical dependency down = %d %+v, want 503", code, resp)
+	}
+}
+
+// TestRun_Kubernetes проверяет метки пода в логе, готовность по фазе
+// жизненного цикла и паузу drain после отмены
+func TestRun_Kubernetes(t *testing.T) {
+	t.Setenv(k8s.EnvPodName, "web-7d9f")
+	t.Setenv(k8s.EnvPodNamespace, "prod")
+	t.Setenv(k8s.EnvNodeName, "node-1")
+	tmpDir := t.TempDir()
+	log, err := logger.New("test-app", tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	cfg := &config.Config{
+		Service:    config.ServiceConfig{LogDir: tmpDir},
+		Kubernetes: config.KubernetesConfig{Enabled: true, DrainSeconds: 1, TerminationGracePeriodSeconds: 3},
+	}
+	app := New(cfg, log)
+	if got := app.shutdownTimeout(); got != 2*time.Second {
+		t.Errorf("shutdownTimeout() = %v, want 2s", got)
+	}
+
+	ready := func() int {
+		rec := httptest.NewRecorder()
+		app.handleReady(rec, httptest.NewRequest("GET", "/ready", nil))
+		return rec.Code
+	}
+	if code := ready(); code != http.StatusServiceUnavailable {
+		t.Errorf("/ready before Run = %d, want 503", code)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run(ctx)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for app.Phase() != phaseRunning && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if code := ready(); code != http.StatusOK {
+		t.Fatalf("/ready while running = %d, want 200", code)
+	}
+
+	start := time.Now()
+	cancel()
+	time.Sleep(100 * time.Millisecond)
+	if code := ready(); code != http.StatusServiceUnavailable {
+		t.Errorf("/ready while draining = %d, want 503", code)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("shutdown took %v, want at least drain_seconds", elapsed)
+	}
+
+	log.Flush()
+	content, err := os.ReadFile(logger.FilePath(tmpDir, "test-app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `"labels":{"k8s_namespace":"prod","k8s_node":"node-1","k8s_pod":"web-7d9f"}`) {
+		t.Error("log entries are not labeled with the pod")
+	}
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strings"
+
+	"service-boilerplate/internal/appctx"
+	"service-boilerplate/internal/buildinfo"
+)
+
+// startupReport возвращает поля записи о запуске сервиса: версию, хост,
+// платформу, хэш конфигурации, включенные возможности, количество
+// таймеров и задач и длительность этапов запуска. Это первое, о чем
+// спрашивает поддержка
+func (a *App) startupReport(ctx context.Context) map[string]interface{} {
+	info := buildinfo.Get()
+	fields := appctx.Fields(ctx)
+	fields["version"] = info.Version
+	fields["commit"] = info.Commit
+	fields["go_version"] = info.GoVersion
+	fields["os"] = runtime.GOOS + "/" + runtime.GOARCH
+	fields["pid"] = os.Getpid()
+	fields["config_hash"] = a.config.Hash()
+	fields["features"] = strings.Join(a.features(), ",")
+	fields["timers"] = a.scheduler.GetTimerCount()
+	fields["tasks"] = a.lifecycle.Count()
+	if hostname, err := os.Hostname(); err == nil {
+		fields["hostname"] = hostname
+	}
+	a.mu.Lock()
+	fields["startup_ms"] = a.startupTotal.Milliseconds()
+	a.mu.Unlock()
+	fields["startup_phases"] = a.StartupPhases()
+	return fields
+}
+
+// features возвращает включенные в конфигурации возможности
+func (a *App) features() []string {
+	cfg := a.config
+	var features []string
+	for _, f := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"metrics", cfg.Metrics.Enabled},
+		{"admin", cfg.Admin.Enabled},
+		{"control_socket", cfg.Admin.Socket},
+		{"grpc", cfg.GRPC.Enabled},
+		{"http", cfg.HTTP.Enabled},
+		{"read_only", cfg.Service.ReadOnly},
+		{"store", cfg.Store.Enabled && !cfg.Service.ReadOnly},
+		{"election", cfg.Election.Enabled && !cfg.Service.ReadOnly},
+		{"watcher", len(cfg.Watcher.Watches) > 0},
+		{"database", cfg.Database.Enabled},
+		{"redis", cfg.Redis.Enabled},
+		{"processes", len(cfg.Processes) > 0},
+		{"alerting", cfg.Alerting.Enabled},
+		{"profiling", cfg.Profiling.Enabled},
+		{"tracing", cfg.Tracing.Enabled},
+		{"watchdog", cfg.Watchdog.Enabled},
+		{"kubernetes", cfg.Kubernetes.Enabled},
+	} {
+		if f.enabled {
+			features = append(features, f.name)
+		}
+	}
+	return features
+}
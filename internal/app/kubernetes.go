@@ -0,0 +1,102 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"service-boilerplate/internal/health"
+)
+
+// Фазы жизненного цикла экземпляра для /ready
+const (
+	phaseStarting = "starting"
+	phaseRunning  = "running"
+	phaseStopping = "stopping"
+)
+
+// defaultShutdownTimeout время на остановку компонентов вне Kubernetes
+const defaultShutdownTimeout = 30 * time.Second
+
+// Readiness ответ /ready. Dependencies - последние результаты проверок
+// внешних зависимостей (health.dependencies)
+type Readiness struct {
+	Status       string          `json:"status"`
+	Phase        string          `json:"phase"`
+	Dependencies []health.Result `json:"dependencies,omitempty"`
+}
+
+// setPhase задает фазу жизненного цикла экземпляра
+func (a *App) setPhase(phase string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.phase = phase
+}
+
+// Phase возвращает фазу жизненного цикла: starting, running или stopping
+func (a *App) Phase() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.phase == "" {
+		return phaseStarting
+	}
+	return a.phase
+}
+
+// handleReady обрабатывает /ready: экземпляр готов принимать трафик,
+// когда все компоненты запущены, остановка не началась и не отказала
+// критичная внешняя зависимость. Зависимости не проверяются на каждый
+// запрос: используются результаты последних фоновых проверок health.
+// Проверки компонентов (db, redis) на готовность не влияют
+func (a *App) handleReady(w http.ResponseWriter, r *http.Request) {
+	phase := a.Phase()
+	resp := Readiness{Status: "ready", Phase: phase}
+	code := http.StatusOK
+	if phase != phaseRunning {
+		resp.Status = "not_ready"
+		code = http.StatusServiceUnavailable
+	}
+	if deps := a.config.Health.Dependencies; len(deps) > 0 && a.health != nil {
+		report, _ := a.health.Last()
+		critical := make(map[string]bool, len(deps))
+		for _, dep := range deps {
+			critical[dep.Name] = dep.Critical
+		}
+		for _, result := range report.Checks {
+			if _, ok := critical[result.Name]; !ok {
+				continue
+			}
+			resp.Dependencies = append(resp.Dependencies, result)
+			if critical[result.Name] && result.Status == health.StatusUnhealthy {
+				resp.Status = "not_ready"
+				code = http.StatusServiceUnavailable
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// drain в режиме Kubernetes продолжает обслуживать запросы после SIGTERM
+// со снятой готовностью, пока под исключается из endpoints сервиса
+func (a *App) drain() {
+	if !a.config.Kubernetes.Enabled {
+		return
+	}
+	d := time.Duration(a.config.Kubernetes.DrainSeconds) * time.Second
+	a.log.Info("Draining before shutdown", map[string]interface{}{"drain": d.String()})
+	time.Sleep(d)
+}
+
+// shutdownTimeout возвращает время на остановку компонентов вместе
+// с ожиданием итогового опроса метрик. В режиме Kubernetes остановка
+// укладывается в terminationGracePeriodSeconds за вычетом паузы drain,
+// после чего kubelet отправляет SIGKILL
+func (a *App) shutdownTimeout() time.Duration {
+	k := a.config.Kubernetes
+	if !k.Enabled {
+		return defaultShutdownTimeout + time.Duration(a.config.Metrics.FinalScrapeSeconds)*time.Second
+	}
+	return time.Duration(k.TerminationGracePeriodSeconds-k.DrainSeconds) * time.Second
+}
@@ -0,0 +1,191 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"service-boilerplate/internal/config"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/scheduler"
+)
+
+// ErrReloadUnavailable возвращается из Reload, если источник конфигурации
+// не задан через SetReloader
+var ErrReloadUnavailable = errors.New("config reload is not configured")
+
+// liveKeys ключи конфигурации, которые применяются без перезапуска
+var liveKeys = map[string]bool{
+	"service.log_level":        true,
+	"scheduler.reload_policy":  true,
+	"reload.rollback":          true,
+	"reload.probation_seconds": true,
+}
+
+// liveTimerKeys ключи scheduler.timers.<name>, которые применяются без перезапуска
+var liveTimerKeys = []string{"interval_seconds", "timeout_seconds", "reload_policy"}
+
+// isLive сообщает, что ключ применяется без перезапуска
+func isLive(key string) bool {
+	if liveKeys[key] {
+		return true
+	}
+	rest, ok := strings.CutPrefix(key, "scheduler.timers.")
+	if !ok {
+		return false
+	}
+	for _, k := range liveTimerKeys {
+		if strings.HasSuffix(rest, "."+k) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetReloader задает функцию загрузки конфигурации для перезагрузки
+// (SIGHUP, команда SCM, POST /config/reload). Функция должна применять
+// те же переопределения флагов, что и при запуске. Вызывается до Run
+func (a *App) SetReloader(load func() (*config.Config, error)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.reloader = load
+}
+
+// Reload загружает и проверяет конфигурацию, записывает в лог изменения
+// относительно действующей конфигурации и применяет ключи, которые
+// меняются без перезапуска (service.log_level, интервал и таймаут
+// таймеров в scheduler.timers). Остальные изменения
+// перечисляются в RestartRequired и вступают в силу после перезапуска.
+// При ошибке загрузки или проверки действующая конфигурация сохраняется.
+// С reload.rollback сбой в испытательный срок возвращает последнюю
+// конфигурацию, его прошедшую
+func (a *App) Reload() (config.ReloadStatus, error) {
+	a.mu.Lock()
+	load := a.reloader
+	a.mu.Unlock()
+	if load == nil {
+		return config.ReloadStatus{}, ErrReloadUnavailable
+	}
+
+	// Перезагрузки выполняются по одной, чтобы diff строился от последней примененной
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	status := config.ReloadStatus{Time: time.Now().UTC(), Changes: []config.Change{}}
+	cfg, err := load()
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err == nil {
+		status.Changes, err = config.Diff(a.current(), cfg)
+	}
+	if err != nil {
+		status.Result = config.ReloadValidationFailed
+		status.Error = err.Error()
+		a.finishReload(status)
+		a.log.Warn("Config reload failed, keeping current config", map[string]interface{}{"error": err.Error()})
+		return status, fmt.Errorf("config reload: %w", err)
+	}
+
+	a.applyLive(a.current(), cfg)
+	for _, c := range status.Changes {
+		if !isLive(c.Key) {
+			status.RestartRequired = append(status.RestartRequired, c.Key)
+		}
+	}
+	status.Result = config.ReloadSuccess
+	a.mu.Lock()
+	a.active = cfg
+	good := a.knownGood
+	a.mu.Unlock()
+	if good == nil {
+		good = a.config
+	}
+	a.finishReload(status)
+	if len(status.Changes) > 0 {
+		a.startProbation(good, cfg)
+	}
+
+	a.log.Info("Config reloaded", map[string]interface{}{
+		"changes":          status.Changes,
+		"changed":          len(status.Changes),
+		"restart_required": status.RestartRequired,
+		"config_hash":      cfg.Hash(),
+	})
+	return status, nil
+}
+
+// applyLive применяет ключи конфигурации to, которые меняются без перезапуска
+func (a *App) applyLive(from, to *config.Config) {
+	level, _ := logger.ParseLevel(to.Service.LogLevel)
+	a.log.SetLevel(level)
+	a.applyTimerSettings(from, to)
+}
+
+// timerSettings возвращает интервал и таймаут таймеров из scheduler.timers
+func timerSettings(cfg *config.Config) map[string]scheduler.TimerSettings {
+	settings := make(map[string]scheduler.TimerSettings, len(cfg.Scheduler.Timers))
+	for name, t := range cfg.Scheduler.Timers {
+		settings[name] = scheduler.TimerSettings{
+			Interval: time.Duration(t.IntervalSeconds) * time.Second,
+			Timeout:  time.Duration(t.TimeoutSeconds) * time.Second,
+		}
+	}
+	return settings
+}
+
+// applyTimerSettings применяет интервал и таймаут таймеров новой
+// конфигурации to. Таймер, удаленный из scheduler.timers, возвращается
+// к интервалу из кода без таймаута
+func (a *App) applyTimerSettings(from, to *config.Config) {
+	settings := timerSettings(to)
+	for name := range from.Scheduler.Timers {
+		if _, ok := settings[name]; !ok {
+			settings[name] = scheduler.TimerSettings{}
+		}
+	}
+	for name, s := range settings {
+		policy := to.Scheduler.ReloadPolicy
+		if p := to.Scheduler.Timers[name].ReloadPolicy; p != "" {
+			policy = p
+		}
+		// Настройки таймеров, не добавленных в планировщик, пропускаются (ErrTimerNotFound)
+		a.scheduler.Reconfigure(name, s, scheduler.ReloadPolicy(policy))
+	}
+}
+
+// LastReload возвращает результат последней перезагрузки конфигурации.
+// false - перезагрузок не было
+func (a *App) LastReload() (config.ReloadStatus, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.lastReload == nil {
+		return config.ReloadStatus{}, false
+	}
+	return *a.lastReload, true
+}
+
+// Config возвращает действующую конфигурацию: последнюю примененную
+// перезагрузкой или загруженную при запуске
+func (a *App) Config() *config.Config {
+	return a.current()
+}
+
+// current возвращает последнюю успешно загруженную конфигурацию
+func (a *App) current() *config.Config {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.active != nil {
+		return a.active
+	}
+	return a.config
+}
+
+// finishReload сохраняет результат перезагрузки и записывает метрики
+func (a *App) finishReload(status config.ReloadStatus) {
+	a.mu.Lock()
+	a.lastReload = &status
+	a.mu.Unlock()
+	a.metrics.RecordConfigReload(status.Time, status.Result == config.ReloadSuccess)
+}
@@ -0,0 +1,137 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"service-boilerplate/internal/config"
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/health"
+)
+
+// probationEvents события, по которым перезагруженная конфигурация
+// считается сбойной в испытательный срок
+var probationEvents = []string{events.TypeTimerRun, events.TypeTimerDisabled, events.TypeHealthStatus}
+
+// startProbation начинает испытательный срок конфигурации applied:
+// сбой компонентов до его окончания возвращает конфигурацию good.
+// Предыдущий испытательный срок прерывается. Вызывается под reloadMu
+func (a *App) startProbation(good, applied *config.Config) {
+	a.stopProbation()
+	if !applied.Reload.Rollback {
+		a.mu.Lock()
+		a.knownGood = applied
+		a.mu.Unlock()
+		return
+	}
+
+	window := time.Duration(applied.Reload.ProbationSeconds) * time.Second
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := a.events.Subscribe(16, probationEvents...)
+	a.mu.Lock()
+	a.probation = cancel
+	a.mu.Unlock()
+
+	go func() {
+		defer sub.Close()
+		timer := time.NewTimer(window)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+				a.confirmConfig(ctx, applied)
+				return
+			case ev := <-sub.C:
+				if reason := probationFailure(ev); reason != "" {
+					a.rollback(ctx, good, applied, reason)
+					return
+				}
+			}
+		}
+	}()
+}
+
+// stopProbation прерывает испытательный срок без отката
+func (a *App) stopProbation() {
+	a.mu.Lock()
+	cancel := a.probation
+	a.probation = nil
+	a.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// probationFailure возвращает причину отката для события или пустую строку
+func probationFailure(ev events.Event) string {
+	data, _ := ev.Data.(map[string]interface{})
+	switch ev.Type {
+	case events.TypeTimerRun:
+		if data["status"] == "panic" {
+			return fmt.Sprintf("timer %v panicked", data["timer"])
+		}
+	case events.TypeTimerDisabled:
+		return fmt.Sprintf("timer %v disabled", data["timer"])
+	case events.TypeHealthStatus:
+		// Переход в degraded во время испытательного срока тоже считается
+		// сбоем: проверка, прошедшая до перезагрузки, перестала проходить
+		if to := data["to"]; to == health.StatusUnhealthy || to == health.StatusDegraded {
+			return fmt.Sprintf("service health is %v", to)
+		}
+	}
+	return ""
+}
+
+// confirmConfig отмечает конфигурацию, прошедшую испытательный срок
+func (a *App) confirmConfig(ctx context.Context, cfg *config.Config) {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+	a.mu.Lock()
+	a.knownGood = cfg
+	a.probation = nil
+	a.mu.Unlock()
+	a.log.Info("Reloaded config passed probation", map[string]interface{}{"config_hash": cfg.Hash()})
+}
+
+// rollback возвращает конфигурацию good вместо сбойной bad
+func (a *App) rollback(ctx context.Context, good, bad *config.Config, reason string) {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+	// Испытательный срок прерван новой перезагрузкой или остановкой
+	if ctx.Err() != nil {
+		return
+	}
+
+	a.applyLive(bad, good)
+	changes, _ := config.Diff(bad, good)
+	status := config.ReloadStatus{
+		Time:    time.Now().UTC(),
+		Result:  config.ReloadRolledBack,
+		Error:   reason,
+		Changes: changes,
+	}
+	a.mu.Lock()
+	a.active = good
+	a.probation = nil
+	a.mu.Unlock()
+	a.finishReload(status)
+	a.metrics.RecordConfigRollback()
+
+	a.log.Error("Reloaded config failed probation, rolled back", map[string]interface{}{
+		"reason":      reason,
+		"changes":     changes,
+		"config_hash": good.Hash(),
+		"failed_hash": bad.Hash(),
+	})
+	a.events.Publish(events.TypeConfigRollback, map[string]interface{}{
+		"reason":      reason,
+		"config_hash": good.Hash(),
+		"failed_hash": bad.Hash(),
+	})
+}
@@ -0,0 +1,129 @@
+package app
+
+import "time"
+
+// startupSlack запас времени на запуск задачи сверх ожидания ее условий запуска
+const startupSlack = 30 * time.Second
+
+// StartupProgress шаг запуска приложения
+type StartupProgress struct {
+	// Step номер шага (с 1) из Total: задачи lifecycle, затем сервер
+	// метрик, планировщик, admin и gRPC серверы управления
+	Step  int
+	Total int
+	// Task имя запускаемой задачи или сервера
+	Task string
+	// WaitHint сколько может занять шаг, прежде чем запуск считается зависшим
+	WaitHint time.Duration
+}
+
+// StartupReporter передает ход запуска и остановки менеджеру сервисов
+// (контрольные точки SCM, sd_notify)
+type StartupReporter interface {
+	// Starting вызывается перед каждым шагом запуска
+	Starting(p StartupProgress)
+	// Started вызывается, когда все компоненты запущены
+	Started()
+	// Stopping вызывается в начале остановки
+	Stopping()
+}
+
+// startupServers серверы, которые Run запускает после задач lifecycle
+var startupServers = []string{"metrics", "scheduler", "admin", "control"}
+
+// SetStartupReporter задает получателя хода запуска. Вызывается до Run
+func (a *App) SetStartupReporter(r StartupReporter) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.startup = r
+}
+
+// startupReporter возвращает получателя хода запуска (nil, если не задан)
+func (a *App) startupReporter() StartupReporter {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.startup
+}
+
+// reportStarting сообщает о шаге запуска. tasks - количество запускаемых
+// задач lifecycle, серверы нумеруются после них
+func (a *App) reportStarting(step, tasks int, name string) {
+	r := a.startupReporter()
+	if r == nil {
+		return
+	}
+	r.Starting(StartupProgress{
+		Step:     step,
+		Total:    tasks + len(startupServers),
+		Task:     name,
+		WaitHint: time.Duration(a.config.Startup.DependencyTimeoutSeconds)*time.Second + startupSlack,
+	})
+}
+
+// StartupPhase длительность этапа запуска: загрузки конфигурации,
+// инициализации логгера, запуска задачи (task:<имя>) или сервера
+type StartupPhase struct {
+	Name       string `json:"phase"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// RecordStartupPhase записывает длительность этапа запуска name,
+// выполненного до Run (загрузка конфигурации, логгер). Вызывается до Run
+func (a *App) RecordStartupPhase(name string, d time.Duration) {
+	a.mu.Lock()
+	a.bootPhases = append(a.bootPhases, StartupPhase{Name: name, DurationMs: d.Milliseconds()})
+	a.bootTotal += d
+	a.mu.Unlock()
+	a.metrics.SetStartupPhaseDuration(name, d)
+}
+
+// StartupPhases возвращает этапы запуска в порядке выполнения: записанные
+// RecordStartupPhase, затем этапы последнего Run
+func (a *App) StartupPhases() []StartupPhase {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	phases := make([]StartupPhase, 0, len(a.bootPhases)+len(a.runPhases))
+	phases = append(phases, a.bootPhases...)
+	return append(phases, a.runPhases...)
+}
+
+// startupTiming отсчитывает этапы запуска в Run
+type startupTiming struct {
+	app   *App
+	start time.Time
+	total time.Duration
+}
+
+// startTiming начинает отсчет этапов Run
+func (a *App) startTiming() *startupTiming {
+	a.mu.Lock()
+	a.runPhases = nil
+	a.mu.Unlock()
+	return &startupTiming{app: a, start: time.Now()}
+}
+
+// restart начинает отсчет следующего этапа с текущего момента
+func (t *startupTiming) restart() {
+	t.start = time.Now()
+}
+
+// phase записывает этап name, закончившийся сейчас
+func (t *startupTiming) phase(name string) {
+	now := time.Now()
+	d := now.Sub(t.start)
+	t.start = now
+	t.total += d
+	t.app.mu.Lock()
+	t.app.runPhases = append(t.app.runPhases, StartupPhase{Name: name, DurationMs: d.Milliseconds()})
+	t.app.mu.Unlock()
+	t.app.metrics.SetStartupPhaseDuration(name, d)
+}
+
+// finish записывает общую длительность запуска вместе с этапами до Run
+func (t *startupTiming) finish() {
+	t.app.mu.Lock()
+	total := t.app.bootTotal + t.total
+	t.app.startupTotal = total
+	t.app.mu.Unlock()
+	t.app.metrics.SetStartupPhaseDuration("total", total)
+}
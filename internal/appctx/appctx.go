@@ -0,0 +1,110 @@
+// Package appctx передает идентичность сервиса через context.Context
+package appctx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+)
+
+// Identity описывает экземпляр сервиса. InstanceID стабилен между
+// перезапусками, если сохраняется в хранилище; Hostname и IP определяются
+// при запуске (DetectHost) и различают одинаковые сервисы на разных узлах
+type Identity struct {
+	Service    string
+	InstanceID string
+	Version    string
+	Hostname   string
+	IP         string
+}
+
+// identityKey ключ контекста для Identity
+type identityKey struct{}
+
+// WithIdentity возвращает контекст с прикрепленной идентичностью сервиса
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext возвращает идентичность сервиса из контекста
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// ServiceName возвращает имя сервиса из контекста
+func ServiceName(ctx context.Context) string {
+	id, _ := FromContext(ctx)
+	return id.Service
+}
+
+// InstanceID возвращает идентификатор экземпляра из контекста
+func InstanceID(ctx context.Context) string {
+	id, _ := FromContext(ctx)
+	return id.InstanceID
+}
+
+// Version возвращает версию сервиса из контекста
+func Version(ctx context.Context) string {
+	id, _ := FromContext(ctx)
+	return id.Version
+}
+
+// Fields возвращает поля идентичности для структурированного лога.
+// Пустые значения пропускаются
+func Fields(ctx context.Context) map[string]interface{} {
+	id, _ := FromContext(ctx)
+	fields := make(map[string]interface{}, 5)
+	if id.Service != "" {
+		fields["service"] = id.Service
+	}
+	if id.InstanceID != "" {
+		fields["instance_id"] = id.InstanceID
+	}
+	if id.Version != "" {
+		fields["version"] = id.Version
+	}
+	if id.Hostname != "" {
+		fields["hostname"] = id.Hostname
+	}
+	if id.IP != "" {
+		fields["ip"] = id.IP
+	}
+	return fields
+}
+
+// NewInstanceID генерирует случайный идентификатор экземпляра
+func NewInstanceID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// Крайне маловероятно; используем PID как запасной вариант
+		return fmt.Sprintf("pid-%d", os.Getpid())
+	}
+	return hex.EncodeToString(b)
+}
+
+// DetectHost возвращает имя узла и первый адрес не loopback интерфейса
+// (IPv4 предпочтительнее IPv6). Неопределенные значения пустые
+func DetectHost() (hostname, ip string) {
+	hostname, _ = os.Hostname()
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return hostname, ""
+	}
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok || !ipnet.IP.IsGlobalUnicast() {
+			continue
+		}
+		if ipnet.IP.To4() != nil {
+			return hostname, ipnet.IP.String()
+		}
+		if ip == "" {
+			ip = ipnet.IP.String()
+		}
+	}
+	return hostname, ip
+}
@@ -0,0 +1,66 @@
+package appctx
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+)
+
+// TestWithIdentity проверяет сохранение и извлечение идентичности
+func TestWithIdentity(t *testing.T) {
+	ctx := WithIdentity(context.Background(), Identity{
+		Service:    "svc",
+		InstanceID: "abc",
+		Version:    "1.2.3",
+		Hostname:   "node-1",
+	})
+
+	if got := ServiceName(ctx); got != "svc" {
+		t.Errorf("ServiceName() = %q, want svc", got)
+	}
+	if got := InstanceID(ctx); got != "abc" {
+		t.Errorf("InstanceID() = %q, want abc", got)
+	}
+	if got := Version(ctx); got != "1.2.3" {
+		t.Errorf("Version() = %q, want 1.2.3", got)
+	}
+
+	fields := Fields(ctx)
+	if len(fields) != 4 || fields["instance_id"] != "abc" || fields["hostname"] != "node-1" {
+		t.Errorf("Fields() = %v", fields)
+	}
+}
+
+// TestFromContext_Missing проверяет поведение без идентичности
+func TestFromContext_Missing(t *testing.T) {
+	ctx := context.Background()
+	if _, ok := FromContext(ctx); ok {
+		t.Error("FromContext() ok = true for empty context")
+	}
+	if ServiceName(ctx) != "" {
+		t.Error("ServiceName() should be empty")
+	}
+	if len(Fields(ctx)) != 0 {
+		t.Error("Fields() should be empty")
+	}
+}
+
+// TestNewInstanceID проверяет уникальность идентификаторов
+func TestNewInstanceID(t *testing.T) {
+	a, b := NewInstanceID(), NewInstanceID()
+	if a == "" || a == b {
+		t.Errorf("NewInstanceID() = %q, %q; want unique non-empty", a, b)
+	}
+}
+
+// TestDetectHost проверяет определение имени узла
+func TestDetectHost(t *testing.T) {
+	hostname, ip := DetectHost()
+	if want, _ := os.Hostname(); hostname != want {
+		t.Errorf("DetectHost() hostname = %q, want %q", hostname, want)
+	}
+	if ip != "" && net.ParseIP(ip) == nil {
+		t.Errorf("DetectHost() ip = %q, want valid address", ip)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package apperr
+
+import (
+	"errors"
+	"syscall"
+)
+
+// IsAddrInUse проверяет, что адрес уже занят (вероятно, другим экземпляром)
+func IsAddrInUse(err error) bool {
+	return errors.Is(err, syscall.EADDRINUSE)
+}
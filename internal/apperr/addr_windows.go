@@ -0,0 +1,15 @@
+//go:build windows
+// +build windows
+
+package apperr
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// IsAddrInUse проверяет, что адрес уже занят (вероятно, другим экземпляром)
+func IsAddrInUse(err error) bool {
+	return errors.Is(err, windows.WSAEADDRINUSE)
+}
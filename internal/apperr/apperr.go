@@ -0,0 +1,111 @@
+// Package apperr содержит типизированные ошибки запуска сервиса. Загрузка
+// конфигурации и запуск компонентов возвращают их вместо непрозрачных строк,
+// чтобы CLI по errors.As выбирал код выхода и подсказку для оператора
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ConfigError ошибка чтения, разбора или проверки конфигурации
+type ConfigError struct {
+	// Path путь к файлу конфигурации, если известен
+	Path string
+	// Key ключ конфигурации, если ошибка относится к конкретному значению
+	Key string
+	Err error
+}
+
+// Error реализует интерфейс error
+func (e *ConfigError) Error() string {
+	msg := e.Err.Error()
+	if e.Key != "" {
+		msg = e.Key + ": " + msg
+	}
+	if e.Path != "" {
+		msg = fmt.Sprintf("config %s: %s", e.Path, msg)
+	}
+	return msg
+}
+
+// Unwrap возвращает исходную ошибку
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// PortInUseError адрес прослушивания уже занят, вероятно другим экземпляром
+type PortInUseError struct {
+	// Component компонент, который не смог занять адрес
+	Component string
+	Addr      string
+	Err       error
+}
+
+// Error реализует интерфейс error
+func (e *PortInUseError) Error() string {
+	return fmt.Sprintf("%s: address %s is already in use", e.Component, e.Addr)
+}
+
+// Unwrap возвращает исходную ошибку
+func (e *PortInUseError) Unwrap() error {
+	return e.Err
+}
+
+// PermissionError нет прав на файл, каталог или адрес
+type PermissionError struct {
+	Resource string
+	Err      error
+}
+
+// Error реализует интерфейс error
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("permission denied for %s: %v", e.Resource, e.Err)
+}
+
+// Unwrap возвращает исходную ошибку
+func (e *PermissionError) Unwrap() error {
+	return e.Err
+}
+
+// DependencyUnavailableError условие запуска задачи не выполнилось
+type DependencyUnavailableError struct {
+	Task       string
+	Dependency string
+	Err        error
+}
+
+// Error реализует интерфейс error
+func (e *DependencyUnavailableError) Error() string {
+	return fmt.Sprintf("dependency %s: %v", e.Dependency, e.Err)
+}
+
+// Unwrap возвращает исходную ошибку
+func (e *DependencyUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// Listen типизирует ошибку прослушивания addr компонентом component:
+// занятый адрес - PortInUseError, нет прав - PermissionError.
+// Остальные ошибки дополняются именем компонента
+func Listen(component, addr string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case IsAddrInUse(err):
+		return &PortInUseError{Component: component, Addr: addr, Err: err}
+	case errors.Is(err, os.ErrPermission):
+		return &PermissionError{Resource: addr, Err: fmt.Errorf("%s: %w", component, err)}
+	}
+	return fmt.Errorf("%s: %w", component, err)
+}
+
+// Access типизирует ошибку доступа к resource как PermissionError.
+// Остальные ошибки возвращаются без изменений
+func Access(resource string, err error) error {
+	if err != nil && errors.Is(err, os.ErrPermission) {
+		return &PermissionError{Resource: resource, Err: err}
+	}
+	return err
+}
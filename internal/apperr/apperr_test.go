@@ -0,0 +1,68 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+)
+
+// TestListen проверяет типизацию ошибок прослушивания
+func TestListen(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	addr := l.Addr().String()
+	_, err = net.Listen("tcp", addr)
+	err = fmt.Errorf("failed to start admin server: %w", Listen("admin server", addr, err))
+
+	var portErr *PortInUseError
+	if !errors.As(err, &portErr) {
+		t.Fatalf("Listen() error = %v, want PortInUseError", err)
+	}
+	if portErr.Component != "admin server" || portErr.Addr != addr {
+		t.Errorf("PortInUseError = %+v", portErr)
+	}
+	if !IsAddrInUse(err) {
+		t.Error("PortInUseError must unwrap to the listen error")
+	}
+
+	other := errors.New("unknown network")
+	if err := Listen("admin server", addr, other); !errors.Is(err, other) || errors.As(err, &portErr) {
+		t.Errorf("Listen() = %v, want plain wrapped error", err)
+	}
+	if err := Listen("admin server", addr, nil); err != nil {
+		t.Errorf("Listen(nil) = %v, want nil", err)
+	}
+}
+
+// TestAccess проверяет типизацию ошибок доступа
+func TestAccess(t *testing.T) {
+	denied := &os.PathError{Op: "open", Path: "/var/lib/svc/state.db", Err: os.ErrPermission}
+	err := Access("/var/lib/svc/state.db", fmt.Errorf("failed to open store: %w", denied))
+
+	var permErr *PermissionError
+	if !errors.As(err, &permErr) || permErr.Resource != "/var/lib/svc/state.db" {
+		t.Fatalf("Access() error = %v, want PermissionError", err)
+	}
+	if !errors.Is(err, os.ErrPermission) {
+		t.Error("PermissionError must unwrap to os.ErrPermission")
+	}
+
+	missing := fmt.Errorf("failed to open store: %w", os.ErrNotExist)
+	if err := Access("state.db", missing); err != missing {
+		t.Errorf("Access() = %v, want unchanged error", err)
+	}
+}
+
+// TestConfigError проверяет текст ошибки конфигурации
+func TestConfigError(t *testing.T) {
+	err := &ConfigError{Path: "config.yaml", Key: "service.log_level", Err: errors.New("unknown level")}
+	if got, want := err.Error(), "config config.yaml: service.log_level: unknown level"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
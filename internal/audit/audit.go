@@ -0,0 +1,204 @@
+// Package audit журнал действий операторов: кто, что, когда и с каким
+// результатом сделал через admin API, gRPC или CLI. Записи добавляются
+// в файл JSON lines, который не ротируется вместе с логом сервиса и
+// может писаться одновременно сервисом и командами CLI
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Источники действий
+const (
+	SourceAdmin  = "admin"
+	SourceSocket = "socket"
+	SourceGRPC   = "grpc"
+	SourceCLI    = "cli"
+)
+
+// Действия операторов
+const (
+	ActionTriggerTimer     = "trigger_timer"
+	ActionPauseTimer       = "pause_timer"
+	ActionResumeTimer      = "resume_timer"
+	ActionEnableNamespace  = "enable_namespace"
+	ActionDisableNamespace = "disable_namespace"
+	ActionSetLogLevel      = "set_log_level"
+	ActionShutdown         = "shutdown"
+	ActionEnqueueJob       = "enqueue_job"
+	ActionReloadConfig     = "reload_config"
+	ActionImportState      = "import_state"
+	ActionUnauthorized     = "unauthorized_request"
+	ActionInstall          = "install_service"
+	ActionReconfigure      = "reconfigure_service"
+	ActionUninstall        = "uninstall_service"
+	ActionStart            = "start_service"
+	ActionStop             = "stop_service"
+)
+
+// Результаты действий
+const (
+	ResultOK     = "ok"
+	ResultFailed = "failed"
+	ResultDenied = "denied"
+)
+
+// ActorHeader заголовок (и ключ метаданных gRPC) с именем оператора.
+// CLI передает имя пользователя ОС; значение не проверяется и служит
+// для учета, доступ по-прежнему определяется токеном или правами ОС
+const ActorHeader = "X-Audit-Actor"
+
+// maxActorLength максимальная длина имени оператора
+const maxActorLength = 128
+
+// Entry запись журнала действий
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Actor  string    `json:"actor"`
+	Source string    `json:"source"`
+	Action string    `json:"action"`
+	// Target объект действия: имя таймера, тип задания, имя сервиса
+	Target    string                 `json:"target,omitempty"`
+	Result    string                 `json:"result"`
+	Error     string                 `json:"error,omitempty"`
+	Remote    string                 `json:"remote,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// Log журнал действий в файле path
+type Log struct {
+	path string
+	mu   sync.Mutex
+}
+
+// New создает журнал действий. Файл и директория создаются при первой записи
+func New(path string) *Log {
+	return &Log{path: path}
+}
+
+// Path возвращает путь к файлу журнала
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Record добавляет запись в журнал. Пустое время заменяется текущим.
+// Файл открывается на каждую запись в режиме добавления, поэтому
+// записи сервиса и CLI не перемешиваются внутри строки
+func (l *Log) Record(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Time = e.Time.UTC()
+	if e.Result == "" {
+		e.Result = ResultOK
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create audit directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// Recent возвращает последние limit записей в хронологическом порядке
+// (0 - все). Отсутствующий файл означает пустой журнал, поврежденные
+// строки пропускаются
+func (l *Log) Recent(limit int) ([]Entry, error) {
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue
+		}
+		entries = append(entries, e)
+		if limit > 0 && len(entries) > 2*limit {
+			entries = append(entries[:0], entries[len(entries)-limit:]...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, nil
+}
+
+// Result возвращает результат действия по ошибке
+func Result(err error) string {
+	if err != nil {
+		return ResultFailed
+	}
+	return ResultOK
+}
+
+// ErrorText возвращает текст ошибки или пустую строку
+func ErrorText(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// CurrentActor возвращает имя пользователя ОС текущего процесса
+func CurrentActor() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	for _, env := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(env); name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// Actor нормализует имя оператора, переданное клиентом: ограничивает
+// длину и убирает управляющие символы (значение попадает в журнал)
+func Actor(value string) string {
+	runes := make([]rune, 0, len(value))
+	for _, c := range value {
+		if c < 0x20 || c == 0x7f {
+			continue
+		}
+		runes = append(runes, c)
+		if len(runes) == maxActorLength {
+			break
+		}
+	}
+	return string(runes)
+}
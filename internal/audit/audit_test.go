@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRecordRecent проверяет добавление записей, чтение последних записей
+// и пропуск поврежденных строк
+func TestRecordRecent(t *testing.T) {
+	log := New(filepath.Join(t.TempDir(), "logs", "audit.log"))
+
+	if entries, err := log.Recent(0); err != nil || len(entries) != 0 {
+		t.Fatalf("Recent() on missing file = %v, %v, want empty", entries, err)
+	}
+
+	for _, target := range []string{"a", "b", "c"} {
+		if err := log.Record(Entry{Actor: "ops", Source: SourceCLI, Action: ActionStart, Target: target}); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	f, _ := os.OpenFile(log.Path(), os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString("{broken\n")
+	f.Close()
+	log.Record(Entry{Actor: "ops", Source: SourceCLI, Action: ActionStop, Target: "d", Result: Result(errors.New("access denied")), Error: "access denied"})
+
+	entries, err := log.Recent(2)
+	if err != nil {
+		t.Fatalf("Recent() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Target != "c" || entries[1].Target != "d" {
+		t.Fatalf("Recent(2) = %+v, want c and d", entries)
+	}
+	if entries[0].Result != ResultOK || entries[0].Time.IsZero() || entries[1].Result != ResultFailed {
+		t.Errorf("Recent(2) results = %+v", entries)
+	}
+	if all, _ := log.Recent(0); len(all) != 4 {
+		t.Errorf("Recent(0) = %d entries, want 4", len(all))
+	}
+}
+
+// TestActor проверяет нормализацию имени оператора от клиента
+func TestActor(t *testing.T) {
+	if got := Actor("alice\n{\"forged\":1}"); got != "alice{\"forged\":1}" {
+		t.Errorf("Actor() = %q", got)
+	}
+	if got := Actor(strings.Repeat("x", 500)); len(got) != maxActorLength {
+		t.Errorf("Actor() length = %d, want %d", len(got), maxActorLength)
+	}
+}
@@ -0,0 +1,72 @@
+// Package buildinfo предоставляет информацию о сборке, внедряемую через -ldflags
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Значения задаются при сборке:
+//
+//	go build -ldflags "-X service-boilerplate/internal/buildinfo.Version=1.2.3 \
+//	  -X service-boilerplate/internal/buildinfo.Commit=abc123 \
+//	  -X service-boilerplate/internal/buildinfo.BuildDate=2025-01-01T00:00:00Z"
+var (
+	// Version версия сервиса
+	Version = "1.0.0"
+	// Commit хеш коммита
+	Commit = ""
+	// BuildDate дата сборки в формате RFC3339
+	BuildDate = ""
+)
+
+// Info содержит полную информацию о сборке
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Get возвращает информацию о сборке. Если commit или дата не были
+// внедрены через ldflags, используются VCS данные из debug.ReadBuildInfo
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = s.Value
+				}
+			}
+		}
+	}
+
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "unknown"
+	}
+	return info
+}
+
+// String возвращает человекочитаемое представление
+func (i Info) String() string {
+	return fmt.Sprintf("Version:    %s\nCommit:     %s\nBuild date: %s\nGo version: %s\nPlatform:   %s\n",
+		i.Version, i.Commit, i.BuildDate, i.GoVersion, i.Platform)
+}
@@ -0,0 +1,46 @@
+package buildinfo
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestGet проверяет заполнение информации о сборке
+func TestGet(t *testing.T) {
+	oldVersion, oldCommit, oldDate := Version, Commit, BuildDate
+	defer func() { Version, Commit, BuildDate = oldVersion, oldCommit, oldDate }()
+
+	Version, Commit, BuildDate = "9.9.9", "deadbeef", "2025-01-01T00:00:00Z"
+	info := Get()
+
+	if info.Version != "9.9.9" || info.Commit != "deadbeef" || info.BuildDate != "2025-01-01T00:00:00Z" {
+		t.Errorf("Get() = %+v, want injected values", info)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("GoVersion = %q, want %q", info.GoVersion, runtime.Version())
+	}
+	if info.Platform != runtime.GOOS+"/"+runtime.GOARCH {
+		t.Errorf("Platform = %q", info.Platform)
+	}
+}
+
+// TestGet_Defaults проверяет значения по умолчанию без ldflags
+func TestGet_Defaults(t *testing.T) {
+	oldCommit, oldDate := Commit, BuildDate
+	defer func() { Commit, BuildDate = oldCommit, oldDate }()
+
+	Commit, BuildDate = "", ""
+	info := Get()
+	if info.Commit == "" || info.BuildDate == "" {
+		t.Errorf("Get() = %+v, want non-empty commit and build date", info)
+	}
+}
+
+// TestString проверяет человекочитаемый вывод
+func TestString(t *testing.T) {
+	s := Info{Version: "1.0.0", Commit: "abc"}.String()
+	if !strings.Contains(s, "Version:    1.0.0") || !strings.Contains(s, "Commit:     abc") {
+		t.Errorf("String() = %q", s)
+	}
+}
@@ -0,0 +1,356 @@
+// Package cleanup предоставляет реестр временных артефактов (файлов,
+// директорий, сокетов и блокировок), которые создают компоненты сервиса.
+// Артефакты удаляются при graceful остановке, а оставшиеся после аварийного
+// завершения предыдущего запуска - при следующем запуске
+package cleanup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"service-boilerplate/internal/localsock"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/task"
+)
+
+// Kind вид артефакта
+type Kind string
+
+const (
+	// KindFile временный файл
+	KindFile Kind = "file"
+	// KindDir временная директория, удаляется вместе с содержимым
+	KindDir Kind = "dir"
+	// KindSocket Unix socket. Сокет, который еще принимает подключения,
+	// при очистке после аварийного завершения не удаляется
+	KindSocket Kind = "socket"
+	// KindLock файл блокировки, используемый только этим экземпляром
+	KindLock Kind = "lock"
+)
+
+// dialTimeout время проверки сокета, оставшегося от предыдущего запуска
+const dialTimeout = time.Second
+
+// Artifact артефакт, зарегистрированный компонентом
+type Artifact struct {
+	Owner string `json:"owner"`
+	Kind  Kind   `json:"kind"`
+	Path  string `json:"path"`
+}
+
+// manifest файл со списком артефактов запущенного экземпляра
+type manifest struct {
+	PID       int        `json:"pid"`
+	StartedAt time.Time  `json:"started_at"`
+	Artifacts []Artifact `json:"artifacts"`
+}
+
+// Registry реализует task.Task: в AfterStart удаляет артефакты,
+// оставшиеся после аварийного завершения предыдущего запуска, и начинает
+// записывать зарегистрированные артефакты в манифест, в BeforeStop
+// удаляет их. Останавливается последним (task.PhaseCleanup)
+type Registry struct {
+	log          *logger.Logger
+	manifestPath string
+	tempDir      string
+
+	mu        sync.Mutex
+	artifacts map[string]Artifact
+	started   time.Time
+	// persist манифест записывается после AfterStart: до этого в нем
+	// артефакты предыдущего запуска
+	persist bool
+}
+
+// New создает реестр с манифестом manifestPath. tempDir - директория
+// CreateTemp и MkdirTemp
+func New(log *logger.Logger, manifestPath, tempDir string) *Registry {
+	return &Registry{
+		log:          log,
+		manifestPath: manifestPath,
+		tempDir:      tempDir,
+		artifacts:    make(map[string]Artifact),
+	}
+}
+
+// Register регистрирует артефакт path, созданный компонентом owner
+func (r *Registry) Register(owner string, kind Kind, path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.artifacts[path] = Artifact{Owner: owner, Kind: kind, Path: path}
+	return r.save()
+}
+
+// Release снимает регистрацию артефакта, который компонент удалил
+// или оставляет сам
+func (r *Registry) Release(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.artifacts[path]; !ok {
+		return nil
+	}
+	delete(r.artifacts, path)
+	return r.save()
+}
+
+// Remove удаляет артефакт path и снимает его регистрацию
+func (r *Registry) Remove(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	art, ok := r.artifacts[path]
+	if !ok {
+		return fmt.Errorf("artifact is not registered: %s", path)
+	}
+	if err := remove(art); err != nil {
+		return err
+	}
+	delete(r.artifacts, path)
+	return r.save()
+}
+
+// CreateTemp создает временный файл во временной директории сервиса
+// (шаблон имени как у os.CreateTemp) и регистрирует его
+func (r *Registry) CreateTemp(owner, pattern string) (*os.File, error) {
+	if err := os.MkdirAll(r.tempDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	f, err := os.CreateTemp(r.tempDir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Register(owner, KindFile, f.Name()); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return f, nil
+}
+
+// MkdirTemp создает временную директорию во временной директории сервиса
+// и регистрирует ее
+func (r *Registry) MkdirTemp(owner, pattern string) (string, error) {
+	if err := os.MkdirAll(r.tempDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	dir, err := os.MkdirTemp(r.tempDir, pattern)
+	if err != nil {
+		return "", err
+	}
+	if err := r.Register(owner, KindDir, dir); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// Artifacts возвращает зарегистрированные артефакты, отсортированные по пути
+func (r *Registry) Artifacts() []Artifact {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.list()
+}
+
+// list возвращает артефакты по пути. Вызывается под mu
+func (r *Registry) list() []Artifact {
+	arts := make([]Artifact, 0, len(r.artifacts))
+	for _, art := range r.artifacts {
+		arts = append(arts, art)
+	}
+	sort.Slice(arts, func(i, j int) bool { return arts[i].Path < arts[j].Path })
+	return arts
+}
+
+// Name возвращает имя задачи
+func (r *Registry) Name() string {
+	return "cleanup"
+}
+
+// ReadOnly задача не запускается в режиме только для чтения: манифест
+// принадлежит рабочему экземпляру, а его сокеты и файлы заняты
+func (r *Registry) ReadOnly() bool {
+	return false
+}
+
+// ShutdownPhase удаляет артефакты после остановки всех задач
+func (r *Registry) ShutdownPhase() task.Phase {
+	return task.PhaseCleanup
+}
+
+// AfterStart удаляет артефакты предыдущего запуска и записывает манифест
+// текущего
+func (r *Registry) AfterStart(ctx context.Context) error {
+	r.CleanStale(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started = time.Now().UTC()
+	r.persist = true
+	return r.save()
+}
+
+// BeforeStop удаляет зарегистрированные артефакты и манифест
+func (r *Registry) BeforeStop(ctx context.Context) error {
+	r.mu.Lock()
+	arts := r.list()
+	r.persist = false
+	r.mu.Unlock()
+
+	var errs []error
+	for _, art := range arts {
+		if err := remove(art); err != nil {
+			r.log.Warn("Failed to remove artifact", map[string]interface{}{
+				"owner": art.Owner,
+				"path":  art.Path,
+				"error": err.Error(),
+			})
+			errs = append(errs, err)
+			continue
+		}
+		r.mu.Lock()
+		delete(r.artifacts, art.Path)
+		r.mu.Unlock()
+	}
+	if len(arts) > 0 {
+		r.log.Info("Artifacts removed", map[string]interface{}{
+			"removed": len(arts) - len(errs),
+			"failed":  len(errs),
+		})
+	}
+	if err := os.Remove(r.manifestPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// CleanStale удаляет артефакты из манифеста предыдущего запуска
+// и возвращает число удаленных. Если процесс, записавший манифест,
+// еще работает, ничего не удаляется
+func (r *Registry) CleanStale(ctx context.Context) int {
+	data, err := os.ReadFile(r.manifestPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			r.log.Warn("Failed to read cleanup manifest", map[string]interface{}{
+				"path":  r.manifestPath,
+				"error": err.Error(),
+			})
+		}
+		return 0
+	}
+	var prev manifest
+	if err := json.Unmarshal(data, &prev); err != nil {
+		r.log.Warn("Failed to read cleanup manifest", map[string]interface{}{
+			"path":  r.manifestPath,
+			"error": err.Error(),
+		})
+		return 0
+	}
+	if prev.PID != os.Getpid() && processAlive(prev.PID) {
+		r.log.Warn("Previous run is still alive, skipping stale artifact cleanup", map[string]interface{}{
+			"pid":      prev.PID,
+			"manifest": r.manifestPath,
+		})
+		return 0
+	}
+
+	removed := 0
+	for _, art := range prev.Artifacts {
+		if ctx.Err() != nil {
+			break
+		}
+		if art.Kind == KindSocket && socketInUse(ctx, art.Path) {
+			r.log.Warn("Stale socket is in use, leaving it", map[string]interface{}{
+				"owner": art.Owner,
+				"path":  art.Path,
+			})
+			continue
+		}
+		if _, err := os.Lstat(art.Path); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err := remove(art); err != nil {
+			r.log.Warn("Failed to remove stale artifact", map[string]interface{}{
+				"owner": art.Owner,
+				"path":  art.Path,
+				"error": err.Error(),
+			})
+			continue
+		}
+		removed++
+	}
+	r.log.Info("Stale artifacts removed", map[string]interface{}{
+		"pid":        prev.PID,
+		"started_at": prev.StartedAt,
+		"artifacts":  len(prev.Artifacts),
+		"removed":    removed,
+	})
+	return removed
+}
+
+// save записывает манифест. Вызывается под mu. Файл заменяется атомарно,
+// чтобы после аварийного завершения не остался частично записанный
+func (r *Registry) save() error {
+	if !r.persist {
+		return nil
+	}
+	data, err := json.MarshalIndent(manifest{PID: os.Getpid(), StartedAt: r.started, Artifacts: r.list()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.manifestPath), 0755); err != nil {
+		return fmt.Errorf("failed to write cleanup manifest: %w", err)
+	}
+	tmp := r.manifestPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cleanup manifest: %w", err)
+	}
+	if err := os.Rename(tmp, r.manifestPath); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write cleanup manifest: %w", err)
+	}
+	return nil
+}
+
+// remove удаляет артефакт. Отсутствующий артефакт не считается ошибкой
+func remove(art Artifact) error {
+	var err error
+	if art.Kind == KindDir {
+		err = os.RemoveAll(art.Path)
+	} else {
+		err = os.Remove(art.Path)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// socketInUse проверяет, принимает ли сокет подключения
+func socketInUse(ctx context.Context, path string) bool {
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	conn, err := localsock.Dial(ctx, path)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
@@ -0,0 +1,152 @@
+package cleanup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"service-boilerplate/internal/localsock"
+	"service-boilerplate/internal/logger"
+)
+
+// newTestRegistry создает реестр с манифестом во временной директории
+func newTestRegistry(t *testing.T, dir string) *Registry {
+	log, err := logger.New("test-cleanup", filepath.Join(dir, "logs"))
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	t.Cleanup(func() { log.Close() })
+	return New(log, filepath.Join(dir, "state", "cleanup.json"), filepath.Join(dir, "tmp"))
+}
+
+// deadPID возвращает идентификатор завершившегося процесса
+func deadPID(t *testing.T) int {
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to run helper process: %v", err)
+	}
+	return cmd.Process.Pid
+}
+
+// TestRegistry проверяет запись манифеста и удаление артефактов при остановке
+func TestRegistry(t *testing.T) {
+	dir := t.TempDir()
+	r := newTestRegistry(t, dir)
+
+	f, err := r.CreateTemp("export", "export-*.csv")
+	if err != nil {
+		t.Fatalf("CreateTemp() error = %v", err)
+	}
+	f.Close()
+	work, err := r.MkdirTemp("build", "build-*")
+	if err != nil {
+		t.Fatalf("MkdirTemp() error = %v", err)
+	}
+	os.WriteFile(filepath.Join(work, "out"), []byte("x"), 0644)
+	lock := filepath.Join(dir, "job.lock")
+	os.WriteFile(lock, nil, 0644)
+	r.Register("job", KindLock, lock)
+	kept := filepath.Join(dir, "report.csv")
+	os.WriteFile(kept, nil, 0644)
+	r.Register("report", KindFile, kept)
+	r.Release(kept)
+
+	// До AfterStart манифест не пишется: в нем артефакты предыдущего запуска
+	if _, err := os.Stat(r.manifestPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("manifest written before AfterStart: %v", err)
+	}
+	if err := r.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+	var m manifest
+	data, _ := os.ReadFile(r.manifestPath)
+	if err := json.Unmarshal(data, &m); err != nil || m.PID != os.Getpid() || len(m.Artifacts) != 3 {
+		t.Errorf("manifest = %+v, %v", m, err)
+	}
+
+	if err := r.Remove(lock); err != nil {
+		t.Errorf("Remove() error = %v", err)
+	}
+	if _, err := os.Stat(lock); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("lock file after Remove: %v", err)
+	}
+	if err := r.Remove(kept); err == nil {
+		t.Error("Remove(released) error = nil")
+	}
+
+	if err := r.BeforeStop(context.Background()); err != nil {
+		t.Fatalf("BeforeStop() error = %v", err)
+	}
+	for _, path := range []string{f.Name(), work, r.manifestPath} {
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s after BeforeStop: %v", path, err)
+		}
+	}
+	if _, err := os.Stat(kept); err != nil {
+		t.Errorf("released file removed: %v", err)
+	}
+	if arts := r.Artifacts(); len(arts) != 0 {
+		t.Errorf("Artifacts() after BeforeStop = %v", arts)
+	}
+}
+
+// TestCleanStale проверяет удаление артефактов аварийно завершенного
+// запуска: сокет, который принимает подключения, и артефакты работающего
+// процесса не удаляются
+func TestCleanStale(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "stale.tmp")
+	os.WriteFile(stale, nil, 0644)
+	staleDir := filepath.Join(dir, "work")
+	os.MkdirAll(filepath.Join(staleDir, "nested"), 0755)
+	// Короткий путь: длина пути Unix socket ограничена
+	sockDir, err := os.MkdirTemp("", "cln")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(sockDir)
+	sock := filepath.Join(sockDir, "live.sock")
+	listener, err := localsock.Listen(sock)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer listener.Close()
+
+	writeManifest := func(r *Registry, pid int) {
+		data, _ := json.Marshal(manifest{PID: pid, Artifacts: []Artifact{
+			{Owner: "a", Kind: KindFile, Path: stale},
+			{Owner: "b", Kind: KindDir, Path: staleDir},
+			{Owner: "c", Kind: KindSocket, Path: sock},
+			{Owner: "d", Kind: KindFile, Path: filepath.Join(dir, "missing")},
+		}})
+		os.MkdirAll(filepath.Dir(r.manifestPath), 0755)
+		os.WriteFile(r.manifestPath, data, 0644)
+	}
+
+	alive := newTestRegistry(t, dir)
+	writeManifest(alive, os.Getppid())
+	if n := alive.CleanStale(context.Background()); n != 0 {
+		t.Errorf("CleanStale() of a running process = %d, want 0", n)
+	}
+	if _, err := os.Stat(stale); err != nil {
+		t.Errorf("artifact of a running process removed: %v", err)
+	}
+
+	r := newTestRegistry(t, dir)
+	writeManifest(r, deadPID(t))
+	if n := r.CleanStale(context.Background()); n != 2 {
+		t.Errorf("CleanStale() = %d, want 2", n)
+	}
+	for _, path := range []string{stale, staleDir} {
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s after CleanStale: %v", path, err)
+		}
+	}
+	if _, err := os.Lstat(sock); err != nil {
+		t.Errorf("socket in use removed: %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+package cleanup
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// processAlive проверяет, существует ли процесс pid. EPERM означает,
+// что процесс есть, но принадлежит другому пользователю
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := unix.Kill(pid, 0)
+	return err == nil || errors.Is(err, unix.EPERM)
+}
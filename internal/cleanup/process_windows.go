@@ -0,0 +1,29 @@
+//go:build windows
+// +build windows
+
+package cleanup
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// stillActive код завершения процесса, который еще работает
+const stillActive = 259
+
+// processAlive проверяет, существует ли процесс pid
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// Процесс другого пользователя виден, но недоступен
+		return err == windows.ERROR_ACCESS_DENIED
+	}
+	defer windows.CloseHandle(h)
+	var code uint32
+	if err := windows.GetExitCodeProcess(h, &code); err != nil {
+		return true
+	}
+	return code == stillActive
+}
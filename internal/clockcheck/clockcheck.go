@@ -0,0 +1,264 @@
+// Package clockcheck проверяет системные часы: сравнивает ход настенного
+// и монотонного времени и обнаруживает скачки часов вперед и назад
+// (типично для виртуальных машин после возобновления и ручной установки
+// времени)
+package clockcheck
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"service-boilerplate/internal/buildinfo"
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/store"
+)
+
+// Направления скачка часов
+const (
+	Forward  = "forward"
+	Backward = "backward"
+)
+
+const (
+	// stateBucket bucket хранилища с последним известным временем
+	stateBucket = "clockcheck"
+	// lastSeenKey ключ последнего известного времени
+	lastSeenKey = "last_seen"
+)
+
+// Config содержит настройки проверки часов
+type Config struct {
+	// Interval период между проверками
+	Interval time.Duration
+	// Threshold расхождение настенного и монотонного времени за интервал,
+	// которое считается скачком часов
+	Threshold time.Duration
+}
+
+// Recorder записывает метрики часов
+type Recorder interface {
+	RecordClockJump(direction string)
+	SetClockDrift(drift time.Duration)
+}
+
+// reading показания часов: настенное время без монотонной составляющей
+// и монотонное время с запуска процесса
+type reading struct {
+	wall time.Time
+	mono time.Duration
+}
+
+// processStart точка отсчета монотонного времени
+var processStart = time.Now()
+
+// readClock снимает текущие показания часов
+func readClock() reading {
+	return reading{wall: time.Now().Round(0), mono: time.Since(processStart)}
+}
+
+// Monitor реализует task.Task: проверяет часы при запуске и периодически
+// между AfterStart и BeforeStop
+type Monitor struct {
+	cfg     Config
+	log     *logger.Logger
+	metrics Recorder
+
+	mu     sync.Mutex
+	events *events.Bus
+	store  *store.Store
+	onJump []func(offset time.Duration)
+	base   reading
+	prev   reading
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// read позволяет подменить источник показаний в тестах
+	read func() reading
+}
+
+// New создает проверку часов. metrics может быть nil
+func New(log *logger.Logger, metrics Recorder, cfg Config) *Monitor {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = 5 * time.Second
+	}
+	return &Monitor{
+		cfg:     cfg,
+		log:     log,
+		metrics: metrics,
+		read:    readClock,
+	}
+}
+
+// SetEvents включает публикацию скачков часов (events.TypeClockJumped)
+func (m *Monitor) SetEvents(bus *events.Bus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = bus
+}
+
+// SetStore включает сохранение последнего известного времени: при запуске
+// часы, отстающие от него, считаются сбитыми. Вызывается до AfterStart
+func (m *Monitor) SetStore(st *store.Store) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store = st
+}
+
+// OnJump добавляет обработчик скачка часов. offset - на сколько настенное
+// время ушло вперед (отрицательный - назад) относительно монотонного.
+// Вызывается до AfterStart
+func (m *Monitor) OnJump(fn func(offset time.Duration)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onJump = append(m.onJump, fn)
+}
+
+// Name возвращает имя задачи
+func (m *Monitor) Name() string {
+	return "clockcheck"
+}
+
+// ReadOnly задача запускается в режиме только для чтения: проверка только наблюдает за часами
+func (m *Monitor) ReadOnly() bool {
+	return true
+}
+
+// AfterStart проверяет часы и запускает периодическую проверку
+func (m *Monitor) AfterStart(ctx context.Context) error {
+	r := m.read()
+	m.checkStartup(r.wall)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.base, m.prev = r, r
+	runCtx, cancel := context.WithCancel(ctx)
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go m.loop(runCtx, m.done)
+	return nil
+}
+
+// BeforeStop останавливает периодическую проверку
+func (m *Monitor) BeforeStop(ctx context.Context) error {
+	m.mu.Lock()
+	cancel := m.cancel
+	done := m.done
+	m.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// loop выполняет проверки с заданным интервалом
+func (m *Monitor) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check()
+		}
+	}
+}
+
+// checkStartup сравнивает часы с датой сборки и последним известным
+// временем: часы, отстающие от них, скорее всего не синхронизированы
+func (m *Monitor) checkStartup(now time.Time) {
+	source := "build_date"
+	ref, _ := time.Parse(time.RFC3339, buildinfo.Get().BuildDate)
+
+	m.mu.Lock()
+	st := m.store
+	m.mu.Unlock()
+	if st != nil {
+		var lastSeen time.Time
+		err := st.GetJSON(stateBucket, lastSeenKey, &lastSeen)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			m.log.Warn("Failed to load last known clock time", map[string]interface{}{"error": err.Error()})
+		}
+		if lastSeen.After(ref) {
+			ref, source = lastSeen, "last_seen"
+		}
+	}
+
+	if ref.IsZero() || !now.Before(ref.Add(-m.cfg.Threshold)) {
+		return
+	}
+	m.log.Warn("System clock is behind the last known time", map[string]interface{}{
+		"now":       now.Format(time.RFC3339),
+		"reference": ref.Format(time.RFC3339),
+		"source":    source,
+		"behind":    ref.Sub(now).String(),
+	})
+}
+
+// Check выполняет одну проверку и возвращает скачок часов с прошлой
+// проверки (0 - скачка не было)
+func (m *Monitor) Check() time.Duration {
+	r := m.read()
+
+	m.mu.Lock()
+	offset := r.wall.Sub(m.prev.wall) - (r.mono - m.prev.mono)
+	drift := r.wall.Sub(m.base.wall) - (r.mono - m.base.mono)
+	m.prev = r
+	bus := m.events
+	st := m.store
+	handlers := m.onJump
+	m.mu.Unlock()
+
+	if m.metrics != nil {
+		m.metrics.SetClockDrift(drift)
+	}
+	if st != nil {
+		if err := st.PutJSON(stateBucket, lastSeenKey, r.wall); err != nil {
+			m.log.Warn("Failed to save last known clock time", map[string]interface{}{"error": err.Error()})
+		}
+	}
+	if offset < m.cfg.Threshold && offset > -m.cfg.Threshold {
+		return 0
+	}
+
+	direction := Forward
+	if offset < 0 {
+		direction = Backward
+	}
+	m.log.Warn("System clock jumped", map[string]interface{}{
+		"direction": direction,
+		"offset":    offset.String(),
+		"now":       r.wall.Format(time.RFC3339),
+		"drift":     drift.String(),
+	})
+	if m.metrics != nil {
+		m.metrics.RecordClockJump(direction)
+	}
+	if bus != nil {
+		bus.Publish(events.TypeClockJumped, map[string]interface{}{
+			"direction":      direction,
+			"offset_seconds": offset.Seconds(),
+		})
+	}
+	for _, fn := range handlers {
+		fn(offset)
+	}
+	return offset
+}
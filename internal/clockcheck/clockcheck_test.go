@@ -0,0 +1,139 @@
+package clockcheck
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/store"
+)
+
+// fakeClock подменный источник показаний часов
+type fakeClock struct {
+	wall time.Time
+	mono time.Duration
+}
+
+// advance продвигает оба времени на d
+func (c *fakeClock) advance(d time.Duration) {
+	c.wall = c.wall.Add(d)
+	c.mono += d
+}
+
+// recorder запоминает метрики часов
+type recorder struct {
+	jumps []string
+	drift time.Duration
+}
+
+func (r *recorder) RecordClockJump(direction string)  { r.jumps = append(r.jumps, direction) }
+func (r *recorder) SetClockDrift(drift time.Duration) { r.drift = drift }
+
+// setupTestMonitor создает проверку часов с подменным источником показаний
+func setupTestMonitor(t *testing.T, dir string) (*Monitor, *logger.Logger, *fakeClock, *recorder) {
+	log, err := logger.New("test-clockcheck", dir)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	clock := &fakeClock{wall: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	rec := &recorder{}
+	m := New(log, rec, Config{Interval: time.Hour, Threshold: 5 * time.Second})
+	m.read = func() reading { return reading{wall: clock.wall, mono: clock.mono} }
+	return m, log, clock, rec
+}
+
+// TestCheck_Jumps проверяет обнаружение скачков и накопление расхождения
+func TestCheck_Jumps(t *testing.T) {
+	m, log, clock, rec := setupTestMonitor(t, t.TempDir())
+	defer log.Close()
+	bus := events.New()
+	sub := bus.Subscribe(10, events.TypeClockJumped)
+	m.SetEvents(bus)
+	var offsets []time.Duration
+	m.OnJump(func(offset time.Duration) { offsets = append(offsets, offset) })
+
+	if err := m.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+	defer m.BeforeStop(context.Background())
+
+	// Плавная подстройка часов меньше порога скачком не считается
+	clock.advance(30 * time.Second)
+	clock.wall = clock.wall.Add(time.Second)
+	if offset := m.Check(); offset != 0 {
+		t.Errorf("Check() = %v for a slewed clock, want 0", offset)
+	}
+
+	// Возобновление виртуальной машины: настенное время ушло на час вперед
+	clock.advance(30 * time.Second)
+	clock.wall = clock.wall.Add(time.Hour)
+	if offset := m.Check(); offset != time.Hour {
+		t.Errorf("Check() = %v, want 1h forward jump", offset)
+	}
+
+	clock.advance(30 * time.Second)
+	clock.wall = clock.wall.Add(-10 * time.Minute)
+	if offset := m.Check(); offset != -10*time.Minute {
+		t.Errorf("Check() = %v, want 10m backward jump", offset)
+	}
+
+	if got := strings.Join(rec.jumps, ","); got != "forward,backward" {
+		t.Errorf("recorded jumps = %s, want forward,backward", got)
+	}
+	if want := time.Second + time.Hour - 10*time.Minute; rec.drift != want {
+		t.Errorf("drift = %v, want %v", rec.drift, want)
+	}
+	if len(offsets) != 2 || offsets[0] != time.Hour || offsets[1] != -10*time.Minute {
+		t.Errorf("OnJump offsets = %v", offsets)
+	}
+	select {
+	case e := <-sub.C:
+		if data := e.Data.(map[string]interface{}); data["direction"] != Forward {
+			t.Errorf("event data = %v, want forward jump", data)
+		}
+	default:
+		t.Error("clock jump event was not published")
+	}
+}
+
+// TestAfterStart_BehindLastSeen проверяет предупреждение при запуске,
+// если часы отстают от времени, сохраненного прошлым запуском
+func TestAfterStart_BehindLastSeen(t *testing.T) {
+	dir := t.TempDir()
+	m, log, clock, _ := setupTestMonitor(t, dir)
+	defer log.Close()
+	st := store.New(log, filepath.Join(dir, "state.db"))
+	if err := st.AfterStart(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer st.BeforeStop(context.Background())
+	m.SetStore(st)
+
+	if err := m.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+	clock.advance(time.Minute)
+	m.Check()
+	m.BeforeStop(context.Background())
+
+	// Следующий запуск с часами, сброшенными на сутки назад
+	clock.wall = clock.wall.Add(-24 * time.Hour)
+	if err := m.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+	m.BeforeStop(context.Background())
+
+	log.Flush()
+	content, err := os.ReadFile(logger.FilePath(dir, "test-clockcheck"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `"source":"last_seen"`) {
+		t.Error("log does not contain warning about clock behind last seen time")
+	}
+}
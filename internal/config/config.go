@@ -2,61 +2,1455 @@
 package config
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"service-boilerplate/internal/apperr"
+	"service-boilerplate/internal/i18n"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/paths"
+	"service-boilerplate/internal/secrets"
 )
 
 // Config представляет конфигурацию сервиса
 type Config struct {
-	Service   ServiceConfig   `yaml:"service"`
-	Scheduler SchedulerConfig `yaml:"scheduler"`
-	Metrics   MetricsConfig   `yaml:"metrics"`
+	Service    ServiceConfig              `yaml:"service"`
+	Scheduler  SchedulerConfig            `yaml:"scheduler"`
+	Metrics    MetricsConfig              `yaml:"metrics"`
+	Watchdog   WatchdogConfig             `yaml:"watchdog"`
+	Clock      ClockConfig                `yaml:"clock"`
+	Admin      AdminConfig                `yaml:"admin"`
+	GRPC       GRPCConfig                 `yaml:"grpc"`
+	HTTP       HTTPConfig                 `yaml:"http"`
+	Jobs       JobsConfig                 `yaml:"jobs"`
+	Store      StoreConfig                `yaml:"store"`
+	Election   ElectionConfig             `yaml:"election"`
+	Watcher    WatcherConfig              `yaml:"watcher"`
+	Database   DatabaseConfig             `yaml:"database"`
+	Redis      RedisConfig                `yaml:"redis"`
+	HTTPClient HTTPClientConfig           `yaml:"http_client"`
+	Secrets    SecretsConfig              `yaml:"secrets"`
+	RateLimits map[string]RateLimitConfig `yaml:"rate_limits,omitempty"`
+	Health     HealthConfig               `yaml:"health"`
+	Startup    StartupConfig              `yaml:"startup"`
+	Shutdown   ShutdownConfig             `yaml:"shutdown"`
+	Reload     ReloadConfig               `yaml:"reload"`
+	Kubernetes KubernetesConfig           `yaml:"kubernetes"`
+	Processes  []ProcessConfig            `yaml:"processes,omitempty"`
+	Hooks      HooksConfig                `yaml:"hooks"`
+	Alerting   AlertingConfig             `yaml:"alerting"`
+	Profiling  ProfilingConfig            `yaml:"profiling"`
+	Tracing    TracingConfig              `yaml:"tracing"`
+	Heartbeat  HeartbeatConfig            `yaml:"heartbeat"`
+
+	// encrypted пути значений, расшифрованных при загрузке (скрываются в View)
+	encrypted [][]string
 }
 
-// ServiceConfig содержит настройки сервиса
+// ServiceConfig содержит настройки сервиса. Пустые Name/DisplayName/Description
+// означают значения, заданные при компиляции
 type ServiceConfig struct {
-	LogDir string `yaml:"log_dir"`
+	Name        string `yaml:"name"`
+	DisplayName string `yaml:"display_name"`
+	Description string `yaml:"description"`
+	// LogDir, DataDir, StateDir, CacheDir директории сервиса, пустые -
+	// по умолчанию для платформы (см. ResolvePaths)
+	LogDir   string `yaml:"log_dir"`
+	DataDir  string `yaml:"data_dir"`
+	StateDir string `yaml:"state_dir"`
+	CacheDir string `yaml:"cache_dir"`
+	LogLevel string `yaml:"log_level"`
+	// LogEncoding кодирование записей в файле лога: json или имя,
+	// зарегистрированное через logger.RegisterEncoder
+	LogEncoding string `yaml:"log_encoding"`
+	// CrashDir директория отчетов о падении, пустая - <log_dir>/crashes
+	CrashDir string `yaml:"crash_dir"`
+	// AuditFile журнал действий операторов, пустой - <log_dir>/<name>-audit.log
+	AuditFile string `yaml:"audit_file"`
+	// LogBufferSize сколько последних записей лога хранить в памяти
+	LogBufferSize int `yaml:"log_buffer_size"`
+	// Locale язык вывода CLI и Event Log (en, ru), пустой - по окружению
+	Locale string `yaml:"locale"`
+	// StartType тип запуска в менеджере сервисов: auto, delayed, manual, disabled
+	StartType string `yaml:"start_type"`
+	// Recovery действия менеджера сервисов при падении сервиса
+	Recovery RecoveryConfig `yaml:"recovery"`
+	// ReadOnly режим только для чтения: запускаются только таймеры
+	// с read_only и задачи, не изменяющие состояние (флаг --read-only)
+	ReadOnly bool `yaml:"read_only"`
+	// InstanceID идентификатор экземпляра в логах, метриках и статусе.
+	// Пустой - создается при первом запуске и сохраняется в хранилище
+	InstanceID string `yaml:"instance_id"`
+}
+
+// RecoveryConfig настройки перезапуска сервиса менеджером сервисов
+// (recovery actions SCM, Restart= в systemd unit).
+// Restart: always, on-failure или never
+type RecoveryConfig struct {
+	Restart      string `yaml:"restart"`
+	DelaySeconds int    `yaml:"delay_seconds"`
+	// ResetSeconds время без сбоев, после которого SCM сбрасывает счетчик сбоев
+	ResetSeconds int `yaml:"reset_seconds"`
 }
 
 // SchedulerConfig содержит настройки планировщика
 type SchedulerConfig struct {
 	MaxPanicRestarts int `yaml:"max_panic_restarts"`
 	BackoffSeconds   int `yaml:"backoff_seconds"`
+	// MaxConcurrentRuns сколько обработчиков всех таймеров выполняется
+	// одновременно, остальные ждут в очереди; 0 - без ограничения
+	MaxConcurrentRuns int `yaml:"max_concurrent_runs"`
+	// MaxConcurrentHandlers размер пула горутин, на которых выполняются
+	// обработчики; 0 - каждый запуск в своей горутине
+	MaxConcurrentHandlers int `yaml:"max_concurrent_handlers"`
+	// StarvationSeconds через сколько секунд ожидания запуск получает
+	// слот раньше запусков с более высоким приоритетом
+	StarvationSeconds int `yaml:"starvation_seconds"`
+	// HistorySize сколько последних запусков каждого таймера хранится
+	// для GET /timers/{name}/history
+	HistorySize int `yaml:"history_size"`
+	// PersistHistory сохранять историю запусков в хранилище (store.enabled)
+	PersistHistory bool `yaml:"persist_history"`
+	// ReloadPolicy выполняющийся запуск таймера, интервал или таймаут
+	// которого изменен перезагрузкой конфигурации: finish - завершается,
+	// cancel - его контекст отменяется и таймер сразу перепланируется
+	ReloadPolicy string `yaml:"reload_policy"`
+	// OverlapPolicy тик таймера, пришедший во время его запуска: skip -
+	// отбрасывается, queue - ставится в очередь таймера, concurrent -
+	// запускает обработчик параллельно с выполняющимся
+	OverlapPolicy string `yaml:"overlap_policy"`
+	// MisfirePolicy таймер, интервал которого истек, пока сервис не
+	// работал (по времени последнего запуска в store): skip - ждет
+	// интервал после запуска, run - выполняется сразу
+	MisfirePolicy string `yaml:"misfire_policy"`
+	// Timers окружение запуска и приоритет таймеров по имени
+	Timers map[string]TimerConfig `yaml:"timers,omitempty"`
+	// Namespaces лимиты и состояние пространств имен таймеров
+	// (префикс имени до "/", например tenant-a/report)
+	Namespaces map[string]NamespaceConfig `yaml:"namespaces,omitempty"`
+}
+
+// NamespaceConfig настройки пространства имен таймеров
+type NamespaceConfig struct {
+	// MaxConcurrentRuns сколько обработчиков таймеров пространства
+	// выполняется одновременно; 0 - без ограничения
+	MaxConcurrentRuns int `yaml:"max_concurrent_runs"`
+	// Disabled таймеры пространства не запускаются до POST /namespaces/{name}/enable
+	Disabled bool `yaml:"disabled"`
+}
+
+// TimerConfig окружение запуска таймера: рабочая директория и переменные
+// для команд, которые запускает обработчик (execenv.Command), и приоритет
+// в очереди лимита max_concurrent_runs (больше - раньше). ReadOnly
+// отмечает таймер, который не изменяет состояние и поэтому работает
+// в режиме только для чтения (service.read_only). Budget ограничивает
+// ресурсы одного запуска
+type TimerConfig struct {
+	Dir      string            `yaml:"dir"`
+	Env      map[string]string `yaml:"env,omitempty"`
+	Priority int               `yaml:"priority"`
+	ReadOnly bool              `yaml:"read_only"`
+	Budget   TimerBudgetConfig `yaml:"budget"`
+	// IntervalSeconds заменяет интервал из кода, 0 - интервал из кода.
+	// Применяется перезагрузкой конфигурации без перезапуска
+	IntervalSeconds int `yaml:"interval_seconds"`
+	// TimeoutSeconds после которого контекст запуска отменяется,
+	// 0 - без ограничения. Применяется перезагрузкой конфигурации
+	TimeoutSeconds int `yaml:"timeout_seconds"`
+	// ReloadPolicy заменяет scheduler.reload_policy для таймера
+	ReloadPolicy string `yaml:"reload_policy,omitempty"`
+	// OverlapPolicy заменяет scheduler.overlap_policy для таймера
+	OverlapPolicy string `yaml:"overlap_policy,omitempty"`
+	// MisfirePolicy заменяет scheduler.misfire_policy для таймера
+	MisfirePolicy string `yaml:"misfire_policy,omitempty"`
+}
+
+// TimerBudgetConfig бюджет ресурсов запуска таймера. Если Violations
+// запусков подряд превышают бюджет, интервал таймера увеличивается
+// в ThrottleFactor раз до первого запуска в пределах бюджета
+type TimerBudgetConfig struct {
+	// WallSeconds длительность запуска, 0 - без ограничения
+	WallSeconds int `yaml:"wall_seconds"`
+	// CPUSeconds процессорное время запуска (только Linux), 0 - без ограничения
+	CPUSeconds int `yaml:"cpu_seconds"`
+	// Violations запусков подряд сверх бюджета до замедления (по умолчанию 3)
+	Violations int `yaml:"violations"`
+	// ThrottleFactor множитель интервала замедленного таймера (по умолчанию 2)
+	ThrottleFactor int `yaml:"throttle_factor"`
 }
 
 // MetricsConfig содержит настройки метрик
 type MetricsConfig struct {
 	Enabled bool   `yaml:"enabled"`
 	Listen  string `yaml:"listen"`
+	// MaxLabelValues лимит значений метки имени (timer, job_type, route)
+	// для каждой метрики
+	MaxLabelValues int `yaml:"max_label_values"`
+	// LabelOverflow обработка значений сверх лимита: aggregate
+	// (объединяются в значение other) или reject (не записываются)
+	LabelOverflow string `yaml:"label_overflow"`
+	// RemoteWrite отправка метрик в endpoint Prometheus remote write
+	RemoteWrite RemoteWriteConfig `yaml:"remote_write"`
+	// PerfCounters публикация ключевых метрик как счетчиков производительности Windows
+	PerfCounters PerfCountersConfig `yaml:"perf_counters"`
+	// FinalScrapeSeconds сколько /metrics отдает итоговый снимок после
+	// остановки компонентов, 0 - сервер метрик останавливается сразу
+	FinalScrapeSeconds int `yaml:"final_scrape_seconds"`
+}
+
+// PerfCountersConfig содержит настройки счетчиков производительности Windows.
+// Набор счетчиков регистрируется командами install и reconfigure
+type PerfCountersConfig struct {
+	Enabled         bool `yaml:"enabled"`
+	IntervalSeconds int  `yaml:"interval_seconds"`
+}
+
+// RemoteWriteConfig содержит настройки отправки метрик через remote write.
+// Неотправленные батчи хранятся в памяти (BufferSize), без WAL.
+// Password и BearerToken можно задать через !encrypted
+type RemoteWriteConfig struct {
+	Enabled         bool              `yaml:"enabled"`
+	URL             string            `yaml:"url"`
+	IntervalSeconds int               `yaml:"interval_seconds"`
+	TimeoutSeconds  int               `yaml:"timeout_seconds"`
+	MaxRetries      int               `yaml:"max_retries"`
+	BufferSize      int               `yaml:"buffer_size"`
+	Username        string            `yaml:"username"`
+	Password        string            `yaml:"password"`
+	BearerToken     string            `yaml:"bearer_token"`
+	Headers         map[string]string `yaml:"headers,omitempty"`
+}
+
+// WatchdogConfig содержит настройки watchdog горутин и памяти
+type WatchdogConfig struct {
+	Enabled          bool `yaml:"enabled"`
+	IntervalSeconds  int  `yaml:"interval_seconds"`
+	MaxGoroutines    int  `yaml:"max_goroutines"`
+	MaxHeapMB        int  `yaml:"max_heap_mb"`
+	SustainedSamples int  `yaml:"sustained_samples"`
+	Restart          bool `yaml:"restart"`
+}
+
+// ClockConfig содержит настройки проверки системных часов: скачок
+// настенного времени относительно монотонного больше порога пишется
+// в лог и пересчитывает время следующего запуска таймеров
+type ClockConfig struct {
+	CheckIntervalSeconds int `yaml:"check_interval_seconds"`
+	JumpThresholdSeconds int `yaml:"jump_threshold_seconds"`
+}
+
+// AdminConfig содержит настройки admin API. Если Token задан,
+// запросы должны передавать его в заголовке Authorization: Bearer.
+// Socket включает локальный канал управления (Unix socket / named pipe)
+// без TCP порта; Enabled относится только к TCP адресу
+type AdminConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Listen  string `yaml:"listen"`
+	Token   string `yaml:"token"`
+	Socket  bool   `yaml:"socket"`
+	// SocketPath путь Unix socket или имя named pipe, пустой - по имени сервиса
+	SocketPath string `yaml:"socket_path"`
+	// IdempotencyWindowSeconds сколько хранится результат ручного запуска
+	// с заголовком Idempotency-Key для повторных запросов с тем же ключом
+	IdempotencyWindowSeconds int `yaml:"idempotency_window_seconds"`
+}
+
+// GRPCConfig содержит настройки gRPC интерфейса управления. Если Token задан,
+// вызовы должны передавать его в метаданных authorization: Bearer.
+// Socket переводит сервер с TCP адреса на Unix socket / named pipe
+type GRPCConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Listen  string `yaml:"listen"`
+	Token   string `yaml:"token"`
+	Socket  bool   `yaml:"socket"`
+	// SocketPath путь Unix socket или имя named pipe, пустой - по имени сервиса
+	SocketPath string `yaml:"socket_path"`
+}
+
+// HTTPConfig содержит настройки пользовательского HTTP сервера.
+// TLS включается, если заданы оба файла
+type HTTPConfig struct {
+	Enabled             bool      `yaml:"enabled"`
+	Listen              string    `yaml:"listen"`
+	TLS                 TLSConfig `yaml:"tls"`
+	ReadTimeoutSeconds  int       `yaml:"read_timeout_seconds"`
+	WriteTimeoutSeconds int       `yaml:"write_timeout_seconds"`
+	IdleTimeoutSeconds  int       `yaml:"idle_timeout_seconds"`
+}
+
+// TLSConfig содержит пути к сертификату и ключу в формате PEM
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+}
+
+// JobsConfig содержит настройки очереди заданий
+type JobsConfig struct {
+	Workers             int `yaml:"workers"`
+	QueueSize           int `yaml:"queue_size"`
+	MaxAttempts         int `yaml:"max_attempts"`
+	RetryBackoffSeconds int `yaml:"retry_backoff_seconds"`
+	DeadLetterSize      int `yaml:"dead_letter_size"`
+}
+
+// StoreConfig содержит настройки встроенного хранилища состояния.
+// Пустой Path - <state_dir>/state.db
+type StoreConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Path    string `yaml:"path"`
+}
+
+// ElectionConfig содержит настройки выбора лидера для схемы active/passive.
+// LockFile должен лежать на диске, общем для всех экземпляров
+type ElectionConfig struct {
+	Enabled      bool   `yaml:"enabled"`
+	LockFile     string `yaml:"lock_file"`
+	RetrySeconds int    `yaml:"retry_seconds"`
+}
+
+// WatcherConfig содержит список наблюдаемых директорий
+type WatcherConfig struct {
+	Watches []WatchConfig `yaml:"watches,omitempty"`
+}
+
+// WatchConfig описывает наблюдаемую директорию. Обработчик регистрируется
+// в коде по Name
+type WatchConfig struct {
+	Name       string `yaml:"name"`
+	Path       string `yaml:"path"`
+	Pattern    string `yaml:"pattern"`
+	DebounceMs int    `yaml:"debounce_ms"`
+	Existing   bool   `yaml:"existing"`
+}
+
+// DatabaseConfig содержит настройки пула соединений database/sql.
+// DSN берется из переменной DSNEnv, файла DSNFile или поля DSN (в этом порядке),
+// драйвер Driver должен быть импортирован приложением
+type DatabaseConfig struct {
+	Enabled                bool   `yaml:"enabled"`
+	Driver                 string `yaml:"driver"`
+	DSN                    string `yaml:"dsn"`
+	DSNEnv                 string `yaml:"dsn_env"`
+	DSNFile                string `yaml:"dsn_file"`
+	MaxOpenConns           int    `yaml:"max_open_conns"`
+	MaxIdleConns           int    `yaml:"max_idle_conns"`
+	ConnMaxLifetimeSeconds int    `yaml:"conn_max_lifetime_seconds"`
+	ConnMaxIdleTimeSeconds int    `yaml:"conn_max_idle_time_seconds"`
+	PingTimeoutSeconds     int    `yaml:"ping_timeout_seconds"`
+}
+
+// RedisConfig содержит настройки клиента Redis. Пароль берется из переменной
+// PasswordEnv, файла PasswordFile или поля Password (в этом порядке)
+type RedisConfig struct {
+	Enabled             bool           `yaml:"enabled"`
+	Addr                string         `yaml:"addr"`
+	Username            string         `yaml:"username"`
+	Password            string         `yaml:"password"`
+	PasswordEnv         string         `yaml:"password_env"`
+	PasswordFile        string         `yaml:"password_file"`
+	DB                  int            `yaml:"db"`
+	PoolSize            int            `yaml:"pool_size"`
+	TLS                 RedisTLSConfig `yaml:"tls"`
+	DialTimeoutSeconds  int            `yaml:"dial_timeout_seconds"`
+	ReadTimeoutSeconds  int            `yaml:"read_timeout_seconds"`
+	WriteTimeoutSeconds int            `yaml:"write_timeout_seconds"`
+}
+
+// RedisTLSConfig содержит настройки TLS соединения с Redis. Пустой CAFile
+// означает системные корневые сертификаты
+type RedisTLSConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	CAFile  string `yaml:"ca_file"`
+}
+
+// HTTPClientConfig содержит настройки по умолчанию для исходящих HTTP клиентов
+type HTTPClientConfig struct {
+	TimeoutSeconds int           `yaml:"timeout_seconds"`
+	MaxRetries     int           `yaml:"max_retries"`
+	RetryBackoffMs int           `yaml:"retry_backoff_ms"`
+	Breaker        BreakerConfig `yaml:"breaker"`
+}
+
+// SecretsConfig содержит настройки отслеживания смены секретов: файлы
+// database.dsn_file и redis.password_file перечитываются каждые
+// RotationCheckSeconds, и при изменении модули переподключаются
+type SecretsConfig struct {
+	RotationCheckSeconds int `yaml:"rotation_check_seconds"`
+}
+
+// BreakerConfig содержит настройки circuit breaker
+type BreakerConfig struct {
+	Enabled            bool `yaml:"enabled"`
+	FailureThreshold   int  `yaml:"failure_threshold"`
+	OpenTimeoutSeconds int  `yaml:"open_timeout_seconds"`
+	HalfOpenProbes     int  `yaml:"half_open_probes"`
+}
+
+// RateLimitConfig содержит настройки именованного ограничителя частоты запросов
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	Burst             int     `yaml:"burst"`
+}
+
+// HealthConfig содержит настройки проверок здоровья. FailureThreshold
+// и SuccessThreshold - сколько неудачных или успешных выполнений подряд
+// меняют состояние проверки (между ними проверка degraded)
+type HealthConfig struct {
+	IntervalSeconds  int `yaml:"interval_seconds"`
+	TimeoutSeconds   int `yaml:"timeout_seconds"`
+	FailureThreshold int `yaml:"failure_threshold"`
+	SuccessThreshold int `yaml:"success_threshold"`
+	// Dependencies внешние зависимости, которые проверяются вместе
+	// с компонентами сервиса
+	Dependencies []DependencyConfig `yaml:"dependencies,omitempty"`
+}
+
+// DependencyConfig внешняя зависимость. Type - tcp (Endpoint host:port),
+// http (URL, ответ 2xx или 3xx), dns (имя) или file (путь). Отказ
+// критичной зависимости делает сервис unhealthy и снимает готовность
+// /ready, некритичной - только degraded
+type DependencyConfig struct {
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"`
+	Endpoint string `yaml:"endpoint"`
+	Critical bool   `yaml:"critical"`
+}
+
+// StartupConfig содержит настройки ожидания условий запуска задач
+// (доступность TCP адресов, файлы, DNS) перед AfterStart
+type StartupConfig struct {
+	DependencyTimeoutSeconds    int `yaml:"dependency_timeout_seconds"`
+	DependencyMaxBackoffSeconds int `yaml:"dependency_max_backoff_seconds"`
+}
+
+// ShutdownConfig содержит настройки остановки задач. BeforeStop задачи,
+// не завершившийся за TaskTimeoutSeconds, бросается со стеком в логе
+type ShutdownConfig struct {
+	TaskTimeoutSeconds int `yaml:"task_timeout_seconds"`
+}
+
+// ReloadConfig содержит настройки отката перезагруженной конфигурации.
+// Если Rollback включен, в течение ProbationSeconds после перезагрузки
+// panic или отключение таймера и переход сервиса в degraded или unhealthy
+// возвращают последнюю конфигурацию, прошедшую испытательный срок
+type ReloadConfig struct {
+	Rollback         bool `yaml:"rollback"`
+	ProbationSeconds int  `yaml:"probation_seconds"`
+}
+
+// KubernetesConfig содержит настройки работы в поде Kubernetes (флаг --k8s).
+// Имя пода, пространство имен и узел добавляются к логам и метрикам.
+// После SIGTERM готовность (/ready) снимается сразу, сервис работает еще
+// DrainSeconds, пока под исключается из endpoints, и останавливается
+// за оставшееся время TerminationGracePeriodSeconds
+type KubernetesConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// PodInfoDir том Downward API с файлами name, namespace, node_name
+	// (переменные POD_NAME, POD_NAMESPACE, NODE_NAME приоритетнее)
+	PodInfoDir string `yaml:"pod_info_dir"`
+	// FileLogging писать файл лога; по умолчанию записи идут только в stdout
+	FileLogging                   bool `yaml:"file_logging"`
+	DrainSeconds                  int  `yaml:"drain_seconds"`
+	TerminationGracePeriodSeconds int  `yaml:"termination_grace_period_seconds"`
 }
 
-// Load загружает конфигурацию из YAML файла
+// ProcessConfig описывает дочерний процесс под управлением сервиса.
+// Restart: always, on-failure или never
+type ProcessConfig struct {
+	Name                   string            `yaml:"name"`
+	Command                string            `yaml:"command"`
+	Args                   []string          `yaml:"args,omitempty"`
+	Env                    map[string]string `yaml:"env,omitempty"`
+	Dir                    string            `yaml:"dir"`
+	Restart                string            `yaml:"restart"`
+	RestartDelaySeconds    int               `yaml:"restart_delay_seconds"`
+	MaxRestartDelaySeconds int               `yaml:"max_restart_delay_seconds"`
+	StopTimeoutSeconds     int               `yaml:"stop_timeout_seconds"`
+	// CPUPercent и MemoryMB ограничения процесса с потомками (Windows Job
+	// Object), 0 - без ограничения
+	CPUPercent int `yaml:"cpu_percent"`
+	MemoryMB   int `yaml:"memory_mb"`
+}
+
+// HooksConfig внешние команды, которые выполняются после запуска сервиса,
+// перед его остановкой и при panic таймера. Вывод команд пишется в лог
+type HooksConfig struct {
+	PostStart    []HookConfig `yaml:"post_start,omitempty"`
+	PreStop      []HookConfig `yaml:"pre_stop,omitempty"`
+	TimerFailure []HookConfig `yaml:"timer_failure,omitempty"`
+}
+
+// HookConfig внешняя команда точки вызова
+type HookConfig struct {
+	Command string            `yaml:"command"`
+	Args    []string          `yaml:"args,omitempty"`
+	Env     map[string]string `yaml:"env,omitempty"`
+	Dir     string            `yaml:"dir"`
+	// TimeoutSeconds после которого команда завершается, 0 - 30 секунд
+	TimeoutSeconds int `yaml:"timeout_seconds"`
+}
+
+// AlertingConfig содержит настройки оповещений о повторяющихся сбоях
+type AlertingConfig struct {
+	Enabled              bool               `yaml:"enabled"`
+	CooldownSeconds      int                `yaml:"cooldown_seconds"`
+	HealthFailingSeconds int                `yaml:"health_failing_seconds"`
+	TimeoutSeconds       int                `yaml:"timeout_seconds"`
+	Webhook              AlertWebhookConfig `yaml:"webhook"`
+	Slack                AlertSlackConfig   `yaml:"slack"`
+	Email                AlertEmailConfig   `yaml:"email"`
+}
+
+// AlertWebhookConfig содержит адрес webhook для оповещений (пустой - отключен)
+type AlertWebhookConfig struct {
+	URL string `yaml:"url"`
+}
+
+// AlertSlackConfig содержит адрес входящего webhook Slack (пустой - отключен)
+type AlertSlackConfig struct {
+	WebhookURL string `yaml:"webhook_url"`
+}
+
+// AlertEmailConfig содержит настройки отправки оповещений по email.
+// Пустой SMTPAddr отключает канал, пароль можно передать через PasswordEnv
+type AlertEmailConfig struct {
+	SMTPAddr    string   `yaml:"smtp_addr"`
+	Username    string   `yaml:"username"`
+	Password    string   `yaml:"password"`
+	PasswordEnv string   `yaml:"password_env"`
+	From        string   `yaml:"from"`
+	To          []string `yaml:"to,omitempty"`
+}
+
+// ProfilingConfig содержит настройки периодического снятия pprof профилей.
+// Пустой Dir означает <log_dir>/profiles
+type ProfilingConfig struct {
+	Enabled         bool            `yaml:"enabled"`
+	Dir             string          `yaml:"dir"`
+	IntervalSeconds int             `yaml:"interval_seconds"`
+	CPUSeconds      int             `yaml:"cpu_seconds"`
+	Profiles        []string        `yaml:"profiles,omitempty"`
+	Retention       int             `yaml:"retention"`
+	Pyroscope       PyroscopeConfig `yaml:"pyroscope"`
+}
+
+// PyroscopeConfig содержит адрес сервера Pyroscope для отправки профилей.
+// Пустой AppName означает имя сервиса
+type PyroscopeConfig struct {
+	URL     string `yaml:"url"`
+	AppName string `yaml:"app_name"`
+}
+
+// TracingConfig содержит настройки OpenTelemetry трассировки запусков таймеров.
+// Endpoint - URL приема спанов OTLP/HTTP, SampleRatio - доля трассируемых запусков
+type TracingConfig struct {
+	Enabled        bool    `yaml:"enabled"`
+	Endpoint       string  `yaml:"endpoint"`
+	SampleRatio    float64 `yaml:"sample_ratio"`
+	TimeoutSeconds int     `yaml:"timeout_seconds"`
+}
+
+// HeartbeatConfig содержит настройки отправки heartbeat в центральный
+// endpoint. BearerToken можно задать через !encrypted
+type HeartbeatConfig struct {
+	Enabled         bool              `yaml:"enabled"`
+	URL             string            `yaml:"url"`
+	IntervalSeconds int               `yaml:"interval_seconds"`
+	TimeoutSeconds  int               `yaml:"timeout_seconds"`
+	MaxRetries      int               `yaml:"max_retries"`
+	BearerToken     string            `yaml:"bearer_token"`
+	Headers         map[string]string `yaml:"headers,omitempty"`
+}
+
+// Load загружает конфигурацию из YAML файла. Значения с тегом !encrypted
+// расшифровываются ключом из secrets.KeyEnv или файла secret.key рядом с конфигом
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		if errors.Is(err, os.ErrPermission) {
+			return nil, &apperr.PermissionError{Resource: path, Err: err}
+		}
+		return nil, &apperr.ConfigError{Path: path, Err: fmt.Errorf("failed to read config file: %w", err)}
 	}
 
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, &apperr.ConfigError{Path: path, Err: fmt.Errorf("failed to parse config file: %w", err)}
+	}
 	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	if root.Kind != 0 {
+		d := &decryptor{keyFile: filepath.Join(filepath.Dir(path), secrets.KeyFileName)}
+		if err := d.walk(&root, nil); err != nil {
+			return nil, &apperr.ConfigError{Path: path, Err: fmt.Errorf("failed to decrypt config value: %w", err)}
+		}
+		if err := root.Decode(&cfg); err != nil {
+			return nil, &apperr.ConfigError{Path: path, Err: fmt.Errorf("failed to parse config file: %w", err)}
+		}
+		cfg.encrypted = d.paths
 	}
 
-	// Устанавливаем значения по умолчанию
-	if cfg.Service.LogDir == "" {
-		cfg.Service.LogDir = "./logs"
+	cfg.setDefaults()
+	return &cfg, nil
+}
+
+// decryptor заменяет значения с тегом !encrypted на расшифрованные.
+// Ключ загружается только при первом зашифрованном значении
+type decryptor struct {
+	keyFile string
+	key     []byte
+	paths   [][]string
+}
+
+// walk обходит узлы YAML, накапливая путь из ключей и индексов
+func (d *decryptor) walk(node *yaml.Node, path []string) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if err := d.walk(child, path); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if err := d.walk(node.Content[i+1], append(path[:len(path):len(path)], node.Content[i].Value)); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			if err := d.walk(child, append(path[:len(path):len(path)], strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if node.Tag != secrets.Tag {
+			return nil
+		}
+		if d.key == nil {
+			key, err := secrets.LoadKey(d.keyFile)
+			if err != nil {
+				return err
+			}
+			d.key = key
+		}
+		value, err := secrets.Decrypt(d.key, node.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", strings.Join(path, "."), err)
+		}
+		// Тег определяется по значению, как у обычного скаляра: так
+		// шифровать можно и числовые поля
+		node.Value = string(value)
+		node.Tag = ""
+		node.Style = 0
+		d.paths = append(d.paths, path)
 	}
-	if cfg.Scheduler.MaxPanicRestarts <= 0 {
-		cfg.Scheduler.MaxPanicRestarts = 5
+	return nil
+}
+
+// Default возвращает конфигурацию по умолчанию (метрики и admin API включены)
+func Default() *Config {
+	cfg := &Config{
+		Metrics: MetricsConfig{Enabled: true},
+		Admin:   AdminConfig{Enabled: true, Socket: true},
 	}
-	if cfg.Scheduler.BackoffSeconds <= 0 {
-		cfg.Scheduler.BackoffSeconds = 5
+	cfg.setDefaults()
+	return cfg
+}
+
+// GeneratedHeader первая строка конфига, записанного Save. По ней
+// uninstall --purge отличает сгенерированный конфиг от созданного вручную
+const GeneratedHeader = "# Generated by service-boilerplate. Remove this line to keep the file on uninstall --purge."
+
+// Save записывает конфигурацию в YAML файл, создавая родительскую директорию
+func Save(path string, cfg *Config) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
 	}
-	if cfg.Metrics.Listen == "" {
-		cfg.Metrics.Listen = ":9090"
+	data = append([]byte(GeneratedHeader+"\n"), data...)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
 	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
 
-	return &cfg, nil
+// IsGenerated проверяет, что файл конфига был записан Save и не помечен как измененный вручную
+func IsGenerated(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	return bytes.HasPrefix(data, []byte(GeneratedHeader+"\n")), nil
+}
+
+// Hash возвращает короткий хэш итоговой конфигурации (с учетом значений
+// по умолчанию), чтобы по логам можно было понять, менялась ли она
+func (c *Config) Hash() string {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
+
+// Redacted заменяет секреты в View
+const Redacted = "***"
+
+// secretKeys ключи конфигурации, значения которых скрываются в View.
+// Вложенные секции разделяются точкой
+var secretKeys = []struct{ section, key string }{
+	{"admin", "token"},
+	{"grpc", "token"},
+	{"database", "dsn"},
+	{"redis", "password"},
+	{"alerting.webhook", "url"},
+	{"alerting.slack", "webhook_url"},
+	{"alerting.email", "password"},
+	{"metrics.remote_write", "password"},
+	{"metrics.remote_write", "bearer_token"},
+	{"heartbeat", "bearer_token"},
+}
+
+// secretMaps пути к map, все значения которых скрываются в View:
+// заголовки запросов и окружение команд могут содержать токены и ключи
+// API. "*" в пути означает любой ключ map или элемент списка
+var secretMaps = []string{
+	"metrics.remote_write.headers",
+	"heartbeat.headers",
+	"processes.*.env",
+	"scheduler.timers.*.env",
+	"hooks.*.*.env",
+}
+
+// View возвращает конфигурацию в виде map с ключами как в YAML
+// и секретами, замененными на Redacted (для admin API и отчетов о падении)
+func (c *Config) View() (map[string]interface{}, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var view map[string]interface{}
+	if err := yaml.Unmarshal(data, &view); err != nil {
+		return nil, err
+	}
+
+	for _, secret := range secretKeys {
+		sectionView := view
+		for _, section := range strings.Split(secret.section, ".") {
+			sectionView, _ = sectionView[section].(map[string]interface{})
+		}
+		if value, _ := sectionView[secret.key].(string); value != "" {
+			sectionView[secret.key] = Redacted
+		}
+	}
+	for _, path := range secretMaps {
+		redactValues(view, strings.Split(path, "."))
+	}
+	// Значения, хранившиеся в файле зашифрованными, тоже секреты
+	for _, path := range c.encrypted {
+		redactPath(view, path)
+	}
+	return view, nil
+}
+
+// redactPath заменяет на Redacted значение по пути из ключей и индексов
+func redactPath(node interface{}, path []string) {
+	for i, key := range path {
+		last := i == len(path)-1
+		switch v := node.(type) {
+		case map[string]interface{}:
+			if _, ok := v[key]; !ok {
+				return
+			}
+			if last {
+				v[key] = Redacted
+				return
+			}
+			node = v[key]
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(v) {
+				return
+			}
+			if last {
+				v[index] = Redacted
+				return
+			}
+			node = v[index]
+		default:
+			return
+		}
+	}
+}
+
+// redactValues заменяет на Redacted все значения map по пути из ключей
+func redactValues(node interface{}, path []string) {
+	if len(path) == 0 {
+		values, _ := node.(map[string]interface{})
+		for key := range values {
+			values[key] = Redacted
+		}
+		return
+	}
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if path[0] != "*" {
+			redactValues(v[path[0]], path[1:])
+			return
+		}
+		for _, child := range v {
+			redactValues(child, path[1:])
+		}
+	case []interface{}:
+		if path[0] != "*" {
+			return
+		}
+		for _, child := range v {
+			redactValues(child, path[1:])
+		}
+	}
+}
+
+// ResolvePaths заполняет незаданные директории сервиса и путь хранилища
+// значениями по умолчанию для платформы (paths.Default) по имени сервиса.
+// Вызывается после того, как определено имя экземпляра
+func (c *Config) ResolvePaths() {
+	dirs := paths.Resolve(c.Service.Name, paths.Dirs{
+		Data:  c.Service.DataDir,
+		State: c.Service.StateDir,
+		Cache: c.Service.CacheDir,
+		Log:   c.Service.LogDir,
+	})
+	c.Service.DataDir = dirs.Data
+	c.Service.StateDir = dirs.State
+	c.Service.CacheDir = dirs.Cache
+	c.Service.LogDir = dirs.Log
+	if c.Store.Path == "" {
+		c.Store.Path = filepath.Join(dirs.State, "state.db")
+	}
+}
+
+// setDefaults устанавливает значения по умолчанию для незаданных полей
+func (c *Config) setDefaults() {
+	if c.Service.LogBufferSize <= 0 {
+		c.Service.LogBufferSize = 500
+	}
+	if c.Service.LogLevel == "" {
+		c.Service.LogLevel = "info"
+	}
+	if c.Service.LogEncoding == "" {
+		c.Service.LogEncoding = logger.JSONEncoding
+	}
+	if c.Service.StartType == "" {
+		c.Service.StartType = "auto"
+	}
+	if c.Service.Recovery.Restart == "" {
+		c.Service.Recovery.Restart = "always"
+	}
+	if c.Service.Recovery.DelaySeconds <= 0 {
+		c.Service.Recovery.DelaySeconds = 5
+	}
+	if c.Service.Recovery.ResetSeconds <= 0 {
+		c.Service.Recovery.ResetSeconds = 86400
+	}
+	if c.Scheduler.MaxPanicRestarts <= 0 {
+		c.Scheduler.MaxPanicRestarts = 5
+	}
+	if c.Scheduler.BackoffSeconds <= 0 {
+		c.Scheduler.BackoffSeconds = 5
+	}
+	if c.Scheduler.StarvationSeconds <= 0 {
+		c.Scheduler.StarvationSeconds = 60
+	}
+	if c.Scheduler.HistorySize <= 0 {
+		c.Scheduler.HistorySize = 20
+	}
+	if c.Scheduler.ReloadPolicy == "" {
+		c.Scheduler.ReloadPolicy = "finish"
+	}
+	if c.Scheduler.OverlapPolicy == "" {
+		c.Scheduler.OverlapPolicy = "skip"
+	}
+	if c.Scheduler.MisfirePolicy == "" {
+		c.Scheduler.MisfirePolicy = "skip"
+	}
+	if c.Metrics.Listen == "" {
+		c.Metrics.Listen = ":9090"
+	}
+	if c.Metrics.MaxLabelValues <= 0 {
+		c.Metrics.MaxLabelValues = 1000
+	}
+	if c.Metrics.LabelOverflow == "" {
+		c.Metrics.LabelOverflow = "aggregate"
+	}
+	if c.Metrics.RemoteWrite.IntervalSeconds <= 0 {
+		c.Metrics.RemoteWrite.IntervalSeconds = 30
+	}
+	if c.Metrics.RemoteWrite.TimeoutSeconds <= 0 {
+		c.Metrics.RemoteWrite.TimeoutSeconds = 10
+	}
+	if c.Metrics.RemoteWrite.BufferSize <= 0 {
+		c.Metrics.RemoteWrite.BufferSize = 10
+	}
+	if c.Metrics.PerfCounters.IntervalSeconds <= 0 {
+		c.Metrics.PerfCounters.IntervalSeconds = 1
+	}
+	if c.Admin.Listen == "" {
+		c.Admin.Listen = "127.0.0.1:9091"
+	}
+	if c.Admin.IdempotencyWindowSeconds <= 0 {
+		c.Admin.IdempotencyWindowSeconds = 600
+	}
+	if c.GRPC.Listen == "" {
+		c.GRPC.Listen = "127.0.0.1:9092"
+	}
+	if c.HTTP.Listen == "" {
+		c.HTTP.Listen = ":8080"
+	}
+	if c.HTTP.ReadTimeoutSeconds <= 0 {
+		c.HTTP.ReadTimeoutSeconds = 30
+	}
+	if c.HTTP.WriteTimeoutSeconds <= 0 {
+		c.HTTP.WriteTimeoutSeconds = 30
+	}
+	if c.HTTP.IdleTimeoutSeconds <= 0 {
+		c.HTTP.IdleTimeoutSeconds = 120
+	}
+	if c.Watchdog.IntervalSeconds <= 0 {
+		c.Watchdog.IntervalSeconds = 30
+	}
+	if c.Watchdog.SustainedSamples <= 0 {
+		c.Watchdog.SustainedSamples = 5
+	}
+	if c.Clock.CheckIntervalSeconds <= 0 {
+		c.Clock.CheckIntervalSeconds = 30
+	}
+	if c.Clock.JumpThresholdSeconds <= 0 {
+		c.Clock.JumpThresholdSeconds = 5
+	}
+	if c.Election.RetrySeconds <= 0 {
+		c.Election.RetrySeconds = 5
+	}
+	for i := range c.Watcher.Watches {
+		if c.Watcher.Watches[i].DebounceMs <= 0 {
+			c.Watcher.Watches[i].DebounceMs = 500
+		}
+	}
+	if c.Database.MaxOpenConns <= 0 {
+		c.Database.MaxOpenConns = 10
+	}
+	if c.Database.MaxIdleConns <= 0 {
+		c.Database.MaxIdleConns = 5
+	}
+	if c.Database.ConnMaxLifetimeSeconds <= 0 {
+		c.Database.ConnMaxLifetimeSeconds = 1800
+	}
+	if c.Database.ConnMaxIdleTimeSeconds <= 0 {
+		c.Database.ConnMaxIdleTimeSeconds = 300
+	}
+	if c.Database.PingTimeoutSeconds <= 0 {
+		c.Database.PingTimeoutSeconds = 5
+	}
+	if c.Redis.Addr == "" {
+		c.Redis.Addr = "127.0.0.1:6379"
+	}
+	if c.Redis.PoolSize <= 0 {
+		c.Redis.PoolSize = 10
+	}
+	if c.Redis.DialTimeoutSeconds <= 0 {
+		c.Redis.DialTimeoutSeconds = 5
+	}
+	if c.Redis.ReadTimeoutSeconds <= 0 {
+		c.Redis.ReadTimeoutSeconds = 3
+	}
+	if c.Redis.WriteTimeoutSeconds <= 0 {
+		c.Redis.WriteTimeoutSeconds = 3
+	}
+	if c.Secrets.RotationCheckSeconds <= 0 {
+		c.Secrets.RotationCheckSeconds = 30
+	}
+	if c.HTTPClient.TimeoutSeconds <= 0 {
+		c.HTTPClient.TimeoutSeconds = 30
+	}
+	if c.HTTPClient.RetryBackoffMs <= 0 {
+		c.HTTPClient.RetryBackoffMs = 200
+	}
+	if c.HTTPClient.Breaker.FailureThreshold <= 0 {
+		c.HTTPClient.Breaker.FailureThreshold = 5
+	}
+	if c.HTTPClient.Breaker.OpenTimeoutSeconds <= 0 {
+		c.HTTPClient.Breaker.OpenTimeoutSeconds = 30
+	}
+	if c.HTTPClient.Breaker.HalfOpenProbes <= 0 {
+		c.HTTPClient.Breaker.HalfOpenProbes = 1
+	}
+	for name, rl := range c.RateLimits {
+		if rl.Burst <= 0 {
+			rl.Burst = 1
+			c.RateLimits[name] = rl
+		}
+	}
+	if c.Health.IntervalSeconds <= 0 {
+		c.Health.IntervalSeconds = 30
+	}
+	if c.Health.TimeoutSeconds <= 0 {
+		c.Health.TimeoutSeconds = 5
+	}
+	if c.Health.FailureThreshold <= 0 {
+		c.Health.FailureThreshold = 1
+	}
+	if c.Health.SuccessThreshold <= 0 {
+		c.Health.SuccessThreshold = 1
+	}
+	if c.Startup.DependencyTimeoutSeconds <= 0 {
+		c.Startup.DependencyTimeoutSeconds = 60
+	}
+	if c.Startup.DependencyMaxBackoffSeconds <= 0 {
+		c.Startup.DependencyMaxBackoffSeconds = 10
+	}
+	if c.Shutdown.TaskTimeoutSeconds <= 0 {
+		c.Shutdown.TaskTimeoutSeconds = 10
+	}
+	if c.Reload.ProbationSeconds <= 0 {
+		c.Reload.ProbationSeconds = 60
+	}
+	if c.Kubernetes.PodInfoDir == "" {
+		c.Kubernetes.PodInfoDir = "/etc/podinfo"
+	}
+	if c.Kubernetes.DrainSeconds <= 0 {
+		c.Kubernetes.DrainSeconds = 5
+	}
+	if c.Kubernetes.TerminationGracePeriodSeconds <= 0 {
+		c.Kubernetes.TerminationGracePeriodSeconds = 30
+	}
+	for i := range c.Processes {
+		p := &c.Processes[i]
+		if p.Restart == "" {
+			p.Restart = "always"
+		}
+		if p.RestartDelaySeconds <= 0 {
+			p.RestartDelaySeconds = 1
+		}
+		if p.MaxRestartDelaySeconds <= 0 {
+			p.MaxRestartDelaySeconds = 60
+		}
+		if p.StopTimeoutSeconds <= 0 {
+			p.StopTimeoutSeconds = 10
+		}
+	}
+	if c.Alerting.CooldownSeconds <= 0 {
+		c.Alerting.CooldownSeconds = 900
+	}
+	if c.Alerting.HealthFailingSeconds <= 0 {
+		c.Alerting.HealthFailingSeconds = 300
+	}
+	if c.Alerting.TimeoutSeconds <= 0 {
+		c.Alerting.TimeoutSeconds = 10
+	}
+	if c.Profiling.IntervalSeconds <= 0 {
+		c.Profiling.IntervalSeconds = 600
+	}
+	if c.Profiling.CPUSeconds <= 0 {
+		c.Profiling.CPUSeconds = 30
+	}
+	if len(c.Profiling.Profiles) == 0 {
+		c.Profiling.Profiles = []string{"cpu", "heap"}
+	}
+	if c.Profiling.Retention <= 0 {
+		c.Profiling.Retention = 48
+	}
+	if c.Tracing.Endpoint == "" {
+		c.Tracing.Endpoint = "http://localhost:4318/v1/traces"
+	}
+	if c.Tracing.SampleRatio <= 0 {
+		c.Tracing.SampleRatio = 1
+	}
+	if c.Tracing.TimeoutSeconds <= 0 {
+		c.Tracing.TimeoutSeconds = 10
+	}
+	if c.Heartbeat.IntervalSeconds <= 0 {
+		c.Heartbeat.IntervalSeconds = 30
+	}
+	if c.Heartbeat.TimeoutSeconds <= 0 {
+		c.Heartbeat.TimeoutSeconds = 10
+	}
+	if c.Jobs.Workers <= 0 {
+		c.Jobs.Workers = 4
+	}
+	if c.Jobs.QueueSize <= 0 {
+		c.Jobs.QueueSize = 1000
+	}
+	if c.Jobs.MaxAttempts <= 0 {
+		c.Jobs.MaxAttempts = 3
+	}
+	if c.Jobs.RetryBackoffSeconds <= 0 {
+		c.Jobs.RetryBackoffSeconds = 5
+	}
+	if c.Jobs.DeadLetterSize <= 0 {
+		c.Jobs.DeadLetterSize = 100
+	}
+}
+
+// validateEnv проверяет имена переменных окружения секции prefix
+func validateEnv(prefix string, env map[string]string) []error {
+	var errs []error
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name == "" || strings.ContainsAny(name, "=\x00") {
+			errs = append(errs, fmt.Errorf("%s.env: invalid variable name %q", prefix, name))
+		}
+	}
+	return errs
+}
+
+// Validate проверяет согласованность конфигурации и возвращает все найденные проблемы
+func (c *Config) Validate() error {
+	var errs []error
+
+	if _, err := logger.ParseLevel(c.Service.LogLevel); err != nil {
+		errs = append(errs, fmt.Errorf("service.log_level: %w", err))
+	}
+	if c.Service.LogEncoding != "" {
+		if _, err := logger.LookupEncoder(c.Service.LogEncoding); err != nil {
+			errs = append(errs, fmt.Errorf("service.log_encoding: %w", err))
+		}
+	}
+	if c.Service.Locale != "" {
+		if _, err := i18n.Parse(c.Service.Locale); err != nil {
+			errs = append(errs, fmt.Errorf("service.locale: %w", err))
+		}
+	}
+	switch c.Service.StartType {
+	case "", "auto", "delayed", "manual", "disabled":
+	default:
+		errs = append(errs, fmt.Errorf("service.start_type must be auto, delayed, manual or disabled"))
+	}
+	switch c.Service.Recovery.Restart {
+	case "", "always", "on-failure", "never":
+	default:
+		errs = append(errs, fmt.Errorf("service.recovery.restart must be always, on-failure or never"))
+	}
+	if c.Scheduler.MaxPanicRestarts < 0 {
+		errs = append(errs, fmt.Errorf("scheduler.max_panic_restarts must be >= 0"))
+	}
+	if c.Scheduler.BackoffSeconds < 0 {
+		errs = append(errs, fmt.Errorf("scheduler.backoff_seconds must be >= 0"))
+	}
+	if c.Scheduler.MaxConcurrentRuns < 0 {
+		errs = append(errs, fmt.Errorf("scheduler.max_concurrent_runs must be >= 0"))
+	}
+	if c.Scheduler.MaxConcurrentHandlers < 0 {
+		errs = append(errs, fmt.Errorf("scheduler.max_concurrent_handlers must be >= 0"))
+	}
+	switch c.Scheduler.ReloadPolicy {
+	case "", "finish", "cancel":
+	default:
+		errs = append(errs, fmt.Errorf("scheduler.reload_policy must be finish or cancel"))
+	}
+	switch c.Scheduler.OverlapPolicy {
+	case "", "skip", "queue", "concurrent":
+	default:
+		errs = append(errs, fmt.Errorf("scheduler.overlap_policy must be skip, queue or concurrent"))
+	}
+	switch c.Scheduler.MisfirePolicy {
+	case "", "skip", "run":
+	default:
+		errs = append(errs, fmt.Errorf("scheduler.misfire_policy must be skip or run"))
+	}
+	timerNames := make([]string, 0, len(c.Scheduler.Timers))
+	for name := range c.Scheduler.Timers {
+		timerNames = append(timerNames, name)
+	}
+	sort.Strings(timerNames)
+	for _, name := range timerNames {
+		errs = append(errs, validateEnv("scheduler.timers."+name, c.Scheduler.Timers[name].Env)...)
+		timer := c.Scheduler.Timers[name]
+		if timer.IntervalSeconds < 0 || timer.TimeoutSeconds < 0 {
+			errs = append(errs, fmt.Errorf("scheduler.timers.%s: interval_seconds and timeout_seconds must be >= 0", name))
+		}
+		switch timer.ReloadPolicy {
+		case "", "finish", "cancel":
+		default:
+			errs = append(errs, fmt.Errorf("scheduler.timers.%s.reload_policy must be finish or cancel", name))
+		}
+		switch timer.OverlapPolicy {
+		case "", "skip", "queue", "concurrent":
+		default:
+			errs = append(errs, fmt.Errorf("scheduler.timers.%s.overlap_policy must be skip, queue or concurrent", name))
+		}
+		switch timer.MisfirePolicy {
+		case "", "skip", "run":
+		default:
+			errs = append(errs, fmt.Errorf("scheduler.timers.%s.misfire_policy must be skip or run", name))
+		}
+		budget := timer.Budget
+		if budget.WallSeconds < 0 || budget.CPUSeconds < 0 || budget.Violations < 0 {
+			errs = append(errs, fmt.Errorf("scheduler.timers.%s.budget: wall_seconds, cpu_seconds and violations must be >= 0", name))
+		}
+		if budget.ThrottleFactor < 0 || budget.ThrottleFactor == 1 {
+			errs = append(errs, fmt.Errorf("scheduler.timers.%s.budget.throttle_factor must be 0 or >= 2", name))
+		}
+	}
+	nsNames := make([]string, 0, len(c.Scheduler.Namespaces))
+	for name := range c.Scheduler.Namespaces {
+		nsNames = append(nsNames, name)
+	}
+	sort.Strings(nsNames)
+	for _, name := range nsNames {
+		if name == "" || strings.Contains(name, "/") {
+			errs = append(errs, fmt.Errorf("scheduler.namespaces: invalid namespace name %q", name))
+		}
+		if c.Scheduler.Namespaces[name].MaxConcurrentRuns < 0 {
+			errs = append(errs, fmt.Errorf("scheduler.namespaces.%s.max_concurrent_runs must be >= 0", name))
+		}
+	}
+	if c.Metrics.Enabled {
+		if _, _, err := net.SplitHostPort(c.Metrics.Listen); err != nil {
+			errs = append(errs, fmt.Errorf("metrics.listen: %w", err))
+		}
+		switch c.Metrics.LabelOverflow {
+		case "", "aggregate", "reject":
+		default:
+			errs = append(errs, fmt.Errorf("metrics.label_overflow: must be aggregate or reject, got %q", c.Metrics.LabelOverflow))
+		}
+	}
+	if rw := c.Metrics.RemoteWrite; rw.Enabled {
+		if !c.Metrics.Enabled {
+			errs = append(errs, fmt.Errorf("metrics.remote_write requires metrics.enabled"))
+		}
+		if u, err := url.Parse(rw.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("metrics.remote_write.url: must be an http(s) URL, got %q", rw.URL))
+		}
+		if rw.MaxRetries < 0 {
+			errs = append(errs, fmt.Errorf("metrics.remote_write.max_retries must be >= 0"))
+		}
+		if rw.BearerToken != "" && rw.Username != "" {
+			errs = append(errs, fmt.Errorf("metrics.remote_write: bearer_token and username are mutually exclusive"))
+		}
+	}
+	if c.Metrics.FinalScrapeSeconds < 0 {
+		errs = append(errs, fmt.Errorf("metrics.final_scrape_seconds must not be negative"))
+	}
+	if c.Metrics.PerfCounters.Enabled && !c.Metrics.Enabled {
+		errs = append(errs, fmt.Errorf("metrics.perf_counters requires metrics.enabled"))
+	}
+	if c.Admin.Enabled {
+		if _, _, err := net.SplitHostPort(c.Admin.Listen); err != nil {
+			errs = append(errs, fmt.Errorf("admin.listen: %w", err))
+		}
+	}
+	if c.GRPC.Enabled && !c.GRPC.Socket {
+		if _, _, err := net.SplitHostPort(c.GRPC.Listen); err != nil {
+			errs = append(errs, fmt.Errorf("grpc.listen: %w", err))
+		}
+	}
+	if c.GRPC.Enabled && c.GRPC.Socket && c.Admin.Socket && c.GRPC.SocketPath != "" && c.GRPC.SocketPath == c.Admin.SocketPath {
+		errs = append(errs, fmt.Errorf("grpc.socket_path and admin.socket_path must differ"))
+	}
+	if c.HTTP.Enabled {
+		if _, _, err := net.SplitHostPort(c.HTTP.Listen); err != nil {
+			errs = append(errs, fmt.Errorf("http.listen: %w", err))
+		}
+		if (c.HTTP.TLS.CertFile == "") != (c.HTTP.TLS.KeyFile == "") {
+			errs = append(errs, fmt.Errorf("http.tls: cert_file and key_file must be set together"))
+		}
+	}
+
+	// Включенные серверы не должны делить адрес
+	listeners := []struct {
+		key     string
+		addr    string
+		enabled bool
+	}{
+		{"metrics.listen", c.Metrics.Listen, c.Metrics.Enabled},
+		{"admin.listen", c.Admin.Listen, c.Admin.Enabled},
+		{"grpc.listen", c.GRPC.Listen, c.GRPC.Enabled && !c.GRPC.Socket},
+		{"http.listen", c.HTTP.Listen, c.HTTP.Enabled},
+	}
+	for i, a := range listeners {
+		for _, b := range listeners[i+1:] {
+			if a.enabled && b.enabled && a.addr == b.addr {
+				errs = append(errs, fmt.Errorf("%s and %s must differ", a.key, b.key))
+			}
+		}
+	}
+	if c.Election.Enabled && c.Election.LockFile == "" {
+		errs = append(errs, fmt.Errorf("election.lock_file is required when election is enabled"))
+	}
+	if c.Database.Enabled {
+		if c.Database.Driver == "" {
+			errs = append(errs, fmt.Errorf("database.driver is required when database is enabled"))
+		}
+		if c.Database.DSN == "" && c.Database.DSNEnv == "" && c.Database.DSNFile == "" {
+			errs = append(errs, fmt.Errorf("database: one of dsn, dsn_env or dsn_file is required"))
+		}
+	}
+	if c.Redis.Enabled {
+		if _, _, err := net.SplitHostPort(c.Redis.Addr); err != nil {
+			errs = append(errs, fmt.Errorf("redis.addr: %w", err))
+		}
+		if c.Redis.DB < 0 {
+			errs = append(errs, fmt.Errorf("redis.db must be >= 0"))
+		}
+	}
+	if c.HTTPClient.MaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("http_client.max_retries must be >= 0"))
+	}
+	for name, rl := range c.RateLimits {
+		if rl.RequestsPerSecond <= 0 {
+			errs = append(errs, fmt.Errorf("rate_limits.%s.requests_per_second must be > 0", name))
+		}
+	}
+	depNames := map[string]bool{"db": c.Database.Enabled, "redis": c.Redis.Enabled}
+	for i, d := range c.Health.Dependencies {
+		switch {
+		case d.Name == "":
+			errs = append(errs, fmt.Errorf("health.dependencies[%d].name is required", i))
+		case depNames[d.Name]:
+			errs = append(errs, fmt.Errorf("health.dependencies[%d]: duplicate name %s", i, d.Name))
+		}
+		depNames[d.Name] = true
+		switch d.Type {
+		case "tcp", "http", "dns", "file":
+		default:
+			errs = append(errs, fmt.Errorf("health.dependencies[%d].type must be tcp, http, dns or file", i))
+		}
+		if d.Endpoint == "" {
+			errs = append(errs, fmt.Errorf("health.dependencies[%d].endpoint is required", i))
+		} else if d.Type == "http" {
+			if u, err := url.Parse(d.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errs = append(errs, fmt.Errorf("health.dependencies[%d].endpoint must be an http or https URL", i))
+			}
+		}
+	}
+	watchNames := make(map[string]bool)
+	for i, w := range c.Watcher.Watches {
+		switch {
+		case w.Name == "":
+			errs = append(errs, fmt.Errorf("watcher.watches[%d].name is required", i))
+		case watchNames[w.Name]:
+			errs = append(errs, fmt.Errorf("watcher.watches[%d]: duplicate name %s", i, w.Name))
+		}
+		watchNames[w.Name] = true
+		if w.Path == "" {
+			errs = append(errs, fmt.Errorf("watcher.watches[%d].path is required", i))
+		}
+		if _, err := filepath.Match(w.Pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("watcher.watches[%d].pattern: %w", i, err))
+		}
+	}
+	procNames := make(map[string]bool)
+	for i, p := range c.Processes {
+		switch {
+		case p.Name == "":
+			errs = append(errs, fmt.Errorf("processes[%d].name is required", i))
+		case procNames[p.Name]:
+			errs = append(errs, fmt.Errorf("processes[%d]: duplicate name %s", i, p.Name))
+		}
+		procNames[p.Name] = true
+		if p.Command == "" {
+			errs = append(errs, fmt.Errorf("processes[%d].command is required", i))
+		}
+		switch p.Restart {
+		case "always", "on-failure", "never":
+		default:
+			errs = append(errs, fmt.Errorf("processes[%d].restart must be always, on-failure or never", i))
+		}
+		if p.CPUPercent < 0 || p.CPUPercent > 100 {
+			errs = append(errs, fmt.Errorf("processes[%d].cpu_percent must be between 0 and 100", i))
+		}
+		if p.MemoryMB < 0 {
+			errs = append(errs, fmt.Errorf("processes[%d].memory_mb must not be negative", i))
+		}
+		errs = append(errs, validateEnv(fmt.Sprintf("processes[%d]", i), p.Env)...)
+	}
+	for _, point := range []struct {
+		name  string
+		hooks []HookConfig
+	}{
+		{"post_start", c.Hooks.PostStart},
+		{"pre_stop", c.Hooks.PreStop},
+		{"timer_failure", c.Hooks.TimerFailure},
+	} {
+		for i, h := range point.hooks {
+			prefix := fmt.Sprintf("hooks.%s[%d]", point.name, i)
+			if h.Command == "" {
+				errs = append(errs, fmt.Errorf("%s.command is required", prefix))
+			}
+			if h.TimeoutSeconds < 0 {
+				errs = append(errs, fmt.Errorf("%s.timeout_seconds must be >= 0", prefix))
+			}
+			errs = append(errs, validateEnv(prefix, h.Env)...)
+		}
+	}
+	if c.Alerting.Enabled && c.Alerting.Webhook.URL == "" && c.Alerting.Slack.WebhookURL == "" && c.Alerting.Email.SMTPAddr == "" {
+		errs = append(errs, fmt.Errorf("alerting: at least one of webhook.url, slack.webhook_url or email.smtp_addr is required"))
+	}
+	if email := c.Alerting.Email; email.SMTPAddr != "" {
+		if _, _, err := net.SplitHostPort(email.SMTPAddr); err != nil {
+			errs = append(errs, fmt.Errorf("alerting.email.smtp_addr: %w", err))
+		}
+		if email.From == "" || len(email.To) == 0 {
+			errs = append(errs, fmt.Errorf("alerting.email: from and to are required"))
+		}
+	}
+	if c.Profiling.Enabled {
+		if c.Profiling.CPUSeconds >= c.Profiling.IntervalSeconds {
+			errs = append(errs, fmt.Errorf("profiling.cpu_seconds must be less than interval_seconds"))
+		}
+		for _, profile := range c.Profiling.Profiles {
+			switch profile {
+			case "cpu", "heap", "allocs", "goroutine", "mutex", "block":
+			default:
+				errs = append(errs, fmt.Errorf("profiling.profiles: unknown profile %q", profile))
+			}
+		}
+		if u := c.Profiling.Pyroscope.URL; u != "" && !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+			errs = append(errs, fmt.Errorf("profiling.pyroscope.url must start with http:// or https://"))
+		}
+	}
+	if c.Tracing.Enabled {
+		if u := c.Tracing.Endpoint; !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+			errs = append(errs, fmt.Errorf("tracing.endpoint must start with http:// or https://"))
+		}
+		if c.Tracing.SampleRatio > 1 {
+			errs = append(errs, fmt.Errorf("tracing.sample_ratio must be between 0 and 1"))
+		}
+	}
+	if hb := c.Heartbeat; hb.Enabled {
+		if u, err := url.Parse(hb.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("heartbeat.url: must be an http(s) URL, got %q", hb.URL))
+		}
+		if hb.MaxRetries < 0 {
+			errs = append(errs, fmt.Errorf("heartbeat.max_retries must be >= 0"))
+		}
+	}
+	if c.Watchdog.Enabled && c.Watchdog.MaxGoroutines <= 0 && c.Watchdog.MaxHeapMB <= 0 {
+		errs = append(errs, fmt.Errorf("watchdog: at least one of max_goroutines or max_heap_mb must be set"))
+	}
+	if c.Kubernetes.Enabled && c.Kubernetes.DrainSeconds+c.Metrics.FinalScrapeSeconds >= c.Kubernetes.TerminationGracePeriodSeconds {
+		errs = append(errs, fmt.Errorf("kubernetes.drain_seconds plus metrics.final_scrape_seconds must be less than termination_grace_period_seconds"))
+	}
+
+	return errors.Join(errs...)
 }
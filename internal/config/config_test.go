@@ -1,9 +1,16 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"service-boilerplate/internal/apperr"
+	"service-boilerplate/internal/paths"
+	"service-boilerplate/internal/secrets"
 )
 
 // TestLoad_Success проверяет успешную загрузку конфигурации из YAML
@@ -69,9 +76,9 @@ func TestLoad_DefaultValues(t *testing.T) {
 		t.Fatalf("Load() error = %v", err)
 	}
 
-	// Проверяем значения по умолчанию
-	if cfg.Service.LogDir != "./logs" {
-		t.Errorf("Service.LogDir default = %v, want ./logs", cfg.Service.LogDir)
+	// Проверяем значения по умолчанию: директории определяются после имени экземпляра
+	if cfg.Service.LogDir != "" {
+		t.Errorf("Service.LogDir default = %v, want empty before ResolvePaths", cfg.Service.LogDir)
 	}
 	if cfg.Scheduler.MaxPanicRestarts != 5 {
 		t.Errorf("Scheduler.MaxPanicRestarts default = %v, want 5", cfg.Scheduler.MaxPanicRestarts)
@@ -82,6 +89,9 @@ func TestLoad_DefaultValues(t *testing.T) {
 	if cfg.Metrics.Listen != ":9090" {
 		t.Errorf("Metrics.Listen default = %v, want :9090", cfg.Metrics.Listen)
 	}
+	if cfg.Jobs.Workers != 4 || cfg.Jobs.QueueSize != 1000 || cfg.Jobs.MaxAttempts != 3 {
+		t.Errorf("Jobs default = %+v", cfg.Jobs)
+	}
 }
 
 // TestLoad_FileNotFound проверяет ошибку при отсутствии файла
@@ -107,8 +117,9 @@ service:
 	}
 
 	_, err := Load(configPath)
-	if err == nil {
-		t.Error("Load() expected error for invalid YAML, got nil")
+	var cfgErr *apperr.ConfigError
+	if !errors.As(err, &cfgErr) || cfgErr.Path != configPath {
+		t.Errorf("Load() error = %v, want ConfigError for invalid YAML", err)
 	}
 }
 
@@ -128,8 +139,29 @@ func TestLoad_EmptyFile(t *testing.T) {
 	}
 
 	// Проверяем что применились дефолтные значения
-	if cfg.Service.LogDir != "./logs" {
-		t.Errorf("expected default log_dir, got %v", cfg.Service.LogDir)
+	cfg.Service.Name = "svc"
+	cfg.ResolvePaths()
+	if want := paths.Default("svc").Log; cfg.Service.LogDir != want {
+		t.Errorf("expected default log_dir %v, got %v", want, cfg.Service.LogDir)
+	}
+}
+
+// TestResolvePaths проверяет директории по умолчанию и сохранение заданных
+func TestResolvePaths(t *testing.T) {
+	cfg := Default()
+	cfg.Service.Name = "svc"
+	cfg.Service.LogDir = "./testlogs"
+	cfg.ResolvePaths()
+
+	def := paths.Default("svc")
+	if cfg.Service.LogDir != "./testlogs" {
+		t.Errorf("LogDir = %v, want ./testlogs", cfg.Service.LogDir)
+	}
+	if cfg.Service.DataDir != def.Data || cfg.Service.StateDir != def.State || cfg.Service.CacheDir != def.Cache {
+		t.Errorf("dirs = %v %v %v, want %+v", cfg.Service.DataDir, cfg.Service.StateDir, cfg.Service.CacheDir, def)
+	}
+	if want := filepath.Join(def.State, "state.db"); cfg.Store.Path != want {
+		t.Errorf("Store.Path = %v, want %v", cfg.Store.Path, want)
 	}
 }
 
@@ -180,3 +212,216 @@ scheduler:
 		t.Errorf("BackoffSeconds with zero = %v, want 5", cfg.Scheduler.BackoffSeconds)
 	}
 }
+
+// TestValidate проверяет валидацию конфигурации
+func TestValidate(t *testing.T) {
+	valid := Config{
+		Metrics: MetricsConfig{Enabled: true, Listen: ":9090"},
+		Admin:   AdminConfig{Enabled: true, Listen: "127.0.0.1:9091"},
+		// Сервер gRPC на локальном канале не требует TCP адреса
+		GRPC: GRPCConfig{Enabled: true, Socket: true},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	invalid := Config{
+		Service:    ServiceConfig{LogLevel: "verbose", LogEncoding: "xml", Locale: "de", StartType: "boot", Recovery: RecoveryConfig{Restart: "sometimes"}},
+		Scheduler:  SchedulerConfig{MaxConcurrentRuns: -1, MaxConcurrentHandlers: -1, OverlapPolicy: "parallel", MisfirePolicy: "fire", Timers: map[string]TimerConfig{"report": {Env: map[string]string{"A=B": "1"}, Budget: TimerBudgetConfig{ThrottleFactor: 1}, TimeoutSeconds: -1, ReloadPolicy: "restart", OverlapPolicy: "wait", MisfirePolicy: "all"}}, Namespaces: map[string]NamespaceConfig{"tenant": {MaxConcurrentRuns: -1}}},
+		Metrics:    MetricsConfig{Enabled: true, Listen: "no-port", LabelOverflow: "drop", FinalScrapeSeconds: -1, RemoteWrite: RemoteWriteConfig{Enabled: true, URL: "prometheus:9090", MaxRetries: -1}},
+		Admin:      AdminConfig{Enabled: true, Listen: "no-port", Socket: true, SocketPath: "/run/svc.sock"},
+		GRPC:       GRPCConfig{Enabled: true, Socket: true, SocketPath: "/run/svc.sock"},
+		Watchdog:   WatchdogConfig{Enabled: true},
+		Health:     HealthConfig{Dependencies: []DependencyConfig{{Name: "redis", Type: "tcp", Endpoint: "cache:6379"}, {Name: "crm", Type: "ftp"}, {Name: "api", Type: "http", Endpoint: "api.local/health"}}},
+		Election:   ElectionConfig{Enabled: true},
+		HTTP:       HTTPConfig{Enabled: true, Listen: "no-port", TLS: TLSConfig{CertFile: "cert.pem"}},
+		Watcher:    WatcherConfig{Watches: []WatchConfig{{Name: "in", Pattern: "["}, {Name: "in", Path: "/tmp"}}},
+		Database:   DatabaseConfig{Enabled: true},
+		Redis:      RedisConfig{Enabled: true, Addr: "no-port", DB: -1},
+		HTTPClient: HTTPClientConfig{MaxRetries: -1},
+		RateLimits: map[string]RateLimitConfig{"crm": {}},
+		Processes:  []ProcessConfig{{Restart: "sometimes", CPUPercent: 150}},
+		Hooks:      HooksConfig{PreStop: []HookConfig{{TimeoutSeconds: 1}}},
+		Alerting:   AlertingConfig{Enabled: true},
+		Profiling:  ProfilingConfig{Enabled: true, IntervalSeconds: 10, CPUSeconds: 30, Profiles: []string{"cpu", "threads"}},
+		Tracing:    TracingConfig{Enabled: true, Endpoint: "localhost:4318", SampleRatio: 2},
+		Heartbeat:  HeartbeatConfig{Enabled: true, URL: "fleet.local/heartbeat", MaxRetries: -1},
+	}
+	err := invalid.Validate()
+	if err == nil {
+		t.Fatal("Validate() expected error, got nil")
+	}
+	for _, want := range []string{"service.log_level", "service.log_encoding", "service.locale", "service.start_type", "service.recovery.restart", "scheduler.max_concurrent_runs", "scheduler.max_concurrent_handlers", "scheduler.timers.report.env", "scheduler.timers.report.budget.throttle_factor", "scheduler.timers.report: interval_seconds", "scheduler.timers.report.reload_policy", "scheduler.overlap_policy", "scheduler.timers.report.overlap_policy", "scheduler.misfire_policy", "scheduler.timers.report.misfire_policy", "scheduler.namespaces.tenant.max_concurrent_runs", "metrics.listen", "metrics.label_overflow", "metrics.remote_write.url", "metrics.remote_write.max_retries", "metrics.final_scrape_seconds", "admin.listen", "grpc.socket_path", "must differ", "watchdog", "election.lock_file", "health.dependencies[0]: duplicate name redis", "health.dependencies[1].type", "health.dependencies[1].endpoint is required", "health.dependencies[2].endpoint must be", "watcher.watches[0].path", "watcher.watches[0].pattern", "duplicate name", "http.listen", "http.tls", "database.driver", "dsn_env", "redis.addr", "redis.db", "http_client.max_retries", "rate_limits.crm", "processes[0].name", "processes[0].command", "processes[0].restart", "processes[0].cpu_percent", "hooks.pre_stop[0].command", "alerting: at least one", "profiling.cpu_seconds", "unknown profile \"threads\"", "tracing.endpoint", "tracing.sample_ratio", "heartbeat.url", "heartbeat.max_retries"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %v, want mention of %q", err, want)
+		}
+	}
+}
+
+// TestDefault_SaveLoad проверяет что конфигурация по умолчанию валидна и переживает запись/чтение
+func TestDefault_SaveLoad(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Default().Validate() error = %v", err)
+	}
+
+	configPath := filepath.Join(t.TempDir(), "configs", "config.yaml")
+	cfg.Service.Name = "svc-test"
+	if err := Save(configPath, cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, cfg) {
+		t.Errorf("Load() = %+v, want %+v", *loaded, *cfg)
+	}
+}
+
+// TestLoad_Encrypted проверяет расшифровку значений !encrypted и их скрытие в View
+func TestLoad_Encrypted(t *testing.T) {
+	dir := t.TempDir()
+	key, _ := secrets.GenerateKey()
+	t.Setenv(secrets.KeyEnv, "")
+	if err := secrets.SaveKey(filepath.Join(dir, secrets.KeyFileName), key); err != nil {
+		t.Fatal(err)
+	}
+	token, _ := secrets.Encrypt(key, []byte("admin-token"))
+	apiKey, _ := secrets.Encrypt(key, []byte("api-key"))
+	port, _ := secrets.Encrypt(key, []byte("6380"))
+
+	configPath := filepath.Join(dir, "config.yaml")
+	content := "admin:\n  token: !encrypted " + token + "\n" +
+		"redis:\n  addr: localhost:6379\n  db: !encrypted " + port + "\n" +
+		"processes:\n  - name: worker\n    command: worker\n    env:\n      API_KEY: !encrypted " + apiKey + "\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Admin.Token != "admin-token" || cfg.Redis.DB != 6380 || cfg.Processes[0].Env["API_KEY"] != "api-key" {
+		t.Errorf("Load() decrypted values = %q, %d, %q", cfg.Admin.Token, cfg.Redis.DB, cfg.Processes[0].Env["API_KEY"])
+	}
+
+	view, err := cfg.View()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := view["processes"].([]interface{})[0].(map[string]interface{})["env"].(map[string]interface{})
+	if env["API_KEY"] != Redacted || view["admin"].(map[string]interface{})["token"] != Redacted {
+		t.Errorf("View() does not redact encrypted values: %v", view)
+	}
+
+	// Без ключа загрузка конфига с зашифрованными значениями невозможна
+	os.Remove(filepath.Join(dir, secrets.KeyFileName))
+	if _, err := Load(configPath); !errors.Is(err, secrets.ErrNoKey) {
+		t.Errorf("Load() without key error = %v, want ErrNoKey", err)
+	}
+}
+
+// TestView_SecretMaps проверяет скрытие всех значений заголовков
+// и окружения команд в View
+func TestView_SecretMaps(t *testing.T) {
+	cfg := Default()
+	cfg.Metrics.RemoteWrite.Headers = map[string]string{"Authorization": "Bearer secret", "X-Scope-OrgID": "tenant"}
+	cfg.Heartbeat.Headers = map[string]string{"X-Api-Key": "secret"}
+	cfg.Scheduler.Timers = map[string]TimerConfig{"export": {Env: map[string]string{"PGPASSWORD": "secret"}}}
+	cfg.Hooks.PreStop = []HookConfig{{Command: "flush", Env: map[string]string{"TOKEN": "secret"}}}
+	cfg.Processes = []ProcessConfig{{Name: "worker", Command: "worker", Env: map[string]string{"DB_PASSWORD": "secret"}}}
+
+	view, err := cfg.View()
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := view["metrics"].(map[string]interface{})
+	headers := metrics["remote_write"].(map[string]interface{})["headers"].(map[string]interface{})
+	for name, value := range headers {
+		if value != Redacted {
+			t.Errorf("View() metrics.remote_write.headers[%s] = %v, want redacted", name, value)
+		}
+	}
+	if len(headers) != 2 || cfg.Metrics.RemoteWrite.Headers["Authorization"] != "Bearer secret" {
+		t.Errorf("View() headers = %v, config headers = %v", headers, cfg.Metrics.RemoteWrite.Headers)
+	}
+	heartbeat := view["heartbeat"].(map[string]interface{})
+	if value := heartbeat["headers"].(map[string]interface{})["X-Api-Key"]; value != Redacted {
+		t.Errorf("View() heartbeat.headers[X-Api-Key] = %v, want redacted", value)
+	}
+	process := view["processes"].([]interface{})[0].(map[string]interface{})
+	if value := process["env"].(map[string]interface{})["DB_PASSWORD"]; value != Redacted || process["command"] != "worker" {
+		t.Errorf("View() processes[0] = %v, want redacted env", process)
+	}
+	timers := view["scheduler"].(map[string]interface{})["timers"].(map[string]interface{})
+	if value := timers["export"].(map[string]interface{})["env"].(map[string]interface{})["PGPASSWORD"]; value != Redacted {
+		t.Errorf("View() scheduler.timers.export.env[PGPASSWORD] = %v, want redacted", value)
+	}
+	hook := view["hooks"].(map[string]interface{})["pre_stop"].([]interface{})[0].(map[string]interface{})
+	if value := hook["env"].(map[string]interface{})["TOKEN"]; value != Redacted {
+		t.Errorf("View() hooks.pre_stop[0].env[TOKEN] = %v, want redacted", value)
+	}
+}
+
+// TestIsGenerated проверяет распознавание сгенерированного конфига
+func TestIsGenerated(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	generated := filepath.Join(tmpDir, "generated.yaml")
+	if err := Save(generated, Default()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	manual := filepath.Join(tmpDir, "manual.yaml")
+	if err := os.WriteFile(manual, []byte("service:\n  log_dir: ./logs\n"), 0644); err != nil {
+		t.Fatalf("failed to create test config: %v", err)
+	}
+
+	if ok, err := IsGenerated(generated); err != nil || !ok {
+		t.Errorf("IsGenerated(generated) = %v, %v, want true", ok, err)
+	}
+	if ok, err := IsGenerated(manual); err != nil || ok {
+		t.Errorf("IsGenerated(manual) = %v, %v, want false", ok, err)
+	}
+	if _, err := IsGenerated(filepath.Join(tmpDir, "missing.yaml")); err == nil {
+		t.Error("IsGenerated(missing) expected error")
+	}
+}
+
+// TestDiff проверяет список измененных ключей и скрытие секретов
+func TestDiff(t *testing.T) {
+	from := Default()
+	from.Admin.Token = "old-token"
+	to := Default()
+	to.Admin.Token = "new-token"
+	to.Service.LogLevel = "debug"
+	to.Processes = []ProcessConfig{{Name: "legacy", Command: "/opt/legacy"}}
+
+	changes, err := Diff(from, to)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	got := make(map[string]Change, len(changes))
+	for _, c := range changes {
+		got[c.Key] = c
+	}
+
+	if c := got["service.log_level"]; c.Old != "info" || c.New != "debug" {
+		t.Errorf("service.log_level change = %+v, want info -> debug", c)
+	}
+	if c, ok := got["admin.token"]; !ok || c.Old != Redacted || c.New != Redacted {
+		t.Errorf("admin.token change = %+v (found %v), want both redacted", c, ok)
+	}
+	if c := got["processes.0.command"]; c.Old != nil || c.New != "/opt/legacy" {
+		t.Errorf("processes.0.command change = %+v, want nil -> /opt/legacy", c)
+	}
+	if _, ok := got["scheduler.backoff_seconds"]; ok {
+		t.Error("unchanged key reported in diff")
+	}
+
+	if changes, _ := Diff(from, from); len(changes) != 0 {
+		t.Errorf("Diff(from, from) = %v, want no changes", changes)
+	}
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Результаты перезагрузки конфигурации
+const (
+	ReloadSuccess          = "success"
+	ReloadValidationFailed = "validation_failed"
+	// ReloadRolledBack конфигурация не прошла испытательный срок
+	// (reload.rollback) и заменена предыдущей
+	ReloadRolledBack = "rolled_back"
+)
+
+// Change изменение одного ключа конфигурации. Key - путь с ключами
+// как в YAML через точку (scheduler.max_concurrent_runs, processes.0.args).
+// Секреты в Old и New заменены на Redacted, отсутствующий ключ - nil
+type Change struct {
+	Key string      `json:"key"`
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// ReloadStatus результат последней перезагрузки конфигурации
+type ReloadStatus struct {
+	Time   time.Time `json:"time"`
+	Result string    `json:"result"`
+	Error  string    `json:"error,omitempty"`
+	// Changes измененные ключи относительно действующей конфигурации
+	Changes []Change `json:"changes"`
+	// RestartRequired ключи, которые применяются только после перезапуска
+	RestartRequired []string `json:"restart_required,omitempty"`
+}
+
+// Diff возвращает изменения от from к to, отсортированные по ключу.
+// Изменение определяется по исходным значениям, поэтому замена секрета
+// видна, хотя оба значения показываются как Redacted
+func Diff(from, to *Config) ([]Change, error) {
+	oldRaw, err := flatConfig(from, false)
+	if err != nil {
+		return nil, err
+	}
+	newRaw, err := flatConfig(to, false)
+	if err != nil {
+		return nil, err
+	}
+	oldView, err := flatConfig(from, true)
+	if err != nil {
+		return nil, err
+	}
+	newView, err := flatConfig(to, true)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]struct{}, len(newRaw))
+	for k := range oldRaw {
+		keys[k] = struct{}{}
+	}
+	for k := range newRaw {
+		keys[k] = struct{}{}
+	}
+
+	changes := []Change{}
+	for k := range keys {
+		if reflect.DeepEqual(oldRaw[k], newRaw[k]) {
+			continue
+		}
+		changes = append(changes, Change{Key: k, Old: oldView[k], New: newView[k]})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes, nil
+}
+
+// flatConfig возвращает значения конфигурации по путям ключей.
+// redacted - значения из View (секреты скрыты)
+func flatConfig(c *Config, redacted bool) (map[string]interface{}, error) {
+	var tree map[string]interface{}
+	if redacted {
+		view, err := c.View()
+		if err != nil {
+			return nil, err
+		}
+		tree = view
+	} else {
+		data, err := yaml.Marshal(c)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, &tree); err != nil {
+			return nil, err
+		}
+	}
+	flat := make(map[string]interface{})
+	flatten(flat, "", tree)
+	return flat, nil
+}
+
+// flatten раскладывает вложенные секции и списки в пути через точку.
+// Пустые секции и списки остаются значениями, чтобы их появление
+// тоже было видно в Diff
+func flatten(out map[string]interface{}, prefix string, node interface{}) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 && prefix != "" {
+			out[prefix] = v
+			return
+		}
+		for key, child := range v {
+			flatten(out, join(key), child)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			out[prefix] = v
+			return
+		}
+		for i, child := range v {
+			flatten(out, join(strconv.Itoa(i)), child)
+		}
+	default:
+		out[prefix] = v
+	}
+}
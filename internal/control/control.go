@@ -0,0 +1,305 @@
+// Package control предоставляет gRPC интерфейс управления запущенным сервисом.
+// Операции повторяют admin HTTP API, контракт описан в controlpb/control.proto
+package control
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"service-boilerplate/internal/appctx"
+	"service-boilerplate/internal/apperr"
+	"service-boilerplate/internal/audit"
+	"service-boilerplate/internal/buildinfo"
+	"service-boilerplate/internal/config"
+	"service-boilerplate/internal/control/controlpb"
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/localsock"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/scheduler"
+)
+
+// Server предоставляет gRPC сервер управления
+type Server struct {
+	controlpb.UnimplementedControlServer
+
+	log       *logger.Logger
+	scheduler *scheduler.Scheduler
+	events    *events.Bus
+	audit     *audit.Log
+	identity  appctx.Identity
+	startedAt time.Time
+	server    *grpc.Server
+	listener  net.Listener
+	enabled   bool
+	listen    string
+	token     string
+	// socketPath локальный канал вместо TCP адреса (пустой - TCP)
+	socketPath string
+}
+
+// New создает новый gRPC сервер управления. Адрес, токен и признак
+// включения берутся из cfg.GRPC
+func New(log *logger.Logger, sched *scheduler.Scheduler, cfg *config.Config, identity appctx.Identity) *Server {
+	s := &Server{
+		log:       log,
+		scheduler: sched,
+		identity:  identity,
+		startedAt: time.Now(),
+		enabled:   cfg.GRPC.Enabled,
+		listen:    cfg.GRPC.Listen,
+		token:     cfg.GRPC.Token,
+	}
+
+	if s.enabled {
+		s.server = grpc.NewServer(grpc.UnaryInterceptor(s.authenticate))
+		controlpb.RegisterControlServer(s.server, s)
+	}
+
+	return s
+}
+
+// SetEvents включает публикацию изменений уровня логирования
+func (s *Server) SetEvents(bus *events.Bus) {
+	s.events = bus
+}
+
+// SetAudit включает запись действий операторов в журнал действий
+func (s *Server) SetAudit(log *audit.Log) {
+	s.audit = log
+}
+
+// SetSocket переводит сервер на локальный канал path (Unix socket на Linux,
+// named pipe на Windows) вместо TCP адреса. Токен в канале не проверяется:
+// доступ ограничен правами ОС. Вызывается до Start
+func (s *Server) SetSocket(path string) {
+	s.socketPath = path
+}
+
+// GetAddress возвращает адрес сервера (полезно для тестов)
+func (s *Server) GetAddress() string {
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return s.listen
+}
+
+// Start запускает gRPC сервер
+func (s *Server) Start(ctx context.Context) error {
+	if !s.enabled {
+		s.log.Info("gRPC control server is disabled")
+		return nil
+	}
+
+	var listener net.Listener
+	var err error
+	if s.socketPath != "" {
+		listener, err = localsock.Listen(s.socketPath)
+		if err != nil {
+			return apperr.Listen("gRPC control socket", s.socketPath, err)
+		}
+	} else if listener, err = net.Listen("tcp", s.listen); err != nil {
+		return apperr.Listen("gRPC control server", s.listen, err)
+	}
+	s.listener = listener
+
+	s.log.Info("Starting gRPC control server", map[string]interface{}{
+		"listen": s.GetAddress(),
+		"auth":   s.token != "" && s.socketPath == "",
+	})
+
+	go func() {
+		if err := s.server.Serve(s.listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			s.log.Error("gRPC control server error", map[string]interface{}{"error": err.Error()})
+		}
+	}()
+
+	return nil
+}
+
+// Stop останавливает gRPC сервер, дожидаясь завершения активных вызовов
+// не дольше, чем позволяет ctx
+func (s *Server) Stop(ctx context.Context) error {
+	if !s.enabled || s.server == nil {
+		return nil
+	}
+
+	s.log.Info("Stopping gRPC control server")
+
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
+
+// authenticate проверяет токен в метаданных authorization, если он задан.
+// В локальном канале доступ проверяет ОС, токен не требуется
+func (s *Server) authenticate(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if s.token == "" || s.socketPath != "" {
+		return handler(ctx, req)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) != 1 || subtle.ConstantTimeCompare([]byte(values[0]), []byte("Bearer "+s.token)) != 1 {
+		s.log.Warn("gRPC control unauthorized call", map[string]interface{}{"method": info.FullMethod})
+		s.record(ctx, audit.ActionUnauthorized, info.FullMethod, nil, nil)
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	return handler(ctx, req)
+}
+
+// ListTimers возвращает состояние всех таймеров
+func (s *Server) ListTimers(ctx context.Context, req *controlpb.ListTimersRequest) (*controlpb.ListTimersResponse, error) {
+	infos := s.scheduler.ListTimers()
+	resp := &controlpb.ListTimersResponse{Timers: make([]*controlpb.Timer, 0, len(infos))}
+	for _, info := range infos {
+		resp.Timers = append(resp.Timers, &controlpb.Timer{
+			Name:       info.Name,
+			Interval:   durationpb.New(info.Interval),
+			LastRun:    timestamp(info.LastRun),
+			NextRun:    timestamp(info.NextRun),
+			PanicCount: int32(info.PanicCount),
+			State:      info.State,
+		})
+	}
+	return resp, nil
+}
+
+// TriggerTimer немедленно выполняет таймер
+func (s *Server) TriggerTimer(ctx context.Context, req *controlpb.TriggerTimerRequest) (*controlpb.TriggerTimerResponse, error) {
+	start := time.Now()
+	err := s.scheduler.Trigger(req.GetName())
+	duration := time.Since(start)
+	resp := &controlpb.TriggerTimerResponse{
+		Timer:    req.GetName(),
+		Ok:       true,
+		Duration: durationpb.New(duration),
+	}
+	s.record(ctx, audit.ActionTriggerTimer, req.GetName(), err, map[string]interface{}{"duration_ms": duration.Milliseconds()})
+
+	switch {
+	case errors.Is(err, scheduler.ErrTimerNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, scheduler.ErrNotRunning):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, scheduler.ErrReadOnly):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, scheduler.ErrNamespaceDisabled):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		resp.Ok = false
+		resp.Error = err.Error()
+	}
+
+	s.log.Info("Control action: trigger timer", map[string]interface{}{
+		"timer": req.GetName(),
+		"ok":    resp.Ok,
+	})
+	return resp, nil
+}
+
+// SetLogLevel меняет уровень логирования
+func (s *Server) SetLogLevel(ctx context.Context, req *controlpb.SetLogLevelRequest) (*controlpb.SetLogLevelResponse, error) {
+	level, err := logger.ParseLevel(req.GetLevel())
+	if err != nil || req.GetLevel() == "" {
+		err := status.Errorf(codes.InvalidArgument, "invalid log level: %q", req.GetLevel())
+		s.record(ctx, audit.ActionSetLogLevel, req.GetLevel(), err, nil)
+		return nil, err
+	}
+
+	previous := s.log.GetLevel()
+	s.log.SetLevel(level)
+	s.record(ctx, audit.ActionSetLogLevel, level.String(), nil, map[string]interface{}{"previous": previous.String()})
+	s.log.Info("Control action: set log level", map[string]interface{}{
+		"previous": previous.String(),
+		"level":    level.String(),
+	})
+	if s.events != nil {
+		s.events.Publish(events.TypeLogLevel, map[string]interface{}{
+			"previous": previous.String(),
+			"level":    level.String(),
+			"source":   "grpc",
+		})
+	}
+	return &controlpb.SetLogLevelResponse{
+		PreviousLevel: previous.String(),
+		Level:         level.String(),
+	}, nil
+}
+
+// GetStatus возвращает идентичность и общее состояние экземпляра
+func (s *Server) GetStatus(ctx context.Context, req *controlpb.GetStatusRequest) (*controlpb.GetStatusResponse, error) {
+	info := buildinfo.Get()
+	return &controlpb.GetStatusResponse{
+		Service:      s.identity.Service,
+		InstanceId:   s.identity.InstanceID,
+		Version:      info.Version,
+		Commit:       info.Commit,
+		StartedAt:    timestamppb.New(s.startedAt),
+		TimerCount:   int32(s.scheduler.GetTimerCount()),
+		ActiveTimers: s.scheduler.GetActiveTimerCount(),
+		LogLevel:     s.log.GetLevel().String(),
+	}, nil
+}
+
+// record записывает действие оператора в журнал действий. Имя оператора
+// берется из метаданных x-audit-actor
+func (s *Server) record(ctx context.Context, action, target string, err error, details map[string]interface{}) {
+	if s.audit == nil {
+		return
+	}
+	entry := audit.Entry{
+		Actor:   "anonymous",
+		Source:  audit.SourceGRPC,
+		Action:  action,
+		Target:  target,
+		Result:  audit.Result(err),
+		Error:   audit.ErrorText(err),
+		Details: details,
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(audit.ActorHeader); len(values) > 0 && audit.Actor(values[0]) != "" {
+			entry.Actor = audit.Actor(values[0])
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		entry.Remote = p.Addr.String()
+	}
+	if action == audit.ActionUnauthorized {
+		entry.Result = audit.ResultDenied
+	}
+	if err := s.audit.Record(entry); err != nil {
+		s.log.Warn("Failed to write audit record", map[string]interface{}{
+			"action": action,
+			"error":  err.Error(),
+		})
+	}
+}
+
+// timestamp возвращает nil для нулевого времени
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
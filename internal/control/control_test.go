@@ -0,0 +1,195 @@
+package control
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"service-boilerplate/internal/appctx"
+	"service-boilerplate/internal/config"
+	"service-boilerplate/internal/control/controlpb"
+	"service-boilerplate/internal/localsock"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+	"service-boilerplate/internal/scheduler"
+)
+
+// setupTestControl запускает gRPC сервер на свободном порту и возвращает клиент
+func setupTestControl(t *testing.T, token string) (controlpb.ControlClient, *logger.Logger, func()) {
+	log, err := logger.New("test-control", t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	sched := scheduler.New(log, metrics.New(log, false, ""), 3, 0)
+	sched.AddTimer("ok-timer", time.Hour, func(ctx context.Context) {})
+	sched.AddTimer("panic-timer", time.Hour, func(ctx context.Context) { panic("boom") })
+	sched.AddTimer("tenant/report", time.Hour, func(ctx context.Context) {})
+	sched.SetNamespaces(map[string]scheduler.NamespaceOptions{"tenant": {Disabled: true}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := sched.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	cfg := &config.Config{GRPC: config.GRPCConfig{Enabled: true, Listen: "127.0.0.1:0", Token: token}}
+	srv := New(log, sched, cfg, appctx.Identity{Service: "svc", InstanceID: "abc"})
+	if err := srv.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	conn, err := grpc.NewClient(srv.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+
+	cleanup := func() {
+		conn.Close()
+		srv.Stop(context.Background())
+		cancel()
+		sched.Stop(context.Background())
+		log.Close()
+	}
+	return controlpb.NewControlClient(conn), log, cleanup
+}
+
+// TestListAndTrigger проверяет список таймеров и ручной запуск
+func TestListAndTrigger(t *testing.T) {
+	client, _, cleanup := setupTestControl(t, "")
+	defer cleanup()
+	ctx := context.Background()
+
+	resp, err := client.TriggerTimer(ctx, &controlpb.TriggerTimerRequest{Name: "ok-timer"})
+	if err != nil || !resp.GetOk() {
+		t.Fatalf("TriggerTimer() = %v, %v", resp, err)
+	}
+
+	resp, err = client.TriggerTimer(ctx, &controlpb.TriggerTimerRequest{Name: "panic-timer"})
+	if err != nil || resp.GetOk() || resp.GetError() == "" {
+		t.Errorf("TriggerTimer(panic-timer) = %v, %v", resp, err)
+	}
+
+	_, err = client.TriggerTimer(ctx, &controlpb.TriggerTimerRequest{Name: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("TriggerTimer(missing) code = %v, want NotFound", status.Code(err))
+	}
+
+	_, err = client.TriggerTimer(ctx, &controlpb.TriggerTimerRequest{Name: "tenant/report"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("TriggerTimer(disabled namespace) code = %v, want PermissionDenied", status.Code(err))
+	}
+
+	list, err := client.ListTimers(ctx, &controlpb.ListTimersRequest{})
+	if err != nil {
+		t.Fatalf("ListTimers() error = %v", err)
+	}
+	if len(list.GetTimers()) != 3 {
+		t.Fatalf("len(timers) = %d, want 3", len(list.GetTimers()))
+	}
+	ok := list.GetTimers()[0]
+	if ok.GetName() != "ok-timer" || ok.GetInterval().AsDuration() != time.Hour || ok.GetLastRun() == nil {
+		t.Errorf("timers[0] = %v", ok)
+	}
+}
+
+// TestSetLogLevelAndStatus проверяет смену уровня логирования и статус
+func TestSetLogLevelAndStatus(t *testing.T) {
+	client, log, cleanup := setupTestControl(t, "")
+	defer cleanup()
+	ctx := context.Background()
+
+	resp, err := client.SetLogLevel(ctx, &controlpb.SetLogLevelRequest{Level: "debug"})
+	if err != nil {
+		t.Fatalf("SetLogLevel() error = %v", err)
+	}
+	if resp.GetPreviousLevel() != "info" || log.GetLevel() != logger.DebugLevel {
+		t.Errorf("SetLogLevel() = %v, level = %s", resp, log.GetLevel())
+	}
+
+	_, err = client.SetLogLevel(ctx, &controlpb.SetLogLevelRequest{Level: "verbose"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("SetLogLevel(verbose) code = %v, want InvalidArgument", status.Code(err))
+	}
+
+	st, err := client.GetStatus(ctx, &controlpb.GetStatusRequest{})
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if st.GetService() != "svc" || st.GetInstanceId() != "abc" || st.GetTimerCount() != 3 || st.GetLogLevel() != "debug" {
+		t.Errorf("GetStatus() = %v", st)
+	}
+}
+
+// TestAuth проверяет авторизацию по токену
+func TestAuth(t *testing.T) {
+	client, _, cleanup := setupTestControl(t, "secret")
+	defer cleanup()
+
+	_, err := client.GetStatus(context.Background(), &controlpb.GetStatusRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("GetStatus() without token code = %v, want Unauthenticated", status.Code(err))
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	if _, err := client.GetStatus(ctx, &controlpb.GetStatusRequest{}); err != nil {
+		t.Errorf("GetStatus() with token error = %v", err)
+	}
+}
+
+// TestSocket проверяет вызовы через локальный канал вместо TCP: токен
+// в канале не требуется
+func TestSocket(t *testing.T) {
+	log, err := logger.New("test-control", t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	defer log.Close()
+
+	sched := scheduler.New(log, nil, 3, 0)
+	sched.AddTimer("ok-timer", time.Hour, func(ctx context.Context) {})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sched.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer sched.Stop(context.Background())
+
+	cfg := &config.Config{GRPC: config.GRPCConfig{Enabled: true, Token: "secret", Socket: true}}
+	srv := New(log, sched, cfg, appctx.Identity{Service: "svc"})
+	path := filepath.Join(t.TempDir(), "grpc.sock")
+	if runtime.GOOS == "windows" {
+		path = localsock.DefaultPath("control-test-" + filepath.Base(t.TempDir()))
+	}
+	srv.SetSocket(path)
+	if err := srv.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer srv.Stop(context.Background())
+
+	conn, err := grpc.NewClient("passthrough:///control",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return localsock.Dial(ctx, path)
+		}))
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	defer conn.Close()
+
+	resp, err := controlpb.NewControlClient(conn).ListTimers(ctx, &controlpb.ListTimersRequest{})
+	if err != nil {
+		t.Fatalf("ListTimers() over socket error = %v", err)
+	}
+	if len(resp.GetTimers()) != 1 || resp.GetTimers()[0].GetName() != "ok-timer" {
+		t.Errorf("ListTimers() = %v", resp.GetTimers())
+	}
+}
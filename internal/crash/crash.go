@@ -0,0 +1,235 @@
+// Package crash записывает отчет о падении сервиса (Fatal или неперехваченный
+// panic) в директорию crashes: время, версия, сводка конфигурации, стеки
+// всех горутин и последние записи лога для разбора после перезапуска
+package crash
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"service-boilerplate/internal/logger"
+)
+
+// recentLines сколько последних строк лога включается в отчет
+const recentLines = 100
+
+// tailBytes сколько байт с конца файла лога читается для последних строк
+const tailBytes = 256 * 1024
+
+// runtimeCrashFile файл, в который рантайм Go пишет трассировку
+// неперехваченного panic в любой горутине
+const runtimeCrashFile = "runtime-crash.log"
+
+// Info сведения о сервисе для отчета
+type Info struct {
+	Service    string
+	Version    string
+	InstanceID string
+	// Config сводка конфигурации (без секретов)
+	Config map[string]interface{}
+	// Recent буфер последних записей лога. Если не задан, последние
+	// записи читаются из файла LogFile
+	Recent *logger.Ring
+	// LogFile файл лога, из которого берутся последние записи
+	LogFile string
+}
+
+// Reporter записывает отчеты о падении
+type Reporter struct {
+	dir  string
+	info Info
+	now  func() time.Time
+}
+
+// New создает Reporter, пишущий отчеты в dir
+func New(dir string, info Info) *Reporter {
+	return &Reporter{dir: dir, info: info, now: time.Now}
+}
+
+// Dir возвращает директорию отчетов
+func (r *Reporter) Dir() string {
+	return r.dir
+}
+
+// Write записывает отчет с причиной reason и стеком stack (может быть пустым)
+// и возвращает путь к файлу отчета
+func (r *Reporter) Write(reason string, stack []byte) (string, error) {
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create crash directory: %w", err)
+	}
+
+	now := r.now()
+	path := filepath.Join(r.dir, fmt.Sprintf("crash-%s-%d.txt", now.Format("20060102-150405"), os.Getpid()))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to create crash report: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	r.report(w, now, reason, stack)
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("failed to write crash report: %w", err)
+	}
+	return path, f.Sync()
+}
+
+// report формирует текст отчета
+func (r *Reporter) report(w io.Writer, now time.Time, reason string, stack []byte) {
+	fmt.Fprintf(w, "Crash report\n\n")
+	fmt.Fprintf(w, "Time:        %s\n", now.UTC().Format(time.RFC3339Nano))
+	fmt.Fprintf(w, "Service:     %s\n", r.info.Service)
+	fmt.Fprintf(w, "Version:     %s\n", r.info.Version)
+	fmt.Fprintf(w, "Instance ID: %s\n", r.info.InstanceID)
+	fmt.Fprintf(w, "PID:         %d\n", os.Getpid())
+	fmt.Fprintf(w, "Go:          %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(w, "Reason:      %s\n", reason)
+
+	if len(stack) > 0 {
+		section(w, "Stack")
+		w.Write(stack)
+	}
+
+	section(w, "Config")
+	if r.info.Config != nil {
+		data, err := yaml.Marshal(r.info.Config)
+		if err != nil {
+			fmt.Fprintf(w, "failed to encode config: %v\n", err)
+		} else {
+			w.Write(data)
+		}
+	}
+
+	section(w, "Recent log")
+	for _, line := range r.recentLog() {
+		fmt.Fprintln(w, line)
+	}
+
+	section(w, "Goroutines")
+	w.Write(allStacks())
+	fmt.Fprintln(w)
+}
+
+// Recover перехватывает panic, записывает отчет и продолжает panic.
+// Вызывается через defer в начале горутины
+func (r *Reporter) Recover() {
+	v := recover()
+	if v == nil {
+		return
+	}
+	if path, err := r.Write(fmt.Sprintf("panic: %v", v), debug.Stack()); err == nil {
+		fmt.Fprintf(os.Stderr, "crash report written to %s\n", path)
+	}
+	panic(v)
+}
+
+// CaptureRuntimeCrashes направляет трассировку неперехваченных panic
+// и фатальных ошибок рантайма из любой горутины в файл runtime-crash.log
+// директории отчетов: Recover перехватывает panic только в своей горутине
+func (r *Reporter) CaptureRuntimeCrashes() error {
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create crash directory: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(r.dir, runtimeCrashFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open runtime crash file: %w", err)
+	}
+	defer f.Close()
+	// SetCrashOutput дублирует дескриптор, поэтому файл можно закрыть
+	return debug.SetCrashOutput(f, debug.CrashOptions{})
+}
+
+// FatalHook возвращает функцию для logger.SetFatalHook
+func (r *Reporter) FatalHook() func(msg string, fields map[string]interface{}) {
+	return func(msg string, fields map[string]interface{}) {
+		reason := "fatal: " + msg
+		if len(fields) > 0 {
+			keys := make([]string, 0, len(fields))
+			for k := range fields {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			parts := make([]string, 0, len(keys))
+			for _, k := range keys {
+				parts = append(parts, fmt.Sprintf("%s=%v", k, fields[k]))
+			}
+			reason += " (" + strings.Join(parts, " ") + ")"
+		}
+		if path, err := r.Write(reason, nil); err == nil {
+			fmt.Fprintf(os.Stderr, "crash report written to %s\n", path)
+		}
+	}
+}
+
+// recentLog возвращает последние записи лога из буфера или файла
+func (r *Reporter) recentLog() []string {
+	if r.info.Recent != nil {
+		var lines []string
+		for _, e := range r.info.Recent.Entries(logger.DebugLevel, recentLines) {
+			data, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			lines = append(lines, string(data))
+		}
+		return lines
+	}
+	if r.info.LogFile == "" {
+		return nil
+	}
+	f, err := os.Open(r.info.LogFile)
+	if err != nil {
+		return []string{fmt.Sprintf("failed to read log file: %v", err)}
+	}
+	defer f.Close()
+
+	var offset int64
+	if info, err := f.Stat(); err == nil && info.Size() > tailBytes {
+		offset = info.Size() - tailBytes
+	}
+	data, err := io.ReadAll(io.NewSectionReader(f, offset, tailBytes))
+	if err != nil {
+		return []string{fmt.Sprintf("failed to read log file: %v", err)}
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	// Первая строка после смещения может быть обрезана
+	if offset > 0 && len(lines) > 0 {
+		lines = lines[1:]
+	}
+	if len(lines) > recentLines {
+		lines = lines[len(lines)-recentLines:]
+	}
+	return lines
+}
+
+// section выводит заголовок раздела отчета
+func section(w io.Writer, title string) {
+	fmt.Fprintf(w, "\n== %s ==\n\n", title)
+}
+
+// allStacks возвращает стеки всех горутин
+func allStacks() []byte {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return bytes.TrimRight(buf[:n], "\n")
+		}
+		if len(buf) >= 64<<20 {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,115 @@
+package crash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"service-boilerplate/internal/logger"
+)
+
+// TestWrite проверяет содержимое отчета
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "svc.log")
+	var log strings.Builder
+	for i := 0; i < 150; i++ {
+		fmt.Fprintf(&log, "{\"message\":\"line %d\"}\n", i)
+	}
+	if err := os.WriteFile(logFile, []byte(log.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(filepath.Join(dir, "crashes"), Info{
+		Service: "svc",
+		Version: "1.2.3",
+		Config:  map[string]interface{}{"admin": map[string]interface{}{"token": "***"}},
+		LogFile: logFile,
+	})
+	path, err := r.Write("panic: boom", []byte("goroutine 1 [running]:\nmain.main()"))
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := string(data)
+
+	for _, want := range []string{
+		"Service:     svc",
+		"Version:     1.2.3",
+		"Reason:      panic: boom",
+		"== Stack ==\n\ngoroutine 1 [running]:\nmain.main()",
+		"token: '***'",
+		`{"message":"line 149"}`,
+		"== Goroutines ==",
+		"crash.TestWrite",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report does not contain %q", want)
+		}
+	}
+	// В отчет попадают только последние recentLines строк лога
+	if strings.Contains(report, `"line 49"`) || !strings.Contains(report, `"line 50"`) {
+		t.Error("report contains unexpected number of recent log lines")
+	}
+}
+
+// TestWrite_Ring проверяет последние записи лога из буфера в памяти
+func TestWrite_Ring(t *testing.T) {
+	ring := logger.NewRing(10)
+	ring.Add(logger.ErrorLevel, logger.LogEntry{Level: "ERROR", Message: "from ring"})
+
+	r := New(t.TempDir(), Info{Service: "svc", Recent: ring, LogFile: "missing.log"})
+	path, err := r.Write("panic: boom", nil)
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"message":"from ring"`) || strings.Contains(string(data), "failed to read log file") {
+		t.Errorf("report = %s", data)
+	}
+}
+
+// TestRecover проверяет запись отчета при panic и продолжение panic
+func TestRecover(t *testing.T) {
+	dir := t.TempDir()
+	r := New(dir, Info{Service: "svc"})
+
+	func() {
+		defer func() {
+			if v := recover(); v != "boom" {
+				t.Errorf("recover() = %v, want panic to continue", v)
+			}
+		}()
+		defer r.Recover()
+		panic("boom")
+	}()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "crash-*.txt"))
+	if len(files) != 1 {
+		t.Fatalf("crash reports = %v, want 1", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(data), "Reason:      panic: boom") {
+		t.Errorf("report = %s", data)
+	}
+}
+
+// TestFatalHook проверяет причину отчета при Fatal
+func TestFatalHook(t *testing.T) {
+	dir := t.TempDir()
+	New(dir, Info{}).FatalHook()("Failed to start", map[string]interface{}{"port": 80, "error": "denied"})
+
+	files, _ := filepath.Glob(filepath.Join(dir, "crash-*.txt"))
+	if len(files) != 1 {
+		t.Fatalf("crash reports = %v, want 1", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(data), "Reason:      fatal: Failed to start (error=denied port=80)") {
+		t.Errorf("report = %s", data)
+	}
+}
@@ -0,0 +1,291 @@
+// Package db управляет пулом соединений database/sql: настройка из
+// конфигурации, проверка доступности при запуске, health check и метрики пула.
+// Драйвер подключается в приложении импортом (например, _ "github.com/lib/pq")
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+	"service-boilerplate/internal/task"
+)
+
+// Config содержит настройки пула. DSN берется из переменной окружения
+// DSNEnv или файла DSNFile, если они заданы, иначе из DSN
+type Config struct {
+	Driver          string
+	DSN             string
+	DSNEnv          string
+	DSNFile         string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	PingTimeout     time.Duration
+}
+
+// statsInterval период обновления метрик пула
+const statsInterval = 15 * time.Second
+
+// ErrNotOpen пул еще не открыт или уже закрыт
+var ErrNotOpen = errors.New("database is not open")
+
+// DB пул соединений с базой данных. Реализует task.Task и task.Checker:
+// открывается в AfterStart, закрывается в BeforeStop
+type DB struct {
+	log     *logger.Logger
+	metrics *metrics.Server
+	cfg     Config
+
+	mu     sync.RWMutex
+	db     *sql.DB
+	conn   *connector
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New создает пул с настройками cfg. Соединение открывается при запуске
+func New(log *logger.Logger, metricsServer *metrics.Server, cfg Config) *DB {
+	if cfg.PingTimeout <= 0 {
+		cfg.PingTimeout = 5 * time.Second
+	}
+	return &DB{log: log, metrics: metricsServer, cfg: cfg}
+}
+
+// DB возвращает *sql.DB или nil, если пул не открыт
+func (d *DB) DB() *sql.DB {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.db
+}
+
+// Name возвращает имя задачи
+func (d *DB) Name() string {
+	return "db"
+}
+
+// ReadOnly задача запускается в режиме только для чтения: пул соединений нужен проверкам здоровья и задачам только для чтения
+func (d *DB) ReadOnly() bool {
+	return true
+}
+
+// ShutdownPhase закрывает пул после задач, которые им пользуются
+func (d *DB) ShutdownPhase() task.Phase {
+	return task.PhaseRelease
+}
+
+// Check проверяет, что DSN задан и база данных доступна
+func (d *DB) Check(ctx context.Context) error {
+	db, _, err := d.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return d.ping(ctx, db)
+}
+
+// AfterStart открывает пул и проверяет соединение
+func (d *DB) AfterStart(ctx context.Context) error {
+	db, conn, err := d.open()
+	if err != nil {
+		return err
+	}
+	if err := d.ping(ctx, db); err != nil {
+		db.Close()
+		return err
+	}
+
+	statsCtx, cancel := context.WithCancel(ctx)
+	d.mu.Lock()
+	d.db = db
+	d.conn = conn
+	d.cancel = cancel
+	d.done = make(chan struct{})
+	d.mu.Unlock()
+	go d.collectStats(statsCtx, db)
+
+	d.log.Info("Database connected", map[string]interface{}{
+		"driver":         d.cfg.Driver,
+		"max_open_conns": d.cfg.MaxOpenConns,
+		"max_idle_conns": d.cfg.MaxIdleConns,
+	})
+	return nil
+}
+
+// BeforeStop закрывает пул
+func (d *DB) BeforeStop(ctx context.Context) error {
+	d.mu.Lock()
+	db, cancel, done := d.db, d.cancel, d.done
+	d.db = nil
+	d.mu.Unlock()
+	if db == nil {
+		return nil
+	}
+
+	cancel()
+	<-done
+	d.log.Info("Closing database")
+	return db.Close()
+}
+
+// Ping проверяет соединение. Используется как health check
+func (d *DB) Ping(ctx context.Context) error {
+	db := d.DB()
+	if db == nil {
+		return ErrNotOpen
+	}
+	return d.ping(ctx, db)
+}
+
+// Rotate перечитывает DSN (файл DSNFile или переменную DSNEnv) и, если
+// он изменился, проверяет соединение с новыми учетными данными и переводит
+// на них пул. *sql.DB остается прежним: новые соединения открываются с новым
+// DSN, простаивающие закрываются, а занятые в момент смены возвращаются
+// в пул и живут не дольше ConnMaxLifetime
+func (d *DB) Rotate(ctx context.Context) error {
+	d.mu.RLock()
+	db, conn := d.db, d.conn
+	d.mu.RUnlock()
+	if db == nil {
+		return ErrNotOpen
+	}
+	dsn, err := d.dsn()
+	if err != nil {
+		return err
+	}
+	if dsn == conn.current() {
+		return nil
+	}
+
+	probe := sql.OpenDB(&connector{driver: conn.driver, dsn: dsn})
+	defer probe.Close()
+	if err := d.ping(ctx, probe); err != nil {
+		return err
+	}
+	conn.set(dsn)
+	db.SetMaxIdleConns(0)
+	db.SetMaxIdleConns(d.cfg.MaxIdleConns)
+	d.log.Info("Database credentials rotated", map[string]interface{}{"driver": d.cfg.Driver})
+	return nil
+}
+
+// open создает пул с настройками из конфигурации. Соединения открываются
+// через connector, чтобы Rotate мог сменить DSN без пересоздания пула
+func (d *DB) open() (*sql.DB, *connector, error) {
+	dsn, err := d.dsn()
+	if err != nil {
+		return nil, nil, err
+	}
+	named, err := sql.Open(d.cfg.Driver, dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	conn := &connector{driver: named.Driver(), dsn: dsn}
+	named.Close()
+	db := sql.OpenDB(conn)
+	db.SetMaxOpenConns(d.cfg.MaxOpenConns)
+	db.SetMaxIdleConns(d.cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(d.cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(d.cfg.ConnMaxIdleTime)
+	return db, conn, nil
+}
+
+// ping проверяет соединение с таймаутом PingTimeout
+func (d *DB) ping(ctx context.Context, db *sql.DB) error {
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.PingTimeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// dsn возвращает строку подключения из окружения, файла или конфигурации
+func (d *DB) dsn() (string, error) {
+	switch {
+	case d.cfg.DSNEnv != "":
+		dsn := os.Getenv(d.cfg.DSNEnv)
+		if dsn == "" {
+			return "", fmt.Errorf("environment variable %s is empty", d.cfg.DSNEnv)
+		}
+		return dsn, nil
+	case d.cfg.DSNFile != "":
+		data, err := os.ReadFile(d.cfg.DSNFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read DSN file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	case d.cfg.DSN != "":
+		return d.cfg.DSN, nil
+	default:
+		return "", errors.New("database DSN is not configured")
+	}
+}
+
+// connector открывает соединения драйвера с текущим DSN
+type connector struct {
+	driver driver.Driver
+
+	mu  sync.RWMutex
+	dsn string
+}
+
+// Connect открывает соединение с текущим DSN
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dsn := c.current()
+	if dc, ok := c.driver.(driver.DriverContext); ok {
+		conn, err := dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+		return conn.Connect(ctx)
+	}
+	return c.driver.Open(dsn)
+}
+
+// Driver возвращает драйвер соединений
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// current возвращает текущий DSN
+func (c *connector) current() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.dsn
+}
+
+// set заменяет DSN новых соединений
+func (c *connector) set(dsn string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dsn = dsn
+}
+
+// collectStats периодически обновляет метрики пула
+func (d *DB) collectStats(ctx context.Context, db *sql.DB) {
+	defer close(d.done)
+	if d.metrics == nil {
+		return
+	}
+
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+	for {
+		d.metrics.SetDBPoolStats(db.Stats())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+)
+
+// fakeDown переключает тестовый драйвер в режим недоступной базы
+var fakeDown int32
+
+// fakeLastDSN DSN последнего открытого тестовым драйвером соединения
+var fakeLastDSN atomic.Value
+
+// fakeDriver тестовый драйвер, поддерживающий только Ping
+type fakeDriver struct{}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	if !strings.HasPrefix(dsn, "fake://ok") {
+		return nil, errors.New("bad dsn")
+	}
+	fakeLastDSN.Store(dsn)
+	return fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+func (fakeConn) Ping(ctx context.Context) error {
+	if atomic.LoadInt32(&fakeDown) == 1 {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func init() {
+	sql.Register("fake", fakeDriver{})
+}
+
+// setupTestDB создает пул с тестовым драйвером
+func setupTestDB(t *testing.T, cfg Config) (*DB, *logger.Logger) {
+	log, err := logger.New("test-db", t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	cfg.Driver = "fake"
+	return New(log, metrics.New(log, true, "127.0.0.1:0"), cfg), log
+}
+
+// TestLifecycle проверяет открытие пула, Ping и закрытие
+func TestLifecycle(t *testing.T) {
+	d, log := setupTestDB(t, Config{DSN: "fake://ok", MaxOpenConns: 2})
+	defer log.Close()
+
+	if err := d.Ping(context.Background()); !errors.Is(err, ErrNotOpen) {
+		t.Errorf("Ping() before start error = %v, want ErrNotOpen", err)
+	}
+	if err := d.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+	if d.DB() == nil || d.DB().Stats().MaxOpenConnections != 2 {
+		t.Errorf("DB() pool is not configured")
+	}
+
+	atomic.StoreInt32(&fakeDown, 1)
+	if err := d.Ping(context.Background()); err == nil {
+		t.Error("Ping() expected error for unavailable database")
+	}
+	atomic.StoreInt32(&fakeDown, 0)
+
+	if err := d.BeforeStop(context.Background()); err != nil {
+		t.Errorf("BeforeStop() error = %v", err)
+	}
+	if d.DB() != nil {
+		t.Error("DB() after stop is not nil")
+	}
+}
+
+// TestDSNSources проверяет получение DSN из окружения и файла
+func TestDSNSources(t *testing.T) {
+	t.Setenv("TEST_DB_DSN", "fake://ok")
+	dsnFile := filepath.Join(t.TempDir(), "dsn")
+	os.WriteFile(dsnFile, []byte("fake://ok\n"), 0600)
+
+	for _, cfg := range []Config{
+		{DSN: "fake://wrong", DSNEnv: "TEST_DB_DSN"},
+		{DSNFile: dsnFile},
+	} {
+		d, log := setupTestDB(t, cfg)
+		if err := d.Check(context.Background()); err != nil {
+			t.Errorf("Check(%+v) error = %v", cfg, err)
+		}
+		log.Close()
+	}
+
+	d, log := setupTestDB(t, Config{DSNEnv: "TEST_DB_MISSING"})
+	defer log.Close()
+	if err := d.AfterStart(context.Background()); err == nil {
+		t.Error("AfterStart() expected error for empty environment variable")
+	}
+}
+
+// TestRotate проверяет переход пула на новый DSN из файла
+func TestRotate(t *testing.T) {
+	dsnFile := filepath.Join(t.TempDir(), "dsn")
+	os.WriteFile(dsnFile, []byte("fake://ok\n"), 0600)
+	d, log := setupTestDB(t, Config{DSNFile: dsnFile, MaxIdleConns: 2})
+	defer log.Close()
+
+	if err := d.Rotate(context.Background()); !errors.Is(err, ErrNotOpen) {
+		t.Errorf("Rotate() before start error = %v, want ErrNotOpen", err)
+	}
+	if err := d.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+	defer d.BeforeStop(context.Background())
+	pool := d.DB()
+
+	os.WriteFile(dsnFile, []byte("fake://wrong\n"), 0600)
+	if err := d.Rotate(context.Background()); err == nil {
+		t.Error("Rotate() expected error for invalid DSN")
+	}
+
+	os.WriteFile(dsnFile, []byte("fake://ok?user=rotated\n"), 0600)
+	if err := d.Rotate(context.Background()); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if d.DB() != pool {
+		t.Error("Rotate() replaced *sql.DB")
+	}
+	conn, err := pool.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("Conn() error = %v", err)
+	}
+	conn.Close()
+	if got := fakeLastDSN.Load(); got != "fake://ok?user=rotated" {
+		t.Errorf("new connection DSN = %v, want rotated DSN", got)
+	}
+}
@@ -0,0 +1,197 @@
+// Package election выбирает лидера среди экземпляров сервиса в схеме
+// active/passive: только лидер выполняет таймеры и привязанные задачи,
+// резервный экземпляр остается запущенным и ждет освобождения блокировки
+package election
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+	"service-boilerplate/internal/task"
+)
+
+// Lock межпроцессная блокировка, которой владеет лидер
+type Lock interface {
+	// TryLock пытается захватить блокировку без ожидания.
+	// Возвращает false, если блокировка занята другим экземпляром
+	TryLock() (bool, error)
+	// Unlock освобождает блокировку
+	Unlock() error
+}
+
+// Election периодически пытается захватить блокировку и управляет
+// привязанными задачами. Реализует task.Task
+type Election struct {
+	log      *logger.Logger
+	metrics  *metrics.Server
+	lock     Lock
+	interval time.Duration
+
+	leader int32
+
+	mu      sync.Mutex
+	bound   []task.Task
+	started []task.Task
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// New создает выборы лидера. interval - период попыток захвата блокировки резервным экземпляром
+func New(log *logger.Logger, metricsServer *metrics.Server, lock Lock, interval time.Duration) *Election {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	return &Election{
+		log:      log,
+		metrics:  metricsServer,
+		lock:     lock,
+		interval: interval,
+	}
+}
+
+// Bind привязывает задачу к лидерству: AfterStart вызывается при получении
+// лидерства, BeforeStop - при остановке. Привязанную задачу не нужно
+// регистрировать в lifecycle отдельно
+func (e *Election) Bind(t task.Task) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.bound = append(e.bound, t)
+}
+
+// IsLeader сообщает, является ли экземпляр лидером
+func (e *Election) IsLeader() bool {
+	return atomic.LoadInt32(&e.leader) == 1
+}
+
+// Guard оборачивает обработчик таймера: на резервном экземпляре вызов пропускается
+func (e *Election) Guard(handler func(ctx context.Context)) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		if e.IsLeader() {
+			handler(ctx)
+		}
+	}
+}
+
+// Name возвращает имя задачи
+func (e *Election) Name() string {
+	return "election"
+}
+
+// ShutdownPhase освобождает блокировку после остановки остальной работы,
+// чтобы резервный экземпляр не начал работу раньше
+func (e *Election) ShutdownPhase() task.Phase {
+	return task.PhaseRelease
+}
+
+// AfterStart делает первую попытку захвата и запускает цикл выборов
+func (e *Election) AfterStart(ctx context.Context) error {
+	e.ctx, e.cancel = context.WithCancel(ctx)
+	e.done = make(chan struct{})
+
+	e.campaign()
+	if !e.IsLeader() {
+		e.log.Info("Standing by, leadership is held by another instance", map[string]interface{}{
+			"retry_interval": e.interval.String(),
+		})
+	}
+
+	go e.loop()
+	return nil
+}
+
+// BeforeStop останавливает привязанные задачи и освобождает блокировку
+func (e *Election) BeforeStop(ctx context.Context) error {
+	if e.cancel == nil {
+		return nil
+	}
+	e.cancel()
+	<-e.done
+
+	if !e.IsLeader() {
+		return nil
+	}
+
+	var firstErr error
+	e.mu.Lock()
+	started := e.started
+	e.started = nil
+	e.mu.Unlock()
+	for i := len(started) - 1; i >= 0; i-- {
+		if err := started[i].BeforeStop(ctx); err != nil {
+			e.log.Error("Error stopping leader task", map[string]interface{}{
+				"task":  started[i].Name(),
+				"error": err.Error(),
+			})
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	atomic.StoreInt32(&e.leader, 0)
+	if e.metrics != nil {
+		e.metrics.SetLeader(false)
+	}
+	if err := e.lock.Unlock(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	e.log.Info("Leadership released")
+	return firstErr
+}
+
+// loop повторяет попытки захвата, пока экземпляр не станет лидером
+func (e *Election) loop() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+			if !e.IsLeader() {
+				e.campaign()
+			}
+		}
+	}
+}
+
+// campaign пытается захватить блокировку и при успехе запускает привязанные задачи
+func (e *Election) campaign() {
+	acquired, err := e.lock.TryLock()
+	if err != nil {
+		e.log.Warn("Leader election attempt failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	atomic.StoreInt32(&e.leader, 1)
+	if e.metrics != nil {
+		e.metrics.SetLeader(true)
+	}
+	e.log.Info("Elected as leader")
+
+	e.mu.Lock()
+	bound := append([]task.Task(nil), e.bound...)
+	e.mu.Unlock()
+	for _, t := range bound {
+		if err := t.AfterStart(e.ctx); err != nil {
+			e.log.Error("Error starting leader task", map[string]interface{}{
+				"task":  t.Name(),
+				"error": err.Error(),
+			})
+			continue
+		}
+		e.mu.Lock()
+		e.started = append(e.started, t)
+		e.mu.Unlock()
+	}
+}
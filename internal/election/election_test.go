@@ -0,0 +1,86 @@
+package election
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/logger"
+)
+
+// countingTask тестовая задача, считающая вызовы хуков
+type countingTask struct {
+	started int32
+	stopped int32
+}
+
+func (t *countingTask) Name() string { return "counting" }
+
+func (t *countingTask) AfterStart(ctx context.Context) error {
+	atomic.AddInt32(&t.started, 1)
+	return nil
+}
+
+func (t *countingTask) BeforeStop(ctx context.Context) error {
+	atomic.AddInt32(&t.stopped, 1)
+	return nil
+}
+
+// TestFailover проверяет переход лидерства к резервному экземпляру
+func TestFailover(t *testing.T) {
+	log, err := logger.New("test-election", t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	defer log.Close()
+
+	lockPath := filepath.Join(t.TempDir(), "leader.lock")
+	active := New(log, nil, NewFileLock(lockPath), 10*time.Millisecond)
+	standby := New(log, nil, NewFileLock(lockPath), 10*time.Millisecond)
+
+	activeTask, standbyTask := &countingTask{}, &countingTask{}
+	active.Bind(activeTask)
+	standby.Bind(standbyTask)
+
+	active.AfterStart(context.Background())
+	standby.AfterStart(context.Background())
+	defer standby.BeforeStop(context.Background())
+
+	if !active.IsLeader() || standby.IsLeader() {
+		t.Fatalf("IsLeader() active = %v, standby = %v", active.IsLeader(), standby.IsLeader())
+	}
+	if atomic.LoadInt32(&activeTask.started) != 1 || atomic.LoadInt32(&standbyTask.started) != 0 {
+		t.Errorf("bound task started: active = %d, standby = %d", activeTask.started, standbyTask.started)
+	}
+
+	var runs int32
+	guarded := standby.Guard(func(ctx context.Context) { atomic.AddInt32(&runs, 1) })
+	guarded(context.Background())
+	if atomic.LoadInt32(&runs) != 0 {
+		t.Error("Guard() ran handler on standby")
+	}
+
+	if err := active.BeforeStop(context.Background()); err != nil {
+		t.Fatalf("BeforeStop() error = %v", err)
+	}
+	if atomic.LoadInt32(&activeTask.stopped) != 1 {
+		t.Errorf("bound task stopped = %d, want 1", activeTask.stopped)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !standby.IsLeader() {
+		if time.Now().After(deadline) {
+			t.Fatal("standby did not take over leadership")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if atomic.LoadInt32(&standbyTask.started) != 1 {
+		t.Errorf("standby bound task started = %d, want 1", standbyTask.started)
+	}
+	guarded(context.Background())
+	if atomic.LoadInt32(&runs) != 1 {
+		t.Error("Guard() did not run handler on leader")
+	}
+}
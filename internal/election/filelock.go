@@ -0,0 +1,71 @@
+package election
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileLock блокировка на файле (flock в Linux, LockFileEx в Windows).
+// Для пары узлов файл должен лежать на общем диске, поддерживающем блокировки
+type FileLock struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileLock создает блокировку на файле path
+func NewFileLock(path string) *FileLock {
+	return &FileLock{path: path}
+}
+
+// TryLock захватывает блокировку и записывает в файл сведения о владельце
+func (l *FileLock) TryLock() (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file != nil {
+		return true, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return false, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return false, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	acquired, err := lockFile(f)
+	if err != nil || !acquired {
+		f.Close()
+		return false, err
+	}
+
+	// Сведения о владельце для диагностики, на блокировку не влияют
+	host, _ := os.Hostname()
+	f.Truncate(0)
+	fmt.Fprintf(f, "host=%s pid=%d since=%s\n", host, os.Getpid(), time.Now().UTC().Format(time.RFC3339))
+	f.Sync()
+
+	l.file = f
+	return true, nil
+}
+
+// Unlock освобождает блокировку
+func (l *FileLock) Unlock() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := unlockFile(l.file)
+	if cerr := l.file.Close(); err == nil {
+		err = cerr
+	}
+	l.file = nil
+	return err
+}
@@ -0,0 +1,129 @@
+// Package events предоставляет шину событий запущенного сервиса
+// (выполнение таймеров, изменение состояния проверок, уровень логирования)
+// для подписчиков вроде потока событий admin API и оповещений
+package events
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Типы событий
+const (
+	TypeTimerRun       = "timer.run"
+	TypeTimerDisabled  = "timer.disabled"
+	TypeHealthChanged  = "health.changed"
+	TypeHealthStatus   = "health.status"
+	TypeLogLevel       = "log.level"
+	TypeWatchdogFired  = "watchdog.fired"
+	TypeTimerThrottled = "timer.throttled"
+	TypeClockJumped    = "clock.jumped"
+	TypeConfigRollback = "config.rolled_back"
+	TypeSecretRotated  = "secret.rotated"
+)
+
+// Event событие шины. Data кодируется в JSON для подписчиков
+type Event struct {
+	ID   uint64      `json:"id"`
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// Subscription подписка на события. События, которые подписчик не успел
+// прочитать из заполненного буфера, отбрасываются
+type Subscription struct {
+	C <-chan Event
+
+	bus     *Bus
+	id      int
+	ch      chan Event
+	types   []string
+	dropped uint64
+}
+
+// Dropped возвращает количество отброшенных событий
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close отменяет подписку и закрывает канал C
+func (s *Subscription) Close() {
+	s.bus.unsubscribe(s.id)
+}
+
+// matches проверяет тип события по фильтру подписки. Фильтр "timer"
+// совпадает с "timer.run", пустой фильтр - со всеми событиями
+func (s *Subscription) matches(typ string) bool {
+	if len(s.types) == 0 {
+		return true
+	}
+	for _, t := range s.types {
+		if typ == t || strings.HasPrefix(typ, t+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Bus шина событий. Publish не блокируется медленными подписчиками
+type Bus struct {
+	seq uint64
+
+	mu   sync.RWMutex
+	subs map[int]*Subscription
+	next int
+}
+
+// New создает шину событий
+func New() *Bus {
+	return &Bus{subs: make(map[int]*Subscription)}
+}
+
+// Publish отправляет событие всем подходящим подписчикам
+func (b *Bus) Publish(typ string, data interface{}) {
+	ev := Event{
+		ID:   atomic.AddUint64(&b.seq, 1),
+		Type: typ,
+		Time: time.Now(),
+		Data: data,
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, sub := range b.subs {
+		if !sub.matches(typ) {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+			atomic.AddUint64(&sub.dropped, 1)
+		}
+	}
+}
+
+// Subscribe создает подписку с буфером buffer на события типов types
+// (или с префиксом типа, например "timer"). Без types подписка получает все события
+func (b *Bus) Subscribe(buffer int, types ...string) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Event, buffer)
+	b.next++
+	sub := &Subscription{C: ch, bus: b, id: b.next, ch: ch, types: types}
+	b.subs[sub.id] = sub
+	return sub
+}
+
+// unsubscribe удаляет подписку и закрывает ее канал
+func (b *Bus) unsubscribe(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if sub, ok := b.subs[id]; ok {
+		delete(b.subs, id)
+		close(sub.ch)
+	}
+}
@@ -0,0 +1,51 @@
+package events
+
+import (
+	"testing"
+)
+
+// TestPublishSubscribe проверяет доставку, фильтр по типу и отписку
+func TestPublishSubscribe(t *testing.T) {
+	bus := New()
+	all := bus.Subscribe(10)
+	timers := bus.Subscribe(10, "timer")
+
+	bus.Publish(TypeTimerRun, map[string]interface{}{"timer": "report"})
+	bus.Publish(TypeLogLevel, map[string]interface{}{"level": "debug"})
+
+	if ev := <-all.C; ev.Type != TypeTimerRun || ev.ID != 1 {
+		t.Errorf("first event = %+v", ev)
+	}
+	if ev := <-all.C; ev.Type != TypeLogLevel || ev.ID != 2 {
+		t.Errorf("second event = %+v", ev)
+	}
+	if ev := <-timers.C; ev.Type != TypeTimerRun {
+		t.Errorf("filtered event = %+v", ev)
+	}
+	select {
+	case ev := <-timers.C:
+		t.Errorf("unexpected event for timer subscription: %+v", ev)
+	default:
+	}
+
+	timers.Close()
+	if _, ok := <-timers.C; ok {
+		t.Error("channel is open after Close()")
+	}
+	bus.Publish(TypeTimerRun, nil)
+	all.Close()
+}
+
+// TestSlowSubscriber проверяет, что переполненный подписчик не блокирует Publish
+func TestSlowSubscriber(t *testing.T) {
+	bus := New()
+	sub := bus.Subscribe(1)
+	defer sub.Close()
+
+	for i := 0; i < 3; i++ {
+		bus.Publish(TypeTimerRun, nil)
+	}
+	if sub.Dropped() != 2 {
+		t.Errorf("Dropped() = %d, want 2", sub.Dropped())
+	}
+}
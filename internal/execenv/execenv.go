@@ -0,0 +1,88 @@
+// Package execenv окружение запуска обработчиков и дочерних процессов:
+// рабочая директория и переменные окружения поверх окружения сервиса.
+// Окружение процесса сервиса общее для всех горутин, поэтому обработчики
+// таймеров получают свое окружение через контекст и передают его
+// запускаемым командам, а не меняют os.Environ
+package execenv
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"sort"
+
+	"service-boilerplate/internal/runid"
+)
+
+// Env рабочая директория и дополнительные переменные окружения
+type Env struct {
+	// Dir рабочая директория, пустая - текущая директория сервиса
+	Dir string
+	// Vars переменные поверх окружения сервиса
+	Vars map[string]string
+}
+
+// envKey ключ контекста окружения
+type envKey struct{}
+
+// IsZero проверяет, что окружение не задано
+func (e Env) IsZero() bool {
+	return e.Dir == "" && len(e.Vars) == 0
+}
+
+// Pairs возвращает переменные в виде KEY=VALUE, отсортированные по имени
+func (e Env) Pairs() []string {
+	pairs := make([]string, 0, len(e.Vars))
+	for k, v := range e.Vars {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// Environ возвращает окружение сервиса с переменными Vars. При совпадении
+// имен exec.Cmd использует последнее значение, то есть значение из Vars
+func (e Env) Environ() []string {
+	return append(os.Environ(), e.Pairs()...)
+}
+
+// Apply задает команде рабочую директорию (если она еще не задана)
+// и добавляет переменные Vars к окружению команды
+func (e Env) Apply(cmd *exec.Cmd) {
+	if cmd.Dir == "" {
+		cmd.Dir = e.Dir
+	}
+	if len(e.Vars) == 0 {
+		return
+	}
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, e.Pairs()...)
+}
+
+// WithEnv возвращает контекст с окружением e
+func WithEnv(ctx context.Context, e Env) context.Context {
+	return context.WithValue(ctx, envKey{}, e)
+}
+
+// FromContext возвращает окружение из контекста или пустое окружение
+func FromContext(ctx context.Context) Env {
+	e, _ := ctx.Value(envKey{}).(Env)
+	return e
+}
+
+// Command создает команду с окружением из ctx (окружение таймера
+// в обработчике) и идентификатором запуска (runid.Environ).
+// Команда завершается при отмене ctx
+func Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	FromContext(ctx).Apply(cmd)
+	if vars := runid.Environ(ctx); len(vars) > 0 {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, vars...)
+	}
+	return cmd
+}
@@ -0,0 +1,43 @@
+package execenv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"service-boilerplate/internal/runid"
+)
+
+// TestCommand проверяет, что команда получает рабочую директорию
+// и переменные окружения из контекста
+func TestCommand(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("EXECENV_BASE", "service")
+	ctx := WithEnv(context.Background(), Env{Dir: dir, Vars: map[string]string{"EXECENV_JOB": "nightly", "EXECENV_BASE": "job"}})
+	ctx = runid.WithRun(ctx, runid.Run{ID: "r1", Timer: "report"})
+
+	cmd := Command(ctx, "sh", "-c", "pwd; echo $EXECENV_JOB $EXECENV_BASE $SERVICE_RUN_ID")
+	if runtime.GOOS == "windows" {
+		cmd = Command(ctx, "cmd", "/c", "cd & echo %EXECENV_JOB% %EXECENV_BASE% %SERVICE_RUN_ID%")
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	lines := strings.Fields(string(out))
+	want, _ := filepath.EvalSymlinks(dir)
+	got, _ := filepath.EvalSymlinks(lines[0])
+	if got != want || len(lines) != 4 || lines[1] != "nightly" || lines[2] != "job" || lines[3] != "r1" {
+		t.Errorf("output = %q, want dir %s and \"nightly job r1\"", out, dir)
+	}
+
+	if plain := Command(context.Background(), "true"); plain.Dir != "" || plain.Env != nil {
+		t.Errorf("Command() without env: Dir = %q, Env = %v, want defaults", plain.Dir, plain.Env)
+	}
+	if os.Getenv("EXECENV_JOB") != "" {
+		t.Error("Command() changed the service environment")
+	}
+}
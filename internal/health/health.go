@@ -0,0 +1,374 @@
+// Package health собирает проверки состояния компонентов сервиса
+// (база данных, внешние зависимости) для endpoint /health и периодически
+// выполняет их, публикуя изменения состояния в шину событий. Состояние
+// проверки меняется с гистерезисом (SetThresholds), чтобы одиночные
+// сбои и восстановления не давали поток переходов
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/metrics"
+)
+
+// Статусы проверки и сервиса. StatusDegraded - проверка не проходит
+// меньше failure_threshold раз подряд или восстанавливается и прошла
+// меньше success_threshold раз подряд
+const (
+	StatusHealthy   = "healthy"
+	StatusDegraded  = "degraded"
+	StatusUnhealthy = "unhealthy"
+)
+
+// Check проверяет компонент. nil означает, что компонент исправен
+type Check func(ctx context.Context) error
+
+// Result результат одной проверки. Status - состояние с учетом
+// гистерезиса, Error - ошибка последнего выполнения, Optional - проверка
+// некритичной зависимости (RegisterOptional)
+type Result struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	Optional   bool   `json:"optional,omitempty"`
+}
+
+// Report сводный результат всех проверок
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks,omitempty"`
+}
+
+// Registry реестр проверок состояния. Реализует task.Task: если interval
+// больше нуля, проверки выполняются в фоне, чтобы изменения состояния
+// были видны без запросов к /health
+type Registry struct {
+	timeout  time.Duration
+	interval time.Duration
+
+	mu       sync.RWMutex
+	checks   map[string]Check
+	optional map[string]bool
+	events   *events.Bus
+	metrics  *metrics.Server
+	failures int
+	recovery int
+
+	stateMu sync.Mutex
+	state   map[string]*checkState
+	status  string
+	// last отчет последнего выполнения проверок (Last)
+	last    Report
+	checked bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New создает реестр. timeout ограничивает время одной проверки,
+// interval задает период фоновых проверок (0 - только по запросу)
+func New(timeout, interval time.Duration) *Registry {
+	return &Registry{
+		timeout:  timeout,
+		interval: interval,
+		checks:   make(map[string]Check),
+		optional: make(map[string]bool),
+		failures: 1,
+		recovery: 1,
+		state:    make(map[string]*checkState),
+		status:   StatusHealthy,
+	}
+}
+
+// SetThresholds задает гистерезис: проверка становится unhealthy после
+// failures неудачных выполнений подряд и healthy после successes успешных
+// подряд, а до этого находится в состоянии degraded. Значения <= 1 -
+// переход с первого выполнения. Вызывается до AfterStart
+func (r *Registry) SetThresholds(failures, successes int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = max(failures, 1)
+	r.recovery = max(successes, 1)
+}
+
+// SetMetrics включает счетчик переходов health_transitions_total.
+// Вызывается до AfterStart
+func (r *Registry) SetMetrics(m *metrics.Server) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = m
+}
+
+// SetEvents включает публикацию изменений состояния проверок (events.TypeHealthChanged)
+// и сервиса (events.TypeHealthStatus)
+func (r *Registry) SetEvents(bus *events.Bus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = bus
+}
+
+// Name возвращает имя задачи
+func (r *Registry) Name() string {
+	return "health"
+}
+
+// ReadOnly задача запускается в режиме только для чтения: проверки здоровья только читают состояние зависимостей
+func (r *Registry) ReadOnly() bool {
+	return true
+}
+
+// AfterStart запускает фоновые проверки
+func (r *Registry) AfterStart(ctx context.Context) error {
+	if r.interval <= 0 {
+		return nil
+	}
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+	go r.loop(ctx)
+	return nil
+}
+
+// BeforeStop останавливает фоновые проверки
+func (r *Registry) BeforeStop(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel()
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loop периодически выполняет проверки. Первое выполнение - сразу,
+// чтобы Last не ждал интервал
+func (r *Registry) loop(ctx context.Context) {
+	defer close(r.done)
+	r.Run(ctx)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Run(ctx)
+		}
+	}
+}
+
+// Register добавляет или заменяет проверку name
+func (r *Registry) Register(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = check
+	delete(r.optional, name)
+}
+
+// RegisterOptional добавляет или заменяет проверку некритичной
+// зависимости name: ее отказ делает сервис degraded, а не unhealthy
+func (r *Registry) RegisterOptional(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = check
+	r.optional[name] = true
+}
+
+// Unregister удаляет проверку name
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	delete(r.checks, name)
+	delete(r.optional, name)
+	r.mu.Unlock()
+
+	r.stateMu.Lock()
+	delete(r.state, name)
+	r.stateMu.Unlock()
+}
+
+// Run выполняет все проверки параллельно. Сервис unhealthy, если
+// unhealthy хотя бы одна критичная проверка, degraded - если хотя бы одна
+// degraded или unhealthy некритичная. Выполнения по запросу /health
+// учитываются в гистерезисе так же, как фоновые
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := make(map[string]Check, len(r.checks))
+	for name, check := range r.checks {
+		checks[name] = check
+	}
+	optional := make(map[string]bool, len(r.optional))
+	for name := range r.optional {
+		optional[name] = true
+	}
+	bus := r.events
+	metricsServer := r.metrics
+	failures, recovery := r.failures, r.recovery
+	r.mu.RUnlock()
+
+	results := make([]Result, 0, len(checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			result := r.run(ctx, name, check)
+			result.Optional = optional[name]
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	status := r.track(bus, metricsServer, results, failures, recovery)
+	report := Report{Status: status, Checks: results}
+	r.stateMu.Lock()
+	r.last, r.checked = report, true
+	r.stateMu.Unlock()
+	return report
+}
+
+// Last возвращает отчет последнего выполнения проверок (фонового или
+// по запросу) без их выполнения. false - проверки еще не выполнялись
+func (r *Registry) Last() (Report, bool) {
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+	report := r.last
+	report.Checks = append([]Result(nil), r.last.Checks...)
+	return report, r.checked
+}
+
+// checkState состояние проверки с учетом гистерезиса. settled - последнее
+// устойчивое состояние (healthy или unhealthy), от которого отсчитываются
+// результаты подряд
+type checkState struct {
+	status    string
+	settled   string
+	failures  int
+	successes int
+}
+
+// observe учитывает результат выполнения проверки. Устойчивое состояние
+// меняется, когда набрано нужное количество противоположных результатов
+// подряд, до этого проверка degraded. Результат в пользу устойчивого
+// состояния сразу возвращает к нему
+func (s *checkState) observe(passed bool, failures, recovery int) {
+	if passed {
+		s.failures = 0
+		s.successes++
+	} else {
+		s.successes = 0
+		s.failures++
+	}
+	switch {
+	case s.settled == StatusHealthy && s.failures >= failures:
+		s.settled = StatusUnhealthy
+	case s.settled == StatusUnhealthy && s.successes >= recovery:
+		s.settled = StatusHealthy
+	}
+	s.status = s.settled
+	if (s.settled == StatusHealthy && s.failures > 0) || (s.settled == StatusUnhealthy && s.successes > 0) {
+		s.status = StatusDegraded
+	}
+}
+
+// track применяет результаты к состоянию проверок, заменяет Status
+// результатов состоянием с гистерезисом, публикует переходы и возвращает
+// состояние сервиса. Новая проверка начинает с состояния healthy
+func (r *Registry) track(bus *events.Bus, metricsServer *metrics.Server, results []Result, failures, recovery int) string {
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+
+	status := StatusHealthy
+	for i := range results {
+		result := &results[i]
+		state, ok := r.state[result.Name]
+		if !ok {
+			state = &checkState{status: StatusHealthy, settled: StatusHealthy}
+			r.state[result.Name] = state
+		}
+		previous := state.status
+		state.observe(result.Status == StatusHealthy, failures, recovery)
+		result.Status = state.status
+
+		switch {
+		case state.status == StatusUnhealthy && !result.Optional:
+			status = StatusUnhealthy
+		case state.status != StatusHealthy && status == StatusHealthy:
+			status = StatusDegraded
+		}
+		if previous == state.status {
+			continue
+		}
+		if metricsServer != nil {
+			metricsServer.RecordHealthTransition(result.Name, previous, state.status)
+		}
+		if bus != nil {
+			bus.Publish(events.TypeHealthChanged, map[string]interface{}{
+				"check": result.Name,
+				"from":  previous,
+				"to":    state.status,
+				"error": result.Error,
+			})
+		}
+	}
+
+	if previous := r.status; previous != status {
+		r.status = status
+		if bus != nil {
+			bus.Publish(events.TypeHealthStatus, map[string]interface{}{
+				"from": previous,
+				"to":   status,
+			})
+		}
+	}
+	return status
+}
+
+// run выполняет одну проверку с таймаутом
+func (r *Registry) run(ctx context.Context, name string, check Check) Result {
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := check(ctx)
+	result := Result{
+		Name:       name,
+		Status:     StatusHealthy,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = StatusUnhealthy
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Handler возвращает HTTP обработчик: 503 для сервиса в состоянии unhealthy,
+// иначе 200 (degraded сервис продолжает принимать трафик)
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Run(req.Context())
+		status := http.StatusOK
+		if report.Status == StatusUnhealthy {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,237 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/events"
+)
+
+// TestRun проверяет сводный статус проверок
+func TestRun(t *testing.T) {
+	r := New(time.Second, 0)
+	if report := r.Run(context.Background()); report.Status != StatusHealthy {
+		t.Errorf("Run() without checks = %s, want healthy", report.Status)
+	}
+
+	r.Register("db", func(ctx context.Context) error { return nil })
+	r.Register("cache", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	report := r.Run(context.Background())
+	if report.Status != StatusUnhealthy || len(report.Checks) != 2 {
+		t.Fatalf("Run() = %+v", report)
+	}
+	if c := report.Checks[0]; c.Name != "cache" || c.Status != StatusUnhealthy || c.Error != "connection refused" {
+		t.Errorf("Checks[0] = %+v", c)
+	}
+
+	r.Unregister("cache")
+	if report := r.Run(context.Background()); report.Status != StatusHealthy {
+		t.Errorf("Run() after Unregister = %s, want healthy", report.Status)
+	}
+}
+
+// TestOptional проверяет, что отказ некритичной зависимости делает
+// сервис degraded, и кэш последнего отчета
+func TestOptional(t *testing.T) {
+	r := New(time.Second, 0)
+	if _, ok := r.Last(); ok {
+		t.Error("Last() before Run reported results")
+	}
+	r.Register("db", func(ctx context.Context) error { return nil })
+	r.RegisterOptional("crm", func(ctx context.Context) error { return errors.New("timeout") })
+
+	report := r.Run(context.Background())
+	if report.Status != StatusDegraded || len(report.Checks) != 2 {
+		t.Fatalf("Run() = %+v, want degraded", report)
+	}
+	if c := report.Checks[0]; c.Name != "crm" || c.Status != StatusUnhealthy || !c.Optional {
+		t.Errorf("Checks[0] = %+v", c)
+	}
+	if last, ok := r.Last(); !ok || last.Status != StatusDegraded || len(last.Checks) != 2 {
+		t.Errorf("Last() = %+v, %v", last, ok)
+	}
+
+	// Register снимает признак некритичной проверки
+	r.Register("crm", func(ctx context.Context) error { return errors.New("timeout") })
+	if report := r.Run(context.Background()); report.Status != StatusUnhealthy {
+		t.Errorf("Run() with critical crm = %s, want unhealthy", report.Status)
+	}
+}
+
+// TestProbe проверяет проверки внешних зависимостей по виду
+func TestProbe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		kind, endpoint string
+		ok             bool
+	}{
+		{ProbeHTTP, srv.URL + "/health", true},
+		{ProbeHTTP, srv.URL + "/down", false},
+		{ProbeTCP, srv.Listener.Addr().String(), true},
+		{ProbeFile, t.TempDir(), true},
+		{ProbeFile, filepath.Join(t.TempDir(), "missing"), false},
+	}
+	for _, c := range cases {
+		check, err := Probe(c.kind, c.endpoint)
+		if err != nil {
+			t.Fatalf("Probe(%s) error = %v", c.kind, err)
+		}
+		if err := check(context.Background()); (err == nil) != c.ok {
+			t.Errorf("%s %s: check error = %v, want ok = %v", c.kind, c.endpoint, err, c.ok)
+		}
+	}
+	if _, err := Probe("ftp", "host:21"); err == nil {
+		t.Error("Probe(ftp) error = nil")
+	}
+}
+
+// TestTimeout проверяет ограничение времени проверки
+func TestTimeout(t *testing.T) {
+	r := New(20*time.Millisecond, 0)
+	r.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if report := r.Run(context.Background()); report.Status != StatusUnhealthy {
+		t.Errorf("Run() = %+v, want unhealthy", report)
+	}
+}
+
+// TestHandler проверяет HTTP коды ответа
+func TestHandler(t *testing.T) {
+	r := New(time.Second, 0)
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("healthy code = %d, want 200", rec.Code)
+	}
+
+	r.Register("db", func(ctx context.Context) error { return errors.New("down") })
+	rec = httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("unhealthy code = %d, want 503", rec.Code)
+	}
+}
+
+// TestTransitions проверяет события изменения состояния при фоновых проверках
+func TestTransitions(t *testing.T) {
+	r := New(time.Second, 10*time.Millisecond)
+	bus := events.New()
+	r.SetEvents(bus)
+	sub := bus.Subscribe(10, events.TypeHealthChanged)
+	defer sub.Close()
+
+	var down int32 = 1
+	r.Register("db", func(ctx context.Context) error {
+		if atomic.LoadInt32(&down) == 1 {
+			return errors.New("down")
+		}
+		return nil
+	})
+	r.Register("cache", func(ctx context.Context) error { return nil })
+
+	if err := r.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+	defer r.BeforeStop(context.Background())
+
+	next := func() map[string]interface{} {
+		t.Helper()
+		select {
+		case ev := <-sub.C:
+			return ev.Data.(map[string]interface{})
+		case <-time.After(2 * time.Second):
+			t.Fatal("no health event")
+			return nil
+		}
+	}
+	if data := next(); data["check"] != "db" || data["to"] != StatusUnhealthy {
+		t.Errorf("first event = %v, want db unhealthy", data)
+	}
+	atomic.StoreInt32(&down, 0)
+	if data := next(); data["check"] != "db" || data["from"] != StatusUnhealthy || data["to"] != StatusHealthy {
+		t.Errorf("second event = %v, want db recovered", data)
+	}
+}
+
+// TestHysteresis проверяет переходы healthy -> degraded -> unhealthy
+// и восстановление после нескольких успешных выполнений подряд
+func TestHysteresis(t *testing.T) {
+	r := New(time.Second, 0)
+	r.SetThresholds(2, 2)
+	bus := events.New()
+	r.SetEvents(bus)
+	sub := bus.Subscribe(20, events.TypeHealthStatus)
+	defer sub.Close()
+
+	var down int32
+	r.Register("db", func(ctx context.Context) error {
+		if atomic.LoadInt32(&down) == 1 {
+			return errors.New("down")
+		}
+		return nil
+	})
+
+	steps := []struct {
+		down int32
+		want string
+	}{
+		{0, StatusHealthy},
+		{1, StatusDegraded},
+		{0, StatusHealthy},
+		{1, StatusDegraded},
+		{1, StatusUnhealthy},
+		{1, StatusUnhealthy},
+		{0, StatusDegraded},
+		{1, StatusUnhealthy},
+		{0, StatusDegraded},
+		{0, StatusHealthy},
+	}
+	for i, step := range steps {
+		atomic.StoreInt32(&down, step.down)
+		report := r.Run(context.Background())
+		if report.Status != step.want || report.Checks[0].Status != step.want {
+			t.Fatalf("step %d: Run() = %+v, want %s", i, report, step.want)
+		}
+	}
+
+	// health.status публикуется при каждом изменении состояния сервиса
+	var transitions []string
+	for len(sub.C) > 0 {
+		ev := <-sub.C
+		transitions = append(transitions, ev.Data.(map[string]interface{})["to"].(string))
+	}
+	want := []string{StatusDegraded, StatusHealthy, StatusDegraded, StatusUnhealthy, StatusDegraded, StatusUnhealthy, StatusDegraded, StatusHealthy}
+	if len(transitions) != len(want) {
+		t.Fatalf("status events = %v, want %v", transitions, want)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Errorf("status events = %v, want %v", transitions, want)
+			break
+		}
+	}
+
+	// degraded сервис отвечает 200
+	atomic.StoreInt32(&down, 1)
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("degraded code = %d, want 200", rec.Code)
+	}
+}
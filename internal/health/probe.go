@@ -0,0 +1,51 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"service-boilerplate/internal/task"
+)
+
+// Виды внешних зависимостей для Probe
+const (
+	ProbeTCP  = "tcp"
+	ProbeHTTP = "http"
+	ProbeDNS  = "dns"
+	ProbeFile = "file"
+)
+
+// Probe возвращает проверку внешней зависимости вида kind: tcp -
+// подключение к endpoint (host:port), http - GET endpoint с ответом
+// 2xx или 3xx, dns - разрешение имени endpoint, file - существование
+// файла endpoint
+func Probe(kind, endpoint string) (Check, error) {
+	switch kind {
+	case ProbeTCP:
+		return task.TCP(endpoint).Ready, nil
+	case ProbeDNS:
+		return task.DNS(endpoint).Ready, nil
+	case ProbeFile:
+		return task.File(endpoint).Ready, nil
+	case ProbeHTTP:
+		return func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			if resp.StatusCode >= http.StatusBadRequest {
+				return fmt.Errorf("unexpected status %s", resp.Status)
+			}
+			return nil
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown dependency type %q", kind)
+}
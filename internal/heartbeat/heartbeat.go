@@ -0,0 +1,311 @@
+// Package heartbeat периодически отправляет документ состояния экземпляра
+// (идентичность, версия, здоровье, сводка таймеров) в центральный endpoint,
+// чтобы панель парка сервисов видела экземпляры за NAT, которые нельзя
+// опрашивать. Отправляется только текущее состояние: неотправленные
+// документы не накапливаются, следующий заменяет предыдущий
+package heartbeat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"service-boilerplate/internal/appctx"
+	"service-boilerplate/internal/health"
+	"service-boilerplate/internal/httpclient"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+	"service-boilerplate/internal/scheduler"
+)
+
+// errRejected ответ endpoint, после которого отправка не повторяется (4xx кроме 429)
+var errRejected = errors.New("heartbeat rejected by endpoint")
+
+// Config содержит настройки отправки
+type Config struct {
+	// URL endpoint приема heartbeat (POST, JSON документ Document)
+	URL string
+	// Interval период отправки
+	Interval time.Duration
+	// Timeout ограничивает отправку документа вместе с повторами
+	Timeout time.Duration
+	// BearerToken токен заголовка Authorization (пустой - без авторизации)
+	BearerToken string
+	// Headers дополнительные заголовки запроса
+	Headers map[string]string
+	// Identity идентичность экземпляра в документе
+	Identity appctx.Identity
+	// Client HTTP клиент для отправки. Повторы при сетевых ошибках и ответах
+	// 429/502/503/504 выполняет клиент (httpclient.Config MaxRetries и RetryBackoff):
+	// документ отправляется с заголовком Idempotency-Key
+	Client *http.Client
+}
+
+// Document тело запроса heartbeat
+type Document struct {
+	Service    string `json:"service"`
+	InstanceID string `json:"instance_id,omitempty"`
+	Version    string `json:"version"`
+	Hostname   string `json:"hostname,omitempty"`
+	IP         string `json:"ip,omitempty"`
+	PID        int    `json:"pid"`
+	// Sequence номер heartbeat с запуска: пропуски показывают потерянные отправки
+	Sequence      uint64    `json:"sequence"`
+	Timestamp     time.Time `json:"timestamp"`
+	StartedAt     time.Time `json:"started_at"`
+	UptimeSeconds float64   `json:"uptime_seconds"`
+	// Health сводное состояние последних проверок, пустое - проверки не выполнялись
+	Health string `json:"health,omitempty"`
+	// Stopping последний heartbeat перед graceful остановкой
+	Stopping bool    `json:"stopping,omitempty"`
+	Timers   []Timer `json:"timers"`
+}
+
+// Timer сводка таймера в документе
+type Timer struct {
+	Name        string     `json:"name"`
+	State       string     `json:"state"`
+	LastRun     *time.Time `json:"last_run,omitempty"`
+	NextRun     *time.Time `json:"next_run,omitempty"`
+	PanicCount  int        `json:"panic_count"`
+	MissedTicks int        `json:"missed_ticks"`
+}
+
+// Publisher отправляет heartbeat по расписанию. Реализует task.Task
+type Publisher struct {
+	log       *logger.Logger
+	metrics   *metrics.Server
+	scheduler *scheduler.Scheduler
+	cfg       Config
+	started   time.Time
+
+	mu       sync.Mutex
+	health   *health.Registry
+	sequence uint64
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New создает отправку heartbeat со сводкой таймеров sched
+func New(log *logger.Logger, metricsServer *metrics.Server, sched *scheduler.Scheduler, cfg Config) *Publisher {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{}
+	}
+	return &Publisher{log: log, metrics: metricsServer, scheduler: sched, cfg: cfg, started: time.Now()}
+}
+
+// SetHealth включает состояние последних проверок в документ.
+// Вызывается до AfterStart
+func (p *Publisher) SetHealth(registry *health.Registry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.health = registry
+}
+
+// Name возвращает имя задачи
+func (p *Publisher) Name() string {
+	return "heartbeat"
+}
+
+// ReadOnly задача запускается в режиме только для чтения: экземпляр
+// должен быть виден на панели парка
+func (p *Publisher) ReadOnly() bool {
+	return true
+}
+
+// AfterStart отправляет первый heartbeat и запускает периодическую отправку
+func (p *Publisher) AfterStart(ctx context.Context) error {
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.wg.Add(1)
+	go p.loop(ctx)
+
+	p.log.Info("Heartbeat started", map[string]interface{}{
+		"url":      redactURL(p.cfg.URL),
+		"interval": p.cfg.Interval.String(),
+	})
+	return nil
+}
+
+// BeforeStop останавливает периодическую отправку и отправляет последний
+// heartbeat с признаком остановки одной попыткой, пока не истек ctx
+func (p *Publisher) BeforeStop(ctx context.Context) error {
+	if p.cancel == nil {
+		return nil
+	}
+	p.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	doc := p.Document()
+	doc.Stopping = true
+	if err := p.post(ctx, doc, false); err != nil {
+		p.record("failed")
+		p.log.Warn("Failed to send final heartbeat", map[string]interface{}{"error": err.Error()})
+		return nil
+	}
+	p.record("sent")
+	return nil
+}
+
+// loop отправляет heartbeat сразу и далее каждые Interval
+func (p *Publisher) loop(ctx context.Context) {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		p.publish(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publish отправляет текущий документ с повторами
+func (p *Publisher) publish(ctx context.Context) {
+	err := p.post(ctx, p.Document(), true)
+	switch {
+	case err == nil:
+		p.record("sent")
+	case ctx.Err() != nil:
+		// Остановка: последний heartbeat отправит BeforeStop
+	default:
+		p.record("failed")
+		p.log.Warn("Failed to send heartbeat", map[string]interface{}{
+			"url":   redactURL(p.cfg.URL),
+			"error": err.Error(),
+		})
+	}
+}
+
+// Document возвращает текущий документ heartbeat со следующим номером
+func (p *Publisher) Document() Document {
+	p.mu.Lock()
+	p.sequence++
+	sequence, registry := p.sequence, p.health
+	p.mu.Unlock()
+
+	now := time.Now()
+	doc := Document{
+		Service:       p.cfg.Identity.Service,
+		InstanceID:    p.cfg.Identity.InstanceID,
+		Version:       p.cfg.Identity.Version,
+		Hostname:      p.cfg.Identity.Hostname,
+		IP:            p.cfg.Identity.IP,
+		PID:           os.Getpid(),
+		Sequence:      sequence,
+		Timestamp:     now.UTC(),
+		StartedAt:     p.started.UTC(),
+		UptimeSeconds: now.Sub(p.started).Seconds(),
+		Timers:        []Timer{},
+	}
+	if registry != nil {
+		if report, ok := registry.Last(); ok {
+			doc.Health = report.Status
+		}
+	}
+	if p.scheduler != nil {
+		for _, info := range p.scheduler.ListTimers() {
+			timer := Timer{
+				Name:        info.Name,
+				State:       info.State,
+				PanicCount:  info.PanicCount,
+				MissedTicks: info.MissedTicks,
+			}
+			if !info.LastRun.IsZero() {
+				last := info.LastRun.UTC()
+				timer.LastRun = &last
+			}
+			if !info.NextRun.IsZero() {
+				next := info.NextRun.UTC()
+				timer.NextRun = &next
+			}
+			doc.Timers = append(doc.Timers, timer)
+		}
+	}
+	return doc
+}
+
+// post отправляет документ. С retry запрос несет Idempotency-Key,
+// и клиент повторяет его при сетевых ошибках и ответах 429/502/503/504;
+// без него выполняется одна попытка
+func (p *Publisher) post(ctx context.Context, doc Document, retry bool) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errRejected, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.URL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errRejected, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if retry {
+		req.Header.Set(httpclient.IdempotencyKeyHeader, httpclient.NewIdempotencyKey())
+	}
+	if p.cfg.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+p.cfg.BearerToken)
+	}
+	for name, value := range p.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := p.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	switch {
+	case resp.StatusCode/100 == 2:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("heartbeat failed: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	default:
+		return fmt.Errorf("%w: %s: %s", errRejected, resp.Status, strings.TrimSpace(string(msg)))
+	}
+}
+
+// record записывает результат отправки в метрики
+func (p *Publisher) record(result string) {
+	if p.metrics != nil {
+		p.metrics.RecordHeartbeat(result)
+	}
+}
+
+// redactURL скрывает пароль в URL для логов
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,135 @@
+package heartbeat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/appctx"
+	"service-boilerplate/internal/httpclient"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/scheduler"
+)
+
+// receiver тестовый endpoint приема heartbeat
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	docs     []Document
+	headers  http.Header
+}
+
+// ServeHTTP принимает документ и отвечает следующим статусом из statuses
+// (после их окончания - 204)
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var doc Document
+	json.NewDecoder(req.Body).Decode(&doc)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.headers = req.Header.Clone()
+	status := http.StatusNoContent
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	if status == http.StatusNoContent {
+		r.docs = append(r.docs, doc)
+	}
+	w.WriteHeader(status)
+}
+
+// received возвращает принятые документы
+func (r *receiver) received() []Document {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Document(nil), r.docs...)
+}
+
+// setupPublisher создает отправку heartbeat в тестовый endpoint
+// со сводкой одного таймера и клиентом с retries повторами
+func setupPublisher(t *testing.T, url string, cfg Config, retries int) *Publisher {
+	t.Helper()
+	log, err := logger.New("test-heartbeat", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { log.Close() })
+
+	sched := scheduler.New(log, nil, 3, 0)
+	sched.AddTimer("report", time.Hour, func(ctx context.Context) {})
+
+	cfg.URL = url
+	cfg.Client = httpclient.New(log, nil, httpclient.Config{Name: "heartbeat", MaxRetries: retries, RetryBackoff: time.Millisecond})
+	cfg.Identity = appctx.Identity{Service: "svc", InstanceID: "i-1", Version: "1.2.3"}
+	return New(log, nil, sched, cfg)
+}
+
+// TestPublish проверяет документ, заголовки, повторы и последний
+// heartbeat при остановке
+func TestPublish(t *testing.T) {
+	r := &receiver{statuses: []int{http.StatusServiceUnavailable}}
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	p := setupPublisher(t, server.URL, Config{Interval: time.Hour, BearerToken: "secret", Headers: map[string]string{"X-Fleet": "eu"}}, 1)
+	if err := p.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(r.received()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := p.BeforeStop(context.Background()); err != nil {
+		t.Fatalf("BeforeStop() error = %v", err)
+	}
+
+	docs := r.received()
+	if len(docs) != 2 {
+		t.Fatalf("heartbeats = %d, want first after retry and final", len(docs))
+	}
+	first, final := docs[0], docs[1]
+	if first.Service != "svc" || first.InstanceID != "i-1" || first.Version != "1.2.3" || first.Sequence != 1 || first.Stopping {
+		t.Errorf("first heartbeat = %+v", first)
+	}
+	if len(first.Timers) != 1 || first.Timers[0].Name != "report" || first.Timers[0].State == "" {
+		t.Errorf("timers = %+v", first.Timers)
+	}
+	if !final.Stopping || final.Sequence != 2 {
+		t.Errorf("final heartbeat = %+v", final)
+	}
+	r.mu.Lock()
+	headers := r.headers
+	r.mu.Unlock()
+	if headers.Get("Authorization") != "Bearer secret" || headers.Get("X-Fleet") != "eu" || headers.Get("Content-Type") != "application/json" {
+		t.Errorf("headers = %v", headers)
+	}
+	// Последний heartbeat отправляется одной попыткой
+	if key := headers.Get(httpclient.IdempotencyKeyHeader); key != "" {
+		t.Errorf("final heartbeat Idempotency-Key = %q, want none", key)
+	}
+}
+
+// TestPublish_Rejected проверяет, что ответ 4xx не повторяется
+func TestPublish_Rejected(t *testing.T) {
+	r := &receiver{statuses: []int{http.StatusBadRequest}}
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	p := setupPublisher(t, server.URL, Config{}, 3)
+	if err := p.post(context.Background(), p.Document(), true); err == nil {
+		t.Fatal("post() error = nil, want rejected")
+	}
+	r.mu.Lock()
+	left := len(r.statuses)
+	r.mu.Unlock()
+	if left != 0 || len(r.received()) != 0 {
+		t.Errorf("statuses left = %d, received = %d", left, len(r.received()))
+	}
+	if err := p.post(context.Background(), p.Document(), true); err != nil {
+		t.Errorf("post() error = %v", err)
+	}
+}
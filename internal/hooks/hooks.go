@@ -0,0 +1,236 @@
+// Package hooks запускает внешние команды в точках жизненного цикла
+// сервиса (после запуска, перед остановкой, при panic таймера), чтобы
+// операторы подключали свои действия (уведомить CMDB, сбросить кэш)
+// без изменения кода. Вывод команды пишется в лог
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"sync"
+	"time"
+
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/execenv"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/runid"
+)
+
+// Точки вызова команд
+const (
+	PostStart    = "post_start"
+	PreStop      = "pre_stop"
+	TimerFailure = "timer_failure"
+)
+
+// EnvHook переменная окружения команды с точкой вызова
+const EnvHook = "SERVICE_HOOK"
+
+const (
+	// defaultTimeout время выполнения команды по умолчанию
+	defaultTimeout = 30 * time.Second
+	// maxOutput сколько байт вывода команды попадает в лог
+	maxOutput = 16 << 10
+	// queueSize события timer_failure, ожидающие выполнения команд
+	queueSize = 16
+)
+
+// Command внешняя команда точки вызова
+type Command struct {
+	Command string
+	Args    []string
+	// Dir рабочая директория, пустая - директория сервиса
+	Dir string
+	// Env переменные поверх окружения сервиса
+	Env map[string]string
+	// Timeout после которого команда завершается, 0 - 30 секунд
+	Timeout time.Duration
+}
+
+// Recorder записывает результаты выполнения команд
+type Recorder interface {
+	RecordHookRun(hook, result string)
+}
+
+// Runner выполняет команды точек вызова. Реализует task.Task: между
+// AfterStart и BeforeStop выполняет команды timer_failure по событиям
+// events.TypeTimerRun со статусом panic
+type Runner struct {
+	log     *logger.Logger
+	metrics Recorder
+	bus     *events.Bus
+	hooks   map[string][]Command
+	vars    map[string]string
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New создает исполнителя команд hooks по точкам вызова
+func New(log *logger.Logger, metrics Recorder, bus *events.Bus, hooks map[string][]Command) *Runner {
+	return &Runner{
+		log:     log,
+		metrics: metrics,
+		bus:     bus,
+		hooks:   hooks,
+	}
+}
+
+// SetVars задает переменные окружения всех команд (имя и идентификатор
+// экземпляра). Вызывается до AfterStart
+func (r *Runner) SetVars(vars map[string]string) {
+	r.vars = vars
+}
+
+// Name возвращает имя задачи
+func (r *Runner) Name() string {
+	return "hooks"
+}
+
+// AfterStart подписывается на события таймеров, если заданы команды timer_failure
+func (r *Runner) AfterStart(ctx context.Context) error {
+	if r.bus == nil || len(r.hooks[TimerFailure]) == 0 {
+		return nil
+	}
+	sub := r.bus.Subscribe(queueSize, events.TypeTimerRun)
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+	go r.loop(ctx, sub)
+	return nil
+}
+
+// BeforeStop прекращает обработку событий и ждет выполняющиеся команды
+func (r *Runner) BeforeStop(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel()
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loop выполняет команды timer_failure для неудачных запусков таймеров
+func (r *Runner) loop(ctx context.Context, sub *events.Subscription) {
+	defer close(r.done)
+	defer sub.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-sub.C:
+			data, _ := e.Data.(map[string]interface{})
+			if status, _ := data["status"].(string); status != "panic" {
+				continue
+			}
+			timer, _ := data["timer"].(string)
+			runID, _ := data["run_id"].(string)
+			r.Run(ctx, TimerFailure, map[string]string{
+				runid.EnvTimer: timer,
+				runid.EnvRunID: runID,
+			})
+		}
+	}
+}
+
+// Run выполняет команды точки hook по очереди и ждет их завершения.
+// vars добавляются к окружению команд. Ошибки команд пишутся в лог
+// и не прерывают работу сервиса
+func (r *Runner) Run(ctx context.Context, hook string, vars map[string]string) {
+	for _, c := range r.hooks[hook] {
+		if ctx.Err() != nil {
+			return
+		}
+		r.exec(ctx, hook, c, vars)
+	}
+}
+
+// exec выполняет одну команду с таймаутом и пишет ее вывод в лог
+func (r *Runner) exec(ctx context.Context, hook string, c Command, vars map[string]string) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	env := make(map[string]string, len(r.vars)+len(vars)+len(c.Env)+1)
+	for _, m := range []map[string]string{r.vars, vars, c.Env} {
+		for k, v := range m {
+			env[k] = v
+		}
+	}
+	env[EnvHook] = hook
+
+	cmd := exec.CommandContext(ctx, c.Command, c.Args...)
+	execenv.Env{Dir: c.Dir, Vars: env}.Apply(cmd)
+	output := &limitedBuffer{limit: maxOutput}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	// Вывод потомков, унаследовавших дескрипторы, не задерживает Wait
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	fields := map[string]interface{}{
+		"hook":        hook,
+		"command":     c.Command,
+		"duration_ms": time.Since(start).Milliseconds(),
+	}
+	if out := output.String(); out != "" {
+		fields["output"] = out
+	}
+
+	result := "ok"
+	if err != nil {
+		result = "failed"
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result = "timeout"
+			fields["timeout"] = timeout.String()
+		}
+		fields["error"] = err.Error()
+		r.log.Warn("Hook command failed", fields)
+	} else {
+		r.log.Info("Hook command finished", fields)
+	}
+	if r.metrics != nil {
+		r.metrics.RecordHookRun(hook, result)
+	}
+}
+
+// limitedBuffer сохраняет первые limit байт вывода и отмечает обрезку
+type limitedBuffer struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// Write реализует io.Writer. Вывод сверх лимита отбрасывается без ошибки,
+// чтобы команда не получила EPIPE
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if room := b.limit - b.buf.Len(); room < len(p) {
+		b.buf.Write(p[:max(room, 0)])
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// String возвращает сохраненный вывод без завершающих переводов строк
+func (b *limitedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := string(bytes.TrimRight(b.buf.Bytes(), "\r\n"))
+	if b.truncated {
+		out += "\n... (output truncated)"
+	}
+	return out
+}
@@ -0,0 +1,142 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/runid"
+)
+
+// TestHelperProcess не является тестом: это внешняя команда для остальных тестов
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	switch os.Getenv("HELPER_MODE") {
+	case "output":
+		fmt.Println("hook", os.Getenv(EnvHook), "service", os.Getenv("SERVICE_NAME"), "timer", os.Getenv(runid.EnvTimer))
+		os.Exit(0)
+	case "fail":
+		fmt.Fprintln(os.Stderr, "cache flush failed")
+		os.Exit(2)
+	case "sleep":
+		time.Sleep(time.Minute)
+	}
+	os.Exit(0)
+}
+
+// helper описывает команду, запускающую TestHelperProcess в режиме mode
+func helper(mode string, timeout time.Duration) Command {
+	return Command{
+		Command: os.Args[0],
+		Args:    []string{"-test.run=TestHelperProcess"},
+		Env:     map[string]string{"GO_WANT_HELPER_PROCESS": "1", "HELPER_MODE": mode},
+		Timeout: timeout,
+	}
+}
+
+// recorder запоминает результаты выполнения команд
+type recorder struct {
+	mu   sync.Mutex
+	runs []string
+}
+
+func (r *recorder) RecordHookRun(hook, result string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs = append(r.runs, hook+":"+result)
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.runs...)
+}
+
+// readLog возвращает содержимое файла лога
+func readLog(t *testing.T, log *logger.Logger, dir string) string {
+	t.Helper()
+	log.Flush()
+	content, err := os.ReadFile(logger.FilePath(dir, "test-hooks"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+// TestRun проверяет вывод команд в лог, окружение, ошибки и таймаут
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	log, err := logger.New("test-hooks", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	rec := &recorder{}
+	r := New(log, rec, nil, map[string][]Command{
+		PostStart: {helper("output", 0)},
+		PreStop:   {helper("fail", 0), helper("sleep", 100*time.Millisecond)},
+	})
+	r.SetVars(map[string]string{"SERVICE_NAME": "svc"})
+
+	r.Run(context.Background(), PostStart, nil)
+	start := time.Now()
+	r.Run(context.Background(), PreStop, nil)
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("pre_stop took %v, want timeout to stop the sleeping command", elapsed)
+	}
+
+	want := []string{"post_start:ok", "pre_stop:failed", "pre_stop:timeout"}
+	if got := rec.get(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("runs = %v, want %v", got, want)
+	}
+	content := readLog(t, log, dir)
+	for _, want := range []string{`"output":"hook post_start service svc timer "`, `"output":"cache flush failed"`, `"timeout":"100ms"`} {
+		if !strings.Contains(content, want) {
+			t.Errorf("log does not contain %s", want)
+		}
+	}
+}
+
+// TestTimerFailure проверяет запуск команд timer_failure по panic таймера
+func TestTimerFailure(t *testing.T) {
+	dir := t.TempDir()
+	log, err := logger.New("test-hooks", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	bus := events.New()
+	rec := &recorder{}
+	r := New(log, rec, bus, map[string][]Command{TimerFailure: {helper("output", 0)}})
+	if err := r.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+
+	bus.Publish(events.TypeTimerRun, map[string]interface{}{"timer": "report", "status": "ok"})
+	bus.Publish(events.TypeTimerRun, map[string]interface{}{"timer": "report", "status": "panic", "run_id": "r1"})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(rec.get()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := r.BeforeStop(context.Background()); err != nil {
+		t.Fatalf("BeforeStop() error = %v", err)
+	}
+
+	if got := rec.get(); len(got) != 1 || got[0] != "timer_failure:ok" {
+		t.Errorf("runs = %v, want one timer_failure:ok", got)
+	}
+	if content := readLog(t, log, dir); !strings.Contains(content, `"output":"hook timer_failure service  timer report"`) {
+		t.Errorf("log does not contain timer_failure output")
+	}
+}
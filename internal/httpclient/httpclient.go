@@ -0,0 +1,299 @@
+// Package httpclient создает http.Client для обращения к внешним API:
+// таймауты, повторы с экспоненциальной задержкой, необязательный circuit
+// breaker, метрики запросов и корреляция логов через X-Request-ID
+package httpclient
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	mathrand "math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"service-boilerplate/internal/appctx"
+	"service-boilerplate/internal/buildinfo"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+	"service-boilerplate/internal/runid"
+)
+
+// RequestIDHeader заголовок с идентификатором запроса для сопоставления
+// логов сервиса и вызываемого API
+const RequestIDHeader = "X-Request-ID"
+
+// IdempotencyKeyHeader заголовок ключа идемпотентности: запросы POST
+// и PATCH с ним повторяются так же, как идемпотентные методы
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxBackoffShift ограничивает рост задержки между повторами
+const maxBackoffShift = 6
+
+// Breaker circuit breaker, через который проходят запросы клиента
+type Breaker interface {
+	// Allow возвращает ошибку, если запросы к зависимости сейчас запрещены
+	Allow() error
+	// Report сообщает результат разрешенного запроса
+	Report(success bool)
+}
+
+// Limiter ограничитель частоты запросов клиента
+type Limiter interface {
+	// Wait дожидается разрешения на запрос
+	Wait(ctx context.Context) error
+}
+
+// Config содержит настройки клиента
+type Config struct {
+	// Name имя клиента в метриках и логах (например, имя внешнего API)
+	Name string
+	// Timeout ограничивает запрос целиком, включая повторы и чтение тела
+	Timeout time.Duration
+	// MaxRetries количество повторов после первой попытки (0 - без повторов)
+	MaxRetries int
+	// RetryBackoff задержка перед первым повтором, удваивается с каждым следующим
+	RetryBackoff time.Duration
+	// Breaker необязательный circuit breaker
+	Breaker Breaker
+	// Limiter необязательный ограничитель частоты; ожидание токена
+	// выполняется перед каждой попыткой
+	Limiter Limiter
+}
+
+// New создает http.Client с настройками cfg
+func New(log *logger.Logger, metricsServer *metrics.Server, cfg Config) *http.Client {
+	if cfg.Name == "" {
+		cfg.Name = "default"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 200 * time.Millisecond
+	}
+	return &http.Client{
+		Timeout: cfg.Timeout,
+		Transport: &Transport{
+			log:     log,
+			metrics: metricsServer,
+			cfg:     cfg,
+			base:    newBaseTransport(),
+		},
+	}
+}
+
+// newBaseTransport создает транспорт с ограниченными таймаутами соединения,
+// в отличие от http.DefaultTransport не зависящий от глобального состояния
+func newBaseTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// Transport http.RoundTripper с повторами, circuit breaker, метриками и логами
+type Transport struct {
+	log     *logger.Logger
+	metrics *metrics.Server
+	cfg     Config
+	base    http.RoundTripper
+}
+
+// RoundTrip выполняет запрос, повторяя его при сетевых ошибках и ответах
+// 429/502/503/504. Повторяются только идемпотентные запросы, тело которых
+// можно прочитать повторно
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if req.Header.Get(RequestIDHeader) == "" {
+		req.Header.Set(RequestIDHeader, newRequestID())
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", userAgent(req.Context()))
+	}
+	// Запрос из обработчика таймера несет его запуск и трассировку
+	runid.Inject(req.Context(), req.Header)
+
+	retries := 0
+	if retryable(req) {
+		retries = t.cfg.MaxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		if t.cfg.Limiter != nil {
+			if err := t.cfg.Limiter.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+		if t.cfg.Breaker != nil {
+			if err := t.cfg.Breaker.Allow(); err != nil {
+				t.log.Warn("HTTP client request rejected by circuit breaker", t.fields(req, map[string]interface{}{
+					"error": err.Error(),
+				}))
+				return nil, fmt.Errorf("%s: %w", t.cfg.Name, err)
+			}
+		}
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		start := time.Now()
+		resp, err := t.base.RoundTrip(req)
+		duration := time.Since(start)
+
+		failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+		if t.cfg.Breaker != nil {
+			t.cfg.Breaker.Report(!failed)
+		}
+		t.record(req, resp, err, duration)
+
+		if attempt >= retries || !shouldRetry(resp, err) || req.Context().Err() != nil {
+			if err != nil {
+				t.log.Warn("HTTP client request failed", t.fields(req, map[string]interface{}{
+					"attempt":     attempt + 1,
+					"duration_ms": duration.Milliseconds(),
+					"error":       err.Error(),
+				}))
+			}
+			return resp, err
+		}
+
+		delay := t.backoff(attempt, resp)
+		fields := map[string]interface{}{
+			"attempt":     attempt + 1,
+			"duration_ms": duration.Milliseconds(),
+			"retry_in":    delay.String(),
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+		} else {
+			fields["status"] = resp.StatusCode
+			// Тело нужно дочитать, чтобы соединение вернулось в пул
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+		t.log.Warn("HTTP client request will be retried", t.fields(req, fields))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// record записывает метрики попытки и отладочный лог
+func (t *Transport) record(req *http.Request, resp *http.Response, err error, duration time.Duration) {
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	if t.metrics != nil {
+		t.metrics.RecordHTTPClientRequest(t.cfg.Name, req.Method, code, duration)
+	}
+	t.log.Debug("HTTP client request", t.fields(req, map[string]interface{}{
+		"code":        code,
+		"duration_ms": duration.Milliseconds(),
+	}))
+}
+
+// fields возвращает поля лога запроса вместе с идентичностью сервиса.
+// Строка запроса не логируется: в ней могут быть ключи API
+func (t *Transport) fields(req *http.Request, extra map[string]interface{}) map[string]interface{} {
+	fields := appctx.Fields(req.Context())
+	fields["client"] = t.cfg.Name
+	fields["method"] = req.Method
+	fields["url"] = req.URL.Scheme + "://" + req.URL.Host + req.URL.Path
+	fields["request_id"] = req.Header.Get(RequestIDHeader)
+	if id := runid.ID(req.Context()); id != "" {
+		fields["run_id"] = id
+	}
+	for k, v := range extra {
+		fields[k] = v
+	}
+	return fields
+}
+
+// backoff возвращает задержку перед повтором: Retry-After ответа, если он
+// задан в секундах, иначе экспоненциальную задержку со случайным разбросом
+func (t *Transport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s >= 0 {
+			return time.Duration(s) * time.Second
+		}
+	}
+	shift := attempt
+	if shift > maxBackoffShift {
+		shift = maxBackoffShift
+	}
+	delay := t.cfg.RetryBackoff << shift
+	// Разброс до 20%, чтобы клиенты не повторяли запросы одновременно
+	return delay + time.Duration(mathrand.Int64N(int64(delay)/5+1))
+}
+
+// retryable сообщает, можно ли безопасно повторить запрос
+func retryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return req.Header.Get(IdempotencyKeyHeader) != "" && (req.Body == nil || req.GetBody != nil)
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// shouldRetry сообщает, стоит ли повторять запрос после такого результата
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// userAgent возвращает User-Agent вида service/version
+func userAgent(ctx context.Context) string {
+	service := appctx.ServiceName(ctx)
+	if service == "" {
+		service = "service-boilerplate"
+	}
+	return service + "/" + buildinfo.Version
+}
+
+// NewIdempotencyKey генерирует ключ для IdempotencyKeyHeader. Повторы
+// одного запроса и повторные отправки тех же данных несут один ключ
+func NewIdempotencyKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// newRequestID генерирует идентификатор запроса
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,120 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+)
+
+// setupTestClient создает клиент с быстрыми повторами
+func setupTestClient(t *testing.T, cfg Config) (*http.Client, *logger.Logger) {
+	log, err := logger.New("test-httpclient", t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	cfg.Name = "test"
+	cfg.RetryBackoff = time.Millisecond
+	return New(log, metrics.New(log, true, "127.0.0.1:0"), cfg), log
+}
+
+// flakyServer отвечает 503 на первые failures запросов
+func flakyServer(t *testing.T, failures int32, calls *int32) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(RequestIDHeader) == "" {
+			t.Error("request without " + RequestIDHeader)
+		}
+		if atomic.AddInt32(calls, 1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// TestRetry проверяет повтор идемпотентных запросов
+func TestRetry(t *testing.T) {
+	client, log := setupTestClient(t, Config{MaxRetries: 2})
+	defer log.Close()
+
+	var calls int32
+	srv := flakyServer(t, 2, &calls)
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls != 3 {
+		t.Errorf("status = %d, calls = %d, want 200 after 3 calls", resp.StatusCode, calls)
+	}
+
+	// POST без Idempotency-Key не повторяется
+	calls = 0
+	resp, err = client.Post(srv.URL, "text/plain", strings.NewReader("body"))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || calls != 1 {
+		t.Errorf("status = %d, calls = %d, want 503 after 1 call", resp.StatusCode, calls)
+	}
+
+	// С Idempotency-Key тело отправляется повторно
+	calls = 0
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("body"))
+	req.Header.Set("Idempotency-Key", "k1")
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls != 3 {
+		t.Errorf("status = %d, calls = %d, want 200 after 3 calls", resp.StatusCode, calls)
+	}
+}
+
+// fakeBreaker размыкается после первой неудачи
+type fakeBreaker struct {
+	open    int32
+	reports int32
+}
+
+func (b *fakeBreaker) Allow() error {
+	if atomic.LoadInt32(&b.open) == 1 {
+		return errors.New("open")
+	}
+	return nil
+}
+
+func (b *fakeBreaker) Report(success bool) {
+	atomic.AddInt32(&b.reports, 1)
+	if !success {
+		atomic.StoreInt32(&b.open, 1)
+	}
+}
+
+// TestBreaker проверяет, что разомкнутый breaker прекращает запросы
+func TestBreaker(t *testing.T) {
+	breaker := &fakeBreaker{}
+	client, log := setupTestClient(t, Config{MaxRetries: 3, Breaker: breaker})
+	defer log.Close()
+
+	var calls int32
+	srv := flakyServer(t, 10, &calls)
+
+	if _, err := client.Get(srv.URL); err == nil {
+		t.Fatal("Get() expected breaker error")
+	}
+	if calls != 1 || breaker.reports != 1 {
+		t.Errorf("calls = %d, reports = %d, want 1 and 1", calls, breaker.reports)
+	}
+}
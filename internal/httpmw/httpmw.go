@@ -0,0 +1,192 @@
+// Package httpmw общие middleware HTTP серверов сервиса (метрики, admin API,
+// сервер приложения): идентификатор запроса, журнал доступа, перехват
+// panic и метрики запросов
+package httpmw
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"service-boilerplate/internal/logger"
+)
+
+// RequestIDHeader заголовок идентификатора запроса. Значение клиента
+// используется, если оно допустимо, иначе генерируется новое
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength максимальная длина идентификатора запроса клиента
+const maxRequestIDLength = 64
+
+// unmatchedRoute метка метрик для запросов без подходящего маршрута
+const unmatchedRoute = "unmatched"
+
+// Recorder записывает метрики HTTP запросов. Реализуется *metrics.Server
+type Recorder interface {
+	RecordHTTPRequest(server, route, method string, code int, duration time.Duration)
+}
+
+// Options настройки middleware
+type Options struct {
+	// Server имя сервера: метка server метрик и поле журнала доступа
+	Server string
+	Log    *logger.Logger
+	// Metrics может быть nil
+	Metrics Recorder
+	// AccessLevel уровень записи журнала доступа для ответов ниже 500:
+	// InfoLevel или DebugLevel (частые запросы, например сбор метрик).
+	// Ответы 5xx всегда пишутся уровнем error
+	AccessLevel logger.Level
+}
+
+// requestIDKey ключ контекста для идентификатора запроса
+type requestIDKey struct{}
+
+// Wrap оборачивает next всеми middleware: идентификатор запроса, журнал
+// доступа и метрики, перехват panic
+func Wrap(next http.Handler, opts Options) http.Handler {
+	return observe(recoverPanic(next, opts), opts)
+}
+
+// RequestID возвращает идентификатор запроса из контекста или пустую строку
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Fields возвращает поля запроса для структурированного лога обработчика,
+// чтобы его записи связывались с записью журнала доступа
+func Fields(r *http.Request) map[string]interface{} {
+	fields := map[string]interface{}{
+		"method": r.Method,
+		"path":   r.URL.Path,
+	}
+	if id := RequestID(r.Context()); id != "" {
+		fields["request_id"] = id
+	}
+	return fields
+}
+
+// observe назначает идентификатор запроса, логирует запрос и записывает метрики
+func observe(next http.Handler, opts Options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := requestID(r.Header.Get(RequestIDHeader))
+		w.Header().Set(RequestIDHeader, id)
+		r = r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+		duration := time.Since(start)
+
+		status := sw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		// ServeMux записывает совпавший шаблон в r.Pattern
+		route := r.Pattern
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		if opts.Metrics != nil {
+			opts.Metrics.RecordHTTPRequest(opts.Server, route, r.Method, status, duration)
+		}
+
+		fields := Fields(r)
+		fields["server"] = opts.Server
+		fields["route"] = route
+		fields["status"] = status
+		fields["duration_ms"] = duration.Milliseconds()
+		fields["remote"] = r.RemoteAddr
+		switch {
+		case status >= http.StatusInternalServerError:
+			opts.Log.Error("HTTP request", fields)
+		case opts.AccessLevel == logger.DebugLevel:
+			opts.Log.Debug("HTTP request", fields)
+		default:
+			opts.Log.Info("HTTP request", fields)
+		}
+	})
+}
+
+// recoverPanic перехватывает panic обработчика и отвечает 500
+func recoverPanic(next http.Handler, opts Options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				fields := Fields(r)
+				fields["server"] = opts.Server
+				fields["panic"] = rec
+				fields["stacktrace"] = string(debug.Stack())
+				opts.Log.Error("HTTP handler panic recovered", fields)
+				if sw, ok := w.(*statusWriter); !ok || sw.status == 0 {
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestID возвращает идентификатор клиента, если он допустим, иначе новый
+func requestID(client string) string {
+	if validRequestID(client) {
+		return client
+	}
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// validRequestID проверяет идентификатор клиента: непустой, ограниченной
+// длины, только буквы, цифры и -_.: (значение попадает в логи и заголовки)
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// statusWriter запоминает код ответа
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader запоминает код ответа
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write запоминает неявный код 200
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap дает http.ResponseController доступ к исходному writer
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
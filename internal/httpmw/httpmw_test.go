@@ -0,0 +1,86 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/logger"
+)
+
+// recorder записывает вызовы RecordHTTPRequest
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) RecordHTTPRequest(server, route, method string, code int, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, server+" "+route+" "+http.StatusText(code))
+}
+
+// setupHandler создает обработчик с middleware и тестовыми маршрутами
+func setupHandler(t *testing.T) (http.Handler, *recorder) {
+	log, err := logger.New("test-httpmw", t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	t.Cleanup(func() { log.Close() })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /id", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(RequestID(r.Context())))
+	})
+	mux.HandleFunc("GET /panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	rec := &recorder{}
+	return Wrap(mux, Options{Server: "admin", Log: log, Metrics: rec, AccessLevel: logger.InfoLevel}), rec
+}
+
+// TestRequestID проверяет генерацию идентификатора запроса и прием
+// допустимого идентификатора клиента
+func TestRequestID(t *testing.T) {
+	h, _ := setupHandler(t)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/id", nil))
+	id := w.Header().Get(RequestIDHeader)
+	if len(id) != 16 || w.Body.String() != id {
+		t.Errorf("generated id = %q, body = %q, want 16 hex chars in both", id, w.Body.String())
+	}
+
+	for client, keep := range map[string]bool{
+		"req-42":                   true,
+		"bad id\nX-Injected: true": false,
+		string(make([]byte, 100)):  false,
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/id", nil)
+		r.Header.Set(RequestIDHeader, client)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if got := w.Header().Get(RequestIDHeader); (got == client) != keep {
+			t.Errorf("client id %q -> %q, keep = %v", client, got, keep)
+		}
+	}
+}
+
+// TestRecoverAndMetrics проверяет перехват panic и метрики с меткой сервера
+func TestRecoverAndMetrics(t *testing.T) {
+	h, rec := setupHandler(t)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("GET /panic = %d, want 500", w.Code)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	want := []string{"admin GET /panic Internal Server Error", "admin unmatched Not Found"}
+	if len(rec.calls) != len(want) || rec.calls[0] != want[0] || rec.calls[1] != want[1] {
+		t.Errorf("RecordHTTPRequest calls = %q, want %q", rec.calls, want)
+	}
+}
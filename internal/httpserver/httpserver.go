@@ -0,0 +1,197 @@
+// Package httpserver предоставляет HTTP сервер для маршрутов приложения
+// с TLS, готовностью и интеграцией с lifecycle. Логирование запросов,
+// метрики и перехват panic - общие middleware httpmw
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"service-boilerplate/internal/apperr"
+	"service-boilerplate/internal/httpmw"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+	"service-boilerplate/internal/task"
+)
+
+// Config содержит настройки сервера
+type Config struct {
+	Enabled      bool
+	Listen       string
+	CertFile     string
+	KeyFile      string
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+}
+
+// Server HTTP сервер приложения. Реализует task.Task: начинает принимать
+// соединения в AfterStart, но до SetReady(true) отвечает 503, чтобы
+// запросы не приходили раньше запуска остальных компонентов
+type Server struct {
+	log      *logger.Logger
+	metrics  *metrics.Server
+	cfg      Config
+	mux      *http.ServeMux
+	server   *http.Server
+	listener net.Listener
+	ready    int32
+}
+
+// New создает HTTP сервер приложения
+func New(log *logger.Logger, metricsServer *metrics.Server, cfg Config) *Server {
+	s := &Server{
+		log:     log,
+		metrics: metricsServer,
+		cfg:     cfg,
+		mux:     http.NewServeMux(),
+	}
+	s.server = &http.Server{
+		Handler:      s.Handler(),
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+	return s
+}
+
+// Handle регистрирует обработчик для шаблона http.ServeMux
+// (например, "GET /items/{id}")
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// HandleFunc регистрирует функцию-обработчик для шаблона http.ServeMux
+func (s *Server) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	s.mux.HandleFunc(pattern, handler)
+}
+
+// Handler возвращает обработчик со всеми middleware (полезно для тестов)
+func (s *Server) Handler() http.Handler {
+	opts := httpmw.Options{Server: "http", Log: s.log, AccessLevel: logger.InfoLevel}
+	if s.metrics != nil {
+		opts.Metrics = s.metrics
+	}
+	return httpmw.Wrap(s.gate(s.mux), opts)
+}
+
+// SetReady открывает или закрывает прием запросов
+func (s *Server) SetReady(ready bool) {
+	var v int32
+	if ready {
+		v = 1
+	}
+	if atomic.SwapInt32(&s.ready, v) != v {
+		s.log.Info("HTTP server readiness changed", map[string]interface{}{"ready": ready})
+	}
+}
+
+// IsReady сообщает, принимает ли сервер запросы
+func (s *Server) IsReady() bool {
+	return atomic.LoadInt32(&s.ready) == 1
+}
+
+// GetAddress возвращает адрес сервера (полезно для тестов)
+func (s *Server) GetAddress() string {
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return s.cfg.Listen
+}
+
+// Name возвращает имя задачи
+func (s *Server) Name() string {
+	return "http"
+}
+
+// ShutdownPhase останавливает прием запросов раньше остальных задач
+func (s *Server) ShutdownPhase() task.Phase {
+	return task.PhaseStopAccepting
+}
+
+// Check проверяет, что сертификат и ключ TLS загружаются
+func (s *Server) Check(ctx context.Context) error {
+	if !s.cfg.Enabled || !s.tlsEnabled() {
+		return nil
+	}
+	if _, err := tls.LoadX509KeyPair(s.cfg.CertFile, s.cfg.KeyFile); err != nil {
+		return fmt.Errorf("failed to load TLS key pair: %w", err)
+	}
+	return nil
+}
+
+// AfterStart начинает принимать соединения
+func (s *Server) AfterStart(ctx context.Context) error {
+	if !s.cfg.Enabled {
+		s.log.Info("HTTP server is disabled")
+		return nil
+	}
+	if err := s.Check(ctx); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", s.cfg.Listen)
+	if err != nil {
+		return apperr.Listen("HTTP server", s.cfg.Listen, err)
+	}
+	s.listener = listener
+
+	s.log.Info("Starting HTTP server", map[string]interface{}{
+		"listen": s.GetAddress(),
+		"tls":    s.tlsEnabled(),
+	})
+
+	go func() {
+		var err error
+		if s.tlsEnabled() {
+			err = s.server.ServeTLS(listener, s.cfg.CertFile, s.cfg.KeyFile)
+		} else {
+			err = s.server.Serve(listener)
+		}
+		if err != nil && err != http.ErrServerClosed {
+			s.log.Error("HTTP server error", map[string]interface{}{"error": err.Error()})
+		}
+	}()
+
+	return nil
+}
+
+// BeforeStop закрывает прием запросов и дожидается завершения текущих
+func (s *Server) BeforeStop(ctx context.Context) error {
+	if !s.cfg.Enabled || s.listener == nil {
+		return nil
+	}
+	s.SetReady(false)
+
+	s.log.Info("Stopping HTTP server")
+	if err := s.server.Shutdown(ctx); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			s.server.Close()
+		}
+		return err
+	}
+	return nil
+}
+
+// tlsEnabled сообщает, заданы ли файлы TLS
+func (s *Server) tlsEnabled() bool {
+	return s.cfg.CertFile != "" && s.cfg.KeyFile != ""
+}
+
+// gate отвечает 503, пока сервер не готов
+func (s *Server) gate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.IsReady() {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "service is not ready", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
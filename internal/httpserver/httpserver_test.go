@@ -0,0 +1,105 @@
+package httpserver
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+)
+
+// setupTestServer создает и запускает тестовый сервер
+func setupTestServer(t *testing.T) (*Server, func()) {
+	log, err := logger.New("test-http", t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	srv := New(log, metrics.New(log, true, "127.0.0.1:0"), Config{Enabled: true, Listen: "127.0.0.1:0"})
+	srv.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.PathValue("id"))
+	})
+	srv.HandleFunc("GET /panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	if err := srv.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+	return srv, func() {
+		srv.BeforeStop(context.Background())
+		log.Close()
+	}
+}
+
+// get выполняет GET запрос и возвращает код и тело ответа
+func get(t *testing.T, srv *Server, path string) (int, string) {
+	t.Helper()
+	client := &http.Client{Timeout: time.Second}
+	resp, err := client.Get("http://" + srv.GetAddress() + path)
+	if err != nil {
+		t.Fatalf("GET %s error = %v", path, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+// TestReadinessGate проверяет ответ 503 до готовности сервера
+func TestReadinessGate(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	if code, _ := get(t, srv, "/items/1"); code != http.StatusServiceUnavailable {
+		t.Errorf("GET before ready = %d, want 503", code)
+	}
+
+	srv.SetReady(true)
+	if code, body := get(t, srv, "/items/42"); code != http.StatusOK || body != "42" {
+		t.Errorf("GET after ready = %d %q, want 200 42", code, body)
+	}
+}
+
+// TestPanicRecovery проверяет перехват panic обработчика
+func TestPanicRecovery(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+	srv.SetReady(true)
+
+	if code, _ := get(t, srv, "/panic"); code != http.StatusInternalServerError {
+		t.Errorf("GET /panic = %d, want 500", code)
+	}
+	// Сервер продолжает работать
+	if code, _ := get(t, srv, "/items/1"); code != http.StatusOK {
+		t.Errorf("GET after panic = %d, want 200", code)
+	}
+}
+
+// TestStop проверяет остановку и отключенный сервер
+func TestStop(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+	srv.SetReady(true)
+
+	if err := srv.BeforeStop(context.Background()); err != nil {
+		t.Fatalf("BeforeStop() error = %v", err)
+	}
+	if srv.IsReady() {
+		t.Error("IsReady() after stop = true")
+	}
+	if _, err := (&http.Client{Timeout: time.Second}).Get("http://" + srv.GetAddress() + "/items/1"); err == nil {
+		t.Error("GET after stop expected connection error")
+	}
+
+	log, _ := logger.New("test-http", t.TempDir())
+	defer log.Close()
+	disabled := New(log, nil, Config{})
+	if err := disabled.AfterStart(context.Background()); err != nil {
+		t.Errorf("AfterStart() disabled error = %v", err)
+	}
+	if err := disabled.BeforeStop(context.Background()); err != nil {
+		t.Errorf("BeforeStop() disabled error = %v", err)
+	}
+}
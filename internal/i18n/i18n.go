@@ -0,0 +1,115 @@
+// Package i18n каталог сообщений для пользовательского вывода CLI и записей
+// Windows Event Log. JSON лог и ответы API не переводятся: их разбирают
+// программы, а не люди
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Locale язык каталога сообщений
+type Locale string
+
+const (
+	English Locale = "en"
+	Russian Locale = "ru"
+)
+
+// Key идентификатор сообщения каталога
+type Key string
+
+// catalogs сообщения по языкам. Английский каталог полный и используется
+// для ключей, которых нет в выбранном языке
+var catalogs = map[Locale]map[Key]string{
+	English: english,
+	Russian: russian,
+}
+
+// eventCatalogs переводы сообщений лога для Event Log по языкам.
+// Ключ - исходное (английское) сообщение лога
+var eventCatalogs = map[Locale]map[string]string{
+	Russian: russianEvents,
+}
+
+var (
+	mu      sync.RWMutex
+	current = English
+)
+
+// Parse преобразует значение locale (en, ru_RU.UTF-8, ru-RU) в язык каталога
+func Parse(s string) (Locale, error) {
+	lang := strings.ToLower(strings.TrimSpace(s))
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	switch lang {
+	case "en", "c", "posix":
+		return English, nil
+	case "ru":
+		return Russian, nil
+	default:
+		return "", fmt.Errorf("unsupported locale: %s (supported: en, ru)", s)
+	}
+}
+
+// Detect определяет язык по LC_ALL, LC_MESSAGES и LANG, а если они
+// не заданы - по языку интерфейса ОС. Неизвестный язык - English
+func Detect() Locale {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(env); value != "" {
+			if l, err := Parse(value); err == nil {
+				return l
+			}
+			return English
+		}
+	}
+	if l, err := Parse(systemLocale()); err == nil {
+		return l
+	}
+	return English
+}
+
+// SetLocale выбирает язык сообщений
+func SetLocale(l Locale) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = l
+}
+
+// Current возвращает выбранный язык сообщений
+func Current() Locale {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// T возвращает сообщение key на выбранном языке, подставляя args как fmt.Sprintf
+func T(key Key, args ...interface{}) string {
+	return Current().T(key, args...)
+}
+
+// T возвращает сообщение key на языке l
+func (l Locale) T(key Key, args ...interface{}) string {
+	format, ok := catalogs[l][key]
+	if !ok {
+		if format, ok = english[key]; !ok {
+			format = string(key)
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Event переводит сообщение лога для Event Log на выбранный язык.
+// Сообщения без перевода возвращаются без изменений
+func Event(msg string) string {
+	if text, ok := eventCatalogs[Current()][msg]; ok {
+		return text
+	}
+	return msg
+}
@@ -0,0 +1,88 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCatalogs проверяет, что каталоги содержат одинаковые ключи
+// и одинаковое количество подстановок
+func TestCatalogs(t *testing.T) {
+	for locale, catalog := range catalogs {
+		for key, format := range english {
+			text, ok := catalog[key]
+			if !ok {
+				t.Errorf("%s: missing message %s", locale, key)
+				continue
+			}
+			if strings.Count(text, "%") != strings.Count(format, "%") ||
+				strings.Count(text, "\t") != strings.Count(format, "\t") {
+				t.Errorf("%s: message %s = %q does not match %q", locale, key, text, format)
+			}
+		}
+		for key := range catalog {
+			if _, ok := english[key]; !ok {
+				t.Errorf("%s: unknown message %s", locale, key)
+			}
+		}
+	}
+}
+
+// TestT проверяет выбор языка и подстановку аргументов
+func TestT(t *testing.T) {
+	defer SetLocale(Current())
+
+	SetLocale(English)
+	if got := T(TimerResult, "cleanup", "ok", 12); got != "Timer cleanup: ok (12ms)" {
+		t.Errorf("T(en) = %q", got)
+	}
+	SetLocale(Russian)
+	if got := T(TimerResult, "cleanup", "ok", 12); got != "Таймер cleanup: ok (12 мс)" {
+		t.Errorf("T(ru) = %q", got)
+	}
+	if got := T(Key("unknown.key")); got != "unknown.key" {
+		t.Errorf("T(unknown) = %q, want key", got)
+	}
+	if got := Event("Timer panic recovered"); got != "Перехвачен panic таймера" {
+		t.Errorf("Event(ru) = %q", got)
+	}
+	if got := Event("Custom message"); got != "Custom message" {
+		t.Errorf("Event(untranslated) = %q", got)
+	}
+}
+
+// TestParse проверяет разбор значений locale
+func TestParse(t *testing.T) {
+	tests := map[string]Locale{
+		"en":          English,
+		"ru":          Russian,
+		"ru_RU.UTF-8": Russian,
+		"ru-RU":       Russian,
+		"EN_us":       English,
+		"C":           English,
+		"POSIX":       English,
+	}
+	for input, want := range tests {
+		if got, err := Parse(input); err != nil || got != want {
+			t.Errorf("Parse(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	if _, err := Parse("de_DE"); err == nil {
+		t.Error("Parse(de_DE) expected error")
+	}
+}
+
+// TestDetect проверяет определение языка по переменным окружения
+func TestDetect(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "ru_RU.UTF-8")
+	t.Setenv("LANG", "en_US.UTF-8")
+	if got := Detect(); got != Russian {
+		t.Errorf("Detect() = %q, want ru", got)
+	}
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "de_DE.UTF-8")
+	if got := Detect(); got != English {
+		t.Errorf("Detect() with unsupported LANG = %q, want en", got)
+	}
+}
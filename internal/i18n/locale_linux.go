@@ -0,0 +1,6 @@
+package i18n
+
+// systemLocale на Linux язык задается только переменными окружения
+func systemLocale() string {
+	return ""
+}
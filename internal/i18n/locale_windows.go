@@ -0,0 +1,17 @@
+//go:build windows
+// +build windows
+
+package i18n
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// systemLocale возвращает первый предпочитаемый язык интерфейса (ru-RU)
+func systemLocale() string {
+	langs, err := windows.GetUserPreferredUILanguages(windows.MUI_LANGUAGE_NAME)
+	if err != nil || len(langs) == 0 {
+		return ""
+	}
+	return langs[0]
+}
@@ -0,0 +1,61 @@
+package i18n
+
+// Сообщения CLI
+const (
+	CLIError           Key = "cli.error"
+	StartupCheckPassed Key = "check.passed"
+	SecretKeyCreated   Key = "secrets.key_created"
+	TimerResult        Key = "trigger.result"
+	TimersHeader       Key = "timers.header"
+	StepOK             Key = "step.ok"
+	StepCreated        Key = "step.created"
+	StepRemoved        Key = "step.removed"
+	StepSkipped        Key = "step.skipped"
+	StepWarning        Key = "step.warning"
+	StepFailed         Key = "step.failed"
+	StatusService      Key = "status.service"
+	StatusInstance     Key = "status.instance"
+	StatusPID          Key = "status.pid"
+	StatusUptime       Key = "status.uptime"
+	StatusLogLevel     Key = "status.log_level"
+	StatusTimers       Key = "status.timers"
+	StatusReload       Key = "status.reload"
+	ScheduleHeader     Key = "schedule.header"
+	HistoryHeader      Key = "history.header"
+	AuditHeader        Key = "audit.header"
+	ScaffoldCreated    Key = "scaffold.created"
+	HintConfig         Key = "hint.config"
+	HintPortInUse      Key = "hint.port_in_use"
+	HintPermission     Key = "hint.permission"
+	HintDependency     Key = "hint.dependency"
+)
+
+// english английский каталог
+var english = map[Key]string{
+	CLIError:           "Error: %v",
+	StartupCheckPassed: "Startup check passed",
+	SecretKeyCreated:   "Secret key created: %s",
+	TimerResult:        "Timer %s: %s (%dms)",
+	TimersHeader:       "NAME\tINTERVAL\tLAST RUN\tNEXT RUN\tPANICS\tSTATE",
+	StepOK:             "ok",
+	StepCreated:        "created",
+	StepRemoved:        "removed",
+	StepSkipped:        "skipped",
+	StepWarning:        "warning",
+	StepFailed:         "failed",
+	StatusService:      "Service:\t%s %s",
+	StatusInstance:     "Instance:\t%s (%s)",
+	StatusPID:          "PID:\t%d",
+	StatusUptime:       "Uptime:\t%s",
+	StatusLogLevel:     "Log level:\t%s",
+	StatusTimers:       "Timers:\t%d (%d paused)",
+	StatusReload:       "Last reload:\t%s %s",
+	ScheduleHeader:     "TIME\tTIMER\tINTERVAL",
+	HistoryHeader:      "START\tDURATION\tOUTCOME\tERROR",
+	AuditHeader:        "TIME\tACTOR\tSOURCE\tACTION\tTARGET\tRESULT",
+	ScaffoldCreated:    "Project %s created in %s (%d files). Next: cd %s && go mod tidy && go build ./...",
+	HintConfig:         "Hint: fix the configuration and verify it with the check command",
+	HintPortInUse:      "Hint: %s cannot listen on %s; stop the other instance or change the listen address",
+	HintPermission:     "Hint: no access to %s; run as a user with access or fix the permissions",
+	HintDependency:     "Hint: task %s is waiting for %s; make sure it is reachable or raise startup.dependency_timeout_seconds",
+}
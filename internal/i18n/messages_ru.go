@@ -0,0 +1,137 @@
+package i18n
+
+// russian русский каталог
+var russian = map[Key]string{
+	CLIError:           "Ошибка: %v",
+	StartupCheckPassed: "Проверка запуска пройдена",
+	SecretKeyCreated:   "Создан секретный ключ: %s",
+	TimerResult:        "Таймер %s: %s (%d мс)",
+	TimersHeader:       "ИМЯ\tИНТЕРВАЛ\tПОСЛЕДНИЙ ЗАПУСК\tСЛЕДУЮЩИЙ ЗАПУСК\tPANIC\tСОСТОЯНИЕ",
+	StepOK:             "ок",
+	StepCreated:        "создано",
+	StepRemoved:        "удалено",
+	StepSkipped:        "пропущено",
+	StepWarning:        "внимание",
+	StepFailed:         "ошибка",
+	StatusService:      "Сервис:\t%s %s",
+	StatusInstance:     "Экземпляр:\t%s (%s)",
+	StatusPID:          "PID:\t%d",
+	StatusUptime:       "Время работы:\t%s",
+	StatusLogLevel:     "Уровень лога:\t%s",
+	StatusTimers:       "Таймеры:\t%d (приостановлено: %d)",
+	StatusReload:       "Перезагрузка конфига:\t%s %s",
+	ScheduleHeader:     "ВРЕМЯ\tТАЙМЕР\tИНТЕРВАЛ",
+	HistoryHeader:      "НАЧАЛО\tДЛИТЕЛЬНОСТЬ\tРЕЗУЛЬТАТ\tОШИБКА",
+	AuditHeader:        "ВРЕМЯ\tОПЕРАТОР\tИСТОЧНИК\tДЕЙСТВИЕ\tОБЪЕКТ\tРЕЗУЛЬТАТ",
+	ScaffoldCreated:    "Проект %s создан в %s (файлов: %d). Далее: cd %s && go mod tidy && go build ./...",
+	HintConfig:         "Подсказка: исправьте конфигурацию и проверьте ее командой check",
+	HintPortInUse:      "Подсказка: %s не может занять %s; остановите другой экземпляр или измените адрес прослушивания",
+	HintPermission:     "Подсказка: нет доступа к %s; запустите от пользователя с доступом или исправьте права",
+	HintDependency:     "Подсказка: задача %s ожидает %s; проверьте ее доступность или увеличьте startup.dependency_timeout_seconds",
+}
+
+// russianEvents переводы сообщений лога уровня warn и выше для Event Log
+var russianEvents = map[string]string{
+	"Admin API unauthorized request":                                 "Неавторизованный запрос к admin API",
+	"Admin action: shutdown requested":                               "Admin API: запрошена остановка",
+	"Config reload failed, keeping current config":                   "Ошибка перезагрузки конфига, действует прежний",
+	"Control socket error":                                           "Ошибка локального канала управления",
+	"Admin server error":                                             "Ошибка admin сервера",
+	"Admin server listens on a non-loopback address without a token": "Admin сервер слушает внешний адрес без токена",
+	"Alert queue is full, dropping alert":                            "Очередь оповещений переполнена, оповещение отброшено",
+	"Application error":                                              "Ошибка приложения",
+	"Circuit breaker opened":                                         "Circuit breaker разомкнут",
+	"Environment configured for unknown timer":                       "Окружение задано для неизвестного таймера",
+	"Error starting leader task":                                     "Ошибка запуска задачи лидера",
+	"Error starting task, rolling back":                              "Ошибка запуска задачи, откат",
+	"Error stopping admin server":                                    "Ошибка остановки admin сервера",
+	"Error stopping gRPC control server":                             "Ошибка остановки gRPC сервера управления",
+	"Error stopping leader task":                                     "Ошибка остановки задачи лидера",
+	"Error stopping lifecycle tasks":                                 "Ошибка остановки задач",
+	"Error stopping metrics server":                                  "Ошибка остановки сервера метрик",
+	"Error stopping scheduler":                                       "Ошибка остановки планировщика",
+	"Error stopping task":                                            "Ошибка остановки задачи",
+	"Failed to capture runtime crashes":                              "Не удалось включить перехват падений runtime",
+	"Failed to collect profile":                                      "Не удалось снять профиль",
+	"Failed to contain process, descendants are not tracked":         "Не удалось поместить процесс в задание, потомки не отслеживаются",
+	"Failed to gather final metrics snapshot":                        "Не удалось снять итоговый снимок метрик",
+	"Failed to gather metrics for remote write":                      "Не удалось собрать метрики для remote write",
+	"Failed to install service":                                      "Не удалось установить сервис",
+	"Failed to load persisted instance ID, using a temporary one":    "Не удалось загрузить сохраненный идентификатор экземпляра, используется временный",
+	"Failed to load task state, starting cold":                       "Не удалось загрузить состояние задачи, задача запускается с чистого состояния",
+	"Failed to list existing files":                                  "Не удалось получить список файлов",
+	"Failed to load last known clock time":                           "Не удалось загрузить последнее известное время часов",
+	"Failed to persist instance ID":                                  "Не удалось сохранить идентификатор экземпляра",
+	"Failed to read cleanup manifest":                                "Не удалось прочитать манифест временных артефактов",
+	"Failed to read secret file":                                     "Не удалось прочитать файл секрета",
+	"Failed to reconfigure service":                                  "Не удалось изменить регистрацию сервиса",
+	"Failed to register metric":                                      "Не удалось зарегистрировать метрику",
+	"Failed to prepare config summary for crash reports":             "Не удалось подготовить конфигурацию для отчетов о падении",
+	"Failed to remove artifact":                                      "Не удалось удалить временный артефакт",
+	"Failed to remove stale artifact":                                "Не удалось удалить артефакт предыдущего запуска",
+	"Failed to restore scheduler state":                              "Не удалось восстановить состояние планировщика",
+	"Failed to restore timer history":                                "Не удалось восстановить историю запусков таймера",
+	"Failed to restore timer last run":                               "Не удалось восстановить время последнего запуска таймера",
+	"Failed to restore unfinished jobs":                              "Не удалось восстановить незавершенные задания",
+	"Failed to save last known clock time":                           "Не удалось сохранить последнее известное время часов",
+	"Failed to save scheduler state":                                 "Не удалось сохранить состояние планировщика",
+	"Failed to save timer history":                                   "Не удалось сохранить историю запусков таймера",
+	"Failed to save timer last run":                                  "Не удалось сохранить время последнего запуска таймера",
+	"Failed to save unfinished jobs":                                 "Не удалось сохранить незавершенные задания",
+	"Failed to send alert":                                           "Не удалось отправить оповещение",
+	"Failed to send final heartbeat":                                 "Не удалось отправить последний heartbeat",
+	"Failed to send heartbeat":                                       "Не удалось отправить heartbeat",
+	"Failed to signal process, killing":                              "Не удалось отправить сигнал процессу, процесс завершается принудительно",
+	"Failed to start performance counters provider":                  "Не удалось запустить провайдер счетчиков производительности",
+	"Failed to start service":                                        "Не удалось запустить сервис",
+	"Failed to stop service":                                         "Не удалось остановить сервис",
+	"Failed to uninstall service":                                    "Не удалось удалить сервис",
+	"Failed to write audit record":                                   "Не удалось записать действие в журнал действий",
+	"File watcher error":                                             "Ошибка наблюдения за файлами",
+	"HTTP client request failed":                                     "Ошибка запроса HTTP клиента",
+	"HTTP client request rejected by circuit breaker":                "Запрос HTTP клиента отклонен circuit breaker",
+	"HTTP client request will be retried":                            "Запрос HTTP клиента будет повторен",
+	"HTTP handler panic recovered":                                   "Перехвачен panic HTTP обработчика",
+	"HTTP server error":                                              "Ошибка HTTP сервера",
+	"Hook command failed":                                            "Внешняя команда hook завершилась ошибкой",
+	"Invalid health dependency":                                      "Некорректная внешняя зависимость проверки здоровья",
+	"Job failed, will retry":                                         "Задание завершилось ошибкой, будет повторено",
+	"Job moved to dead letter":                                       "Задание перемещено в dead letter",
+	"Job panic recovered":                                            "Перехвачен panic задания",
+	"Job queue stopped before draining":                              "Очередь заданий остановлена до завершения обработки",
+	"Leader election attempt failed":                                 "Ошибка попытки выбора лидера",
+	"Metric label cardinality limit reached":                         "Достигнут лимит значений метки метрики",
+	"Metrics server error":                                           "Ошибка сервера метрик",
+	"Previous run is still alive, skipping stale artifact cleanup":   "Предыдущий запуск еще работает, очистка артефактов пропущена",
+	"Process did not stop in time, killing":                          "Процесс не остановился вовремя, завершается принудительно",
+	"Process exited, restarting":                                     "Процесс завершился, перезапуск",
+	"Process limits are not supported on this platform":              "Ограничения процесса не поддерживаются на этой платформе",
+	"Read-only mode: state-mutating timers and tasks are disabled":   "Режим только для чтения: таймеры и задачи, изменяющие состояние, отключены",
+	"Reloaded config failed probation, rolled back":                  "Перезагруженная конфигурация не прошла испытательный срок и откачена",
+	"Remote write buffer is full, dropping oldest batch":             "Буфер remote write переполнен, самый старый батч отброшен",
+	"Remote write buffer lost on stop":                               "Неотправленные метрики remote write потеряны при остановке",
+	"Remote write endpoint rejected batch":                           "Endpoint remote write отклонил батч метрик",
+	"Remote write failed, keeping batch for the next push":           "Ошибка remote write, батч будет отправлен повторно",
+	"Restart requested":                                              "Запрошен перезапуск",
+	"Secret rotation hook failed":                                    "Обработчик смены секрета завершился с ошибкой",
+	"Shutdown requested":                                             "Запрошена остановка",
+	"Skipping corrupted stored job":                                  "Пропущено поврежденное сохраненное задание",
+	"Stale socket is in use, leaving it":                             "Сокет предыдущего запуска используется, он не удален",
+	"Startup check failed":                                           "Проверка запуска не пройдена",
+	"System clock is behind the last known time":                     "Системные часы отстают от последнего известного времени",
+	"System clock jumped":                                            "Скачок системных часов",
+	"Task abandoned during shutdown":                                 "Задача брошена при остановке: BeforeStop не завершился вовремя",
+	"Task check failed":                                              "Проверка задачи не пройдена",
+	"Task panic recovered":                                           "Перехвачен panic задачи",
+	"Timeout waiting for timers to stop":                             "Истекло время ожидания остановки таймеров",
+	"Timer exceeded max panic restarts, disabling":                   "Таймер превысил лимит перезапусков после panic и отключен",
+	"Timer is missing ticks, handler is slower than interval":        "Таймер пропускает тики: обработчик выполняется дольше интервала",
+	"Timer panic recovered":                                          "Перехвачен panic таймера",
+	"Timer run exceeded resource budget":                             "Запуск таймера превысил бюджет ресурсов",
+	"Timer throttled after repeatedly exceeding resource budget":     "Таймер замедлен: запуски подряд превышают бюджет ресурсов",
+	"Tracing error":                                                  "Ошибка трассировки",
+	"Unexpected control request":                                     "Неожиданный запрос управления",
+	"Watchdog detected sustained resource growth":                    "Watchdog обнаружил устойчивый рост потребления ресурсов",
+	"gRPC control server error":                                      "Ошибка gRPC сервера управления",
+	"gRPC control unauthorized call":                                 "Неавторизованный вызов gRPC управления",
+}
@@ -0,0 +1,547 @@
+// Package jobs предоставляет очередь заданий с пулом обработчиков,
+// повторными попытками и dead-letter списком. Планировщик решает, когда
+// ставить работу, очередь - сколько работы выполнять одновременно
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+	"service-boilerplate/internal/store"
+)
+
+// Job представляет одно задание в очереди
+type Job struct {
+	ID         string
+	Type       string
+	Payload    interface{}
+	Attempt    int
+	EnqueuedAt time.Time
+	LastError  string
+}
+
+// Handler обрабатывает задание определенного типа. Ошибка приводит
+// к повторной попытке или переносу в dead-letter список
+type Handler func(ctx context.Context, job *Job) error
+
+// Config содержит настройки очереди
+type Config struct {
+	// Workers количество одновременно выполняемых заданий
+	Workers int
+	// QueueSize максимальное количество ожидающих заданий
+	QueueSize int
+	// MaxAttempts максимальное количество попыток выполнения задания
+	MaxAttempts int
+	// RetryBackoff задержка перед первой повторной попыткой, далее удваивается
+	RetryBackoff time.Duration
+	// DeadLetterSize сколько последних неудачных заданий хранить
+	DeadLetterSize int
+}
+
+// Результаты попытки выполнения для метрик
+const (
+	ResultSuccess    = "success"
+	ResultRetry      = "retry"
+	ResultDeadLetter = "dead_letter"
+)
+
+var (
+	// ErrUnknownType для типа задания не зарегистрирован обработчик
+	ErrUnknownType = errors.New("unknown job type")
+	// ErrQueueFull очередь заполнена
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrClosed очередь остановлена и не принимает задания
+	ErrClosed = errors.New("job queue is closed")
+)
+
+// pendingBucket bucket хранилища с незавершенными при остановке заданиями
+const pendingBucket = "jobs.pending"
+
+// maxBackoffShift ограничивает рост задержки между попытками (RetryBackoff * 2^5)
+const maxBackoffShift = 5
+
+// Stats снимок состояния очереди
+type Stats struct {
+	Queued      int
+	InFlight    int
+	Retrying    int
+	DeadLetters int
+}
+
+// Queue очередь заданий. Реализует task.Task: обработчики запускаются
+// в AfterStart и останавливаются в BeforeStop
+type Queue struct {
+	cfg     Config
+	log     *logger.Logger
+	metrics *metrics.Server
+	store   *store.Store
+
+	mu          sync.Mutex
+	handlers    map[string]Handler
+	queue       chan *Job
+	retrying    map[*Job]*time.Timer
+	deadLetters []Job
+	unfinished  []*Job
+	closed      bool
+	seq         uint64
+
+	inFlight int32
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// New создает новую очередь заданий
+func New(log *logger.Logger, metricsServer *metrics.Server, cfg Config) *Queue {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+	return &Queue{
+		cfg:      cfg,
+		log:      log,
+		metrics:  metricsServer,
+		handlers: make(map[string]Handler),
+		queue:    make(chan *Job, cfg.QueueSize),
+		retrying: make(map[*Job]*time.Timer),
+	}
+}
+
+// SetStore включает сохранение незавершенных заданий при остановке.
+// Сохраненные задания возвращаются в очередь при следующем запуске,
+// их Payload восстанавливается как json.RawMessage
+func (q *Queue) SetStore(st *store.Store) {
+	q.store = st
+}
+
+// Register регистрирует обработчик для типа заданий
+func (q *Queue) Register(jobType string, handler Handler) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, exists := q.handlers[jobType]; exists {
+		return fmt.Errorf("job type %s already registered", jobType)
+	}
+	q.handlers[jobType] = handler
+	return nil
+}
+
+// Enqueue ставит задание в очередь и возвращает его идентификатор
+func (q *Queue) Enqueue(jobType string, payload interface{}) (string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return "", ErrClosed
+	}
+	if _, ok := q.handlers[jobType]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownType, jobType)
+	}
+
+	q.seq++
+	job := &Job{
+		ID:         strconv.FormatUint(q.seq, 10),
+		Type:       jobType,
+		Payload:    payload,
+		EnqueuedAt: time.Now(),
+	}
+
+	select {
+	case q.queue <- job:
+	default:
+		return "", ErrQueueFull
+	}
+
+	if q.metrics != nil {
+		q.metrics.RecordJobEnqueued(jobType)
+		q.metrics.SetJobQueueDepth(len(q.queue))
+	}
+	return job.ID, nil
+}
+
+// Name возвращает имя задачи
+func (q *Queue) Name() string {
+	return "jobs"
+}
+
+// AfterStart запускает пул обработчиков
+func (q *Queue) AfterStart(ctx context.Context) error {
+	q.restore()
+
+	runCtx, cancel := context.WithCancel(ctx)
+	q.cancel = cancel
+
+	for i := 0; i < q.cfg.Workers; i++ {
+		q.wg.Add(1)
+		go q.worker(runCtx)
+	}
+
+	q.log.Info("Job queue started", map[string]interface{}{
+		"workers":      q.cfg.Workers,
+		"queue_size":   q.cfg.QueueSize,
+		"max_attempts": q.cfg.MaxAttempts,
+	})
+	return nil
+}
+
+// BeforeStop прекращает прием заданий и дожидается обработки очереди.
+// Если ctx истекает раньше, выполняемые задания отменяются. Отложенные
+// повторы и прерванные задания сохраняются в хранилище, а без него
+// переносятся в dead-letter список
+func (q *Queue) BeforeStop(ctx context.Context) error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	for job, timer := range q.retrying {
+		timer.Stop()
+		delete(q.retrying, job)
+		q.unfinished = append(q.unfinished, job)
+	}
+	close(q.queue)
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+		q.log.Warn("Job queue stopped before draining", map[string]interface{}{"error": err.Error()})
+	}
+	if q.cancel != nil {
+		q.cancel()
+	}
+	<-done
+
+	q.mu.Lock()
+	unfinished := q.unfinished
+	q.unfinished = nil
+	q.mu.Unlock()
+	q.persist(unfinished)
+
+	if err != nil {
+		return err
+	}
+	q.log.Info("Job queue stopped")
+	return nil
+}
+
+// worker обрабатывает задания из очереди до ее закрытия
+func (q *Queue) worker(ctx context.Context) {
+	defer q.wg.Done()
+	for job := range q.queue {
+		// После отмены оставшиеся задания не выполняются, а откладываются
+		if ctx.Err() != nil {
+			q.interrupt(job)
+			continue
+		}
+		if q.metrics != nil {
+			q.metrics.SetJobQueueDepth(len(q.queue))
+		}
+		q.process(ctx, job)
+	}
+}
+
+// process выполняет одну попытку задания
+func (q *Queue) process(ctx context.Context, job *Job) {
+	q.mu.Lock()
+	handler := q.handlers[job.Type]
+	q.mu.Unlock()
+
+	job.Attempt++
+	if handler == nil {
+		// Задание восстановлено из хранилища, но обработчик больше не зарегистрирован
+		job.LastError = fmt.Sprintf("%v: %s", ErrUnknownType, job.Type)
+		q.mu.Lock()
+		q.deadLetterLocked(job)
+		q.mu.Unlock()
+		q.record(job.Type, ResultDeadLetter)
+		return
+	}
+	atomic.AddInt32(&q.inFlight, 1)
+	err := q.call(ctx, handler, job)
+	atomic.AddInt32(&q.inFlight, -1)
+
+	if err == nil {
+		q.record(job.Type, ResultSuccess)
+		return
+	}
+	job.LastError = err.Error()
+
+	// Задание прервано остановкой: попытка не засчитывается
+	if ctx.Err() != nil {
+		job.Attempt--
+		q.interrupt(job)
+		return
+	}
+
+	fields := map[string]interface{}{
+		"job_id":   job.ID,
+		"job_type": job.Type,
+		"attempt":  job.Attempt,
+		"error":    err.Error(),
+	}
+
+	if job.Attempt < q.cfg.MaxAttempts {
+		q.scheduleRetry(job)
+		q.record(job.Type, ResultRetry)
+		q.log.Warn("Job failed, will retry", fields)
+		return
+	}
+
+	q.mu.Lock()
+	q.deadLetterLocked(job)
+	q.mu.Unlock()
+	q.record(job.Type, ResultDeadLetter)
+	q.log.Error("Job moved to dead letter", fields)
+}
+
+// interrupt откладывает задание, не выполненное из-за остановки
+func (q *Queue) interrupt(job *Job) {
+	q.mu.Lock()
+	q.unfinished = append(q.unfinished, job)
+	q.mu.Unlock()
+}
+
+// storedJob представление задания в хранилище
+type storedJob struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	Attempt    int             `json:"attempt"`
+	EnqueuedAt time.Time       `json:"enqueued_at"`
+	LastError  string          `json:"last_error,omitempty"`
+}
+
+// persist сохраняет незавершенные задания в хранилище. Задания, которые
+// не удалось сохранить, переносятся в dead-letter список
+func (q *Queue) persist(unfinished []*Job) {
+	if len(unfinished) == 0 {
+		return
+	}
+
+	items := make(map[string][]byte, len(unfinished))
+	var failed []*Job
+	for _, job := range unfinished {
+		if q.store == nil {
+			failed = append(failed, job)
+			continue
+		}
+		data, err := encodeJob(job)
+		if err != nil {
+			job.LastError = err.Error()
+			failed = append(failed, job)
+			continue
+		}
+		items[job.ID] = data
+	}
+
+	if len(items) > 0 {
+		if err := q.store.Replace(pendingBucket, items); err != nil {
+			q.log.Error("Failed to save unfinished jobs", map[string]interface{}{"error": err.Error()})
+			failed = unfinished
+		} else {
+			q.log.Info("Unfinished jobs saved", map[string]interface{}{"count": len(items)})
+		}
+	}
+
+	q.mu.Lock()
+	for _, job := range failed {
+		q.deadLetterLocked(job)
+	}
+	q.mu.Unlock()
+	for _, job := range failed {
+		q.record(job.Type, ResultDeadLetter)
+	}
+}
+
+// encodeJob кодирует задание для хранилища
+func encodeJob(job *Job) ([]byte, error) {
+	stored := storedJob{
+		ID:         job.ID,
+		Type:       job.Type,
+		Attempt:    job.Attempt,
+		EnqueuedAt: job.EnqueuedAt,
+		LastError:  job.LastError,
+	}
+	if job.Payload != nil {
+		payload, err := json.Marshal(job.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode payload: %w", err)
+		}
+		stored.Payload = payload
+	}
+	return json.Marshal(stored)
+}
+
+// restore возвращает в очередь задания, сохраненные при прошлой остановке
+func (q *Queue) restore() {
+	if q.store == nil {
+		return
+	}
+
+	var restored []*Job
+	err := q.store.ForEach(pendingBucket, func(key string, value []byte) error {
+		var stored storedJob
+		if err := json.Unmarshal(value, &stored); err != nil {
+			q.log.Warn("Skipping corrupted stored job", map[string]interface{}{"job_id": key, "error": err.Error()})
+			return nil
+		}
+		job := &Job{
+			ID:         stored.ID,
+			Type:       stored.Type,
+			Attempt:    stored.Attempt,
+			EnqueuedAt: stored.EnqueuedAt,
+			LastError:  stored.LastError,
+		}
+		if stored.Payload != nil {
+			job.Payload = stored.Payload
+		}
+		restored = append(restored, job)
+		return nil
+	})
+	if err == nil {
+		err = q.store.Replace(pendingBucket, nil)
+	}
+	if err != nil {
+		q.log.Error("Failed to restore unfinished jobs", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	if len(restored) == 0 {
+		return
+	}
+	sort.Slice(restored, func(i, j int) bool { return restored[i].EnqueuedAt.Before(restored[j].EnqueuedAt) })
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, job := range restored {
+		if id, err := strconv.ParseUint(job.ID, 10, 64); err == nil && id > q.seq {
+			q.seq = id
+		}
+		select {
+		case q.queue <- job:
+		default:
+			job.LastError = ErrQueueFull.Error()
+			q.deadLetterLocked(job)
+		}
+	}
+	q.log.Info("Unfinished jobs restored", map[string]interface{}{"count": len(restored)})
+}
+
+// call вызывает обработчик с защитой от panic
+func (q *Queue) call(ctx context.Context, handler Handler, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			q.log.Error("Job panic recovered", map[string]interface{}{
+				"job_id":     job.ID,
+				"job_type":   job.Type,
+				"panic":      r,
+				"stacktrace": string(debug.Stack()),
+			})
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// scheduleRetry откладывает повторную попытку. Если очередь уже
+// остановлена, задание откладывается до следующего запуска
+func (q *Queue) scheduleRetry(job *Job) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		q.unfinished = append(q.unfinished, job)
+		return
+	}
+
+	shift := job.Attempt - 1
+	if shift > maxBackoffShift {
+		shift = maxBackoffShift
+	}
+	delay := q.cfg.RetryBackoff << shift
+
+	q.retrying[job] = time.AfterFunc(delay, func() { q.requeue(job) })
+}
+
+// requeue возвращает задание в очередь после задержки
+func (q *Queue) requeue(job *Job) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.retrying[job]; !ok {
+		// Остановка уже перенесла задание в dead-letter список
+		return
+	}
+	delete(q.retrying, job)
+
+	select {
+	case q.queue <- job:
+		if q.metrics != nil {
+			q.metrics.SetJobQueueDepth(len(q.queue))
+		}
+	default:
+		job.LastError = ErrQueueFull.Error()
+		q.deadLetterLocked(job)
+		q.record(job.Type, ResultDeadLetter)
+	}
+}
+
+// deadLetterLocked добавляет задание в dead-letter список, вытесняя самые старые
+func (q *Queue) deadLetterLocked(job *Job) {
+	if q.cfg.DeadLetterSize <= 0 {
+		return
+	}
+	q.deadLetters = append(q.deadLetters, *job)
+	if over := len(q.deadLetters) - q.cfg.DeadLetterSize; over > 0 {
+		q.deadLetters = append([]Job(nil), q.deadLetters[over:]...)
+	}
+}
+
+// record записывает метрику результата попытки
+func (q *Queue) record(jobType, result string) {
+	if q.metrics != nil {
+		q.metrics.RecordJobProcessed(jobType, result)
+	}
+}
+
+// DeadLetters возвращает копию dead-letter списка, от старых к новым
+func (q *Queue) DeadLetters() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]Job(nil), q.deadLetters...)
+}
+
+// Stats возвращает снимок состояния очереди
+func (q *Queue) Stats() Stats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return Stats{
+		Queued:      len(q.queue),
+		InFlight:    int(atomic.LoadInt32(&q.inFlight)),
+		Retrying:    len(q.retrying),
+		DeadLetters: len(q.deadLetters),
+	}
+}
@@ -0,0 +1,196 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+	"service-boilerplate/internal/store"
+)
+
+// setupTestQueue создает тестовую очередь
+func setupTestQueue(t *testing.T, cfg Config) (*Queue, *logger.Logger) {
+	log, err := logger.New("test-jobs", t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	return New(log, metrics.New(log, false, ""), cfg), log
+}
+
+// waitFor ожидает выполнения условия
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("condition not met in time")
+}
+
+// TestEnqueue_Success проверяет выполнение заданий пулом обработчиков
+func TestEnqueue_Success(t *testing.T) {
+	q, log := setupTestQueue(t, Config{Workers: 2, QueueSize: 10})
+	defer log.Close()
+
+	var done int32
+	q.Register("count", func(ctx context.Context, job *Job) error {
+		atomic.AddInt32(&done, int32(job.Payload.(int)))
+		return nil
+	})
+
+	if err := q.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := q.Enqueue("count", 1); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+
+	waitFor(t, func() bool { return atomic.LoadInt32(&done) == 5 })
+
+	if err := q.BeforeStop(context.Background()); err != nil {
+		t.Errorf("BeforeStop() error = %v", err)
+	}
+	if _, err := q.Enqueue("count", 1); !errors.Is(err, ErrClosed) {
+		t.Errorf("Enqueue() after stop error = %v, want ErrClosed", err)
+	}
+}
+
+// TestEnqueue_Errors проверяет ошибки постановки в очередь
+func TestEnqueue_Errors(t *testing.T) {
+	q, log := setupTestQueue(t, Config{QueueSize: 1})
+	defer log.Close()
+
+	q.Register("noop", func(ctx context.Context, job *Job) error { return nil })
+
+	if _, err := q.Enqueue("missing", nil); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("Enqueue(missing) error = %v, want ErrUnknownType", err)
+	}
+	// Обработчики не запущены: вторая постановка переполняет очередь
+	if _, err := q.Enqueue("noop", nil); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if _, err := q.Enqueue("noop", nil); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Enqueue() error = %v, want ErrQueueFull", err)
+	}
+	if err := q.Register("noop", nil); err == nil {
+		t.Error("Register() duplicate expected error")
+	}
+}
+
+// TestRetryAndDeadLetter проверяет повторные попытки и dead-letter список
+func TestRetryAndDeadLetter(t *testing.T) {
+	q, log := setupTestQueue(t, Config{Workers: 1, QueueSize: 10, MaxAttempts: 3, RetryBackoff: time.Millisecond, DeadLetterSize: 10})
+	defer log.Close()
+
+	var attempts int32
+	q.Register("flaky", func(ctx context.Context, job *Job) error {
+		// Успех с третьей попытки
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			return errors.New("temporary")
+		}
+		return nil
+	})
+	q.Register("broken", func(ctx context.Context, job *Job) error {
+		panic("boom")
+	})
+
+	q.AfterStart(context.Background())
+	defer q.BeforeStop(context.Background())
+
+	q.Enqueue("flaky", nil)
+	waitFor(t, func() bool { return atomic.LoadInt32(&attempts) == 3 })
+
+	id, _ := q.Enqueue("broken", nil)
+	waitFor(t, func() bool { return q.Stats().DeadLetters == 1 })
+
+	dl := q.DeadLetters()
+	if dl[0].ID != id || dl[0].Attempt != 3 || dl[0].LastError == "" {
+		t.Errorf("DeadLetters()[0] = %+v", dl[0])
+	}
+}
+
+// TestBeforeStop_Timeout проверяет отмену выполняемых заданий по таймауту
+func TestBeforeStop_Timeout(t *testing.T) {
+	q, log := setupTestQueue(t, Config{Workers: 1, QueueSize: 10})
+	defer log.Close()
+
+	started := make(chan struct{})
+	q.Register("slow", func(ctx context.Context, job *Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	q.AfterStart(context.Background())
+	q.Enqueue("slow", nil)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := q.BeforeStop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("BeforeStop() error = %v, want DeadlineExceeded", err)
+	}
+}
+
+// TestPersistUnfinished проверяет сохранение незавершенных заданий и их восстановление
+func TestPersistUnfinished(t *testing.T) {
+	q, log := setupTestQueue(t, Config{Workers: 1, QueueSize: 10, MaxAttempts: 3, RetryBackoff: time.Hour})
+	defer log.Close()
+
+	st := store.New(log, filepath.Join(t.TempDir(), "state.db"))
+	if err := st.Open(); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer st.Close()
+	q.SetStore(st)
+
+	q.Register("mail", func(ctx context.Context, job *Job) error {
+		return errors.New("smtp unavailable")
+	})
+	q.AfterStart(context.Background())
+	id, _ := q.Enqueue("mail", map[string]string{"to": "ops"})
+	waitFor(t, func() bool { return q.Stats().Retrying == 1 })
+	q.BeforeStop(context.Background())
+
+	if dl := q.DeadLetters(); len(dl) != 0 {
+		t.Fatalf("DeadLetters() = %+v, want none", dl)
+	}
+
+	// Новая очередь, как после перезапуска сервиса
+	restarted, _ := setupTestQueue(t, Config{Workers: 1, QueueSize: 10})
+	restarted.SetStore(st)
+	got := make(chan *Job, 1)
+	restarted.Register("mail", func(ctx context.Context, job *Job) error {
+		got <- job
+		return nil
+	})
+	restarted.AfterStart(context.Background())
+	defer restarted.BeforeStop(context.Background())
+
+	select {
+	case job := <-got:
+		var payload map[string]string
+		json.Unmarshal(job.Payload.(json.RawMessage), &payload)
+		if job.ID != id || job.Attempt != 2 || payload["to"] != "ops" {
+			t.Errorf("restored job = %+v, payload %v", job, payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("restored job was not processed")
+	}
+
+	// Новые задания не повторяют идентификаторы восстановленных
+	if next, _ := restarted.Enqueue("mail", nil); next == id {
+		t.Errorf("Enqueue() id = %s, duplicates restored job", next)
+	}
+}
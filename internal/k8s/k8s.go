@@ -0,0 +1,81 @@
+// Package k8s определяет под Kubernetes, в котором запущен сервис:
+// имя, пространство имен и узел из Downward API (переменные окружения
+// или файлы тома)
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Переменные окружения Downward API (fieldRef metadata.name,
+// metadata.namespace, spec.nodeName)
+const (
+	EnvPodName      = "POD_NAME"
+	EnvPodNamespace = "POD_NAMESPACE"
+	EnvNodeName     = "NODE_NAME"
+)
+
+// serviceAccountNamespace файл пространства имен смонтированного
+// токена service account, есть почти в каждом поде
+var serviceAccountNamespace = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// PodInfo описывает под. Неопределенные значения пустые
+type PodInfo struct {
+	Name      string
+	Namespace string
+	Node      string
+}
+
+// Detect определяет под по переменным окружения Downward API, затем по
+// файлам name, namespace и node_name тома Downward API в dir. Имя пода
+// по умолчанию - имя узла контейнера (hostname), пространство имен -
+// пространство имен service account
+func Detect(dir string) PodInfo {
+	p := PodInfo{
+		Name:      lookup(EnvPodName, dir, "name"),
+		Namespace: lookup(EnvPodNamespace, dir, "namespace"),
+		Node:      lookup(EnvNodeName, dir, "node_name"),
+	}
+	if p.Name == "" {
+		p.Name, _ = os.Hostname()
+	}
+	if p.Namespace == "" {
+		p.Namespace = readValue(serviceAccountNamespace)
+	}
+	return p
+}
+
+// Labels возвращает метки пода для логов и метрик (k8s_pod, k8s_namespace,
+// k8s_node). Префикс не дает им совпасть с метками метрик, например
+// namespace пространства имен таймеров. Пустые значения пропускаются
+func (p PodInfo) Labels() map[string]string {
+	labels := make(map[string]string, 3)
+	for name, value := range map[string]string{"k8s_pod": p.Name, "k8s_namespace": p.Namespace, "k8s_node": p.Node} {
+		if value != "" {
+			labels[name] = value
+		}
+	}
+	return labels
+}
+
+// lookup возвращает значение переменной env или файла file в dir
+func lookup(env, dir, file string) string {
+	if v := strings.TrimSpace(os.Getenv(env)); v != "" {
+		return v
+	}
+	if dir == "" {
+		return ""
+	}
+	return readValue(filepath.Join(dir, file))
+}
+
+// readValue возвращает содержимое файла без пробелов по краям
+func readValue(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,39 @@
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestDetect проверяет приоритет переменных окружения над файлами
+// тома Downward API и значения по умолчанию
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+	serviceAccountNamespace = filepath.Join(dir, "sa-namespace")
+	for file, value := range map[string]string{"name": "web-7d9f\n", "namespace": "prod\n", "sa-namespace": "default"} {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(value), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv(EnvPodName, "")
+	t.Setenv(EnvPodNamespace, "")
+	t.Setenv(EnvNodeName, "node-1")
+
+	p := Detect(dir)
+	if p.Name != "web-7d9f" || p.Namespace != "prod" || p.Node != "node-1" {
+		t.Errorf("Detect() = %+v", p)
+	}
+
+	t.Setenv(EnvPodName, "web-abc")
+	os.Remove(filepath.Join(dir, "namespace"))
+	p = Detect(dir)
+	if p.Name != "web-abc" || p.Namespace != "default" {
+		t.Errorf("Detect() = %+v, want env pod name and service account namespace", p)
+	}
+
+	labels := PodInfo{Name: "web-abc", Namespace: "prod"}.Labels()
+	if len(labels) != 2 || labels["k8s_pod"] != "web-abc" || labels["k8s_namespace"] != "prod" {
+		t.Errorf("Labels() = %v", labels)
+	}
+}
@@ -0,0 +1,90 @@
+package lifecycle
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Операции lifecycle для TaskError
+const (
+	OpStart   = "start"
+	OpStop    = "stop"
+	OpCheck   = "check"
+	OpRestore = "restore"
+)
+
+// TaskError описывает ошибку конкретной задачи
+type TaskError struct {
+	Task string
+	Op   string
+	Err  error
+}
+
+// Error реализует интерфейс error
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("failed to %s task %s: %v", e.Op, e.Task, e.Err)
+}
+
+// Unwrap возвращает исходную ошибку задачи
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError агрегирует ошибки всех задач, завершившихся неудачно
+type MultiError struct {
+	Errors []*TaskError
+}
+
+// Error реализует интерфейс error
+func (e *MultiError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	parts := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		parts[i] = err.Error()
+	}
+	return fmt.Sprintf("%d lifecycle errors: %s", len(e.Errors), strings.Join(parts, "; "))
+}
+
+// Unwrap позволяет использовать errors.Is/errors.As для каждой ошибки
+func (e *MultiError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Tasks возвращает имена задач, завершившихся с ошибкой
+func (e *MultiError) Tasks() []string {
+	names := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		names[i] = err.Task
+	}
+	return names
+}
+
+// add добавляет ошибку задачи
+func (e *MultiError) add(taskName, op string, err error) {
+	e.Errors = append(e.Errors, &TaskError{Task: taskName, Op: op, Err: err})
+}
+
+// errOrNil возвращает nil, если ошибок нет
+func (e *MultiError) errOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// PanicError описывает panic, перехваченный в хуке задачи
+type PanicError struct {
+	Value interface{}
+	Stack string
+}
+
+// Error реализует интерфейс error
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
@@ -3,71 +3,515 @@ package lifecycle
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
+	"service-boilerplate/internal/apperr"
 	"service-boilerplate/internal/logger"
 	"service-boilerplate/internal/task"
 )
 
+// registration хранит задачу вместе с ее фазой остановки
+type registration struct {
+	task  task.Task
+	phase task.Phase
+}
+
+// Параметры ожидания условий запуска по умолчанию
+const (
+	defaultDependencyTimeout    = 60 * time.Second
+	defaultDependencyMaxBackoff = 10 * time.Second
+	dependencyInitialBackoff    = 500 * time.Millisecond
+)
+
+// abandonGrace сколько BeforeStop ждет после истечения срока: задача,
+// которая учитывает ctx, успевает вернуть ошибку и не считается брошенной
+const abandonGrace = 200 * time.Millisecond
+
+// ErrTaskAbandoned BeforeStop не завершился за отведенное время, остановка
+// продолжилась без него
+var ErrTaskAbandoned = errors.New("task abandoned during shutdown")
+
+// ErrDependencyTimeout условие запуска задачи не выполнилось за отведенное время
+var ErrDependencyTimeout = errors.New("timed out waiting for dependency")
+
+// StateLoader источник сохраненного состояния задач (store.Store)
+type StateLoader interface {
+	// TaskState возвращает состояние задачи name. Отсутствие состояния
+	// не ошибка: возвращается State с Warm false
+	TaskState(name string) (task.State, error)
+}
+
+// ProgressFunc получает ход запуска задач: step - номер запускаемой
+// задачи (с 1), total - количество запускаемых задач
+type ProgressFunc func(step, total int, taskName string)
+
 // Manager управляет lifecycle компонентов
 type Manager struct {
-	mu    sync.RWMutex
-	tasks []task.Task
-	log   *logger.Logger
+	mu       sync.RWMutex
+	tasks    []registration
+	log      *logger.Logger
+	states   StateLoader
+	progress ProgressFunc
+	// readOnly запускаются только задачи task.ReadOnly
+	readOnly bool
+
+	depTimeout    time.Duration
+	depMaxBackoff time.Duration
+	// stopTimeout время на BeforeStop одной задачи, 0 - до истечения ctx
+	stopTimeout time.Duration
 }
 
 // New создает новый lifecycle менеджер
 func New(log *logger.Logger) *Manager {
 	return &Manager{
-		tasks: make([]task.Task, 0),
-		log:   log,
+		tasks:         make([]registration, 0),
+		log:           log,
+		depTimeout:    defaultDependencyTimeout,
+		depMaxBackoff: defaultDependencyMaxBackoff,
+	}
+}
+
+// SetDependencyWait задает, сколько ждать условий запуска задачи
+// (task.Dependent) и максимальную паузу между проверками. Пауза
+// удваивается после каждой неудачной проверки. Нулевые значения
+// оставляют значения по умолчанию
+func (m *Manager) SetDependencyWait(timeout, maxBackoff time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if timeout > 0 {
+		m.depTimeout = timeout
+	}
+	if maxBackoff > 0 {
+		m.depMaxBackoff = maxBackoff
 	}
 }
 
-// Register регистрирует новую задачу
+// SetStopTimeout задает, сколько ждать BeforeStop одной задачи. Задача,
+// не завершившаяся за это время (task.StopTimeouter переопределяет его),
+// бросается: в лог пишется стек ее горутины, остальные задачи
+// останавливаются без ожидания. 0 - ждать до истечения контекста StopAll
+func (m *Manager) SetStopTimeout(timeout time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stopTimeout = timeout
+}
+
+// SetStateLoader задает источник состояния для задач task.Restorer.
+// Без него Restore вызывается с холодным состоянием. Источник должен
+// быть доступен к моменту запуска таких задач: хранилище регистрируется
+// раньше них. Вызывается до StartAll
+func (m *Manager) SetStateLoader(l StateLoader) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states = l
+}
+
+// SetProgress задает получателя хода запуска: он вызывается перед
+// запуском каждой задачи. Вызывается до StartAll
+func (m *Manager) SetProgress(fn ProgressFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.progress = fn
+}
+
+// SetReadOnly включает режим только для чтения: StartAll и StopAll
+// пропускают задачи, не реализующие task.ReadOnly. Вызывается до StartAll
+func (m *Manager) SetReadOnly(readOnly bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.readOnly = readOnly
+}
+
+// Register регистрирует новую задачу. Фаза остановки берется из
+// task.Phased, если задача его реализует, иначе task.PhaseDefault
 func (m *Manager) Register(t task.Task) {
+	m.RegisterWithPhase(t, task.PhaseOf(t))
+}
+
+// RegisterWithPhase регистрирует задачу с явно заданной фазой остановки
+func (m *Manager) RegisterWithPhase(t task.Task, phase task.Phase) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.tasks = append(m.tasks, t)
-	m.log.Info("Task registered", map[string]interface{}{"task": t.Name()})
+	m.tasks = append(m.tasks, registration{task: t, phase: phase})
+	m.log.Info("Task registered", map[string]interface{}{
+		"task":  t.Name(),
+		"phase": int(phase),
+	})
+}
+
+// Count возвращает количество зарегистрированных задач
+func (m *Manager) Count() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.tasks)
+}
+
+// snapshot возвращает копию списка регистраций
+func (m *Manager) snapshot() []registration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	regs := make([]registration, len(m.tasks))
+	copy(regs, m.tasks)
+	return regs
+}
+
+// active возвращает регистрации задач, которые запускаются, и задачи,
+// отключенные режимом только для чтения (не реализуют task.ReadOnly)
+func (m *Manager) active() (active, disabled []registration) {
+	regs := m.snapshot()
+	m.mu.RLock()
+	readOnly := m.readOnly
+	m.mu.RUnlock()
+	if !readOnly {
+		return regs, nil
+	}
+	for _, r := range regs {
+		if task.IsReadOnly(r.task) {
+			active = append(active, r)
+		} else {
+			disabled = append(disabled, r)
+		}
+	}
+	return active, disabled
 }
 
-// StartAll запускает все зарегистрированные задачи
+// StartAll запускает все зарегистрированные задачи. При ошибке запуск
+// прекращается, уже запущенные задачи останавливаются, а возвращаемый
+// *MultiError содержит ошибку запуска и ошибки отката
 func (m *Manager) StartAll(ctx context.Context) error {
+	regs, disabled := m.active()
+	for _, r := range disabled {
+		m.log.Info("Task disabled in read-only mode", map[string]interface{}{"task": r.task.Name()})
+	}
+
 	m.mu.RLock()
-	tasks := make([]task.Task, len(m.tasks))
-	copy(tasks, m.tasks)
+	progress := m.progress
 	m.mu.RUnlock()
 
-	for _, t := range tasks {
-		m.log.Info("Starting task", map[string]interface{}{"task": t.Name()})
-		if err := t.AfterStart(ctx); err != nil {
-			return fmt.Errorf("failed to start task %s: %w", t.Name(), err)
+	for i, r := range regs {
+		t := r.task
+		m.log.Info("Starting task", map[string]interface{}{
+			"task":  t.Name(),
+			"step":  i + 1,
+			"total": len(regs),
+		})
+		if progress != nil {
+			progress(i+1, len(regs), t.Name())
+		}
+		op := OpStart
+		err := m.waitDependencies(ctx, t)
+		if err == nil {
+			if err = m.restore(ctx, t); err != nil {
+				op = OpRestore
+			}
+		}
+		if err == nil {
+			err = m.callHook(t, OpStart, func() error { return t.AfterStart(ctx) })
+		}
+		if err != nil {
+			merr := &MultiError{}
+			merr.add(t.Name(), op, err)
+			m.log.Error("Error starting task, rolling back", map[string]interface{}{
+				"task":  t.Name(),
+				"error": err.Error(),
+			})
+			m.stop(ctx, regs[:i], merr)
+			return merr
 		}
 	}
 
 	return nil
 }
 
-// StopAll останавливает все задачи в обратном порядке
-func (m *Manager) StopAll(ctx context.Context) error {
+// waitDependencies ждет выполнения условий запуска задачи, повторяя
+// проверки с растущей паузой. Условие, не выполненное за depTimeout,
+// возвращает *apperr.DependencyUnavailableError с ErrDependencyTimeout
+// и последней ошибкой проверки
+func (m *Manager) waitDependencies(ctx context.Context, t task.Task) error {
+	dependent, ok := t.(task.Dependent)
+	if !ok {
+		return nil
+	}
+	m.mu.RLock()
+	timeout, maxBackoff := m.depTimeout, m.depMaxBackoff
+	m.mu.RUnlock()
+
+	for _, dep := range dependent.Dependencies() {
+		start := time.Now()
+		deadline := start.Add(timeout)
+		backoff := dependencyInitialBackoff
+		for attempt := 1; ; attempt++ {
+			err := m.checkDependency(ctx, t, dep, time.Until(deadline))
+			if err == nil {
+				if attempt > 1 {
+					m.log.Info("Task dependency ready", map[string]interface{}{
+						"task":       t.Name(),
+						"dependency": dep.Name,
+						"waited_ms":  time.Since(start).Milliseconds(),
+					})
+				}
+				break
+			}
+			if attempt == 1 {
+				m.log.Info("Waiting for task dependency", map[string]interface{}{
+					"task":       t.Name(),
+					"dependency": dep.Name,
+					"error":      err.Error(),
+				})
+			}
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return &apperr.DependencyUnavailableError{
+					Task:       t.Name(),
+					Dependency: dep.Name,
+					Err:        fmt.Errorf("%w after %s: %v", ErrDependencyTimeout, timeout, err),
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(min(backoff, remaining)):
+			}
+			if backoff *= 2; backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+	}
+	return nil
+}
+
+// restore передает задаче task.Restorer состояние предыдущего запуска.
+// Ошибка чтения состояния не прерывает запуск: задача стартует холодной
+func (m *Manager) restore(ctx context.Context, t task.Task) error {
+	restorer, ok := t.(task.Restorer)
+	if !ok {
+		return nil
+	}
 	m.mu.RLock()
-	tasks := make([]task.Task, len(m.tasks))
-	copy(tasks, m.tasks)
+	states := m.states
 	m.mu.RUnlock()
 
-	// Останавливаем в обратном порядке
-	for i := len(tasks) - 1; i >= 0; i-- {
-		t := tasks[i]
-		m.log.Info("Stopping task", map[string]interface{}{"task": t.Name()})
-		if err := t.BeforeStop(ctx); err != nil {
-			m.log.Error("Error stopping task", map[string]interface{}{
+	var state task.State
+	if states != nil {
+		st, err := states.TaskState(t.Name())
+		if err != nil {
+			m.log.Warn("Failed to load task state, starting cold", map[string]interface{}{
 				"task":  t.Name(),
 				"error": err.Error(),
 			})
+		} else {
+			state = st
 		}
 	}
+	fields := map[string]interface{}{
+		"task": t.Name(),
+		"warm": state.Warm,
+	}
+	if state.Warm {
+		fields["saved_at"] = state.SavedAt.Format(time.RFC3339)
+	}
+	m.log.Info("Restoring task state", fields)
+	return m.callHook(t, OpRestore, func() error { return restorer.Restore(ctx, state) })
+}
 
-	return nil
+// checkDependency выполняет одну проверку условия не дольше limit
+func (m *Manager) checkDependency(ctx context.Context, t task.Task, dep task.Dependency, limit time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, limit)
+	defer cancel()
+	return m.callHook(t, OpCheck, func() error { return dep.Ready(ctx) })
+}
+
+// CheckAll выполняет предстартовые проверки всех задач, реализующих
+// task.Checker, и однократно проверяет условия запуска task.Dependent.
+// Проверяются все задачи, ошибки возвращаются как *MultiError
+func (m *Manager) CheckAll(ctx context.Context) error {
+	merr := &MultiError{}
+	for _, r := range m.snapshot() {
+		t := r.task
+		if dependent, ok := t.(task.Dependent); ok {
+			for _, dep := range dependent.Dependencies() {
+				if err := m.checkDependency(ctx, t, dep, defaultDependencyMaxBackoff); err != nil {
+					err = &apperr.DependencyUnavailableError{Task: t.Name(), Dependency: dep.Name, Err: err}
+					m.log.Error("Task check failed", map[string]interface{}{
+						"task":  t.Name(),
+						"error": err.Error(),
+					})
+					merr.add(t.Name(), OpCheck, err)
+				}
+			}
+		}
+
+		checker, ok := t.(task.Checker)
+		if !ok {
+			continue
+		}
+		m.log.Info("Checking task", map[string]interface{}{"task": t.Name()})
+		if err := m.callHook(t, OpCheck, func() error { return checker.Check(ctx) }); err != nil {
+			m.log.Error("Task check failed", map[string]interface{}{
+				"task":  t.Name(),
+				"error": err.Error(),
+			})
+			merr.add(t.Name(), OpCheck, err)
+		}
+	}
+	return merr.errOrNil()
+}
+
+// StopAll останавливает все задачи по фазам: в порядке возрастания фазы,
+// внутри одной фазы - в обратном порядке регистрации. Ошибка одной задачи
+// не прерывает остановку остальных, все ошибки возвращаются как *MultiError
+func (m *Manager) StopAll(ctx context.Context) error {
+	merr := &MultiError{}
+	regs, _ := m.active()
+	m.stop(ctx, regs, merr)
+	return merr.errOrNil()
+}
+
+// stop останавливает переданные задачи, собирая ошибки в merr
+func (m *Manager) stop(ctx context.Context, regs []registration, merr *MultiError) {
+	for _, r := range stopOrder(regs) {
+		t := r.task
+		m.log.Info("Stopping task", map[string]interface{}{
+			"task":  t.Name(),
+			"phase": int(r.phase),
+		})
+		if err := m.stopTask(ctx, t); err != nil {
+			if !errors.Is(err, ErrTaskAbandoned) {
+				m.log.Error("Error stopping task", map[string]interface{}{
+					"task":  t.Name(),
+					"error": err.Error(),
+				})
+			}
+			merr.add(t.Name(), OpStop, err)
+		}
+	}
+}
+
+// stopTask вызывает BeforeStop в отдельной горутине и ждет его не дольше
+// срока задачи. Если срок истек, в лог пишется стек горутины BeforeStop
+// и возвращается ErrTaskAbandoned; горутина продолжает работу без ожидания
+func (m *Manager) stopTask(ctx context.Context, t task.Task) error {
+	timeout := m.stopTimeoutOf(t)
+	stopCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		stopCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	ids := make(chan string, 1)
+	done := make(chan error, 1)
+	go func() {
+		ids <- goroutineID()
+		done <- m.callHook(t, OpStop, func() error { return t.BeforeStop(stopCtx) })
+	}()
+	id := <-ids
+
+	select {
+	case err := <-done:
+		return err
+	case <-stopCtx.Done():
+	}
+	grace := time.NewTimer(abandonGrace)
+	defer grace.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-grace.C:
+	}
+
+	fields := map[string]interface{}{
+		"task":       t.Name(),
+		"reason":     stopCtx.Err().Error(),
+		"stacktrace": goroutineStack(id),
+	}
+	if timeout > 0 {
+		fields["timeout"] = timeout.String()
+	}
+	m.log.Error("Task abandoned during shutdown", fields)
+	return fmt.Errorf("%w: %v", ErrTaskAbandoned, stopCtx.Err())
+}
+
+// stopTimeoutOf возвращает время на BeforeStop задачи
+func (m *Manager) stopTimeoutOf(t task.Task) time.Duration {
+	if s, ok := t.(task.StopTimeouter); ok {
+		if timeout := s.StopTimeout(); timeout > 0 {
+			return timeout
+		}
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.stopTimeout
+}
+
+// goroutineID возвращает номер текущей горутины из заголовка runtime.Stack
+func goroutineID() string {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	// "goroutine 123 [running]:"
+	fields := strings.Fields(string(buf))
+	if len(fields) < 2 {
+		return ""
+	}
+	return fields[1]
+}
+
+// goroutineStack возвращает стек горутины id из дампа всех горутин
+func goroutineStack(id string) string {
+	if id == "" {
+		return ""
+	}
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	prefix := "goroutine " + id + " ["
+	for _, g := range strings.Split(string(buf), "\n\n") {
+		if strings.HasPrefix(g, prefix) {
+			return g
+		}
+	}
+	return ""
+}
+
+// callHook вызывает хук задачи, преобразуя panic в *PanicError
+func (m *Manager) callHook(t task.Task, op string, hook func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := string(debug.Stack())
+			m.log.Error("Task panic recovered", map[string]interface{}{
+				"task":       t.Name(),
+				"op":         op,
+				"panic":      fmt.Sprint(r),
+				"stacktrace": stack,
+			})
+			err = &PanicError{Value: r, Stack: stack}
+		}
+	}()
+	return hook()
+}
+
+// stopOrder упорядочивает регистрации для остановки
+func stopOrder(in []registration) []registration {
+	// Разворачиваем, чтобы стабильная сортировка сохранила обратный порядок регистрации
+	regs := make([]registration, len(in))
+	for i, r := range in {
+		regs[len(in)-1-i] = r
+	}
+	sort.SliceStable(regs, func(i, j int) bool {
+		return regs[i].phase < regs[j].phase
+	})
+	return regs
 }
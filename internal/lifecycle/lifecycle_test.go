@@ -3,10 +3,14 @@ package lifecycle
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"service-boilerplate/internal/apperr"
 	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/task"
 )
 
 // mockTask реализует task.Task для тестов
@@ -129,6 +133,40 @@ func TestStartAll_Error(t *testing.T) {
 	if task3.started {
 		t.Error("Third task should not be started after error")
 	}
+
+	// Уже запущенная задача должна быть остановлена (откат)
+	if !task1.stopped {
+		t.Error("First task should be stopped on rollback")
+	}
+}
+
+// TestStartAll_AggregatesRollbackErrors проверяет агрегацию ошибок запуска и отката
+func TestStartAll_AggregatesRollbackErrors(t *testing.T) {
+	manager, log := setupTestManager(t)
+	defer log.Close()
+
+	errStop := errors.New("stop failed")
+	errStart := errors.New("start failed")
+	manager.Register(&mockTask{name: "task1", stopError: errStop})
+	manager.Register(&mockTask{name: "task2", startError: errStart})
+
+	err := manager.StartAll(context.Background())
+	var merr *MultiError
+	if !errors.As(err, &merr) {
+		t.Fatalf("StartAll() error = %v, want *MultiError", err)
+	}
+	if len(merr.Errors) != 2 {
+		t.Fatalf("len(MultiError.Errors) = %d, want 2", len(merr.Errors))
+	}
+	if merr.Errors[0].Task != "task2" || merr.Errors[0].Op != OpStart {
+		t.Errorf("first error = %+v, want start of task2", merr.Errors[0])
+	}
+	if merr.Errors[1].Task != "task1" || merr.Errors[1].Op != OpStop {
+		t.Errorf("second error = %+v, want stop of task1", merr.Errors[1])
+	}
+	if !errors.Is(err, errStart) || !errors.Is(err, errStop) {
+		t.Error("errors.Is() should match wrapped task errors")
+	}
 }
 
 // TestStopAll_ReverseOrder проверяет остановку в обратном порядке
@@ -188,8 +226,13 @@ func TestStopAll_ContinuesOnError(t *testing.T) {
 		t.Fatalf("StartAll() error = %v", err)
 	}
 
-	if err := manager.StopAll(ctx); err != nil {
-		t.Errorf("StopAll() error = %v", err)
+	err := manager.StopAll(ctx)
+	var merr *MultiError
+	if !errors.As(err, &merr) {
+		t.Fatalf("StopAll() error = %v, want *MultiError", err)
+	}
+	if tasks := merr.Tasks(); len(tasks) != 1 || tasks[0] != "task2" {
+		t.Errorf("MultiError.Tasks() = %v, want [task2]", tasks)
 	}
 
 	// Все задачи должны быть остановлены (даже с ошибкой)
@@ -300,3 +343,430 @@ func TestConcurrentAccess(t *testing.T) {
 		t.Errorf("StopAll() error = %v", err)
 	}
 }
+
+// phasedTask задача с явной фазой остановки
+type phasedTask struct {
+	mockTask
+	phase task.Phase
+}
+
+func (p *phasedTask) ShutdownPhase() task.Phase {
+	return p.phase
+}
+
+// TestStopAll_Phases проверяет остановку по фазам независимо от порядка регистрации
+func TestStopAll_Phases(t *testing.T) {
+	manager, log := setupTestManager(t)
+	defer log.Close()
+
+	order := 0
+	flush := &phasedTask{mockTask: mockTask{name: "flush", globalOrder: &order}, phase: task.PhaseFlush}
+	accept := &phasedTask{mockTask: mockTask{name: "accept", globalOrder: &order}, phase: task.PhaseStopAccepting}
+	plain1 := &mockTask{name: "plain1", globalOrder: &order}
+	plain2 := &mockTask{name: "plain2", globalOrder: &order}
+	release := &mockTask{name: "release", globalOrder: &order}
+
+	manager.Register(flush)
+	manager.Register(plain1)
+	manager.Register(accept)
+	manager.Register(plain2)
+	manager.RegisterWithPhase(release, task.PhaseRelease)
+
+	ctx := context.Background()
+	if err := manager.StartAll(ctx); err != nil {
+		t.Fatalf("StartAll() error = %v", err)
+	}
+
+	order = 0
+	if err := manager.StopAll(ctx); err != nil {
+		t.Errorf("StopAll() error = %v", err)
+	}
+
+	// Ожидаемый порядок: accept, plain2, plain1, flush, release
+	want := map[string]int{"accept": 0, "plain2": 1, "plain1": 2, "flush": 3, "release": 4}
+	got := map[string]int{
+		"accept":  accept.stopOrder,
+		"plain2":  plain2.stopOrder,
+		"plain1":  plain1.stopOrder,
+		"flush":   flush.stopOrder,
+		"release": release.stopOrder,
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("%s stop order = %d, want %d", name, got[name], w)
+		}
+	}
+}
+
+// panicTask задача, паникующая в хуках
+type panicTask struct {
+	name         string
+	panicOnStart bool
+	panicOnStop  bool
+}
+
+func (p *panicTask) Name() string {
+	return p.name
+}
+
+func (p *panicTask) AfterStart(ctx context.Context) error {
+	if p.panicOnStart {
+		panic("start boom")
+	}
+	return nil
+}
+
+func (p *panicTask) BeforeStop(ctx context.Context) error {
+	if p.panicOnStop {
+		panic("stop boom")
+	}
+	return nil
+}
+
+// TestStartAll_PanicIsolated проверяет перехват panic в AfterStart
+func TestStartAll_PanicIsolated(t *testing.T) {
+	manager, log := setupTestManager(t)
+	defer log.Close()
+
+	first := &mockTask{name: "first"}
+	manager.Register(first)
+	manager.Register(&panicTask{name: "panicky", panicOnStart: true})
+
+	err := manager.StartAll(context.Background())
+	var perr *PanicError
+	if !errors.As(err, &perr) {
+		t.Fatalf("StartAll() error = %v, want *PanicError", err)
+	}
+	if perr.Value != "start boom" || perr.Stack == "" {
+		t.Errorf("PanicError = %+v", perr)
+	}
+	if !first.stopped {
+		t.Error("already started task should be rolled back")
+	}
+}
+
+// TestStopAll_PanicIsolated проверяет что panic в BeforeStop не прерывает остановку
+func TestStopAll_PanicIsolated(t *testing.T) {
+	manager, log := setupTestManager(t)
+	defer log.Close()
+
+	first := &mockTask{name: "first"}
+	manager.Register(first)
+	manager.Register(&panicTask{name: "panicky", panicOnStop: true})
+
+	ctx := context.Background()
+	if err := manager.StartAll(ctx); err != nil {
+		t.Fatalf("StartAll() error = %v", err)
+	}
+
+	err := manager.StopAll(ctx)
+	var merr *MultiError
+	if !errors.As(err, &merr) || len(merr.Errors) != 1 || merr.Errors[0].Task != "panicky" {
+		t.Fatalf("StopAll() error = %v, want MultiError for panicky", err)
+	}
+	if !first.stopped {
+		t.Error("remaining tasks should be stopped after panic")
+	}
+}
+
+// hangingTask задача, BeforeStop которой не учитывает ctx
+type hangingTask struct {
+	mockTask
+	release chan struct{}
+	timeout time.Duration
+}
+
+func (h *hangingTask) BeforeStop(ctx context.Context) error {
+	<-h.release
+	return nil
+}
+
+func (h *hangingTask) StopTimeout() time.Duration {
+	return h.timeout
+}
+
+// TestStopAll_AbandonsHangingTask проверяет, что зависший BeforeStop
+// бросается по сроку задачи со стеком в логе, а остальные задачи останавливаются
+func TestStopAll_AbandonsHangingTask(t *testing.T) {
+	manager, log := setupTestManager(t)
+	defer log.Close()
+	log.SetRing(logger.NewRing(10))
+	manager.SetStopTimeout(time.Hour)
+
+	first := &mockTask{name: "first"}
+	hanging := &hangingTask{mockTask: mockTask{name: "hanging"}, release: make(chan struct{}), timeout: 50 * time.Millisecond}
+	defer close(hanging.release)
+	manager.Register(first)
+	manager.Register(hanging)
+
+	ctx := context.Background()
+	if err := manager.StartAll(ctx); err != nil {
+		t.Fatalf("StartAll() error = %v", err)
+	}
+
+	start := time.Now()
+	err := manager.StopAll(ctx)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("StopAll() took %v, want task timeout and grace", elapsed)
+	}
+	if !errors.Is(err, ErrTaskAbandoned) {
+		t.Fatalf("StopAll() error = %v, want ErrTaskAbandoned", err)
+	}
+	if !first.stopped {
+		t.Error("remaining tasks should be stopped after abandoned task")
+	}
+
+	entries := log.Ring().Entries(logger.ErrorLevel, 0)
+	if len(entries) != 1 || entries[0].Message != "Task abandoned during shutdown" {
+		t.Fatalf("error entries = %+v, want one abandoned entry", entries)
+	}
+	stack, _ := entries[0].Fields["stacktrace"].(string)
+	if !strings.Contains(stack, "hangingTask).BeforeStop") {
+		t.Errorf("stacktrace does not contain BeforeStop:\n%s", stack)
+	}
+}
+
+// contextTask задача, BeforeStop которой ждет истечения ctx
+type contextTask struct {
+	name string
+}
+
+func (c *contextTask) Name() string                         { return c.name }
+func (c *contextTask) AfterStart(ctx context.Context) error { return nil }
+func (c *contextTask) BeforeStop(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// TestStopAll_ContextAwareNotAbandoned проверяет, что BeforeStop, вернувший
+// ошибку контекста в пределах срока, не считается брошенным
+func TestStopAll_ContextAwareNotAbandoned(t *testing.T) {
+	manager, log := setupTestManager(t)
+	defer log.Close()
+	manager.SetStopTimeout(20 * time.Millisecond)
+	manager.Register(&contextTask{name: "polite"})
+
+	err := manager.StopAll(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrTaskAbandoned) {
+		t.Errorf("StopAll() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+// checkTask задача с предстартовой проверкой
+type checkTask struct {
+	mockTask
+	checkError error
+	checked    bool
+}
+
+func (c *checkTask) Check(ctx context.Context) error {
+	c.checked = true
+	return c.checkError
+}
+
+// TestCheckAll проверяет предстартовые проверки всех задач
+func TestCheckAll(t *testing.T) {
+	manager, log := setupTestManager(t)
+	defer log.Close()
+
+	ok := &checkTask{mockTask: mockTask{name: "ok"}}
+	bad := &checkTask{mockTask: mockTask{name: "bad"}, checkError: errors.New("unreachable")}
+	plain := &mockTask{name: "plain"}
+	manager.Register(bad)
+	manager.Register(ok)
+	manager.Register(plain)
+
+	err := manager.CheckAll(context.Background())
+	var merr *MultiError
+	if !errors.As(err, &merr) || len(merr.Errors) != 1 || merr.Errors[0].Op != OpCheck {
+		t.Fatalf("CheckAll() error = %v, want single check error", err)
+	}
+	if !ok.checked || !bad.checked {
+		t.Error("all checkers should be called")
+	}
+	if plain.started || ok.started {
+		t.Error("CheckAll() must not start tasks")
+	}
+}
+
+// dependentTask задача с условиями запуска
+type dependentTask struct {
+	mockTask
+	deps []task.Dependency
+}
+
+func (d *dependentTask) Dependencies() []task.Dependency {
+	return d.deps
+}
+
+// TestStartAll_WaitsForDependencies проверяет ожидание условий запуска
+// перед AfterStart и ошибку по таймауту
+func TestStartAll_WaitsForDependencies(t *testing.T) {
+	manager, log := setupTestManager(t)
+	defer log.Close()
+	manager.SetDependencyWait(2*time.Second, 50*time.Millisecond)
+
+	var attempts int
+	ready := &dependentTask{
+		mockTask: mockTask{name: "ready"},
+		deps: []task.Dependency{{Name: "flaky", Ready: func(ctx context.Context) error {
+			if attempts++; attempts < 3 {
+				return errors.New("not yet")
+			}
+			return nil
+		}}},
+	}
+	manager.Register(ready)
+	if err := manager.StartAll(context.Background()); err != nil {
+		t.Fatalf("StartAll() error = %v", err)
+	}
+	if !ready.started || attempts != 3 {
+		t.Errorf("started = %v, attempts = %d, want started after 3 attempts", ready.started, attempts)
+	}
+
+	manager, log2 := setupTestManager(t)
+	defer log2.Close()
+	manager.SetDependencyWait(100*time.Millisecond, 20*time.Millisecond)
+	missing := &dependentTask{
+		mockTask: mockTask{name: "missing"},
+		deps:     []task.Dependency{task.File(t.TempDir() + "/absent")},
+	}
+	manager.Register(missing)
+	err := manager.StartAll(context.Background())
+	if !errors.Is(err, ErrDependencyTimeout) {
+		t.Fatalf("StartAll() error = %v, want ErrDependencyTimeout", err)
+	}
+	var depErr *apperr.DependencyUnavailableError
+	if !errors.As(err, &depErr) || depErr.Task != "missing" {
+		t.Errorf("StartAll() error = %v, want DependencyUnavailableError for task missing", err)
+	}
+	if missing.started {
+		t.Error("task with unmet dependency must not be started")
+	}
+
+	// В dry-run условие проверяется один раз, без ожидания
+	if err := manager.CheckAll(context.Background()); err == nil {
+		t.Error("CheckAll() expected dependency error, got nil")
+	}
+}
+
+// restoreTask задача, продолжающая работу с сохраненного состояния
+type restoreTask struct {
+	mockTask
+	state      task.State
+	restored   bool
+	restoreErr error
+}
+
+func (r *restoreTask) Restore(ctx context.Context, state task.State) error {
+	r.restored = true
+	r.state = state
+	return r.restoreErr
+}
+
+// stateMap источник состояния задач для тестов
+type stateMap map[string][]byte
+
+func (s stateMap) TaskState(name string) (task.State, error) {
+	data, ok := s[name]
+	if !ok {
+		return task.State{}, nil
+	}
+	return task.State{Warm: true, Data: data}, nil
+}
+
+// TestStartAll_Restore проверяет передачу состояния перед AfterStart
+func TestStartAll_Restore(t *testing.T) {
+	manager, log := setupTestManager(t)
+	defer log.Close()
+
+	// Без источника состояния старт холодный
+	cold := &restoreTask{mockTask: mockTask{name: "cold"}}
+	manager.Register(cold)
+	if err := manager.StartAll(context.Background()); err != nil {
+		t.Fatalf("StartAll() error = %v", err)
+	}
+	if !cold.restored || cold.state.Warm || !cold.started {
+		t.Errorf("restored = %v, state = %+v, started = %v, want cold restore", cold.restored, cold.state, cold.started)
+	}
+
+	manager, log2 := setupTestManager(t)
+	defer log2.Close()
+	manager.SetStateLoader(stateMap{"warm": []byte("cursor")})
+	warm := &restoreTask{mockTask: mockTask{name: "warm"}}
+	failing := &restoreTask{mockTask: mockTask{name: "failing"}, restoreErr: errors.New("corrupt state")}
+	manager.Register(warm)
+	manager.Register(failing)
+	err := manager.StartAll(context.Background())
+	if !warm.state.Warm || string(warm.state.Data) != "cursor" {
+		t.Errorf("warm state = %+v, want warm cursor", warm.state)
+	}
+	var merr *MultiError
+	if !errors.As(err, &merr) || merr.Errors[0].Op != OpRestore {
+		t.Fatalf("StartAll() error = %v, want restore error", err)
+	}
+	if failing.started || !warm.stopped {
+		t.Error("failed restore must abort start and roll back started tasks")
+	}
+}
+
+// readOnlyTask задача, безопасная в режиме только для чтения
+type readOnlyTask struct {
+	mockTask
+}
+
+func (r *readOnlyTask) ReadOnly() bool {
+	return true
+}
+
+// TestStartAll_ReadOnly проверяет, что в режиме только для чтения
+// запускаются и останавливаются только задачи task.ReadOnly
+func TestStartAll_ReadOnly(t *testing.T) {
+	manager, log := setupTestManager(t)
+	defer log.Close()
+
+	writer := &mockTask{name: "writer"}
+	reader := &readOnlyTask{mockTask{name: "reader"}}
+	manager.Register(writer)
+	manager.Register(reader)
+	manager.SetReadOnly(true)
+
+	ctx := context.Background()
+	if err := manager.StartAll(ctx); err != nil {
+		t.Fatalf("StartAll() error = %v", err)
+	}
+	if err := manager.StopAll(ctx); err != nil {
+		t.Fatalf("StopAll() error = %v", err)
+	}
+
+	if writer.started || writer.stopped {
+		t.Errorf("writer started=%v stopped=%v, want both false", writer.started, writer.stopped)
+	}
+	if !reader.started || !reader.stopped {
+		t.Errorf("reader started=%v stopped=%v, want both true", reader.started, reader.stopped)
+	}
+}
+
+// TestStartAll_Progress проверяет сообщения о ходе запуска: задача,
+// на которой запуск остановился, сообщается последней
+func TestStartAll_Progress(t *testing.T) {
+	manager, log := setupTestManager(t)
+	defer log.Close()
+
+	manager.Register(&mockTask{name: "store"})
+	manager.Register(&mockTask{name: "http", startError: errors.New("bind failed")})
+	manager.Register(&mockTask{name: "jobs"})
+
+	var steps []string
+	manager.SetProgress(func(step, total int, taskName string) {
+		steps = append(steps, fmt.Sprintf("%d/%d %s", step, total, taskName))
+	})
+	if err := manager.StartAll(context.Background()); err == nil {
+		t.Fatal("StartAll() error = nil, want error")
+	}
+
+	want := []string{"1/3 store", "2/3 http"}
+	if strings.Join(steps, ", ") != strings.Join(want, ", ") {
+		t.Errorf("progress = %v, want %v", steps, want)
+	}
+}
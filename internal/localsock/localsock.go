@@ -0,0 +1,22 @@
+// Package localsock предоставляет локальный канал управления: Unix socket
+// на Linux и named pipe на Windows. Доступ к каналу ограничивается правами ОС
+// (владелец процесса и администраторы), TCP порт не открывается
+package localsock
+
+import (
+	"net"
+)
+
+// DefaultPath возвращает путь канала по умолчанию для экземпляра name:
+// <runtime>/<name>.sock на Linux (runtimeDir) и \\.\pipe\<name> на Windows
+func DefaultPath(name string) string {
+	return defaultPath(name)
+}
+
+// addr адрес локального канала
+type addr string
+
+func (a addr) Network() string { return network }
+func (a addr) String() string  { return string(a) }
+
+var _ net.Addr = addr("")
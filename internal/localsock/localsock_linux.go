@@ -0,0 +1,93 @@
+package localsock
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// network сеть локального канала
+const network = "unix"
+
+// defaultPath путь Unix socket в директории времени выполнения (runtimeDir)
+func defaultPath(name string) string {
+	return filepath.Join(runtimeDir(), name+".sock")
+}
+
+// runtimeDir возвращает директорию сокетов, в которой не может создавать
+// файлы другой пользователь: /run для root, $XDG_RUNTIME_DIR или личную
+// директорию пользователя во временной директории
+func runtimeDir() string {
+	if os.Geteuid() == 0 {
+		return "/run"
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("service-boilerplate-%d", os.Geteuid()))
+}
+
+// Listen создает Unix socket path, доступный только владельцу процесса.
+// Недостающая директория создается с правами 0700; директория, в которой
+// может создавать файлы другой пользователь, отклоняется, чтобы сокет
+// нельзя было подменить. Файл сокета, оставшийся после аварийного
+// завершения, удаляется; сокет, который еще принимает подключения,
+// означает второй экземпляр
+func Listen(path string) (net.Listener, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	if err := checkDir(dir); err != nil {
+		return nil, err
+	}
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if conn, err := net.Dial(network, path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("control socket %s is in use by another instance", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen(network, path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+	return listener, nil
+}
+
+// checkDir проверяет, что директорией сокета владеет текущий пользователь
+// или root и что другие пользователи не могут в нее писать
+func checkDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to check socket directory: %w", err)
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		if uid := int(stat.Uid); uid != 0 && uid != os.Geteuid() {
+			return fmt.Errorf("socket directory %s is owned by uid %d", dir, uid)
+		}
+	}
+	if info.Mode().Perm()&0022 != 0 {
+		return fmt.Errorf("socket directory %s is writable by other users (mode %04o)", dir, info.Mode().Perm())
+	}
+	return nil
+}
+
+// Dial подключается к Unix socket path
+func Dial(ctx context.Context, path string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, network, path)
+}
@@ -0,0 +1,119 @@
+package localsock
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testPath возвращает уникальный путь канала для теста
+func testPath(t *testing.T) string {
+	if runtime.GOOS == "windows" {
+		return DefaultPath("localsock-test-" + filepath.Base(t.TempDir()))
+	}
+	return filepath.Join(t.TempDir(), "control.sock")
+}
+
+// TestListenDial проверяет обмен данными через локальный канал и закрытие listener
+func TestListenDial(t *testing.T) {
+	path := testPath(t)
+	listener, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	accepted := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			accepted <- err
+			return
+		}
+		defer conn.Close()
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err == nil {
+			_, err = conn.Write([]byte("pong " + line))
+		}
+		accepted <- err
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := Dial(ctx, path)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	if _, err := conn.Write([]byte("ping\n")); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	conn.Close()
+	if err != nil || reply != "pong ping\n" {
+		t.Errorf("reply = %q, %v", reply, err)
+	}
+	if err := <-accepted; err != nil {
+		t.Errorf("server error = %v", err)
+	}
+
+	if _, err := Listen(path); err == nil {
+		t.Error("second Listen() on a busy path succeeded")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := listener.Accept()
+		done <- err
+	}()
+	listener.Close()
+	select {
+	case err := <-done:
+		if !errors.Is(err, net.ErrClosed) {
+			t.Errorf("Accept() after Close() error = %v, want net.ErrClosed", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Accept() was not interrupted by Close()")
+	}
+}
+
+// TestListen_UnsafeDir проверяет, что сокет не создается в директории,
+// доступной на запись другим пользователям, и что директория по умолчанию
+// создается закрытой
+func TestListen_UnsafeDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("named pipes have no parent directory")
+	}
+	dir := filepath.Join(t.TempDir(), "shared")
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(dir, 0777); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Listen(filepath.Join(dir, "control.sock")); err == nil {
+		t.Error("Listen() in a world-writable directory succeeded")
+	}
+
+	path := filepath.Join(t.TempDir(), "run", "svc", "control.sock")
+	listener, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer listener.Close()
+	info, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0700 {
+		t.Errorf("socket directory mode = %v, want 0700", info.Mode().Perm())
+	}
+	if strings.HasPrefix(DefaultPath("svc"), filepath.Join(os.TempDir(), "svc")) {
+		t.Errorf("DefaultPath() = %s, want a per-user directory", DefaultPath("svc"))
+	}
+}
@@ -0,0 +1,205 @@
+//go:build windows
+// +build windows
+
+package localsock
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// network сеть локального канала
+const network = "pipe"
+
+// pipeSDDL доступ к каналу: LocalSystem, администраторы и владелец процесса
+const pipeSDDL = "D:P(A;;GA;;;SY)(A;;GA;;;BA)(A;;GA;;;OW)"
+
+// pipeBufferSize размер буферов канала
+const pipeBufferSize = 64 * 1024
+
+// defaultPath имя named pipe
+func defaultPath(name string) string {
+	return `\\.\pipe\` + name
+}
+
+// pipeListener принимает подключения к named pipe
+type pipeListener struct {
+	path string
+	sa   *windows.SecurityAttributes
+
+	mu     sync.Mutex
+	next   windows.Handle
+	closed windows.Handle
+	once   sync.Once
+}
+
+// pipeConn подключение к named pipe. Handle открыт для overlapped I/O,
+// поэтому os.File поддерживает deadline и не блокирует поток ОС
+type pipeConn struct {
+	*os.File
+	path string
+}
+
+func (c *pipeConn) LocalAddr() net.Addr  { return addr(c.path) }
+func (c *pipeConn) RemoteAddr() net.Addr { return addr(c.path) }
+
+// Listen создает named pipe path, доступный только LocalSystem,
+// администраторам и владельцу процесса. Занятое имя канала - ошибка
+func Listen(path string) (net.Listener, error) {
+	sd, err := windows.SecurityDescriptorFromString(pipeSDDL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build pipe security descriptor: %w", err)
+	}
+	sa := &windows.SecurityAttributes{SecurityDescriptor: sd}
+	sa.Length = uint32(unsafe.Sizeof(*sa))
+
+	// Первый экземпляр создается сразу: так второй процесс с тем же именем
+	// канала получает ошибку при запуске, а не при первом подключении
+	first, err := createPipe(path, sa, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pipe %s: %w", path, err)
+	}
+	closed, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		windows.CloseHandle(first)
+		return nil, err
+	}
+	return &pipeListener{path: path, sa: sa, next: first, closed: closed}, nil
+}
+
+// Accept ожидает подключение клиента
+func (l *pipeListener) Accept() (net.Conn, error) {
+	l.mu.Lock()
+	h := l.next
+	l.next = windows.InvalidHandle
+	l.mu.Unlock()
+
+	if h == windows.InvalidHandle {
+		if l.isClosed() {
+			return nil, net.ErrClosed
+		}
+		var err error
+		if h, err = createPipe(l.path, l.sa, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := l.connect(h); err != nil {
+		windows.CloseHandle(h)
+		return nil, err
+	}
+
+	// Следующий экземпляр создается сразу, иначе клиент, подключающийся
+	// до следующего Accept, не найдет канал
+	if next, err := createPipe(l.path, l.sa, false); err == nil {
+		l.mu.Lock()
+		if l.isClosed() {
+			windows.CloseHandle(next)
+		} else {
+			l.next = next
+		}
+		l.mu.Unlock()
+	}
+	return &pipeConn{File: os.NewFile(uintptr(h), l.path), path: l.path}, nil
+}
+
+// connect ожидает подключение клиента к экземпляру h или закрытие listener
+func (l *pipeListener) connect(h windows.Handle) error {
+	event, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(event)
+
+	ov := windows.Overlapped{HEvent: event}
+	switch err := windows.ConnectNamedPipe(h, &ov); err {
+	case nil, windows.ERROR_PIPE_CONNECTED:
+		return nil
+	case windows.ERROR_IO_PENDING:
+	default:
+		return err
+	}
+
+	which, err := windows.WaitForMultipleObjects([]windows.Handle{event, l.closed}, false, windows.INFINITE)
+	if err != nil {
+		return err
+	}
+	var n uint32
+	if which != windows.WAIT_OBJECT_0 {
+		windows.CancelIoEx(h, &ov)
+		windows.GetOverlappedResult(h, &ov, &n, true)
+		return net.ErrClosed
+	}
+	return windows.GetOverlappedResult(h, &ov, &n, false)
+}
+
+// Close закрывает listener: ожидающий Accept возвращает net.ErrClosed
+func (l *pipeListener) Close() error {
+	l.once.Do(func() {
+		windows.SetEvent(l.closed)
+		l.mu.Lock()
+		if l.next != windows.InvalidHandle {
+			windows.CloseHandle(l.next)
+			l.next = windows.InvalidHandle
+		}
+		l.mu.Unlock()
+	})
+	return nil
+}
+
+// Addr возвращает имя канала
+func (l *pipeListener) Addr() net.Addr {
+	return addr(l.path)
+}
+
+// isClosed проверяет, закрыт ли listener
+func (l *pipeListener) isClosed() bool {
+	event, err := windows.WaitForSingleObject(l.closed, 0)
+	return err == nil && event == windows.WAIT_OBJECT_0
+}
+
+// createPipe создает экземпляр named pipe для overlapped I/O
+func createPipe(path string, sa *windows.SecurityAttributes, first bool) (windows.Handle, error) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return windows.InvalidHandle, err
+	}
+	flags := uint32(windows.PIPE_ACCESS_DUPLEX | windows.FILE_FLAG_OVERLAPPED)
+	if first {
+		flags |= windows.FILE_FLAG_FIRST_PIPE_INSTANCE
+	}
+	return windows.CreateNamedPipe(name, flags,
+		windows.PIPE_TYPE_BYTE|windows.PIPE_READMODE_BYTE|windows.PIPE_WAIT|windows.PIPE_REJECT_REMOTE_CLIENTS,
+		windows.PIPE_UNLIMITED_INSTANCES, pipeBufferSize, pipeBufferSize, 0, sa)
+}
+
+// Dial подключается к named pipe path. Пока все экземпляры канала заняты,
+// попытки повторяются до отмены ctx
+func Dial(ctx context.Context, path string) (net.Conn, error) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		h, err := windows.CreateFile(name, windows.GENERIC_READ|windows.GENERIC_WRITE, 0, nil,
+			windows.OPEN_EXISTING, windows.FILE_FLAG_OVERLAPPED, 0)
+		if err == nil {
+			return &pipeConn{File: os.NewFile(uintptr(h), path), path: path}, nil
+		}
+		if err != windows.ERROR_PIPE_BUSY {
+			return nil, &net.OpError{Op: "dial", Net: network, Addr: addr(path), Err: err}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// JSONEncoding имя кодирования по умолчанию: JSON строка на запись
+const JSONEncoding = "json"
+
+// Encoder сериализует запись лога для файла лога. Результат пишется
+// в файл как есть, поэтому разделение записей (перевод строки, префикс
+// длины кадра) - задача энкодера. Вызывается конкурентно
+type Encoder interface {
+	Encode(e LogEntry) ([]byte, error)
+}
+
+// EncoderFunc позволяет использовать функцию как Encoder
+type EncoderFunc func(e LogEntry) ([]byte, error)
+
+// Encode вызывает f(e)
+func (f EncoderFunc) Encode(e LogEntry) ([]byte, error) {
+	return f(e)
+}
+
+// jsonLine кодирует запись JSON строкой с переводом строки
+func jsonLine(e LogEntry) ([]byte, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]Encoder{JSONEncoding: EncoderFunc(jsonLine)}
+)
+
+// RegisterEncoder регистрирует кодирование под именем для
+// service.log_encoding (например, protobuf, msgpack или CBOR кадры).
+// Вызывается из init пакета сервиса, до загрузки конфигурации
+func RegisterEncoder(name string, enc Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[name] = enc
+}
+
+// LookupEncoder возвращает кодирование по имени
+func LookupEncoder(name string) (Encoder, error) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	if enc, ok := encoders[name]; ok {
+		return enc, nil
+	}
+	names := make([]string, 0, len(encoders))
+	for n := range encoders {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown log encoding %q (registered: %v)", name, names)
+}
@@ -0,0 +1,64 @@
+package logger
+
+import "strings"
+
+//go:generate go run gen_msgtable.go
+
+// EventCategory категория записей Windows Event Log. Категория
+// определяет диапазон идентификаторов событий: ID*100 + смещение уровня
+type EventCategory struct {
+	ID   uint16
+	Name string
+	// keywords слова сообщения лога, относящие запись к категории
+	keywords []string
+}
+
+// EventCategories категории Event Log. Проверяются по порядку, сообщение
+// без ключевых слов относится к первой категории (General).
+// Идентификаторы стабильны: на них настраивают фильтры и оповещения,
+// поэтому новые категории добавляются только в конец
+var EventCategories = []EventCategory{
+	{ID: 1, Name: "General"},
+	{ID: 2, Name: "Scheduler", keywords: []string{"timer", "scheduler"}},
+	{ID: 3, Name: "Jobs", keywords: []string{"job"}},
+	{ID: 4, Name: "Control", keywords: []string{"admin", "control", "grpc"}},
+	{ID: 5, Name: "Network", keywords: []string{"http", "circuit breaker", "alert"}},
+	{ID: 6, Name: "Service", keywords: []string{"service", "task", "startup", "shutdown", "restart", "process", "leader"}},
+}
+
+// Смещения идентификатора события по уровню внутри категории
+const (
+	eventWarning = 1
+	eventError   = 2
+	eventFatal   = 3
+)
+
+// EventID возвращает идентификатор события уровня level в категории.
+// Уровни ниже warn в Event Log не пишутся и получают 0
+func (c EventCategory) EventID(level Level) uint32 {
+	base := uint32(c.ID) * 100
+	switch level {
+	case WarnLevel:
+		return base + eventWarning
+	case ErrorLevel:
+		return base + eventError
+	case FatalLevel:
+		return base + eventFatal
+	default:
+		return 0
+	}
+}
+
+// EventCategoryOf возвращает категорию Event Log для исходного
+// (непереведенного) сообщения лога
+func EventCategoryOf(msg string) EventCategory {
+	msg = strings.ToLower(msg)
+	for _, c := range EventCategories[1:] {
+		for _, kw := range c.keywords {
+			if strings.Contains(msg, kw) {
+				return c
+			}
+		}
+	}
+	return EventCategories[0]
+}
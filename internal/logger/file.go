@@ -0,0 +1,8 @@
+package logger
+
+import "path/filepath"
+
+// FilePath возвращает путь к файлу лога сервиса
+func FilePath(logDir, serviceName string) string {
+	return filepath.Join(logDir, serviceName+".log")
+}
@@ -0,0 +1,182 @@
+//go:build ignore
+// +build ignore
+
+// gen_msgtable генерирует msgtable_windows_*.syso: COFF объекты с ресурсом
+// RT_MESSAGETABLE, который линкер Go встраивает в исполняемый файл. Файл
+// регистрируется как EventMessageFile и CategoryMessageFile источника
+// событий, и Event Viewer показывает текст записи и имя категории вместо
+// "описание для события не найдено".
+//
+// Запуск: go generate ./internal/logger
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"unicode/utf16"
+
+	"service-boilerplate/internal/logger"
+)
+
+// Константы формата PE/COFF
+const (
+	rtMessageTable = 11
+	langEnUS       = 0x0409
+	sectionFlags   = 0x40000040 // IMAGE_SCN_CNT_INITIALIZED_DATA | IMAGE_SCN_MEM_READ
+	symClassStatic = 3
+	unicodeEntry   = 0x0001
+)
+
+// machine тип машины COFF и тип relocation ADDR32NB для архитектуры
+type machine struct {
+	arch    string
+	machine uint16
+	reloc   uint16
+}
+
+var machines = []machine{
+	{arch: "386", machine: 0x014c, reloc: 0x0007},   // IMAGE_REL_I386_DIR32NB
+	{arch: "amd64", machine: 0x8664, reloc: 0x0003}, // IMAGE_REL_AMD64_ADDR32NB
+	{arch: "arm64", machine: 0xaa64, reloc: 0x0002}, // IMAGE_REL_ARM64_ADDR32NB
+}
+
+func main() {
+	table := messageTable(messages())
+	for _, m := range machines {
+		name := fmt.Sprintf("msgtable_windows_%s.syso", m.arch)
+		if err := os.WriteFile(name, coff(m, table), 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// messages возвращает сообщения таблицы: имена категорий и события.
+// Текст события - строка, переданная в ReportEvent
+func messages() map[uint32]string {
+	msgs := make(map[uint32]string)
+	for _, c := range logger.EventCategories {
+		msgs[uint32(c.ID)] = c.Name
+		for _, level := range []logger.Level{logger.WarnLevel, logger.ErrorLevel, logger.FatalLevel} {
+			msgs[c.EventID(level)] = "%1"
+		}
+	}
+	return msgs
+}
+
+// messageTable кодирует MESSAGE_RESOURCE_DATA: блоки подряд идущих
+// идентификаторов и записи UTF-16 с выравниванием на 4 байта
+func messageTable(msgs map[uint32]string) []byte {
+	ids := make([]uint32, 0, len(msgs))
+	for id := range msgs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	type block struct{ low, high uint32 }
+	var blocks []block
+	for _, id := range ids {
+		if n := len(blocks); n > 0 && blocks[n-1].high+1 == id {
+			blocks[n-1].high = id
+			continue
+		}
+		blocks = append(blocks, block{low: id, high: id})
+	}
+
+	var entries bytes.Buffer
+	offsets := make([]uint32, len(blocks))
+	headerSize := uint32(4 + 12*len(blocks))
+	i := 0
+	for b, blk := range blocks {
+		offsets[b] = headerSize + uint32(entries.Len())
+		for ; i < len(ids) && ids[i] <= blk.high; i++ {
+			text := utf16.Encode([]rune(msgs[ids[i]] + "\r\n\x00"))
+			size := 4 + 2*len(text)
+			size = (size + 3) &^ 3
+			binary.Write(&entries, binary.LittleEndian, uint16(size))
+			binary.Write(&entries, binary.LittleEndian, uint16(unicodeEntry))
+			binary.Write(&entries, binary.LittleEndian, text)
+			for pad := 4 + 2*len(text); pad < size; pad++ {
+				entries.WriteByte(0)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(len(blocks)))
+	for b, blk := range blocks {
+		binary.Write(&buf, binary.LittleEndian, [3]uint32{blk.low, blk.high, offsets[b]})
+	}
+	buf.Write(entries.Bytes())
+	return buf.Bytes()
+}
+
+// coff собирает объектный файл с секцией .rsrc: дерево ресурсов
+// тип/имя/язык и одна запись данных с таблицей сообщений. Адрес данных
+// в IMAGE_RESOURCE_DATA_ENTRY заполняет линкер по relocation
+func coff(m machine, table []byte) []byte {
+	const (
+		fileHeaderSize    = 20
+		sectionHeaderSize = 40
+		dataEntryOffset   = 3 * (16 + 8)
+		tableOffset       = dataEntryOffset + 16
+	)
+
+	var rsrc bytes.Buffer
+	directory := func(id, offset uint32) {
+		binary.Write(&rsrc, binary.LittleEndian, [4]uint32{0, 0, 0, 1 << 16}) // одна запись с ID
+		binary.Write(&rsrc, binary.LittleEndian, [2]uint32{id, offset})
+	}
+	directory(rtMessageTable, 0x80000000|(16+8))
+	directory(1, 0x80000000|2*(16+8))
+	directory(langEnUS, dataEntryOffset)
+	binary.Write(&rsrc, binary.LittleEndian, [4]uint32{tableOffset, uint32(len(table)), 0, 0})
+	rsrc.Write(table)
+	for rsrc.Len()%8 != 0 {
+		rsrc.WriteByte(0)
+	}
+
+	rawOffset := uint32(fileHeaderSize + sectionHeaderSize)
+	relocOffset := rawOffset + uint32(rsrc.Len())
+	symbolOffset := relocOffset + 10
+
+	var out bytes.Buffer
+	w := func(v interface{}) { binary.Write(&out, binary.LittleEndian, v) }
+	// IMAGE_FILE_HEADER
+	w(m.machine)
+	w(uint16(1))    // NumberOfSections
+	w(uint32(0))    // TimeDateStamp
+	w(symbolOffset) // PointerToSymbolTable
+	w(uint32(1))    // NumberOfSymbols
+	w(uint16(0))    // SizeOfOptionalHeader
+	w(uint16(0))    // Characteristics
+	// IMAGE_SECTION_HEADER
+	w([8]byte{'.', 'r', 's', 'r', 'c'})
+	w(uint32(0))            // VirtualSize
+	w(uint32(0))            // VirtualAddress
+	w(uint32(rsrc.Len()))   // SizeOfRawData
+	w(rawOffset)            // PointerToRawData
+	w(relocOffset)          // PointerToRelocations
+	w(uint32(0))            // PointerToLinenumbers
+	w(uint16(1))            // NumberOfRelocations
+	w(uint16(0))            // NumberOfLinenumbers
+	w(uint32(sectionFlags)) // Characteristics
+	out.Write(rsrc.Bytes())
+	// IMAGE_RELOCATION для OffsetToData записи данных
+	w(uint32(dataEntryOffset))
+	w(uint32(0)) // символ секции .rsrc
+	w(m.reloc)
+	// IMAGE_SYMBOL секции .rsrc
+	w([8]byte{'.', 'r', 's', 'r', 'c'})
+	w(uint32(0))             // Value
+	w(int16(1))              // SectionNumber
+	w(uint16(0))             // Type
+	w(uint8(symClassStatic)) // StorageClass
+	w(uint8(0))              // NumberOfAuxSymbols
+	// Пустая таблица строк
+	w(uint32(4))
+	return out.Bytes()
+}
@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"encoding/binary"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// IndexRecordSize размер записи индекса: время записи лога (UnixNano)
+	// и смещение ее строки в файле, оба int64 little-endian
+	IndexRecordSize = 16
+	// indexStride байт лога между соседними записями индекса
+	indexStride = 64 * 1024
+	// indexMaxRecords предел записей индекса (1 МБ). При достижении
+	// остается каждая вторая запись, а шаг удваивается
+	indexMaxRecords = 64 * 1024
+)
+
+// IndexPath возвращает путь к индексу времени файла лога
+func IndexPath(logFile string) string {
+	return logFile + ".idx"
+}
+
+// EncodeIndexRecord кодирует запись индекса
+func EncodeIndexRecord(ts time.Time, offset int64) []byte {
+	b := make([]byte, IndexRecordSize)
+	binary.LittleEndian.PutUint64(b, uint64(ts.UnixNano()))
+	binary.LittleEndian.PutUint64(b[8:], uint64(offset))
+	return b
+}
+
+// DecodeIndexRecord разбирает запись индекса
+func DecodeIndexRecord(b []byte) (time.Time, int64) {
+	ts := int64(binary.LittleEndian.Uint64(b))
+	return time.Unix(0, ts).UTC(), int64(binary.LittleEndian.Uint64(b[8:]))
+}
+
+// index ведет индекс времени файла лога: смещения строк через каждые
+// stride байт, по которым logs --since переходит сразу к нужному месту
+type index struct {
+	mu   sync.Mutex
+	file *os.File
+	// size текущий размер файла лога, last - смещение последней
+	// проиндексированной строки (-1 - индекс пуст)
+	size    int64
+	last    int64
+	records int
+	stride  int64
+	max     int
+}
+
+// openIndex открывает индекс файла лога log. Индекс, не совпадающий
+// с файлом (лог усечен или заменен), начинается заново. Ошибка индекса
+// не мешает логированию: без него logs --since читает файл целиком
+func openIndex(log *os.File) (*index, error) {
+	st, err := log.Stat()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(IndexPath(log.Name()), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	ix := &index{file: f, size: st.Size(), last: -1, stride: indexStride, max: indexMaxRecords}
+
+	ist, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	ix.records = int(ist.Size() / IndexRecordSize)
+	if ix.records > 0 {
+		rec := make([]byte, IndexRecordSize)
+		if _, err := f.ReadAt(rec, int64(ix.records-1)*IndexRecordSize); err == nil {
+			_, ix.last = DecodeIndexRecord(rec)
+		}
+		if ix.last < 0 || ix.last >= ix.size {
+			ix.records, ix.last = 0, -1
+		}
+	}
+	if err := f.Truncate(int64(ix.records) * IndexRecordSize); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return ix, nil
+}
+
+// write пишет строку лога в log и при необходимости добавляет ее в индекс
+func (ix *index) write(log *os.File, ts time.Time, frame []byte) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	offset := ix.size
+	n, _ := log.Write(frame)
+	ix.size += int64(n)
+	if n != len(frame) || ix.last >= 0 && offset-ix.last < ix.stride {
+		return
+	}
+	if _, err := ix.file.WriteAt(EncodeIndexRecord(ts, offset), int64(ix.records)*IndexRecordSize); err != nil {
+		return
+	}
+	ix.records++
+	ix.last = offset
+	if ix.records >= ix.max {
+		ix.compact()
+	}
+}
+
+// compact оставляет каждую вторую запись индекса и удваивает шаг
+func (ix *index) compact() {
+	data := make([]byte, ix.records*IndexRecordSize)
+	if _, err := ix.file.ReadAt(data, 0); err != nil {
+		return
+	}
+	kept := 0
+	for i := 0; i < ix.records; i += 2 {
+		copy(data[kept*IndexRecordSize:], data[i*IndexRecordSize:(i+1)*IndexRecordSize])
+		kept++
+	}
+	if _, err := ix.file.WriteAt(data[:kept*IndexRecordSize], 0); err != nil {
+		return
+	}
+	if err := ix.file.Truncate(int64(kept) * IndexRecordSize); err != nil {
+		return
+	}
+	ix.records = kept
+	_, ix.last = DecodeIndexRecord(data[(kept-1)*IndexRecordSize:])
+	ix.stride *= 2
+}
+
+// close закрывает файл индекса
+func (ix *index) close() error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	return ix.file.Close()
+}
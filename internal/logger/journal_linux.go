@@ -0,0 +1,179 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// journalSocket сокет нативного протокола systemd-journald
+var journalSocket = "/run/systemd/journal/socket"
+
+// journalReserved поля журнала, которые задает сам логгер. Поле записи
+// с таким именем получает префикс, чтобы не подменить сообщение или уровень
+var journalReserved = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"SYSLOG_IDENTIFIER": true,
+	"SERVICE":           true,
+	"LEVEL":             true,
+}
+
+// journal отправляет записи в systemd-journald по нативному протоколу:
+// поля записи становятся полями журнала (TIMER=, RUN_ID=) и доступны
+// для фильтрации journalctl TIMER=every_5s
+type journal struct {
+	conn *net.UnixConn
+	addr *net.UnixAddr
+}
+
+// openJournal подключается к journald, если stdout процесса направлен
+// в журнал (systemd задает JOURNAL_STREAM для StandardOutput=journal).
+// Иначе возвращает nil и записи пишутся в stdout как JSON
+func openJournal() *journal {
+	if !stdoutIsJournal() {
+		return nil
+	}
+	j, err := dialJournal(journalSocket)
+	if err != nil {
+		return nil
+	}
+	return j
+}
+
+// dialJournal открывает datagram сокет journald
+func dialJournal(path string) (*journal, error) {
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &journal{conn: conn, addr: &net.UnixAddr{Name: path, Net: "unixgram"}}, nil
+}
+
+// stdoutIsJournal сравнивает устройство и inode из JOURNAL_STREAM
+// с дескриптором stdout
+func stdoutIsJournal() bool {
+	stream := os.Getenv("JOURNAL_STREAM")
+	if stream == "" {
+		return false
+	}
+	var dev, ino uint64
+	if _, err := fmt.Sscanf(stream, "%d:%d", &dev, &ino); err != nil {
+		return false
+	}
+	var st syscall.Stat_t
+	if err := syscall.Fstat(int(os.Stdout.Fd()), &st); err != nil {
+		return false
+	}
+	return uint64(st.Dev) == dev && uint64(st.Ino) == ino
+}
+
+// send отправляет запись в журнал
+func (j *journal) send(level Level, entry LogEntry) error {
+	_, _, err := j.conn.WriteMsgUnix(encodeJournal(level, entry), nil, j.addr)
+	return err
+}
+
+// close закрывает сокет
+func (j *journal) close() error {
+	return j.conn.Close()
+}
+
+// encodeJournal кодирует запись в формате нативного протокола journald:
+// KEY=value для однострочных значений и KEY\n<длина uint64 LE><value>\n
+// для значений с переводом строки
+func encodeJournal(level Level, entry LogEntry) []byte {
+	var b bytes.Buffer
+	writeJournalField(&b, "MESSAGE", entry.Message)
+	writeJournalField(&b, "PRIORITY", fmt.Sprint(journalPriority(level)))
+	writeJournalField(&b, "SYSLOG_IDENTIFIER", entry.Service)
+	if entry.InstanceID != "" {
+		writeJournalField(&b, "INSTANCE_ID", entry.InstanceID)
+	}
+
+	keys := make([]string, 0, len(entry.Fields))
+	for k := range entry.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writeJournalField(&b, journalFieldName(k), journalValue(entry.Fields[k]))
+	}
+	return b.Bytes()
+}
+
+// writeJournalField записывает одно поле
+func writeJournalField(b *bytes.Buffer, name, value string) {
+	b.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteByte('\n')
+	binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// journalFieldName преобразует имя поля записи в имя поля журнала:
+// верхний регистр, символы кроме A-Z, 0-9 и _ заменяются на _. Имя
+// не может начинаться с цифры или _ (такие поля journald считает
+// доверенными) и совпадать с полями логгера
+func journalFieldName(key string) string {
+	name := []byte(strings.ToUpper(key))
+	for i, c := range name {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			name[i] = '_'
+		}
+	}
+	s := string(name)
+	if s == "" || s[0] == '_' || s[0] >= '0' && s[0] <= '9' || journalReserved[s] {
+		s = "FIELD_" + strings.TrimLeft(s, "_")
+	}
+	if len(s) > 64 {
+		s = s[:64]
+	}
+	return s
+}
+
+// journalValue преобразует значение поля в строку: строки как есть,
+// остальное в JSON
+func journalValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	case error:
+		return v.Error()
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// journalPriority возвращает приоритет syslog для уровня
+func journalPriority(level Level) int {
+	switch level {
+	case DebugLevel:
+		return 7
+	case InfoLevel:
+		return 6
+	case WarnLevel:
+		return 4
+	case ErrorLevel:
+		return 3
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestJournalFields проверяет кодирование полей записи в поля журнала
+// и отправку в сокет journald
+func TestJournalFields(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "journal.sock")
+	server, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unixgram not supported: %v", err)
+	}
+	defer server.Close()
+
+	j, err := dialJournal(socket)
+	if err != nil {
+		t.Fatalf("dialJournal() error = %v", err)
+	}
+	defer j.close()
+
+	entry := LogEntry{
+		Message: "Timer executed",
+		Service: "test-service",
+		Fields: map[string]interface{}{
+			"timer":    "every_5s",
+			"run_id":   "r-1",
+			"duration": 1.5,
+			"priority": "high",
+			"output":   "line1\nline2",
+		},
+	}
+	if err := j.send(WarnLevel, entry); err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+
+	buf := make([]byte, 4096)
+	server.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := server.Read(buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	data := string(buf[:n])
+	for _, want := range []string{
+		"MESSAGE=Timer executed\n",
+		"PRIORITY=4\n",
+		"SYSLOG_IDENTIFIER=test-service\n",
+		"TIMER=every_5s\n",
+		"RUN_ID=r-1\n",
+		"DURATION=1.5\n",
+		"FIELD_PRIORITY=high\n",
+	} {
+		if !strings.Contains(data, want) {
+			t.Errorf("journal datagram missing %q:\n%s", want, data)
+		}
+	}
+
+	var multiline bytes.Buffer
+	multiline.WriteString("OUTPUT\n")
+	binary.Write(&multiline, binary.LittleEndian, uint64(len("line1\nline2")))
+	multiline.WriteString("line1\nline2\n")
+	if !strings.Contains(data, multiline.String()) {
+		t.Errorf("multiline field not encoded with length prefix:\n%q", data)
+	}
+
+	for key, want := range map[string]string{
+		"http.status": "HTTP_STATUS",
+		"_pid":        "FIELD_PID",
+		"1st":         "FIELD_1ST",
+		"message":     "FIELD_MESSAGE",
+	} {
+		if got := journalFieldName(key); got != want {
+			t.Errorf("journalFieldName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level представляет уровень логирования
+type Level int
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+	FatalLevel
+)
+
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	case FatalLevel:
+		return "fatal"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseLevel преобразует строку в уровень логирования
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return DebugLevel, nil
+	case "info", "":
+		return InfoLevel, nil
+	case "warn", "warning":
+		return WarnLevel, nil
+	case "error":
+		return ErrorLevel, nil
+	case "fatal":
+		return FatalLevel, nil
+	default:
+		return InfoLevel, fmt.Errorf("unknown log level %q", s)
+	}
+}
+
+// LogEntry представляет одну запись в логе. InstanceID и Hostname
+// задаются через SetInstance и различают экземпляры в общем хранилище логов,
+// Labels - метки окружения из SetLabels (например, под Kubernetes)
+type LogEntry struct {
+	Timestamp  string                 `json:"timestamp"`
+	Level      string                 `json:"level"`
+	Service    string                 `json:"service"`
+	InstanceID string                 `json:"instance_id,omitempty"`
+	Hostname   string                 `json:"hostname,omitempty"`
+	Labels     map[string]string      `json:"labels,omitempty"`
+	Message    string                 `json:"message"`
+	Fields     map[string]interface{} `json:"fields,omitempty"`
+}
@@ -1,65 +1,38 @@
-//go:build !windows
-// +build !windows
-
 // Package logger предоставляет кроссплатформенное логирование для Linux
 package logger
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"path/filepath"
 	"sync"
 	"time"
 )
 
-// Level представляет уровень логирования
-type Level int
-
-const (
-	DebugLevel Level = iota
-	InfoLevel
-	WarnLevel
-	ErrorLevel
-	FatalLevel
-)
-
-func (l Level) String() string {
-	switch l {
-	case DebugLevel:
-		return "debug"
-	case InfoLevel:
-		return "info"
-	case WarnLevel:
-		return "warn"
-	case ErrorLevel:
-		return "error"
-	case FatalLevel:
-		return "fatal"
-	default:
-		return "unknown"
-	}
-}
-
 // Logger представляет структурированный JSON логгер
 type Logger struct {
-	mu      sync.RWMutex
-	level   Level
-	file    *os.File
-	writer  io.Writer
+	mu    sync.RWMutex
+	level Level
+	file  *os.File
+	// stdout получает JSON строки записей (nil, если записи идут в journald
+	// или в человекочитаемую консоль)
+	stdout  io.Writer
+	console io.Writer
+	// encoder кодирование записей в файле (nil - JSON)
+	encoder Encoder
 	logDir  string
 	service string
-}
-
-// LogEntry представляет одну запись в логе
-type LogEntry struct {
-	Timestamp string                 `json:"timestamp"`
-	Level     string                 `json:"level"`
-	Service   string                 `json:"service"`
-	Message   string                 `json:"message"`
-	Fields    map[string]interface{} `json:"fields,omitempty"`
+	// instanceID и hostname экземпляра, добавляются в каждую запись
+	instanceID string
+	hostname   string
+	labels     map[string]string
+	onFatal    func(msg string, fields map[string]interface{})
+	ring       *Ring
+	// index индекс времени файла лога (nil - не открылся)
+	index *index
+	// journal нативная отправка в journald вместо JSON в stdout
+	journal *journal
 }
 
 // New создает новый логгер
@@ -70,24 +43,45 @@ func New(serviceName, logDir string) (*Logger, error) {
 	}
 
 	// Открываем файл для логирования
-	logFile := filepath.Join(logDir, serviceName+".log")
+	logFile := FilePath(logDir, serviceName)
 	file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open log file: %w", err)
 	}
+	ix, err := openIndex(file)
+	if err != nil {
+		log.Printf("failed to open log index: %v", err)
+	}
 
-	// Создаем multiwriter для записи и в файл, и в stdout (для journald)
-	writer := io.MultiWriter(file, os.Stdout)
+	// Записи пишутся и в файл, и в stdout. Если stdout направлен
+	// в journald, записи отправляются в журнал с полями вместо JSON строки
+	var stdout io.Writer = os.Stdout
+	j := openJournal()
+	if j != nil {
+		stdout = nil
+	}
 
 	return &Logger{
 		level:   InfoLevel,
 		file:    file,
-		writer:  writer,
+		index:   ix,
+		stdout:  stdout,
 		logDir:  logDir,
 		service: serviceName,
+		journal: j,
 	}, nil
 }
 
+// NewStdout создает логгер без файла лога: записи идут JSON строками
+// в stdout (режим Kubernetes, где логи собирает среда выполнения)
+func NewStdout(serviceName string) *Logger {
+	return &Logger{
+		level:   InfoLevel,
+		stdout:  os.Stdout,
+		service: serviceName,
+	}
+}
+
 // SetLevel устанавливает уровень логирования
 func (l *Logger) SetLevel(level Level) {
 	l.mu.Lock()
@@ -95,6 +89,26 @@ func (l *Logger) SetLevel(level Level) {
 	l.level = level
 }
 
+// GetLevel возвращает текущий уровень логирования
+func (l *Logger) GetLevel() Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.level
+}
+
+// SetPrettyConsole выводит записи в w в человекочитаемом формате.
+// В файл по-прежнему пишется JSON (или кодирование SetEncoder)
+func (l *Logger) SetPrettyConsole(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stdout = nil
+	l.console = w
+	if l.journal != nil {
+		l.journal.close()
+		l.journal = nil
+	}
+}
+
 // log записывает сообщение в лог
 func (l *Logger) log(level Level, msg string, fields map[string]interface{}) {
 	l.mu.RLock()
@@ -102,25 +116,74 @@ func (l *Logger) log(level Level, msg string, fields map[string]interface{}) {
 		l.mu.RUnlock()
 		return
 	}
-	writer := l.writer
+	file := l.file
+	stdout := l.stdout
+	encoder := l.encoder
+	console := l.console
 	service := l.service
+	instanceID := l.instanceID
+	hostname := l.hostname
+	labels := l.labels
+	ring := l.ring
+	ix := l.index
+	journal := l.journal
 	l.mu.RUnlock()
 
+	now := time.Now().UTC()
 	entry := LogEntry{
-		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
-		Level:     level.String(),
-		Service:   service,
-		Message:   msg,
-		Fields:    fields,
+		Timestamp:  now.Format(time.RFC3339Nano),
+		Level:      level.String(),
+		Service:    service,
+		InstanceID: instanceID,
+		Hostname:   hostname,
+		Labels:     labels,
+		Message:    msg,
+		Fields:     fields,
+	}
+	if ring != nil {
+		ring.Add(level, entry)
 	}
 
-	data, err := json.Marshal(entry)
-	if err != nil {
-		log.Printf("failed to marshal log entry: %v", err)
-		return
+	// В stdout запись выводится JSON строкой, в файл - выбранным кодированием
+	var line []byte
+	var err error
+	if encoder == nil || stdout != nil {
+		if line, err = jsonLine(entry); err != nil {
+			log.Printf("failed to marshal log entry: %v", err)
+			return
+		}
+	}
+	frame := line
+	if encoder != nil {
+		if frame, err = encoder.Encode(entry); err != nil {
+			log.Printf("failed to encode log entry: %v", err)
+			return
+		}
 	}
 
-	fmt.Fprintln(writer, string(data))
+	switch {
+	case file == nil:
+		// Без файла лога (NewStdout) запись выводится только в stdout
+	case ix != nil:
+		ix.write(file, now, frame)
+	default:
+		file.Write(frame)
+	}
+	if stdout != nil {
+		stdout.Write(line)
+	}
+	if journal != nil {
+		if err := journal.send(level, entry); err != nil {
+			// Журнал недоступен или запись слишком велика: пишем в stdout как раньше
+			if line == nil {
+				line, _ = jsonLine(entry)
+			}
+			os.Stdout.Write(line)
+		}
+	}
+	if console != nil {
+		fmt.Fprintln(console, Pretty(entry))
+	}
 }
 
 // Debug записывает debug сообщение
@@ -167,9 +230,53 @@ func (l *Logger) Fatal(msg string, fields ...map[string]interface{}) {
 	}
 	l.log(FatalLevel, msg, f)
 	l.Flush()
+
+	l.mu.RLock()
+	onFatal := l.onFatal
+	l.mu.RUnlock()
+	if onFatal != nil {
+		onFatal(msg, f)
+	}
 	os.Exit(1)
 }
 
+// SetInstance задает идентификатор экземпляра и имя узла, которые
+// добавляются в каждую запись лога
+func (l *Logger) SetInstance(instanceID, hostname string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.instanceID = instanceID
+	l.hostname = hostname
+}
+
+// SetLabels задает метки окружения, которые добавляются в каждую запись лога
+func (l *Logger) SetLabels(labels map[string]string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.labels = labels
+}
+
+// SetEncoder задает кодирование записей в файле лога (LookupEncoder).
+// nil - JSON строки. stdout, journald и буфер последних записей
+// не меняются. Вызывается до первой записи
+func (l *Logger) SetEncoder(enc Encoder) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.encoder = enc
+}
+
+// SetFatalHook задает функцию, вызываемую в Fatal перед завершением
+// программы (например, для записи отчета о падении)
+func (l *Logger) SetFatalHook(fn func(msg string, fields map[string]interface{})) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onFatal = fn
+}
+
+// SetEventTranslator задает перевод сообщений для Event Log. На Linux
+// Event Log нет, вызов ничего не меняет
+func (l *Logger) SetEventTranslator(fn func(msg string) string) {}
+
 // Flush сбрасывает буферы логирования
 func (l *Logger) Flush() error {
 	l.mu.Lock()
@@ -184,6 +291,14 @@ func (l *Logger) Flush() error {
 func (l *Logger) Close() error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	if l.journal != nil {
+		l.journal.close()
+		l.journal = nil
+	}
+	if l.index != nil {
+		l.index.close()
+		l.index = nil
+	}
 	if l.file != nil {
 		return l.file.Close()
 	}
@@ -196,6 +311,12 @@ func RegisterEventSource(serviceName string) error {
 	return nil
 }
 
+// EventSourceRegistered проверяет регистрацию источника событий (только для Windows, на Linux всегда false)
+func EventSourceRegistered(serviceName string) (bool, error) {
+	// На Linux не используется Windows Event Log
+	return false, nil
+}
+
 // UnregisterEventSource удаляет источник событий (только для Windows, на Linux no-op)
 func UnregisterEventSource(serviceName string) error {
 	// На Linux не используется Windows Event Log
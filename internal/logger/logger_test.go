@@ -269,3 +269,219 @@ func TestFlush(t *testing.T) {
 		t.Error("Log message not found after Flush()")
 	}
 }
+
+// TestSetPrettyConsole проверяет человекочитаемый вывод в консоль при JSON в файле
+func TestSetPrettyConsole(t *testing.T) {
+	logDir := t.TempDir()
+
+	logger, err := New("test-service", logDir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer logger.Close()
+
+	var console strings.Builder
+	logger.SetPrettyConsole(&console)
+	logger.SetLevel(DebugLevel)
+	logger.Debug("pretty message", map[string]interface{}{"key": "value"})
+	logger.Flush()
+
+	if !strings.Contains(console.String(), "DEBUG pretty message key=value") {
+		t.Errorf("console output = %q", console.String())
+	}
+
+	content, err := os.ReadFile(filepath.Join(logDir, "test-service.log"))
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	var entry LogEntry
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(content))), &entry); err != nil {
+		t.Fatalf("log file is not JSON: %v", err)
+	}
+	if entry.Message != "pretty message" {
+		t.Errorf("entry.Message = %q, want %q", entry.Message, "pretty message")
+	}
+}
+
+// TestSetEncoder проверяет запись файла зарегистрированным кодированием:
+// кадры с префиксом длины вместо JSON строк
+func TestSetEncoder(t *testing.T) {
+	logDir := t.TempDir()
+	RegisterEncoder("test-frames", EncoderFunc(func(e LogEntry) ([]byte, error) {
+		payload := e.Level + "|" + e.Message
+		return append([]byte{byte(len(payload))}, payload...), nil
+	}))
+	enc, err := LookupEncoder("test-frames")
+	if err != nil {
+		t.Fatalf("LookupEncoder() error = %v", err)
+	}
+	if _, err := LookupEncoder("msgpack"); err == nil || !strings.Contains(err.Error(), "test-frames") {
+		t.Errorf("LookupEncoder(msgpack) error = %v, want unknown with registered names", err)
+	}
+
+	logger, err := New("test-service", logDir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer logger.Close()
+	var console strings.Builder
+	logger.SetPrettyConsole(&console)
+	ring := NewRing(10)
+	logger.SetRing(ring)
+	logger.SetEncoder(enc)
+
+	logger.Info("first")
+	logger.Warn("second")
+	logger.Flush()
+
+	content, err := os.ReadFile(filepath.Join(logDir, "test-service.log"))
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if want := "\x0ainfo|first\x0bwarn|second"; string(content) != want {
+		t.Errorf("log file = %q, want %q", content, want)
+	}
+	if !strings.Contains(console.String(), "WARN  second") {
+		t.Errorf("console output = %q, want pretty entries", console.String())
+	}
+	if entries := ring.Entries(DebugLevel, 0); len(entries) != 2 {
+		t.Errorf("ring entries = %d, want 2", len(entries))
+	}
+}
+
+// TestRing проверяет буфер последних записей: вытеснение, фильтр и лимит
+func TestRing(t *testing.T) {
+	logger, err := New("test-service", t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer logger.Close()
+
+	ring := NewRing(3)
+	logger.SetRing(ring)
+	logger.SetLevel(DebugLevel)
+
+	fields := map[string]interface{}{"n": 1}
+	logger.Debug("first")
+	logger.Info("second", fields)
+	fields["n"] = 2
+	logger.Error("third")
+	logger.Warn("fourth")
+
+	messages := func(entries []LogEntry) []string {
+		var result []string
+		for _, e := range entries {
+			result = append(result, e.Message)
+		}
+		return result
+	}
+
+	all := ring.Entries(DebugLevel, 0)
+	if got := strings.Join(messages(all), ","); got != "second,third,fourth" {
+		t.Errorf("Entries() = %s, want second,third,fourth", got)
+	}
+	if all[0].Fields["n"] != 1 {
+		t.Errorf("Entries()[0].Fields = %v, want copy taken at write time", all[0].Fields)
+	}
+	if got := strings.Join(messages(ring.Entries(WarnLevel, 0)), ","); got != "third,fourth" {
+		t.Errorf("Entries(warn) = %s, want third,fourth", got)
+	}
+	if got := strings.Join(messages(ring.Entries(DebugLevel, 1)), ","); got != "fourth" {
+		t.Errorf("Entries(limit=1) = %s, want fourth", got)
+	}
+}
+
+// TestEventCategoryOf проверяет категории и идентификаторы событий Event Log
+func TestEventCategoryOf(t *testing.T) {
+	tests := []struct {
+		msg   string
+		level Level
+		want  uint32
+	}{
+		{"Timer panic recovered", ErrorLevel, 202},
+		{"Failed to save timer last run", WarnLevel, 201},
+		{"Job moved to dead letter", ErrorLevel, 302},
+		{"gRPC control unauthorized call", WarnLevel, 401},
+		{"HTTP client request failed", WarnLevel, 501},
+		{"Failed to start service", FatalLevel, 603},
+		{"Tracing error", ErrorLevel, 102},
+		{"Tracing error", InfoLevel, 0},
+	}
+	for _, tt := range tests {
+		if got := EventCategoryOf(tt.msg).EventID(tt.level); got != tt.want {
+			t.Errorf("EventID(%q, %s) = %d, want %d", tt.msg, tt.level, got, tt.want)
+		}
+	}
+
+	ids := make(map[uint16]bool)
+	for i, c := range EventCategories {
+		if c.ID != uint16(i+1) || ids[c.ID] {
+			t.Errorf("EventCategories[%d].ID = %d, want %d", i, c.ID, i+1)
+		}
+		ids[c.ID] = true
+	}
+}
+
+// TestIndex проверяет индекс времени файла лога: записи указывают на строки
+// с тем же временем, а размер индекса ограничен
+func TestIndex(t *testing.T) {
+	tmpDir := t.TempDir()
+	logger, err := New("test-service", tmpDir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if logger.index == nil {
+		t.Fatal("log index was not opened")
+	}
+	logger.index.stride = 512
+	logger.index.max = 8
+	for i := 0; i < 200; i++ {
+		logger.Info("Indexed message", map[string]interface{}{"i": i})
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	logFile := FilePath(tmpDir, "test-service")
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := os.ReadFile(IndexPath(logFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx)%IndexRecordSize != 0 || len(idx) == 0 || len(idx) >= 8*IndexRecordSize {
+		t.Fatalf("index size = %d, want 1-7 records", len(idx))
+	}
+	prev := int64(-1)
+	for i := 0; i < len(idx); i += IndexRecordSize {
+		ts, offset := DecodeIndexRecord(idx[i:])
+		if offset <= prev || offset >= int64(len(content)) || offset > 0 && content[offset-1] != '\n' {
+			t.Fatalf("record %d offset = %d, not a line start after %d", i/IndexRecordSize, offset, prev)
+		}
+		prev = offset
+		line := string(content[offset:])
+		line = line[:strings.IndexByte(line, '\n')]
+		var entry LogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("record %d line: %v", i/IndexRecordSize, err)
+		}
+		if entry.Timestamp != ts.Format(time.RFC3339Nano) {
+			t.Errorf("record %d time = %s, line timestamp = %s", i/IndexRecordSize, ts.Format(time.RFC3339Nano), entry.Timestamp)
+		}
+	}
+
+	// Повторное открытие продолжает индекс, усеченный лог начинает его заново
+	if err := os.Truncate(logFile, 0); err != nil {
+		t.Fatal(err)
+	}
+	logger, err = New("test-service", tmpDir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer logger.Close()
+	if logger.index.records != 0 {
+		t.Errorf("records after truncation = %d, want 0", logger.index.records)
+	}
+}
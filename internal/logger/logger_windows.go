@@ -5,64 +5,44 @@
 package logger
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
 	"golang.org/x/sys/windows/svc/eventlog"
 )
 
-// Level представляет уровень логирования
-type Level int
-
-const (
-	DebugLevel Level = iota
-	InfoLevel
-	WarnLevel
-	ErrorLevel
-	FatalLevel
-)
-
-func (l Level) String() string {
-	switch l {
-	case DebugLevel:
-		return "debug"
-	case InfoLevel:
-		return "info"
-	case WarnLevel:
-		return "warn"
-	case ErrorLevel:
-		return "error"
-	case FatalLevel:
-		return "fatal"
-	default:
-		return "unknown"
-	}
-}
+// eventSourceKey ключ реестра источников событий журнала Application
+const eventSourceKey = `SYSTEM\CurrentControlSet\Services\EventLog\Application\`
 
 // Logger представляет структурированный JSON логгер с поддержкой Windows Event Log
 type Logger struct {
-	mu       sync.RWMutex
-	level    Level
-	file     *os.File
-	writer   io.Writer
-	logDir   string
-	service  string
-	eventLog *eventlog.Log
-}
-
-// LogEntry представляет одну запись в логе
-type LogEntry struct {
-	Timestamp string                 `json:"timestamp"`
-	Level     string                 `json:"level"`
-	Service   string                 `json:"service"`
-	Message   string                 `json:"message"`
-	Fields    map[string]interface{} `json:"fields,omitempty"`
+	mu      sync.RWMutex
+	level   Level
+	file    *os.File
+	console io.Writer
+	// encoder кодирование записей в файле (nil - JSON)
+	encoder Encoder
+	logDir  string
+	service string
+	// instanceID и hostname экземпляра, добавляются в каждую запись
+	instanceID string
+	hostname   string
+	labels     map[string]string
+	eventLog   *eventlog.Log
+	onFatal    func(msg string, fields map[string]interface{})
+	ring       *Ring
+	// index индекс времени файла лога (nil - не открылся)
+	index *index
+	// translate переводит сообщения для Event Log
+	translate func(msg string) string
 }
 
 // New создает новый логгер
@@ -73,17 +53,21 @@ func New(serviceName, logDir string) (*Logger, error) {
 	}
 
 	// Открываем файл для логирования
-	logFile := filepath.Join(logDir, serviceName+".log")
+	logFile := FilePath(logDir, serviceName)
 	file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open log file: %w", err)
 	}
+	ix, err := openIndex(file)
+	if err != nil {
+		log.Printf("failed to open log index: %v", err)
+	}
 
 	// Открываем Windows Event Log
 	var el *eventlog.Log
 	if el, err = eventlog.Open(serviceName); err != nil {
 		// Если не удалось открыть, пробуем создать
-		if err = eventlog.InstallAsEventCreate(serviceName, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+		if err = RegisterEventSource(serviceName); err != nil {
 			log.Printf("failed to install event log source: %v", err)
 		} else {
 			el, _ = eventlog.Open(serviceName)
@@ -93,13 +77,22 @@ func New(serviceName, logDir string) (*Logger, error) {
 	return &Logger{
 		level:    InfoLevel,
 		file:     file,
-		writer:   file,
+		index:    ix,
 		logDir:   logDir,
 		service:  serviceName,
 		eventLog: el,
 	}, nil
 }
 
+// NewStdout создает логгер без файла лога и Event Log: записи идут
+// в stdout (режим Kubernetes, где логи собирает среда выполнения)
+func NewStdout(serviceName string) *Logger {
+	return &Logger{
+		level:   InfoLevel,
+		service: serviceName,
+	}
+}
+
 // SetLevel устанавливает уровень логирования
 func (l *Logger) SetLevel(level Level) {
 	l.mu.Lock()
@@ -107,6 +100,21 @@ func (l *Logger) SetLevel(level Level) {
 	l.level = level
 }
 
+// GetLevel возвращает текущий уровень логирования
+func (l *Logger) GetLevel() Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.level
+}
+
+// SetPrettyConsole выводит записи в w в человекочитаемом формате.
+// В файл по-прежнему пишется JSON (или кодирование SetEncoder)
+func (l *Logger) SetPrettyConsole(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.console = w
+}
+
 // log записывает сообщение в лог
 func (l *Logger) log(level Level, msg string, fields map[string]interface{}) {
 	l.mu.RLock()
@@ -114,38 +122,88 @@ func (l *Logger) log(level Level, msg string, fields map[string]interface{}) {
 		l.mu.RUnlock()
 		return
 	}
-	writer := l.writer
+	file := l.file
+	encoder := l.encoder
+	console := l.console
 	service := l.service
+	instanceID := l.instanceID
+	hostname := l.hostname
+	labels := l.labels
 	eventLog := l.eventLog
+	ring := l.ring
+	ix := l.index
+	translate := l.translate
 	l.mu.RUnlock()
 
+	now := time.Now().UTC()
 	entry := LogEntry{
-		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
-		Level:     level.String(),
-		Service:   service,
-		Message:   msg,
-		Fields:    fields,
+		Timestamp:  now.Format(time.RFC3339Nano),
+		Level:      level.String(),
+		Service:    service,
+		InstanceID: instanceID,
+		Hostname:   hostname,
+		Labels:     labels,
+		Message:    msg,
+		Fields:     fields,
+	}
+	if ring != nil {
+		ring.Add(level, entry)
 	}
 
-	data, err := json.Marshal(entry)
+	var frame []byte
+	var err error
+	if encoder != nil {
+		frame, err = encoder.Encode(entry)
+	} else {
+		frame, err = jsonLine(entry)
+	}
 	if err != nil {
-		log.Printf("failed to marshal log entry: %v", err)
+		log.Printf("failed to encode log entry: %v", err)
 		return
 	}
 
-	fmt.Fprintln(writer, string(data))
+	switch {
+	case file == nil:
+		// Без файла лога (NewStdout) записи идут в stdout, если он
+		// не занят человекочитаемым выводом
+		if console == nil {
+			os.Stdout.Write(frame)
+		}
+	case ix != nil:
+		ix.write(file, now, frame)
+	default:
+		file.Write(frame)
+	}
+	if console != nil {
+		fmt.Fprintln(console, Pretty(entry))
+	}
 
 	// Также пишем в Windows Event Log для важных сообщений
 	if eventLog != nil && level >= WarnLevel {
-		switch level {
-		case WarnLevel:
-			eventLog.Warning(1, msg)
-		case ErrorLevel, FatalLevel:
-			eventLog.Error(2, msg)
+		// Категория определяется по исходному сообщению, до перевода
+		category := EventCategoryOf(msg)
+		if translate != nil {
+			msg = translate(msg)
 		}
+		reportEvent(eventLog, level, category, msg)
 	}
 }
 
+// reportEvent пишет запись в Event Log с категорией и идентификатором
+// события из таблицы сообщений исполняемого файла
+func reportEvent(el *eventlog.Log, level Level, category EventCategory, msg string) {
+	etype := uint16(windows.EVENTLOG_WARNING_TYPE)
+	if level >= ErrorLevel {
+		etype = windows.EVENTLOG_ERROR_TYPE
+	}
+	text, err := windows.UTF16PtrFromString(msg)
+	if err != nil {
+		return
+	}
+	strs := []*uint16{text}
+	windows.ReportEvent(el.Handle, etype, category.ID, category.EventID(level), 0, 1, 0, &strs[0], nil)
+}
+
 // Debug записывает debug сообщение
 func (l *Logger) Debug(msg string, fields ...map[string]interface{}) {
 	var f map[string]interface{}
@@ -190,9 +248,57 @@ func (l *Logger) Fatal(msg string, fields ...map[string]interface{}) {
 	}
 	l.log(FatalLevel, msg, f)
 	l.Flush()
+
+	l.mu.RLock()
+	onFatal := l.onFatal
+	l.mu.RUnlock()
+	if onFatal != nil {
+		onFatal(msg, f)
+	}
 	os.Exit(1)
 }
 
+// SetInstance задает идентификатор экземпляра и имя узла, которые
+// добавляются в каждую запись лога
+func (l *Logger) SetInstance(instanceID, hostname string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.instanceID = instanceID
+	l.hostname = hostname
+}
+
+// SetLabels задает метки окружения, которые добавляются в каждую запись лога
+func (l *Logger) SetLabels(labels map[string]string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.labels = labels
+}
+
+// SetEncoder задает кодирование записей в файле лога (LookupEncoder).
+// nil - JSON строки. Event Log и буфер последних записей не меняются.
+// Вызывается до первой записи
+func (l *Logger) SetEncoder(enc Encoder) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.encoder = enc
+}
+
+// SetFatalHook задает функцию, вызываемую в Fatal перед завершением
+// программы (например, для записи отчета о падении)
+func (l *Logger) SetFatalHook(fn func(msg string, fields map[string]interface{})) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onFatal = fn
+}
+
+// SetEventTranslator задает перевод сообщений, записываемых в Event Log.
+// JSON лог всегда пишется с исходными сообщениями
+func (l *Logger) SetEventTranslator(fn func(msg string) string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.translate = fn
+}
+
 // Flush сбрасывает буферы логирования
 func (l *Logger) Flush() error {
 	l.mu.Lock()
@@ -210,15 +316,67 @@ func (l *Logger) Close() error {
 	if l.eventLog != nil {
 		l.eventLog.Close()
 	}
+	if l.index != nil {
+		l.index.close()
+		l.index = nil
+	}
 	if l.file != nil {
 		return l.file.Close()
 	}
 	return nil
 }
 
-// RegisterEventSource регистрирует источник событий Windows
+// RegisterEventSource регистрирует источник событий Windows. Таблица
+// сообщений и имена категорий берутся из текущего исполняемого файла
+// (msgtable_windows_*.syso), поэтому Event Viewer показывает текст
+// записи без "описание не найдено". Существующая регистрация, например
+// прежняя через EventCreate.exe, перезаписывается
 func RegisterEventSource(serviceName string) error {
-	return eventlog.InstallAsEventCreate(serviceName, eventlog.Error|eventlog.Warning|eventlog.Info)
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+	k, _, err := registry.CreateKey(registry.LOCAL_MACHINE, eventSourceKey+serviceName, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+
+	if err := k.SetStringValue("EventMessageFile", exe); err != nil {
+		return err
+	}
+	if err := k.SetStringValue("CategoryMessageFile", exe); err != nil {
+		return err
+	}
+	if err := k.SetDWordValue("CategoryCount", uint32(len(EventCategories))); err != nil {
+		return err
+	}
+	return k.SetDWordValue("TypesSupported", eventlog.Error|eventlog.Warning|eventlog.Info)
+}
+
+// EventSourceRegistered проверяет, зарегистрирован ли источник событий
+// Windows с таблицей сообщений текущего исполняемого файла. Регистрация
+// с другим файлом сообщений (прежняя версия, перенесенный бинарник)
+// считается отсутствующей, чтобы установка ее обновила
+func EventSourceRegistered(serviceName string) (bool, error) {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, eventSourceKey+serviceName, registry.QUERY_VALUE)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer k.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return false, fmt.Errorf("failed to get executable path: %w", err)
+	}
+	file, _, err := k.GetStringValue("EventMessageFile")
+	if err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return false, err
+	}
+	return strings.EqualFold(file, exe), nil
 }
 
 // UnregisterEventSource удаляет источник событий Windows
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Pretty возвращает человекочитаемое представление записи:
+// время, уровень, сообщение и поля в порядке сортировки ключей
+func Pretty(e LogEntry) string {
+	var b strings.Builder
+
+	ts := e.Timestamp
+	if t, err := time.Parse(time.RFC3339Nano, e.Timestamp); err == nil {
+		ts = t.Local().Format("2006-01-02 15:04:05.000")
+	}
+	if ts != "" {
+		b.WriteString(ts)
+		b.WriteByte(' ')
+	}
+	fmt.Fprintf(&b, "%-5s %s", strings.ToUpper(e.Level), e.Message)
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var multiline []string
+	for _, k := range keys {
+		v := fmt.Sprint(e.Fields[k])
+		// Многострочные значения (стектрейсы) выводим отдельным блоком
+		if strings.Contains(v, "\n") {
+			multiline = append(multiline, k)
+			continue
+		}
+		if strings.ContainsAny(v, " \t\"") {
+			v = fmt.Sprintf("%q", v)
+		}
+		fmt.Fprintf(&b, " %s=%s", k, v)
+	}
+	for _, k := range multiline {
+		fmt.Fprintf(&b, "\n  %s:\n    %s", k, strings.ReplaceAll(strings.TrimRight(fmt.Sprint(e.Fields[k]), "\n"), "\n", "\n    "))
+	}
+	return b.String()
+}
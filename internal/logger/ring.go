@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"sync"
+)
+
+// Ring кольцевой буфер последних записей лога. Позволяет посмотреть
+// недавнюю историю через admin API и в отчетах о падении, даже если
+// файл лога недоступен
+type Ring struct {
+	mu      sync.Mutex
+	entries []ringEntry
+	next    int
+	full    bool
+}
+
+// ringEntry запись буфера с разобранным уровнем
+type ringEntry struct {
+	level Level
+	entry LogEntry
+}
+
+// NewRing создает буфер на size записей
+func NewRing(size int) *Ring {
+	if size <= 0 {
+		size = 1
+	}
+	return &Ring{entries: make([]ringEntry, size)}
+}
+
+// Add добавляет запись, вытесняя самую старую при заполнении
+func (r *Ring) Add(level Level, e LogEntry) {
+	// Поля копируются: вызывающий код может изменить map после записи
+	if e.Fields != nil {
+		fields := make(map[string]interface{}, len(e.Fields))
+		for k, v := range e.Fields {
+			fields[k] = v
+		}
+		e.Fields = fields
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[r.next] = ringEntry{level: level, entry: e}
+	r.next++
+	if r.next == len(r.entries) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// Entries возвращает записи с уровнем не ниже minLevel от старых к новым.
+// limit > 0 ограничивает количество последних записей
+func (r *Ring) Entries(minLevel Level, limit int) []LogEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ordered []ringEntry
+	if r.full {
+		ordered = append(ordered, r.entries[r.next:]...)
+	}
+	ordered = append(ordered, r.entries[:r.next]...)
+
+	result := make([]LogEntry, 0, len(ordered))
+	for _, e := range ordered {
+		if e.level >= minLevel {
+			result = append(result, e.entry)
+		}
+	}
+	if limit > 0 && len(result) > limit {
+		result = result[len(result)-limit:]
+	}
+	return result
+}
+
+// SetRing включает сохранение записей в буфер r (nil - отключает)
+func (l *Logger) SetRing(r *Ring) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ring = r
+}
+
+// Ring возвращает буфер последних записей или nil
+func (l *Logger) Ring() *Ring {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.ring
+}
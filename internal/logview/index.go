@@ -0,0 +1,68 @@
+package logview
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"service-boilerplate/internal/logger"
+)
+
+// SeekSince переводит f к строке, с которой начинаются записи не старше
+// since, по индексу времени logger.IndexPath рядом с файлом, и возвращает
+// ее смещение. Без индекса или если индекс не совпадает с файлом
+// (лог усечен, заменен или записан другим кодированием) f остается
+// в начале и читается целиком. Индекс (не больше 1 МБ) читается целиком:
+// логгер усекает его при уплотнении, и отображение в память упало бы
+// при обращении за новым концом файла
+func SeekSince(f *os.File, since time.Time) (int64, error) {
+	data, err := os.ReadFile(logger.IndexPath(f.Name()))
+	if err != nil {
+		return 0, nil
+	}
+	if len(data) < logger.IndexRecordSize {
+		return 0, nil
+	}
+
+	n := len(data) / logger.IndexRecordSize
+	record := func(i int) (time.Time, int64) {
+		return logger.DecodeIndexRecord(data[i*logger.IndexRecordSize:])
+	}
+	// Первая запись индекса не раньше since: чтение начинается с предыдущей,
+	// записи между ними отбрасывает Filter.Since
+	i := sort.Search(n, func(i int) bool {
+		ts, _ := record(i)
+		return !ts.Before(since)
+	})
+	if i == 0 {
+		return 0, nil
+	}
+	ts, offset := record(i - 1)
+	if !lineAt(f, offset, ts) {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return offset, nil
+}
+
+// lineAt проверяет, что по смещению offset в f начинается запись
+// со временем ts, то есть индекс относится к этому файлу
+func lineAt(f *os.File, offset int64, ts time.Time) bool {
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return false
+	}
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	e, ok := parseLine(line)
+	if !ok {
+		return false
+	}
+	got, err := time.Parse(time.RFC3339Nano, e.Timestamp)
+	return err == nil && got.Equal(ts)
+}
@@ -0,0 +1,115 @@
+// Package logview читает, фильтрует и форматирует JSON логи сервиса
+package logview
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"service-boilerplate/internal/logger"
+)
+
+// Filter определяет условия отбора записей
+type Filter struct {
+	// MinLevel минимальный уровень записи
+	MinLevel logger.Level
+	// Since отбрасывает записи старше указанного момента (нулевое значение = без ограничения)
+	Since time.Time
+}
+
+// Match проверяет соответствие записи фильтру
+func (f Filter) Match(e logger.LogEntry) bool {
+	if lvl, err := logger.ParseLevel(e.Level); err == nil && lvl < f.MinLevel {
+		return false
+	}
+	if !f.Since.IsZero() {
+		ts, err := time.Parse(time.RFC3339Nano, e.Timestamp)
+		if err == nil && ts.Before(f.Since) {
+			return false
+		}
+	}
+	return true
+}
+
+// Read читает записи из r и вызывает fn для каждой подходящей записи.
+// Строки, не являющиеся JSON, передаются как сообщение уровня info
+func Read(r io.Reader, filter Filter, fn func(logger.LogEntry)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if e, ok := parseLine(scanner.Text()); ok && filter.Match(e) {
+			fn(e)
+		}
+	}
+	return scanner.Err()
+}
+
+// Follow выводит записи файла и продолжает следить за его ростом
+// до отмены контекста, аналогично tail -f. Усечение файла (ротация)
+// обрабатывается чтением с начала
+func Follow(ctx context.Context, path string, filter Filter, poll time.Duration, fn func(logger.LogEntry)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	var offset int64
+	var partial string
+
+	for {
+		line, err := reader.ReadString('\n')
+		offset += int64(len(line))
+		if err == nil {
+			if e, ok := parseLine(partial + line); ok && filter.Match(e) {
+				fn(e)
+			}
+			partial = ""
+			continue
+		}
+		if err != io.EOF {
+			return err
+		}
+		// Неполная строка - дожидаемся окончания записи
+		partial += line
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(poll):
+		}
+
+		// Файл усечен - начинаем сначала
+		if st, err := f.Stat(); err == nil && st.Size() < offset {
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			reader.Reset(f)
+			offset = 0
+			partial = ""
+		}
+	}
+}
+
+// parseLine разбирает одну строку лога
+func parseLine(line string) (logger.LogEntry, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.TrimSpace(line) == "" {
+		return logger.LogEntry{}, false
+	}
+	var e logger.LogEntry
+	if err := json.Unmarshal([]byte(line), &e); err != nil || e.Message == "" && e.Level == "" {
+		return logger.LogEntry{Level: "info", Message: line}, true
+	}
+	return e, true
+}
+
+// Format возвращает человекочитаемое представление записи
+func Format(e logger.LogEntry) string {
+	return logger.Pretty(e)
+}
@@ -0,0 +1,189 @@
+package logview
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/logger"
+)
+
+const sample = `{"timestamp":"2025-01-01T10:00:00Z","level":"info","service":"svc","message":"started"}
+{"timestamp":"2025-01-01T11:00:00Z","level":"error","service":"svc","message":"failed","fields":{"timer":"t1","error":"boom"}}
+not json at all
+{"timestamp":"2025-01-01T12:00:00Z","level":"warn","service":"svc","message":"slow"}
+`
+
+// collect читает записи с фильтром
+func collect(t *testing.T, filter Filter) []logger.LogEntry {
+	var entries []logger.LogEntry
+	if err := Read(strings.NewReader(sample), filter, func(e logger.LogEntry) {
+		entries = append(entries, e)
+	}); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	return entries
+}
+
+// TestRead_All проверяет чтение всех записей
+func TestRead_All(t *testing.T) {
+	entries := collect(t, Filter{MinLevel: logger.DebugLevel})
+	if len(entries) != 4 {
+		t.Fatalf("len(entries) = %d, want 4", len(entries))
+	}
+	if entries[2].Message != "not json at all" {
+		t.Errorf("non-JSON line = %+v", entries[2])
+	}
+}
+
+// TestRead_LevelFilter проверяет фильтрацию по уровню
+func TestRead_LevelFilter(t *testing.T) {
+	entries := collect(t, Filter{MinLevel: logger.WarnLevel})
+	if len(entries) != 2 || entries[0].Message != "failed" || entries[1].Message != "slow" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+// TestRead_SinceFilter проверяет фильтрацию по времени
+func TestRead_SinceFilter(t *testing.T) {
+	since := time.Date(2025, 1, 1, 10, 30, 0, 0, time.UTC)
+	entries := collect(t, Filter{MinLevel: logger.ErrorLevel, Since: since})
+	if len(entries) != 1 || entries[0].Message != "failed" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+// TestFormat проверяет человекочитаемый вывод
+func TestFormat(t *testing.T) {
+	out := Format(logger.LogEntry{
+		Level:   "error",
+		Message: "failed",
+		Fields: map[string]interface{}{
+			"timer":      "t1",
+			"error":      "two words",
+			"stacktrace": "line1\nline2",
+		},
+	})
+	if !strings.HasPrefix(out, "ERROR failed error=\"two words\" timer=t1") {
+		t.Errorf("Format() = %q", out)
+	}
+	if !strings.Contains(out, "\n  stacktrace:\n    line1\n    line2") {
+		t.Errorf("Format() multiline = %q", out)
+	}
+}
+
+// TestFollow проверяет чтение дописываемых записей
+func TestFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "svc.log")
+	if err := os.WriteFile(path, []byte(`{"level":"info","message":"first"}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var got []string
+	done := make(chan error, 1)
+	go func() {
+		done <- Follow(ctx, path, Filter{}, 10*time.Millisecond, func(e logger.LogEntry) {
+			mu.Lock()
+			got = append(got, e.Message)
+			mu.Unlock()
+		})
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"level":"info","message":"sec`)
+	time.Sleep(30 * time.Millisecond)
+	f.WriteString(`ond"}` + "\n")
+	f.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(got)
+		mu.Unlock()
+		if n == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Follow() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 2 || got[0] != "first" || got[1] != "second" {
+		t.Errorf("got = %v, want [first second]", got)
+	}
+}
+
+// TestSeekSince проверяет переход к записям за период по индексу времени
+// и чтение с начала при индексе, не совпадающем с файлом
+func TestSeekSince(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "svc.log")
+	if err := os.WriteFile(path, []byte(sample), 0644); err != nil {
+		t.Fatal(err)
+	}
+	second := int64(strings.Index(sample, `{"timestamp":"2025-01-01T11`))
+	third := int64(strings.Index(sample, `{"timestamp":"2025-01-01T12`))
+	writeIndex := func(records ...[]byte) {
+		t.Helper()
+		var data []byte
+		for _, r := range records {
+			data = append(data, r...)
+		}
+		if err := os.WriteFile(logger.IndexPath(path), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	at := func(hour int) time.Time { return time.Date(2025, 1, 1, hour, 0, 0, 0, time.UTC) }
+	seek := func(since time.Time) (int64, []string) {
+		t.Helper()
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		offset, err := SeekSince(f, since)
+		if err != nil {
+			t.Fatalf("SeekSince() error = %v", err)
+		}
+		var got []string
+		Read(f, Filter{Since: since}, func(e logger.LogEntry) { got = append(got, e.Message) })
+		return offset, got
+	}
+
+	// Без индекса файл читается целиком
+	if offset, got := seek(at(11).Add(30 * time.Minute)); offset != 0 || strings.Join(got, ",") != "not json at all,slow" {
+		t.Errorf("without index: offset = %d, got = %v", offset, got)
+	}
+
+	writeIndex(logger.EncodeIndexRecord(at(10), 0), logger.EncodeIndexRecord(at(11), second), logger.EncodeIndexRecord(at(12), third))
+	if offset, got := seek(at(11).Add(30 * time.Minute)); offset != second || strings.Join(got, ",") != "not json at all,slow" {
+		t.Errorf("since 11:30: offset = %d, got = %v, want %d", offset, got, second)
+	}
+	if offset, got := seek(at(12)); offset != second || strings.Join(got, ",") != "not json at all,slow" {
+		t.Errorf("since 12:00: offset = %d, got = %v, want %d", offset, got, second)
+	}
+	if offset, _ := seek(at(9)); offset != 0 {
+		t.Errorf("since 09:00: offset = %d, want 0", offset)
+	}
+
+	// Индекс от другого файла: время строки не совпадает с записью индекса
+	writeIndex(logger.EncodeIndexRecord(at(10), 0), logger.EncodeIndexRecord(at(11).Add(time.Second), second))
+	if offset, got := seek(at(12)); offset != 0 || len(got) != 2 {
+		t.Errorf("stale index: offset = %d, got = %v, want full scan", offset, got)
+	}
+}
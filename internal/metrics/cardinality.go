@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+)
+
+// Режимы обработки значений метки сверх лимита
+const (
+	// LabelOverflowAggregate значения сверх лимита объединяются в OtherLabelValue
+	LabelOverflowAggregate = "aggregate"
+	// LabelOverflowReject наблюдения со значениями сверх лимита не записываются
+	LabelOverflowReject = "reject"
+)
+
+// OtherLabelValue значение метки, в которое объединяются значения сверх лимита
+const OtherLabelValue = "other"
+
+// DefaultMaxLabelValues лимит значений метки одной метрики по умолчанию
+const DefaultMaxLabelValues = 1000
+
+// LabelCardinality количество значений метки имени (timer, job_type,
+// route и т.п.) одной метрики
+type LabelCardinality struct {
+	Metric string `json:"metric"`
+	Values int    `json:"values"`
+	Limit  int    `json:"limit"`
+	// Overflow количество наблюдений со значениями сверх лимита
+	Overflow uint64 `json:"overflow"`
+}
+
+// labelGuard ограничивает количество значений метки имени для каждой
+// метрики: имена таймеров и заданий задаются пользовательским кодом
+// и без ограничения могут раздуть registry
+type labelGuard struct {
+	mu       sync.Mutex
+	limit    int
+	mode     string
+	values   map[string]map[string]struct{}
+	overflow map[string]uint64
+}
+
+// newLabelGuard создает ограничитель с лимитом по умолчанию
+func newLabelGuard() *labelGuard {
+	return &labelGuard{
+		limit:    DefaultMaxLabelValues,
+		mode:     LabelOverflowAggregate,
+		values:   make(map[string]map[string]struct{}),
+		overflow: make(map[string]uint64),
+	}
+}
+
+// check возвращает значение метки для записи. Для значения сверх лимита
+// overflow true, а ok false в режиме reject; first отмечает первое
+// превышение лимита метрикой
+func (g *labelGuard) check(metric, value string) (result string, ok, overflow, first bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	seen := g.values[metric]
+	if seen == nil {
+		seen = make(map[string]struct{})
+		g.values[metric] = seen
+	}
+	if _, exists := seen[value]; exists || len(seen) < g.limit {
+		seen[value] = struct{}{}
+		return value, true, false, false
+	}
+
+	g.overflow[metric]++
+	first = g.overflow[metric] == 1
+	if g.mode == LabelOverflowReject {
+		return "", false, true, first
+	}
+	return OtherLabelValue, true, true, first
+}
+
+// stats возвращает статистику метрик, отсортированную по имени
+func (g *labelGuard) stats() []LabelCardinality {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stats := make([]LabelCardinality, 0, len(g.values))
+	for metric, seen := range g.values {
+		stats = append(stats, LabelCardinality{
+			Metric:   metric,
+			Values:   len(seen),
+			Limit:    g.limit,
+			Overflow: g.overflow[metric],
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Metric < stats[j].Metric })
+	return stats
+}
+
+// SetLabelLimit задает лимит значений метки имени для каждой метрики
+// (limit <= 0 - лимит по умолчанию) и режим обработки значений сверх
+// лимита: LabelOverflowAggregate или LabelOverflowReject. Вызывается до Start
+func (s *Server) SetLabelLimit(limit int, mode string) {
+	if limit <= 0 {
+		limit = DefaultMaxLabelValues
+	}
+	if mode != LabelOverflowReject {
+		mode = LabelOverflowAggregate
+	}
+	s.labels.mu.Lock()
+	defer s.labels.mu.Unlock()
+	s.labels.limit = limit
+	s.labels.mode = mode
+}
+
+// LabelCardinality возвращает количество значений метки имени по метрикам
+func (s *Server) LabelCardinality() []LabelCardinality {
+	return s.labels.stats()
+}
+
+// label возвращает значение метки имени для метрики metric с учетом лимита.
+// false означает, что наблюдение не записывается
+func (s *Server) label(metric, value string) (string, bool) {
+	result, ok, overflow, first := s.labels.check(metric, value)
+	if !overflow {
+		return result, ok
+	}
+	if s.labelOverflow != nil {
+		s.labelOverflow.WithLabelValues(metric).Inc()
+	}
+	if first {
+		limit, mode := s.labels.settings()
+		s.log.Warn("Metric label cardinality limit reached", map[string]interface{}{
+			"metric": metric,
+			"value":  value,
+			"limit":  limit,
+			"mode":   mode,
+		})
+	}
+	return result, ok
+}
+
+// settings возвращает текущие лимит и режим
+func (g *labelGuard) settings() (int, string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.limit, g.mode
+}
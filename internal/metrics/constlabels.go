@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// constLabels метки экземпляра, которые добавляются ко всем метрикам
+// при выдаче /metrics. Метрики регистрируются до того, как известна
+// идентичность экземпляра, поэтому метки добавляются при сборе
+type constLabels struct {
+	mu     sync.RWMutex
+	labels []*dto.LabelPair
+}
+
+// SetConstLabels задает метки, добавляемые ко всем метрикам на /metrics
+// (instance_id, hostname). Метка, которая уже есть у метрики, не заменяется.
+// Registry при встраивании отдает метрики без этих меток. Вызывается до Start
+func (s *Server) SetConstLabels(labels map[string]string) {
+	pairs := make([]*dto.LabelPair, 0, len(labels))
+	for name, value := range labels {
+		if value == "" {
+			continue
+		}
+		pairs = append(pairs, &dto.LabelPair{Name: &name, Value: &value})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].GetName() < pairs[j].GetName() })
+
+	s.constLabels.mu.Lock()
+	defer s.constLabels.mu.Unlock()
+	s.constLabels.labels = pairs
+}
+
+// Gatherer возвращает источник метрик с метками экземпляра, как на /metrics
+// (для remote write). nil, если метрики отключены
+func (s *Server) Gatherer() prometheus.Gatherer {
+	if s.registry == nil {
+		return nil
+	}
+	return s.gatherer()
+}
+
+// gatherer возвращает источник метрик для /metrics с метками экземпляра
+func (s *Server) gatherer() prometheus.Gatherer {
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		if families, ok := s.frozen(); ok {
+			return families, nil
+		}
+		families, err := s.registry.Gather()
+		s.constLabels.mu.RLock()
+		labels := s.constLabels.labels
+		s.constLabels.mu.RUnlock()
+		if len(labels) == 0 {
+			return families, err
+		}
+		for _, family := range families {
+			for _, m := range family.Metric {
+				m.Label = withLabels(m.Label, labels)
+			}
+		}
+		return families, err
+	})
+}
+
+// withLabels добавляет к меткам метрики отсутствующие метки extra
+// и сортирует результат по имени
+func withLabels(own, extra []*dto.LabelPair) []*dto.LabelPair {
+	names := make(map[string]bool, len(own))
+	for _, l := range own {
+		names[l.GetName()] = true
+	}
+	for _, l := range extra {
+		if !names[l.GetName()] {
+			own = append(own, l)
+		}
+	}
+	sort.Slice(own, func(i, j int) bool { return own[i].GetName() < own[j].GetName() })
+	return own
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// finalSnapshot итоговый снимок метрик, который /metrics отдает после
+// остановки компонентов
+type finalSnapshot struct {
+	families []*dto.MetricFamily
+	once     sync.Once
+	scraped  chan struct{}
+}
+
+// FinalScrape снимает итоговый снимок метрик и ждет, пока его опросит
+// Prometheus, но не дольше window. С этого момента /metrics отдает снимок,
+// а не текущие значения, поэтому счетчики короткоживущего экземпляра
+// и длительность его остановки не теряются. Возвращает true, если снимок
+// был опрошен. Вызывается после остановки компонентов, до Stop
+func (s *Server) FinalScrape(ctx context.Context, window time.Duration) bool {
+	if !s.enabled || s.listener == nil || window <= 0 {
+		return false
+	}
+	families, err := s.gatherer().Gather()
+	if err != nil {
+		s.log.Warn("Failed to gather final metrics snapshot", map[string]interface{}{"error": err.Error()})
+	}
+	snapshot := &finalSnapshot{families: families, scraped: make(chan struct{})}
+	s.final.Store(snapshot)
+	s.log.Info("Serving final metrics snapshot", map[string]interface{}{"window": window.String()})
+
+	timer := time.NewTimer(window)
+	defer timer.Stop()
+	select {
+	case <-snapshot.scraped:
+		s.log.Info("Final metrics snapshot scraped")
+		return true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return false
+}
+
+// frozen возвращает итоговый снимок, если он снят, и отмечает его опрос
+func (s *Server) frozen() ([]*dto.MetricFamily, bool) {
+	snapshot := s.final.Load()
+	if snapshot == nil {
+		return nil, false
+	}
+	snapshot.once.Do(func() { close(snapshot.scraped) })
+	return snapshot.families, true
+}
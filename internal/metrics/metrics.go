@@ -3,14 +3,19 @@ package metrics
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"net"
 	"net/http"
+	"strconv"
 	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"service-boilerplate/internal/apperr"
+	"service-boilerplate/internal/httpmw"
 	"service-boilerplate/internal/logger"
 )
 
@@ -23,26 +28,89 @@ type Server struct {
 	listen    string
 	startTime time.Time
 	registry  *prometheus.Registry
+	health    http.Handler
+	ready     http.Handler
+	labels    *labelGuard
+	// constLabels метки экземпляра для /metrics
+	constLabels constLabels
+	// final итоговый снимок, который /metrics отдает при остановке
+	final atomic.Pointer[finalSnapshot]
 
 	// Метрики
 	uptimeSeconds *prometheus.CounterVec
 	timerRuns     *prometheus.CounterVec
 	timerPanics   *prometheus.CounterVec
+	timerDuration *prometheus.HistogramVec
+	ticksMissed   *prometheus.CounterVec
+	queueWait     *prometheus.HistogramVec
+	runsQueued    prometheus.Gauge
+	poolQueued    prometheus.Gauge
+	poolBusy      prometheus.Gauge
+	budgetOver    *prometheus.CounterVec
+	throttle      *prometheus.GaugeVec
+	nsRuns        *prometheus.CounterVec
+	nsEnabled     *prometheus.GaugeVec
 	activeTimers  prometheus.Gauge
+	jobsEnqueued  *prometheus.CounterVec
+	jobsProcessed *prometheus.CounterVec
+	jobsQueued    prometheus.Gauge
+	leader        prometheus.Gauge
+	watchEvents   *prometheus.CounterVec
+	httpRequests  *prometheus.CounterVec
+	httpDuration  *prometheus.HistogramVec
+	dbConns       *prometheus.GaugeVec
+	dbWaitCount   prometheus.Gauge
+	dbWaitSeconds prometheus.Gauge
+	redisDuration *prometheus.HistogramVec
+	redisErrors   *prometheus.CounterVec
+	clientReqs    *prometheus.CounterVec
+	clientDur     *prometheus.HistogramVec
+	breakerState  *prometheus.GaugeVec
+	limiterWait   *prometheus.HistogramVec
+	limiterReject *prometheus.CounterVec
+	procRestarts  *prometheus.CounterVec
+	procUp        *prometheus.GaugeVec
+	hookRuns      *prometheus.CounterVec
+	clockJumps    *prometheus.CounterVec
+	clockDrift    prometheus.Gauge
+	alertsSent    *prometheus.CounterVec
+	alertsDropped prometheus.Counter
+	profiles      *prometheus.CounterVec
+	labelOverflow *prometheus.CounterVec
+	reloadOK      prometheus.Gauge
+	reloadTime    prometheus.Gauge
+	rollbacks     prometheus.Counter
+	remoteSamples *prometheus.CounterVec
+	remotePending prometheus.Gauge
+	heartbeats    *prometheus.CounterVec
+	healthChanges *prometheus.CounterVec
+	shutdownTime  *prometheus.GaugeVec
+	startupTime   *prometheus.GaugeVec
 }
 
-// New создает новый metrics сервер
+// New создает новый metrics сервер с собственным registry
 func New(log *logger.Logger, enabled bool, listen string) *Server {
+	return NewWithRegistry(log, enabled, listen, nil)
+}
+
+// NewWithRegistry создает metrics сервер, регистрирующий метрики в registry
+// (nil - собственный registry). Несколько серверов с общим registry
+// используют одни и те же метрики вместо panic при повторной регистрации
+func NewWithRegistry(log *logger.Logger, enabled bool, listen string, registry *prometheus.Registry) *Server {
 	s := &Server{
 		log:       log,
 		enabled:   enabled,
 		listen:    listen,
 		startTime: time.Now(),
+		labels:    newLabelGuard(),
 	}
 
 	if enabled {
-		// Создаем отдельный registry для избежания конфликтов в тестах
-		s.registry = prometheus.NewRegistry()
+		// Отдельный registry по умолчанию исключает конфликты между экземплярами
+		s.registry = registry
+		if s.registry == nil {
+			s.registry = prometheus.NewRegistry()
+		}
 
 		// Инициализируем метрики
 		s.uptimeSeconds = prometheus.NewCounterVec(
@@ -69,6 +137,85 @@ func New(log *logger.Logger, enabled bool, listen string) *Server {
 			[]string{"timer"},
 		)
 
+		s.timerDuration = prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "timer_duration_seconds",
+				Help:    "Timer execution duration in seconds",
+				Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300, 900, 3600},
+			},
+			[]string{"timer"},
+		)
+
+		s.ticksMissed = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "timer_ticks_missed_total",
+				Help: "Total number of timer ticks dropped because the handler was still running",
+			},
+			[]string{"timer"},
+		)
+
+		s.queueWait = prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "timer_queue_wait_seconds",
+				Help:    "Time a timer run waited for a slot of the scheduler concurrency limit",
+				Buckets: []float64{0, .01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+			},
+			[]string{"timer"},
+		)
+
+		s.runsQueued = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "timer_runs_queued",
+				Help: "Number of timer runs waiting for a slot of the scheduler concurrency limit",
+			},
+		)
+
+		s.poolQueued = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "timer_handler_queue_depth",
+				Help: "Number of timer runs waiting for a free worker of the handler pool",
+			},
+		)
+
+		s.poolBusy = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "timer_handler_workers_busy",
+				Help: "Number of handler pool workers executing a timer handler",
+			},
+		)
+
+		s.budgetOver = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "timer_budget_exceeded_total",
+				Help: "Total number of timer runs that exceeded the configured wall or CPU time budget",
+			},
+			[]string{"timer"},
+		)
+
+		s.throttle = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "timer_throttle_factor",
+				Help: "Interval multiplier of a timer throttled for exceeding its resource budget (1 - not throttled)",
+			},
+			[]string{"timer"},
+		)
+
+		s.nsRuns = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "namespace_runs_total",
+				Help: "Total number of timer runs in a timer namespace by result",
+			},
+			[]string{"namespace", "result"},
+		)
+
+		s.nsEnabled = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "namespace_enabled",
+				Help: "Whether a timer namespace is enabled (1) or disabled (0)",
+			},
+			[]string{"namespace"},
+		)
+
 		s.activeTimers = prometheus.NewGauge(
 			prometheus.GaugeOpts{
 				Name: "active_timers",
@@ -76,25 +223,375 @@ func New(log *logger.Logger, enabled bool, listen string) *Server {
 			},
 		)
 
-		// Регистрируем метрики в нашем registry
-		s.registry.MustRegister(s.uptimeSeconds)
-		s.registry.MustRegister(s.timerRuns)
-		s.registry.MustRegister(s.timerPanics)
-		s.registry.MustRegister(s.activeTimers)
+		s.jobsEnqueued = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "jobs_enqueued_total",
+				Help: "Total number of jobs added to the queue",
+			},
+			[]string{"type"},
+		)
+
+		s.jobsProcessed = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "jobs_processed_total",
+				Help: "Total number of job attempts by result (success, retry, dead_letter)",
+			},
+			[]string{"type", "result"},
+		)
+
+		s.jobsQueued = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "jobs_queue_depth",
+				Help: "Number of jobs waiting in the queue",
+			},
+		)
+
+		s.leader = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "election_is_leader",
+				Help: "1 if this instance holds leadership, 0 otherwise",
+			},
+		)
+
+		s.watchEvents = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "watcher_events_total",
+				Help: "Total number of debounced file events dispatched to handlers",
+			},
+			[]string{"watch", "op"},
+		)
+
+		s.httpRequests = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "http_requests_total",
+				Help: "Total number of HTTP requests served by the service HTTP servers",
+			},
+			[]string{"server", "route", "method", "code"},
+		)
+
+		s.httpDuration = prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_request_duration_seconds",
+				Help:    "HTTP request duration in seconds",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"server", "route", "method"},
+		)
+
+		s.dbConns = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "db_pool_connections",
+				Help: "Number of database pool connections by state (open, in_use, idle)",
+			},
+			[]string{"state"},
+		)
+
+		s.dbWaitCount = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "db_pool_wait_count",
+				Help: "Total number of connections waited for since the pool was opened",
+			},
+		)
+
+		s.dbWaitSeconds = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "db_pool_wait_seconds",
+				Help: "Total time blocked waiting for a new connection since the pool was opened",
+			},
+		)
+
+		s.redisDuration = prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "redis_command_duration_seconds",
+				Help:    "Redis command duration in seconds",
+				Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+			},
+			[]string{"command"},
+		)
+
+		s.redisErrors = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "redis_command_errors_total",
+				Help: "Total number of failed Redis commands",
+			},
+			[]string{"command"},
+		)
+
+		s.clientReqs = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "http_client_requests_total",
+				Help: "Total number of outbound HTTP request attempts",
+			},
+			[]string{"client", "method", "code"},
+		)
+
+		s.clientDur = prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_client_request_duration_seconds",
+				Help:    "Outbound HTTP request attempt duration in seconds",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"client", "method"},
+		)
+
+		s.breakerState = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "circuit_breaker_state",
+				Help: "Circuit breaker state (0 = closed, 1 = half-open, 2 = open)",
+			},
+			[]string{"name"},
+		)
+
+		s.limiterWait = prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "ratelimit_wait_seconds",
+				Help:    "Time spent waiting for a rate limiter token",
+				Buckets: []float64{0, .01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+			},
+			[]string{"limiter"},
+		)
+
+		s.limiterReject = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ratelimit_rejected_total",
+				Help: "Total number of requests rejected by a rate limiter without waiting",
+			},
+			[]string{"limiter"},
+		)
+
+		s.procRestarts = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "process_restarts_total",
+				Help: "Total number of supervised child process restarts",
+			},
+			[]string{"process"},
+		)
+
+		s.procUp = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "process_up",
+				Help: "1 if the supervised child process is running",
+			},
+			[]string{"process"},
+		)
+
+		s.hookRuns = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hook_runs_total",
+				Help: "Total number of external hook command runs by hook point and result",
+			},
+			[]string{"hook", "result"},
+		)
+
+		s.clockJumps = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "clock_jumps_total",
+				Help: "Total number of detected system clock jumps by direction",
+			},
+			[]string{"direction"},
+		)
+
+		s.clockDrift = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "clock_drift_seconds",
+				Help: "Wall clock time elapsed since start minus monotonic time elapsed",
+			},
+		)
+
+		s.alertsSent = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "alerts_sent_total",
+				Help: "Total number of alert notifications by notifier and result",
+			},
+			[]string{"notifier", "result"},
+		)
+
+		s.alertsDropped = prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "alerts_suppressed_total",
+				Help: "Total number of alerts suppressed by cooldown",
+			},
+		)
+
+		s.profiles = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "profiles_collected_total",
+				Help: "Total number of collected pprof profiles by profile and result",
+			},
+			[]string{"profile", "result"},
+		)
+
+		s.labelOverflow = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "metrics_label_overflow_total",
+				Help: "Total number of observations with label values over the per-metric cardinality limit",
+			},
+			[]string{"metric"},
+		)
+
+		s.reloadOK = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "config_last_reload_successful",
+				Help: "1 if the last config reload succeeded, 0 if the new config failed validation",
+			},
+		)
+
+		s.reloadTime = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "config_last_reload_timestamp_seconds",
+				Help: "Unix time of the last config reload attempt",
+			},
+		)
+
+		s.rollbacks = prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "config_rollbacks_total",
+				Help: "Total number of reloaded configs rolled back after failing probation",
+			},
+		)
+
+		s.remoteSamples = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "remote_write_samples_total",
+				Help: "Total number of samples pushed to the remote-write endpoint by result (sent, dropped)",
+			},
+			[]string{"result"},
+		)
+
+		s.remotePending = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "remote_write_pending_samples",
+				Help: "Number of samples buffered in memory waiting for the remote-write endpoint",
+			},
+		)
+
+		s.heartbeats = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "heartbeats_total",
+				Help: "Total number of heartbeats sent to the central endpoint by result (sent, failed)",
+			},
+			[]string{"result"},
+		)
+
+		s.healthChanges = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "health_transitions_total",
+				Help: "Total number of health check state transitions (healthy, degraded, unhealthy)",
+			},
+			[]string{"check", "from", "to"},
+		)
+
+		s.shutdownTime = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "shutdown_duration_seconds",
+				Help: "Duration of graceful shutdown stages (drain, pre_stop, servers, scheduler, tasks, total)",
+			},
+			[]string{"stage"},
+		)
+
+		s.startupTime = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "startup_phase_duration_seconds",
+				Help: "Duration of startup phases (config, logger, init, task:<name>, metrics, scheduler, admin, control, total)",
+			},
+			[]string{"phase"},
+		)
+
+		// Регистрируем метрики; уже зарегистрированные в registry переиспользуются
+		s.uptimeSeconds = register(s, s.uptimeSeconds)
+		s.timerRuns = register(s, s.timerRuns)
+		s.timerPanics = register(s, s.timerPanics)
+		s.timerDuration = register(s, s.timerDuration)
+		s.ticksMissed = register(s, s.ticksMissed)
+		s.queueWait = register(s, s.queueWait)
+		s.runsQueued = register(s, s.runsQueued)
+		s.poolQueued = register(s, s.poolQueued)
+		s.poolBusy = register(s, s.poolBusy)
+		s.budgetOver = register(s, s.budgetOver)
+		s.throttle = register(s, s.throttle)
+		s.nsRuns = register(s, s.nsRuns)
+		s.nsEnabled = register(s, s.nsEnabled)
+		s.activeTimers = register(s, s.activeTimers)
+		s.jobsEnqueued = register(s, s.jobsEnqueued)
+		s.jobsProcessed = register(s, s.jobsProcessed)
+		s.jobsQueued = register(s, s.jobsQueued)
+		s.leader = register(s, s.leader)
+		s.watchEvents = register(s, s.watchEvents)
+		s.httpRequests = register(s, s.httpRequests)
+		s.httpDuration = register(s, s.httpDuration)
+		s.dbConns = register(s, s.dbConns)
+		s.dbWaitCount = register(s, s.dbWaitCount)
+		s.dbWaitSeconds = register(s, s.dbWaitSeconds)
+		s.redisDuration = register(s, s.redisDuration)
+		s.redisErrors = register(s, s.redisErrors)
+		s.clientReqs = register(s, s.clientReqs)
+		s.clientDur = register(s, s.clientDur)
+		s.breakerState = register(s, s.breakerState)
+		s.limiterWait = register(s, s.limiterWait)
+		s.limiterReject = register(s, s.limiterReject)
+		s.procRestarts = register(s, s.procRestarts)
+		s.procUp = register(s, s.procUp)
+		s.hookRuns = register(s, s.hookRuns)
+		s.clockJumps = register(s, s.clockJumps)
+		s.clockDrift = register(s, s.clockDrift)
+		s.alertsSent = register(s, s.alertsSent)
+		s.alertsDropped = register(s, s.alertsDropped)
+		s.profiles = register(s, s.profiles)
+		s.labelOverflow = register(s, s.labelOverflow)
+		s.reloadOK = register(s, s.reloadOK)
+		s.reloadTime = register(s, s.reloadTime)
+		s.rollbacks = register(s, s.rollbacks)
+		s.remoteSamples = register(s, s.remoteSamples)
+		s.remotePending = register(s, s.remotePending)
+		s.heartbeats = register(s, s.heartbeats)
+		s.healthChanges = register(s, s.healthChanges)
+		s.shutdownTime = register(s, s.shutdownTime)
+		s.startupTime = register(s, s.startupTime)
 
 		// Создаем HTTP сервер с нашим handler
 		mux := http.NewServeMux()
-		mux.Handle("/metrics", promhttp.HandlerFor(s.registry, promhttp.HandlerOpts{}))
+		// OpenMetrics нужен для передачи exemplar с идентификатором трассировки
+		mux.Handle("/metrics", promhttp.HandlerFor(s.gatherer(), promhttp.HandlerOpts{EnableOpenMetrics: true}))
 		mux.HandleFunc("/health", s.healthHandler)
+		mux.HandleFunc("/ready", s.readyHandler)
 
 		s.server = &http.Server{
-			Handler: mux,
+			Handler: httpmw.Wrap(mux, httpmw.Options{
+				Server:  "metrics",
+				Log:     log,
+				Metrics: s,
+				// Prometheus опрашивает сервер постоянно
+				AccessLevel: logger.DebugLevel,
+			}),
 		}
 	}
 
 	return s
 }
 
+// register регистрирует метрику c и возвращает метрику, которую нужно
+// использовать: при повторной регистрации - уже существующую в registry.
+// Конфликт описаний не приводит к panic: метрика работает, но не экспортируется
+func register[T prometheus.Collector](s *Server, c T) T {
+	err := s.registry.Register(c)
+	if err == nil {
+		return c
+	}
+	var already prometheus.AlreadyRegisteredError
+	if errors.As(err, &already) {
+		if existing, ok := already.ExistingCollector.(T); ok {
+			return existing
+		}
+	}
+	s.log.Warn("Failed to register metric", map[string]interface{}{"error": err.Error()})
+	return c
+}
+
+// Registry возвращает registry метрик (nil, если метрики отключены)
+func (s *Server) Registry() *prometheus.Registry {
+	return s.registry
+}
+
 // GetAddress возвращает адрес сервера (полезно для тестов)
 func (s *Server) GetAddress() string {
 	if s.listener != nil {
@@ -103,13 +600,40 @@ func (s *Server) GetAddress() string {
 	return s.listen
 }
 
+// SetHealthHandler задает обработчик /health вместо статического ответа.
+// Вызывается до Start
+func (s *Server) SetHealthHandler(h http.Handler) {
+	s.health = h
+}
+
 // healthHandler обрабатывает запросы /health
 func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
+	if s.health != nil {
+		s.health.ServeHTTP(w, r)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(`{"status":"healthy"}`))
 }
 
+// SetReadyHandler задает обработчик /ready (готовность принимать трафик)
+// вместо статического ответа. Вызывается до Start
+func (s *Server) SetReadyHandler(h http.Handler) {
+	s.ready = h
+}
+
+// readyHandler обрабатывает запросы /ready
+func (s *Server) readyHandler(w http.ResponseWriter, r *http.Request) {
+	if s.ready != nil {
+		s.ready.ServeHTTP(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"status":"ready"}`))
+}
+
 // Start запускает metrics сервер
 func (s *Server) Start(ctx context.Context) error {
 	if !s.enabled {
@@ -120,7 +644,7 @@ func (s *Server) Start(ctx context.Context) error {
 	// Создаем listener чтобы получить реальный адрес (особенно важно для :0)
 	listener, err := net.Listen("tcp", s.listen)
 	if err != nil {
-		return err
+		return apperr.Listen("metrics server", s.listen, err)
 	}
 	s.listener = listener
 
@@ -163,14 +687,111 @@ func (s *Server) Stop(ctx context.Context) error {
 // RecordTimerRun записывает выполнение таймера
 func (s *Server) RecordTimerRun(timerName string) {
 	if s.enabled && s.timerRuns != nil {
-		s.timerRuns.WithLabelValues(timerName).Inc()
+		if timer, ok := s.label("timer_runs_total", timerName); ok {
+			s.timerRuns.WithLabelValues(timer).Inc()
+		}
 	}
 }
 
 // RecordTimerPanic записывает panic таймера
 func (s *Server) RecordTimerPanic(timerName string) {
 	if s.enabled && s.timerPanics != nil {
-		s.timerPanics.WithLabelValues(timerName).Inc()
+		if timer, ok := s.label("timer_panics_total", timerName); ok {
+			s.timerPanics.WithLabelValues(timer).Inc()
+		}
+	}
+}
+
+// RecordTimerDuration записывает длительность выполнения таймера. Непустой
+// traceID прикрепляется к наблюдению как exemplar, чтобы из графика
+// длительности можно было перейти к трассировке запуска
+func (s *Server) RecordTimerDuration(timerName string, duration time.Duration, traceID string) {
+	if s.enabled && s.timerDuration != nil {
+		timer, ok := s.label("timer_duration_seconds", timerName)
+		if !ok {
+			return
+		}
+		observer := s.timerDuration.WithLabelValues(timer)
+		if eo, ok := observer.(prometheus.ExemplarObserver); ok && traceID != "" {
+			eo.ObserveWithExemplar(duration.Seconds(), prometheus.Labels{"trace_id": traceID})
+			return
+		}
+		observer.Observe(duration.Seconds())
+	}
+}
+
+// RecordTimerTicksMissed записывает тики таймера, пропущенные из-за долгого выполнения
+func (s *Server) RecordTimerTicksMissed(timerName string, count int) {
+	if s.enabled && s.ticksMissed != nil {
+		if timer, ok := s.label("timer_ticks_missed_total", timerName); ok {
+			s.ticksMissed.WithLabelValues(timer).Add(float64(count))
+		}
+	}
+}
+
+// RecordTimerQueueWait записывает время ожидания слота лимита одновременных запусков
+func (s *Server) RecordTimerQueueWait(timerName string, wait time.Duration) {
+	if s.enabled && s.queueWait != nil {
+		if timer, ok := s.label("timer_queue_wait_seconds", timerName); ok {
+			s.queueWait.WithLabelValues(timer).Observe(wait.Seconds())
+		}
+	}
+}
+
+// SetTimerRunsQueued устанавливает количество запусков, ожидающих слот
+func (s *Server) SetTimerRunsQueued(count int) {
+	if s.enabled && s.runsQueued != nil {
+		s.runsQueued.Set(float64(count))
+	}
+}
+
+// SetTimerHandlerPool устанавливает число запусков, ожидающих исполнителя
+// пула обработчиков, и число занятых исполнителей
+func (s *Server) SetTimerHandlerPool(queued, busy int) {
+	if s.enabled && s.poolQueued != nil {
+		s.poolQueued.Set(float64(queued))
+		s.poolBusy.Set(float64(busy))
+	}
+}
+
+// RecordTimerBudgetExceeded записывает запуск таймера сверх бюджета ресурсов
+func (s *Server) RecordTimerBudgetExceeded(timerName string) {
+	if s.enabled && s.budgetOver != nil {
+		if timer, ok := s.label("timer_budget_exceeded_total", timerName); ok {
+			s.budgetOver.WithLabelValues(timer).Inc()
+		}
+	}
+}
+
+// SetTimerThrottle устанавливает множитель интервала замедленного таймера
+func (s *Server) SetTimerThrottle(timerName string, factor int) {
+	if s.enabled && s.throttle != nil {
+		if timer, ok := s.label("timer_throttle_factor", timerName); ok {
+			s.throttle.WithLabelValues(timer).Set(float64(factor))
+		}
+	}
+}
+
+// RecordNamespaceRun записывает запуск таймера пространства имен
+// с результатом ok или panic
+func (s *Server) RecordNamespaceRun(namespace, result string) {
+	if s.enabled && s.nsRuns != nil {
+		if ns, ok := s.label("namespace_runs_total", namespace); ok {
+			s.nsRuns.WithLabelValues(ns, result).Inc()
+		}
+	}
+}
+
+// SetNamespaceEnabled устанавливает состояние пространства имен таймеров
+func (s *Server) SetNamespaceEnabled(namespace string, enabled bool) {
+	if s.enabled && s.nsEnabled != nil {
+		if ns, ok := s.label("namespace_enabled", namespace); ok {
+			value := 0.0
+			if enabled {
+				value = 1
+			}
+			s.nsEnabled.WithLabelValues(ns).Set(value)
+		}
 	}
 }
 
@@ -194,3 +815,265 @@ func (s *Server) DecActiveTimers() {
 		s.activeTimers.Dec()
 	}
 }
+
+// RecordJobEnqueued записывает постановку задания в очередь
+func (s *Server) RecordJobEnqueued(jobType string) {
+	if s.enabled && s.jobsEnqueued != nil {
+		if jobType, ok := s.label("jobs_enqueued_total", jobType); ok {
+			s.jobsEnqueued.WithLabelValues(jobType).Inc()
+		}
+	}
+}
+
+// RecordJobProcessed записывает результат попытки выполнения задания
+func (s *Server) RecordJobProcessed(jobType, result string) {
+	if s.enabled && s.jobsProcessed != nil {
+		if jobType, ok := s.label("jobs_processed_total", jobType); ok {
+			s.jobsProcessed.WithLabelValues(jobType, result).Inc()
+		}
+	}
+}
+
+// SetJobQueueDepth устанавливает количество заданий в очереди
+func (s *Server) SetJobQueueDepth(depth int) {
+	if s.enabled && s.jobsQueued != nil {
+		s.jobsQueued.Set(float64(depth))
+	}
+}
+
+// SetLeader устанавливает признак лидерства экземпляра
+func (s *Server) SetLeader(leader bool) {
+	if s.enabled && s.leader != nil {
+		if leader {
+			s.leader.Set(1)
+		} else {
+			s.leader.Set(0)
+		}
+	}
+}
+
+// RecordWatchEvent записывает событие файловой системы, переданное обработчику
+func (s *Server) RecordWatchEvent(watch, op string) {
+	if s.enabled && s.watchEvents != nil {
+		if watch, ok := s.label("watcher_events_total", watch); ok {
+			s.watchEvents.WithLabelValues(watch, op).Inc()
+		}
+	}
+}
+
+// RecordHTTPRequest записывает обработанный HTTP запрос сервера server
+// (http, admin, metrics). route - шаблон маршрута, а не путь, чтобы
+// не раздувать количество серий
+func (s *Server) RecordHTTPRequest(server, route, method string, code int, duration time.Duration) {
+	if s.enabled && s.httpRequests != nil {
+		route, ok := s.label("http_requests_total", route)
+		if !ok {
+			return
+		}
+		s.httpRequests.WithLabelValues(server, route, method, strconv.Itoa(code)).Inc()
+		s.httpDuration.WithLabelValues(server, route, method).Observe(duration.Seconds())
+	}
+}
+
+// SetDBPoolStats устанавливает метрики пула соединений базы данных
+func (s *Server) SetDBPoolStats(stats sql.DBStats) {
+	if s.enabled && s.dbConns != nil {
+		s.dbConns.WithLabelValues("open").Set(float64(stats.OpenConnections))
+		s.dbConns.WithLabelValues("in_use").Set(float64(stats.InUse))
+		s.dbConns.WithLabelValues("idle").Set(float64(stats.Idle))
+		s.dbWaitCount.Set(float64(stats.WaitCount))
+		s.dbWaitSeconds.Set(stats.WaitDuration.Seconds())
+	}
+}
+
+// RecordRedisCommand записывает выполненную команду Redis
+func (s *Server) RecordRedisCommand(command string, duration time.Duration, failed bool) {
+	if s.enabled && s.redisDuration != nil {
+		command, ok := s.label("redis_command_duration_seconds", command)
+		if !ok {
+			return
+		}
+		s.redisDuration.WithLabelValues(command).Observe(duration.Seconds())
+		if failed {
+			s.redisErrors.WithLabelValues(command).Inc()
+		}
+	}
+}
+
+// RecordHTTPClientRequest записывает попытку исходящего HTTP запроса.
+// code - код ответа или "error", если ответ не получен
+func (s *Server) RecordHTTPClientRequest(client, method, code string, duration time.Duration) {
+	if s.enabled && s.clientReqs != nil {
+		client, ok := s.label("http_client_requests_total", client)
+		if !ok {
+			return
+		}
+		s.clientReqs.WithLabelValues(client, method, code).Inc()
+		s.clientDur.WithLabelValues(client, method).Observe(duration.Seconds())
+	}
+}
+
+// SetBreakerState устанавливает состояние circuit breaker
+func (s *Server) SetBreakerState(name string, state int) {
+	if s.enabled && s.breakerState != nil {
+		if name, ok := s.label("circuit_breaker_state", name); ok {
+			s.breakerState.WithLabelValues(name).Set(float64(state))
+		}
+	}
+}
+
+// RecordRateLimitWait записывает время ожидания токена ограничителя
+func (s *Server) RecordRateLimitWait(limiter string, wait time.Duration) {
+	if s.enabled && s.limiterWait != nil {
+		if limiter, ok := s.label("ratelimit_wait_seconds", limiter); ok {
+			s.limiterWait.WithLabelValues(limiter).Observe(wait.Seconds())
+		}
+	}
+}
+
+// RecordRateLimitRejected записывает запрос, отклоненный ограничителем
+func (s *Server) RecordRateLimitRejected(limiter string) {
+	if s.enabled && s.limiterReject != nil {
+		if limiter, ok := s.label("ratelimit_rejected_total", limiter); ok {
+			s.limiterReject.WithLabelValues(limiter).Inc()
+		}
+	}
+}
+
+// RecordProcessRestart записывает перезапуск дочернего процесса
+func (s *Server) RecordProcessRestart(process string) {
+	if s.enabled && s.procRestarts != nil {
+		if process, ok := s.label("process_restarts_total", process); ok {
+			s.procRestarts.WithLabelValues(process).Inc()
+		}
+	}
+}
+
+// SetProcessUp устанавливает признак работы дочернего процесса
+func (s *Server) SetProcessUp(process string, up bool) {
+	if s.enabled && s.procUp != nil {
+		value := 0.0
+		if up {
+			value = 1
+		}
+		s.procUp.WithLabelValues(process).Set(value)
+	}
+}
+
+// RecordHookRun записывает выполнение внешней команды точки вызова hook
+// с результатом ok, failed или timeout
+func (s *Server) RecordHookRun(hook, result string) {
+	if s.enabled && s.hookRuns != nil {
+		s.hookRuns.WithLabelValues(hook, result).Inc()
+	}
+}
+
+// RecordClockJump записывает скачок системных часов в направлении
+// forward или backward
+func (s *Server) RecordClockJump(direction string) {
+	if s.enabled && s.clockJumps != nil {
+		s.clockJumps.WithLabelValues(direction).Inc()
+	}
+}
+
+// SetClockDrift устанавливает расхождение настенного и монотонного времени с запуска
+func (s *Server) SetClockDrift(drift time.Duration) {
+	if s.enabled && s.clockDrift != nil {
+		s.clockDrift.Set(drift.Seconds())
+	}
+}
+
+// RecordAlert записывает отправку оповещения через канал notifier
+func (s *Server) RecordAlert(notifier string, failed bool) {
+	if s.enabled && s.alertsSent != nil {
+		result := "sent"
+		if failed {
+			result = "failed"
+		}
+		s.alertsSent.WithLabelValues(notifier, result).Inc()
+	}
+}
+
+// RecordAlertSuppressed записывает оповещение, подавленное cooldown
+func (s *Server) RecordAlertSuppressed() {
+	if s.enabled && s.alertsDropped != nil {
+		s.alertsDropped.Inc()
+	}
+}
+
+// RecordProfile записывает снятие pprof профиля
+func (s *Server) RecordProfile(profile string, failed bool) {
+	if s.enabled && s.profiles != nil {
+		result := "success"
+		if failed {
+			result = "error"
+		}
+		s.profiles.WithLabelValues(profile, result).Inc()
+	}
+}
+
+// RecordConfigReload записывает результат перезагрузки конфигурации
+func (s *Server) RecordConfigReload(at time.Time, success bool) {
+	if s.enabled && s.reloadOK != nil {
+		if success {
+			s.reloadOK.Set(1)
+		} else {
+			s.reloadOK.Set(0)
+		}
+		s.reloadTime.Set(float64(at.Unix()))
+	}
+}
+
+// RecordConfigRollback записывает откат конфигурации, не прошедшей испытательный срок
+func (s *Server) RecordConfigRollback() {
+	if s.enabled && s.rollbacks != nil {
+		s.rollbacks.Inc()
+	}
+}
+
+// RecordRemoteWrite записывает отправленные (sent) или отброшенные (dropped)
+// сэмплы remote write
+func (s *Server) RecordRemoteWrite(result string, samples int) {
+	if s.enabled && s.remoteSamples != nil {
+		s.remoteSamples.WithLabelValues(result).Add(float64(samples))
+	}
+}
+
+// SetRemoteWritePending устанавливает количество сэмплов в буфере remote write
+func (s *Server) SetRemoteWritePending(samples int) {
+	if s.enabled && s.remotePending != nil {
+		s.remotePending.Set(float64(samples))
+	}
+}
+
+// RecordHeartbeat записывает отправленный (sent) или не отправленный
+// после всех повторов (failed) heartbeat
+func (s *Server) RecordHeartbeat(result string) {
+	if s.enabled && s.heartbeats != nil {
+		s.heartbeats.WithLabelValues(result).Inc()
+	}
+}
+
+// RecordHealthTransition записывает переход проверки здоровья check
+// из состояния from в to
+func (s *Server) RecordHealthTransition(check, from, to string) {
+	if s.enabled && s.healthChanges != nil {
+		if check, ok := s.label("health_transitions_total", check); ok {
+			s.healthChanges.WithLabelValues(check, from, to).Inc()
+		}
+	}
+}
+
+// SetStartupPhaseDuration записывает длительность этапа запуска phase
+func (s *Server) SetStartupPhaseDuration(phase string, d time.Duration) {
+	if s.enabled && s.startupTime != nil {
+		s.startupTime.WithLabelValues(phase).Set(d.Seconds())
+	}
+}
+
+// SetShutdownDuration записывает длительность этапа остановки stage
+func (s *Server) SetShutdownDuration(stage string, d time.Duration) {
+	if s.enabled && s.shutdownTime != nil {
+		s.shutdownTime.WithLabelValues(stage).Set(d.Seconds())
+	}
+}
@@ -2,12 +2,16 @@ package metrics
 
 import (
 	"context"
+	"database/sql"
 	"io"
 	"net/http"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
 	"service-boilerplate/internal/logger"
 )
 
@@ -68,6 +72,37 @@ func TestNew_Enabled(t *testing.T) {
 	}
 }
 
+// TestNewWithRegistry_Shared проверяет, что серверы с общим registry
+// используют одни метрики, а конфликт описаний не вызывает panic
+func TestNewWithRegistry_Shared(t *testing.T) {
+	log, err := logger.New("test-metrics", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	registry := prometheus.NewRegistry()
+	conflicting := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "active_timers", Help: "conflict"}, []string{"timer"})
+	registry.MustRegister(conflicting)
+
+	first := NewWithRegistry(log, true, "127.0.0.1:0", registry)
+	second := NewWithRegistry(log, true, "127.0.0.1:0", registry)
+	if first.Registry() != registry || second.Registry() != registry {
+		t.Fatal("Registry() is not the provided registry")
+	}
+
+	first.RecordTimerRun("shared")
+	second.RecordTimerRun("shared")
+	second.IncActiveTimers()
+
+	if got := testutil.ToFloat64(first.timerRuns.WithLabelValues("shared")); got != 2 {
+		t.Errorf("timer_runs_total = %v, want 2", got)
+	}
+	if got := testutil.CollectAndCount(conflicting); got != 0 {
+		t.Errorf("conflicting collector has %d series, want 0", got)
+	}
+}
+
 // TestStartStop_Disabled проверяет запуск/остановку отключенного сервера
 func TestStartStop_Disabled(t *testing.T) {
 	server, log := setupTestMetrics(t, false)
@@ -187,6 +222,115 @@ func TestRecordTimerPanic(t *testing.T) {
 	// Метрики должны быть записаны
 }
 
+// TestRecordTimerDuration_Exemplar проверяет exemplar с идентификатором трассировки
+func TestRecordTimerDuration_Exemplar(t *testing.T) {
+	server, log := setupTestMetrics(t, true)
+	defer log.Close()
+
+	server.RecordTimerDuration("traced", 2*time.Second, "4bf92f3577b34da6a3ce929d0e0e4736")
+	server.RecordTimerDuration("untraced", time.Second, "")
+
+	families, err := server.registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exemplars := make(map[string]string)
+	for _, family := range families {
+		if family.GetName() != "timer_duration_seconds" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			timer := m.GetLabel()[0].GetValue()
+			for _, bucket := range m.GetHistogram().GetBucket() {
+				for _, label := range bucket.GetExemplar().GetLabel() {
+					exemplars[timer] = label.GetName() + "=" + label.GetValue()
+				}
+			}
+		}
+	}
+	if exemplars["traced"] != "trace_id=4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("traced exemplar = %q", exemplars["traced"])
+	}
+	if _, ok := exemplars["untraced"]; ok {
+		t.Errorf("untraced observation has exemplar %q", exemplars["untraced"])
+	}
+}
+
+// TestLabelLimit проверяет агрегацию и отбрасывание значений метки сверх лимита
+func TestLabelLimit(t *testing.T) {
+	server, log := setupTestMetrics(t, true)
+	defer log.Close()
+	server.SetLabelLimit(2, LabelOverflowAggregate)
+
+	for _, name := range []string{"a", "b", "c", "d", "a"} {
+		server.RecordTimerRun(name)
+	}
+	if got := testutil.ToFloat64(server.timerRuns.WithLabelValues(OtherLabelValue)); got != 2 {
+		t.Errorf("timer_runs_total{timer=other} = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(server.timerRuns.WithLabelValues("a")); got != 2 {
+		t.Errorf("timer_runs_total{timer=a} = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(server.labelOverflow.WithLabelValues("timer_runs_total")); got != 2 {
+		t.Errorf("metrics_label_overflow_total = %v, want 2", got)
+	}
+
+	server.SetLabelLimit(1, LabelOverflowReject)
+	server.RecordJobEnqueued("email")
+	server.RecordJobEnqueued("sms")
+	if got := testutil.CollectAndCount(server.jobsEnqueued); got != 1 {
+		t.Errorf("jobs_enqueued_total series = %d, want 1", got)
+	}
+
+	stats := server.LabelCardinality()
+	want := []LabelCardinality{
+		{Metric: "jobs_enqueued_total", Values: 1, Limit: 1, Overflow: 1},
+		{Metric: "timer_runs_total", Values: 2, Limit: 1, Overflow: 2},
+	}
+	if len(stats) != len(want) || stats[0] != want[0] || stats[1] != want[1] {
+		t.Errorf("LabelCardinality() = %+v, want %+v", stats, want)
+	}
+}
+
+// TestConstLabels проверяет метки экземпляра на /metrics
+func TestConstLabels(t *testing.T) {
+	server, log := setupTestMetrics(t, true)
+	defer log.Close()
+
+	server.SetConstLabels(map[string]string{"instance_id": "abc", "hostname": "node-1", "timer": "other", "ip": ""})
+	server.RecordTimerRun("sync")
+
+	families, err := server.gatherer().Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	var got []string
+	for _, family := range families {
+		if family.GetName() != "timer_runs_total" {
+			continue
+		}
+		for _, l := range family.Metric[0].Label {
+			got = append(got, l.GetName()+"="+l.GetValue())
+		}
+	}
+	// Собственная метка timer не заменяется, пустые значения пропускаются
+	want := "hostname=node-1,instance_id=abc,timer=sync"
+	if strings.Join(got, ",") != want {
+		t.Errorf("timer_runs_total labels = %v, want %s", got, want)
+	}
+
+	// Registry отдает метрики без меток экземпляра
+	families, err = server.Registry().Gather()
+	if err != nil {
+		t.Fatalf("Registry().Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() == "timer_runs_total" && len(family.Metric[0].Label) != 1 {
+			t.Errorf("registry timer_runs_total labels = %v, want only timer", family.Metric[0].Label)
+		}
+	}
+}
+
 // TestIncDecActiveTimers проверяет изменение счетчика активных таймеров
 func TestIncDecActiveTimers(t *testing.T) {
 	server, log := setupTestMetrics(t, true)
@@ -211,6 +355,19 @@ func TestSetActiveTimers(t *testing.T) {
 	// Значения должны быть установлены без ошибок
 }
 
+// TestRecordJobMetrics проверяет запись метрик очереди заданий
+func TestRecordJobMetrics(t *testing.T) {
+	server, log := setupTestMetrics(t, true)
+	defer log.Close()
+
+	server.RecordJobEnqueued("email")
+	server.RecordJobProcessed("email", "success")
+	server.RecordJobProcessed("email", "dead_letter")
+	server.SetJobQueueDepth(3)
+
+	// Метрики должны быть записаны без ошибок
+}
+
 // TestRecordTimerRun_Disabled проверяет работу при disabled
 func TestRecordTimerRun_Disabled(t *testing.T) {
 	server, log := setupTestMetrics(t, false)
@@ -219,9 +376,46 @@ func TestRecordTimerRun_Disabled(t *testing.T) {
 	// Не должно быть panic при disabled
 	server.RecordTimerRun("timer")
 	server.RecordTimerPanic("timer")
+	server.RecordTimerDuration("timer", time.Millisecond, "4bf92f3577b34da6a3ce929d0e0e4736")
+	server.RecordTimerTicksMissed("timer", 2)
+	server.RecordTimerQueueWait("timer", time.Millisecond)
+	server.SetTimerRunsQueued(3)
+	server.SetTimerHandlerPool(2, 1)
+	server.RecordTimerBudgetExceeded("timer")
+	server.SetTimerThrottle("timer", 2)
+	server.RecordNamespaceRun("tenant", "ok")
+	server.SetNamespaceEnabled("tenant", false)
 	server.IncActiveTimers()
 	server.DecActiveTimers()
 	server.SetActiveTimers(5)
+	server.SetLeader(true)
+	server.RecordWatchEvent("inbox", "create")
+	server.RecordHTTPRequest("http", "GET /items", "GET", 200, time.Millisecond)
+	server.SetDBPoolStats(sql.DBStats{OpenConnections: 1})
+	server.RecordRedisCommand("get", time.Millisecond, false)
+	server.RecordHTTPClientRequest("api", "GET", "200", time.Millisecond)
+	server.SetBreakerState("api", 2)
+	server.RecordRateLimitWait("api", time.Millisecond)
+	server.RecordRateLimitRejected("api")
+	server.RecordProcessRestart("legacy")
+	server.SetProcessUp("legacy", true)
+	server.RecordHookRun("post_start", "ok")
+	server.RecordClockJump("forward")
+	server.SetClockDrift(time.Second)
+	server.RecordConfigRollback()
+	server.RecordAlert("slack", false)
+	server.RecordAlertSuppressed()
+	server.RecordProfile("cpu", false)
+	server.RecordConfigReload(time.Now(), true)
+	server.RecordRemoteWrite("sent", 10)
+	server.SetRemoteWritePending(0)
+	server.RecordHealthTransition("db", "healthy", "degraded")
+	server.SetShutdownDuration("total", time.Second)
+	if server.FinalScrape(context.Background(), time.Second) {
+		t.Error("FinalScrape() = true for disabled server")
+	}
+	server.SetLabelLimit(10, LabelOverflowReject)
+	server.LabelCardinality()
 }
 
 // TestUptimeMetric проверяет метрику uptime
@@ -263,6 +457,61 @@ func TestUptimeMetric(t *testing.T) {
 }
 
 // TestGracefulShutdown проверяет graceful shutdown
+// TestFinalScrape проверяет выдачу итогового снимка метрик при остановке
+func TestFinalScrape(t *testing.T) {
+	server, log := setupTestMetrics(t, true)
+	defer log.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer server.Stop(ctx)
+	waitForServer(t, server.GetAddress(), 2*time.Second)
+
+	server.RecordTimerRun("report")
+	server.SetShutdownDuration("total", 1500*time.Millisecond)
+
+	// Без опроса ожидание заканчивается по истечении окна
+	start := time.Now()
+	if server.FinalScrape(ctx, 100*time.Millisecond) {
+		t.Error("FinalScrape() = true without scrape")
+	}
+	if time.Since(start) < 100*time.Millisecond {
+		t.Error("FinalScrape() returned before the window elapsed")
+	}
+
+	done := make(chan bool, 1)
+	go func() { done <- server.FinalScrape(ctx, 5*time.Second) }()
+	// Значения после снятия снимка в выдачу не попадают
+	time.Sleep(50 * time.Millisecond)
+	server.RecordTimerRun("late")
+
+	resp, err := http.Get("http://" + server.GetAddress() + "/metrics")
+	if err != nil {
+		t.Fatalf("HTTP request error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	for _, want := range []string{`timer_runs_total{timer="report"} 1`, `shutdown_duration_seconds{stage="total"} 1.5`} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("final snapshot does not contain %s", want)
+		}
+	}
+	if strings.Contains(string(body), `timer="late"`) {
+		t.Error("final snapshot contains metrics recorded after it was taken")
+	}
+	select {
+	case scraped := <-done:
+		if !scraped {
+			t.Error("FinalScrape() = false after scrape")
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("FinalScrape() did not return after scrape")
+	}
+}
+
 func TestGracefulShutdown(t *testing.T) {
 	server, log := setupTestMetrics(t, true)
 	defer log.Close()
@@ -0,0 +1,41 @@
+// Package paths определяет директории данных, состояния, кэша и логов
+// сервиса по умолчанию для платформы: ProgramData на Windows, /var/lib,
+// /var/cache и /var/log на Linux, ~/Library на macOS. Пути не зависят
+// от расположения исполняемого файла и рабочей директории процесса
+package paths
+
+// Dirs директории сервиса
+type Dirs struct {
+	// Data данные приложения, которые нужно сохранять (обработчики таймеров и заданий)
+	Data string
+	// State состояние между запусками: хранилище, история запусков
+	State string
+	// Cache данные, которые можно удалить без потери состояния
+	Cache string
+	// Log логи, отчеты о падении, профили и журнал аудита
+	Log string
+}
+
+// Default возвращает директории сервиса name по умолчанию для платформы
+func Default(name string) Dirs {
+	return defaultDirs(name)
+}
+
+// Resolve дополняет незаданные директории dirs значениями по умолчанию
+// для сервиса name. Заданные директории возвращаются без изменений
+func Resolve(name string, dirs Dirs) Dirs {
+	def := Default(name)
+	if dirs.Data == "" {
+		dirs.Data = def.Data
+	}
+	if dirs.State == "" {
+		dirs.State = def.State
+	}
+	if dirs.Cache == "" {
+		dirs.Cache = def.Cache
+	}
+	if dirs.Log == "" {
+		dirs.Log = def.Log
+	}
+	return dirs
+}
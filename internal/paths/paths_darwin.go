@@ -0,0 +1,31 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// defaultDirs возвращает директории в /Library для процесса root
+// и в ~/Library пользователя для остальных
+func defaultDirs(name string) Dirs {
+	base := "/Library"
+	if os.Geteuid() != 0 {
+		base = filepath.Join(homeDir(), "Library")
+	}
+	support := filepath.Join(base, "Application Support", name)
+	return Dirs{
+		Data:  support,
+		State: filepath.Join(support, "state"),
+		Cache: filepath.Join(base, "Caches", name),
+		Log:   filepath.Join(base, "Logs", name),
+	}
+}
+
+// homeDir возвращает домашнюю директорию пользователя или временную
+// директорию, если домашняя неизвестна (пользователь без HOME)
+func homeDir() string {
+	if home, err := os.UserHomeDir(); err == nil {
+		return home
+	}
+	return os.TempDir()
+}
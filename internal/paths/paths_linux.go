@@ -0,0 +1,44 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// defaultDirs возвращает системные директории для процесса root
+// и директории XDG пользователя для остальных
+func defaultDirs(name string) Dirs {
+	if os.Geteuid() == 0 {
+		return Dirs{
+			Data:  filepath.Join("/var/lib", name),
+			State: filepath.Join("/var/lib", name, "state"),
+			Cache: filepath.Join("/var/cache", name),
+			Log:   filepath.Join("/var/log", name),
+		}
+	}
+	state := filepath.Join(xdgDir("XDG_STATE_HOME", ".local/state"), name)
+	return Dirs{
+		Data:  filepath.Join(xdgDir("XDG_DATA_HOME", ".local/share"), name),
+		State: state,
+		Cache: filepath.Join(xdgDir("XDG_CACHE_HOME", ".cache"), name),
+		Log:   filepath.Join(state, "logs"),
+	}
+}
+
+// xdgDir возвращает абсолютный путь из переменной env или fallback
+// относительно домашней директории
+func xdgDir(env, fallback string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(homeDir(), fallback)
+}
+
+// homeDir возвращает домашнюю директорию пользователя или временную
+// директорию, если домашняя неизвестна (пользователь без HOME)
+func homeDir() string {
+	if home, err := os.UserHomeDir(); err == nil {
+		return home
+	}
+	return os.TempDir()
+}
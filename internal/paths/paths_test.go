@@ -0,0 +1,38 @@
+package paths
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDefault проверяет, что директории по умолчанию абсолютные,
+// различаются и содержат имя сервиса
+func TestDefault(t *testing.T) {
+	dirs := Default("svc")
+	seen := make(map[string]bool)
+	for kind, dir := range map[string]string{"data": dirs.Data, "state": dirs.State, "cache": dirs.Cache, "log": dirs.Log} {
+		if !filepath.IsAbs(dir) {
+			t.Errorf("%s dir %q is not absolute", kind, dir)
+		}
+		if !strings.Contains(dir, "svc") {
+			t.Errorf("%s dir %q does not contain service name", kind, dir)
+		}
+		if seen[dir] {
+			t.Errorf("%s dir %q is shared with another kind", kind, dir)
+		}
+		seen[dir] = true
+	}
+}
+
+// TestResolve проверяет, что заданные директории не заменяются
+func TestResolve(t *testing.T) {
+	logDir := filepath.Join(t.TempDir(), "logs")
+	dirs := Resolve("svc", Dirs{Log: logDir})
+	if dirs.Log != logDir {
+		t.Errorf("Log = %q, want %q", dirs.Log, logDir)
+	}
+	if def := Default("svc"); dirs.Data != def.Data || dirs.State != def.State || dirs.Cache != def.Cache {
+		t.Errorf("Resolve() = %+v, want defaults %+v for unset dirs", dirs, def)
+	}
+}
@@ -0,0 +1,25 @@
+//go:build windows
+// +build windows
+
+package paths
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// defaultDirs возвращает директории в %ProgramData%\<name>: сервис
+// работает от системной учетной записи, а профиль пользователя недоступен
+func defaultDirs(name string) Dirs {
+	programData := os.Getenv("ProgramData")
+	if programData == "" {
+		programData = `C:\ProgramData`
+	}
+	base := filepath.Join(programData, name)
+	return Dirs{
+		Data:  filepath.Join(base, "data"),
+		State: filepath.Join(base, "state"),
+		Cache: filepath.Join(base, "cache"),
+		Log:   filepath.Join(base, "logs"),
+	}
+}
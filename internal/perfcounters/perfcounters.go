@@ -0,0 +1,283 @@
+// Package perfcounters публикует ключевые метрики сервиса как счетчики
+// производительности Windows (Perflib v2), чтобы их видел perfmon и
+// системы мониторинга, которые не опрашивают /metrics. Набор счетчиков
+// описывается манифестом, который регистрируется через lodctr при
+// установке сервиса. На других платформах публикация не поддерживается
+package perfcounters
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/xml"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"service-boilerplate/internal/logger"
+)
+
+// Типы счетчиков Perflib (winperf.h)
+const (
+	// perfCounterBulkCount 64-битный счетчик, perfmon показывает скорость в секунду
+	perfCounterBulkCount = 0x10410500
+	// perfCounterLargeRawcount 64-битное значение, показывается как есть
+	perfCounterLargeRawcount = 0x00010100
+)
+
+// counter описывает счетчик производительности и метрику, из которой он берется
+type counter struct {
+	id          uint32
+	name        string
+	description string
+	// kind тип счетчика (perfCounterBulkCount, perfCounterLargeRawcount)
+	kind uint32
+	// metric имя метрики Prometheus, значения всех серий суммируются
+	metric string
+}
+
+// counters счетчики набора, id соответствуют манифесту
+var counters = []counter{
+	{1, "Timer Runs/sec", "Timer executions per second", perfCounterBulkCount, "timer_runs_total"},
+	{2, "Timer Panics", "Total number of timer panics", perfCounterLargeRawcount, "timer_panics_total"},
+	{3, "Active Timers", "Number of active timers", perfCounterLargeRawcount, "active_timers"},
+	{4, "Uptime Seconds", "Service uptime in seconds", perfCounterLargeRawcount, "service_uptime_seconds"},
+}
+
+// guidNamespace пространство имен для GUID провайдера и набора счетчиков
+var guidNamespace = [16]byte{0x6b, 0x1f, 0x3e, 0x52, 0x8c, 0x0d, 0x4a, 0x51, 0x9e, 0x27, 0x4d, 0x30, 0xa1, 0x5c, 0x77, 0x02}
+
+// guid идентификатор в каноническом порядке байт
+type guid [16]byte
+
+// String возвращает GUID в формате манифеста: {xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx}
+func (g guid) String() string {
+	return fmt.Sprintf("{%x-%x-%x-%x-%x}", g[0:4], g[4:6], g[6:8], g[8:10], g[10:16])
+}
+
+// nameGUID возвращает UUID версии 5 от имени. GUID детерминированы,
+// поэтому манифест для удаления можно построить заново по имени сервиса
+func nameGUID(name string) guid {
+	h := sha1.New()
+	h.Write(guidNamespace[:])
+	h.Write([]byte(name))
+	var g guid
+	copy(g[:], h.Sum(nil))
+	g[6] = (g[6] & 0x0f) | 0x50
+	g[8] = (g[8] & 0x3f) | 0x80
+	return g
+}
+
+// providerGUID возвращает GUID провайдера счетчиков сервиса
+func providerGUID(serviceName string) guid {
+	return nameGUID(serviceName + "/provider")
+}
+
+// counterSetGUID возвращает GUID набора счетчиков сервиса
+func counterSetGUID(serviceName string) guid {
+	return nameGUID(serviceName + "/counterset")
+}
+
+// Элементы манифеста счетчиков (схема counters 2.0)
+type (
+	manifestXML struct {
+		XMLName         xml.Name `xml:"instrumentationManifest"`
+		Xmlns           string   `xml:"xmlns,attr"`
+		Instrumentation struct {
+			Counters countersXML `xml:"counters"`
+		} `xml:"instrumentation"`
+	}
+	countersXML struct {
+		Xmlns         string      `xml:"xmlns,attr"`
+		SchemaVersion string      `xml:"schemaVersion,attr"`
+		Provider      providerXML `xml:"provider"`
+	}
+	providerXML struct {
+		Callback            string        `xml:"callback,attr"`
+		ApplicationIdentity string        `xml:"applicationIdentity,attr"`
+		ProviderType        string        `xml:"providerType,attr"`
+		ProviderGUID        string        `xml:"providerGuid,attr"`
+		CounterSet          counterSetXML `xml:"counterSet"`
+	}
+	counterSetXML struct {
+		GUID        string       `xml:"guid,attr"`
+		URI         string       `xml:"uri,attr"`
+		Name        string       `xml:"name,attr"`
+		Description string       `xml:"description,attr"`
+		Instances   string       `xml:"instances,attr"`
+		Counters    []counterXML `xml:"counter"`
+	}
+	counterXML struct {
+		ID          uint32 `xml:"id,attr"`
+		URI         string `xml:"uri,attr"`
+		Name        string `xml:"name,attr"`
+		Description string `xml:"description,attr"`
+		Type        string `xml:"type,attr"`
+		DetailLevel string `xml:"detailLevel,attr"`
+	}
+)
+
+// Manifest возвращает манифест счетчиков сервиса для lodctr /m.
+// exePath - бинарник провайдера (applicationIdentity)
+func Manifest(serviceName, exePath string) []byte {
+	var m manifestXML
+	m.Xmlns = "http://schemas.microsoft.com/win/2004/08/events"
+	m.Instrumentation.Counters = countersXML{
+		Xmlns:         "http://schemas.microsoft.com/win/2005/12/counters",
+		SchemaVersion: "2.0",
+		Provider: providerXML{
+			Callback:            "custom",
+			ApplicationIdentity: exePath,
+			ProviderType:        "userMode",
+			ProviderGUID:        providerGUID(serviceName).String(),
+			CounterSet: counterSetXML{
+				GUID:        counterSetGUID(serviceName).String(),
+				URI:         serviceName,
+				Name:        serviceName,
+				Description: "Service " + serviceName + " timers and uptime",
+				Instances:   "multiple",
+			},
+		},
+	}
+	for _, c := range counters {
+		kind := "perf_counter_large_rawcount"
+		if c.kind == perfCounterBulkCount {
+			kind = "perf_counter_bulk_count"
+		}
+		m.Instrumentation.Counters.Provider.CounterSet.Counters = append(m.Instrumentation.Counters.Provider.CounterSet.Counters, counterXML{
+			ID:          c.id,
+			URI:         serviceName + "." + c.metric,
+			Name:        c.name,
+			Description: c.description,
+			Type:        kind,
+			DetailLevel: "standard",
+		})
+	}
+
+	data, _ := xml.MarshalIndent(m, "", "  ")
+	return append([]byte(xml.Header), append(data, '\n')...)
+}
+
+// snapshot возвращает значения счетчиков по id из метрик gatherer
+func snapshot(gatherer prometheus.Gatherer) (map[uint32]uint64, error) {
+	families, err := gatherer.Gather()
+	sums := make(map[string]float64, len(families))
+	for _, family := range families {
+		for _, m := range family.Metric {
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				sums[family.GetName()] += m.GetCounter().GetValue()
+			case dto.MetricType_GAUGE:
+				sums[family.GetName()] += m.GetGauge().GetValue()
+			}
+		}
+	}
+
+	values := make(map[uint32]uint64, len(counters))
+	for _, c := range counters {
+		if v := sums[c.metric]; v > 0 {
+			values[c.id] = uint64(v)
+		}
+	}
+	return values, err
+}
+
+// Config содержит настройки публикации
+type Config struct {
+	// ServiceName имя сервиса: определяет GUID набора счетчиков и имя экземпляра
+	ServiceName string
+	// Interval период обновления значений счетчиков
+	Interval time.Duration
+}
+
+// Publisher периодически обновляет счетчики производительности из метрик.
+// Реализует task.Task
+type Publisher struct {
+	log      *logger.Logger
+	gatherer prometheus.Gatherer
+	cfg      Config
+
+	provider *provider
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// New создает публикацию счетчиков из gatherer (metrics.Server.Gatherer)
+func New(log *logger.Logger, gatherer prometheus.Gatherer, cfg Config) *Publisher {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	return &Publisher{log: log, gatherer: gatherer, cfg: cfg}
+}
+
+// Name возвращает имя задачи
+func (p *Publisher) Name() string {
+	return "perf-counters"
+}
+
+// AfterStart регистрирует провайдер счетчиков и запускает обновление значений.
+// Ошибка провайдера не останавливает сервис: счетчики не публикуются
+func (p *Publisher) AfterStart(ctx context.Context) error {
+	prov, err := openProvider(p.cfg.ServiceName)
+	if err != nil {
+		p.log.Warn("Failed to start performance counters provider", map[string]interface{}{"error": err.Error()})
+		return nil
+	}
+	p.provider = prov
+	p.update()
+
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.wg.Add(1)
+	go p.loop(ctx)
+
+	p.log.Info("Performance counters published", map[string]interface{}{
+		"counter_set": p.cfg.ServiceName,
+		"interval":    p.cfg.Interval.String(),
+	})
+	return nil
+}
+
+// BeforeStop останавливает обновление и удаляет экземпляр набора счетчиков
+func (p *Publisher) BeforeStop(ctx context.Context) error {
+	if p.cancel == nil {
+		return nil
+	}
+	p.cancel()
+	p.wg.Wait()
+	p.provider.close()
+	return nil
+}
+
+// loop обновляет значения каждые Interval
+func (p *Publisher) loop(ctx context.Context) {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.update()
+		}
+	}
+}
+
+// update переносит текущие значения метрик в счетчики
+func (p *Publisher) update() {
+	values, err := snapshot(p.gatherer)
+	if err != nil {
+		// Gather возвращает метрики, которые удалось собрать, вместе с ошибкой
+		p.log.Debug("Failed to gather metrics for performance counters", map[string]interface{}{"error": err.Error()})
+	}
+	for _, c := range counters {
+		if err := p.provider.set(c.id, values[c.id]); err != nil {
+			p.log.Debug("Failed to set performance counter", map[string]interface{}{
+				"counter": c.name,
+				"error":   err.Error(),
+			})
+		}
+	}
+}
@@ -0,0 +1,37 @@
+//go:build !windows
+// +build !windows
+
+package perfcounters
+
+import "errors"
+
+// errUnsupported счетчики производительности есть только в Windows
+var errUnsupported = errors.New("performance counters are supported only on Windows")
+
+// provider заглушка провайдера для платформ без счетчиков производительности
+type provider struct{}
+
+// openProvider возвращает errUnsupported
+func openProvider(serviceName string) (*provider, error) {
+	return nil, errUnsupported
+}
+
+// set ничего не делает
+func (p *provider) set(id uint32, value uint64) error {
+	return errUnsupported
+}
+
+// close ничего не делает
+func (p *provider) close() {}
+
+// Register регистрирует набор счетчиков сервиса
+func Register(serviceName, exePath string) error {
+	// Вне Windows счетчики производительности не используются
+	return nil
+}
+
+// Unregister снимает регистрацию набора счетчиков сервиса
+func Unregister(serviceName, exePath string) error {
+	// Вне Windows счетчики производительности не используются
+	return nil
+}
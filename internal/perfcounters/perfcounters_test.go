@@ -0,0 +1,60 @@
+package perfcounters
+
+import (
+	"encoding/xml"
+	"regexp"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TestSnapshot проверяет сумму серий метрик и значения по id счетчиков
+func TestSnapshot(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	runs := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "timer_runs_total", Help: "runs"}, []string{"timer"})
+	runs.WithLabelValues("a").Add(3)
+	runs.WithLabelValues("b").Add(4)
+	active := prometheus.NewGauge(prometheus.GaugeOpts{Name: "active_timers", Help: "active"})
+	active.Set(2)
+	registry.MustRegister(runs, active)
+
+	values, err := snapshot(registry)
+	if err != nil {
+		t.Fatalf("snapshot() error = %v", err)
+	}
+	want := map[uint32]uint64{1: 7, 2: 0, 3: 2, 4: 0}
+	for id, value := range want {
+		if values[id] != value {
+			t.Errorf("counter %d = %d, want %d", id, values[id], value)
+		}
+	}
+}
+
+// TestManifest проверяет манифест и детерминированность GUID
+func TestManifest(t *testing.T) {
+	var m manifestXML
+	if err := xml.Unmarshal(Manifest("worker-a", `C:\svc\worker.exe`), &m); err != nil {
+		t.Fatalf("manifest is not valid XML: %v", err)
+	}
+	provider := m.Instrumentation.Counters.Provider
+	if provider.ApplicationIdentity != `C:\svc\worker.exe` || provider.Callback != "custom" {
+		t.Errorf("provider = %+v", provider)
+	}
+	if provider.CounterSet.Name != "worker-a" || len(provider.CounterSet.Counters) != len(counters) {
+		t.Errorf("counter set = %+v", provider.CounterSet)
+	}
+	if provider.CounterSet.Counters[0].Type != "perf_counter_bulk_count" {
+		t.Errorf("Timer Runs/sec type = %s, want perf_counter_bulk_count", provider.CounterSet.Counters[0].Type)
+	}
+
+	format := regexp.MustCompile(`^\{[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}\}$`)
+	if !format.MatchString(provider.ProviderGUID) || !format.MatchString(provider.CounterSet.GUID) {
+		t.Errorf("GUIDs %s, %s are not UUID v5", provider.ProviderGUID, provider.CounterSet.GUID)
+	}
+	if provider.ProviderGUID != providerGUID("worker-a").String() {
+		t.Error("provider GUID is not deterministic")
+	}
+	if providerGUID("worker-a") == providerGUID("worker-b") || providerGUID("worker-a") == counterSetGUID("worker-a") {
+		t.Error("GUIDs of different services or objects must differ")
+	}
+}
@@ -0,0 +1,176 @@
+//go:build windows
+// +build windows
+
+package perfcounters
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Функции Perflib v2 (advapi32.dll)
+var (
+	advapi32                         = windows.NewLazySystemDLL("advapi32.dll")
+	procPerfStartProvider            = advapi32.NewProc("PerfStartProvider")
+	procPerfStopProvider             = advapi32.NewProc("PerfStopProvider")
+	procPerfSetCounterSetInfo        = advapi32.NewProc("PerfSetCounterSetInfo")
+	procPerfCreateInstance           = advapi32.NewProc("PerfCreateInstance")
+	procPerfDeleteInstance           = advapi32.NewProc("PerfDeleteInstance")
+	procPerfSetULongLongCounterValue = advapi32.NewProc("PerfSetULongLongCounterValue")
+)
+
+const (
+	// perfCountersetMultiInstances тип набора счетчиков с экземплярами
+	perfCountersetMultiInstances = 2
+	// perfDetailNovice уровень детализации счетчиков (detailLevel="standard")
+	perfDetailNovice = 100
+	// counterSetInfoSize размер PERF_COUNTERSET_INFO
+	counterSetInfoSize = 40
+	// counterInfoSize размер PERF_COUNTER_INFO
+	counterInfoSize = 32
+)
+
+// provider зарегистрированный провайдер счетчиков с одним экземпляром набора
+type provider struct {
+	handle   windows.Handle
+	instance uintptr
+}
+
+// windowsGUID переводит GUID из канонического порядка байт в структуру Windows
+func windowsGUID(g guid) windows.GUID {
+	w := windows.GUID{
+		Data1: binary.BigEndian.Uint32(g[0:4]),
+		Data2: binary.BigEndian.Uint16(g[4:6]),
+		Data3: binary.BigEndian.Uint16(g[6:8]),
+	}
+	copy(w.Data4[:], g[8:16])
+	return w
+}
+
+// counterSetTemplate возвращает PERF_COUNTERSET_INFO с массивом
+// PERF_COUNTER_INFO. Значения счетчиков хранятся в блоке экземпляра
+// по 8 байт в порядке counters
+func counterSetTemplate(serviceName string) []byte {
+	buf := make([]byte, counterSetInfoSize+counterInfoSize*len(counters))
+	*(*windows.GUID)(unsafe.Pointer(&buf[0])) = windowsGUID(counterSetGUID(serviceName))
+	*(*windows.GUID)(unsafe.Pointer(&buf[16])) = windowsGUID(providerGUID(serviceName))
+	binary.LittleEndian.PutUint32(buf[32:], uint32(len(counters)))
+	binary.LittleEndian.PutUint32(buf[36:], perfCountersetMultiInstances)
+	for i, c := range counters {
+		info := buf[counterSetInfoSize+i*counterInfoSize:]
+		binary.LittleEndian.PutUint32(info[0:], c.id)
+		binary.LittleEndian.PutUint32(info[4:], c.kind)
+		// Attrib (8 байт) = 0: значение хранится в блоке экземпляра
+		binary.LittleEndian.PutUint32(info[16:], 8)
+		binary.LittleEndian.PutUint32(info[20:], perfDetailNovice)
+		// Scale (4 байта) = 0
+		binary.LittleEndian.PutUint32(info[28:], uint32(i*8))
+	}
+	return buf
+}
+
+// openProvider регистрирует провайдер, шаблон набора счетчиков и экземпляр
+// с именем сервиса
+func openProvider(serviceName string) (*provider, error) {
+	if err := advapi32.Load(); err != nil {
+		return nil, err
+	}
+
+	providerID := windowsGUID(providerGUID(serviceName))
+	p := &provider{}
+	if r, _, _ := procPerfStartProvider.Call(uintptr(unsafe.Pointer(&providerID)), 0, uintptr(unsafe.Pointer(&p.handle))); r != 0 {
+		return nil, fmt.Errorf("PerfStartProvider: %w", windows.Errno(r))
+	}
+
+	template := counterSetTemplate(serviceName)
+	if r, _, _ := procPerfSetCounterSetInfo.Call(uintptr(p.handle), uintptr(unsafe.Pointer(&template[0])), uintptr(len(template))); r != 0 {
+		p.close()
+		return nil, fmt.Errorf("PerfSetCounterSetInfo: %w", windows.Errno(r))
+	}
+
+	counterSetID := windowsGUID(counterSetGUID(serviceName))
+	name, err := windows.UTF16PtrFromString(serviceName)
+	if err != nil {
+		p.close()
+		return nil, err
+	}
+	instance, _, callErr := procPerfCreateInstance.Call(uintptr(p.handle), uintptr(unsafe.Pointer(&counterSetID)), uintptr(unsafe.Pointer(name)), 0)
+	if instance == 0 {
+		p.close()
+		return nil, fmt.Errorf("PerfCreateInstance: %w", callErr)
+	}
+	p.instance = instance
+	return p, nil
+}
+
+// set записывает значение счетчика экземпляра
+func (p *provider) set(id uint32, value uint64) error {
+	var r uintptr
+	if unsafe.Sizeof(uintptr(0)) == 8 {
+		r, _, _ = procPerfSetULongLongCounterValue.Call(uintptr(p.handle), p.instance, uintptr(id), uintptr(value))
+	} else {
+		// На 32-битных платформах ULONGLONG передается двумя словами
+		r, _, _ = procPerfSetULongLongCounterValue.Call(uintptr(p.handle), p.instance, uintptr(id), uintptr(uint32(value)), uintptr(value>>32))
+	}
+	if r != 0 {
+		return windows.Errno(r)
+	}
+	return nil
+}
+
+// close удаляет экземпляр и снимает регистрацию провайдера
+func (p *provider) close() {
+	if p.instance != 0 {
+		procPerfDeleteInstance.Call(uintptr(p.handle), p.instance)
+		p.instance = 0
+	}
+	if p.handle != 0 {
+		procPerfStopProvider.Call(uintptr(p.handle))
+		p.handle = 0
+	}
+}
+
+// ManifestPath возвращает путь манифеста счетчиков рядом с бинарником
+func ManifestPath(serviceName, exePath string) string {
+	return filepath.Join(filepath.Dir(exePath), serviceName+".perfcounters.man")
+}
+
+// Register записывает манифест рядом с бинарником и регистрирует набор
+// счетчиков сервиса (lodctr /m). Требуются права администратора
+func Register(serviceName, exePath string) error {
+	path := ManifestPath(serviceName, exePath)
+	if err := os.WriteFile(path, Manifest(serviceName, exePath), 0644); err != nil {
+		return fmt.Errorf("failed to write counters manifest: %w", err)
+	}
+	return runCounterTool("lodctr", path)
+}
+
+// Unregister снимает регистрацию набора счетчиков сервиса (unlodctr /m)
+// и удаляет манифест. Манифест строится заново, если файла нет
+func Unregister(serviceName, exePath string) error {
+	path := ManifestPath(serviceName, exePath)
+	if _, err := os.Stat(path); err != nil {
+		if err := os.WriteFile(path, Manifest(serviceName, exePath), 0644); err != nil {
+			return fmt.Errorf("failed to write counters manifest: %w", err)
+		}
+	}
+	err := runCounterTool("unlodctr", path)
+	os.Remove(path)
+	return err
+}
+
+// runCounterTool запускает lodctr или unlodctr с манифестом
+func runCounterTool(tool, manifest string) error {
+	out, err := exec.Command(tool, "/m:"+manifest).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %w: %s", tool, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package platform
+
+import "errors"
+
+var (
+	// ErrAlreadyRunning сервис уже запущен
+	ErrAlreadyRunning = errors.New("service is already running")
+	// ErrAlreadyInstalled сервис уже установлен
+	ErrAlreadyInstalled = errors.New("service is already installed")
+	// ErrNotInstalled сервис не установлен
+	ErrNotInstalled = errors.New("service is not installed")
+)
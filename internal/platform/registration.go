@@ -0,0 +1,40 @@
+package platform
+
+import "time"
+
+// Типы запуска сервиса (service.start_type)
+const (
+	StartAuto     = "auto"
+	StartDelayed  = "delayed"
+	StartManual   = "manual"
+	StartDisabled = "disabled"
+)
+
+// Политики перезапуска после падения (service.recovery.restart)
+const (
+	RestartAlways    = "always"
+	RestartOnFailure = "on-failure"
+	RestartNever     = "never"
+)
+
+// Registration параметры регистрации сервиса в менеджере сервисов
+// (Windows SCM или systemd unit)
+type Registration struct {
+	Name        string
+	DisplayName string
+	Description string
+	ExecPath    string
+	// Args аргументы, с которыми менеджер сервисов запускает бинарник
+	Args []string
+	// StartType тип запуска. На Linux delayed равнозначен auto
+	StartType string
+	// Restart политика перезапуска после падения с задержкой RestartDelay
+	Restart      string
+	RestartDelay time.Duration
+	// ResetPeriod время без сбоев, после которого SCM сбрасывает счетчик
+	// сбоев (только Windows)
+	ResetPeriod time.Duration
+	// UnitDir директория unit файла (только Linux). Пустая - unit ищется
+	// там, откуда его загрузил systemd, или в DefaultUnitDir
+	UnitDir string
+}
@@ -0,0 +1,62 @@
+package platform
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"service-boilerplate/internal/app"
+	"service-boilerplate/internal/logger"
+)
+
+// notifier отправляет состояние сервиса systemd по протоколу sd_notify.
+// Реализует app.StartupReporter
+type notifier struct {
+	log  *logger.Logger
+	addr *net.UnixAddr
+}
+
+// newNotifier возвращает notifier для сокета из NOTIFY_SOCKET или nil,
+// если сервис запущен не systemd с Type=notify
+func newNotifier(log *logger.Logger) *notifier {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	// Абстрактный сокет задается с префиксом @
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+	return &notifier{log: log, addr: &net.UnixAddr{Name: socket, Net: "unixgram"}}
+}
+
+// Starting сообщает задачу, которая запускается, и продлевает таймаут
+// запуска unit (TimeoutStartSec) на время шага
+func (n *notifier) Starting(p app.StartupProgress) {
+	n.send(fmt.Sprintf("STATUS=Starting %s (%d/%d)\nEXTEND_TIMEOUT_USEC=%d",
+		p.Task, p.Step, p.Total, p.WaitHint.Microseconds()))
+}
+
+// Started сообщает о готовности сервиса
+func (n *notifier) Started() {
+	n.send("READY=1\nSTATUS=Running")
+}
+
+// Stopping сообщает о начале остановки
+func (n *notifier) Stopping() {
+	n.send("STOPPING=1\nSTATUS=Stopping")
+}
+
+// send отправляет сообщение в сокет уведомлений. Ошибка не прерывает
+// работу сервиса: systemd лишь не получит состояние
+func (n *notifier) send(state string) {
+	conn, err := net.DialUnix("unixgram", nil, n.addr)
+	if err == nil {
+		_, err = conn.Write([]byte(state))
+		conn.Close()
+	}
+	if err != nil {
+		n.log.Debug("Failed to notify systemd", map[string]interface{}{"error": err.Error()})
+	}
+}
@@ -1,15 +1,15 @@
-//go:build !windows
-// +build !windows
-
 // Package platform предоставляет кроссплатформенную реализацию сервиса для Linux
 package platform
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 
 	"service-boilerplate/internal/app"
@@ -28,6 +28,28 @@ func Run(log *logger.Logger, application *app.App) error {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
 
+	// SIGHUP (systemctl reload) перезагружает конфигурацию
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	defer signal.Stop(hupChan)
+	go func() {
+		for {
+			select {
+			case <-hupChan:
+				log.Info("Received SIGHUP, reloading config")
+				// Результат и ошибка проверки пишутся в лог самим Reload
+				application.Reload()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Под systemd (Type=notify) сообщаем ход запуска и готовность
+	if n := newNotifier(log); n != nil {
+		application.SetStartupReporter(n)
+	}
+
 	// Запускаем приложение в отдельной горутине
 	errChan := make(chan error, 1)
 	go func() {
@@ -53,7 +75,7 @@ func Run(log *logger.Logger, application *app.App) error {
 func Start(serviceName string) error {
 	cmd := exec.Command("systemctl", "start", serviceName)
 	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("failed to start service: %w (output: %s)", err, string(output))
+		return systemctlError("start", err, output)
 	}
 	return nil
 }
@@ -62,17 +84,114 @@ func Start(serviceName string) error {
 func Stop(serviceName string) error {
 	cmd := exec.Command("systemctl", "stop", serviceName)
 	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("failed to stop service: %w (output: %s)", err, string(output))
+		return systemctlError("stop", err, output)
 	}
 	return nil
 }
 
+// systemctlError формирует ошибку systemctl, распознавая типовые причины
+func systemctlError(op string, err error, output []byte) error {
+	out := string(output)
+	switch {
+	case strings.Contains(out, "Access denied") || strings.Contains(out, "authentication required"):
+		return fmt.Errorf("failed to %s service: %w (output: %s)", op, os.ErrPermission, out)
+	case strings.Contains(out, "not loaded") || strings.Contains(out, "not found"):
+		return fmt.Errorf("failed to %s service: %w (output: %s)", op, ErrNotInstalled, out)
+	}
+	return fmt.Errorf("failed to %s service: %w (output: %s)", op, err, out)
+}
+
 // Install устанавливает systemd сервис
-func Install(serviceName, displayName, description, execPath string) error {
-	return fmt.Errorf("install on Linux: use scripts/install.sh instead")
+func Install(reg Registration) error {
+	return fmt.Errorf("install on Linux: use bootstrap or scripts/install.sh instead")
+}
+
+// Reconfigure перезаписывает systemd unit установленного сервиса по reg,
+// перечитывает конфигурацию systemd и включает или отключает автозапуск
+// по типу запуска. Запущенный сервис не перезапускается
+func Reconfigure(reg Registration) error {
+	unitPath := installedUnitPath(reg)
+	if _, err := os.Stat(unitPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %s", ErrNotInstalled, reg.Name)
+		}
+		return err
+	}
+
+	if err := os.WriteFile(unitPath, []byte(UnitFile(reg)), 0644); err != nil {
+		return fmt.Errorf("failed to write unit file: %w", err)
+	}
+	if err := DaemonReload(); err != nil {
+		return err
+	}
+
+	op := "enable"
+	if reg.StartType == StartManual || reg.StartType == StartDisabled {
+		op = "disable"
+	}
+	cmd := exec.Command("systemctl", op, reg.Name)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return systemctlError(op, err, output)
+	}
+	return nil
+}
+
+// Uninstall останавливает сервис и удаляет его systemd unit
+func Uninstall(reg Registration) error {
+	unitPath := installedUnitPath(reg)
+	if _, err := os.Stat(unitPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %s", ErrNotInstalled, reg.Name)
+		}
+		return err
+	}
+
+	// Ошибки остановки и отключения не критичны: сервис может быть не запущен
+	exec.Command("systemctl", "stop", reg.Name).Run()
+	exec.Command("systemctl", "disable", reg.Name).Run()
+
+	if err := os.Remove(unitPath); err != nil {
+		return fmt.Errorf("failed to remove unit file: %w", err)
+	}
+	return DaemonReload()
+}
+
+// ExecPath возвращает исполняемый файл из ExecStart установленного unit
+func ExecPath(reg Registration) (string, error) {
+	unitPath := installedUnitPath(reg)
+	data, err := os.ReadFile(unitPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%w: %s", ErrNotInstalled, reg.Name)
+		}
+		return "", err
+	}
+	execPath := UnitExecPath(string(data))
+	if execPath == "" {
+		return "", fmt.Errorf("no ExecStart in %s", unitPath)
+	}
+	return execPath, nil
 }
 
-// Uninstall удаляет systemd сервис
-func Uninstall(serviceName string) error {
-	return fmt.Errorf("uninstall on Linux: use scripts/uninstall.sh instead")
+// installedUnitPath возвращает путь unit файла сервиса: в reg.UnitDir,
+// если она задана, иначе файл, из которого systemd загрузил unit
+// (bootstrap --unit-dir), или файл в DefaultUnitDir
+func installedUnitPath(reg Registration) string {
+	if reg.UnitDir != "" {
+		return UnitPath(reg.UnitDir, reg.Name)
+	}
+	out, err := exec.Command("systemctl", "show", "--property=FragmentPath", "--value", reg.Name+".service").Output()
+	if path := strings.TrimSpace(string(out)); err == nil && filepath.IsAbs(path) {
+		return path
+	}
+	return UnitPath(DefaultUnitDir, reg.Name)
+}
+
+// DaemonReload перечитывает конфигурацию systemd
+func DaemonReload() error {
+	cmd := exec.Command("systemctl", "daemon-reload")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return systemctlError("reload", err, output)
+	}
+	return nil
 }
@@ -6,8 +6,11 @@ package platform
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"syscall"
 
+	"golang.org/x/sys/windows"
 	"golang.org/x/sys/windows/svc"
 	"golang.org/x/sys/windows/svc/debug"
 	"golang.org/x/sys/windows/svc/mgr"
@@ -27,21 +30,22 @@ type windowsService struct {
 
 // Execute запускается Windows Service Control Manager
 func (s *windowsService) Execute(args []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (ssec bool, errno uint32) {
-	const cmdsAccepted = svc.AcceptStop | svc.AcceptShutdown
-	changes <- svc.Status{State: svc.StartPending}
+	const cmdsAccepted = svc.AcceptStop | svc.AcceptShutdown | svc.AcceptParamChange
+	changes <- svc.Status{State: svc.StartPending, WaitHint: startWaitHint}
 
 	// Создаем контекст для приложения
 	s.ctx, s.cancel = context.WithCancel(context.Background())
 	s.errChan = make(chan error, 1)
 
+	// Ход запуска передается в SCM контрольными точками, Running -
+	// после запуска всех компонентов
+	s.app.SetStartupReporter(&scmReporter{log: s.log, changes: changes, accepts: cmdsAccepted})
+
 	// Запускаем приложение
 	go func() {
 		s.errChan <- s.app.Run(s.ctx)
 	}()
 
-	changes <- svc.Status{State: svc.Running, Accepts: cmdsAccepted}
-	s.log.Info("Windows service started")
-
 	// Основной цикл обработки команд
 	for {
 		select {
@@ -49,6 +53,12 @@ func (s *windowsService) Execute(args []string, r <-chan svc.ChangeRequest, chan
 			switch c.Cmd {
 			case svc.Interrogate:
 				changes <- c.CurrentStatus
+			case svc.ParamChange:
+				// sc control <name> paramchange перезагружает конфигурацию;
+				// результат и ошибка проверки пишутся в лог самим Reload
+				s.log.Info("Received paramchange command, reloading config")
+				s.app.Reload()
+				changes <- c.CurrentStatus
 			case svc.Stop, svc.Shutdown:
 				s.log.Info("Received stop/shutdown command")
 				changes <- svc.Status{State: svc.StopPending}
@@ -61,15 +71,47 @@ func (s *windowsService) Execute(args []string, r <-chan svc.ChangeRequest, chan
 				s.log.Error("Unexpected control request", map[string]interface{}{"cmd": c.Cmd})
 			}
 		case err := <-s.errChan:
+			changes <- svc.Status{State: svc.Stopped}
 			if err != nil {
 				s.log.Error("Application error", map[string]interface{}{"error": err.Error()})
+				// Ненулевой код выхода запускает recovery actions SCM
+				return false, 1
 			}
-			changes <- svc.Status{State: svc.Stopped}
 			return
 		}
 	}
 }
 
+// startWaitHint время до первой контрольной точки запуска (мс)
+const startWaitHint = 30000
+
+// scmReporter передает ход запуска в SCM. Реализует app.StartupReporter.
+// Номер контрольной точки совпадает с номером шага в записи лога
+// "Starting task", поэтому по sc query видно, на какой задаче завис запуск
+type scmReporter struct {
+	log     *logger.Logger
+	changes chan<- svc.Status
+	accepts svc.Accepted
+}
+
+// Starting сообщает SCM контрольную точку шага и время его ожидания
+func (r *scmReporter) Starting(p app.StartupProgress) {
+	r.changes <- svc.Status{
+		State:      svc.StartPending,
+		CheckPoint: uint32(p.Step),
+		WaitHint:   uint32(p.WaitHint.Milliseconds()),
+	}
+}
+
+// Started переводит сервис в состояние Running
+func (r *scmReporter) Started() {
+	r.changes <- svc.Status{State: svc.Running, Accepts: r.accepts}
+	r.log.Info("Windows service started")
+}
+
+// Stopping ничего не делает: StopPending сообщает обработчик команды остановки
+func (r *scmReporter) Stopping() {}
+
 // Run запускает сервис как обычное приложение (для тестирования)
 func Run(log *logger.Logger, application *app.App) error {
 	isService, err := svc.IsWindowsService()
@@ -83,7 +125,7 @@ func Run(log *logger.Logger, application *app.App) error {
 			log: log,
 			app: application,
 		}
-		return svc.Run("service-boilerplate", s)
+		return svc.Run(application.Identity().Service, s)
 	}
 
 	// Запускаем как обычное приложение
@@ -93,44 +135,121 @@ func Run(log *logger.Logger, application *app.App) error {
 	return application.Run(ctx)
 }
 
-// Install устанавливает сервис в Windows
-func Install(serviceName, displayName, description string, execPath string) error {
+// Install устанавливает сервис в Windows. reg.Args передаются бинарнику при запуске SCM
+func Install(reg Registration) error {
 	m, err := mgr.Connect()
 	if err != nil {
 		return fmt.Errorf("failed to connect to service manager: %w", err)
 	}
 	defer m.Disconnect()
 
-	s, err := m.OpenService(serviceName)
+	s, err := m.OpenService(reg.Name)
 	if err == nil {
 		s.Close()
-		return fmt.Errorf("service %s already exists", serviceName)
+		return fmt.Errorf("%w: %s", ErrAlreadyInstalled, reg.Name)
 	}
 
-	s, err = m.CreateService(serviceName, execPath, mgr.Config{
-		DisplayName: displayName,
-		Description: description,
-		StartType:   mgr.StartAutomatic,
-	})
+	c := mgr.Config{
+		DisplayName: reg.DisplayName,
+		Description: reg.Description,
+	}
+	applyStartType(&c, reg.StartType)
+	s, err = m.CreateService(reg.Name, reg.ExecPath, c, reg.Args...)
 	if err != nil {
 		return fmt.Errorf("failed to create service: %w", err)
 	}
 	defer s.Close()
 
+	return setRecovery(s, reg)
+}
+
+// Reconfigure обновляет регистрацию установленного сервиса в SCM:
+// отображаемое имя, описание, тип запуска, командную строку и recovery
+// actions. Запущенный сервис не перезапускается, новая командная строка
+// применяется при следующем запуске
+func Reconfigure(reg Registration) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(reg.Name)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrNotInstalled, reg.Name)
+	}
+	defer s.Close()
+
+	c, err := s.Config()
+	if err != nil {
+		return fmt.Errorf("failed to query service config: %w", err)
+	}
+	c.DisplayName = reg.DisplayName
+	c.Description = reg.Description
+	c.BinaryPathName = binaryPathName(reg.ExecPath, reg.Args)
+	applyStartType(&c, reg.StartType)
+	if err := s.UpdateConfig(c); err != nil {
+		return fmt.Errorf("failed to update service config: %w", err)
+	}
+	return setRecovery(s, reg)
+}
+
+// applyStartType задает тип запуска SCM
+func applyStartType(c *mgr.Config, startType string) {
+	c.DelayedAutoStart = false
+	switch startType {
+	case StartManual:
+		c.StartType = mgr.StartManual
+	case StartDisabled:
+		c.StartType = mgr.StartDisabled
+	case StartDelayed:
+		c.StartType = mgr.StartAutomatic
+		c.DelayedAutoStart = true
+	default:
+		c.StartType = mgr.StartAutomatic
+	}
+}
+
+// setRecovery настраивает recovery actions: перезапуск после каждого сбоя,
+// в том числе завершения с ненулевым кодом выхода
+func setRecovery(s *mgr.Service, reg Registration) error {
+	if reg.Restart == RestartNever {
+		if err := s.ResetRecoveryActions(); err != nil {
+			return fmt.Errorf("failed to reset recovery actions: %w", err)
+		}
+		return nil
+	}
+	restart := mgr.RecoveryAction{Type: mgr.ServiceRestart, Delay: reg.RestartDelay}
+	if err := s.SetRecoveryActions([]mgr.RecoveryAction{restart, restart, restart}, uint32(reg.ResetPeriod.Seconds())); err != nil {
+		return fmt.Errorf("failed to set recovery actions: %w", err)
+	}
+	if err := s.SetRecoveryActionsOnNonCrashFailures(true); err != nil {
+		return fmt.Errorf("failed to set recovery actions: %w", err)
+	}
 	return nil
 }
 
+// binaryPathName формирует командную строку сервиса так же, как
+// mgr.CreateService
+func binaryPathName(execPath string, args []string) string {
+	s := syscall.EscapeArg(execPath)
+	for _, a := range args {
+		s += " " + syscall.EscapeArg(a)
+	}
+	return s
+}
+
 // Uninstall удаляет сервис из Windows
-func Uninstall(serviceName string) error {
+func Uninstall(reg Registration) error {
 	m, err := mgr.Connect()
 	if err != nil {
 		return fmt.Errorf("failed to connect to service manager: %w", err)
 	}
 	defer m.Disconnect()
 
-	s, err := m.OpenService(serviceName)
+	s, err := m.OpenService(reg.Name)
 	if err != nil {
-		return fmt.Errorf("service %s does not exist", serviceName)
+		return fmt.Errorf("%w: %s", ErrNotInstalled, reg.Name)
 	}
 	defer s.Close()
 
@@ -143,6 +262,34 @@ func Uninstall(serviceName string) error {
 	return s.Delete()
 }
 
+// ExecPath возвращает исполняемый файл из командной строки установленного сервиса
+func ExecPath(reg Registration) (string, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(reg.Name)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrNotInstalled, reg.Name)
+	}
+	defer s.Close()
+
+	c, err := s.Config()
+	if err != nil {
+		return "", fmt.Errorf("failed to query service config: %w", err)
+	}
+	args, err := windows.DecomposeCommandLine(c.BinaryPathName)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse service command line: %w", err)
+	}
+	if len(args) == 0 {
+		return "", fmt.Errorf("empty service command line")
+	}
+	return args[0], nil
+}
+
 // Start запускает установленный сервис
 func Start(serviceName string) error {
 	m, err := mgr.Connect()
@@ -153,11 +300,17 @@ func Start(serviceName string) error {
 
 	s, err := m.OpenService(serviceName)
 	if err != nil {
-		return fmt.Errorf("service %s does not exist", serviceName)
+		return fmt.Errorf("%w: %s", ErrNotInstalled, serviceName)
 	}
 	defer s.Close()
 
-	return s.Start()
+	if err := s.Start(); err != nil {
+		if errors.Is(err, windows.ERROR_SERVICE_ALREADY_RUNNING) {
+			return fmt.Errorf("%w: %s", ErrAlreadyRunning, serviceName)
+		}
+		return err
+	}
+	return nil
 }
 
 // Stop останавливает запущенный сервис
@@ -170,7 +323,7 @@ func Stop(serviceName string) error {
 
 	s, err := m.OpenService(serviceName)
 	if err != nil {
-		return fmt.Errorf("service %s does not exist", serviceName)
+		return fmt.Errorf("%w: %s", ErrNotInstalled, serviceName)
 	}
 	defer s.Close()
 
@@ -184,7 +337,7 @@ func RunAsService(log *logger.Logger, application *app.App) error {
 		log: log,
 		app: application,
 	}
-	return svc.Run("service-boilerplate", s)
+	return svc.Run(application.Identity().Service, s)
 }
 
 // Log сообщения для debug
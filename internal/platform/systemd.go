@@ -0,0 +1,89 @@
+package platform
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultUnitDir директория systemd unit файлов по умолчанию
+const DefaultUnitDir = "/etc/systemd/system"
+
+// UnitFile формирует systemd unit для регистрации сервиса
+func UnitFile(reg Registration) string {
+	execStart := make([]string, 0, len(reg.Args)+1)
+	for _, a := range append([]string{reg.ExecPath}, reg.Args...) {
+		execStart = append(execStart, unitQuote(a))
+	}
+
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=%s\n", reg.Description)
+	b.WriteString("After=network.target\n\n")
+	b.WriteString("[Service]\n")
+	b.WriteString("Type=notify\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(execStart, " "))
+	b.WriteString("ExecReload=/bin/kill -HUP $MAINPID\n")
+	fmt.Fprintf(&b, "WorkingDirectory=%s\n", filepath.Dir(reg.ExecPath))
+	fmt.Fprintf(&b, "Restart=%s\n", unitRestart(reg.Restart))
+	fmt.Fprintf(&b, "RestartSec=%d\n", int(reg.RestartDelay.Seconds()))
+	b.WriteString("StandardOutput=journal\n")
+	b.WriteString("StandardError=journal\n")
+	fmt.Fprintf(&b, "SyslogIdentifier=%s\n\n", reg.Name)
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=multi-user.target\n")
+	return b.String()
+}
+
+// unitRestart преобразует политику перезапуска в значение Restart= unit
+func unitRestart(restart string) string {
+	switch restart {
+	case RestartOnFailure:
+		return "on-failure"
+	case RestartNever:
+		return "no"
+	default:
+		return "always"
+	}
+}
+
+// unitQuote экранирует аргумент командной строки для ExecStart
+func unitQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// UnitExecPath возвращает исполняемый файл из строки ExecStart unit
+// или пустую строку, если ее нет. Префиксы ExecStart (-, @, +, !, :)
+// отбрасываются, кавычки снимаются так же, как их ставит unitQuote
+func UnitExecPath(unit string) string {
+	for _, line := range strings.Split(unit, "\n") {
+		value, ok := strings.CutPrefix(strings.TrimSpace(line), "ExecStart=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimLeft(strings.TrimSpace(value), "-@+!:")
+		if !strings.HasPrefix(value, `"`) {
+			if fields := strings.Fields(value); len(fields) > 0 {
+				return fields[0]
+			}
+			return ""
+		}
+		var b strings.Builder
+		for i := 1; i < len(value) && value[i] != '"'; i++ {
+			if value[i] == '\\' && i+1 < len(value) {
+				i++
+			}
+			b.WriteByte(value[i])
+		}
+		return b.String()
+	}
+	return ""
+}
+
+// UnitPath возвращает путь к unit файлу сервиса в директории unitDir
+func UnitPath(unitDir, serviceName string) string {
+	return filepath.Join(unitDir, serviceName+".service")
+}
@@ -0,0 +1,447 @@
+// Package procman запускает и контролирует дочерние процессы (например,
+// унаследованные программы, которые сервис оборачивает): вывод процессов
+// пишется в структурированный лог, упавшие процессы перезапускаются
+// с нарастающей задержкой, при остановке сервиса процессы завершаются штатно
+package procman
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+)
+
+// RestartPolicy политика перезапуска процесса
+type RestartPolicy string
+
+// Политики перезапуска
+const (
+	// RestartAlways перезапускать после любого завершения
+	RestartAlways RestartPolicy = "always"
+	// RestartOnFailure перезапускать только после ненулевого кода выхода
+	RestartOnFailure RestartPolicy = "on-failure"
+	// RestartNever не перезапускать
+	RestartNever RestartPolicy = "never"
+)
+
+// Состояния процесса для Status
+const (
+	StateStarting = "starting"
+	StateRunning  = "running"
+	StateBackoff  = "backoff"
+	StateExited   = "exited"
+	StateStopped  = "stopped"
+)
+
+// stableRunTime время работы, после которого задержка перезапуска сбрасывается
+const stableRunTime = time.Minute
+
+// maxLineLength ограничивает длину строки вывода в одной записи лога
+const maxLineLength = 16 * 1024
+
+// Process описывает дочерний процесс
+type Process struct {
+	Name    string
+	Command string
+	Args    []string
+	// Env дополнительные переменные окружения KEY=VALUE поверх окружения сервиса
+	Env []string
+	// Dir рабочая директория, пустая - текущая директория сервиса
+	Dir     string
+	Restart RestartPolicy
+	// RestartDelay задержка перед первым перезапуском, удваивается до MaxRestartDelay
+	RestartDelay    time.Duration
+	MaxRestartDelay time.Duration
+	// StopTimeout время на штатное завершение до принудительного
+	StopTimeout time.Duration
+	// Limits ограничения процесса и его потомков
+	Limits Limits
+}
+
+// Limits ограничения процессорного времени и памяти процесса вместе
+// с потомками. Применяются на Windows через Job Object; на Linux
+// не поддерживаются (используются cgroups: MemoryMax=, CPUQuota= unit)
+type Limits struct {
+	// CPUPercent доля процессорного времени всех ядер (1-100), 0 - без ограничения
+	CPUPercent int
+	// MemoryMB предел выделенной памяти в МБ, 0 - без ограничения
+	MemoryMB int
+}
+
+// IsZero сообщает, что ограничения не заданы
+func (l Limits) IsZero() bool {
+	return l.CPUPercent <= 0 && l.MemoryMB <= 0
+}
+
+// Status состояние процесса
+type Status struct {
+	Name     string    `json:"name"`
+	State    string    `json:"state"`
+	PID      int       `json:"pid,omitempty"`
+	Restarts int       `json:"restarts"`
+	Started  time.Time `json:"started,omitempty"`
+	LastExit string    `json:"last_exit,omitempty"`
+}
+
+// Manager контролирует дочерние процессы. Реализует task.Task и task.Checker
+type Manager struct {
+	log     *logger.Logger
+	metrics *metrics.Server
+	procs   []*supervisor
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// supervisor состояние одного процесса
+type supervisor struct {
+	Process
+
+	mu     sync.Mutex
+	status Status
+}
+
+// New создает менеджер для списка процессов
+func New(log *logger.Logger, metricsServer *metrics.Server, procs []Process) *Manager {
+	m := &Manager{log: log, metrics: metricsServer}
+	for _, p := range procs {
+		if p.Restart == "" {
+			p.Restart = RestartAlways
+		}
+		if p.RestartDelay <= 0 {
+			p.RestartDelay = time.Second
+		}
+		if p.MaxRestartDelay < p.RestartDelay {
+			p.MaxRestartDelay = p.RestartDelay
+		}
+		if p.StopTimeout <= 0 {
+			p.StopTimeout = 10 * time.Second
+		}
+		m.procs = append(m.procs, &supervisor{
+			Process: p,
+			status:  Status{Name: p.Name, State: StateStopped},
+		})
+	}
+	return m
+}
+
+// Name возвращает имя задачи
+func (m *Manager) Name() string {
+	return "procman"
+}
+
+// Check проверяет, что исполняемые файлы процессов найдены
+func (m *Manager) Check(ctx context.Context) error {
+	var errs []error
+	for _, p := range m.procs {
+		if _, err := exec.LookPath(p.Command); err != nil {
+			errs = append(errs, fmt.Errorf("process %s: %w", p.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AfterStart запускает процессы
+func (m *Manager) AfterStart(ctx context.Context) error {
+	if len(m.procs) == 0 {
+		return nil
+	}
+	if err := m.Check(ctx); err != nil {
+		return err
+	}
+	if !limitsSupported {
+		for _, p := range m.procs {
+			if !p.Limits.IsZero() {
+				m.log.Warn("Process limits are not supported on this platform", map[string]interface{}{"process": p.Name})
+			}
+		}
+	}
+
+	// Процессы живут до BeforeStop, а не до отмены контекста запуска,
+	// иначе дочерние процессы получили бы сигнал в самом начале остановки
+	ctx, m.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	for _, p := range m.procs {
+		m.wg.Add(1)
+		go m.supervise(ctx, p)
+	}
+	return nil
+}
+
+// BeforeStop штатно завершает процессы и дожидается их выхода
+func (m *Manager) BeforeStop(ctx context.Context) error {
+	if m.cancel == nil {
+		return nil
+	}
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Status возвращает состояние процессов, отсортированное по имени
+func (m *Manager) Status() []Status {
+	result := make([]Status, 0, len(m.procs))
+	for _, p := range m.procs {
+		p.mu.Lock()
+		result = append(result, p.status)
+		p.mu.Unlock()
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// supervise запускает процесс и перезапускает его согласно политике
+func (m *Manager) supervise(ctx context.Context, p *supervisor) {
+	defer m.wg.Done()
+	defer p.update(func(s *Status) {
+		if s.State != StateExited {
+			s.State = StateStopped
+		}
+	})
+
+	delay := p.RestartDelay
+	for {
+		started := time.Now()
+		err := m.run(ctx, p)
+		if ctx.Err() != nil {
+			return
+		}
+
+		exitCode := 0
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		} else if err != nil {
+			exitCode = -1
+		}
+		fields := map[string]interface{}{
+			"process":   p.Name,
+			"exit_code": exitCode,
+			"uptime":    time.Since(started).Round(time.Millisecond).String(),
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+		}
+
+		if p.Restart == RestartNever || (p.Restart == RestartOnFailure && err == nil) {
+			m.log.Info("Process exited", fields)
+			p.update(func(s *Status) { s.State = StateExited })
+			return
+		}
+
+		if time.Since(started) >= stableRunTime {
+			delay = p.RestartDelay
+		}
+		fields["restart_in"] = delay.String()
+		m.log.Warn("Process exited, restarting", fields)
+		p.update(func(s *Status) { s.State, s.Restarts = StateBackoff, s.Restarts+1 })
+		if m.metrics != nil {
+			m.metrics.RecordProcessRestart(p.Name)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if delay *= 2; delay > p.MaxRestartDelay {
+			delay = p.MaxRestartDelay
+		}
+	}
+}
+
+// run запускает процесс и ждет его завершения. При отмене ctx процесс
+// получает сигнал штатного завершения, а через StopTimeout завершается принудительно
+func (m *Manager) run(ctx context.Context, p *supervisor) error {
+	cmd := exec.Command(p.Command, p.Args...)
+	cmd.Dir = p.Dir
+	cmd.Env = append(os.Environ(), p.Env...)
+	stdout := &lineWriter{log: m.log, process: p.Name, stream: "stdout"}
+	stderr := &lineWriter{log: m.log, process: p.Name, stream: "stderr"}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Вывод потомков, унаследовавших дескрипторы, не задерживает Wait
+	cmd.WaitDelay = p.StopTimeout
+	setProcAttr(cmd)
+
+	p.update(func(s *Status) { s.State = StateStarting })
+	if err := cmd.Start(); err != nil {
+		p.update(func(s *Status) { s.LastExit = err.Error() })
+		return err
+	}
+	// Без задания ограничения не действуют, поэтому с Limits процесс
+	// не продолжает работу. Без них теряется только завершение потомков
+	release, containErr := contain(cmd, p.Limits)
+	if containErr != nil && !p.Limits.IsZero() {
+		return m.abort(p, cmd, fmt.Errorf("contain process: %w", containErr))
+	}
+	if containErr != nil {
+		m.log.Warn("Failed to contain process, descendants are not tracked", map[string]interface{}{
+			"process": p.Name,
+			"error":   containErr.Error(),
+		})
+	} else {
+		defer release()
+	}
+	if err := resume(cmd); err != nil {
+		return m.abort(p, cmd, fmt.Errorf("resume process: %w", err))
+	}
+	startedAt := time.Now()
+	p.update(func(s *Status) { s.State, s.PID, s.Started = StateRunning, cmd.Process.Pid, startedAt })
+	m.log.Info("Process started", map[string]interface{}{
+		"process": p.Name,
+		"pid":     cmd.Process.Pid,
+		"command": p.Command,
+	})
+	if m.metrics != nil {
+		m.metrics.SetProcessUp(p.Name, true)
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	var err error
+	select {
+	case err = <-exited:
+	case <-ctx.Done():
+		err = m.stop(p, cmd, exited)
+	}
+	stdout.Flush()
+	stderr.Flush()
+
+	if m.metrics != nil {
+		m.metrics.SetProcessUp(p.Name, false)
+	}
+	p.update(func(s *Status) {
+		s.PID = 0
+		if err != nil {
+			s.LastExit = err.Error()
+		} else {
+			s.LastExit = "exit status 0"
+		}
+	})
+	return err
+}
+
+// abort завершает процесс, который не удалось подготовить после запуска
+func (m *Manager) abort(p *supervisor, cmd *exec.Cmd, err error) error {
+	kill(cmd)
+	cmd.Wait()
+	p.update(func(s *Status) { s.LastExit = err.Error() })
+	return err
+}
+
+// stop штатно завершает процесс, а по истечении StopTimeout - принудительно
+func (m *Manager) stop(p *supervisor, cmd *exec.Cmd, exited <-chan error) error {
+	m.log.Info("Stopping process", map[string]interface{}{
+		"process": p.Name,
+		"pid":     cmd.Process.Pid,
+	})
+	if err := terminate(cmd); err != nil {
+		m.log.Warn("Failed to signal process, killing", map[string]interface{}{
+			"process": p.Name,
+			"error":   err.Error(),
+		})
+		kill(cmd)
+		return <-exited
+	}
+
+	timer := time.NewTimer(p.StopTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-exited:
+		return err
+	case <-timer.C:
+		m.log.Warn("Process did not stop in time, killing", map[string]interface{}{
+			"process":      p.Name,
+			"stop_timeout": p.StopTimeout.String(),
+		})
+		kill(cmd)
+		return <-exited
+	}
+}
+
+// update изменяет состояние процесса
+func (p *supervisor) update(fn func(s *Status)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fn(&p.status)
+}
+
+// lineWriter пишет вывод процесса в лог построчно
+type lineWriter struct {
+	log     *logger.Logger
+	process string
+	stream  string
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// Write буферизует вывод и логирует завершенные строки
+func (w *lineWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, b...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) >= maxLineLength {
+		w.emit(w.buf)
+		w.buf = nil
+	}
+	return len(b), nil
+}
+
+// Flush логирует остаток вывода без перевода строки
+func (w *lineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.emit(w.buf)
+		w.buf = nil
+	}
+}
+
+// emit логирует одну строку; stderr пишется с уровнем warn
+func (w *lineWriter) emit(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) == 0 {
+		return
+	}
+	fields := map[string]interface{}{
+		"process": w.process,
+		"stream":  w.stream,
+		"line":    string(line),
+	}
+	if w.stream == "stderr" {
+		w.log.Warn("Process output", fields)
+	} else {
+		w.log.Info("Process output", fields)
+	}
+}
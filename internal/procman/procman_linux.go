@@ -0,0 +1,37 @@
+package procman
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcAttr запускает процесс в собственной группе, чтобы сигнал
+// остановки получили и его потомки
+func setProcAttr(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// limitsSupported Limits на Linux не применяются
+const limitsSupported = false
+
+// contain ничего не делает: потомков процесса завершает сигнал его группе
+func contain(cmd *exec.Cmd, limits Limits) (release func(), err error) {
+	return func() {}, nil
+}
+
+// resume ничего не делает: процесс запускается сразу
+func resume(cmd *exec.Cmd) error {
+	return nil
+}
+
+// terminate отправляет группе процесса SIGTERM
+func terminate(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+}
+
+// kill завершает группу процесса принудительно
+func kill(cmd *exec.Cmd) {
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+		cmd.Process.Kill()
+	}
+}
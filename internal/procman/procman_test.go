@@ -0,0 +1,173 @@
+package procman
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/logger"
+)
+
+// TestHelperProcess не является тестом: это дочерний процесс для остальных тестов
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	switch os.Getenv("HELPER_MODE") {
+	case "output":
+		fmt.Println("hello from", os.Getenv("HELPER_NAME"))
+		fmt.Fprint(os.Stderr, "warning without newline")
+		os.Exit(0)
+	case "fail":
+		os.Exit(3)
+	case "sleep":
+		time.Sleep(time.Minute)
+	}
+	os.Exit(0)
+}
+
+// helper описывает процесс, запускающий TestHelperProcess в режиме mode
+func helper(name, mode string, restart RestartPolicy) Process {
+	return Process{
+		Name:         name,
+		Command:      os.Args[0],
+		Args:         []string{"-test.run=TestHelperProcess"},
+		Env:          []string{"GO_WANT_HELPER_PROCESS=1", "HELPER_MODE=" + mode, "HELPER_NAME=" + name},
+		Restart:      restart,
+		RestartDelay: 10 * time.Millisecond,
+		StopTimeout:  time.Second,
+	}
+}
+
+// waitState ждет, пока процесс name перейдет в состояние state
+func waitState(t *testing.T, m *Manager, name, state string) Status {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, s := range m.Status() {
+			if s.Name == name && s.State == state {
+				return s
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("process %s did not reach state %s: %+v", name, state, m.Status())
+	return Status{}
+}
+
+// TestOutputAndRestart проверяет запись вывода в лог и политики перезапуска
+func TestOutputAndRestart(t *testing.T) {
+	dir := t.TempDir()
+	log, err := logger.New("test-procman", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	m := New(log, nil, []Process{
+		helper("once", "output", RestartOnFailure),
+		helper("crashy", "fail", RestartOnFailure),
+	})
+	if err := m.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+
+	once := waitState(t, m, "once", StateExited)
+	if once.Restarts != 0 || once.LastExit != "exit status 0" {
+		t.Errorf("once status = %+v", once)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for m.Status()[0].Restarts < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if crashy := m.Status()[0]; crashy.Name != "crashy" || crashy.Restarts < 2 || crashy.LastExit != "exit status 3" {
+		t.Errorf("crashy status = %+v, want >= 2 restarts", crashy)
+	}
+
+	if err := m.BeforeStop(context.Background()); err != nil {
+		t.Fatalf("BeforeStop() error = %v", err)
+	}
+	log.Flush()
+	content, err := os.ReadFile(logger.FilePath(dir, "test-procman"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"line":"hello from once"`, `"line":"warning without newline"`, `"stream":"stderr"`} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("log does not contain %s", want)
+		}
+	}
+}
+
+// TestGracefulStop проверяет остановку работающего процесса в BeforeStop
+func TestGracefulStop(t *testing.T) {
+	log, err := logger.New("test-procman", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	m := New(log, nil, []Process{helper("sleeper", "sleep", RestartAlways)})
+	if err := m.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+	if s := waitState(t, m, "sleeper", StateRunning); s.PID == 0 {
+		t.Errorf("running process without PID: %+v", s)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := m.BeforeStop(ctx); err != nil {
+		t.Fatalf("BeforeStop() error = %v", err)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Errorf("BeforeStop() took %v", time.Since(start))
+	}
+	if s := m.Status()[0]; s.State != StateStopped || s.Restarts != 0 {
+		t.Errorf("status after stop = %+v", s)
+	}
+}
+
+// TestStartContextCanceled проверяет, что отмена контекста запуска
+// не останавливает процессы до BeforeStop
+func TestStartContextCanceled(t *testing.T) {
+	log, err := logger.New("test-procman", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	m := New(log, nil, []Process{helper("sleeper", "sleep", RestartNever)})
+	startCtx, cancelStart := context.WithCancel(context.Background())
+	if err := m.AfterStart(startCtx); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+	running := waitState(t, m, "sleeper", StateRunning)
+	cancelStart()
+	time.Sleep(300 * time.Millisecond)
+	if s := m.Status()[0]; s.State != StateRunning || s.PID != running.PID {
+		t.Errorf("status after start context canceled = %+v, want running PID %d", s, running.PID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.BeforeStop(ctx); err != nil {
+		t.Fatalf("BeforeStop() error = %v", err)
+	}
+	if s := m.Status()[0]; s.State != StateStopped {
+		t.Errorf("status after stop = %+v", s)
+	}
+}
+
+// TestCheck проверяет обнаружение отсутствующего исполняемого файла
+func TestCheck(t *testing.T) {
+	m := New(nil, nil, []Process{{Name: "missing", Command: "definitely-not-a-command-xyz"}})
+	err := m.Check(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "process missing") {
+		t.Errorf("Check() error = %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+//go:build windows
+// +build windows
+
+package procman
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// limitsSupported Limits применяются через Job Object
+const limitsSupported = true
+
+// Флаги JOBOBJECT_CPU_RATE_CONTROL_INFORMATION
+const (
+	jobCPURateControlEnable  = 0x1
+	jobCPURateControlHardCap = 0x4
+)
+
+// jobCPURateInfo JOBOBJECT_CPU_RATE_CONTROL_INFORMATION (нет в x/sys/windows)
+type jobCPURateInfo struct {
+	ControlFlags uint32
+	// CPURate доля процессорного времени в сотых долях процента
+	CPURate uint32
+}
+
+// setProcAttr запускает процесс в новой группе, чтобы CTRL_BREAK
+// не получил сам сервис. Процесс создается приостановленным: до resume
+// он не выполняется и не может запустить потомков вне задания (contain)
+func setProcAttr(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.CREATE_SUSPENDED,
+	}
+}
+
+// contain помещает запущенный процесс в Job Object с ограничениями limits.
+// Потомки процесса попадают в то же задание. С JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE
+// задание завершает все свои процессы, когда закрывается его дескриптор:
+// release после выхода процесса или система при падении сервиса
+func contain(cmd *exec.Cmd, limits Limits) (release func(), err error) {
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create job object: %w", err)
+	}
+	release = func() { windows.CloseHandle(job) }
+
+	var info windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION
+	info.BasicLimitInformation.LimitFlags = windows.JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE
+	if limits.MemoryMB > 0 {
+		info.BasicLimitInformation.LimitFlags |= windows.JOB_OBJECT_LIMIT_JOB_MEMORY
+		info.JobMemoryLimit = uintptr(limits.MemoryMB) << 20
+	}
+	if _, err := windows.SetInformationJobObject(job, windows.JobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+		release()
+		return nil, fmt.Errorf("set job limits: %w", err)
+	}
+	if limits.CPUPercent > 0 {
+		cpu := jobCPURateInfo{
+			ControlFlags: jobCPURateControlEnable | jobCPURateControlHardCap,
+			CPURate:      uint32(limits.CPUPercent) * 100,
+		}
+		if _, err := windows.SetInformationJobObject(job, windows.JobObjectCpuRateControlInformation,
+			uintptr(unsafe.Pointer(&cpu)), uint32(unsafe.Sizeof(cpu))); err != nil {
+			release()
+			return nil, fmt.Errorf("set job CPU rate: %w", err)
+		}
+	}
+
+	proc, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(cmd.Process.Pid))
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("open process: %w", err)
+	}
+	defer windows.CloseHandle(proc)
+	if err := windows.AssignProcessToJobObject(job, proc); err != nil {
+		release()
+		return nil, fmt.Errorf("assign process to job object: %w", err)
+	}
+	return release, nil
+}
+
+// resume возобновляет основной поток процесса, созданного приостановленным.
+// exec.Cmd закрывает дескриптор потока после запуска, поэтому поток
+// находится по снимку потоков системы
+func resume(cmd *exec.Cmd) error {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPTHREAD, 0)
+	if err != nil {
+		return fmt.Errorf("snapshot threads: %w", err)
+	}
+	defer windows.CloseHandle(snapshot)
+
+	pid := uint32(cmd.Process.Pid)
+	entry := windows.ThreadEntry32{Size: uint32(unsafe.Sizeof(windows.ThreadEntry32{}))}
+	resumed := false
+	for err = windows.Thread32First(snapshot, &entry); err == nil; err = windows.Thread32Next(snapshot, &entry) {
+		if entry.OwnerProcessID != pid {
+			continue
+		}
+		thread, err := windows.OpenThread(windows.THREAD_SUSPEND_RESUME, false, entry.ThreadID)
+		if err != nil {
+			return fmt.Errorf("open thread %d: %w", entry.ThreadID, err)
+		}
+		_, err = windows.ResumeThread(thread)
+		windows.CloseHandle(thread)
+		if err != nil {
+			return fmt.Errorf("resume thread %d: %w", entry.ThreadID, err)
+		}
+		resumed = true
+	}
+	if !resumed {
+		return fmt.Errorf("no threads found for process %d", pid)
+	}
+	return nil
+}
+
+// terminate отправляет группе процесса CTRL_BREAK. У службы без консоли
+// вызов завершается ошибкой, и процесс завершается принудительно
+func terminate(cmd *exec.Cmd) error {
+	return windows.GenerateConsoleCtrlEvent(windows.CTRL_BREAK_EVENT, uint32(cmd.Process.Pid))
+}
+
+// kill завершает процесс принудительно. Его потомков завершает закрытие
+// задания (contain)
+func kill(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
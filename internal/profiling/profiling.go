@@ -0,0 +1,297 @@
+// Package profiling периодически снимает pprof профили работающего сервиса
+// (CPU, heap и другие), сохраняет их на диск с ротацией и при необходимости
+// отправляет в Pyroscope, чтобы регрессии долгоживущего сервиса можно было
+// разобрать после того, как они произошли
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+)
+
+// Профили, которые можно снимать
+const (
+	ProfileCPU       = "cpu"
+	ProfileHeap      = "heap"
+	ProfileAllocs    = "allocs"
+	ProfileGoroutine = "goroutine"
+	ProfileMutex     = "mutex"
+	ProfileBlock     = "block"
+)
+
+// Profiles список поддерживаемых профилей
+var Profiles = []string{ProfileCPU, ProfileHeap, ProfileAllocs, ProfileGoroutine, ProfileMutex, ProfileBlock}
+
+// fileExt расширение файлов профилей (pprof protobuf в gzip)
+const fileExt = ".pb.gz"
+
+// Config содержит настройки профилирования
+type Config struct {
+	// Dir директория для файлов профилей
+	Dir string
+	// Interval период между снятиями профилей
+	Interval time.Duration
+	// CPUDuration длительность снятия CPU профиля
+	CPUDuration time.Duration
+	// Profiles какие профили снимать
+	Profiles []string
+	// Retention сколько последних файлов каждого профиля хранить
+	Retention int
+	// PyroscopeURL адрес сервера Pyroscope (пустой - не отправлять)
+	PyroscopeURL string
+	// AppName имя приложения в Pyroscope
+	AppName string
+	// Client HTTP клиент для отправки в Pyroscope
+	Client *http.Client
+}
+
+// Profiler снимает профили по расписанию. Реализует task.Task
+type Profiler struct {
+	log     *logger.Logger
+	metrics *metrics.Server
+	cfg     Config
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New создает профилировщик
+func New(log *logger.Logger, metricsServer *metrics.Server, cfg Config) *Profiler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Minute
+	}
+	if cfg.CPUDuration <= 0 {
+		cfg.CPUDuration = 30 * time.Second
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = 48
+	}
+	if len(cfg.Profiles) == 0 {
+		cfg.Profiles = []string{ProfileCPU, ProfileHeap}
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Profiler{log: log, metrics: metricsServer, cfg: cfg}
+}
+
+// Name возвращает имя задачи
+func (p *Profiler) Name() string {
+	return "profiling"
+}
+
+// AfterStart создает директорию профилей и запускает периодическое снятие
+func (p *Profiler) AfterStart(ctx context.Context) error {
+	if err := os.MkdirAll(p.cfg.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create profiles directory: %w", err)
+	}
+	if p.enabled(ProfileMutex) {
+		runtime.SetMutexProfileFraction(5)
+	}
+	if p.enabled(ProfileBlock) {
+		runtime.SetBlockProfileRate(int(10 * time.Microsecond))
+	}
+
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.wg.Add(1)
+	go p.loop(ctx)
+
+	p.log.Info("Profiling started", map[string]interface{}{
+		"dir":       p.cfg.Dir,
+		"interval":  p.cfg.Interval.String(),
+		"profiles":  strings.Join(p.cfg.Profiles, ","),
+		"pyroscope": p.cfg.PyroscopeURL != "",
+	})
+	return nil
+}
+
+// BeforeStop останавливает снятие профилей, прерывая текущий CPU профиль
+func (p *Profiler) BeforeStop(ctx context.Context) error {
+	if p.cancel == nil {
+		return nil
+	}
+	p.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if p.enabled(ProfileMutex) {
+		runtime.SetMutexProfileFraction(0)
+	}
+	if p.enabled(ProfileBlock) {
+		runtime.SetBlockProfileRate(0)
+	}
+	return nil
+}
+
+// loop снимает профили с интервалом Interval
+func (p *Profiler) loop(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.Collect(ctx)
+		}
+	}
+}
+
+// Collect снимает все настроенные профили один раз
+func (p *Profiler) Collect(ctx context.Context) {
+	for _, name := range p.cfg.Profiles {
+		if ctx.Err() != nil {
+			return
+		}
+		from := time.Now()
+		data, err := p.profile(ctx, name)
+		if err == nil {
+			err = p.save(name, from, data)
+		}
+		if err == nil && p.cfg.PyroscopeURL != "" {
+			err = p.upload(ctx, name, from, time.Now(), data)
+		}
+
+		if p.metrics != nil {
+			p.metrics.RecordProfile(name, err != nil)
+		}
+		if err != nil {
+			p.log.Warn("Failed to collect profile", map[string]interface{}{
+				"profile": name,
+				"error":   err.Error(),
+			})
+			continue
+		}
+		p.log.Debug("Profile collected", map[string]interface{}{
+			"profile": name,
+			"bytes":   len(data),
+		})
+	}
+}
+
+// profile снимает профиль name в формате pprof
+func (p *Profiler) profile(ctx context.Context, name string) ([]byte, error) {
+	var buf bytes.Buffer
+	if name != ProfileCPU {
+		prof := pprof.Lookup(name)
+		if prof == nil {
+			return nil, fmt.Errorf("unknown profile %s", name)
+		}
+		if err := prof.WriteTo(&buf, 0); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	// CPU профиль может быть уже запущен, например через net/http/pprof
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(p.cfg.CPUDuration)
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		timer.Stop()
+	}
+	pprof.StopCPUProfile()
+	return buf.Bytes(), nil
+}
+
+// save записывает профиль в файл и удаляет старые файлы сверх Retention
+func (p *Profiler) save(name string, at time.Time, data []byte) error {
+	path := filepath.Join(p.cfg.Dir, name+"-"+at.Format("20060102-150405")+fileExt)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+
+	files, err := filepath.Glob(filepath.Join(p.cfg.Dir, name+"-*"+fileExt))
+	if err != nil {
+		return err
+	}
+	// Имена содержат время, поэтому сортировка по имени - по времени
+	sort.Strings(files)
+	for len(files) > p.cfg.Retention {
+		if err := os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// upload отправляет профиль в Pyroscope через HTTP API /ingest
+func (p *Profiler) upload(ctx context.Context, name string, from, until time.Time, data []byte) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("profile", name+".pprof")
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(data); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("name", p.cfg.AppName+"."+name)
+	query.Set("from", fmt.Sprint(from.Unix()))
+	query.Set("until", fmt.Sprint(until.Unix()))
+	query.Set("format", "pprof")
+	query.Set("spyName", "gospy")
+	endpoint := strings.TrimRight(p.cfg.PyroscopeURL, "/") + "/ingest?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := p.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("pyroscope upload failed: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// enabled проверяет, включен ли профиль name
+func (p *Profiler) enabled(name string) bool {
+	for _, profile := range p.cfg.Profiles {
+		if profile == name {
+			return true
+		}
+	}
+	return false
+}
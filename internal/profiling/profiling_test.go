@@ -0,0 +1,113 @@
+package profiling
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/logger"
+)
+
+// setupProfiler создает профилировщик с директорией во временном каталоге
+func setupProfiler(t *testing.T, cfg Config) *Profiler {
+	t.Helper()
+	log, err := logger.New("test-profiling", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { log.Close() })
+	cfg.Dir = t.TempDir()
+	return New(log, nil, cfg)
+}
+
+// TestCollect проверяет запись профилей и ротацию файлов
+func TestCollect(t *testing.T) {
+	p := setupProfiler(t, Config{
+		CPUDuration: 50 * time.Millisecond,
+		Profiles:    []string{ProfileCPU, ProfileHeap, ProfileGoroutine},
+		Retention:   2,
+	})
+
+	p.Collect(context.Background())
+	for _, name := range []string{ProfileCPU, ProfileHeap, ProfileGoroutine} {
+		files, _ := filepath.Glob(filepath.Join(p.cfg.Dir, name+"-*"+fileExt))
+		if len(files) != 1 {
+			t.Fatalf("%s profiles = %v, want 1 file", name, files)
+		}
+		info, err := os.Stat(files[0])
+		if err != nil || info.Size() == 0 {
+			t.Errorf("%s profile is empty: %v", name, err)
+		}
+	}
+
+	// Старые файлы сверх Retention удаляются
+	for i := 0; i < 3; i++ {
+		if err := p.save(ProfileHeap, time.Now().Add(time.Duration(i+1)*time.Hour), []byte("x")); err != nil {
+			t.Fatalf("save() error = %v", err)
+		}
+	}
+	files, _ := filepath.Glob(filepath.Join(p.cfg.Dir, ProfileHeap+"-*"+fileExt))
+	if len(files) != 2 {
+		t.Errorf("heap profiles after rotation = %v, want 2 files", files)
+	}
+}
+
+// TestPyroscope проверяет отправку профиля в Pyroscope
+func TestPyroscope(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		names []string
+		size  int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("profile")
+		if err != nil || r.URL.Path != "/ingest" || r.URL.Query().Get("format") != "pprof" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		mu.Lock()
+		names = append(names, r.URL.Query().Get("name"))
+		size += len(data)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	p := setupProfiler(t, Config{
+		Profiles:     []string{ProfileHeap},
+		PyroscopeURL: srv.URL + "/",
+		AppName:      "svc",
+	})
+	p.Collect(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(names) != 1 || names[0] != "svc.heap" || size == 0 {
+		t.Errorf("uploaded names = %v, size = %d", names, size)
+	}
+}
+
+// TestStopDuringCPUProfile проверяет, что остановка прерывает снятие CPU профиля
+func TestStopDuringCPUProfile(t *testing.T) {
+	p := setupProfiler(t, Config{
+		Interval:    10 * time.Millisecond,
+		CPUDuration: time.Minute,
+		Profiles:    []string{ProfileCPU},
+	})
+	if err := p.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.BeforeStop(ctx); err != nil {
+		t.Fatalf("BeforeStop() error = %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+// Package ratelimit предоставляет именованные ограничители частоты запросов
+// (token bucket), общие для всех таймеров и заданий сервиса: несколько
+// обработчиков, обращающихся к одному внешнему API, делят один бюджет
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+)
+
+// ErrUnknownLimiter ограничитель с таким именем не настроен
+var ErrUnknownLimiter = errors.New("unknown rate limiter")
+
+// Config содержит настройки ограничителя
+type Config struct {
+	// RequestsPerSecond скорость пополнения токенов
+	RequestsPerSecond float64
+	// Burst емкость корзины: сколько запросов можно выполнить без ожидания
+	Burst int
+}
+
+// Limiter ограничитель token bucket. Безопасен для одновременного использования
+type Limiter struct {
+	name    string
+	metrics *metrics.Server
+	rate    float64
+	burst   float64
+	now     func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// New создает ограничитель с полной корзиной
+func New(name string, metricsServer *metrics.Server, cfg Config) *Limiter {
+	if cfg.Burst <= 0 {
+		cfg.Burst = 1
+	}
+	l := &Limiter{
+		name:    name,
+		metrics: metricsServer,
+		rate:    cfg.RequestsPerSecond,
+		burst:   float64(cfg.Burst),
+		now:     time.Now,
+	}
+	l.tokens = l.burst
+	l.last = l.now()
+	return l
+}
+
+// Name возвращает имя ограничителя
+func (l *Limiter) Name() string {
+	return l.name
+}
+
+// Allow забирает токен, если он есть, не дожидаясь пополнения
+func (l *Limiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill()
+	if l.tokens < 1 {
+		if l.metrics != nil {
+			l.metrics.RecordRateLimitRejected(l.name)
+		}
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Wait забирает токен, при необходимости дожидаясь его пополнения.
+// При отмене ctx токен возвращается в корзину
+func (l *Limiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	l.refill()
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if l.metrics != nil {
+		l.metrics.RecordRateLimitWait(l.name, delay)
+	}
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// refill пополняет корзину за время с предыдущего обращения
+func (l *Limiter) refill() {
+	now := l.now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+}
+
+// Registry набор именованных ограничителей из конфигурации
+type Registry struct {
+	limiters map[string]*Limiter
+}
+
+// NewRegistry создает ограничители для настроек configs
+func NewRegistry(log *logger.Logger, metricsServer *metrics.Server, configs map[string]Config) *Registry {
+	r := &Registry{limiters: make(map[string]*Limiter, len(configs))}
+	for name, cfg := range configs {
+		r.limiters[name] = New(name, metricsServer, cfg)
+		log.Debug("Rate limiter configured", map[string]interface{}{
+			"limiter":             name,
+			"requests_per_second": cfg.RequestsPerSecond,
+			"burst":               cfg.Burst,
+		})
+	}
+	return r
+}
+
+// Get возвращает ограничитель name или ErrUnknownLimiter
+func (r *Registry) Get(name string) (*Limiter, error) {
+	l, ok := r.limiters[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownLimiter, name)
+	}
+	return l, nil
+}
+
+// Names возвращает отсортированные имена ограничителей
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.limiters))
+	for name := range r.limiters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
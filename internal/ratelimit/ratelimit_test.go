@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+)
+
+// TestAllow проверяет емкость корзины и пополнение токенов
+func TestAllow(t *testing.T) {
+	l := New("api", nil, Config{RequestsPerSecond: 2, Burst: 2})
+	now := time.Now()
+	l.now = func() time.Time { return now }
+	l.last = now
+
+	if !l.Allow() || !l.Allow() {
+		t.Fatal("Allow() = false within burst")
+	}
+	if l.Allow() {
+		t.Error("Allow() = true with empty bucket")
+	}
+	now = now.Add(500 * time.Millisecond)
+	if !l.Allow() {
+		t.Error("Allow() = false after refill")
+	}
+	// Корзина не пополняется выше Burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		l.Allow()
+	}
+	if l.Allow() {
+		t.Error("Allow() = true beyond burst after long idle")
+	}
+}
+
+// TestWait проверяет ожидание токена и отмену по контексту
+func TestWait(t *testing.T) {
+	l := New("api", nil, Config{RequestsPerSecond: 20, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 waits at 20 rps took %v, want >= 100ms", elapsed)
+	}
+
+	slow := New("slow", nil, Config{RequestsPerSecond: 0.1, Burst: 1})
+	slow.Wait(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := slow.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want DeadlineExceeded", err)
+	}
+}
+
+// TestRegistry проверяет получение ограничителей по имени
+func TestRegistry(t *testing.T) {
+	log, err := logger.New("test-ratelimit", t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	defer log.Close()
+
+	r := NewRegistry(log, metrics.New(log, true, "127.0.0.1:0"), map[string]Config{
+		"github": {RequestsPerSecond: 1},
+		"crm":    {RequestsPerSecond: 5, Burst: 10},
+	})
+	if l, err := r.Get("crm"); err != nil || l.Name() != "crm" {
+		t.Errorf("Get(crm) = %v, %v", l, err)
+	}
+	if _, err := r.Get("missing"); !errors.Is(err, ErrUnknownLimiter) {
+		t.Errorf("Get(missing) error = %v, want ErrUnknownLimiter", err)
+	}
+	if names := r.Names(); len(names) != 2 || names[0] != "crm" {
+		t.Errorf("Names() = %v", names)
+	}
+}
@@ -0,0 +1,328 @@
+// Package redisclient управляет клиентом Redis: настройка из конфигурации
+// (адрес, TLS, учетные данные), подключение при запуске, health check
+// и метрики длительности команд
+package redisclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+	"service-boilerplate/internal/task"
+)
+
+// Config содержит настройки клиента. Пароль берется из переменной окружения
+// PasswordEnv или файла PasswordFile, если они заданы, иначе из Password
+type Config struct {
+	Addr         string
+	Username     string
+	Password     string
+	PasswordEnv  string
+	PasswordFile string
+	DB           int
+	PoolSize     int
+	TLS          bool
+	CAFile       string
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	PingTimeout  time.Duration
+}
+
+// pipelineCommand метка метрик для pipeline и транзакций
+const pipelineCommand = "pipeline"
+
+// ErrNotConnected клиент еще не подключен или уже закрыт
+var ErrNotConnected = errors.New("redis is not connected")
+
+// Client клиент Redis. Реализует task.Task, task.Checker и task.Dependent:
+// ждет доступности сервера, подключается в AfterStart, закрывается в BeforeStop
+type Client struct {
+	log     *logger.Logger
+	metrics *metrics.Server
+	cfg     Config
+
+	mu     sync.RWMutex
+	client *redis.Client
+	// secret пароль новых соединений клиента, заменяется Rotate
+	secret string
+}
+
+// New создает клиент с настройками cfg. Подключение выполняется при запуске
+func New(log *logger.Logger, metricsServer *metrics.Server, cfg Config) *Client {
+	if cfg.PingTimeout <= 0 {
+		cfg.PingTimeout = 5 * time.Second
+	}
+	return &Client{log: log, metrics: metricsServer, cfg: cfg}
+}
+
+// Client возвращает *redis.Client или nil, если клиент не подключен
+func (c *Client) Client() *redis.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.client
+}
+
+// Name возвращает имя задачи
+func (c *Client) Name() string {
+	return "redis"
+}
+
+// ReadOnly задача запускается в режиме только для чтения: клиент нужен проверкам здоровья и задачам только для чтения
+func (c *Client) ReadOnly() bool {
+	return true
+}
+
+// ShutdownPhase закрывает клиент после задач, которые им пользуются
+func (c *Client) ShutdownPhase() task.Phase {
+	return task.PhaseRelease
+}
+
+// Dependencies ждет доступности адреса сервера перед подключением:
+// Redis, запускаемый вместе с сервисом, может подняться позже
+func (c *Client) Dependencies() []task.Dependency {
+	return []task.Dependency{task.TCP(c.cfg.Addr)}
+}
+
+// Check проверяет настройки TLS и учетных данных и доступность сервера
+func (c *Client) Check(ctx context.Context) error {
+	client, err := c.open()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return c.ping(ctx, client)
+}
+
+// AfterStart подключается к серверу и проверяет соединение
+func (c *Client) AfterStart(ctx context.Context) error {
+	password, err := c.password()
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.secret = password
+	c.mu.Unlock()
+	client, err := c.newClient(c.credentials)
+	if err != nil {
+		return err
+	}
+	if err := c.ping(ctx, client); err != nil {
+		client.Close()
+		return err
+	}
+
+	c.mu.Lock()
+	c.client = client
+	c.mu.Unlock()
+
+	c.log.Info("Redis connected", map[string]interface{}{
+		"addr": c.cfg.Addr,
+		"db":   c.cfg.DB,
+		"tls":  c.cfg.TLS,
+	})
+	return nil
+}
+
+// BeforeStop закрывает соединения клиента
+func (c *Client) BeforeStop(ctx context.Context) error {
+	c.mu.Lock()
+	client := c.client
+	c.client = nil
+	c.mu.Unlock()
+	if client == nil {
+		return nil
+	}
+
+	c.log.Info("Closing Redis client")
+	return client.Close()
+}
+
+// Ping проверяет соединение. Используется как health check
+func (c *Client) Ping(ctx context.Context) error {
+	client := c.Client()
+	if client == nil {
+		return ErrNotConnected
+	}
+	return c.ping(ctx, client)
+}
+
+// Rotate перечитывает пароль (файл PasswordFile или переменную PasswordEnv)
+// и, если он изменился, проверяет его отдельным подключением и передает
+// клиенту. *redis.Client остается прежним: новые соединения аутентифицируются
+// новым паролем, открытые остаются аутентифицированными старым
+func (c *Client) Rotate(ctx context.Context) error {
+	if c.Client() == nil {
+		return ErrNotConnected
+	}
+	password, err := c.password()
+	if err != nil {
+		return err
+	}
+	c.mu.RLock()
+	same := password == c.secret
+	c.mu.RUnlock()
+	if same {
+		return nil
+	}
+
+	probe, err := c.newClient(func() (string, string) { return c.cfg.Username, password })
+	if err != nil {
+		return err
+	}
+	defer probe.Close()
+	if err := c.ping(ctx, probe); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.secret = password
+	c.mu.Unlock()
+	c.log.Info("Redis credentials rotated", map[string]interface{}{"addr": c.cfg.Addr})
+	return nil
+}
+
+// credentials возвращает учетные данные новых соединений клиента
+func (c *Client) credentials() (string, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cfg.Username, c.secret
+}
+
+// open создает клиент с паролем из окружения, файла или конфигурации
+func (c *Client) open() (*redis.Client, error) {
+	password, err := c.password()
+	if err != nil {
+		return nil, err
+	}
+	return c.newClient(func() (string, string) { return c.cfg.Username, password })
+}
+
+// newClient создает клиент с настройками из конфигурации и учетными
+// данными от credentials
+func (c *Client) newClient(credentials func() (string, string)) (*redis.Client, error) {
+	if c.cfg.Addr == "" {
+		return nil, errors.New("redis address is not configured")
+	}
+
+	opts := &redis.Options{
+		Addr:                c.cfg.Addr,
+		CredentialsProvider: credentials,
+		DB:                  c.cfg.DB,
+		PoolSize:            c.cfg.PoolSize,
+		DialTimeout:         c.cfg.DialTimeout,
+		ReadTimeout:         c.cfg.ReadTimeout,
+		WriteTimeout:        c.cfg.WriteTimeout,
+	}
+	if c.cfg.TLS {
+		tlsConfig, err := c.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		opts.TLSConfig = tlsConfig
+	}
+
+	client := redis.NewClient(opts)
+	client.AddHook(metricsHook{metrics: c.metrics})
+	return client, nil
+}
+
+// ping проверяет соединение с таймаутом PingTimeout
+func (c *Client) ping(ctx context.Context, client *redis.Client) error {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.PingTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to ping redis %s: %w", c.cfg.Addr, err)
+	}
+	return nil
+}
+
+// password возвращает пароль из окружения, файла или конфигурации
+func (c *Client) password() (string, error) {
+	switch {
+	case c.cfg.PasswordEnv != "":
+		password := os.Getenv(c.cfg.PasswordEnv)
+		if password == "" {
+			return "", fmt.Errorf("environment variable %s is empty", c.cfg.PasswordEnv)
+		}
+		return password, nil
+	case c.cfg.PasswordFile != "":
+		data, err := os.ReadFile(c.cfg.PasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read redis password file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	default:
+		return c.cfg.Password, nil
+	}
+}
+
+// tlsConfig создает настройки TLS; CAFile заменяет системные корневые сертификаты
+func (c *Client) tlsConfig() (*tls.Config, error) {
+	host, _, err := net.SplitHostPort(c.cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis address: %w", err)
+	}
+	tlsConfig := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+	if c.cfg.CAFile == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(c.cfg.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redis CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", c.cfg.CAFile)
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+// metricsHook записывает длительность и ошибки команд
+type metricsHook struct {
+	metrics *metrics.Server
+}
+
+// DialHook не изменяет установку соединения
+func (h metricsHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook записывает метрики одиночной команды
+func (h metricsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.record(strings.ToLower(cmd.Name()), start, err)
+		return err
+	}
+}
+
+// ProcessPipelineHook записывает метрики pipeline одной серией
+func (h metricsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.record(pipelineCommand, start, err)
+		return err
+	}
+}
+
+// record передает результат команды в метрики. redis.Nil (нет ключа) не считается ошибкой
+func (h metricsHook) record(command string, start time.Time, err error) {
+	if h.metrics != nil {
+		h.metrics.RecordRedisCommand(command, time.Since(start), err != nil && !errors.Is(err, redis.Nil))
+	}
+}
@@ -0,0 +1,128 @@
+package redisclient
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+)
+
+// setupTestClient создает клиент для тестового сервера
+func setupTestClient(t *testing.T, cfg Config) (*Client, *logger.Logger) {
+	log, err := logger.New("test-redis", t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	return New(log, metrics.New(log, true, "127.0.0.1:0"), cfg), log
+}
+
+// TestLifecycle проверяет подключение, выполнение команд, Ping и закрытие
+func TestLifecycle(t *testing.T) {
+	srv := miniredis.RunT(t)
+	c, log := setupTestClient(t, Config{Addr: srv.Addr()})
+	defer log.Close()
+
+	if err := c.Ping(context.Background()); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("Ping() before start error = %v, want ErrNotConnected", err)
+	}
+	if err := c.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if err := c.Client().Set(ctx, "key", "value", 0).Err(); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, _ := c.Client().Get(ctx, "key").Result(); got != "value" {
+		t.Errorf("Get() = %q, want value", got)
+	}
+	if err := c.Client().Get(ctx, "missing").Err(); !errors.Is(err, redis.Nil) {
+		t.Errorf("Get(missing) error = %v, want redis.Nil", err)
+	}
+
+	srv.SetError("LOADING")
+	if err := c.Ping(ctx); err == nil {
+		t.Error("Ping() expected error for failing server")
+	}
+	srv.SetError("")
+
+	if err := c.BeforeStop(ctx); err != nil {
+		t.Errorf("BeforeStop() error = %v", err)
+	}
+	if c.Client() != nil {
+		t.Error("Client() after stop is not nil")
+	}
+}
+
+// TestAuth проверяет получение пароля из окружения и файла
+func TestAuth(t *testing.T) {
+	srv := miniredis.RunT(t)
+	srv.RequireAuth("secret")
+
+	t.Setenv("TEST_REDIS_PASSWORD", "secret")
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	os.WriteFile(passwordFile, []byte("secret\n"), 0600)
+
+	for _, cfg := range []Config{
+		{Addr: srv.Addr(), Password: "wrong", PasswordEnv: "TEST_REDIS_PASSWORD"},
+		{Addr: srv.Addr(), PasswordFile: passwordFile},
+	} {
+		c, log := setupTestClient(t, cfg)
+		if err := c.Check(context.Background()); err != nil {
+			t.Errorf("Check(%+v) error = %v", cfg, err)
+		}
+		log.Close()
+	}
+
+	c, log := setupTestClient(t, Config{Addr: srv.Addr(), Password: "wrong"})
+	defer log.Close()
+	if err := c.AfterStart(context.Background()); err == nil {
+		t.Error("AfterStart() expected error for wrong password")
+	}
+}
+
+// TestRotate проверяет переход клиента на новый пароль из файла
+func TestRotate(t *testing.T) {
+	srv := miniredis.RunT(t)
+	srv.RequireAuth("old")
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	os.WriteFile(passwordFile, []byte("old\n"), 0600)
+
+	c, log := setupTestClient(t, Config{Addr: srv.Addr(), PasswordFile: passwordFile})
+	defer log.Close()
+	if err := c.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+	defer c.BeforeStop(context.Background())
+	client := c.Client()
+
+	srv.RequireAuth("new")
+	if err := c.Rotate(context.Background()); err != nil {
+		t.Errorf("Rotate() with unchanged file error = %v", err)
+	}
+	os.WriteFile(passwordFile, []byte("wrong\n"), 0600)
+	if err := c.Rotate(context.Background()); err == nil {
+		t.Error("Rotate() expected error for wrong password")
+	}
+
+	os.WriteFile(passwordFile, []byte("new\n"), 0600)
+	if err := c.Rotate(context.Background()); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if c.Client() != client {
+		t.Error("Rotate() replaced *redis.Client")
+	}
+	// Новое соединение аутентифицируется новым паролем
+	conn := client.Conn()
+	defer conn.Close()
+	if err := conn.Ping(context.Background()).Err(); err != nil {
+		t.Errorf("Ping() on new connection error = %v", err)
+	}
+}
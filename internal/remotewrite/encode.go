@@ -0,0 +1,120 @@
+package remotewrite
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// labelPair метка серии remote write
+type labelPair struct {
+	name  string
+	value string
+}
+
+// encode преобразует семейства метрик в WriteRequest протокола remote write 1.0
+// и возвращает его protobuf представление и количество сэмплов. Гистограммы
+// и summary раскладываются на серии _bucket/_sum/_count, как на /metrics.
+// Сэмплы без времени получают время now
+func encode(families []*dto.MetricFamily, now time.Time) ([]byte, int) {
+	var buf []byte
+	samples := 0
+	add := func(name string, labels []*dto.LabelPair, extra *labelPair, value float64, ts int64) {
+		buf = appendSeries(buf, seriesLabels(name, labels, extra), value, ts)
+		samples++
+	}
+
+	for _, family := range families {
+		name := family.GetName()
+		for _, m := range family.Metric {
+			ts := m.GetTimestampMs()
+			if ts == 0 {
+				ts = now.UnixMilli()
+			}
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				add(name, m.Label, nil, m.GetCounter().GetValue(), ts)
+			case dto.MetricType_GAUGE:
+				add(name, m.Label, nil, m.GetGauge().GetValue(), ts)
+			case dto.MetricType_UNTYPED:
+				add(name, m.Label, nil, m.GetUntyped().GetValue(), ts)
+			case dto.MetricType_SUMMARY:
+				summary := m.GetSummary()
+				for _, q := range summary.Quantile {
+					add(name, m.Label, &labelPair{"quantile", formatFloat(q.GetQuantile())}, q.GetValue(), ts)
+				}
+				add(name+"_sum", m.Label, nil, summary.GetSampleSum(), ts)
+				add(name+"_count", m.Label, nil, float64(summary.GetSampleCount()), ts)
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				histogram := m.GetHistogram()
+				infSeen := false
+				for _, b := range histogram.Bucket {
+					if math.IsInf(b.GetUpperBound(), 1) {
+						infSeen = true
+					}
+					add(name+"_bucket", m.Label, &labelPair{"le", formatFloat(b.GetUpperBound())}, float64(b.GetCumulativeCount()), ts)
+				}
+				if !infSeen {
+					add(name+"_bucket", m.Label, &labelPair{"le", "+Inf"}, float64(histogram.GetSampleCount()), ts)
+				}
+				add(name+"_sum", m.Label, nil, histogram.GetSampleSum(), ts)
+				add(name+"_count", m.Label, nil, float64(histogram.GetSampleCount()), ts)
+			}
+		}
+	}
+	return buf, samples
+}
+
+// seriesLabels возвращает метки серии с __name__, отсортированные по имени,
+// как требует протокол
+func seriesLabels(name string, labels []*dto.LabelPair, extra *labelPair) []labelPair {
+	pairs := make([]labelPair, 0, len(labels)+2)
+	pairs = append(pairs, labelPair{"__name__", name})
+	for _, l := range labels {
+		pairs = append(pairs, labelPair{l.GetName(), l.GetValue()})
+	}
+	if extra != nil {
+		pairs = append(pairs, *extra)
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].name < pairs[j].name })
+	return pairs
+}
+
+// formatFloat форматирует значение меток le и quantile, как в формате экспозиции
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// appendSeries добавляет к WriteRequest серию (поле 1) с одним сэмплом.
+// TimeSeries: labels = 1, samples = 2; Label: name = 1, value = 2;
+// Sample: value = 1 (double), timestamp = 2 (int64, мс)
+func appendSeries(buf []byte, labels []labelPair, value float64, ts int64) []byte {
+	var series []byte
+	for _, l := range labels {
+		var label []byte
+		label = protowire.AppendTag(label, 1, protowire.BytesType)
+		label = protowire.AppendString(label, l.name)
+		label = protowire.AppendTag(label, 2, protowire.BytesType)
+		label = protowire.AppendString(label, l.value)
+		series = protowire.AppendTag(series, 1, protowire.BytesType)
+		series = protowire.AppendBytes(series, label)
+	}
+
+	var sample []byte
+	sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+	sample = protowire.AppendFixed64(sample, math.Float64bits(value))
+	sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+	sample = protowire.AppendVarint(sample, uint64(ts))
+	series = protowire.AppendTag(series, 2, protowire.BytesType)
+	series = protowire.AppendBytes(series, sample)
+
+	buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+	return protowire.AppendBytes(buf, series)
+}
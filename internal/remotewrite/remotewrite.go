@@ -0,0 +1,286 @@
+// Package remotewrite периодически отправляет метрики в endpoint Prometheus
+// remote write (протокол 1.0: protobuf WriteRequest со сжатием snappy)
+// для окружений, где нет Prometheus, опрашивающего /metrics. Батчи, которые
+// не удалось отправить, хранятся в памяти без WAL: при переполнении буфера
+// отбрасываются самые старые, при остановке сервиса неотправленные теряются
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"service-boilerplate/internal/httpclient"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+)
+
+// errRejected ответ endpoint, после которого батч не повторяется (4xx кроме 429)
+var errRejected = errors.New("batch rejected by remote-write endpoint")
+
+// Config содержит настройки отправки
+type Config struct {
+	// URL endpoint remote write (например, http://prometheus:9090/api/v1/write)
+	URL string
+	// Interval период сбора и отправки метрик
+	Interval time.Duration
+	// Timeout ограничивает отправку батча вместе с повторами
+	Timeout time.Duration
+	// BufferSize сколько неотправленных батчей хранить в памяти
+	BufferSize int
+	// Username и Password для basic auth (пустой Username - без basic auth)
+	Username string
+	Password string
+	// BearerToken токен заголовка Authorization (вместо basic auth)
+	BearerToken string
+	// Headers дополнительные заголовки запроса (например, X-Scope-OrgID)
+	Headers map[string]string
+	// Client HTTP клиент для отправки. Повторы при сетевых ошибках и ответах
+	// 429/502/503/504 выполняет клиент (httpclient.Config MaxRetries и RetryBackoff):
+	// батч отправляется с заголовком Idempotency-Key
+	Client *http.Client
+}
+
+// batch собранные за один период метрики, сжатые для отправки. key
+// ключ идемпотентности: повторные отправки батча несут один ключ
+type batch struct {
+	data    []byte
+	samples int
+	key     string
+}
+
+// Writer собирает метрики по расписанию и отправляет их. Реализует task.Task
+type Writer struct {
+	log      *logger.Logger
+	metrics  *metrics.Server
+	gatherer prometheus.Gatherer
+	cfg      Config
+
+	// pending неотправленные батчи, от старых к новым. Используется только
+	// горутиной отправки, а после ее остановки - BeforeStop
+	pending []batch
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New создает отправку метрик из gatherer (metrics.Server.Gatherer)
+func New(log *logger.Logger, metricsServer *metrics.Server, gatherer prometheus.Gatherer, cfg Config) *Writer {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 10
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{}
+	}
+	return &Writer{log: log, metrics: metricsServer, gatherer: gatherer, cfg: cfg}
+}
+
+// Name возвращает имя задачи
+func (w *Writer) Name() string {
+	return "remote-write"
+}
+
+// AfterStart запускает периодическую отправку
+func (w *Writer) AfterStart(ctx context.Context) error {
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.wg.Add(1)
+	go w.loop(ctx)
+
+	w.log.Info("Remote write started", map[string]interface{}{
+		"url":      redactURL(w.cfg.URL),
+		"interval": w.cfg.Interval.String(),
+	})
+	return nil
+}
+
+// BeforeStop останавливает периодическую отправку и последний раз
+// отправляет метрики и буфер, пока не истек ctx остановки
+func (w *Writer) BeforeStop(ctx context.Context) error {
+	if w.cancel == nil {
+		return nil
+	}
+	w.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	w.push(ctx)
+	if samples := w.pendingSamples(); samples > 0 {
+		if w.metrics != nil {
+			w.metrics.RecordRemoteWrite("dropped", samples)
+		}
+		w.log.Warn("Remote write buffer lost on stop", map[string]interface{}{
+			"batches": len(w.pending),
+			"samples": samples,
+		})
+	}
+	return nil
+}
+
+// loop собирает и отправляет метрики каждые Interval
+func (w *Writer) loop(ctx context.Context) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.push(ctx)
+		}
+	}
+}
+
+// push собирает метрики в новый батч и отправляет буфер
+func (w *Writer) push(ctx context.Context) {
+	w.collect()
+	w.flush(ctx)
+	if w.metrics != nil {
+		w.metrics.SetRemoteWritePending(w.pendingSamples())
+	}
+}
+
+// collect собирает метрики и добавляет батч в буфер, отбрасывая
+// самый старый батч при переполнении
+func (w *Writer) collect() {
+	families, err := w.gatherer.Gather()
+	if err != nil {
+		// Gather возвращает метрики, которые удалось собрать, вместе с ошибкой
+		w.log.Warn("Failed to gather metrics for remote write", map[string]interface{}{"error": err.Error()})
+	}
+	data, samples := encode(families, time.Now())
+	if samples == 0 {
+		return
+	}
+	w.pending = append(w.pending, batch{data: s2.EncodeSnappy(nil, data), samples: samples, key: httpclient.NewIdempotencyKey()})
+	if over := len(w.pending) - w.cfg.BufferSize; over > 0 {
+		dropped := 0
+		for _, b := range w.pending[:over] {
+			dropped += b.samples
+		}
+		w.pending = append([]batch(nil), w.pending[over:]...)
+		if w.metrics != nil {
+			w.metrics.RecordRemoteWrite("dropped", dropped)
+		}
+		w.log.Warn("Remote write buffer is full, dropping oldest batch", map[string]interface{}{
+			"buffer_size": w.cfg.BufferSize,
+			"samples":     dropped,
+		})
+	}
+}
+
+// flush отправляет батчи буфера от старых к новым. Отправка прекращается
+// на первом батче, который не удалось отправить, чтобы сэмплы серий
+// приходили по порядку времени
+func (w *Writer) flush(ctx context.Context) {
+	for len(w.pending) > 0 {
+		b := w.pending[0]
+		err := w.post(ctx, b)
+		switch {
+		case errors.Is(err, errRejected):
+			if w.metrics != nil {
+				w.metrics.RecordRemoteWrite("dropped", b.samples)
+			}
+			w.log.Warn("Remote write endpoint rejected batch", map[string]interface{}{
+				"samples": b.samples,
+				"error":   err.Error(),
+			})
+		case err != nil:
+			if ctx.Err() == nil {
+				w.log.Warn("Remote write failed, keeping batch for the next push", map[string]interface{}{
+					"batches": len(w.pending),
+					"error":   err.Error(),
+				})
+			}
+			return
+		default:
+			if w.metrics != nil {
+				w.metrics.RecordRemoteWrite("sent", b.samples)
+			}
+		}
+		w.pending = w.pending[1:]
+	}
+}
+
+// post отправляет батч. Запрос несет Idempotency-Key, и клиент
+// повторяет его при сетевых ошибках и ответах 429/502/503/504
+func (w *Writer) post(ctx context.Context, b batch) error {
+	ctx, cancel := context.WithTimeout(ctx, w.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(b.data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errRejected, err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	req.Header.Set(httpclient.IdempotencyKeyHeader, b.key)
+	switch {
+	case w.cfg.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+w.cfg.BearerToken)
+	case w.cfg.Username != "":
+		req.SetBasicAuth(w.cfg.Username, w.cfg.Password)
+	}
+	for name, value := range w.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := w.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	switch {
+	case resp.StatusCode/100 == 2:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("remote write failed: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	default:
+		return fmt.Errorf("%w: %s: %s", errRejected, resp.Status, strings.TrimSpace(string(msg)))
+	}
+}
+
+// pendingSamples возвращает количество сэмплов в буфере
+func (w *Writer) pendingSamples() int {
+	samples := 0
+	for _, b := range w.pending {
+		samples += b.samples
+	}
+	return samples
+}
+
+// redactURL скрывает пароль в URL для логов
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return u.Redacted()
+}
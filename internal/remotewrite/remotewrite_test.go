@@ -0,0 +1,221 @@
+package remotewrite
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"service-boilerplate/internal/httpclient"
+	"service-boilerplate/internal/logger"
+)
+
+// decodeRequest разбирает WriteRequest в map "имя{метки}" -> значение.
+// Метки перечисляются в порядке запроса, без __name__
+func decodeRequest(t *testing.T, data []byte) map[string]float64 {
+	t.Helper()
+	series := make(map[string]float64)
+	for len(data) > 0 {
+		_, _, n := protowire.ConsumeTag(data)
+		ts, m := protowire.ConsumeBytes(data[n:])
+		data = data[n+m:]
+
+		var name, labels string
+		var value float64
+		for len(ts) > 0 {
+			num, _, n := protowire.ConsumeTag(ts)
+			field, m := protowire.ConsumeBytes(ts[n:])
+			ts = ts[n+m:]
+			if num == 1 {
+				_, _, n := protowire.ConsumeTag(field)
+				key, m := protowire.ConsumeString(field[n:])
+				field = field[n+m:]
+				_, _, n = protowire.ConsumeTag(field)
+				val, _ := protowire.ConsumeString(field[n:])
+				if key == "__name__" {
+					name = val
+				} else {
+					labels += key + "=" + val + ","
+				}
+				continue
+			}
+			_, _, n = protowire.ConsumeTag(field)
+			bits, _ := protowire.ConsumeFixed64(field[n:])
+			value = math.Float64frombits(bits)
+		}
+		series[name+"{"+labels+"}"] = value
+	}
+	return series
+}
+
+// receiver тестовый endpoint remote write
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []map[string]float64
+	headers  http.Header
+}
+
+// handler принимает запрос и отвечает следующим статусом из statuses
+// (после их окончания - 204)
+func (r *receiver) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		data, err := s2.Decode(nil, body)
+		if err != nil {
+			t.Errorf("snappy decode error = %v", err)
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.headers = req.Header.Clone()
+		status := http.StatusNoContent
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		if status == http.StatusNoContent {
+			r.requests = append(r.requests, decodeRequest(t, data))
+		}
+		w.WriteHeader(status)
+	}
+}
+
+// respond задает статусы следующих ответов
+func (r *receiver) respond(statuses ...int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses = statuses
+}
+
+// received возвращает принятые запросы
+func (r *receiver) received() []map[string]float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]map[string]float64(nil), r.requests...)
+}
+
+// setupWriter создает отправку метрик registry в тестовый endpoint
+// с клиентом с retries повторами
+func setupWriter(t *testing.T, url string, cfg Config, retries int) *Writer {
+	t.Helper()
+	log, err := logger.New("test-remotewrite", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { log.Close() })
+
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_runs_total", Help: "runs"}, []string{"timer"})
+	counter.WithLabelValues("report").Add(3)
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_duration_seconds", Help: "duration", Buckets: []float64{1}})
+	histogram.Observe(0.5)
+	registry.MustRegister(counter, histogram)
+
+	cfg.URL = url
+	cfg.Client = retryClient(log, retries)
+	return New(log, nil, registry, cfg)
+}
+
+// retryClient создает HTTP клиент с retries повторами
+func retryClient(log *logger.Logger, retries int) *http.Client {
+	return httpclient.New(log, nil, httpclient.Config{Name: "remote_write", MaxRetries: retries, RetryBackoff: time.Millisecond})
+}
+
+// TestPush проверяет формат запроса, заголовки и авторизацию
+func TestPush(t *testing.T) {
+	r := &receiver{}
+	server := httptest.NewServer(r.handler(t))
+	defer server.Close()
+
+	w := setupWriter(t, server.URL, Config{BearerToken: "secret", Headers: map[string]string{"X-Scope-OrgID": "team"}}, 0)
+	w.push(context.Background())
+
+	requests := r.received()
+	if len(requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(requests))
+	}
+	got := requests[0]
+	want := map[string]float64{
+		"test_runs_total{timer=report,}":         3,
+		"test_duration_seconds_bucket{le=1,}":    1,
+		"test_duration_seconds_bucket{le=+Inf,}": 1,
+		"test_duration_seconds_sum{}":            0.5,
+		"test_duration_seconds_count{}":          1,
+	}
+	for series, value := range want {
+		if got[series] != value {
+			t.Errorf("%s = %v, want %v (request %v)", series, got[series], value, got)
+		}
+	}
+	for header, value := range map[string]string{
+		"Content-Encoding":                  "snappy",
+		"Content-Type":                      "application/x-protobuf",
+		"X-Prometheus-Remote-Write-Version": "0.1.0",
+		"Authorization":                     "Bearer secret",
+		"X-Scope-Orgid":                     "team",
+	} {
+		r.mu.Lock()
+		headers := r.headers
+		r.mu.Unlock()
+		if headers.Get(header) != value {
+			t.Errorf("header %s = %q, want %q", header, headers.Get(header), value)
+		}
+	}
+	r.mu.Lock()
+	key := r.headers.Get(httpclient.IdempotencyKeyHeader)
+	r.mu.Unlock()
+	if key == "" {
+		t.Error("batch sent without Idempotency-Key")
+	}
+	if len(w.pending) != 0 {
+		t.Errorf("pending = %d batches after successful push, want 0", len(w.pending))
+	}
+}
+
+// TestPush_RetryAndBuffer проверяет повторы, хранение неотправленных
+// батчей и отбрасывание отклоненных
+func TestPush_RetryAndBuffer(t *testing.T) {
+	r := &receiver{}
+	server := httptest.NewServer(r.handler(t))
+	defer server.Close()
+
+	// Ответ 503 повторяется
+	w := setupWriter(t, server.URL, Config{BufferSize: 2}, 1)
+	r.respond(http.StatusServiceUnavailable)
+	w.push(context.Background())
+	if len(r.received()) != 1 || len(w.pending) != 0 {
+		t.Fatalf("requests = %d, pending = %d, want 1 and 0 after retry", len(r.received()), len(w.pending))
+	}
+
+	// Без повторов батчи копятся в буфере, старые вытесняются
+	w.cfg.Client = retryClient(w.log, 0)
+	r.respond(http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	for i := 0; i < 3; i++ {
+		w.push(context.Background())
+	}
+	if len(w.pending) != 2 {
+		t.Fatalf("pending = %d batches, want buffer_size 2", len(w.pending))
+	}
+
+	// Endpoint восстановился: новый батч вытесняет самый старый,
+	// оставшиеся отправлены по порядку
+	w.push(context.Background())
+	if len(r.received()) != 3 || len(w.pending) != 0 {
+		t.Errorf("requests = %d, pending = %d, want 3 and 0", len(r.received()), len(w.pending))
+	}
+
+	// 400 не повторяется, батч отбрасывается
+	w.cfg.Client = retryClient(w.log, 3)
+	r.respond(http.StatusBadRequest)
+	w.push(context.Background())
+	if len(r.received()) != 3 || len(w.pending) != 0 {
+		t.Errorf("requests = %d, pending = %d after rejected batch, want 3 and 0", len(r.received()), len(w.pending))
+	}
+}
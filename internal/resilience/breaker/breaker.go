@@ -0,0 +1,192 @@
+// Package breaker реализует circuit breaker: после серии неудач запросы
+// к зависимости временно прекращаются, затем пропускаются пробные запросы,
+// и при их успехе breaker снова замыкается
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+)
+
+// State состояние breaker
+type State int
+
+// Состояния breaker. Значения совпадают со значениями метрики circuit_breaker_state
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+// String возвращает название состояния
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	// ErrOpen breaker разомкнут, запрос не выполняется
+	ErrOpen = errors.New("circuit breaker is open")
+	// ErrTooManyProbes все пробные запросы полуоткрытого breaker уже выполняются
+	ErrTooManyProbes = errors.New("circuit breaker probe limit reached")
+)
+
+// Config содержит настройки breaker
+type Config struct {
+	// Name имя зависимости в метриках и логах
+	Name string
+	// FailureThreshold количество неудач подряд, после которого breaker размыкается
+	FailureThreshold int
+	// OpenTimeout время в разомкнутом состоянии до пробных запросов
+	OpenTimeout time.Duration
+	// HalfOpenProbes количество пробных запросов; если все успешны, breaker замыкается
+	HalfOpenProbes int
+}
+
+// Breaker circuit breaker. Безопасен для одновременного использования.
+// Реализует httpclient.Breaker
+type Breaker struct {
+	log     *logger.Logger
+	metrics *metrics.Server
+	cfg     Config
+	now     func() time.Time
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	openedAt  time.Time
+	probes    int
+	successes int
+}
+
+// New создает замкнутый breaker с настройками cfg
+func New(log *logger.Logger, metricsServer *metrics.Server, cfg Config) *Breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+	if cfg.HalfOpenProbes <= 0 {
+		cfg.HalfOpenProbes = 1
+	}
+	b := &Breaker{log: log, metrics: metricsServer, cfg: cfg, now: time.Now}
+	if b.metrics != nil {
+		b.metrics.SetBreakerState(cfg.Name, int(StateClosed))
+	}
+	return b
+}
+
+// State возвращает текущее состояние
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expire()
+	return b.state
+}
+
+// Allow разрешает запрос или возвращает ErrOpen/ErrTooManyProbes.
+// После разрешенного запроса нужно вызвать Report
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expire()
+
+	switch b.state {
+	case StateOpen:
+		return ErrOpen
+	case StateHalfOpen:
+		if b.probes >= b.cfg.HalfOpenProbes {
+			return ErrTooManyProbes
+		}
+		b.probes++
+	}
+	return nil
+}
+
+// Report сообщает результат запроса, разрешенного Allow
+func (b *Breaker) Report(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		if success {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold {
+			b.transition(StateOpen)
+		}
+	case StateHalfOpen:
+		if b.probes > 0 {
+			b.probes--
+		}
+		if !success {
+			b.transition(StateOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.cfg.HalfOpenProbes {
+			b.transition(StateClosed)
+		}
+	}
+}
+
+// Do выполняет fn, если breaker разрешает запрос, и сообщает результат.
+// Удобно для обработчиков таймеров, обращающихся к внешним зависимостям
+func (b *Breaker) Do(fn func() error) error {
+	if err := b.Allow(); err != nil {
+		return err
+	}
+	err := fn()
+	b.Report(err == nil)
+	return err
+}
+
+// expire переводит разомкнутый breaker в полуоткрытое состояние по истечении OpenTimeout
+func (b *Breaker) expire() {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.cfg.OpenTimeout {
+		b.transition(StateHalfOpen)
+	}
+}
+
+// transition меняет состояние, сбрасывает счетчики и записывает метрику
+func (b *Breaker) transition(state State) {
+	from := b.state
+	b.state = state
+	b.failures = 0
+	b.probes = 0
+	b.successes = 0
+	if state == StateOpen {
+		b.openedAt = b.now()
+	}
+
+	fields := map[string]interface{}{
+		"breaker": b.cfg.Name,
+		"from":    from.String(),
+		"to":      state.String(),
+	}
+	if state == StateOpen {
+		fields["open_timeout"] = b.cfg.OpenTimeout.String()
+		b.log.Warn("Circuit breaker opened", fields)
+	} else {
+		b.log.Info("Circuit breaker state changed", fields)
+	}
+	if b.metrics != nil {
+		b.metrics.SetBreakerState(b.cfg.Name, int(state))
+	}
+}
@@ -0,0 +1,84 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/httpclient"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+)
+
+// Breaker используется как breaker HTTP клиента
+var _ httpclient.Breaker = (*Breaker)(nil)
+
+// setupTestBreaker создает breaker с управляемыми часами
+func setupTestBreaker(t *testing.T, cfg Config) (*Breaker, *time.Time, *logger.Logger) {
+	log, err := logger.New("test-breaker", t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	cfg.Name = "test"
+	b := New(log, metrics.New(log, true, "127.0.0.1:0"), cfg)
+	now := time.Now()
+	b.now = func() time.Time { return now }
+	return b, &now, log
+}
+
+// TestTransitions проверяет размыкание, пробные запросы и замыкание
+func TestTransitions(t *testing.T) {
+	b, now, log := setupTestBreaker(t, Config{FailureThreshold: 3, OpenTimeout: time.Minute, HalfOpenProbes: 2})
+	defer log.Close()
+
+	failure := errors.New("unavailable")
+	// Успех сбрасывает счетчик неудач подряд
+	b.Do(func() error { return failure })
+	b.Do(func() error { return failure })
+	b.Do(func() error { return nil })
+	b.Do(func() error { return failure })
+	if b.State() != StateClosed {
+		t.Fatalf("State() = %v, want closed", b.State())
+	}
+	b.Do(func() error { return failure })
+	b.Do(func() error { return failure })
+	if b.State() != StateOpen {
+		t.Fatalf("State() = %v, want open", b.State())
+	}
+	if err := b.Do(func() error { return nil }); !errors.Is(err, ErrOpen) {
+		t.Errorf("Do() error = %v, want ErrOpen", err)
+	}
+
+	// По истечении OpenTimeout пропускаются только HalfOpenProbes запросов
+	*now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrTooManyProbes) {
+		t.Errorf("Allow() error = %v, want ErrTooManyProbes", err)
+	}
+	b.Report(true)
+	b.Report(true)
+	if b.State() != StateClosed {
+		t.Errorf("State() = %v, want closed after successful probes", b.State())
+	}
+}
+
+// TestProbeFailure проверяет повторное размыкание при неудачной пробе
+func TestProbeFailure(t *testing.T) {
+	b, now, log := setupTestBreaker(t, Config{FailureThreshold: 1, OpenTimeout: time.Second})
+	defer log.Close()
+
+	b.Do(func() error { return errors.New("unavailable") })
+	*now = now.Add(time.Second)
+	if b.State() != StateHalfOpen {
+		t.Fatalf("State() = %v, want half-open", b.State())
+	}
+	b.Do(func() error { return errors.New("still unavailable") })
+	if b.State() != StateOpen {
+		t.Errorf("State() = %v, want open", b.State())
+	}
+}
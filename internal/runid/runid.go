@@ -0,0 +1,116 @@
+// Package runid передает идентификатор запуска таймера и контекст
+// трассировки за пределы процесса: в переменные окружения дочерних
+// процессов и в заголовки исходящих HTTP запросов. Так логи вызванной
+// программы или API можно сопоставить с запуском, который ее вызвал
+package runid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Header заголовок исходящих запросов с идентификатором запуска
+const Header = "X-Run-ID"
+
+// Переменные окружения дочерних процессов
+const (
+	// EnvRunID идентификатор запуска
+	EnvRunID = "SERVICE_RUN_ID"
+	// EnvTimer имя таймера
+	EnvTimer = "SERVICE_TIMER"
+)
+
+// Run запуск обработчика таймера
+type Run struct {
+	ID    string
+	Timer string
+}
+
+// runKey ключ контекста запуска
+type runKey struct{}
+
+// traceContext формат W3C Trace Context (traceparent, tracestate)
+var traceContext = propagation.TraceContext{}
+
+// New генерирует идентификатор запуска
+func New() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithRun возвращает контекст с запуском r
+func WithRun(ctx context.Context, r Run) context.Context {
+	return context.WithValue(ctx, runKey{}, r)
+}
+
+// FromContext возвращает запуск из контекста
+func FromContext(ctx context.Context) (Run, bool) {
+	r, ok := ctx.Value(runKey{}).(Run)
+	return r, ok
+}
+
+// ID возвращает идентификатор запуска из контекста или пустую строку
+func ID(ctx context.Context) string {
+	r, _ := FromContext(ctx)
+	return r.ID
+}
+
+// Environ возвращает переменные окружения для дочернего процесса:
+// SERVICE_RUN_ID, SERVICE_TIMER и TRACEPARENT/TRACESTATE, если запуск
+// трассируется. Пустой список, если в контексте нет ни запуска, ни спана
+func Environ(ctx context.Context) []string {
+	var env []string
+	if r, ok := FromContext(ctx); ok {
+		env = append(env, EnvRunID+"="+r.ID)
+		if r.Timer != "" {
+			env = append(env, EnvTimer+"="+r.Timer)
+		}
+	}
+	carrier := propagation.MapCarrier{}
+	traceContext.Inject(ctx, carrier)
+	for _, key := range []string{"traceparent", "tracestate"} {
+		if v := carrier.Get(key); v != "" {
+			env = append(env, strings.ToUpper(key)+"="+v)
+		}
+	}
+	return env
+}
+
+// Inject добавляет в заголовки идентификатор запуска и traceparent.
+// Уже заданные заголовки не перезаписываются
+func Inject(ctx context.Context, h http.Header) {
+	if id := ID(ctx); id != "" && h.Get(Header) == "" {
+		h.Set(Header, id)
+	}
+	if h.Get("traceparent") == "" {
+		traceContext.Inject(ctx, propagation.HeaderCarrier(h))
+	}
+}
+
+// Transport оборачивает base (nil - http.DefaultTransport), добавляя
+// в запросы заголовки Inject из контекста запроса. Клиенты
+// httpclient добавляют их сами
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripper{base: base}
+}
+
+// roundTripper транспорт, добавляющий заголовки запуска
+type roundTripper struct {
+	base http.RoundTripper
+}
+
+// RoundTrip реализует http.RoundTripper
+func (t roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	Inject(req.Context(), req.Header)
+	return t.base.RoundTrip(req)
+}
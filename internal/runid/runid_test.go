@@ -0,0 +1,56 @@
+package runid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestPropagation проверяет передачу запуска и трассировки в окружение
+// дочернего процесса и заголовки исходящего запроса
+func TestPropagation(t *testing.T) {
+	if env := Environ(context.Background()); len(env) != 0 {
+		t.Errorf("Environ() without run = %v, want empty", env)
+	}
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+	ctx = WithRun(ctx, Run{ID: "r1", Timer: "report"})
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	env := Environ(ctx)
+	for _, want := range []string{"SERVICE_RUN_ID=r1", "SERVICE_TIMER=report", "TRACEPARENT=" + traceparent} {
+		if !slices.Contains(env, want) {
+			t.Errorf("Environ() = %v, want %s", env, want)
+		}
+	}
+
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	req.Header.Set(Header, "caller")
+	resp, err := (&http.Client{Transport: Transport(nil)}).Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+	if got.Get(Header) != "caller" || got.Get("traceparent") != traceparent {
+		t.Errorf("headers = %v, want caller run id kept and traceparent %s", got, traceparent)
+	}
+	if req.Header.Get("traceparent") != "" {
+		t.Error("Transport() modified the original request")
+	}
+}
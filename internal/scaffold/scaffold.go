@@ -0,0 +1,340 @@
+// Package scaffold создает проект нового сервиса из исходников boilerplate:
+// заменяет путь модуля и имя сервиса, переименовывает cmd/<имя> и добавляет
+// пример таймера и задачи. Исходниками может быть и сервис, созданный
+// из boilerplate: его имя и модуль заменяются так же
+package scaffold
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// skipped файлы и директории исходников, которые не копируются
+// в дополнение к .gitignore: история git и артефакты сборки и запуска
+var skipped = map[string]bool{
+	".git":          true,
+	"build":         true,
+	"logs":          true,
+	"data":          true,
+	"coverage.out":  true,
+	"coverage.html": true,
+}
+
+// Options параметры нового проекта
+type Options struct {
+	// Source корень исходников (директория с go.mod)
+	Source string
+	// Target директория нового проекта: не существует или пустая
+	Target string
+	// Module путь Go модуля нового проекта
+	Module string
+	// Name имя сервиса: имя службы, бинарника и директории cmd
+	Name string
+	// DisplayName отображаемое имя сервиса
+	DisplayName string
+	// SourceName и SourceDisplayName имена сервиса в исходниках
+	// (app.ServiceName, app.ServiceDisplayName)
+	SourceName        string
+	SourceDisplayName string
+}
+
+// Result результат генерации проекта
+type Result struct {
+	// SourceModule путь модуля исходников из go.mod
+	SourceModule string
+	// Files количество записанных файлов
+	Files int
+}
+
+// Generate создает проект в opts.Target. Текстовые файлы копируются
+// с заменой пути модуля в импортах, имени и отображаемого имени сервиса,
+// двоичные и *.pb.go - без изменений. Директории с именем сервиса переименовываются.
+// cmd/<имя>/components.go заменяется регистрацией примера из internal/example
+func Generate(opts Options) (Result, error) {
+	if err := opts.validate(); err != nil {
+		return Result{}, err
+	}
+	sourceModule, err := readModule(filepath.Join(opts.Source, "go.mod"))
+	if err != nil {
+		return Result{}, err
+	}
+	if _, err := os.Stat(filepath.Join(opts.Source, "cmd", opts.SourceName)); err != nil {
+		return Result{}, fmt.Errorf("%s is not a service source tree: cmd/%s not found", opts.Source, opts.SourceName)
+	}
+	if err := checkTarget(opts.Target); err != nil {
+		return Result{}, err
+	}
+
+	ignore := readIgnore(filepath.Join(opts.Source, ".gitignore"))
+	replace := newReplacer(sourceModule, opts)
+	result := Result{SourceModule: sourceModule}
+
+	err = filepath.WalkDir(opts.Source, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(opts.Source, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if skipped[d.Name()] || ignore.match(rel, d.IsDir()) || isTarget(p, opts.Target) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		// В сгенерированном protobuf коде путь модуля входит в сериализованный
+		// дескриптор с длиной, поэтому файл не меняется: go_package в .proto
+		// заменяется, и make proto генерирует код для нового модуля
+		if !bytes.Contains(data, []byte{0}) && !strings.HasSuffix(rel, ".pb.go") {
+			data = []byte(replace(string(data)))
+		}
+		if rel == "go.mod" {
+			data = replaceModule(data, opts.Module)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		result.Files++
+		return writeFile(opts.Target, renamePath(rel, opts.SourceName, opts.Name), data, info.Mode().Perm())
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to copy sources: %w", err)
+	}
+
+	generated := map[string]string{
+		path.Join("cmd", opts.Name, "components.go"):        "components.go.tmpl",
+		path.Join("internal", "example", "example.go"):      "example.go.tmpl",
+		path.Join("internal", "example", "example_test.go"): "example_test.go.tmpl",
+	}
+	for rel, name := range generated {
+		data, err := render(name, opts)
+		if err != nil {
+			return Result{}, err
+		}
+		result.Files++
+		if err := writeFile(opts.Target, rel, data, 0644); err != nil {
+			return Result{}, err
+		}
+	}
+	return result, nil
+}
+
+// validate проверяет параметры
+func (o Options) validate() error {
+	switch {
+	case o.Source == "" || o.Target == "":
+		return errors.New("source and target directories are required")
+	case o.Module == "" || strings.ContainsAny(o.Module, " \t\"\\"):
+		return fmt.Errorf("invalid module path %q", o.Module)
+	case !validName(o.Name):
+		return fmt.Errorf("invalid service name %q: use letters, digits, '-' and '_'", o.Name)
+	case o.SourceName == "":
+		return errors.New("source service name is required")
+	}
+	return nil
+}
+
+// validName проверяет, что имя подходит для службы, бинарника и директории
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// readModule возвращает путь модуля из go.mod
+func readModule(file string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("%s is not a Go module: %w", filepath.Dir(file), err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`), nil
+		}
+	}
+	return "", fmt.Errorf("module directive not found in %s", file)
+}
+
+// replaceModule заменяет директиву module в go.mod
+func replaceModule(data []byte, module string) []byte {
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "module" {
+			lines[i] = "module " + module
+			break
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// newReplacer возвращает функцию замены за один проход: путь модуля
+// в начале пути импорта ("module/internal/..."), затем имя и отображаемое
+// имя сервиса. Один проход не дает повторно заменить уже замененный текст,
+// если новое имя содержит старое
+func newReplacer(sourceModule string, opts Options) func(string) string {
+	alternatives := []string{`(^|[^\w./-])` + regexp.QuoteMeta(sourceModule) + `/`}
+	if opts.SourceDisplayName != "" {
+		alternatives = append(alternatives, regexp.QuoteMeta(opts.SourceDisplayName))
+	}
+	alternatives = append(alternatives, regexp.QuoteMeta(opts.SourceName))
+	re := regexp.MustCompile("(?m)" + strings.Join(alternatives, "|"))
+
+	displayName := opts.DisplayName
+	if displayName == "" {
+		displayName = opts.Name
+	}
+	return func(s string) string {
+		return re.ReplaceAllStringFunc(s, func(m string) string {
+			switch {
+			case strings.HasSuffix(m, sourceModule+"/"):
+				return strings.TrimSuffix(m, sourceModule+"/") + opts.Module + "/"
+			case opts.SourceDisplayName != "" && m == opts.SourceDisplayName:
+				return displayName
+			default:
+				return opts.Name
+			}
+		})
+	}
+}
+
+// renamePath заменяет элементы пути, совпадающие с именем сервиса
+func renamePath(rel, from, to string) string {
+	parts := strings.Split(rel, "/")
+	for i, part := range parts {
+		if part == from {
+			parts[i] = to
+		}
+	}
+	return path.Join(parts...)
+}
+
+// render заполняет шаблон файла нового проекта
+func render(name string, opts Options) ([]byte, error) {
+	tmpl, err := template.ParseFS(templates, "templates/"+name)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, opts); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// checkTarget проверяет, что директория нового проекта не существует или пустая
+func checkTarget(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("target directory %s is not empty", dir)
+	}
+	return nil
+}
+
+// isTarget проверяет, что p - директория нового проекта внутри исходников
+func isTarget(p, target string) bool {
+	a, err1 := filepath.Abs(p)
+	b, err2 := filepath.Abs(target)
+	return err1 == nil && err2 == nil && a == b
+}
+
+// writeFile записывает файл rel (путь через /) в директорию dir
+func writeFile(dir, rel string, data []byte, perm os.FileMode) error {
+	p := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, perm)
+}
+
+// ignoreRules простые правила .gitignore: имена и шаблоны filepath.Match.
+// Правило с / сравнивается с путем от корня, без / - с именем файла
+type ignoreRules []ignoreRule
+
+// ignoreRule одно правило .gitignore
+type ignoreRule struct {
+	pattern string
+	dirOnly bool
+	rooted  bool
+}
+
+// readIgnore читает правила .gitignore. Отрицания (!) не поддерживаются
+// и пропускаются
+func readIgnore(file string) ignoreRules {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	var rules ignoreRules
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		rule := ignoreRule{pattern: line}
+		if strings.HasSuffix(rule.pattern, "/") {
+			rule.dirOnly = true
+			rule.pattern = strings.TrimSuffix(rule.pattern, "/")
+		}
+		if strings.Contains(rule.pattern, "/") {
+			rule.rooted = true
+			rule.pattern = strings.TrimPrefix(rule.pattern, "/")
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// match проверяет, что путь rel исключен правилами
+func (r ignoreRules) match(rel string, dir bool) bool {
+	for _, rule := range r {
+		if rule.dirOnly && !dir {
+			continue
+		}
+		name := path.Base(rel)
+		if rule.rooted {
+			name = rel
+		}
+		if ok, _ := path.Match(rule.pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSource создает минимальные исходники сервиса
+func writeSource(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		if err := writeFile(dir, rel, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", rel, err)
+		}
+	}
+}
+
+// readTarget читает файл нового проекта
+func readTarget(t *testing.T, dir, rel string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", rel, err)
+	}
+	return string(data)
+}
+
+// TestGenerate проверяет замену модуля и имени сервиса, пропуск
+// игнорируемых файлов и добавление примера
+func TestGenerate(t *testing.T) {
+	source := t.TempDir()
+	binary := "bin\x00demo-svc"
+	writeSource(t, source, map[string]string{
+		"go.mod":                "module demo-svc\n\ngo 1.25\n",
+		".gitignore":            "/requests.jsonl\n*.tmp\n",
+		"requests.jsonl":        "{}\n",
+		"cache.tmp":             "tmp\n",
+		"logs/service.log":      "log\n",
+		"testdata/blob.bin":     binary,
+		"pb/api.pb.go":          "Z.demo-svc/pb\n",
+		"cmd/demo-svc/main.go":  "package main\n\nimport \"demo-svc/internal/app\"\n",
+		"cmd/demo-svc/extra.go": "package main\n\n// github.com/x/demo-svc/v2 не модуль\n",
+		"configs/config.yaml":   "name: demo-svc\ndisplay_name: Demo Service\n",
+	})
+
+	target := filepath.Join(t.TempDir(), "billing")
+	result, err := Generate(Options{
+		Source:            source,
+		Target:            target,
+		Module:            "example.com/billing",
+		Name:              "billing",
+		DisplayName:       "Billing Service",
+		SourceName:        "demo-svc",
+		SourceDisplayName: "Demo Service",
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if result.SourceModule != "demo-svc" {
+		t.Errorf("SourceModule = %q, want demo-svc", result.SourceModule)
+	}
+
+	if got := readTarget(t, target, "go.mod"); !strings.HasPrefix(got, "module example.com/billing\n") {
+		t.Errorf("go.mod = %q, want module example.com/billing", got)
+	}
+	if got := readTarget(t, target, "cmd/billing/main.go"); !strings.Contains(got, `"example.com/billing/internal/app"`) {
+		t.Errorf("main.go import not rewritten: %q", got)
+	}
+	if got := readTarget(t, target, "cmd/billing/extra.go"); !strings.Contains(got, "github.com/x/billing/v2") {
+		t.Errorf("extra.go = %q, want name replaced in foreign path", got)
+	}
+	if got := readTarget(t, target, "configs/config.yaml"); got != "name: billing\ndisplay_name: Billing Service\n" {
+		t.Errorf("config.yaml = %q", got)
+	}
+	if got := readTarget(t, target, "testdata/blob.bin"); got != binary {
+		t.Errorf("binary file changed: %q", got)
+	}
+	if got := readTarget(t, target, "pb/api.pb.go"); got != "Z.demo-svc/pb\n" {
+		t.Errorf("generated protobuf file changed: %q", got)
+	}
+	for _, rel := range []string{"requests.jsonl", "cache.tmp", "logs", "cmd/demo-svc"} {
+		if _, err := os.Stat(filepath.Join(target, rel)); !os.IsNotExist(err) {
+			t.Errorf("%s copied to target, want skipped", rel)
+		}
+	}
+	for _, rel := range []string{"cmd/billing/components.go", "internal/example/example.go", "internal/example/example_test.go"} {
+		if got := readTarget(t, target, rel); !strings.Contains(got, "example.com/billing/internal/") {
+			t.Errorf("%s does not import the new module: %q", rel, got)
+		}
+	}
+
+	// Непустая директория не перезаписывается
+	_, err = Generate(Options{Source: source, Target: target, Module: "m", Name: "n", SourceName: "demo-svc"})
+	if err == nil || !strings.Contains(err.Error(), "not empty") {
+		t.Errorf("Generate() into non-empty target error = %v, want not empty", err)
+	}
+}
+
+// TestGenerate_Invalid проверяет проверку параметров и исходников
+func TestGenerate_Invalid(t *testing.T) {
+	source := t.TempDir()
+	writeSource(t, source, map[string]string{"go.mod": "module m\n"})
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"bad name", Options{Source: source, Target: t.TempDir(), Module: "m", Name: "a b", SourceName: "svc"}},
+		{"bad module", Options{Source: source, Target: t.TempDir(), Module: "a b", Name: "n", SourceName: "svc"}},
+		{"no cmd dir", Options{Source: source, Target: t.TempDir(), Module: "m", Name: "n", SourceName: "svc"}},
+		{"no go.mod", Options{Source: t.TempDir(), Target: t.TempDir(), Module: "m", Name: "n", SourceName: "svc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Generate(tt.opts); err == nil {
+				t.Error("Generate() error = nil, want error")
+			}
+		})
+	}
+}
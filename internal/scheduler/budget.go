@@ -0,0 +1,174 @@
+package scheduler
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"service-boilerplate/internal/events"
+)
+
+// Значения бюджета по умолчанию
+const (
+	defaultBudgetViolations = 3
+	defaultThrottleFactor   = 2
+)
+
+// Budget ограничивает ресурсы одного запуска таймера. Если запуски
+// Violations раз подряд превышают бюджет, таймер замедляется: запускается
+// на каждом ThrottleFactor-м тике. Первый запуск в пределах бюджета
+// возвращает обычный интервал
+type Budget struct {
+	// Wall длительность запуска, 0 - без ограничения
+	Wall time.Duration
+	// CPU процессорное время горутины обработчика (только Linux; горутины,
+	// запущенные обработчиком, не учитываются), 0 - без ограничения
+	CPU time.Duration
+	// Violations сколько запусков подряд сверх бюджета замедляют таймер
+	Violations int
+	// ThrottleFactor во сколько раз увеличивается интервал замедленного таймера
+	ThrottleFactor int
+}
+
+// IsZero сообщает, что бюджет не ограничивает запуски
+func (b Budget) IsZero() bool {
+	return b.Wall <= 0 && b.CPU <= 0
+}
+
+// budgetState состояние бюджета таймера
+type budgetState struct {
+	budget Budget
+	// over запуски сверх бюджета подряд
+	over int32
+	// throttle текущий множитель интервала (0 - таймер не замедлен)
+	throttle int32
+	// skipped тики, пропущенные замедленным таймером с последнего запуска
+	skipped int32
+}
+
+// SetBudgets задает бюджеты ресурсов таймеров по имени.
+// Вызывается до AddTimer
+func (s *Scheduler) SetBudgets(budgets map[string]Budget) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.budgets = budgets
+}
+
+// newBudgetState возвращает состояние бюджета со значениями по умолчанию
+func newBudgetState(b Budget) budgetState {
+	if b.Violations <= 0 {
+		b.Violations = defaultBudgetViolations
+	}
+	if b.ThrottleFactor <= 1 {
+		b.ThrottleFactor = defaultThrottleFactor
+	}
+	return budgetState{budget: b}
+}
+
+// measure выполняет run и возвращает его длительность и процессорное
+// время. ok false, если процессорное время на платформе не измеряется
+// или бюджет CPU не задан
+func (b *budgetState) measure(run func()) (wall, cpu time.Duration, ok bool) {
+	if b.budget.CPU <= 0 {
+		start := time.Now()
+		run()
+		return time.Since(start), 0, false
+	}
+
+	// Поток закрепляется за горутиной, чтобы время потока было временем обработчика
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	before, cpuOK := threadCPUTime()
+	start := time.Now()
+	run()
+	wall = time.Since(start)
+	after, _ := threadCPUTime()
+	if !cpuOK {
+		return wall, 0, false
+	}
+	return wall, after - before, true
+}
+
+// skip сообщает, что тик замедленного таймера пропускается, и возвращает
+// количество тиков до следующего запуска
+func (b *budgetState) skip() (bool, int) {
+	factor := atomic.LoadInt32(&b.throttle)
+	if factor <= 1 {
+		return false, 1
+	}
+	skipped := atomic.AddInt32(&b.skipped, 1)
+	if skipped < factor {
+		return true, int(factor - skipped)
+	}
+	atomic.StoreInt32(&b.skipped, 0)
+	return false, int(factor)
+}
+
+// checkBudget учитывает результат запуска: превышения подряд замедляют
+// таймер, запуск в пределах бюджета снимает замедление
+func (s *Scheduler) checkBudget(name string, timer *Timer, wall, cpu time.Duration, cpuOK bool) {
+	b := &timer.budget
+	exceeded := (b.budget.Wall > 0 && wall > b.budget.Wall) ||
+		(b.budget.CPU > 0 && cpuOK && cpu > b.budget.CPU)
+
+	fields := map[string]interface{}{
+		"timer":   name,
+		"wall_ms": wall.Milliseconds(),
+	}
+	if cpuOK {
+		fields["cpu_ms"] = cpu.Milliseconds()
+	}
+
+	if !exceeded {
+		atomic.StoreInt32(&b.over, 0)
+		if atomic.SwapInt32(&b.throttle, 0) > 1 {
+			atomic.StoreInt32(&b.skipped, 0)
+			s.log.Info("Timer is within resource budget again, throttling lifted", fields)
+			s.publishThrottle(name, timer, false, wall, cpu)
+		}
+		return
+	}
+
+	over := atomic.AddInt32(&b.over, 1)
+	if s.metrics != nil {
+		s.metrics.RecordTimerBudgetExceeded(name)
+	}
+	fields["budget_wall_ms"] = b.budget.Wall.Milliseconds()
+	fields["budget_cpu_ms"] = b.budget.CPU.Milliseconds()
+	fields["violations"] = over
+	s.log.Warn("Timer run exceeded resource budget", fields)
+
+	if int(over) >= b.budget.Violations && atomic.CompareAndSwapInt32(&b.throttle, 0, int32(b.budget.ThrottleFactor)) {
+		fields["interval"] = (timer.period() * time.Duration(b.budget.ThrottleFactor)).String()
+		s.log.Warn("Timer throttled after repeatedly exceeding resource budget", fields)
+		s.publishThrottle(name, timer, true, wall, cpu)
+	}
+}
+
+// publishThrottle записывает метрику и публикует событие
+// events.TypeTimerThrottled о замедлении таймера или его снятии
+func (s *Scheduler) publishThrottle(name string, timer *Timer, throttled bool, wall, cpu time.Duration) {
+	factor := 1
+	if throttled {
+		factor = timer.budget.budget.ThrottleFactor
+	}
+	if s.metrics != nil {
+		s.metrics.SetTimerThrottle(name, factor)
+	}
+
+	s.mu.RLock()
+	bus := s.events
+	s.mu.RUnlock()
+	if bus == nil {
+		return
+	}
+	bus.Publish(events.TypeTimerThrottled, map[string]interface{}{
+		"timer":          name,
+		"throttled":      throttled,
+		"interval":       (timer.period() * time.Duration(factor)).String(),
+		"wall_ms":        wall.Milliseconds(),
+		"cpu_ms":         cpu.Milliseconds(),
+		"budget_wall_ms": timer.budget.budget.Wall.Milliseconds(),
+		"budget_cpu_ms":  timer.budget.budget.CPU.Milliseconds(),
+	})
+}
@@ -0,0 +1,17 @@
+package scheduler
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// threadCPUTime возвращает процессорное время (user + system) текущего
+// потока ОС. Вызывающий закрепляет горутину за потоком (runtime.LockOSThread)
+func threadCPUTime() (time.Duration, bool) {
+	var usage unix.Rusage
+	if err := unix.Getrusage(unix.RUSAGE_THREAD, &usage); err != nil {
+		return 0, false
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano()), true
+}
@@ -0,0 +1,12 @@
+//go:build windows
+// +build windows
+
+package scheduler
+
+import "time"
+
+// threadCPUTime на Windows не поддерживается: бюджет CPU не проверяется,
+// учитывается только длительность запуска
+func threadCPUTime() (time.Duration, bool) {
+	return 0, false
+}
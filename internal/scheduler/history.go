@@ -0,0 +1,174 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"service-boilerplate/internal/store"
+)
+
+// historyBucket bucket хранилища с историей запусков таймеров
+const historyBucket = "scheduler.history"
+
+// DefaultHistorySize сколько последних запусков каждого таймера хранится по умолчанию
+const DefaultHistorySize = 20
+
+// Результаты запуска в RunRecord
+const (
+	OutcomeSuccess  = "success"
+	OutcomeFailed   = "failed"
+	OutcomePanic    = "panic"
+	OutcomeCanceled = "canceled"
+)
+
+// RunRecord результат одного запуска таймера
+type RunRecord struct {
+	RunID    string        `json:"run_id"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	Outcome  string        `json:"outcome"`
+	// Error текст panic или ошибки, переданной в ReportError
+	Error string `json:"error,omitempty"`
+}
+
+// runResultKey ключ контекста результата текущего запуска
+type runResultKey struct{}
+
+// runResult ошибка, которую обработчик сообщил через ReportError
+type runResult struct {
+	mu  sync.Mutex
+	err error
+}
+
+// ReportError отмечает текущий запуск таймера как неуспешный (OutcomeFailed)
+// с текстом err в истории запусков. Обработчик не возвращает ошибку,
+// поэтому сообщает о ней так. Повторный вызов заменяет ошибку;
+// вне обработчика таймера вызов ничего не делает
+func ReportError(ctx context.Context, err error) {
+	r, ok := ctx.Value(runResultKey{}).(*runResult)
+	if !ok || err == nil {
+		return
+	}
+	r.mu.Lock()
+	r.err = err
+	r.mu.Unlock()
+}
+
+// withRunResult возвращает контекст, в который обработчик сообщает ошибку
+func withRunResult(ctx context.Context) (context.Context, *runResult) {
+	r := &runResult{}
+	return context.WithValue(ctx, runResultKey{}, r), r
+}
+
+// record возвращает запись истории для завершенного запуска. err - panic
+// обработчика, ctx - контекст обработчика
+func (r *runResult) record(ctx context.Context, runID string, start time.Time, err error) RunRecord {
+	rec := RunRecord{RunID: runID, Start: start.UTC(), Duration: time.Since(start), Outcome: OutcomeSuccess}
+	r.mu.Lock()
+	reported := r.err
+	r.mu.Unlock()
+	switch {
+	case err != nil:
+		rec.Outcome = OutcomePanic
+		rec.Error = err.Error()
+	case reported != nil:
+		rec.Outcome = OutcomeFailed
+		rec.Error = reported.Error()
+	case ctx.Err() != nil:
+		rec.Outcome = OutcomeCanceled
+		rec.Error = context.Cause(ctx).Error()
+	}
+	return rec
+}
+
+// SetHistory задает, сколько последних запусков каждого таймера хранится
+// (size <= 0 - DefaultHistorySize), и включает сохранение истории
+// в хранилище (SetStore), чтобы она переживала перезапуск. Вызывается до Start
+func (s *Scheduler) SetHistory(size int, persist bool) {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.historySize = size
+	s.persistHistory = persist
+}
+
+// GetTimerHistory возвращает последние запуски таймера, начиная с самого нового
+func (s *Scheduler) GetTimerHistory(name string) ([]RunRecord, error) {
+	s.mu.RLock()
+	timer, ok := s.timers[name]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTimerNotFound, name)
+	}
+
+	timer.stateMu.RLock()
+	defer timer.stateMu.RUnlock()
+	history := make([]RunRecord, len(timer.history))
+	for i, rec := range timer.history {
+		history[len(history)-1-i] = rec
+	}
+	return history, nil
+}
+
+// addHistory добавляет запись в историю таймера, вытесняя самые старые
+// записи сверх лимита, и сохраняет историю в хранилище, если это включено
+func (s *Scheduler) addHistory(timer *Timer, rec RunRecord) {
+	s.mu.RLock()
+	size := s.historySize
+	st := s.store
+	persist := s.persistHistory
+	s.mu.RUnlock()
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+
+	timer.stateMu.Lock()
+	timer.history = append(timer.history, rec)
+	if over := len(timer.history) - size; over > 0 {
+		timer.history = append([]RunRecord(nil), timer.history[over:]...)
+	}
+	history := append([]RunRecord(nil), timer.history...)
+	timer.stateMu.Unlock()
+
+	if st == nil || !persist {
+		return
+	}
+	if err := st.PutJSON(historyBucket, timer.name, history); err != nil {
+		s.log.Warn("Failed to save timer history", map[string]interface{}{
+			"timer": timer.name,
+			"error": err.Error(),
+		})
+	}
+}
+
+// restoreHistory загружает историю запусков из хранилища. Вызывается под s.mu
+func (s *Scheduler) restoreHistory() {
+	if s.store == nil || !s.persistHistory {
+		return
+	}
+	for name, timer := range s.timers {
+		var history []RunRecord
+		err := s.store.GetJSON(historyBucket, name, &history)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			s.log.Warn("Failed to restore timer history", map[string]interface{}{
+				"timer": name,
+				"error": err.Error(),
+			})
+			continue
+		}
+		if over := len(history) - s.historySize; s.historySize > 0 && over > 0 {
+			history = history[over:]
+		}
+		timer.stateMu.Lock()
+		timer.history = history
+		timer.stateMu.Unlock()
+	}
+}
@@ -0,0 +1,126 @@
+package scheduler
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultStarvationTimeout время ожидания, после которого запуск с низким
+// приоритетом получает слот раньше запусков с более высоким
+const defaultStarvationTimeout = 60 * time.Second
+
+// limiter ограничивает число одновременно выполняемых обработчиков.
+// Освободившийся слот получает ожидающий с наибольшим приоритетом,
+// при равных приоритетах - первый в очереди (FIFO). Запуск, ожидающий
+// дольше starveAfter, обслуживается первым независимо от приоритета,
+// поэтому частые критичные таймеры не могут бесконечно обгонять остальные
+type limiter struct {
+	mu          sync.Mutex
+	size        int
+	active      int
+	starveAfter time.Duration
+	waiters     list.List // *waiter в порядке постановки в очередь
+	// onQueue вызывается с новой длиной очереди при ее изменении
+	onQueue func(queued int)
+}
+
+// waiter запуск, ожидающий слот
+type waiter struct {
+	// ready закрывается при передаче слота
+	ready    chan struct{}
+	priority int
+	since    time.Time
+}
+
+// newLimiter создает ограничитель на size одновременных запусков.
+// onQueue может быть nil
+func newLimiter(size int, starveAfter time.Duration, onQueue func(queued int)) *limiter {
+	return &limiter{size: size, starveAfter: starveAfter, onQueue: onQueue}
+}
+
+// acquire занимает слот, ожидая в очереди с приоритетом priority. При
+// отмене ctx ожидание прекращается с ошибкой контекста
+func (l *limiter) acquire(ctx context.Context, priority int) error {
+	l.mu.Lock()
+	if l.active < l.size && l.waiters.Len() == 0 {
+		l.active++
+		l.mu.Unlock()
+		return nil
+	}
+	w := &waiter{ready: make(chan struct{}), priority: priority, since: time.Now()}
+	elem := l.waiters.PushBack(w)
+	l.queueChanged()
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		select {
+		case <-w.ready:
+			// Слот передан одновременно с отменой: возвращаем его следующему
+			l.mu.Unlock()
+			l.release()
+		default:
+			l.waiters.Remove(elem)
+			l.queueChanged()
+			l.mu.Unlock()
+		}
+		return ctx.Err()
+	}
+}
+
+// release освобождает слот: он передается следующему ожидающему
+func (l *limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if next := l.next(); next != nil {
+		l.waiters.Remove(next)
+		l.queueChanged()
+		close(next.Value.(*waiter).ready)
+		return
+	}
+	l.active--
+}
+
+// next выбирает ожидающего, которому передается слот: первого из ждущих
+// дольше starveAfter, иначе первого с наибольшим приоритетом. Вызывается под mu
+func (l *limiter) next() *list.Element {
+	var best *list.Element
+	now := time.Now()
+	for e := l.waiters.Front(); e != nil; e = e.Next() {
+		w := e.Value.(*waiter)
+		// Очередь упорядочена по времени постановки: первый голодающий ждет дольше всех
+		if l.starveAfter > 0 && now.Sub(w.since) >= l.starveAfter {
+			return e
+		}
+		if best == nil || w.priority > best.Value.(*waiter).priority {
+			best = e
+		}
+	}
+	return best
+}
+
+// setStarveAfter меняет время ожидания до повышения приоритета
+func (l *limiter) setStarveAfter(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.starveAfter = d
+}
+
+// queued возвращает число ожидающих слота
+func (l *limiter) queued() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.waiters.Len()
+}
+
+// queueChanged сообщает новую длину очереди. Вызывается под mu
+func (l *limiter) queueChanged() {
+	if l.onQueue != nil {
+		l.onQueue(l.waiters.Len())
+	}
+}
@@ -0,0 +1,66 @@
+package scheduler
+
+import "time"
+
+// MisfirePolicy поведение таймера, интервал которого истек, пока сервис
+// не работал. Пропуск определяется по времени последнего запуска,
+// сохраненному в хранилище (SetStore)
+type MisfirePolicy string
+
+const (
+	// MisfireSkip пропущенные запуски не выполняются, первый запуск -
+	// через интервал после Start
+	MisfireSkip MisfirePolicy = "skip"
+	// MisfireRun один запуск выполняется сразу после Start, сколько бы
+	// интервалов ни было пропущено
+	MisfireRun MisfirePolicy = "run"
+)
+
+// SetMisfirePolicy задает политику пропущенных за время простоя запусков:
+// def для всех таймеров (пустая - MisfireSkip) и policies для таймеров
+// по имени. Вызывается до AddTimer
+func (s *Scheduler) SetMisfirePolicy(def MisfirePolicy, policies map[string]MisfirePolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.misfire = def
+	s.misfires = policies
+}
+
+// misfirePolicy возвращает политику пропущенных запусков таймера name.
+// Вызывается под s.mu
+func (s *Scheduler) misfirePolicy(name string) MisfirePolicy {
+	if p := s.misfires[name]; p != "" {
+		return p
+	}
+	if s.misfire != "" {
+		return s.misfire
+	}
+	return MisfireSkip
+}
+
+// checkMisfire проверяет, истек ли интервал таймера с последнего
+// запуска до Start, и отмечает таймер для запуска сразу по MisfireRun.
+// Вызывается под s.mu после restoreLastRuns
+func (s *Scheduler) checkMisfire(name string, timer *Timer) {
+	if !timer.at.IsZero() {
+		return
+	}
+	timer.stateMu.RLock()
+	last := timer.lastRun
+	timer.stateMu.RUnlock()
+	interval := timer.period()
+	if last.IsZero() || interval <= 0 {
+		return
+	}
+	elapsed := time.Since(last)
+	if elapsed < interval {
+		return
+	}
+	timer.catchUp = timer.misfire == MisfireRun
+	s.log.Info("Timer missed runs while service was down", map[string]interface{}{
+		"timer":    name,
+		"last_run": last,
+		"missed":   int(elapsed / interval),
+		"policy":   string(timer.misfire),
+	})
+}
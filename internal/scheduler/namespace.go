@@ -0,0 +1,167 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// NamespaceSeparator отделяет пространство имен от имени таймера:
+// tenant-a/report - таймер report пространства tenant-a
+const NamespaceSeparator = "/"
+
+// ErrNamespaceDisabled возвращается при ручном запуске таймера
+// отключенного пространства имен
+var ErrNamespaceDisabled = errors.New("timer namespace is disabled")
+
+// ErrNamespaceNotFound возвращается при обращении к пространству имен
+// без таймеров и настроек
+var ErrNamespaceNotFound = errors.New("timer namespace not found")
+
+// Namespace возвращает пространство имен таймера name: часть до первого
+// NamespaceSeparator или пустую строку для таймеров без пространства
+func Namespace(name string) string {
+	ns, _, ok := strings.Cut(name, NamespaceSeparator)
+	if !ok {
+		return ""
+	}
+	return ns
+}
+
+// NamespaceOptions настройки пространства имен таймеров
+type NamespaceOptions struct {
+	// MaxConcurrentRuns лимит одновременных запусков таймеров пространства
+	// (действует вместе с общим лимитом), 0 - без ограничения
+	MaxConcurrentRuns int
+	// Disabled пространство отключено при запуске сервиса
+	Disabled bool
+}
+
+// namespace состояние пространства имен таймеров
+type namespace struct {
+	name     string
+	limit    *limiter
+	size     int
+	disabled int32
+	running  int32
+}
+
+// NamespaceInfo снимок состояния пространства имен
+type NamespaceInfo struct {
+	Name              string
+	Timers            int
+	Running           int
+	MaxConcurrentRuns int
+	Enabled           bool
+}
+
+// SetNamespaces задает лимиты и начальное состояние пространств имен.
+// Вызывается до Start
+func (s *Scheduler) SetNamespaces(namespaces map[string]NamespaceOptions) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, opts := range namespaces {
+		ns := s.namespaceLocked(name)
+		ns.size = opts.MaxConcurrentRuns
+		ns.limit = nil
+		if opts.MaxConcurrentRuns > 0 {
+			ns.limit = newLimiter(opts.MaxConcurrentRuns, s.starveAfter, nil)
+		}
+		var disabled int32
+		if opts.Disabled {
+			disabled = 1
+		}
+		atomic.StoreInt32(&ns.disabled, disabled)
+		if s.metrics != nil {
+			s.metrics.SetNamespaceEnabled(name, !opts.Disabled)
+		}
+	}
+}
+
+// namespaceLocked возвращает состояние пространства имен, создавая его
+// при первом обращении. Вызывается под s.mu (запись)
+func (s *Scheduler) namespaceLocked(name string) *namespace {
+	if s.namespaces == nil {
+		s.namespaces = make(map[string]*namespace)
+	}
+	ns, ok := s.namespaces[name]
+	if !ok {
+		ns = &namespace{name: name}
+		s.namespaces[name] = ns
+	}
+	return ns
+}
+
+// EnableNamespace возобновляет запуски таймеров пространства имен
+func (s *Scheduler) EnableNamespace(name string) error {
+	return s.setNamespaceEnabled(name, true)
+}
+
+// DisableNamespace отключает пространство имен: таймеры пропускают тики,
+// ручной запуск возвращает ErrNamespaceDisabled. Выполняющиеся запуски
+// не прерываются
+func (s *Scheduler) DisableNamespace(name string) error {
+	return s.setNamespaceEnabled(name, false)
+}
+
+// setNamespaceEnabled переключает пространство имен
+func (s *Scheduler) setNamespaceEnabled(name string, enabled bool) error {
+	s.mu.RLock()
+	ns, ok := s.namespaces[name]
+	s.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNamespaceNotFound, name)
+	}
+
+	var disabled int32 = 1
+	if enabled {
+		disabled = 0
+	}
+	if atomic.SwapInt32(&ns.disabled, disabled) == disabled {
+		return nil
+	}
+	if s.metrics != nil {
+		s.metrics.SetNamespaceEnabled(name, enabled)
+	}
+	msg := "Timer namespace disabled"
+	if enabled {
+		msg = "Timer namespace enabled"
+	}
+	s.log.Info(msg, map[string]interface{}{"namespace": name})
+	return nil
+}
+
+// ListNamespaces возвращает пространства имен с таймерами или настройками,
+// отсортированные по имени. Таймеры без пространства не входят в список
+func (s *Scheduler) ListNamespaces() []NamespaceInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	infos := make([]NamespaceInfo, 0, len(s.namespaces))
+	for name, ns := range s.namespaces {
+		if name == "" {
+			continue
+		}
+		info := NamespaceInfo{
+			Name:              name,
+			Running:           int(atomic.LoadInt32(&ns.running)),
+			MaxConcurrentRuns: ns.size,
+			Enabled:           atomic.LoadInt32(&ns.disabled) == 0,
+		}
+		for _, t := range s.timers {
+			if t.ns == ns {
+				info.Timers++
+			}
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// enabled сообщает, что пространство имен не отключено (nil - таймер без пространства)
+func (ns *namespace) enabled() bool {
+	return ns == nil || atomic.LoadInt32(&ns.disabled) == 0
+}
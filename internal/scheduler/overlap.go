@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// OverlapPolicy поведение таймера, тик которого пришел, пока выполняется
+// предыдущий запуск
+type OverlapPolicy string
+
+const (
+	// OverlapSkip тик отбрасывается и учитывается как пропущенный,
+	// следующий запуск - по первому тику после завершения
+	OverlapSkip OverlapPolicy = "skip"
+	// OverlapQueue тик ставится в очередь таймера, запуски из очереди
+	// выполняются друг за другом. Тики сверх MaxQueuedRuns отбрасываются
+	OverlapQueue OverlapPolicy = "queue"
+	// OverlapConcurrent каждый тик запускает обработчик, даже если
+	// предыдущий запуск еще выполняется
+	OverlapConcurrent OverlapPolicy = "concurrent"
+)
+
+// MaxQueuedRuns сколько запусков таймера с OverlapQueue ждет в очереди
+const MaxQueuedRuns = 10
+
+// SetOverlapPolicy задает политику перекрытия запусков: def для всех
+// таймеров (пустая - OverlapSkip) и policies для таймеров по имени.
+// Вызывается до AddTimer
+func (s *Scheduler) SetOverlapPolicy(def OverlapPolicy, policies map[string]OverlapPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overlap = def
+	s.overlaps = policies
+}
+
+// overlapPolicy возвращает политику перекрытия таймера name. Вызывается под s.mu
+func (s *Scheduler) overlapPolicy(name string) OverlapPolicy {
+	if p := s.overlaps[name]; p != "" {
+		return p
+	}
+	if s.overlap != "" {
+		return s.overlap
+	}
+	return OverlapSkip
+}
+
+// dispatcher выполняет запуски таймера по тикам в соответствии
+// с его политикой перекрытия, не блокируя цикл тиков
+type dispatcher struct {
+	s     *Scheduler
+	name  string
+	timer *Timer
+	runs  sync.WaitGroup
+	// busy выполняется запуск (OverlapSkip)
+	busy int32
+	// pending очередь запусков (OverlapQueue)
+	pending chan struct{}
+	done    chan struct{}
+}
+
+// newDispatcher создает исполнитель запусков таймера. Для OverlapQueue
+// запускается горутина, выполняющая запуски из очереди
+func (s *Scheduler) newDispatcher(name string, timer *Timer) *dispatcher {
+	d := &dispatcher{s: s, name: name, timer: timer, done: make(chan struct{})}
+	if timer.overlap == OverlapQueue {
+		d.pending = make(chan struct{}, MaxQueuedRuns)
+		d.runs.Add(1)
+		go d.drain()
+	}
+	return d
+}
+
+// tick запускает обработчик по тику или отбрасывает тик
+func (d *dispatcher) tick() {
+	switch d.timer.overlap {
+	case OverlapConcurrent:
+		d.runs.Add(1)
+		go func() {
+			defer d.runs.Done()
+			d.s.executeTimerWithRecovery(d.name, d.timer)
+		}()
+	case OverlapQueue:
+		select {
+		case d.pending <- struct{}{}:
+		default:
+			d.s.missTicks(d.name, d.timer, 1)
+		}
+	default:
+		if !atomic.CompareAndSwapInt32(&d.busy, 0, 1) {
+			d.s.missTicks(d.name, d.timer, 1)
+			return
+		}
+		d.runs.Add(1)
+		go func() {
+			defer d.runs.Done()
+			defer atomic.StoreInt32(&d.busy, 0)
+			d.s.executeTimerWithRecovery(d.name, d.timer)
+		}()
+	}
+}
+
+// drain выполняет запуски из очереди по одному до остановки
+func (d *dispatcher) drain() {
+	defer d.runs.Done()
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-d.pending:
+			select {
+			case <-d.done:
+				return
+			default:
+			}
+			d.s.executeTimerWithRecovery(d.name, d.timer)
+		}
+	}
+}
+
+// stop прекращает запуски из очереди и ждет завершения выполняющихся
+func (d *dispatcher) stop() {
+	close(d.done)
+	d.runs.Wait()
+}
@@ -0,0 +1,176 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// pool выполняет обработчики на ограниченном числе горутин-исполнителей.
+// Запуск ждет свободного исполнителя в порядке очереди; приоритеты
+// учитывает лимит одновременных запусков (SetMaxConcurrentRuns), слот
+// которого занимается до постановки в очередь пула. Исполнители создаются
+// при первом запуске и завершаются при остановке планировщика; после
+// остановки пул запуски не принимает
+type pool struct {
+	size int
+	// jobs без буфера: задание передается только свободному исполнителю,
+	// поэтому при остановке в канале не остается невыполненных
+	jobs chan *poolJob
+
+	mu      sync.Mutex
+	quit    chan struct{}
+	stopped bool
+
+	queued int32
+	busy   int32
+	// onChange вызывается с числом ожидающих запусков и занятых исполнителей
+	onChange func(queued, busy int)
+}
+
+// poolJob обработчик, переданный исполнителю
+type poolJob struct {
+	run  func()
+	done chan struct{}
+}
+
+// workerKey ключ контекста обработчика, выполняющегося на исполнителе пула
+type workerKey struct{}
+
+// newPool создает пул из size исполнителей. onChange может быть nil
+func newPool(size int, onChange func(queued, busy int)) *pool {
+	return &pool{size: size, jobs: make(chan *poolJob), onChange: onChange}
+}
+
+// run выполняет fn на исполнителе пула и ждет завершения. fn получает ctx
+// с отметкой исполнителя: вложенный запуск из обработчика (Execute
+// с контекстом обработчика) выполняется сразу на том же исполнителе,
+// иначе при занятых исполнителях он ждал бы вечно. queued (если не nil)
+// отмечает ожидание исполнителя в состоянии таймера. При отмене ctx
+// до передачи исполнителю fn не выполняется и возвращается ошибка
+// контекста, после остановки пула - ErrNotRunning
+func (p *pool) run(ctx context.Context, queued *int32, fn func(ctx context.Context)) error {
+	if ctx.Value(workerKey{}) == p {
+		fn(ctx)
+		return nil
+	}
+	quit, err := p.start()
+	if err != nil {
+		return err
+	}
+	job := p.job(ctx, fn)
+
+	if queued != nil {
+		atomic.AddInt32(queued, 1)
+	}
+	atomic.AddInt32(&p.queued, 1)
+	p.changed()
+	select {
+	case p.jobs <- job:
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-quit:
+		err = ErrNotRunning
+	}
+	atomic.AddInt32(&p.queued, -1)
+	p.changed()
+	if queued != nil {
+		atomic.AddInt32(queued, -1)
+	}
+	if err != nil {
+		return err
+	}
+	<-job.done
+	return nil
+}
+
+// runOrInline выполняет fn на свободном исполнителе, а если свободного
+// нет - в вызывающей горутине, не ожидая в очереди. Так ручной запуск
+// (Trigger) из обработчика не ждет исполнителя, занятого им самим
+func (p *pool) runOrInline(ctx context.Context, fn func(ctx context.Context)) error {
+	if ctx.Value(workerKey{}) == p {
+		fn(ctx)
+		return nil
+	}
+	quit, err := p.start()
+	if err != nil {
+		return err
+	}
+	job := p.job(ctx, fn)
+	select {
+	case p.jobs <- job:
+		<-job.done
+	case <-quit:
+		return ErrNotRunning
+	default:
+		fn(ctx)
+	}
+	return nil
+}
+
+// job создает задание, которое выполняет fn с отметкой исполнителя в ctx
+func (p *pool) job(ctx context.Context, fn func(ctx context.Context)) *poolJob {
+	ctx = context.WithValue(ctx, workerKey{}, p)
+	return &poolJob{run: func() { fn(ctx) }, done: make(chan struct{})}
+}
+
+// start запускает исполнителей, если они не работают, и возвращает
+// канал их остановки. После stop возвращает ErrNotRunning
+func (p *pool) start() (chan struct{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return nil, ErrNotRunning
+	}
+	if p.quit == nil {
+		p.quit = make(chan struct{})
+		for i := 0; i < p.size; i++ {
+			go p.worker(p.quit)
+		}
+	}
+	return p.quit, nil
+}
+
+// stop завершает исполнителей без ожидания: занятые завершаются после
+// своего обработчика. Ожидающие и следующие запуски получают ErrNotRunning
+func (p *pool) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return
+	}
+	p.stopped = true
+	if p.quit != nil {
+		close(p.quit)
+	}
+}
+
+// worker выполняет задания до остановки пула
+func (p *pool) worker(quit chan struct{}) {
+	for {
+		select {
+		case <-quit:
+			return
+		case job := <-p.jobs:
+			atomic.AddInt32(&p.busy, 1)
+			p.changed()
+			p.execute(job)
+			atomic.AddInt32(&p.busy, -1)
+			p.changed()
+		}
+	}
+}
+
+// execute выполняет задание. Panic обработчика перехватывается call,
+// done закрывается в любом случае, чтобы запуск не ждал вечно
+func (p *pool) execute(job *poolJob) {
+	defer close(job.done)
+	job.run()
+}
+
+// changed сообщает число ожидающих запусков и занятых исполнителей
+func (p *pool) changed() {
+	if p.onChange != nil {
+		p.onChange(int(atomic.LoadInt32(&p.queued)), int(atomic.LoadInt32(&p.busy)))
+	}
+}
@@ -0,0 +1,126 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTimerReconfigured причина отмены запуска, прерванного изменением
+// настроек таймера (context.Cause контекста обработчика)
+var ErrTimerReconfigured = errors.New("timer reconfigured")
+
+// ReloadPolicy поведение выполняющегося запуска при изменении
+// интервала или таймаута таймера
+type ReloadPolicy string
+
+const (
+	// ReloadFinish запуск завершается, новые настройки действуют со следующего
+	ReloadFinish ReloadPolicy = "finish"
+	// ReloadCancel контекст запуска отменяется, таймер сразу перепланируется
+	ReloadCancel ReloadPolicy = "cancel"
+)
+
+// TimerSettings настройки таймера, которые меняются без перезапуска
+type TimerSettings struct {
+	// Interval интервал запусков, 0 - интервал из AddTimer
+	Interval time.Duration
+	// Timeout длительность запуска, после которой контекст обработчика
+	// отменяется, 0 - без ограничения
+	Timeout time.Duration
+}
+
+// SetTimerSettings задает интервал и таймаут таймеров по имени из
+// конфигурации. Вызывается до AddTimer
+func (s *Scheduler) SetTimerSettings(settings map[string]TimerSettings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings = settings
+}
+
+// Reconfigure применяет новые настройки таймера name и сообщает, изменились
+// ли они. Отсчет интервала начинается заново, как только таймер свободен.
+// Выполняющийся запуск при ReloadFinish завершается со старым таймаутом,
+// при ReloadCancel его контекст отменяется с причиной ErrTimerReconfigured
+func (s *Scheduler) Reconfigure(name string, settings TimerSettings, policy ReloadPolicy) (bool, error) {
+	s.mu.RLock()
+	timer, ok := s.timers[name]
+	s.mu.RUnlock()
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrTimerNotFound, name)
+	}
+
+	// Однократный таймер остается без интервала
+	interval := settings.Interval
+	if interval <= 0 || !timer.at.IsZero() {
+		interval = timer.baseInterval
+	}
+	timer.stateMu.Lock()
+	if timer.interval == interval && timer.timeout == settings.Timeout {
+		timer.stateMu.Unlock()
+		return false, nil
+	}
+	timer.interval = interval
+	timer.timeout = settings.Timeout
+	var cancels []context.CancelCauseFunc
+	if policy == ReloadCancel {
+		for _, cancel := range timer.runs {
+			cancels = append(cancels, cancel)
+		}
+	}
+	timer.stateMu.Unlock()
+
+	for _, cancel := range cancels {
+		cancel(ErrTimerReconfigured)
+	}
+	select {
+	case timer.reset <- struct{}{}:
+	default:
+	}
+	s.log.Info("Timer reconfigured", map[string]interface{}{
+		"timer":         name,
+		"interval":      interval.String(),
+		"timeout":       settings.Timeout.String(),
+		"policy":        string(policy),
+		"canceled_runs": len(cancels),
+	})
+	return true, nil
+}
+
+// runContext возвращает контекст запуска с таймаутом таймера, который
+// Reconfigure может отменить. Возвращенная функция освобождает контекст
+func (t *Timer) runContext(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(parent)
+	t.stateMu.Lock()
+	timeout := t.timeout
+	if t.runs == nil {
+		t.runs = make(map[int64]context.CancelCauseFunc)
+	}
+	t.runSeq++
+	id := t.runSeq
+	t.runs[id] = cancel
+	t.stateMu.Unlock()
+
+	stop := func() {
+		t.stateMu.Lock()
+		delete(t.runs, id)
+		t.stateMu.Unlock()
+		cancel(nil)
+	}
+	if timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancelTimeout := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancelTimeout()
+		stop()
+	}
+}
+
+// period возвращает текущий интервал таймера
+func (t *Timer) period() time.Duration {
+	t.stateMu.RLock()
+	defer t.stateMu.RUnlock()
+	return t.interval
+}
@@ -3,16 +3,29 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime/debug"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/execenv"
 	"service-boilerplate/internal/logger"
 	"service-boilerplate/internal/metrics"
+	"service-boilerplate/internal/runid"
+	"service-boilerplate/internal/store"
 )
 
+// stateBucket bucket хранилища с временем последнего запуска таймеров
+const stateBucket = "scheduler.last_run"
+
 // Handler функция-обработчик таймера
 type Handler func(ctx context.Context)
 
@@ -25,33 +38,308 @@ type Timer struct {
 	maxRestarts    int
 	backoffSeconds int
 	running        int32
+	queued         int32
+	paused         int32
+	missedTicks    int64
+	missedWarned   int32
+	env            execenv.Env
+	priority       int
+	// readOnly таймер отключен режимом только для чтения
+	readOnly bool
+	// budget бюджет ресурсов запуска и замедление за его превышение
+	budget budgetState
+	// ns пространство имен таймера (nil - таймер без пространства)
+	ns *namespace
+	// baseInterval интервал из AddTimer
+	baseInterval time.Duration
+	// reset сигнал runTimer начать отсчет интервала заново (Reconfigure)
+	reset chan struct{}
+	// removed закрывается RemoveTimer: горутина таймера завершается
+	removed chan struct{}
+	// at время запуска однократного таймера (AddOneShot), нулевое у периодических
+	at time.Time
+	// overlap политика тиков, пришедших во время запуска
+	overlap OverlapPolicy
+	// misfire политика запусков, пропущенных за время простоя сервиса;
+	// catchUp - таймер выполняется сразу после Start (MisfireRun)
+	misfire MisfirePolicy
+	catchUp bool
+
+	// stateMu защищает интервал, таймаут, выполняющиеся запуски, время
+	// последнего и следующего запуска и историю
+	stateMu sync.RWMutex
+	lastRun time.Time
+	nextRun time.Time
+	// history последние запуски, от старых к новым
+	history []RunRecord
+	// timeout ограничение длительности запуска, 0 - без ограничения
+	timeout time.Duration
+	// runs отмена выполняющихся запусков (Reconfigure с ReloadCancel)
+	runs   map[int64]context.CancelCauseFunc
+	runSeq int64
+}
+
+// Состояния таймера для TimerInfo
+const (
+	StateStopped  = "stopped"
+	StateIdle     = "idle"
+	StateRunning  = "running"
+	StateQueued   = "queued"
+	StatePaused   = "paused"
+	StateStandby  = "standby"
+	StateDisabled = "disabled"
+	StateReadOnly = "read-only"
+	// StateNamespaceDisabled пространство имен таймера отключено
+	StateNamespaceDisabled = "namespace-disabled"
+)
+
+// TimerInfo снимок состояния таймера
+type TimerInfo struct {
+	Name       string
+	Interval   time.Duration
+	LastRun    time.Time
+	NextRun    time.Time
+	PanicCount int
+	// MissedTicks тики, отброшенные политикой перекрытия, пока обработчик
+	// выполнялся дольше интервала
+	MissedTicks int
+	// Throttle множитель интервала таймера, замедленного за превышение
+	// бюджета ресурсов (0 - таймер не замедлен)
+	Throttle int
+	State    string
+}
+
+// FireTimes возвращает n ближайших запусков по расписанию: NextRun
+// и далее с шагом Interval. Таймер без NextRun (остановлен, приостановлен,
+// отключен, standby) запусков не имеет, однократный (Interval 0) - один
+// запуск. Замедленный таймер запускается с шагом Interval*Throttle. Тики,
+// пропущенные во время долгого выполнения, и backoff после panic не учитываются
+func (i TimerInfo) FireTimes(n int) []time.Time {
+	if i.NextRun.IsZero() || n <= 0 {
+		return nil
+	}
+	if i.Interval <= 0 {
+		return []time.Time{i.NextRun}
+	}
+	step := i.Interval
+	if i.Throttle > 1 {
+		step *= time.Duration(i.Throttle)
+	}
+	times := make([]time.Time, n)
+	for k := range times {
+		times[k] = i.NextRun.Add(time.Duration(k) * step)
+	}
+	return times
+}
+
+// Recorder записывает метрики планировщика. Реализуется *metrics.Server,
+// в тестах может быть заменен моком из testutil/mocks
+type Recorder interface {
+	RecordTimerRun(timerName string)
+	RecordTimerPanic(timerName string)
+	RecordTimerDuration(timerName string, duration time.Duration, traceID string)
+	RecordTimerTicksMissed(timerName string, count int)
+	RecordTimerQueueWait(timerName string, wait time.Duration)
+	SetTimerRunsQueued(count int)
+	SetTimerHandlerPool(queued, busy int)
+	RecordTimerBudgetExceeded(timerName string)
+	SetTimerThrottle(timerName string, factor int)
+	RecordNamespaceRun(namespace, result string)
+	SetNamespaceEnabled(namespace string, enabled bool)
+	IncActiveTimers()
+	DecActiveTimers()
 }
 
+var _ Recorder = (*metrics.Server)(nil)
+
+// Runner методы планировщика, которыми пользуется код сервиса: регистрация
+// и запуск таймеров. Код, принимающий Runner вместо *Scheduler, можно
+// тестировать с моком из testutil/mocks без реальных тикеров
+type Runner interface {
+	AddTimer(name string, interval time.Duration, handler Handler) error
+	AddOneShot(name string, at time.Time, handler Handler) error
+	RemoveTimer(name string) error
+	Trigger(name string) error
+	Execute(ctx context.Context, name string, handler Handler) error
+	Pause(name string) error
+	Resume(name string) error
+	ListTimers() []TimerInfo
+}
+
+var _ Runner = (*Scheduler)(nil)
+
 // Scheduler управляет таймерами
 type Scheduler struct {
 	mu             sync.RWMutex
 	timers         map[string]*Timer
 	log            *logger.Logger
-	metrics        *metrics.Server
+	metrics        Recorder
+	store          *store.Store
+	events         *events.Bus
+	tracer         trace.Tracer
+	gate           func() bool
+	limit          *limiter
+	pool           *pool
+	starveAfter    time.Duration
+	envs           map[string]execenv.Env
+	priorities     map[string]int
+	overlap        OverlapPolicy
+	overlaps       map[string]OverlapPolicy
+	misfire        MisfirePolicy
+	misfires       map[string]MisfirePolicy
+	budgets        map[string]Budget
+	settings       map[string]TimerSettings
+	namespaces     map[string]*namespace
+	readOnly       bool
+	readOnlySafe   map[string]bool
 	wg             sync.WaitGroup
 	ctx            context.Context
 	cancel         context.CancelFunc
 	maxRestarts    int
 	backoffSeconds int
 	activeTimers   int32
+	historySize    int
+	persistHistory bool
+	// restoredAt время снимка, примененного последним (Restore)
+	restoredAt time.Time
 }
 
-// New создает новый планировщик
-func New(log *logger.Logger, metricsServer *metrics.Server, maxRestarts, backoffSeconds int) *Scheduler {
+// New создает новый планировщик. recorder может быть nil
+func New(log *logger.Logger, recorder Recorder, maxRestarts, backoffSeconds int) *Scheduler {
 	return &Scheduler{
 		timers:         make(map[string]*Timer),
 		log:            log,
-		metrics:        metricsServer,
+		metrics:        recorder,
 		maxRestarts:    maxRestarts,
 		backoffSeconds: backoffSeconds,
+		starveAfter:    defaultStarvationTimeout,
+		historySize:    DefaultHistorySize,
 	}
 }
 
+// SetStore включает сохранение времени последнего запуска таймеров.
+// Сохраненное время восстанавливается при Start
+func (s *Scheduler) SetStore(st *store.Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = st
+}
+
+// SetEvents включает публикацию событий выполнения таймеров (events.TypeTimerRun)
+func (s *Scheduler) SetEvents(bus *events.Bus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = bus
+}
+
+// SetTracer включает трассировку: каждый запуск обработчика получает
+// спан, контекст которого передается обработчику. Запуски по расписанию
+// начинают новую трассировку, panic и таймаут отмечаются статусом спана
+func (s *Scheduler) SetTracer(tracer trace.Tracer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tracer = tracer
+}
+
+// SetGate задает условие запуска таймеров по расписанию (например,
+// лидерство экземпляра). Пока gate возвращает false, тики пропускаются.
+// Ручной Trigger не зависит от gate
+func (s *Scheduler) SetGate(gate func() bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gate = gate
+}
+
+// SetMaxConcurrentRuns ограничивает число обработчиков, выполняемых
+// одновременно всеми таймерами, Trigger и Execute. Запуски сверх лимита
+// ждут слот в порядке приоритета (SetPriorities), при равных приоритетах -
+// в порядке очереди. 0 - без ограничения. Вызывается до Start
+func (s *Scheduler) SetMaxConcurrentRuns(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limit = nil
+	if n > 0 {
+		s.limit = newLimiter(n, s.starveAfter, func(queued int) {
+			if s.metrics != nil {
+				s.metrics.SetTimerRunsQueued(queued)
+			}
+		})
+	}
+}
+
+// SetMaxConcurrentHandlers включает выполнение обработчиков таймеров,
+// Trigger и Execute на пуле из n горутин-исполнителей вместо горутины
+// каждого запуска. Запуски сверх n ждут свободного исполнителя в порядке
+// очереди. Execute с контекстом обработчика выполняется на исполнителе
+// вызвавшего обработчика, а Trigger без свободного исполнителя - в своей
+// горутине, поэтому вложенные запуски не ждут вечно. 0 - без пула.
+// Вызывается до Start
+func (s *Scheduler) SetMaxConcurrentHandlers(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pool != nil {
+		s.pool.stop()
+	}
+	s.pool = nil
+	if n > 0 {
+		s.pool = newPool(n, func(queued, busy int) {
+			if s.metrics != nil {
+				s.metrics.SetTimerHandlerPool(queued, busy)
+			}
+		})
+	}
+}
+
+// SetPriorities задает приоритеты таймеров по имени для очереди лимита
+// одновременных запусков: при освобождении слота первым запускается
+// таймер с большим приоритетом. По умолчанию приоритет 0, Execute
+// выполняется с приоритетом 0. Вызывается до AddTimer
+func (s *Scheduler) SetPriorities(priorities map[string]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.priorities = priorities
+}
+
+// SetStarvationTimeout задает время ожидания слота, после которого запуск
+// получает слот раньше запусков с более высоким приоритетом (d <= 0 -
+// значение по умолчанию, 60 секунд)
+func (s *Scheduler) SetStarvationTimeout(d time.Duration) {
+	if d <= 0 {
+		d = defaultStarvationTimeout
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.starveAfter = d
+	for _, ns := range s.namespaces {
+		if ns.limit != nil {
+			ns.limit.setStarveAfter(d)
+		}
+	}
+	if s.limit != nil {
+		s.limit.setStarveAfter(d)
+	}
+}
+
+// SetReadOnly включает режим только для чтения: таймеры, кроме safe
+// (не изменяющих состояние), не запускаются по расписанию и вручную
+// (Trigger возвращает ErrReadOnly). Вызывается до AddTimer
+func (s *Scheduler) SetReadOnly(safe map[string]bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readOnly = true
+	s.readOnlySafe = safe
+}
+
+// SetEnvironments задает окружение запуска таймеров по имени: рабочую
+// директорию и переменные, которые обработчик получает через
+// execenv.FromContext и передает командам execenv.Command.
+// Вызывается до AddTimer
+func (s *Scheduler) SetEnvironments(envs map[string]execenv.Env) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.envs = envs
+}
+
 // AddTimer добавляет новый таймер
 func (s *Scheduler) AddTimer(name string, interval time.Duration, handler Handler) error {
 	s.mu.Lock()
@@ -61,23 +349,127 @@ func (s *Scheduler) AddTimer(name string, interval time.Duration, handler Handle
 		return fmt.Errorf("timer %s already exists", name)
 	}
 
+	settings := s.settings[name]
 	timer := &Timer{
 		name:           name,
 		interval:       interval,
+		baseInterval:   interval,
+		timeout:        settings.Timeout,
+		reset:          make(chan struct{}, 1),
+		removed:        make(chan struct{}),
 		handler:        handler,
 		maxRestarts:    s.maxRestarts,
 		backoffSeconds: s.backoffSeconds,
+		env:            s.envs[name],
+		priority:       s.priorities[name],
+		readOnly:       s.readOnly && !s.readOnlySafe[name],
+		budget:         newBudgetState(s.budgets[name]),
+		overlap:        s.overlapPolicy(name),
+		misfire:        s.misfirePolicy(name),
+	}
+	if ns := Namespace(name); ns != "" {
+		timer.ns = s.namespaceLocked(ns)
+	}
+	if settings.Interval > 0 {
+		timer.interval = settings.Interval
 	}
 
 	s.timers[name] = timer
 	s.log.Info("Timer added", map[string]interface{}{
 		"name":     name,
-		"interval": interval.String(),
+		"interval": timer.interval.String(),
+		"overlap":  string(timer.overlap),
 	})
 
 	return nil
 }
 
+// AddOneShot добавляет однократный таймер: обработчик выполняется один раз
+// в момент at с той же защитой от panic и метриками, что и у периодических
+// таймеров, после чего таймер удаляется. Время в прошлом означает запуск
+// сразу после Start. У запущенного планировщика таймер запускается сразу.
+// Приостановленный или не прошедший gate в момент at таймер удаляется
+// без выполнения
+func (s *Scheduler) AddOneShot(name string, at time.Time, handler Handler) error {
+	if at.IsZero() {
+		return fmt.Errorf("timer %s: zero run time", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.timers[name]; exists {
+		return fmt.Errorf("timer %s already exists", name)
+	}
+
+	timer := &Timer{
+		name:           name,
+		at:             at,
+		timeout:        s.settings[name].Timeout,
+		reset:          make(chan struct{}, 1),
+		removed:        make(chan struct{}),
+		handler:        handler,
+		maxRestarts:    s.maxRestarts,
+		backoffSeconds: s.backoffSeconds,
+		env:            s.envs[name],
+		priority:       s.priorities[name],
+		readOnly:       s.readOnly && !s.readOnlySafe[name],
+		budget:         newBudgetState(s.budgets[name]),
+	}
+	if ns := Namespace(name); ns != "" {
+		timer.ns = s.namespaceLocked(ns)
+	}
+
+	s.timers[name] = timer
+	s.log.Info("One-shot timer added", map[string]interface{}{
+		"name": name,
+		"at":   at.Format(time.RFC3339),
+	})
+	if s.ctx != nil && !timer.readOnly {
+		s.startTimer(name, timer)
+	}
+
+	return nil
+}
+
+// ErrTimerRemoved причина отмены запуска таймера, удаленного RemoveTimer
+// (context.Cause контекста обработчика)
+var ErrTimerRemoved = errors.New("timer removed")
+
+// RemoveTimer удаляет таймер. До Start таймер просто не запускается.
+// У запущенного планировщика горутина таймера завершается (счетчик
+// и метрика активных таймеров уменьшаются), выполняющийся запуск
+// отменяется с причиной ErrTimerRemoved, а запуск, ждущий слот лимита,
+// не выполняется. RemoveTimer не ждет завершения обработчика
+func (s *Scheduler) RemoveTimer(name string) error {
+	s.mu.Lock()
+	timer, ok := s.timers[name]
+	if ok {
+		delete(s.timers, name)
+	}
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrTimerNotFound, name)
+	}
+
+	close(timer.removed)
+	timer.stateMu.Lock()
+	cancels := make([]context.CancelCauseFunc, 0, len(timer.runs))
+	for _, cancel := range timer.runs {
+		cancels = append(cancels, cancel)
+	}
+	timer.stateMu.Unlock()
+	for _, cancel := range cancels {
+		cancel(ErrTimerRemoved)
+	}
+
+	s.log.Info("Timer removed", map[string]interface{}{
+		"timer":         name,
+		"canceled_runs": len(cancels),
+	})
+	return nil
+}
+
 // Start запускает все таймеры
 func (s *Scheduler) Start(ctx context.Context) error {
 	s.mu.Lock()
@@ -95,14 +487,23 @@ func (s *Scheduler) Start(ctx context.Context) error {
 		return nil
 	}
 
+	s.restoreLastRuns()
+	s.restoreHistory()
+	s.restoreSnapshot()
+	for name := range s.envs {
+		if _, ok := s.timers[name]; !ok {
+			s.log.Warn("Environment configured for unknown timer", map[string]interface{}{"timer": name})
+		}
+	}
+
 	// Запускаем каждый таймер в отдельной горутине
 	for name, timer := range s.timers {
-		s.wg.Add(1)
-		atomic.AddInt32(&s.activeTimers, 1)
-		if s.metrics != nil {
-			s.metrics.IncActiveTimers()
+		if timer.readOnly {
+			s.log.Info("Timer disabled in read-only mode", map[string]interface{}{"timer": name})
+			continue
 		}
-		go s.runTimer(name, timer)
+		s.checkMisfire(name, timer)
+		s.startTimer(name, timer)
 	}
 
 	s.log.Info("Scheduler started", map[string]interface{}{
@@ -112,6 +513,21 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	return nil
 }
 
+// startTimer запускает горутину таймера. Вызывается под s.mu
+func (s *Scheduler) startTimer(name string, timer *Timer) {
+	s.wg.Add(1)
+	atomic.AddInt32(&s.activeTimers, 1)
+	if s.metrics != nil {
+		s.metrics.IncActiveTimers()
+	}
+	if !timer.at.IsZero() {
+		timer.setNextRun(timer.at)
+		go s.runOneShot(name, timer)
+		return
+	}
+	go s.runTimer(name, timer)
+}
+
 // runTimer выполняет таймер с защитой от panic
 func (s *Scheduler) runTimer(name string, timer *Timer) {
 	defer s.wg.Done()
@@ -124,20 +540,116 @@ func (s *Scheduler) runTimer(name string, timer *Timer) {
 
 	s.log.Info("Timer started", map[string]interface{}{"timer": name})
 
-	ticker := time.NewTicker(timer.interval)
+	// Запуски выполняются вне цикла тиков: тики, пришедшие во время
+	// запуска, обрабатываются политикой перекрытия таймера
+	runs := s.newDispatcher(name, timer)
+	defer runs.stop()
+
+	interval := timer.period()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
+	timer.setNextRun(time.Now().Add(interval))
+	defer timer.setNextRun(time.Time{})
+
+	// Запуск, пропущенный за время простоя (MisfireRun), выполняется сразу
+	if timer.catchUp && atomic.LoadInt32(&timer.paused) == 0 && timer.ns.enabled() && s.open() {
+		runs.tick()
+	}
 
 	for {
 		select {
 		case <-s.ctx.Done():
 			s.log.Info("Timer stopped", map[string]interface{}{"timer": name})
 			return
-		case <-ticker.C:
-			s.executeTimerWithRecovery(name, timer)
+		case <-timer.removed:
+			return
+		case <-timer.reset:
+			// Настройки изменены: отсчет нового интервала с текущего момента
+			interval = timer.period()
+			ticker.Reset(interval)
+			timer.setNextRun(time.Now().Add(interval))
+		case tick := <-ticker.C:
+			// Замедленный за превышение бюджета таймер пропускает тики
+			throttled, ticks := timer.budget.skip()
+			timer.setNextRun(tick.Add(time.Duration(ticks) * interval))
+			if throttled {
+				continue
+			}
+			// Приостановленный таймер пропускает тики, но продолжает отсчет
+			if atomic.LoadInt32(&timer.paused) == 1 || !timer.ns.enabled() || !s.open() {
+				continue
+			}
+			runs.tick()
 		}
 	}
 }
 
+// runOneShot ждет момента запуска однократного таймера, выполняет его
+// и удаляет таймер
+func (s *Scheduler) runOneShot(name string, timer *Timer) {
+	defer s.wg.Done()
+	defer func() {
+		atomic.AddInt32(&s.activeTimers, -1)
+		if s.metrics != nil {
+			s.metrics.DecActiveTimers()
+		}
+	}()
+
+	wait := time.NewTimer(time.Until(timer.at))
+	defer wait.Stop()
+	defer timer.setNextRun(time.Time{})
+
+	select {
+	case <-s.ctx.Done():
+		s.log.Info("Timer stopped", map[string]interface{}{"timer": name})
+		return
+	case <-timer.removed:
+		return
+	case <-wait.C:
+	}
+
+	skipped := atomic.LoadInt32(&timer.paused) == 1 || !timer.ns.enabled() || !s.open()
+	if !skipped {
+		s.runHandler(name, timer, false)
+	}
+
+	s.mu.Lock()
+	if s.timers[name] == timer {
+		delete(s.timers, name)
+	}
+	s.mu.Unlock()
+	s.log.Info("One-shot timer finished", map[string]interface{}{
+		"timer":   name,
+		"skipped": skipped,
+	})
+}
+
+// missTicks учитывает тики, отброшенные политикой перекрытия, пока
+// выполнялся предыдущий запуск
+func (s *Scheduler) missTicks(name string, timer *Timer, missed int) {
+	interval := timer.period()
+	total := atomic.AddInt64(&timer.missedTicks, int64(missed))
+	if s.metrics != nil {
+		s.metrics.RecordTimerTicksMissed(name, missed)
+	}
+	// Предупреждение пишется один раз, дальше пропуски видны в метрике
+	if atomic.CompareAndSwapInt32(&timer.missedWarned, 0, 1) {
+		s.log.Warn("Timer is missing ticks, handler is slower than interval", map[string]interface{}{
+			"timer":    name,
+			"missed":   total,
+			"interval": interval.String(),
+		})
+	}
+}
+
+// open проверяет условие запуска таймеров по расписанию
+func (s *Scheduler) open() bool {
+	s.mu.RLock()
+	gate := s.gate
+	s.mu.RUnlock()
+	return gate == nil || gate()
+}
+
 // executeTimerWithRecovery выполняет таймер с восстановлением после panic
 func (s *Scheduler) executeTimerWithRecovery(name string, timer *Timer) {
 	// Проверяем лимит перезапусков
@@ -155,40 +667,422 @@ func (s *Scheduler) executeTimerWithRecovery(name string, timer *Timer) {
 	}
 
 	// Выполняем с защитой от panic
-	func() {
-		defer func() {
-			if r := recover(); r != nil {
-				// Увеличиваем счетчик panic
-				newCount := atomic.AddInt32(&timer.panicCount, 1)
-
-				// Логируем подробную информацию
-				s.log.Error("Timer panic recovered", map[string]interface{}{
-					"timer":       name,
-					"panic":       r,
-					"panic_count": newCount,
-					"stacktrace":  string(debug.Stack()),
-				})
-
-				// Записываем метрику
-				if s.metrics != nil {
-					s.metrics.RecordTimerPanic(name)
-				}
+	var panicErr *PanicError
+	if err := s.runHandler(name, timer, false); errors.As(err, &panicErr) {
+		// Backoff перед следующей попыткой
+		if timer.backoffSeconds > 0 {
+			time.Sleep(time.Duration(timer.backoffSeconds) * time.Second)
+		}
+	}
+}
+
+// runHandler выполняет обработчик таймера с защитой от panic.
+// Перехваченный panic возвращается как *PanicError, остановка
+// планировщика во время ожидания слота - ошибкой контекста.
+// Ручной запуск (manual) не ждет исполнителя пула (runOrInline)
+func (s *Scheduler) runHandler(name string, timer *Timer, manual bool) error {
+	release, err := s.acquire(s.ctx, name, timer.priority, &timer.queued)
+	if err != nil {
+		return err
+	}
+	defer release()
+	run := func(ctx context.Context) { err = s.execute(ctx, name, timer) }
+	if manual {
+		if perr := s.dispatchNow(s.ctx, run); perr != nil {
+			return perr
+		}
+		return err
+	}
+	if perr := s.dispatch(s.ctx, &timer.queued, run); perr != nil {
+		return perr
+	}
+	return err
+}
+
+// execute выполняет обработчик таймера, занявшего слот лимита запусков.
+// parent - контекст планировщика, на пуле - с отметкой исполнителя
+func (s *Scheduler) execute(parent context.Context, name string, timer *Timer) (err error) {
+	// Таймер удален, пока запуск ждал слот
+	select {
+	case <-timer.removed:
+		return fmt.Errorf("%w: %s", ErrTimerNotFound, name)
+	default:
+	}
+
+	now := time.Now()
+	timer.setLastRun(now)
+	s.saveLastRun(name, now)
+	atomic.AddInt32(&timer.running, 1)
+	defer atomic.AddInt32(&timer.running, -1)
+
+	ctx, cancel := timer.runContext(parent)
+	defer cancel()
+	if !timer.env.IsZero() {
+		ctx = execenv.WithEnv(ctx, timer.env)
+	}
+	wall, cpu, cpuOK := timer.budget.measure(func() {
+		err = s.call(ctx, name, timer.handler, &timer.panicCount, func(rec RunRecord) {
+			s.addHistory(timer, rec)
+		})
+	})
+	if !timer.budget.budget.IsZero() {
+		s.checkBudget(name, timer, wall, cpu, cpuOK)
+	}
+	// Событие публикуется один раз, когда таймер исчерпал лимит перезапусков
+	if err != nil && timer.maxRestarts > 0 && atomic.LoadInt32(&timer.panicCount) == int32(timer.maxRestarts)+1 {
+		s.mu.RLock()
+		bus := s.events
+		s.mu.RUnlock()
+		if bus != nil {
+			bus.Publish(events.TypeTimerDisabled, map[string]interface{}{
+				"timer":        name,
+				"panic_count":  timer.maxRestarts + 1,
+				"max_restarts": timer.maxRestarts,
+				"error":        err.Error(),
+			})
+		}
+	}
+	return err
+}
+
+// Execute выполняет обработчик вне расписания (например, по событию
+// watcher) с той же защитой от panic и метриками, что и таймеры.
+// name используется в логах и в метке timer метрик. Запуск планировщика
+// не требуется: обработчик получает переданный ctx
+func (s *Scheduler) Execute(ctx context.Context, name string, handler Handler) error {
+	release, err := s.acquire(ctx, name, 0, nil)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	var panics int32
+	if perr := s.dispatch(ctx, nil, func(ctx context.Context) { err = s.call(ctx, name, handler, &panics, nil) }); perr != nil {
+		return perr
+	}
+	return err
+}
+
+// dispatch выполняет fn на пуле исполнителей (SetMaxConcurrentHandlers),
+// а без пула - в текущей горутине. Ошибка - отмена ctx до начала выполнения
+// или остановленный пул (ErrNotRunning)
+func (s *Scheduler) dispatch(ctx context.Context, queued *int32, fn func(ctx context.Context)) error {
+	s.mu.RLock()
+	p := s.pool
+	s.mu.RUnlock()
+	if p == nil {
+		fn(ctx)
+		return nil
+	}
+	return p.run(ctx, queued, fn)
+}
+
+// dispatchNow выполняет fn на свободном исполнителе пула, а если его нет
+// или пула нет - в текущей горутине
+func (s *Scheduler) dispatchNow(ctx context.Context, fn func(ctx context.Context)) error {
+	s.mu.RLock()
+	p := s.pool
+	s.mu.RUnlock()
+	if p == nil {
+		fn(ctx)
+		return nil
+	}
+	return p.runOrInline(ctx, fn)
+}
+
+// acquire занимает слот лимита одновременных запусков. Если слот занят,
+// запуск ждет в очереди с приоритетом priority: queued (если не nil)
+// отмечает ожидание в состоянии таймера, время ожидания записывается в метрику
+func (s *Scheduler) acquire(ctx context.Context, name string, priority int, queued *int32) (func(), error) {
+	s.mu.RLock()
+	limit := s.limit
+	ns := s.namespaces[Namespace(name)]
+	s.mu.RUnlock()
+	var nsLimit *limiter
+	if ns != nil {
+		nsLimit = ns.limit
+	}
+	release := func() {
+		if ns != nil {
+			atomic.AddInt32(&ns.running, -1)
+		}
+		if limit != nil {
+			limit.release()
+		}
+		if nsLimit != nil {
+			nsLimit.release()
+		}
+	}
+	if limit == nil && nsLimit == nil {
+		if ns != nil {
+			atomic.AddInt32(&ns.running, 1)
+		}
+		return release, nil
+	}
 
-				// Backoff перед следующей попыткой
-				if timer.backoffSeconds > 0 {
-					time.Sleep(time.Duration(timer.backoffSeconds) * time.Second)
+	start := time.Now()
+	if queued != nil {
+		atomic.AddInt32(queued, 1)
+		defer atomic.AddInt32(queued, -1)
+	}
+	// Сначала слот пространства имен: запуск, ожидающий его, не занимает общий слот
+	if nsLimit != nil {
+		if err := nsLimit.acquire(ctx, priority); err != nil {
+			return nil, err
+		}
+	}
+	if limit != nil {
+		if err := limit.acquire(ctx, priority); err != nil {
+			if nsLimit != nil {
+				nsLimit.release()
+			}
+			return nil, err
+		}
+	}
+	if ns != nil {
+		atomic.AddInt32(&ns.running, 1)
+	}
+	wait := time.Since(start)
+	if wait >= time.Millisecond {
+		s.log.Debug("Timer run waited for concurrency slot", map[string]interface{}{
+			"timer":   name,
+			"wait_ms": wait.Milliseconds(),
+		})
+	}
+	if s.metrics != nil {
+		s.metrics.RecordTimerQueueWait(name, wait)
+	}
+	return release, nil
+}
+
+// call вызывает обработчик, записывает метрики и перехватывает panic,
+// увеличивая счетчик panics. record (если не nil) получает результат
+// запуска для истории
+func (s *Scheduler) call(ctx context.Context, name string, handler Handler, panics *int32, record func(RunRecord)) (err error) {
+	start := time.Now()
+	runID := runid.New()
+	ctx = runid.WithRun(ctx, runid.Run{ID: runID, Timer: name})
+	ctx, result := withRunResult(ctx)
+	s.mu.RLock()
+	bus := s.events
+	tracer := s.tracer
+	s.mu.RUnlock()
+	if tracer != nil {
+		var span trace.Span
+		ctx, span = tracer.Start(ctx, "timer "+name, trace.WithAttributes(
+			attribute.String("timer.name", name),
+			attribute.String("timer.run_id", runID),
+		))
+		defer func() { finishSpan(ctx, span, err) }()
+	}
+	if s.metrics != nil {
+		defer func() {
+			s.metrics.RecordTimerDuration(name, time.Since(start), traceID(ctx))
+		}()
+		if ns := Namespace(name); ns != "" {
+			defer func() {
+				result := "ok"
+				if err != nil {
+					result = "panic"
 				}
+				s.metrics.RecordNamespaceRun(ns, result)
+			}()
+		}
+	}
+	if bus != nil {
+		defer func() {
+			status := "ok"
+			if err != nil {
+				status = "panic"
 			}
+			bus.Publish(events.TypeTimerRun, map[string]interface{}{
+				"timer":       name,
+				"run_id":      runID,
+				"status":      status,
+				"duration_ms": time.Since(start).Milliseconds(),
+			})
 		}()
+	}
+	if record != nil {
+		defer func() { record(result.record(ctx, runID, start, err)) }()
+	}
 
-		// Записываем метрику выполнения
-		if s.metrics != nil {
-			s.metrics.RecordTimerRun(name)
-		}
+	defer func() {
+		if r := recover(); r != nil {
+			// Увеличиваем счетчик panic
+			newCount := atomic.AddInt32(panics, 1)
+			stack := string(debug.Stack())
+
+			// Логируем подробную информацию
+			fields := map[string]interface{}{
+				"timer":       name,
+				"run_id":      runID,
+				"panic":       r,
+				"panic_count": newCount,
+				"stacktrace":  stack,
+			}
+			if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+				fields["trace_id"] = sc.TraceID().String()
+			}
+			s.log.Error("Timer panic recovered", fields)
 
-		// Выполняем обработчик
-		timer.handler(s.ctx)
+			// Записываем метрику
+			if s.metrics != nil {
+				s.metrics.RecordTimerPanic(name)
+			}
+
+			err = &PanicError{Timer: name, Value: r, Stack: stack}
+		}
 	}()
+
+	// Записываем метрику выполнения
+	if s.metrics != nil {
+		s.metrics.RecordTimerRun(name)
+	}
+
+	// Выполняем обработчик
+	handler(ctx)
+	return nil
+}
+
+// finishSpan завершает спан запуска. Перехваченный panic и истекший
+// дедлайн контекста обработчика отмечаются статусом ошибки
+func finishSpan(ctx context.Context, span trace.Span, err error) {
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		span.SetStatus(codes.Error, "timeout")
+	}
+	span.End()
+}
+
+// traceID возвращает идентификатор трассировки запуска для exemplar
+// или пустую строку, если запуск не трассируется
+func traceID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsSampled() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
+
+// restoreLastRuns загружает время последнего запуска таймеров из хранилища
+func (s *Scheduler) restoreLastRuns() {
+	if s.store == nil {
+		return
+	}
+	for name, timer := range s.timers {
+		var at time.Time
+		err := s.store.GetJSON(stateBucket, name, &at)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			s.log.Warn("Failed to restore timer last run", map[string]interface{}{
+				"timer": name,
+				"error": err.Error(),
+			})
+			continue
+		}
+		timer.setLastRun(at)
+	}
+}
+
+// saveLastRun сохраняет время запуска таймера в хранилище
+func (s *Scheduler) saveLastRun(name string, at time.Time) {
+	s.mu.RLock()
+	st := s.store
+	s.mu.RUnlock()
+	if st == nil {
+		return
+	}
+	if err := st.PutJSON(stateBucket, name, at); err != nil {
+		s.log.Warn("Failed to save timer last run", map[string]interface{}{
+			"timer": name,
+			"error": err.Error(),
+		})
+	}
+}
+
+// PanicError описывает panic, перехваченный в обработчике таймера
+type PanicError struct {
+	Timer string
+	Value interface{}
+	Stack string
+}
+
+// Error реализует интерфейс error
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("timer %s panicked: %v", e.Timer, e.Value)
+}
+
+// ErrTimerNotFound возвращается при обращении к несуществующему таймеру
+var ErrTimerNotFound = errors.New("timer not found")
+
+// ErrNotRunning возвращается, если операция требует запущенного планировщика
+var ErrNotRunning = errors.New("scheduler is not running")
+
+// ErrReadOnly возвращается при ручном запуске таймера, отключенного
+// режимом только для чтения
+var ErrReadOnly = errors.New("timer is disabled in read-only mode")
+
+// Trigger немедленно выполняет таймер вне расписания и возвращает результат.
+// Вызов синхронный: возврат происходит после завершения обработчика
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.RLock()
+	timer, ok := s.timers[name]
+	running := s.ctx != nil && s.ctx.Err() == nil
+	s.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrTimerNotFound, name)
+	}
+	if !running {
+		return ErrNotRunning
+	}
+	if timer.readOnly {
+		return fmt.Errorf("%w: %s", ErrReadOnly, name)
+	}
+	if !timer.ns.enabled() {
+		return fmt.Errorf("%w: %s", ErrNamespaceDisabled, timer.ns.name)
+	}
+
+	s.log.Info("Timer triggered manually", map[string]interface{}{"timer": name})
+	return s.runHandler(name, timer, true)
+}
+
+// Pause приостанавливает запуски таймера по расписанию. Ручной Trigger
+// продолжает работать
+func (s *Scheduler) Pause(name string) error {
+	return s.setPaused(name, true)
+}
+
+// Resume возобновляет запуски таймера по расписанию
+func (s *Scheduler) Resume(name string) error {
+	return s.setPaused(name, false)
+}
+
+// setPaused меняет признак паузы таймера
+func (s *Scheduler) setPaused(name string, paused bool) error {
+	s.mu.RLock()
+	timer, ok := s.timers[name]
+	s.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrTimerNotFound, name)
+	}
+
+	var value int32
+	msg := "Timer resumed"
+	if paused {
+		value = 1
+		msg = "Timer paused"
+	}
+	if atomic.SwapInt32(&timer.paused, value) != value {
+		s.log.Info(msg, map[string]interface{}{"timer": name})
+	}
+	return nil
 }
 
 // Stop останавливает все таймеры
@@ -214,7 +1108,14 @@ func (s *Scheduler) Stop(ctx context.Context) error {
 	case <-ctx.Done():
 		s.log.Warn("Timeout waiting for timers to stop")
 	}
+	s.mu.RLock()
+	p := s.pool
+	s.mu.RUnlock()
+	if p != nil {
+		p.stop()
+	}
 
+	s.saveSnapshot()
 	return nil
 }
 
@@ -229,3 +1130,106 @@ func (s *Scheduler) GetTimerCount() int {
 func (s *Scheduler) GetActiveTimerCount() int32 {
 	return atomic.LoadInt32(&s.activeTimers)
 }
+
+// ListTimers возвращает состояние всех таймеров, отсортированное по имени
+func (s *Scheduler) ListTimers() []TimerInfo {
+	s.mu.RLock()
+	running := s.ctx != nil && s.ctx.Err() == nil
+	timers := make([]*Timer, 0, len(s.timers))
+	for _, t := range s.timers {
+		timers = append(timers, t)
+	}
+	s.mu.RUnlock()
+	standby := running && !s.open()
+
+	infos := make([]TimerInfo, 0, len(timers))
+	for _, t := range timers {
+		infos = append(infos, t.info(running, standby))
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// ClockJumped пересчитывает время следующего запуска таймеров после
+// скачка системных часов на offset: тикеры отсчитывают монотонное время
+// и срабатывают в срок, а сохраненное настенное время устаревает
+func (s *Scheduler) ClockJumped(offset time.Duration) {
+	s.mu.RLock()
+	timers := make([]*Timer, 0, len(s.timers))
+	for _, t := range s.timers {
+		timers = append(timers, t)
+	}
+	s.mu.RUnlock()
+
+	now := time.Now()
+	recomputed := 0
+	for _, t := range timers {
+		t.stateMu.Lock()
+		if !t.nextRun.IsZero() {
+			// Until использует монотонную составляющую nextRun
+			t.nextRun = now.Add(time.Until(t.nextRun))
+			recomputed++
+		}
+		t.stateMu.Unlock()
+	}
+	s.log.Info("Timer next run times recomputed after clock jump", map[string]interface{}{
+		"offset": offset.String(),
+		"timers": recomputed,
+	})
+}
+
+// info возвращает снимок состояния таймера. standby означает, что
+// запуски по расписанию закрыты gate планировщика
+func (t *Timer) info(schedulerRunning, standby bool) TimerInfo {
+	t.stateMu.RLock()
+	info := TimerInfo{
+		Name:        t.name,
+		Interval:    t.interval,
+		LastRun:     t.lastRun,
+		NextRun:     t.nextRun,
+		PanicCount:  int(atomic.LoadInt32(&t.panicCount)),
+		MissedTicks: int(atomic.LoadInt64(&t.missedTicks)),
+		Throttle:    int(atomic.LoadInt32(&t.budget.throttle)),
+	}
+	t.stateMu.RUnlock()
+
+	switch {
+	case t.readOnly:
+		info.State = StateReadOnly
+	case t.maxRestarts > 0 && info.PanicCount > t.maxRestarts:
+		info.State = StateDisabled
+		info.NextRun = time.Time{}
+	case atomic.LoadInt32(&t.running) > 0:
+		info.State = StateRunning
+	case atomic.LoadInt32(&t.queued) > 0:
+		info.State = StateQueued
+	case !t.ns.enabled():
+		info.State = StateNamespaceDisabled
+		info.NextRun = time.Time{}
+	case atomic.LoadInt32(&t.paused) == 1:
+		info.State = StatePaused
+		info.NextRun = time.Time{}
+	case standby:
+		info.State = StateStandby
+		info.NextRun = time.Time{}
+	case schedulerRunning:
+		info.State = StateIdle
+	default:
+		info.State = StateStopped
+	}
+	return info
+}
+
+// setLastRun сохраняет время последнего запуска
+func (t *Timer) setLastRun(at time.Time) {
+	t.stateMu.Lock()
+	t.lastRun = at
+	t.stateMu.Unlock()
+}
+
+// setNextRun сохраняет время следующего запуска
+func (t *Timer) setNextRun(at time.Time) {
+	t.stateMu.Lock()
+	t.nextRun = at
+	t.stateMu.Unlock()
+}
@@ -2,13 +2,26 @@ package scheduler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/execenv"
 	"service-boilerplate/internal/logger"
 	"service-boilerplate/internal/metrics"
+	"service-boilerplate/internal/store"
 )
 
 // setupTestScheduler создает тестовый scheduler
@@ -134,6 +147,9 @@ func TestMaxRestartsExceeded(t *testing.T) {
 	// Устанавливаем лимит в 2 restarts
 	sched.maxRestarts = 2
 	sched.backoffSeconds = 0
+	bus := events.New()
+	disabled := bus.Subscribe(10, events.TypeTimerDisabled)
+	sched.SetEvents(bus)
 
 	var execCount int32
 	err := sched.AddTimer("limited-timer", 50*time.Millisecond, func(ctx context.Context) {
@@ -161,6 +177,16 @@ func TestMaxRestartsExceeded(t *testing.T) {
 	if count < 3 {
 		t.Errorf("Execution count = %d, expected at least 3 (1 + 2 restarts)", count)
 	}
+
+	// Событие об отключении публикуется один раз
+	if ev := <-disabled.C; ev.Data.(map[string]interface{})["timer"] != "limited-timer" {
+		t.Errorf("timer.disabled event = %+v", ev)
+	}
+	select {
+	case ev := <-disabled.C:
+		t.Errorf("duplicate timer.disabled event: %+v", ev)
+	default:
+	}
 }
 
 // TestBackoff проверяет задержку перед перезапуском
@@ -352,6 +378,173 @@ func TestGetActiveTimerCount(t *testing.T) {
 	sched.Stop(ctx)
 }
 
+// TestRemoveTimer проверяет удаление таймера до запуска и во время
+// работы планировщика: горутина завершается, запуск отменяется
+func TestRemoveTimer(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+
+	if err := sched.RemoveTimer("missing"); !errors.Is(err, ErrTimerNotFound) {
+		t.Errorf("RemoveTimer(missing) error = %v, want ErrTimerNotFound", err)
+	}
+
+	var removedRuns int32
+	sched.AddTimer("removed-before-start", 10*time.Millisecond, func(ctx context.Context) {
+		atomic.AddInt32(&removedRuns, 1)
+	})
+	if err := sched.RemoveTimer("removed-before-start"); err != nil {
+		t.Fatalf("RemoveTimer() error = %v", err)
+	}
+
+	started := make(chan struct{}, 1)
+	cause := make(chan error, 1)
+	sched.AddTimer("long", 10*time.Millisecond, func(ctx context.Context) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		select {
+		case cause <- context.Cause(ctx):
+		default:
+		}
+	})
+	sched.AddTimer("other", time.Hour, func(ctx context.Context) {})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sched.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sched.Stop(context.Background())
+	if got := sched.GetActiveTimerCount(); got != 2 {
+		t.Fatalf("active timers = %d, want 2", got)
+	}
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("timer did not start")
+	}
+	if err := sched.RemoveTimer("long"); err != nil {
+		t.Fatalf("RemoveTimer() error = %v", err)
+	}
+	select {
+	case err := <-cause:
+		if !errors.Is(err, ErrTimerRemoved) {
+			t.Errorf("run cancel cause = %v, want ErrTimerRemoved", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("running handler was not canceled")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for sched.GetActiveTimerCount() != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := sched.GetActiveTimerCount(); got != 1 {
+		t.Errorf("active timers after removal = %d, want 1", got)
+	}
+	if infos := sched.ListTimers(); len(infos) != 1 || infos[0].Name != "other" {
+		t.Errorf("ListTimers() = %+v, want only other", infos)
+	}
+	if err := sched.Trigger("long"); !errors.Is(err, ErrTimerNotFound) {
+		t.Errorf("Trigger(removed) error = %v, want ErrTimerNotFound", err)
+	}
+	if n := atomic.LoadInt32(&removedRuns); n != 0 {
+		t.Errorf("timer removed before Start ran %d times", n)
+	}
+}
+
+// TestAddOneShot проверяет однократный запуск в заданное время,
+// перехват panic и удаление таймера после запуска
+func TestAddOneShot(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+	bus := events.New()
+	runs := bus.Subscribe(10, events.TypeTimerRun)
+	sched.SetEvents(bus)
+
+	if err := sched.AddOneShot("zero", time.Time{}, func(ctx context.Context) {}); err == nil {
+		t.Error("AddOneShot(zero time) error = nil")
+	}
+
+	at := time.Now().Add(100 * time.Millisecond)
+	fired := make(chan time.Time, 2)
+	if err := sched.AddOneShot("once", at, func(ctx context.Context) {
+		fired <- time.Now()
+	}); err != nil {
+		t.Fatalf("AddOneShot() error = %v", err)
+	}
+	if err := sched.AddOneShot("once", at, func(ctx context.Context) {}); err == nil {
+		t.Error("AddOneShot(duplicate) error = nil")
+	}
+	sched.AddTimer("other", time.Hour, func(ctx context.Context) {})
+	if sim := sched.Simulate(time.Now(), at.Add(time.Minute)); len(sim) != 1 || sim[0].Timer != "once" || !sim[0].At.Equal(at) {
+		t.Errorf("Simulate() = %+v, want one run of once at %v", sim, at)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sched.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sched.Stop(context.Background())
+
+	info := sched.ListTimers()[0]
+	if info.Name != "once" || !info.NextRun.Equal(at) {
+		t.Errorf("ListTimers()[0] = %+v, want once with NextRun %v", info, at)
+	}
+	if got := info.FireTimes(3); len(got) != 1 || !got[0].Equal(at) {
+		t.Errorf("FireTimes() = %v, want [%v]", got, at)
+	}
+
+	select {
+	case ran := <-fired:
+		if ran.Before(at) {
+			t.Errorf("one-shot ran at %v, before %v", ran, at)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("one-shot timer did not run")
+	}
+
+	// Таймер, добавленный после Start, запускается сразу; panic перехватывается
+	if err := sched.AddOneShot("panic", time.Now(), func(ctx context.Context) {
+		panic("one-shot panic")
+	}); err != nil {
+		t.Fatalf("AddOneShot() error = %v", err)
+	}
+	statuses := map[string]interface{}{}
+	for len(statuses) < 2 {
+		select {
+		case ev := <-runs.C:
+			data := ev.Data.(map[string]interface{})
+			statuses[data["timer"].(string)] = data["status"]
+		case <-time.After(time.Second):
+			t.Fatalf("timer run events = %v, want once and panic", statuses)
+		}
+	}
+	if statuses["once"] != "ok" || statuses["panic"] != "panic" {
+		t.Errorf("run statuses = %v, want once ok and panic panic", statuses)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for sched.GetActiveTimerCount() != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := sched.GetActiveTimerCount(); got != 1 {
+		t.Errorf("active timers after one-shots = %d, want 1", got)
+	}
+	if infos := sched.ListTimers(); len(infos) != 1 || infos[0].Name != "other" {
+		t.Errorf("ListTimers() = %+v, want only other", infos)
+	}
+	select {
+	case <-fired:
+		t.Error("one-shot timer ran twice")
+	default:
+	}
+}
+
 // TestStart_AlreadyRunning проверяет ошибку при повторном запуске
 func TestStart_AlreadyRunning(t *testing.T) {
 	sched, log := setupTestScheduler(t)
@@ -396,3 +589,1221 @@ func TestNoTimers(t *testing.T) {
 		t.Errorf("Stop() error = %v", err)
 	}
 }
+
+// TestTrigger проверяет ручной запуск таймера
+func TestTrigger(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+
+	var counter int32
+	sched.AddTimer("manual", time.Hour, func(ctx context.Context) {
+		atomic.AddInt32(&counter, 1)
+	})
+	sched.AddTimer("panicky", time.Hour, func(ctx context.Context) {
+		panic("boom")
+	})
+
+	// До запуска планировщика вызов недоступен
+	if err := sched.Trigger("manual"); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Trigger() before Start error = %v, want ErrNotRunning", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sched.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if err := sched.Trigger("manual"); err != nil {
+		t.Errorf("Trigger() error = %v", err)
+	}
+	if atomic.LoadInt32(&counter) != 1 {
+		t.Errorf("counter = %d, want 1", counter)
+	}
+
+	var perr *PanicError
+	if err := sched.Trigger("panicky"); !errors.As(err, &perr) {
+		t.Errorf("Trigger() panicking timer error = %v, want *PanicError", err)
+	}
+
+	if err := sched.Trigger("missing"); !errors.Is(err, ErrTimerNotFound) {
+		t.Errorf("Trigger() missing timer error = %v, want ErrTimerNotFound", err)
+	}
+
+	// После остановки вызов снова недоступен
+	sched.Stop(ctx)
+	if err := sched.Trigger("manual"); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Trigger() after Stop error = %v, want ErrNotRunning", err)
+	}
+	if atomic.LoadInt32(&counter) != 1 {
+		t.Errorf("counter after Stop = %d, want 1", counter)
+	}
+}
+
+// TestListTimers проверяет снимок состояния таймеров
+func TestListTimers(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+
+	sched.AddTimer("b", time.Hour, func(ctx context.Context) {})
+	sched.AddTimer("a", 20*time.Millisecond, func(ctx context.Context) {})
+
+	infos := sched.ListTimers()
+	if len(infos) != 2 || infos[0].Name != "a" || infos[1].Name != "b" {
+		t.Fatalf("ListTimers() = %+v", infos)
+	}
+	if infos[0].State != StateStopped {
+		t.Errorf("State before Start = %s, want %s", infos[0].State, StateStopped)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sched.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	time.Sleep(70 * time.Millisecond)
+
+	infos = sched.ListTimers()
+	if infos[0].LastRun.IsZero() {
+		t.Error("LastRun should be set after execution")
+	}
+	if infos[1].NextRun.IsZero() || infos[1].State != StateIdle {
+		t.Errorf("timer b = %+v, want idle with next run", infos[1])
+	}
+
+	sched.Stop(ctx)
+}
+
+// TestClockJumped проверяет пересчет времени следующего запуска после
+// скачка часов: оставшееся до запуска время сохраняется
+func TestClockJumped(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+
+	sched.AddTimer("hourly", time.Hour, func(ctx context.Context) {})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sched.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sched.Stop(ctx)
+	time.Sleep(10 * time.Millisecond)
+
+	before := time.Until(sched.ListTimers()[0].NextRun)
+	sched.ClockJumped(time.Hour)
+	next := sched.ListTimers()[0].NextRun
+	if after := time.Until(next); after > before || before-after > time.Second {
+		t.Errorf("time until next run = %v after clock jump, want about %v", after, before)
+	}
+	if next.Round(0).Sub(time.Now().Round(0)) < 59*time.Minute {
+		t.Errorf("NextRun wall time = %v, want about an hour from now", next)
+	}
+}
+
+// TestReconfigure проверяет политики выполняющегося запуска при изменении
+// настроек: finish дожидается запуска, cancel отменяет его контекст
+func TestReconfigure(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+
+	sched.SetTimerSettings(map[string]TimerSettings{"slow": {Timeout: time.Minute}})
+	started := make(chan struct{}, 10)
+	finished := make(chan error, 10)
+	sched.AddTimer("slow", 20*time.Millisecond, func(ctx context.Context) {
+		started <- struct{}{}
+		select {
+		case <-ctx.Done():
+			finished <- context.Cause(ctx)
+		case <-time.After(100 * time.Millisecond):
+			finished <- nil
+		}
+	})
+	if _, err := sched.Reconfigure("missing", TimerSettings{}, ReloadFinish); !errors.Is(err, ErrTimerNotFound) {
+		t.Errorf("Reconfigure(missing) error = %v, want ErrTimerNotFound", err)
+	}
+	if changed, _ := sched.Reconfigure("slow", TimerSettings{Timeout: time.Minute}, ReloadCancel); changed {
+		t.Error("Reconfigure() with the same settings reported a change")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sched.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sched.Stop(ctx)
+
+	<-started
+	if changed, err := sched.Reconfigure("slow", TimerSettings{Interval: 30 * time.Millisecond, Timeout: time.Minute}, ReloadFinish); !changed || err != nil {
+		t.Fatalf("Reconfigure(finish) = %v, %v", changed, err)
+	}
+	if err := <-finished; err != nil {
+		t.Errorf("run canceled with finish policy: %v", err)
+	}
+	if interval := sched.ListTimers()[0].Interval; interval != 30*time.Millisecond {
+		t.Errorf("Interval = %v, want 30ms", interval)
+	}
+
+	<-started
+	if changed, err := sched.Reconfigure("slow", TimerSettings{}, ReloadCancel); !changed || err != nil {
+		t.Fatalf("Reconfigure(cancel) = %v, %v", changed, err)
+	}
+	if err := <-finished; !errors.Is(err, ErrTimerReconfigured) {
+		t.Errorf("run cause = %v, want ErrTimerReconfigured", err)
+	}
+	// Без настроек таймер возвращается к интервалу из AddTimer
+	if interval := sched.ListTimers()[0].Interval; interval != 20*time.Millisecond {
+		t.Errorf("Interval = %v, want 20ms", interval)
+	}
+	time.Sleep(10 * time.Millisecond)
+	history, _ := sched.GetTimerHistory("slow")
+	if len(history) == 0 || history[0].Outcome != OutcomeCanceled || history[0].Error != ErrTimerReconfigured.Error() {
+		t.Errorf("last run = %+v, want canceled by reconfigure", history)
+	}
+}
+
+// TestTimerTimeout проверяет отмену контекста запуска по таймауту из настроек
+func TestTimerTimeout(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+
+	sched.SetTimerSettings(map[string]TimerSettings{"bounded": {Interval: time.Hour, Timeout: 10 * time.Millisecond}})
+	var cause error
+	sched.AddTimer("bounded", time.Minute, func(ctx context.Context) {
+		<-ctx.Done()
+		cause = ctx.Err()
+	})
+	if interval := sched.ListTimers()[0].Interval; interval != time.Hour {
+		t.Errorf("Interval = %v, want interval from settings", interval)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sched.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sched.Stop(ctx)
+	if err := sched.Trigger("bounded"); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+	if !errors.Is(cause, context.DeadlineExceeded) {
+		t.Errorf("handler context error = %v, want DeadlineExceeded", cause)
+	}
+}
+
+// TestPauseResume проверяет приостановку и возобновление таймера
+func TestPauseResume(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+
+	var counter int32
+	sched.AddTimer("fast", 10*time.Millisecond, func(ctx context.Context) {
+		atomic.AddInt32(&counter, 1)
+	})
+
+	if err := sched.Pause("missing"); !errors.Is(err, ErrTimerNotFound) {
+		t.Errorf("Pause() missing timer error = %v, want ErrTimerNotFound", err)
+	}
+	if err := sched.Pause("fast"); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sched.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sched.Stop(ctx)
+
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&counter); n != 0 {
+		t.Errorf("paused timer executed %d times", n)
+	}
+	if state := sched.ListTimers()[0].State; state != StatePaused {
+		t.Errorf("State = %s, want %s", state, StatePaused)
+	}
+
+	// Ручной запуск работает и на паузе
+	if err := sched.Trigger("fast"); err != nil {
+		t.Errorf("Trigger() on paused timer error = %v", err)
+	}
+
+	if err := sched.Resume("fast"); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&counter); n < 2 {
+		t.Errorf("resumed timer executed %d times, want at least 2", n)
+	}
+}
+
+// TestLastRunPersistence проверяет восстановление времени последнего запуска из хранилища
+func TestLastRunPersistence(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+
+	st := store.New(log, filepath.Join(t.TempDir(), "state.db"))
+	if err := st.Open(); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer st.Close()
+
+	sched.SetStore(st)
+	sched.AddTimer("persisted", time.Hour, func(ctx context.Context) {})
+	sched.Start(context.Background())
+	if err := sched.Trigger("persisted"); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+	sched.Stop(context.Background())
+	lastRun := sched.ListTimers()[0].LastRun
+
+	// Новый экземпляр планировщика, как после перезапуска сервиса
+	restarted := New(log, nil, 3, 0)
+	restarted.SetStore(st)
+	restarted.AddTimer("persisted", time.Hour, func(ctx context.Context) {})
+	restarted.Start(context.Background())
+	defer restarted.Stop(context.Background())
+
+	if got := restarted.ListTimers()[0].LastRun; !got.Equal(lastRun) {
+		t.Errorf("LastRun after restart = %v, want %v", got, lastRun)
+	}
+}
+
+// TestMisfirePolicy проверяет запуск сразу после Start таймера, интервал
+// которого истек, пока сервис не работал
+func TestMisfirePolicy(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+
+	st := store.New(log, filepath.Join(t.TempDir(), "state.db"))
+	if err := st.Open(); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer st.Close()
+	// overdue и skipped пропустили запуски, recent - нет
+	st.PutJSON(stateBucket, "overdue", time.Now().Add(-3*time.Hour))
+	st.PutJSON(stateBucket, "skipped", time.Now().Add(-3*time.Hour))
+	st.PutJSON(stateBucket, "recent", time.Now().Add(-time.Minute))
+
+	var mu sync.Mutex
+	ran := map[string]int{}
+	sched.SetStore(st)
+	sched.SetMisfirePolicy(MisfireRun, map[string]MisfirePolicy{"skipped": MisfireSkip})
+	for _, name := range []string{"overdue", "skipped", "recent", "new"} {
+		name := name
+		sched.AddTimer(name, time.Hour, func(ctx context.Context) {
+			mu.Lock()
+			ran[name]++
+			mu.Unlock()
+		})
+	}
+	if err := sched.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	sched.Stop(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	if ran["overdue"] != 1 || len(ran) != 1 {
+		t.Errorf("runs after Start = %v, want only overdue once", ran)
+	}
+}
+
+// TestGate проверяет пропуск запусков по расписанию при закрытом gate
+func TestGate(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+
+	var open int32
+	var runs int32
+	sched.SetGate(func() bool { return atomic.LoadInt32(&open) == 1 })
+	sched.AddTimer("gated", 10*time.Millisecond, func(ctx context.Context) {
+		atomic.AddInt32(&runs, 1)
+	})
+	sched.Start(context.Background())
+	defer sched.Stop(context.Background())
+
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&runs) != 0 {
+		t.Errorf("runs with closed gate = %d, want 0", runs)
+	}
+	if state := sched.ListTimers()[0].State; state != StateStandby {
+		t.Errorf("State = %s, want %s", state, StateStandby)
+	}
+
+	atomic.StoreInt32(&open, 1)
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&runs) == 0 {
+		t.Error("timer did not run with open gate")
+	}
+}
+
+// TestExecute проверяет выполнение обработчика вне расписания с защитой от panic
+func TestExecute(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+
+	var ran bool
+	if err := sched.Execute(context.Background(), "watch:inbox", func(ctx context.Context) { ran = true }); err != nil || !ran {
+		t.Errorf("Execute() = %v, ran = %v", err, ran)
+	}
+
+	err := sched.Execute(context.Background(), "watch:inbox", func(ctx context.Context) { panic("boom") })
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || panicErr.Timer != "watch:inbox" {
+		t.Errorf("Execute() error = %v, want *PanicError", err)
+	}
+}
+
+// TestMissedTicks проверяет учет тиков, пропущенных медленным обработчиком
+func TestMissedTicks(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+
+	if err := sched.AddTimer("slow", 20*time.Millisecond, func(ctx context.Context) {
+		time.Sleep(70 * time.Millisecond)
+	}); err != nil {
+		t.Fatalf("AddTimer() error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := sched.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	time.Sleep(250 * time.Millisecond)
+	sched.Stop(ctx)
+
+	infos := sched.ListTimers()
+	if len(infos) != 1 || infos[0].MissedTicks == 0 {
+		t.Errorf("ListTimers() = %+v, want missed ticks", infos)
+	}
+}
+
+// TestOverlapPolicies проверяет политики перекрытия запусков таймера:
+// skip и queue не запускают обработчик, пока выполняется предыдущий
+// запуск, concurrent запускает его по каждому тику
+func TestOverlapPolicies(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+	sched.SetOverlapPolicy("", map[string]OverlapPolicy{"queue": OverlapQueue, "concurrent": OverlapConcurrent})
+
+	var mu sync.Mutex
+	active := map[string]int{}
+	peak := map[string]int{}
+	for _, name := range []string{"skip", "queue", "concurrent"} {
+		name := name
+		sched.AddTimer(name, 20*time.Millisecond, func(ctx context.Context) {
+			mu.Lock()
+			active[name]++
+			if active[name] > peak[name] {
+				peak[name] = active[name]
+			}
+			mu.Unlock()
+			time.Sleep(70 * time.Millisecond)
+			mu.Lock()
+			active[name]--
+			mu.Unlock()
+		})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := sched.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	time.Sleep(250 * time.Millisecond)
+	sched.Stop(ctx)
+
+	mu.Lock()
+	if peak["skip"] != 1 || peak["queue"] != 1 || peak["concurrent"] < 2 {
+		t.Errorf("concurrent runs = %v, want skip 1, queue 1, concurrent >= 2", peak)
+	}
+	mu.Unlock()
+	missed := map[string]int{}
+	for _, info := range sched.ListTimers() {
+		missed[info.Name] = info.MissedTicks
+	}
+	if missed["skip"] == 0 || missed["concurrent"] != 0 {
+		t.Errorf("missed ticks = %v, want skip > 0, concurrent 0", missed)
+	}
+
+	// queue: тики 20 и 30 ждут в очереди, запуск из очереди - по окончании
+	// предыдущего; concurrent: запуски по всем тикам, до трех одновременно
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	sim, simLog := setupTestScheduler(t)
+	defer simLog.Close()
+	sim.SetOverlapPolicy(OverlapQueue, map[string]OverlapPolicy{"concurrent": OverlapConcurrent})
+	sim.AddTimer("queue", 10*time.Second, func(ctx context.Context) {})
+	sim.AddTimer("concurrent", 10*time.Second, func(ctx context.Context) {})
+	runs, _ := sim.SimulateWith(context.Background(), from, from.Add(time.Minute), SimulateOptions{
+		Durations: map[string]time.Duration{"queue": 25 * time.Second, "concurrent": 25 * time.Second},
+	})
+	var got []string
+	peakSim := 0
+	for _, run := range runs {
+		if run.Timer == "queue" {
+			got = append(got, fmt.Sprintf("%ds", int(run.At.Sub(from).Seconds())))
+		} else if run.Concurrent > peakSim {
+			peakSim = run.Concurrent
+		}
+	}
+	if strings.Join(got, ",") != "10s,35s,60s" {
+		t.Errorf("queue runs = %v, want 10s,35s,60s", got)
+	}
+	if len(runs) != 9 {
+		t.Errorf("simulated runs = %d, want 9", len(runs))
+	}
+}
+
+// TestMaxConcurrentRuns проверяет общий лимит одновременных запусков:
+// запуски сверх лимита ждут слот в порядке очереди, а отмена контекста
+// снимает запуск с ожидания
+func TestMaxConcurrentRuns(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+	sched.SetMaxConcurrentRuns(1)
+
+	hold := make(chan struct{})
+	started := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		sched.Execute(context.Background(), "hold", func(ctx context.Context) {
+			close(started)
+			<-hold
+		})
+	}()
+	waitQueued := func(n int) {
+		deadline := time.Now().Add(time.Second)
+		for sched.limit.queued() != n {
+			if time.Now().After(deadline) {
+				t.Fatalf("queued = %d, want %d", sched.limit.queued(), n)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	// hold занимает слот раньше, чем остальные встают в очередь
+	<-started
+
+	var mu sync.Mutex
+	var order []string
+	for i, name := range []string{"a", "b", "c"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sched.Execute(context.Background(), name, func(ctx context.Context) {
+				mu.Lock()
+				order = append(order, name)
+				mu.Unlock()
+			})
+		}()
+		waitQueued(i + 1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- sched.Execute(ctx, "cancelled", func(ctx context.Context) {
+			t.Error("cancelled run executed")
+		})
+	}()
+	waitQueued(4)
+	cancel()
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Errorf("Execute() while queued error = %v, want context.Canceled", err)
+	}
+	waitQueued(3)
+
+	close(hold)
+	wg.Wait()
+	if len(order) != 3 || order[0] != "a" || order[1] != "b" || order[2] != "c" {
+		t.Errorf("run order = %v, want [a b c]", order)
+	}
+}
+
+// TestMaxConcurrentHandlers проверяет выполнение обработчиков на пуле
+// исполнителей: одновременно не больше размера пула, остальные ждут
+// в очереди, отмененный в очереди запуск не выполняется
+func TestMaxConcurrentHandlers(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+	sched.SetMaxConcurrentHandlers(2)
+
+	hold := make(chan struct{})
+	var running, peak, runs int32
+	handler := func(ctx context.Context) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&peak)
+			if n <= max || atomic.CompareAndSwapInt32(&peak, max, n) {
+				break
+			}
+		}
+		<-hold
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&runs, 1)
+	}
+	waitPool := func(queued, busy int32) {
+		deadline := time.Now().Add(time.Second)
+		for atomic.LoadInt32(&sched.pool.queued) != queued || atomic.LoadInt32(&sched.pool.busy) != busy {
+			if time.Now().After(deadline) {
+				t.Fatalf("pool queued = %d, busy = %d, want %d and %d",
+					atomic.LoadInt32(&sched.pool.queued), atomic.LoadInt32(&sched.pool.busy), queued, busy)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sched.Execute(context.Background(), "run", handler); err != nil {
+				t.Errorf("Execute() error = %v", err)
+			}
+		}()
+	}
+	waitPool(2, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- sched.Execute(ctx, "cancelled", func(ctx context.Context) {
+			t.Error("cancelled run executed")
+		})
+	}()
+	waitPool(3, 2)
+	cancel()
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Errorf("Execute() while queued error = %v, want context.Canceled", err)
+	}
+
+	close(hold)
+	wg.Wait()
+	if runs != 4 || peak != 2 {
+		t.Errorf("runs = %d, peak concurrency = %d, want 4 and 2", runs, peak)
+	}
+
+	// Остановленный пул запуски не принимает и исполнителей не запускает
+	sched.pool.stop()
+	if err := sched.Execute(context.Background(), "after-stop", handler); !errors.Is(err, ErrNotRunning) || runs != 4 {
+		t.Errorf("Execute() after pool stop error = %v, runs = %d, want ErrNotRunning", err, runs)
+	}
+}
+
+// TestMaxConcurrentHandlers_Nested проверяет, что обработчик, занявший
+// единственного исполнителя, может вызвать Execute и Trigger
+func TestMaxConcurrentHandlers_Nested(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+	sched.SetMaxConcurrentHandlers(1)
+
+	var inner int32
+	sched.AddTimer("inner", time.Hour, func(ctx context.Context) { atomic.AddInt32(&inner, 1) })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sched.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sched.Stop(ctx)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- sched.Execute(ctx, "outer", func(ctx context.Context) {
+			if err := sched.Execute(ctx, "nested", func(ctx context.Context) { atomic.AddInt32(&inner, 1) }); err != nil {
+				t.Errorf("nested Execute() error = %v", err)
+			}
+			if err := sched.Trigger("inner"); err != nil {
+				t.Errorf("nested Trigger() error = %v", err)
+			}
+		})
+	}()
+	select {
+	case err := <-done:
+		if err != nil || atomic.LoadInt32(&inner) != 2 {
+			t.Errorf("Execute() error = %v, nested runs = %d, want 2", err, inner)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("nested Execute or Trigger deadlocked on the pool")
+	}
+}
+
+// TestPriorities проверяет порядок запусков в очереди лимита: сначала
+// больший приоритет, а дольше допустимого ждущий запуск - вне очереди
+func TestPriorities(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+	sched.SetMaxConcurrentRuns(1)
+	sched.SetPriorities(map[string]int{"critical": 10, "best_effort": -1})
+
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) Handler {
+		return func(ctx context.Context) {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+		}
+	}
+	for _, name := range []string{"critical", "normal", "best_effort"} {
+		sched.AddTimer(name, time.Hour, record(name))
+	}
+	sched.Start(context.Background())
+	defer sched.Stop(context.Background())
+
+	run := func(starvation time.Duration, names ...string) []string {
+		sched.SetStarvationTimeout(starvation)
+		order = nil
+		hold := make(chan struct{})
+		started := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sched.Execute(context.Background(), "hold", func(ctx context.Context) {
+				close(started)
+				<-hold
+			})
+		}()
+		<-started
+		for i, name := range names {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sched.Trigger(name)
+			}()
+			for sched.limit.queued() != i+1 {
+				time.Sleep(time.Millisecond)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		close(hold)
+		wg.Wait()
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), order...)
+	}
+
+	if got := run(time.Hour, "best_effort", "normal", "critical"); len(got) != 3 ||
+		got[0] != "critical" || got[1] != "normal" || got[2] != "best_effort" {
+		t.Errorf("run order = %v, want [critical normal best_effort]", got)
+	}
+	// best_effort ждет дольше порога и получает слот первым
+	if got := run(30*time.Millisecond, "best_effort", "critical"); len(got) != 2 || got[0] != "best_effort" {
+		t.Errorf("run order with starvation = %v, want best_effort first", got)
+	}
+}
+
+// TestEnvironments проверяет передачу окружения таймера обработчику
+func TestEnvironments(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+	env := execenv.Env{Dir: t.TempDir(), Vars: map[string]string{"REPORT_MODE": "full"}}
+	sched.SetEnvironments(map[string]execenv.Env{"report": env})
+
+	got := make(chan execenv.Env, 2)
+	handler := func(ctx context.Context) { got <- execenv.FromContext(ctx) }
+	sched.AddTimer("report", time.Hour, handler)
+	sched.AddTimer("plain", time.Hour, handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sched.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sched.Stop(ctx)
+
+	sched.Trigger("report")
+	if e := <-got; e.Dir != env.Dir || e.Vars["REPORT_MODE"] != "full" {
+		t.Errorf("report env = %+v, want %+v", e, env)
+	}
+	sched.Trigger("plain")
+	if e := <-got; !e.IsZero() {
+		t.Errorf("plain env = %+v, want empty", e)
+	}
+}
+
+// traceRecorder записывает идентификаторы трассировки из RecordTimerDuration
+type traceRecorder struct {
+	*metrics.Server
+	mu       sync.Mutex
+	traceIDs map[string]string
+}
+
+func (r *traceRecorder) RecordTimerDuration(timerName string, duration time.Duration, traceID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.traceIDs[timerName] = traceID
+}
+
+// TestTracing проверяет спаны запусков: контекст спана передается обработчику,
+// panic и таймаут отмечаются статусом ошибки, а идентификатор трассировки
+// передается в метрику длительности как exemplar
+func TestTracing(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+	recorder := &traceRecorder{Server: metrics.New(log, false, ""), traceIDs: make(map[string]string)}
+	sched.metrics = recorder
+
+	spanRecorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))
+	sched.SetTracer(provider.Tracer("test"))
+
+	var handlerSpan trace.SpanContext
+	sched.AddTimer("ok", time.Hour, func(ctx context.Context) {
+		handlerSpan = trace.SpanContextFromContext(ctx)
+	})
+	sched.AddTimer("panic", time.Hour, func(ctx context.Context) { panic("boom") })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sched.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sched.Stop(context.Background())
+
+	sched.Trigger("ok")
+	sched.Trigger("panic")
+	timeoutCtx, timeoutCancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer timeoutCancel()
+	sched.Execute(timeoutCtx, "slow", func(ctx context.Context) { <-ctx.Done() })
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range spanRecorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	ok := spans["timer ok"]
+	if ok == nil || ok.SpanContext().SpanID() != handlerSpan.SpanID() || ok.Parent().IsValid() {
+		t.Fatalf("span of ok timer = %v, handler span = %v, want root span passed to handler", ok, handlerSpan)
+	}
+	if ok.Status().Code != codes.Unset {
+		t.Errorf("ok span status = %v, want unset", ok.Status())
+	}
+	recorder.mu.Lock()
+	if got := recorder.traceIDs["ok"]; got != ok.SpanContext().TraceID().String() {
+		t.Errorf("exemplar trace ID = %q, want %s", got, ok.SpanContext().TraceID())
+	}
+	recorder.mu.Unlock()
+	if span := spans["timer panic"]; span == nil || span.Status().Code != codes.Error || len(span.Events()) == 0 {
+		t.Errorf("panic span = %v, want error status and recorded error", span)
+	}
+	if span := spans["timer slow"]; span == nil || span.Status().Description != "timeout" {
+		t.Errorf("slow span = %v, want timeout status", span)
+	}
+}
+
+// TestSnapshotRestore проверяет перенос состояния таймеров между
+// планировщиками: снимок, передачу при смене лидера и хранилище
+func TestSnapshotRestore(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+
+	st := store.New(log, filepath.Join(t.TempDir(), "state.db"))
+	if err := st.Open(); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer st.Close()
+	sched.SetStore(st)
+	sched.AddTimer("report", time.Hour, func(ctx context.Context) {})
+	sched.AddTimer("broken", time.Hour, func(ctx context.Context) { panic("boom") })
+	sched.Start(context.Background())
+	sched.Trigger("report")
+	sched.Trigger("broken")
+	sched.Pause("report")
+
+	snap := sched.Snapshot()
+	if len(snap.Timers) != 2 || snap.Timers[0].Name != "broken" || snap.Timers[0].PanicCount != 1 ||
+		!snap.Timers[1].Paused || snap.Timers[1].LastRun.IsZero() {
+		t.Fatalf("Snapshot() = %+v", snap)
+	}
+
+	// Резервный экземпляр получает состояние лидера через файл передачи
+	handoff := filepath.Join(t.TempDir(), "leader.lock.scheduler")
+	if err := NewHandoff(sched, handoff).BeforeStop(context.Background()); err != nil {
+		t.Fatalf("Handoff.BeforeStop() error = %v", err)
+	}
+	standby := New(log, nil, 3, 0)
+	standby.AddTimer("report", time.Hour, func(ctx context.Context) {})
+	if err := NewHandoff(standby, handoff).AfterStart(context.Background()); err != nil {
+		t.Fatalf("Handoff.AfterStart() error = %v", err)
+	}
+	info := standby.ListTimers()[0]
+	if info.State != StatePaused || !info.LastRun.Equal(snap.Timers[1].LastRun) {
+		t.Errorf("standby timer = %+v, want paused with leader's last run", info)
+	}
+	if err := standby.Restore(snap); !errors.Is(err, ErrTimerNotFound) {
+		t.Errorf("Restore() with unknown timer error = %v, want ErrTimerNotFound", err)
+	}
+
+	// Более старый снимок не отменяет паузу из более нового
+	old := snap
+	old.TakenAt = snap.TakenAt.Add(-time.Hour)
+	old.Timers = []TimerSnapshot{{Name: "report"}}
+	standby.Restore(old)
+	if standby.ListTimers()[0].State != StatePaused {
+		t.Error("stale snapshot resumed the timer")
+	}
+
+	// После перезапуска пауза сохраняется, а счетчик panic сбрасывается
+	sched.Stop(context.Background())
+	restarted := New(log, nil, 3, 0)
+	restarted.SetStore(st)
+	restarted.AddTimer("report", time.Hour, func(ctx context.Context) {})
+	restarted.AddTimer("broken", time.Hour, func(ctx context.Context) {})
+	restarted.Start(context.Background())
+	defer restarted.Stop(context.Background())
+	infos := restarted.ListTimers()
+	if infos[0].PanicCount != 0 || infos[1].State != StatePaused {
+		t.Errorf("timers after restart = %+v, want broken reset and report paused", infos)
+	}
+}
+
+// TestReadOnly проверяет, что в режиме только для чтения работают
+// только безопасные таймеры, а остальные не запускаются даже вручную
+func TestReadOnly(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+
+	sched.SetReadOnly(map[string]bool{"report": true})
+	var reports, writes int32
+	sched.AddTimer("report", 20*time.Millisecond, func(ctx context.Context) {
+		atomic.AddInt32(&reports, 1)
+	})
+	sched.AddTimer("cleanup", 20*time.Millisecond, func(ctx context.Context) {
+		atomic.AddInt32(&writes, 1)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sched.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if err := sched.Trigger("cleanup"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Trigger(cleanup) error = %v, want ErrReadOnly", err)
+	}
+	if err := sched.Trigger("report"); err != nil {
+		t.Errorf("Trigger(report) error = %v", err)
+	}
+	sched.Stop(context.Background())
+
+	if atomic.LoadInt32(&reports) == 0 {
+		t.Error("read-only safe timer did not run")
+	}
+	if n := atomic.LoadInt32(&writes); n != 0 {
+		t.Errorf("disabled timer ran %d times", n)
+	}
+	for _, info := range sched.ListTimers() {
+		if info.Name == "cleanup" && info.State != StateReadOnly {
+			t.Errorf("cleanup state = %q, want %q", info.State, StateReadOnly)
+		}
+	}
+}
+
+// TestTimerHistory проверяет историю запусков, ее лимит и сохранение
+func TestTimerHistory(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+
+	st := store.New(log, filepath.Join(t.TempDir(), "state.db"))
+	if err := st.Open(); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer st.Close()
+
+	var calls int32
+	handler := func(ctx context.Context) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 2:
+			ReportError(ctx, errors.New("upstream unavailable"))
+		case 3:
+			panic("boom")
+		}
+	}
+	sched.SetStore(st)
+	sched.SetHistory(2, true)
+	sched.AddTimer("nightly", time.Hour, handler)
+	sched.Start(context.Background())
+	for i := 0; i < 3; i++ {
+		sched.Trigger("nightly")
+	}
+	sched.Stop(context.Background())
+
+	history, err := sched.GetTimerHistory("nightly")
+	if err != nil {
+		t.Fatalf("GetTimerHistory() error = %v", err)
+	}
+	// Лимит 2: первый успешный запуск вытеснен, самый новый - первый
+	if len(history) != 2 {
+		t.Fatalf("GetTimerHistory() len = %d, want 2", len(history))
+	}
+	if history[0].Outcome != OutcomePanic || history[0].Error != "timer nightly panicked: boom" {
+		t.Errorf("history[0] = %+v, want panic", history[0])
+	}
+	if history[1].Outcome != OutcomeFailed || history[1].Error != "upstream unavailable" || history[1].RunID == "" {
+		t.Errorf("history[1] = %+v, want failed", history[1])
+	}
+	if _, err := sched.GetTimerHistory("missing"); !errors.Is(err, ErrTimerNotFound) {
+		t.Errorf("GetTimerHistory(missing) error = %v, want ErrTimerNotFound", err)
+	}
+
+	// История восстанавливается после перезапуска
+	restarted := New(log, nil, 3, 0)
+	restarted.SetStore(st)
+	restarted.SetHistory(2, true)
+	restarted.AddTimer("nightly", time.Hour, handler)
+	restarted.Start(context.Background())
+	defer restarted.Stop(context.Background())
+	if got, _ := restarted.GetTimerHistory("nightly"); len(got) != 2 || got[0].RunID != history[0].RunID {
+		t.Errorf("history after restart = %+v, want %+v", got, history)
+	}
+}
+
+// TestBudget проверяет замедление таймера после запусков подряд сверх
+// бюджета и снятие замедления после запуска в пределах бюджета
+func TestBudget(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+	bus := events.New()
+	throttled := bus.Subscribe(10, events.TypeTimerThrottled)
+	sched.SetEvents(bus)
+	sched.SetBudgets(map[string]Budget{"heavy": {Wall: 10 * time.Millisecond, Violations: 2, ThrottleFactor: 3}})
+
+	var slow int32 = 1
+	if err := sched.AddTimer("heavy", time.Hour, func(ctx context.Context) {
+		if atomic.LoadInt32(&slow) == 1 {
+			time.Sleep(30 * time.Millisecond)
+		}
+	}); err != nil {
+		t.Fatalf("AddTimer() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sched.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sched.Stop(ctx)
+
+	// Первое превышение только учитывается
+	if err := sched.Trigger("heavy"); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+	if info := sched.ListTimers()[0]; info.Throttle != 0 {
+		t.Fatalf("Throttle after one violation = %d, want 0", info.Throttle)
+	}
+
+	if err := sched.Trigger("heavy"); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+	info := sched.ListTimers()[0]
+	if info.Throttle != 3 {
+		t.Fatalf("Throttle = %d, want 3", info.Throttle)
+	}
+	if times := info.FireTimes(2); times[1].Sub(times[0]) != 3*time.Hour {
+		t.Errorf("FireTimes() step = %s, want 3h", times[1].Sub(times[0]))
+	}
+	ev := <-throttled.C
+	if data := ev.Data.(map[string]interface{}); data["throttled"] != true || data["interval"] != "3h0m0s" {
+		t.Errorf("timer.throttled event = %+v", ev)
+	}
+
+	atomic.StoreInt32(&slow, 0)
+	if err := sched.Trigger("heavy"); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+	if info := sched.ListTimers()[0]; info.Throttle != 0 {
+		t.Errorf("Throttle after run within budget = %d, want 0", info.Throttle)
+	}
+	if ev := <-throttled.C; ev.Data.(map[string]interface{})["throttled"] != false {
+		t.Errorf("timer.throttled recovery event = %+v", ev)
+	}
+}
+
+// TestBudgetSkip проверяет пропуск тиков замедленным таймером
+func TestBudgetSkip(t *testing.T) {
+	b := newBudgetState(Budget{Wall: time.Second})
+	if skip, ticks := b.skip(); skip || ticks != 1 {
+		t.Errorf("skip() without throttle = %v, %d", skip, ticks)
+	}
+	atomic.StoreInt32(&b.throttle, 3)
+	var got []bool
+	for i := 0; i < 6; i++ {
+		skip, _ := b.skip()
+		got = append(got, skip)
+	}
+	want := []bool{true, true, false, true, true, false}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("skip() sequence = %v, want %v", got, want)
+		}
+	}
+}
+
+// TestNamespaces проверяет лимит пространства имен и его отключение
+func TestNamespaces(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+
+	if ns := Namespace("tenant/report"); ns != "tenant" {
+		t.Errorf("Namespace(tenant/report) = %q, want tenant", ns)
+	}
+	if ns := Namespace("report"); ns != "" {
+		t.Errorf("Namespace(report) = %q, want empty", ns)
+	}
+
+	sched.SetNamespaces(map[string]NamespaceOptions{"tenant": {MaxConcurrentRuns: 1}})
+	var runs int32
+	sched.AddTimer("tenant/report", 20*time.Millisecond, func(ctx context.Context) {
+		atomic.AddInt32(&runs, 1)
+	})
+	sched.AddTimer("cleanup", time.Hour, func(ctx context.Context) {})
+
+	// Лимит пространства не задерживает таймеры вне его
+	hold := make(chan struct{})
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sched.Execute(context.Background(), "tenant/hold", func(ctx context.Context) {
+			close(started)
+			<-hold
+		})
+	}()
+	<-started
+	if err := sched.Execute(context.Background(), "other", func(ctx context.Context) {}); err != nil {
+		t.Errorf("Execute(other) error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	err := sched.Execute(ctx, "tenant/queued", func(ctx context.Context) {
+		t.Error("run over namespace limit executed")
+	})
+	cancel()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Execute() over namespace limit error = %v, want context.DeadlineExceeded", err)
+	}
+	if infos := sched.ListNamespaces(); len(infos) != 1 || infos[0].Running != 1 || infos[0].Timers != 1 {
+		t.Errorf("ListNamespaces() = %+v, want tenant with 1 timer and 1 running", infos)
+	}
+	close(hold)
+	<-done
+
+	runCtx, stop := context.WithCancel(context.Background())
+	defer stop()
+	if err := sched.Start(runCtx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sched.Stop(context.Background())
+
+	if err := sched.DisableNamespace("missing"); !errors.Is(err, ErrNamespaceNotFound) {
+		t.Errorf("DisableNamespace(missing) error = %v, want ErrNamespaceNotFound", err)
+	}
+	if err := sched.DisableNamespace("tenant"); err != nil {
+		t.Fatalf("DisableNamespace() error = %v", err)
+	}
+	if err := sched.Trigger("tenant/report"); !errors.Is(err, ErrNamespaceDisabled) {
+		t.Errorf("Trigger() in disabled namespace error = %v, want ErrNamespaceDisabled", err)
+	}
+	if err := sched.Trigger("cleanup"); err != nil {
+		t.Errorf("Trigger(cleanup) error = %v", err)
+	}
+	for _, info := range sched.ListTimers() {
+		if info.Name == "tenant/report" && info.State != StateNamespaceDisabled {
+			t.Errorf("tenant/report state = %q, want %q", info.State, StateNamespaceDisabled)
+		}
+	}
+	// Запуск, начатый до отключения, успевает завершиться
+	time.Sleep(20 * time.Millisecond)
+	before := atomic.LoadInt32(&runs)
+	time.Sleep(80 * time.Millisecond)
+	if n := atomic.LoadInt32(&runs); n != before {
+		t.Errorf("disabled namespace ran %d times", n-before)
+	}
+
+	if err := sched.EnableNamespace("tenant"); err != nil {
+		t.Fatalf("EnableNamespace() error = %v", err)
+	}
+	time.Sleep(80 * time.Millisecond)
+	if atomic.LoadInt32(&runs) == before {
+		t.Error("enabled namespace did not run")
+	}
+}
+
+// TestSimulate проверяет свойства вычисленных запусков для случайных
+// интервалов: шаг равен интервалу, запуски укладываются в промежуток,
+// их число равно числу интервалов в нем
+func TestSimulate(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		sched, log := setupTestScheduler(t)
+		interval := time.Duration(1+rng.Intn(3600)) * time.Second
+		to := from.Add(time.Duration(rng.Int63n(int64(48 * time.Hour))))
+		sched.AddTimer("job", interval, func(ctx context.Context) {})
+
+		runs := sched.Simulate(from, to)
+		if want := int(to.Sub(from) / interval); len(runs) != want {
+			t.Fatalf("interval %v: %d runs, want %d", interval, len(runs), want)
+		}
+		prev := from
+		for _, run := range runs {
+			if run.At.Sub(prev) != interval || run.At.After(to) || run.Concurrent != 1 {
+				t.Fatalf("interval %v: run %+v after %v", interval, run, prev)
+			}
+			prev = run.At
+		}
+		log.Close()
+	}
+}
+
+// TestSimulate_Overlap проверяет отложенные и отброшенные тики долгих
+// запусков, одновременные запуски и выполнение обработчиков
+func TestSimulate_Overlap(t *testing.T) {
+	sched, log := setupTestScheduler(t)
+	defer log.Close()
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	var seen []time.Time
+	sched.AddTimer("slow", 10*time.Second, func(ctx context.Context) {
+		seen = append(seen, Now(ctx))
+	})
+	sched.AddTimer("fast", 15*time.Second, func(ctx context.Context) {
+		if Now(ctx).Equal(from.Add(30 * time.Second)) {
+			panic("boom")
+		}
+	})
+	sched.AddTimer("paused", time.Second, func(ctx context.Context) {})
+	sched.Pause("paused")
+
+	runs, err := sched.SimulateWith(context.Background(), from, from.Add(time.Minute), SimulateOptions{
+		Execute:   true,
+		Durations: map[string]time.Duration{"slow": 25 * time.Second, "fast": time.Second},
+	})
+	if err != nil {
+		t.Fatalf("SimulateWith() error = %v", err)
+	}
+
+	var got []string
+	for _, run := range runs {
+		got = append(got, fmt.Sprintf("%s@%ds/missed=%d/concurrent=%d/%s",
+			run.Timer, int(run.At.Sub(from).Seconds()), run.MissedTicks, run.Concurrent, run.Outcome))
+	}
+	// slow (OverlapSkip): тики 20 и 30 приходятся на запуск 10-35
+	// и отбрасываются, следующий запуск - по тику 40
+	want := []string{
+		"slow@10s/missed=0/concurrent=1/success",
+		"fast@15s/missed=0/concurrent=2/success",
+		"fast@30s/missed=0/concurrent=2/panic",
+		"slow@40s/missed=2/concurrent=1/success",
+		"fast@45s/missed=0/concurrent=2/success",
+		"fast@60s/missed=0/concurrent=2/success",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("runs:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if len(seen) != 2 || !seen[1].Equal(from.Add(40*time.Second)) {
+		t.Errorf("handler clock = %v", seen)
+	}
+}
@@ -0,0 +1,264 @@
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"runtime/debug"
+	"sort"
+	"time"
+
+	"service-boilerplate/internal/execenv"
+)
+
+// SimulatedRun запуск таймера, вычисленный Simulate
+type SimulatedRun struct {
+	Timer string
+	// At время запуска
+	At time.Time
+	// Duration длительность запуска: из SimulateOptions.Durations, при
+	// выполнении - фактическая, иначе средняя по истории запусков таймера
+	Duration time.Duration
+	// MissedTicks тики таймера, отброшенные перед этим запуском, пока
+	// выполнялся предыдущий
+	MissedTicks int
+	// Concurrent сколько запусков всех таймеров, включая этот, выполняется
+	// в момент At
+	Concurrent int
+	// Outcome и Error результат выполненного обработчика (SimulateOptions.Execute)
+	Outcome string
+	Error   string
+}
+
+// SimulateOptions настройки Simulate
+type SimulateOptions struct {
+	// Execute выполнить обработчики в порядке запусков. Обработчик получает
+	// время запуска через Now(ctx); метрики, события и история не пишутся
+	Execute bool
+	// Durations длительность запусков таймеров по имени вместо фактической
+	// или средней по истории
+	Durations map[string]time.Duration
+}
+
+// simulatedTimeKey ключ контекста времени симулированного запуска
+type simulatedTimeKey struct{}
+
+// Now возвращает время запуска, если обработчик выполняется Simulate,
+// иначе текущее время. Обработчики, которые берут время из Now, ведут
+// себя в симуляции так же, как по расписанию
+func Now(ctx context.Context) time.Time {
+	if at, ok := ctx.Value(simulatedTimeKey{}).(time.Time); ok {
+		return at
+	}
+	return time.Now()
+}
+
+// Simulate вычисляет запуски таймеров по расписанию в промежутке (from, to],
+// как если бы планировщик был запущен в момент from, без выполнения
+// обработчиков
+func (s *Scheduler) Simulate(from, to time.Time) []SimulatedRun {
+	runs, _ := s.SimulateWith(context.Background(), from, to, SimulateOptions{})
+	return runs
+}
+
+// SimulateWith вычисляет и, если задано opts.Execute, выполняет запуски
+// таймеров в промежутке (from, to] в порядке времени. Таймер запускается
+// через каждый Interval (замедленный - через Interval*Throttle) от from,
+// однократный - в свое время запуска, если оно попадает в промежуток;
+// тики, пришедшие во время запуска, обрабатываются политикой перекрытия
+// таймера (OverlapPolicy), как у запущенного планировщика. Приостановленные,
+// отключенные таймеры и таймеры отключенного пространства имен не
+// запускаются. Ограничение одновременных запусков не применяется: Concurrent
+// показывает, сколько слотов понадобится
+func (s *Scheduler) SimulateWith(ctx context.Context, from, to time.Time, opts SimulateOptions) ([]SimulatedRun, error) {
+	s.mu.RLock()
+	timers := make([]*Timer, 0, len(s.timers))
+	for _, timer := range s.timers {
+		timers = append(timers, timer)
+	}
+	s.mu.RUnlock()
+	sort.Slice(timers, func(i, j int) bool { return timers[i].name < timers[j].name })
+
+	queue := &simQueue{}
+	for _, timer := range timers {
+		info := timer.info(true, false)
+		switch info.State {
+		case StateReadOnly, StateDisabled, StateNamespaceDisabled, StatePaused:
+			continue
+		}
+		step := info.Interval
+		if info.Throttle > 1 {
+			step *= time.Duration(info.Throttle)
+		}
+		sim := &simTimer{timer: timer, step: step, at: from.Add(step), duration: averageDuration(timer)}
+		if !timer.at.IsZero() {
+			// Однократный таймер запускается один раз в момент at
+			if !timer.at.After(from) {
+				continue
+			}
+			sim.once, sim.at = true, timer.at
+		} else if step <= 0 {
+			continue
+		}
+		if d, ok := opts.Durations[timer.name]; ok {
+			sim.duration = d
+		}
+		sim.tick = sim.at.Add(step)
+		if !sim.at.After(to) {
+			heap.Push(queue, sim)
+		}
+	}
+
+	var runs []SimulatedRun
+	for queue.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			return runs, err
+		}
+		sim := heap.Pop(queue).(*simTimer)
+		run := SimulatedRun{Timer: sim.timer.name, At: sim.at, Duration: sim.duration, MissedTicks: sim.missed}
+		if opts.Execute {
+			rec := s.simulateCall(ctx, sim.timer, sim.at)
+			run.Outcome, run.Error = rec.Outcome, rec.Error
+			if _, ok := opts.Durations[sim.timer.name]; !ok {
+				run.Duration = rec.Duration
+			}
+		}
+		runs = append(runs, run)
+		if sim.once {
+			continue
+		}
+
+		sim.advance(run.Duration)
+		if !sim.at.After(to) {
+			heap.Push(queue, sim)
+		}
+	}
+	countConcurrent(runs)
+	return runs, nil
+}
+
+// simulateCall выполняет обработчик таймера для Simulate с перехватом panic
+func (s *Scheduler) simulateCall(ctx context.Context, timer *Timer, at time.Time) (rec RunRecord) {
+	ctx = context.WithValue(ctx, simulatedTimeKey{}, at)
+	if !timer.env.IsZero() {
+		ctx = execenv.WithEnv(ctx, timer.env)
+	}
+	ctx, result := withRunResult(ctx)
+	start := time.Now()
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Timer: timer.name, Value: r, Stack: string(debug.Stack())}
+		}
+		rec = result.record(ctx, "", start, err)
+	}()
+	timer.handler(ctx)
+	return
+}
+
+// averageDuration возвращает среднюю длительность запусков из истории таймера
+func averageDuration(timer *Timer) time.Duration {
+	timer.stateMu.RLock()
+	defer timer.stateMu.RUnlock()
+	if len(timer.history) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, rec := range timer.history {
+		total += rec.Duration
+	}
+	return total / time.Duration(len(timer.history))
+}
+
+// countConcurrent заполняет Concurrent запусков, отсортированных по времени
+func countConcurrent(runs []SimulatedRun) {
+	var ends endHeap
+	for i := range runs {
+		for ends.Len() > 0 && !ends[0].After(runs[i].At) {
+			heap.Pop(&ends)
+		}
+		heap.Push(&ends, runs[i].At.Add(runs[i].Duration))
+		runs[i].Concurrent = ends.Len()
+	}
+}
+
+// simTimer состояние таймера в симуляции
+type simTimer struct {
+	timer    *Timer
+	step     time.Duration
+	duration time.Duration
+	// at время следующего запуска, tick - следующего тика после него
+	at   time.Time
+	tick time.Time
+	// missed тики, отброшенные перед следующим запуском
+	missed int
+	// pending запуски в очереди таймера (OverlapQueue)
+	pending int
+	// once однократный таймер (AddOneShot)
+	once bool
+}
+
+// advance вычисляет следующий запуск после запуска длительностью d
+// по политике перекрытия таймера
+func (t *simTimer) advance(d time.Duration) {
+	t.missed = 0
+	if t.timer.overlap == OverlapConcurrent {
+		t.at, t.tick = t.tick, t.tick.Add(t.step)
+		return
+	}
+
+	// Тики, пришедшие во время запуска
+	end := t.at.Add(d)
+	ticks := 0
+	if t.tick.Before(end) {
+		ticks = int((end.Sub(t.tick) + t.step - 1) / t.step)
+		t.tick = t.tick.Add(time.Duration(ticks) * t.step)
+	}
+	if t.timer.overlap == OverlapQueue {
+		t.pending += ticks
+		if t.pending > MaxQueuedRuns {
+			t.missed, t.pending = t.pending-MaxQueuedRuns, MaxQueuedRuns
+		}
+		if t.pending > 0 {
+			// Запуск из очереди начинается по окончании предыдущего
+			t.pending--
+			t.at = end
+			return
+		}
+	} else {
+		t.missed = ticks
+	}
+	t.at, t.tick = t.tick, t.tick.Add(t.step)
+}
+
+// simQueue очередь таймеров симуляции по времени следующего запуска
+type simQueue []*simTimer
+
+func (q simQueue) Len() int { return len(q) }
+func (q simQueue) Less(i, j int) bool {
+	if q[i].at.Equal(q[j].at) {
+		return q[i].timer.name < q[j].timer.name
+	}
+	return q[i].at.Before(q[j].at)
+}
+func (q simQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *simQueue) Push(x interface{}) { *q = append(*q, x.(*simTimer)) }
+func (q *simQueue) Pop() interface{} {
+	old := *q
+	t := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return t
+}
+
+// endHeap время окончания выполняющихся запусков
+type endHeap []time.Time
+
+func (h endHeap) Len() int            { return len(h) }
+func (h endHeap) Less(i, j int) bool  { return h[i].Before(h[j]) }
+func (h endHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *endHeap) Push(x interface{}) { *h = append(*h, x.(time.Time)) }
+func (h *endHeap) Pop() interface{} {
+	old := *h
+	end := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return end
+}
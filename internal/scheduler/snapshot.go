@@ -0,0 +1,227 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"service-boilerplate/internal/store"
+)
+
+// snapshotBucket bucket хранилища со снимком планировщика на момент остановки
+const snapshotBucket = "scheduler.snapshot"
+
+// snapshotKey ключ снимка в snapshotBucket
+const snapshotKey = "latest"
+
+// Snapshot состояние таймеров планировщика: время последнего запуска,
+// счетчики panic и пропущенных тиков, пауза. Обработчики в снимок
+// не входят, поэтому восстанавливается только состояние таймеров,
+// уже добавленных через AddTimer
+type Snapshot struct {
+	TakenAt time.Time       `json:"taken_at"`
+	Timers  []TimerSnapshot `json:"timers"`
+}
+
+// TimerSnapshot состояние одного таймера в Snapshot
+type TimerSnapshot struct {
+	Name        string        `json:"name"`
+	Interval    time.Duration `json:"interval"`
+	LastRun     time.Time     `json:"last_run"`
+	PanicCount  int           `json:"panic_count"`
+	MissedTicks int           `json:"missed_ticks"`
+	Paused      bool          `json:"paused"`
+}
+
+// Snapshot возвращает снимок состояния таймеров, отсортированный по имени
+func (s *Scheduler) Snapshot() Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snapshot()
+}
+
+// snapshot снимает состояние таймеров. Вызывается под s.mu
+func (s *Scheduler) snapshot() Snapshot {
+	snap := Snapshot{TakenAt: time.Now().UTC(), Timers: make([]TimerSnapshot, 0, len(s.timers))}
+	for _, t := range s.timers {
+		t.stateMu.RLock()
+		lastRun, interval := t.lastRun, t.interval
+		t.stateMu.RUnlock()
+		snap.Timers = append(snap.Timers, TimerSnapshot{
+			Name:        t.name,
+			Interval:    interval,
+			LastRun:     lastRun,
+			PanicCount:  int(atomic.LoadInt32(&t.panicCount)),
+			MissedTicks: int(atomic.LoadInt64(&t.missedTicks)),
+			Paused:      atomic.LoadInt32(&t.paused) == 1,
+		})
+	}
+	sort.Slice(snap.Timers, func(i, j int) bool { return snap.Timers[i].Name < snap.Timers[j].Name })
+	return snap
+}
+
+// Restore применяет снимок к таймерам планировщика. Время последнего
+// запуска берется более позднее из текущего и снимка; пауза и счетчики
+// заменяются значениями снимка, если он не старше уже примененного.
+// Таймеры снимка, которых нет в планировщике, пропускаются и
+// перечисляются в ошибке ErrTimerNotFound
+func (s *Scheduler) Restore(snap Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.restore(snap)
+}
+
+// restore применяет снимок. Вызывается под s.mu
+func (s *Scheduler) restore(snap Snapshot) error {
+	stale := !snap.TakenAt.IsZero() && snap.TakenAt.Before(s.restoredAt)
+	if !stale {
+		s.restoredAt = snap.TakenAt
+	}
+
+	var unknown []string
+	for _, ts := range snap.Timers {
+		t, ok := s.timers[ts.Name]
+		if !ok {
+			unknown = append(unknown, ts.Name)
+			continue
+		}
+		t.stateMu.Lock()
+		if ts.LastRun.After(t.lastRun) {
+			t.lastRun = ts.LastRun
+		}
+		t.stateMu.Unlock()
+		if stale {
+			continue
+		}
+		atomic.StoreInt32(&t.panicCount, int32(ts.PanicCount))
+		atomic.StoreInt64(&t.missedTicks, int64(ts.MissedTicks))
+		var paused int32
+		if ts.Paused {
+			paused = 1
+		}
+		atomic.StoreInt32(&t.paused, paused)
+	}
+
+	s.log.Info("Scheduler state restored", map[string]interface{}{
+		"taken_at": snap.TakenAt.Format(time.RFC3339),
+		"timers":   len(snap.Timers) - len(unknown),
+		"stale":    stale,
+	})
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: %s", ErrTimerNotFound, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// restoreSnapshot применяет снимок, сохраненный при предыдущей остановке.
+// Счетчики panic обнуляются: перезапуск сервиса снова включает таймеры,
+// отключенные после panic. Вызывается под s.mu
+func (s *Scheduler) restoreSnapshot() {
+	if s.store == nil {
+		return
+	}
+	var snap Snapshot
+	err := s.store.GetJSON(snapshotBucket, snapshotKey, &snap)
+	if errors.Is(err, store.ErrNotFound) {
+		return
+	}
+	if err == nil {
+		for i := range snap.Timers {
+			snap.Timers[i].PanicCount = 0
+		}
+		err = s.restore(snap)
+	}
+	// Таймеры, удаленные из кода, пропускаются без предупреждения
+	if err != nil && !errors.Is(err, ErrTimerNotFound) {
+		s.log.Warn("Failed to restore scheduler state", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+// saveSnapshot сохраняет снимок в хранилище при остановке. Планировщик,
+// который не запускался, не перезаписывает сохраненное состояние
+func (s *Scheduler) saveSnapshot() {
+	s.mu.RLock()
+	st := s.store
+	started := s.ctx != nil
+	snap := s.snapshot()
+	s.mu.RUnlock()
+	if st == nil || !started {
+		return
+	}
+	if err := st.PutJSON(snapshotBucket, snapshotKey, snap); err != nil {
+		s.log.Warn("Failed to save scheduler state", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+// Handoff передает состояние планировщика между экземплярами active/passive
+// через файл рядом с блокировкой выборов: лидер записывает снимок при
+// освобождении лидерства, новый лидер применяет его при получении.
+// Привязывается к выборам через election.Bind. После аварийного завершения
+// лидера снимок не записывается и новый лидер продолжает со своим состоянием
+type Handoff struct {
+	sched *Scheduler
+	path  string
+}
+
+// NewHandoff создает передачу состояния через файл path
+func NewHandoff(sched *Scheduler, path string) *Handoff {
+	return &Handoff{sched: sched, path: path}
+}
+
+// Name возвращает имя задачи
+func (h *Handoff) Name() string {
+	return "scheduler-handoff"
+}
+
+// AfterStart применяет снимок предыдущего лидера, если он есть
+func (h *Handoff) AfterStart(ctx context.Context) error {
+	data, err := os.ReadFile(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read scheduler handoff: %w", err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("failed to decode scheduler handoff %s: %w", h.path, err)
+	}
+	if err := h.sched.Restore(snap); err != nil && !errors.Is(err, ErrTimerNotFound) {
+		return err
+	}
+	return nil
+}
+
+// BeforeStop записывает снимок для следующего лидера. Файл заменяется
+// атомарно, чтобы резервный экземпляр не прочитал его частично
+func (h *Handoff) BeforeStop(ctx context.Context) error {
+	data, err := json.MarshalIndent(h.sched.Snapshot(), "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(h.path), filepath.Base(h.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write scheduler handoff: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write scheduler handoff: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write scheduler handoff: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), h.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write scheduler handoff: %w", err)
+	}
+	return nil
+}
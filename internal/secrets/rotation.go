@@ -0,0 +1,262 @@
+package secrets
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/logger"
+)
+
+// Hook обработчик смены секрета: переподключает модуль с новыми учетными
+// данными. Ошибка пишется в лог, модуль остается со старыми
+type Hook func(ctx context.Context) error
+
+// hookTimeout ограничивает выполнение одного обработчика смены секрета
+const hookTimeout = 30 * time.Second
+
+// source источник секрета, содержимое которого отслеживает Rotator
+type source struct {
+	path  string
+	sum   [sha256.Size]byte
+	hooks []Hook
+}
+
+// Rotator реализует task.Task: периодически перечитывает файлы секретов
+// (DSN, пароли, смонтированные Kubernetes Secret) и при изменении содержимого
+// вызывает обработчики модулей, которые этими секретами пользуются.
+// Внешние источники (продление аренды Vault) сообщают о смене через Notify
+type Rotator struct {
+	log      *logger.Logger
+	interval time.Duration
+
+	mu      sync.Mutex
+	events  *events.Bus
+	sources map[string]*source
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewRotator создает отслеживание секретов с периодом проверки interval
+func NewRotator(log *logger.Logger, interval time.Duration) *Rotator {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &Rotator{
+		log:      log,
+		interval: interval,
+		sources:  make(map[string]*source),
+		ctx:      context.Background(),
+	}
+}
+
+// SetEvents включает публикацию смены секретов (events.TypeSecretRotated)
+func (r *Rotator) SetEvents(bus *events.Bus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = bus
+}
+
+// WatchFile отслеживает содержимое файла path секрета name. Пустой path
+// регистрирует секрет без файла: о его смене сообщает Notify.
+// Вызывается до AfterStart
+func (r *Rotator) WatchFile(name, path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.source(name).path = path
+}
+
+// OnRotate добавляет обработчик смены секрета name. Вызывается до AfterStart
+func (r *Rotator) OnRotate(name string, hook Hook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	src := r.source(name)
+	src.hooks = append(src.hooks, hook)
+}
+
+// source возвращает источник секрета name, создавая его. Вызывается под mu
+func (r *Rotator) source(name string) *source {
+	src, ok := r.sources[name]
+	if !ok {
+		src = &source{}
+		r.sources[name] = src
+	}
+	return src
+}
+
+// Names возвращает имена отслеживаемых секретов
+func (r *Rotator) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.sources))
+	for name := range r.sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Name возвращает имя задачи
+func (r *Rotator) Name() string {
+	return "secrets"
+}
+
+// ReadOnly задача запускается в режиме только для чтения: переподключение
+// с новыми учетными данными не меняет состояние
+func (r *Rotator) ReadOnly() bool {
+	return true
+}
+
+// AfterStart запоминает текущее содержимое файлов и запускает проверку
+func (r *Rotator) AfterStart(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, src := range r.sources {
+		if src.path == "" {
+			continue
+		}
+		sum, err := fileSum(src.path)
+		if err != nil {
+			r.log.Warn("Failed to read secret file", map[string]interface{}{
+				"secret": name,
+				"error":  err.Error(),
+			})
+		}
+		src.sum = sum
+	}
+
+	r.ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+	go r.loop(r.ctx, r.done)
+	return nil
+}
+
+// BeforeStop останавливает проверку
+func (r *Rotator) BeforeStop(ctx context.Context) error {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel = nil
+	r.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// loop проверяет файлы секретов с заданным интервалом
+func (r *Rotator) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Check()
+		}
+	}
+}
+
+// Check перечитывает файлы секретов и вызывает обработчики изменившихся.
+// Возвращает имена изменившихся секретов. Нечитаемый файл (например,
+// в момент замены) не считается изменением: проверка повторится
+func (r *Rotator) Check() []string {
+	r.mu.Lock()
+	var changed []string
+	for name, src := range r.sources {
+		if src.path == "" {
+			continue
+		}
+		sum, err := fileSum(src.path)
+		if err != nil || sum == src.sum {
+			continue
+		}
+		src.sum = sum
+		changed = append(changed, name)
+	}
+	r.mu.Unlock()
+
+	sort.Strings(changed)
+	for _, name := range changed {
+		r.rotate(name, "file")
+	}
+	return changed
+}
+
+// Notify сообщает о смене секрета name внешним источником и вызывает
+// его обработчики
+func (r *Rotator) Notify(name string) error {
+	r.mu.Lock()
+	src, ok := r.sources[name]
+	if ok && src.path != "" {
+		src.sum, _ = fileSum(src.path)
+	}
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown secret: %s", name)
+	}
+	return r.rotate(name, "notify")
+}
+
+// rotate вызывает обработчики секрета name по очереди и возвращает
+// их объединенную ошибку
+func (r *Rotator) rotate(name, trigger string) error {
+	r.mu.Lock()
+	ctx := r.ctx
+	hooks := append([]Hook(nil), r.sources[name].hooks...)
+	bus := r.events
+	r.mu.Unlock()
+
+	var errs []error
+	for _, hook := range hooks {
+		hookCtx, cancel := context.WithTimeout(ctx, hookTimeout)
+		err := hook(hookCtx)
+		cancel()
+		if err != nil {
+			r.log.Warn("Secret rotation hook failed", map[string]interface{}{
+				"secret": name,
+				"error":  err.Error(),
+			})
+			errs = append(errs, err)
+		}
+	}
+	r.log.Info("Secret rotated", map[string]interface{}{
+		"secret":  name,
+		"trigger": trigger,
+		"hooks":   len(hooks),
+		"failed":  len(errs),
+	})
+	if bus != nil {
+		bus.Publish(events.TypeSecretRotated, map[string]interface{}{
+			"secret":  name,
+			"trigger": trigger,
+			"failed":  len(errs),
+		})
+	}
+	return errors.Join(errs...)
+}
+
+// fileSum возвращает хеш содержимого файла
+func fileSum(path string) ([sha256.Size]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(data), nil
+}
@@ -0,0 +1,77 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/logger"
+)
+
+// TestRotator проверяет вызов обработчиков при изменении файла секрета
+// и при Notify
+func TestRotator(t *testing.T) {
+	dir := t.TempDir()
+	log, err := logger.New("test-secrets", dir)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	defer log.Close()
+
+	path := filepath.Join(dir, "dsn")
+	os.WriteFile(path, []byte("postgres://old"), 0600)
+
+	r := NewRotator(log, time.Hour)
+	bus := events.New()
+	sub := bus.Subscribe(10, events.TypeSecretRotated)
+	r.SetEvents(bus)
+	var calls []string
+	r.WatchFile("database", path)
+	r.OnRotate("database", func(ctx context.Context) error {
+		data, _ := os.ReadFile(path)
+		calls = append(calls, string(data))
+		return nil
+	})
+	r.OnRotate("vault", func(ctx context.Context) error { return errors.New("reconnect failed") })
+
+	if err := r.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+	defer r.BeforeStop(context.Background())
+
+	if changed := r.Check(); len(changed) != 0 {
+		t.Errorf("Check() = %v for unchanged file", changed)
+	}
+	os.WriteFile(path, []byte("postgres://new"), 0600)
+	if changed := r.Check(); strings.Join(changed, ",") != "database" {
+		t.Errorf("Check() = %v, want [database]", changed)
+	}
+	if changed := r.Check(); len(changed) != 0 {
+		t.Errorf("repeated Check() = %v, want no changes", changed)
+	}
+	if strings.Join(calls, ",") != "postgres://new" {
+		t.Errorf("hook calls = %v", calls)
+	}
+
+	if err := r.Notify("vault"); err == nil {
+		t.Error("Notify() expected hook error")
+	}
+	if err := r.Notify("missing"); err == nil {
+		t.Error("Notify() expected error for unknown secret")
+	}
+	for _, want := range []string{"database", "vault"} {
+		select {
+		case e := <-sub.C:
+			if data := e.Data.(map[string]interface{}); data["secret"] != want {
+				t.Errorf("event data = %v, want secret %s", data, want)
+			}
+		default:
+			t.Errorf("rotation event for %s was not published", want)
+		}
+	}
+}
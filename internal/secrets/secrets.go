@@ -0,0 +1,145 @@
+// Package secrets шифрует значения конфигурации (AES-256-GCM), чтобы учетные
+// данные в config.yaml не хранились на диске открытым текстом. Ключ берется
+// из переменной окружения SERVICE_SECRET_KEY или из файла ключа рядом
+// с конфигом; на Windows файл ключа защищен DPAPI
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Tag YAML тег зашифрованного значения: `password: !encrypted <base64>`
+const Tag = "!encrypted"
+
+// KeyEnv переменная окружения с ключом в base64. Имеет приоритет над файлом ключа
+const KeyEnv = "SERVICE_SECRET_KEY"
+
+// KeyFileName имя файла ключа в директории конфига
+const KeyFileName = "secret.key"
+
+// KeySize размер ключа AES-256
+const KeySize = 32
+
+// ErrNoKey возвращается, если ключ не задан ни в окружении, ни в файле
+var ErrNoKey = errors.New("secret key not found")
+
+// GenerateKey создает случайный ключ
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Encrypt шифрует plaintext и возвращает base64(nonce || ciphertext)
+func Encrypt(key, plaintext []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+// Decrypt расшифровывает значение, полученное Encrypt. Префикс Tag допускается
+func Decrypt(key []byte, value string) ([]byte, error) {
+	value = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), Tag))
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted value: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("invalid encrypted value: too short")
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt value: wrong key or corrupted data")
+	}
+	return plaintext, nil
+}
+
+// LoadKey возвращает ключ из KeyEnv или, если переменная не задана,
+// из файла path. Отсутствие обоих источников - ErrNoKey
+func LoadKey(path string) ([]byte, error) {
+	if value := os.Getenv(KeyEnv); value != "" {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", KeyEnv, err)
+		}
+		return key, checkKey(key)
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: set %s or create %s", ErrNoKey, KeyEnv, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	key, err := unprotect(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unprotect key file %s: %w", path, err)
+	}
+	return key, checkKey(key)
+}
+
+// SaveKey записывает ключ в файл path, доступный только владельцу
+// (на Windows - защищенный DPAPI). Существующий файл не перезаписывается:
+// с потерей ключа теряются все зашифрованные им значения
+func SaveKey(path string, key []byte) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	data, err := protect(key)
+	if err != nil {
+		return fmt.Errorf("failed to protect key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create key file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	return f.Close()
+}
+
+// checkKey проверяет размер ключа
+func checkKey(key []byte) error {
+	if len(key) != KeySize {
+		return fmt.Errorf("invalid secret key size %d, want %d bytes", len(key), KeySize)
+	}
+	return nil
+}
+
+// newGCM создает AES-GCM для ключа
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
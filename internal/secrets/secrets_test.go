@@ -0,0 +1,62 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// TestEncryptDecrypt проверяет шифрование и расшифровку значения
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := Encrypt(key, []byte("s3cret"))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	for _, v := range []string{value, Tag + " " + value} {
+		plaintext, err := Decrypt(key, v)
+		if err != nil || string(plaintext) != "s3cret" {
+			t.Errorf("Decrypt(%q) = %q, %v", v, plaintext, err)
+		}
+	}
+
+	other, _ := GenerateKey()
+	if _, err := Decrypt(other, value); err == nil {
+		t.Error("Decrypt() with wrong key succeeded")
+	}
+	if _, err := Encrypt([]byte("short"), []byte("x")); err == nil {
+		t.Error("Encrypt() with short key succeeded")
+	}
+}
+
+// TestLoadKey проверяет загрузку ключа из окружения и файла
+func TestLoadKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), KeyFileName)
+	t.Setenv(KeyEnv, "")
+
+	if _, err := LoadKey(path); !errors.Is(err, ErrNoKey) {
+		t.Errorf("LoadKey() without key error = %v, want ErrNoKey", err)
+	}
+
+	key, _ := GenerateKey()
+	if err := SaveKey(path, key); err != nil {
+		t.Fatalf("SaveKey() error = %v", err)
+	}
+	if err := SaveKey(path, key); err == nil {
+		t.Error("SaveKey() overwrote existing key file")
+	}
+	if loaded, err := LoadKey(path); err != nil || string(loaded) != string(key) {
+		t.Errorf("LoadKey() from file = %v, want saved key", err)
+	}
+
+	envKey, _ := GenerateKey()
+	t.Setenv(KeyEnv, base64.StdEncoding.EncodeToString(envKey))
+	if loaded, err := LoadKey(path); err != nil || string(loaded) != string(envKey) {
+		t.Errorf("LoadKey() from env = %v, want env key", err)
+	}
+}
@@ -0,0 +1,45 @@
+//go:build windows
+// +build windows
+
+package secrets
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// protect шифрует ключ через DPAPI с областью машины: файл ключа может
+// прочитать и служба (LocalSystem), и администратор, выполняющий encrypt
+func protect(key []byte) ([]byte, error) {
+	return cryptData(key, true)
+}
+
+// unprotect расшифровывает ключ, защищенный protect
+func unprotect(data []byte) ([]byte, error) {
+	return cryptData(data, false)
+}
+
+// cryptData вызывает CryptProtectData или CryptUnprotectData
+func cryptData(data []byte, encrypt bool) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, windows.ERROR_INVALID_DATA
+	}
+	in := windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+	var out windows.DataBlob
+	var err error
+	if encrypt {
+		err = windows.CryptProtectData(&in, nil, nil, 0, nil,
+			windows.CRYPTPROTECT_UI_FORBIDDEN|windows.CRYPTPROTECT_LOCAL_MACHINE, &out)
+	} else {
+		err = windows.CryptUnprotectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+
+	result := make([]byte, out.Size)
+	copy(result, unsafe.Slice(out.Data, out.Size))
+	return result, nil
+}
@@ -0,0 +1,332 @@
+// Package store предоставляет встроенное персистентное хранилище (bbolt)
+// для состояния, которое должно переживать перезапуск сервиса:
+// время запусков таймеров, незавершенные задания, состояние задач
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"service-boilerplate/internal/apperr"
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/task"
+)
+
+var (
+	// ErrNotFound ключ отсутствует в bucket
+	ErrNotFound = errors.New("key not found")
+	// ErrClosed хранилище не открыто
+	ErrClosed = errors.New("store is not open")
+)
+
+// openTimeout время ожидания блокировки файла, занятого другим процессом
+const openTimeout = time.Second
+
+// Store хранилище ключ-значение, разбитое на bucket'ы. Реализует task.Task:
+// файл открывается в AfterStart и закрывается в BeforeStop
+type Store struct {
+	log  *logger.Logger
+	path string
+
+	mu sync.RWMutex
+	db *bolt.DB
+}
+
+// New создает хранилище для файла path. Файл открывается при запуске
+func New(log *logger.Logger, path string) *Store {
+	return &Store{log: log, path: path}
+}
+
+// Path возвращает путь к файлу хранилища
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Name возвращает имя задачи
+func (s *Store) Name() string {
+	return "store"
+}
+
+// ShutdownPhase закрывает хранилище после задач, сохраняющих в него состояние
+func (s *Store) ShutdownPhase() task.Phase {
+	return task.PhaseRelease
+}
+
+// AfterStart открывает файл хранилища, создавая директорию при необходимости
+func (s *Store) AfterStart(ctx context.Context) error {
+	return s.Open()
+}
+
+// BeforeStop закрывает файл хранилища
+func (s *Store) BeforeStop(ctx context.Context) error {
+	return s.Close()
+}
+
+// Open открывает файл хранилища. Повторный вызов ничего не делает
+func (s *Store) Open() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.db != nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return apperr.Access(filepath.Dir(s.path), fmt.Errorf("failed to create store directory: %w", err))
+	}
+	db, err := bolt.Open(s.path, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return apperr.Access(s.path, fmt.Errorf("failed to open store %s: %w", s.path, err))
+	}
+	s.db = db
+
+	s.log.Info("Store opened", map[string]interface{}{"path": s.path})
+	return nil
+}
+
+// Close закрывает файл хранилища
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.db == nil {
+		return nil
+	}
+	err := s.db.Close()
+	s.db = nil
+	s.log.Info("Store closed")
+	return err
+}
+
+// Get возвращает копию значения ключа или ErrNotFound
+func (s *Store) Get(bucket, key string) ([]byte, error) {
+	var value []byte
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return ErrNotFound
+		}
+		v := b.Get([]byte(key))
+		if v == nil {
+			return ErrNotFound
+		}
+		value = append([]byte(nil), v...)
+		return nil
+	})
+	return value, err
+}
+
+// Put сохраняет значение ключа, создавая bucket при необходимости
+func (s *Store) Put(bucket, key string, value []byte) error {
+	return s.update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), value)
+	})
+}
+
+// Delete удаляет ключ. Отсутствие ключа не является ошибкой
+func (s *Store) Delete(bucket, key string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+// ForEach вызывает fn для каждого ключа bucket в порядке сортировки ключей.
+// value действителен только внутри fn
+func (s *Store) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	return s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			return fn(string(k), v)
+		})
+	})
+}
+
+// Replace атомарно заменяет содержимое bucket набором items
+func (s *Store) Replace(bucket string, items map[string][]byte) error {
+	return s.update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket([]byte(bucket)); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+			return err
+		}
+		b, err := tx.CreateBucket([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		for k, v := range items {
+			if err := b.Put([]byte(k), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetJSON читает значение ключа и декодирует его из JSON в v
+func (s *Store) GetJSON(bucket, key string, v interface{}) error {
+	data, err := s.Get(bucket, key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// PutJSON кодирует v в JSON и сохраняет как значение ключа
+func (s *Store) PutJSON(bucket, key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.Put(bucket, key, data)
+}
+
+// view выполняет транзакцию чтения
+func (s *Store) view(fn func(tx *bolt.Tx) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.db == nil {
+		return ErrClosed
+	}
+	return s.db.View(fn)
+}
+
+// update выполняет транзакцию записи
+func (s *Store) update(fn func(tx *bolt.Tx) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.db == nil {
+		return ErrClosed
+	}
+	return s.db.Update(fn)
+}
+
+// taskStateBucket bucket хранилища с состоянием задач task.Restorer
+const taskStateBucket = "tasks.state"
+
+// taskState запись состояния задачи
+type taskState struct {
+	SavedAt time.Time `json:"saved_at"`
+	Data    []byte    `json:"data"`
+}
+
+// TaskState возвращает состояние задачи name, сохраненное SaveTaskState.
+// Отсутствие состояния означает холодный старт и не является ошибкой.
+// Реализует lifecycle.StateLoader
+func (s *Store) TaskState(name string) (task.State, error) {
+	var st taskState
+	err := s.GetJSON(taskStateBucket, name, &st)
+	if errors.Is(err, ErrNotFound) {
+		return task.State{}, nil
+	}
+	if err != nil {
+		return task.State{}, fmt.Errorf("failed to read state of task %s: %w", name, err)
+	}
+	return task.State{Warm: true, Data: st.Data, SavedAt: st.SavedAt}, nil
+}
+
+// SaveTaskState сохраняет состояние задачи name для следующего запуска.
+// Обычно вызывается задачей в BeforeStop или после обработки очередной
+// порции данных
+func (s *Store) SaveTaskState(name string, data []byte) error {
+	return s.PutJSON(taskStateBucket, name, taskState{SavedAt: time.Now().UTC(), Data: data})
+}
+
+// DeleteTaskState удаляет состояние задачи name: следующий запуск будет холодным
+func (s *Store) DeleteTaskState(name string) error {
+	return s.Delete(taskStateBucket, name)
+}
+
+// identityBucket bucket хранилища с идентичностью экземпляра
+const identityBucket = "identity"
+
+// instanceIDKey ключ идентификатора экземпляра в identityBucket
+const instanceIDKey = "instance_id"
+
+// LookupInstanceID возвращает сохраненный идентификатор экземпляра или
+// пустую строку, если он еще не сохранен. Хранилище не создается и не
+// меняется: закрытое открывается только для чтения на время вызова
+func (s *Store) LookupInstanceID() (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	db := s.db
+	if db == nil {
+		if _, err := os.Stat(s.path); errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		opened, err := bolt.Open(s.path, 0600, &bolt.Options{Timeout: openTimeout, ReadOnly: true})
+		if err != nil {
+			return "", fmt.Errorf("failed to open store %s: %w", s.path, err)
+		}
+		defer opened.Close()
+		db = opened
+	}
+
+	var id string
+	err := db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket([]byte(identityBucket)); b != nil {
+			id = string(b.Get([]byte(instanceIDKey)))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read instance id: %w", err)
+	}
+	return id, nil
+}
+
+// InstanceID возвращает идентификатор экземпляра, сохраненный в хранилище.
+// При первом вызове идентификатор создается функцией generate и сохраняется,
+// поэтому он не меняется между перезапусками. Закрытое хранилище
+// открывается только на время вызова
+func (s *Store) InstanceID(generate func() string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db := s.db
+	if db == nil {
+		if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+			return "", fmt.Errorf("failed to create store directory: %w", err)
+		}
+		opened, err := bolt.Open(s.path, 0600, &bolt.Options{Timeout: openTimeout})
+		if err != nil {
+			return "", fmt.Errorf("failed to open store %s: %w", s.path, err)
+		}
+		defer opened.Close()
+		db = opened
+	}
+
+	var id string
+	err := db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(identityBucket))
+		if err != nil {
+			return err
+		}
+		if v := b.Get([]byte(instanceIDKey)); len(v) > 0 {
+			id = string(v)
+			return nil
+		}
+		id = generate()
+		return b.Put([]byte(instanceIDKey), []byte(id))
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read instance id: %w", err)
+	}
+	return id, nil
+}
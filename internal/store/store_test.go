@@ -0,0 +1,168 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"service-boilerplate/internal/logger"
+)
+
+// setupTestStore создает и открывает тестовое хранилище
+func setupTestStore(t *testing.T) (*Store, *logger.Logger) {
+	tmpDir := t.TempDir()
+	log, err := logger.New("test-store", tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	st := New(log, filepath.Join(tmpDir, "data", "state.db"))
+	if err := st.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+	return st, log
+}
+
+// TestPutGetDelete проверяет базовые операции с ключами
+func TestPutGetDelete(t *testing.T) {
+	st, log := setupTestStore(t)
+	defer log.Close()
+	defer st.Close()
+
+	if _, err := st.Get("b", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() missing bucket error = %v, want ErrNotFound", err)
+	}
+	if err := st.Put("b", "k", []byte("v")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if v, err := st.Get("b", "k"); err != nil || string(v) != "v" {
+		t.Errorf("Get() = %q, %v, want v", v, err)
+	}
+	if err := st.Delete("b", "k"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := st.Get("b", "k"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after delete error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestReplaceForEach проверяет замену содержимого bucket и обход ключей
+func TestReplaceForEach(t *testing.T) {
+	st, log := setupTestStore(t)
+	defer log.Close()
+	defer st.Close()
+
+	st.Put("b", "old", []byte("1"))
+	if err := st.Replace("b", map[string][]byte{"a": []byte("1"), "c": []byte("3")}); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+
+	var keys []string
+	st.ForEach("b", func(key string, value []byte) error {
+		keys = append(keys, key)
+		return nil
+	})
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "c" {
+		t.Errorf("ForEach() keys = %v, want [a c]", keys)
+	}
+}
+
+// TestPersistence проверяет сохранение данных между открытиями
+func TestPersistence(t *testing.T) {
+	st, log := setupTestStore(t)
+	defer log.Close()
+
+	type state struct{ Count int }
+	if err := st.PutJSON("task", "state", state{Count: 7}); err != nil {
+		t.Fatalf("PutJSON() error = %v", err)
+	}
+	if err := st.BeforeStop(context.Background()); err != nil {
+		t.Fatalf("BeforeStop() error = %v", err)
+	}
+	if _, err := st.Get("task", "state"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Get() on closed store error = %v, want ErrClosed", err)
+	}
+
+	if err := st.Open(); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer st.Close()
+	var got state
+	if err := st.GetJSON("task", "state", &got); err != nil || got.Count != 7 {
+		t.Errorf("GetJSON() = %+v, %v, want Count 7", got, err)
+	}
+}
+
+// TestTaskState проверяет холодный и теплый старт задачи
+func TestTaskState(t *testing.T) {
+	st, log := setupTestStore(t)
+	defer log.Close()
+	defer st.Close()
+
+	state, err := st.TaskState("reader")
+	if err != nil || state.Warm || state.Data != nil {
+		t.Errorf("TaskState() before save = %+v, %v, want cold", state, err)
+	}
+	if err := st.SaveTaskState("reader", []byte("offset=42")); err != nil {
+		t.Fatalf("SaveTaskState() error = %v", err)
+	}
+	state, err = st.TaskState("reader")
+	if err != nil || !state.Warm || string(state.Data) != "offset=42" || state.SavedAt.IsZero() {
+		t.Errorf("TaskState() after save = %+v, %v, want warm offset=42", state, err)
+	}
+	if err := st.DeleteTaskState("reader"); err != nil {
+		t.Fatalf("DeleteTaskState() error = %v", err)
+	}
+	if state, _ := st.TaskState("reader"); state.Warm {
+		t.Error("TaskState() after delete is warm")
+	}
+}
+
+// TestInstanceID проверяет, что идентификатор создается один раз
+// и сохраняется между открытиями хранилища
+func TestInstanceID(t *testing.T) {
+	tmpDir := t.TempDir()
+	log, err := logger.New("test-store", tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	defer log.Close()
+	path := filepath.Join(tmpDir, "data", "state.db")
+
+	calls := 0
+	generate := func() string {
+		calls++
+		return "generated"
+	}
+
+	// Поиск не создает хранилище
+	if id, err := New(log, path).LookupInstanceID(); err != nil || id != "" {
+		t.Fatalf("LookupInstanceID() = %q, %v, want empty", id, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("LookupInstanceID() created the store file: %v", err)
+	}
+
+	// Закрытое хранилище открывается на время вызова
+	first, err := New(log, path).InstanceID(generate)
+	if err != nil || first != "generated" {
+		t.Fatalf("InstanceID() = %q, %v, want generated", first, err)
+	}
+
+	st := New(log, path)
+	if err := st.Open(); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer st.Close()
+	second, err := st.InstanceID(generate)
+	if err != nil || second != first {
+		t.Errorf("InstanceID() = %q, %v, want %q", second, err, first)
+	}
+	if calls != 1 {
+		t.Errorf("generate called %d times, want 1", calls)
+	}
+	if id, err := st.LookupInstanceID(); err != nil || id != first {
+		t.Errorf("LookupInstanceID() = %q, %v, want %q", id, err, first)
+	}
+}
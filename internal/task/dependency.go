@@ -0,0 +1,66 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+)
+
+// Dependency внешнее условие, которое должно выполниться до запуска задачи
+type Dependency struct {
+	// Name описание условия для логов и ошибок (tcp redis:6379)
+	Name string
+	// Ready проверяет условие один раз. nil - условие выполнено
+	Ready func(ctx context.Context) error
+}
+
+// Dependent может реализовываться задачей для объявления внешних условий
+// запуска. lifecycle.Manager ждет их выполнения перед AfterStart
+type Dependent interface {
+	// Dependencies возвращает условия запуска задачи
+	Dependencies() []Dependency
+}
+
+// TCP условие доступности TCP адреса addr (host:port)
+func TCP(addr string) Dependency {
+	return Dependency{
+		Name: "tcp " + addr,
+		Ready: func(ctx context.Context) error {
+			var d net.Dialer
+			conn, err := d.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		},
+	}
+}
+
+// File условие существования файла или директории path
+func File(path string) Dependency {
+	return Dependency{
+		Name: "file " + path,
+		Ready: func(ctx context.Context) error {
+			_, err := os.Stat(path)
+			return err
+		},
+	}
+}
+
+// DNS условие разрешения имени host хотя бы в один адрес
+func DNS(host string) Dependency {
+	return Dependency{
+		Name: "dns " + host,
+		Ready: func(ctx context.Context) error {
+			addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+			if err != nil {
+				return err
+			}
+			if len(addrs) == 0 {
+				return fmt.Errorf("no addresses for %s", host)
+			}
+			return nil
+		},
+	}
+}
@@ -1,7 +1,10 @@
 // Package task предоставляет интерфейс Task для lifecycle
 package task
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Task определяет интерфейс для компонентов с lifecycle
 type Task interface {
@@ -12,3 +15,84 @@ type Task interface {
 	// BeforeStop вызывается перед остановкой сервиса
 	BeforeStop(ctx context.Context) error
 }
+
+// Phase определяет фазу остановки задачи. Задачи останавливаются
+// в порядке возрастания фазы, внутри одной фазы - в обратном порядке регистрации
+type Phase int
+
+const (
+	// PhaseStopAccepting прекращение приема новой работы (listeners, consumers)
+	PhaseStopAccepting Phase = 100
+	// PhaseDefault фаза по умолчанию для задач без явной фазы
+	PhaseDefault Phase = 500
+	// PhaseFlush сброс буферов и незавершенных данных
+	PhaseFlush Phase = 800
+	// PhaseRelease освобождение ресурсов (соединения, файлы)
+	PhaseRelease Phase = 900
+	// PhaseCleanup удаление временных файлов, сокетов и блокировок
+	// после освобождения ресурсов
+	PhaseCleanup Phase = 950
+)
+
+// Phased может реализовываться задачей для указания фазы остановки
+type Phased interface {
+	// ShutdownPhase возвращает фазу остановки задачи
+	ShutdownPhase() Phase
+}
+
+// StopTimeouter может реализовываться задачей, которой нужно больше
+// (или меньше) времени на BeforeStop, чем задано lifecycle.Manager
+type StopTimeouter interface {
+	// StopTimeout возвращает время на BeforeStop, 0 - значение менеджера
+	StopTimeout() time.Duration
+}
+
+// PhaseOf возвращает фазу остановки задачи
+func PhaseOf(t Task) Phase {
+	if p, ok := t.(Phased); ok {
+		return p.ShutdownPhase()
+	}
+	return PhaseDefault
+}
+
+// Checker может реализовываться задачей для предстартовой проверки
+// (доступность зависимостей, права доступа) в режиме dry-run
+type Checker interface {
+	// Check проверяет готовность задачи к запуску без побочных эффектов
+	Check(ctx context.Context) error
+}
+
+// State сохраненное состояние задачи от предыдущего запуска сервиса
+type State struct {
+	// Warm состояние найдено в хранилище (теплый старт). При холодном
+	// старте (первый запуск, хранилище отключено или очищено) false
+	Warm bool
+	// Data состояние, сохраненное задачей (курсоры, смещения), или nil
+	Data []byte
+	// SavedAt время сохранения состояния
+	SavedAt time.Time
+}
+
+// Restorer может реализовываться задачей, которая продолжает работу
+// с места остановки. lifecycle.Manager вызывает Restore перед AfterStart
+// и передает состояние из встроенного хранилища. Ошибка Restore
+// прерывает запуск так же, как ошибка AfterStart
+type Restorer interface {
+	// Restore получает состояние предыдущего запуска
+	Restore(ctx context.Context, state State) error
+}
+
+// ReadOnly может реализовываться задачей, которая не изменяет состояние
+// (файлы, хранилище, внешние системы) и поэтому запускается в режиме
+// только для чтения (service.read_only). Остальные задачи в этом режиме
+// не запускаются
+type ReadOnly interface {
+	// ReadOnly возвращает true, если задача безопасна в режиме только для чтения
+	ReadOnly() bool
+}
+
+// IsReadOnly проверяет, что задача безопасна в режиме только для чтения
+func IsReadOnly(t Task) bool {
+	r, ok := t.(ReadOnly)
+	return ok && r.ReadOnly()
+}
@@ -0,0 +1,115 @@
+// Package tracing настраивает OpenTelemetry трассировку: каждый запуск
+// таймера получает корневой спан, а спаны отправляются в коллектор по OTLP/HTTP
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"service-boilerplate/internal/appctx"
+	"service-boilerplate/internal/logger"
+)
+
+// Config содержит настройки трассировки
+type Config struct {
+	// Endpoint URL приема спанов OTLP/HTTP, например http://localhost:4318/v1/traces
+	Endpoint string
+	// SampleRatio доля трассируемых запусков от 0 до 1
+	SampleRatio float64
+	// Timeout таймаут отправки пачки спанов
+	Timeout time.Duration
+	// Identity идентичность сервиса для атрибутов ресурса
+	Identity appctx.Identity
+}
+
+// Provider владеет TracerProvider и экспортером спанов. Реализует task.Task:
+// экспортер подключается в AfterStart, а BeforeStop отправляет оставшиеся спаны
+type Provider struct {
+	log      *logger.Logger
+	cfg      Config
+	provider *sdktrace.TracerProvider
+}
+
+// New создает Provider и делает его глобальным для otel.Tracer, чтобы
+// обработчики могли создавать дочерние спаны. Спаны, завершенные до
+// AfterStart, не отправляются
+func New(log *logger.Logger, cfg Config) *Provider {
+	if cfg.SampleRatio <= 0 {
+		cfg.SampleRatio = 1
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	attrs := []attribute.KeyValue{attribute.String("service.name", cfg.Identity.Service)}
+	if cfg.Identity.Version != "" {
+		attrs = append(attrs, attribute.String("service.version", cfg.Identity.Version))
+	}
+	if cfg.Identity.InstanceID != "" {
+		attrs = append(attrs, attribute.String("service.instance.id", cfg.Identity.InstanceID))
+	}
+	if cfg.Identity.Hostname != "" {
+		attrs = append(attrs, attribute.String("host.name", cfg.Identity.Hostname))
+	}
+
+	p := &Provider{
+		log: log,
+		cfg: cfg,
+		provider: sdktrace.NewTracerProvider(
+			sdktrace.WithResource(resource.NewSchemaless(attrs...)),
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		),
+	}
+	otel.SetTracerProvider(p.provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		log.Warn("Tracing error", map[string]interface{}{"error": err.Error()})
+	}))
+	return p
+}
+
+// Tracer возвращает именованный трассировщик
+func (p *Provider) Tracer(name string) trace.Tracer {
+	return p.provider.Tracer(name)
+}
+
+// Name возвращает имя задачи
+func (p *Provider) Name() string {
+	return "tracing"
+}
+
+// ReadOnly задача запускается в режиме только для чтения: экспорт спанов не изменяет состояние сервиса
+func (p *Provider) ReadOnly() bool {
+	return true
+}
+
+// AfterStart подключает экспортер OTLP/HTTP
+func (p *Provider) AfterStart(ctx context.Context) error {
+	exporter, err := otlptracehttp.New(ctx,
+		otlptracehttp.WithEndpointURL(p.cfg.Endpoint),
+		otlptracehttp.WithTimeout(p.cfg.Timeout),
+	)
+	if err != nil {
+		return err
+	}
+	p.provider.RegisterSpanProcessor(sdktrace.NewBatchSpanProcessor(exporter))
+
+	p.log.Info("Tracing started", map[string]interface{}{
+		"endpoint":     p.cfg.Endpoint,
+		"sample_ratio": p.cfg.SampleRatio,
+	})
+	return nil
+}
+
+// BeforeStop отправляет накопленные спаны и останавливает экспортер
+func (p *Provider) BeforeStop(ctx context.Context) error {
+	return p.provider.Shutdown(ctx)
+}
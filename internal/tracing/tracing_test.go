@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"service-boilerplate/internal/appctx"
+	"service-boilerplate/internal/logger"
+)
+
+// TestExport проверяет отправку спанов в коллектор при остановке
+func TestExport(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+		size     int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/traces" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests++
+		size += len(data)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	defer srv.Close()
+
+	log, err := logger.New("test-tracing", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	p := New(log, Config{
+		Endpoint: srv.URL + "/v1/traces",
+		Identity: appctx.Identity{Service: "svc", InstanceID: "abc", Version: "1.0.0"},
+	})
+	ctx := context.Background()
+	if err := p.AfterStart(ctx); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+
+	_, span := p.Tracer("test").Start(ctx, "run")
+	if !span.SpanContext().IsSampled() {
+		t.Error("span is not sampled with default sample ratio")
+	}
+	span.End()
+
+	if err := p.BeforeStop(ctx); err != nil {
+		t.Fatalf("BeforeStop() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 1 || size == 0 {
+		t.Errorf("export requests = %d, size = %d, want 1 non-empty request", requests, size)
+	}
+}
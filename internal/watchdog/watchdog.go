@@ -0,0 +1,238 @@
+// Package watchdog следит за количеством горутин и размером кучи
+package watchdog
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/logger"
+)
+
+// Config содержит пороги срабатывания watchdog
+type Config struct {
+	// Interval период между замерами
+	Interval time.Duration
+	// MaxGoroutines порог количества горутин (0 = не проверять)
+	MaxGoroutines int
+	// MaxHeapBytes порог размера кучи в байтах (0 = не проверять)
+	MaxHeapBytes uint64
+	// SustainedSamples сколько замеров подряд порог должен быть превышен
+	SustainedSamples int
+	// Restart запрашивать graceful перезапуск при срабатывании
+	Restart bool
+}
+
+// Sample представляет один замер
+type Sample struct {
+	Time       time.Time
+	Goroutines int
+	HeapBytes  uint64
+}
+
+// Watchdog реализует task.Task и периодически проверяет ресурсы процесса
+type Watchdog struct {
+	cfg       Config
+	log       *logger.Logger
+	onRestart func(reason string)
+	events    *events.Bus
+
+	mu       sync.Mutex
+	exceeded int
+	fired    bool
+	last     Sample
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	// sample позволяет подменить источник замеров в тестах
+	sample func() Sample
+}
+
+// New создает новый watchdog. onRestart вызывается при срабатывании,
+// если в конфигурации включен Restart
+func New(log *logger.Logger, cfg Config, onRestart func(reason string)) *Watchdog {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	if cfg.SustainedSamples <= 0 {
+		cfg.SustainedSamples = 1
+	}
+	return &Watchdog{
+		cfg:       cfg,
+		log:       log,
+		onRestart: onRestart,
+		sample:    readSample,
+	}
+}
+
+// SetEvents включает публикацию срабатываний (events.TypeWatchdogFired)
+func (w *Watchdog) SetEvents(bus *events.Bus) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.events = bus
+}
+
+// readSample снимает текущие показатели рантайма
+func readSample() Sample {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return Sample{
+		Time:       time.Now(),
+		Goroutines: runtime.NumGoroutine(),
+		HeapBytes:  ms.HeapAlloc,
+	}
+}
+
+// Name возвращает имя задачи
+func (w *Watchdog) Name() string {
+	return "watchdog"
+}
+
+// ReadOnly задача запускается в режиме только для чтения: watchdog только наблюдает за процессом
+func (w *Watchdog) ReadOnly() bool {
+	return true
+}
+
+// AfterStart запускает фоновую проверку
+func (w *Watchdog) AfterStart(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	runCtx, cancel := context.WithCancel(ctx)
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	w.log.Info("Watchdog started", map[string]interface{}{
+		"interval":          w.cfg.Interval.String(),
+		"max_goroutines":    w.cfg.MaxGoroutines,
+		"max_heap_bytes":    w.cfg.MaxHeapBytes,
+		"sustained_samples": w.cfg.SustainedSamples,
+		"restart":           w.cfg.Restart,
+	})
+
+	go w.loop(runCtx, w.done)
+	return nil
+}
+
+// BeforeStop останавливает фоновую проверку
+func (w *Watchdog) BeforeStop(ctx context.Context) error {
+	w.mu.Lock()
+	cancel := w.cancel
+	done := w.done
+	w.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// loop выполняет замеры с заданным интервалом
+func (w *Watchdog) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Check()
+		}
+	}
+}
+
+// Check выполняет один замер и возвращает true, если watchdog сработал
+func (w *Watchdog) Check() bool {
+	s := w.sample()
+
+	w.mu.Lock()
+	w.last = s
+	over := w.exceedsLocked(s)
+	if !over {
+		if w.exceeded > 0 {
+			w.log.Info("Watchdog thresholds back to normal", map[string]interface{}{
+				"goroutines": s.Goroutines,
+				"heap_bytes": s.HeapBytes,
+			})
+		}
+		w.exceeded = 0
+		w.fired = false
+		w.mu.Unlock()
+		return false
+	}
+
+	w.exceeded++
+	if w.exceeded < w.cfg.SustainedSamples || w.fired {
+		w.mu.Unlock()
+		return false
+	}
+	// Срабатываем один раз на каждый эпизод превышения
+	w.fired = true
+	bus := w.events
+	w.mu.Unlock()
+
+	w.log.Error("Watchdog detected sustained resource growth", map[string]interface{}{
+		"goroutines":      s.Goroutines,
+		"heap_bytes":      s.HeapBytes,
+		"max_goroutines":  w.cfg.MaxGoroutines,
+		"max_heap_bytes":  w.cfg.MaxHeapBytes,
+		"samples":         w.cfg.SustainedSamples,
+		"goroutine_dump":  goroutineDump(),
+		"restart_enabled": w.cfg.Restart,
+	})
+	if bus != nil {
+		bus.Publish(events.TypeWatchdogFired, map[string]interface{}{
+			"goroutines":     s.Goroutines,
+			"heap_bytes":     s.HeapBytes,
+			"max_goroutines": w.cfg.MaxGoroutines,
+			"max_heap_bytes": w.cfg.MaxHeapBytes,
+			"restart":        w.cfg.Restart,
+		})
+	}
+
+	if w.cfg.Restart && w.onRestart != nil {
+		w.onRestart("watchdog thresholds exceeded")
+	}
+	return true
+}
+
+// exceedsLocked проверяет превышение порогов
+func (w *Watchdog) exceedsLocked(s Sample) bool {
+	if w.cfg.MaxGoroutines > 0 && s.Goroutines > w.cfg.MaxGoroutines {
+		return true
+	}
+	if w.cfg.MaxHeapBytes > 0 && s.HeapBytes > w.cfg.MaxHeapBytes {
+		return true
+	}
+	return false
+}
+
+// LastSample возвращает последний замер
+func (w *Watchdog) LastSample() Sample {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.last
+}
+
+// goroutineDump возвращает стеки всех горутин
+func goroutineDump() string {
+	var buf bytes.Buffer
+	if p := pprof.Lookup("goroutine"); p != nil {
+		p.WriteTo(&buf, 2)
+	}
+	return buf.String()
+}
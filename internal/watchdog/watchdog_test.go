@@ -0,0 +1,121 @@
+package watchdog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/events"
+	"service-boilerplate/internal/logger"
+)
+
+// setupTestWatchdog создает тестовый watchdog с подменным источником замеров
+func setupTestWatchdog(t *testing.T, cfg Config, onRestart func(string)) (*Watchdog, *logger.Logger, *Sample) {
+	tmpDir := t.TempDir()
+	log, err := logger.New("test-watchdog", tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	current := &Sample{}
+	w := New(log, cfg, onRestart)
+	w.sample = func() Sample { return *current }
+	return w, log, current
+}
+
+// TestCheck_BelowThreshold проверяет отсутствие срабатывания при нормальных значениях
+func TestCheck_BelowThreshold(t *testing.T) {
+	w, log, current := setupTestWatchdog(t, Config{MaxGoroutines: 100, SustainedSamples: 2}, nil)
+	defer log.Close()
+
+	current.Goroutines = 50
+	for i := 0; i < 5; i++ {
+		if w.Check() {
+			t.Fatal("Check() fired below threshold")
+		}
+	}
+}
+
+// TestCheck_Sustained проверяет срабатывание только после N замеров подряд
+func TestCheck_Sustained(t *testing.T) {
+	var reason string
+	w, log, current := setupTestWatchdog(t, Config{
+		MaxHeapBytes:     1024,
+		SustainedSamples: 3,
+		Restart:          true,
+	}, func(r string) { reason = r })
+	defer log.Close()
+	bus := events.New()
+	fired := bus.Subscribe(10)
+	w.SetEvents(bus)
+
+	current.HeapBytes = 4096
+	if w.Check() || w.Check() {
+		t.Fatal("Check() fired before sustained samples reached")
+	}
+	if !w.Check() {
+		t.Fatal("Check() did not fire after sustained samples")
+	}
+	if reason == "" {
+		t.Error("restart callback was not called")
+	}
+	select {
+	case ev := <-fired.C:
+		if ev.Type != events.TypeWatchdogFired {
+			t.Errorf("event type = %s", ev.Type)
+		}
+	default:
+		t.Error("watchdog.fired event was not published")
+	}
+
+	// Повторно в рамках того же эпизода не срабатываем
+	if w.Check() {
+		t.Error("Check() fired twice for the same episode")
+	}
+
+	// После нормализации счетчик сбрасывается
+	current.HeapBytes = 10
+	w.Check()
+	current.HeapBytes = 4096
+	if w.Check() {
+		t.Error("Check() fired immediately after reset")
+	}
+}
+
+// TestCheck_NoRestartWhenDisabled проверяет что перезапуск не запрашивается без флага
+func TestCheck_NoRestartWhenDisabled(t *testing.T) {
+	called := false
+	w, log, current := setupTestWatchdog(t, Config{MaxGoroutines: 1, SustainedSamples: 1}, func(string) { called = true })
+	defer log.Close()
+
+	current.Goroutines = 10
+	if !w.Check() {
+		t.Fatal("Check() did not fire")
+	}
+	if called {
+		t.Error("restart callback called with Restart disabled")
+	}
+}
+
+// TestStartStop проверяет запуск и остановку фоновой проверки
+func TestStartStop(t *testing.T) {
+	w, log, current := setupTestWatchdog(t, Config{Interval: 10 * time.Millisecond}, nil)
+	defer log.Close()
+
+	current.Goroutines = 7
+	if err := w.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := w.BeforeStop(ctx); err != nil {
+		t.Fatalf("BeforeStop() error = %v", err)
+	}
+
+	if w.LastSample().Goroutines != 7 {
+		t.Errorf("LastSample().Goroutines = %d, want 7", w.LastSample().Goroutines)
+	}
+}
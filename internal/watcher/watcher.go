@@ -0,0 +1,369 @@
+// Package watcher следит за директориями и передает события файлов
+// (создание, изменение, удаление) зарегистрированным обработчикам.
+// События одного файла объединяются за время debounce, обработчики
+// вызываются через scheduler.Execute с защитой от panic и метриками
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+	"service-boilerplate/internal/scheduler"
+)
+
+// Op тип события файла
+type Op string
+
+// Типы событий
+const (
+	OpCreate Op = "create"
+	OpModify Op = "modify"
+	OpDelete Op = "delete"
+)
+
+// Event событие файла после debounce
+type Event struct {
+	Watch string
+	Path  string
+	Op    Op
+	Time  time.Time
+}
+
+// Handler обрабатывает событие файла
+type Handler func(ctx context.Context, ev Event)
+
+// Watch описывает наблюдаемую директорию
+type Watch struct {
+	// Name имя, по которому регистрируется обработчик
+	Name string
+	// Dir наблюдаемая директория (без вложенных)
+	Dir string
+	// Pattern glob для имени файла (filepath.Match), пустой - все файлы
+	Pattern string
+	// Debounce время, за которое события одного файла объединяются в одно
+	Debounce time.Duration
+	// Existing передать файлы, уже лежащие в директории при запуске, как OpCreate
+	Existing bool
+}
+
+// eventBuffer размер очереди событий одного наблюдения
+const eventBuffer = 256
+
+// ErrUnknownWatch наблюдение с таким именем не настроено
+var ErrUnknownWatch = errors.New("unknown watch")
+
+// Watcher следит за настроенными директориями. Реализует task.Task и task.Checker
+type Watcher struct {
+	log       *logger.Logger
+	scheduler *scheduler.Scheduler
+	metrics   *metrics.Server
+	watches   map[string]*watchState
+	byDir     map[string][]*watchState
+
+	fsn    *fsnotify.Watcher
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// watchState состояние одного наблюдения
+type watchState struct {
+	Watch
+	handler Handler
+	events  chan Event
+
+	mu      sync.Mutex
+	pending map[string]*pendingEvent
+}
+
+// pendingEvent события файла, накопленные за время debounce
+type pendingEvent struct {
+	first Op
+	last  Op
+	timer *time.Timer
+}
+
+// New создает watcher для списка наблюдений
+func New(log *logger.Logger, sched *scheduler.Scheduler, metricsServer *metrics.Server, watches []Watch) *Watcher {
+	w := &Watcher{
+		log:       log,
+		scheduler: sched,
+		metrics:   metricsServer,
+		watches:   make(map[string]*watchState),
+		byDir:     make(map[string][]*watchState),
+	}
+	for _, watch := range watches {
+		watch.Dir = filepath.Clean(watch.Dir)
+		ws := &watchState{
+			Watch:   watch,
+			events:  make(chan Event, eventBuffer),
+			pending: make(map[string]*pendingEvent),
+		}
+		w.watches[watch.Name] = ws
+		w.byDir[watch.Dir] = append(w.byDir[watch.Dir], ws)
+	}
+	return w
+}
+
+// Handle регистрирует обработчик наблюдения name
+func (w *Watcher) Handle(name string, handler Handler) error {
+	ws, ok := w.watches[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownWatch, name)
+	}
+	if ws.handler != nil {
+		return fmt.Errorf("handler for watch %s already registered", name)
+	}
+	ws.handler = handler
+	return nil
+}
+
+// Name возвращает имя задачи
+func (w *Watcher) Name() string {
+	return "watcher"
+}
+
+// Check проверяет, что директории существуют и для каждого наблюдения есть обработчик
+func (w *Watcher) Check(ctx context.Context) error {
+	var errs []error
+	for name, ws := range w.watches {
+		if ws.handler == nil {
+			errs = append(errs, fmt.Errorf("watch %s: no handler registered", name))
+		}
+		if info, err := os.Stat(ws.Dir); err != nil {
+			errs = append(errs, fmt.Errorf("watch %s: %w", name, err))
+		} else if !info.IsDir() {
+			errs = append(errs, fmt.Errorf("watch %s: %s is not a directory", name, ws.Dir))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AfterStart начинает наблюдение за директориями
+func (w *Watcher) AfterStart(ctx context.Context) error {
+	if len(w.watches) == 0 {
+		return nil
+	}
+	if err := w.Check(ctx); err != nil {
+		return err
+	}
+
+	fsn, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	for dir := range w.byDir {
+		if err := fsn.Add(dir); err != nil {
+			fsn.Close()
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+	w.fsn = fsn
+	w.ctx, w.cancel = context.WithCancel(ctx)
+
+	for _, ws := range w.watches {
+		w.wg.Add(1)
+		go w.dispatch(ws)
+		if ws.Existing {
+			w.wg.Add(1)
+			go w.emitExisting(ws)
+		}
+		w.log.Info("Watching directory", map[string]interface{}{
+			"watch":    ws.Name,
+			"dir":      ws.Dir,
+			"pattern":  ws.Pattern,
+			"debounce": ws.Debounce.String(),
+		})
+	}
+
+	w.wg.Add(1)
+	go w.loop()
+	return nil
+}
+
+// BeforeStop прекращает наблюдение и дожидается завершения обработчиков.
+// Необработанные события отбрасываются
+func (w *Watcher) BeforeStop(ctx context.Context) error {
+	if w.fsn == nil {
+		return nil
+	}
+	w.cancel()
+	w.fsn.Close()
+	for _, ws := range w.watches {
+		ws.mu.Lock()
+		for path, p := range ws.pending {
+			p.timer.Stop()
+			delete(ws.pending, path)
+		}
+		ws.mu.Unlock()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loop читает события fsnotify и распределяет их по наблюдениям
+func (w *Watcher) loop() {
+	defer w.wg.Done()
+	for {
+		select {
+		case ev, ok := <-w.fsn.Events:
+			if !ok {
+				return
+			}
+			w.route(ev)
+		case err, ok := <-w.fsn.Errors:
+			if !ok {
+				return
+			}
+			w.log.Warn("File watcher error", map[string]interface{}{"error": err.Error()})
+		}
+	}
+}
+
+// route передает событие fsnotify наблюдениям его директории
+func (w *Watcher) route(ev fsnotify.Event) {
+	var op Op
+	switch {
+	case ev.Has(fsnotify.Create):
+		op = OpCreate
+	case ev.Has(fsnotify.Write):
+		op = OpModify
+	case ev.Has(fsnotify.Remove), ev.Has(fsnotify.Rename):
+		op = OpDelete
+	default:
+		return
+	}
+
+	if op == OpCreate {
+		if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+			return
+		}
+	}
+
+	dir, base := filepath.Split(ev.Name)
+	for _, ws := range w.byDir[filepath.Clean(dir)] {
+		if ws.matches(base) {
+			ws.observe(w.ctx, ev.Name, op)
+		}
+	}
+}
+
+// emitExisting передает файлы, уже лежащие в директории, как OpCreate
+func (w *Watcher) emitExisting(ws *watchState) {
+	defer w.wg.Done()
+	entries, err := os.ReadDir(ws.Dir)
+	if err != nil {
+		w.log.Warn("Failed to list existing files", map[string]interface{}{"watch": ws.Name, "error": err.Error()})
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !ws.matches(entry.Name()) {
+			continue
+		}
+		ws.send(w.ctx, Event{Watch: ws.Name, Path: filepath.Join(ws.Dir, entry.Name()), Op: OpCreate, Time: time.Now()})
+	}
+}
+
+// dispatch вызывает обработчик для событий наблюдения по одному
+func (w *Watcher) dispatch(ws *watchState) {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case ev := <-ws.events:
+			if w.metrics != nil {
+				w.metrics.RecordWatchEvent(ws.Name, string(ev.Op))
+			}
+			w.scheduler.Execute(w.ctx, "watch:"+ws.Name, func(ctx context.Context) {
+				ws.handler(ctx, ev)
+			})
+		}
+	}
+}
+
+// matches проверяет имя файла по шаблону наблюдения
+func (ws *watchState) matches(base string) bool {
+	if ws.Pattern == "" {
+		return true
+	}
+	ok, _ := filepath.Match(ws.Pattern, base)
+	return ok
+}
+
+// observe накапливает событие файла и откладывает отправку на время debounce
+func (ws *watchState) observe(ctx context.Context, path string, op Op) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if p, ok := ws.pending[path]; ok {
+		p.last = op
+		p.timer.Reset(ws.Debounce)
+		return
+	}
+	ws.pending[path] = &pendingEvent{
+		first: op,
+		last:  op,
+		timer: time.AfterFunc(ws.Debounce, func() { ws.flush(ctx, path) }),
+	}
+}
+
+// flush отправляет объединенное событие файла
+func (ws *watchState) flush(ctx context.Context, path string) {
+	ws.mu.Lock()
+	p, ok := ws.pending[path]
+	delete(ws.pending, path)
+	ws.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	op := merge(p.first, p.last)
+	if op == "" {
+		return
+	}
+	ws.send(ctx, Event{Watch: ws.Name, Path: path, Op: op, Time: time.Now()})
+}
+
+// send ставит событие в очередь обработчика
+func (ws *watchState) send(ctx context.Context, ev Event) {
+	select {
+	case ws.events <- ev:
+	case <-ctx.Done():
+	}
+}
+
+// merge объединяет первое и последнее событие файла за время debounce.
+// Файл, созданный и удаленный в одном окне, не порождает события
+func merge(first, last Op) Op {
+	switch {
+	case last == OpDelete && first == OpCreate:
+		return ""
+	case last == OpDelete:
+		return OpDelete
+	case first == OpCreate:
+		return OpCreate
+	default:
+		// Изменение, в том числе удаление с повторным созданием
+		return OpModify
+	}
+}
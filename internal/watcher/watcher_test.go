@@ -0,0 +1,128 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"service-boilerplate/internal/logger"
+	"service-boilerplate/internal/metrics"
+	"service-boilerplate/internal/scheduler"
+)
+
+// setupTestWatcher создает watcher для одного наблюдения и канал событий
+func setupTestWatcher(t *testing.T, watch Watch) (*Watcher, chan Event, func()) {
+	log, err := logger.New("test-watcher", t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	m := metrics.New(log, false, "")
+	w := New(log, scheduler.New(log, m, 3, 0), m, []Watch{watch})
+
+	events := make(chan Event, 16)
+	if err := w.Handle(watch.Name, func(ctx context.Context, ev Event) { events <- ev }); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if err := w.AfterStart(context.Background()); err != nil {
+		t.Fatalf("AfterStart() error = %v", err)
+	}
+	return w, events, func() {
+		w.BeforeStop(context.Background())
+		log.Close()
+	}
+}
+
+// nextEvent ожидает событие
+func nextEvent(t *testing.T, events chan Event) Event {
+	t.Helper()
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event received")
+		return Event{}
+	}
+}
+
+// TestDebounce проверяет объединение событий файла и фильтр по шаблону
+func TestDebounce(t *testing.T) {
+	dir := t.TempDir()
+	_, events, cleanup := setupTestWatcher(t, Watch{Name: "inbox", Dir: dir, Pattern: "*.csv", Debounce: 50 * time.Millisecond})
+	defer cleanup()
+
+	path := filepath.Join(dir, "data.csv")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		f.WriteString("row\n")
+	}
+	f.Close()
+	os.WriteFile(filepath.Join(dir, "ignored.txt"), []byte("x"), 0644)
+
+	if ev := nextEvent(t, events); ev.Op != OpCreate || ev.Path != path || ev.Watch != "inbox" {
+		t.Errorf("event = %+v, want create of %s", ev, path)
+	}
+
+	os.Remove(path)
+	if ev := nextEvent(t, events); ev.Op != OpDelete {
+		t.Errorf("event = %+v, want delete", ev)
+	}
+
+	select {
+	case ev := <-events:
+		t.Errorf("unexpected event %+v", ev)
+	case <-time.After(150 * time.Millisecond):
+	}
+}
+
+// TestExisting проверяет передачу файлов, лежавших в директории при запуске
+func TestExisting(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "old.csv"), []byte("x"), 0644)
+
+	_, events, cleanup := setupTestWatcher(t, Watch{Name: "inbox", Dir: dir, Debounce: 10 * time.Millisecond, Existing: true})
+	defer cleanup()
+
+	if ev := nextEvent(t, events); ev.Op != OpCreate || filepath.Base(ev.Path) != "old.csv" {
+		t.Errorf("event = %+v, want create of old.csv", ev)
+	}
+}
+
+// TestCheck проверяет ошибки конфигурации наблюдений
+func TestCheck(t *testing.T) {
+	log, err := logger.New("test-watcher", t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	defer log.Close()
+
+	w := New(log, nil, nil, []Watch{{Name: "missing", Dir: filepath.Join(t.TempDir(), "nope")}})
+	if err := w.Check(context.Background()); err == nil {
+		t.Error("Check() expected error for missing dir and handler")
+	}
+	if err := w.Handle("other", nil); err == nil {
+		t.Error("Handle() expected error for unknown watch")
+	}
+}
+
+// TestMerge проверяет объединение первого и последнего события
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		first, last, want Op
+	}{
+		{OpCreate, OpModify, OpCreate},
+		{OpCreate, OpDelete, ""},
+		{OpModify, OpDelete, OpDelete},
+		{OpDelete, OpCreate, OpModify},
+		{OpModify, OpModify, OpModify},
+	}
+	for _, tt := range tests {
+		if got := merge(tt.first, tt.last); got != tt.want {
+			t.Errorf("merge(%s, %s) = %q, want %q", tt.first, tt.last, got, tt.want)
+		}
+	}
+}
@@ -321,7 +321,11 @@ exit /b 0
 echo.
 echo %CYAN%==^> Building binary: %~1...%NC%
 set OUTPUT=%BUILD_DIR%\%~1
-go build -ldflags="-s -w" -o %OUTPUT% .\cmd\service-boilerplate
+set COMMIT=unknown
+for /f %%i in ('git rev-parse --short HEAD 2^>nul') do set COMMIT=%%i
+if "%VERSION%"=="" set VERSION=dev
+set BUILDINFO_PKG=service-boilerplate/internal/buildinfo
+go build -ldflags="-s -w -X %BUILDINFO_PKG%.Version=%VERSION% -X %BUILDINFO_PKG%.Commit=%COMMIT%" -o %OUTPUT% .\cmd\service-boilerplate
 if %errorlevel% neq 0 (
     echo %RED%[ERROR] Build failed!%NC%
     exit /b 1
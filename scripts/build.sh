@@ -100,11 +100,16 @@ build() {
     print_header "Building binary: $output_name..."
     
     local output="$BUILD_DIR/$output_name"
+    local version="${VERSION:-$(git describe --tags --always --dirty 2>/dev/null || echo dev)}"
+    local commit="$(git rev-parse --short HEAD 2>/dev/null || echo unknown)"
+    local build_date="$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+    local pkg="service-boilerplate/internal/buildinfo"
+    local ldflags="-s -w -X ${pkg}.Version=${version} -X ${pkg}.Commit=${commit} -X ${pkg}.BuildDate=${build_date}"
     
     if [ -n "$goos" ]; then
-        GOOS="$goos" GOARCH="$goarch" go build -ldflags="-s -w" -o "$output" ./cmd/service-boilerplate
+        GOOS="$goos" GOARCH="$goarch" go build -ldflags="$ldflags" -o "$output" ./cmd/service-boilerplate
     else
-        go build -ldflags="-s -w" -o "$output" ./cmd/service-boilerplate
+        go build -ldflags="$ldflags" -o "$output" ./cmd/service-boilerplate
     fi
     
     print_success "Build complete: $output"
@@ -0,0 +1,252 @@
+package mocks
+
+import (
+	"sync"
+	"time"
+
+	"service-boilerplate/internal/scheduler"
+)
+
+// MockMetrics мок метрик планировщика для тестов. Реализует scheduler.Recorder
+// и считает вызовы без HTTP сервера и реестра Prometheus
+type MockMetrics struct {
+	mu           sync.RWMutex
+	runs         map[string]int
+	panics       map[string]int
+	durations    map[string][]time.Duration
+	traceIDs     map[string][]string
+	ticksMissed  map[string]int
+	queueWaits   map[string][]time.Duration
+	runsQueued   int
+	poolQueued   int
+	poolBusy     int
+	budgetOver   map[string]int
+	throttle     map[string]int
+	nsRuns       map[string]int
+	nsEnabled    map[string]bool
+	activeTimers int
+}
+
+var _ scheduler.Recorder = (*MockMetrics)(nil)
+
+// NewMockMetrics создает новый мок метрик
+func NewMockMetrics() *MockMetrics {
+	return &MockMetrics{
+		runs:        make(map[string]int),
+		panics:      make(map[string]int),
+		durations:   make(map[string][]time.Duration),
+		traceIDs:    make(map[string][]string),
+		ticksMissed: make(map[string]int),
+		queueWaits:  make(map[string][]time.Duration),
+		budgetOver:  make(map[string]int),
+		throttle:    make(map[string]int),
+		nsRuns:      make(map[string]int),
+		nsEnabled:   make(map[string]bool),
+	}
+}
+
+// RecordTimerRun записывает выполнение таймера
+func (m *MockMetrics) RecordTimerRun(timerName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs[timerName]++
+}
+
+// RecordTimerPanic записывает panic таймера
+func (m *MockMetrics) RecordTimerPanic(timerName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.panics[timerName]++
+}
+
+// RecordTimerDuration записывает длительность выполнения таймера
+// и идентификатор трассировки exemplar
+func (m *MockMetrics) RecordTimerDuration(timerName string, duration time.Duration, traceID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.durations[timerName] = append(m.durations[timerName], duration)
+	m.traceIDs[timerName] = append(m.traceIDs[timerName], traceID)
+}
+
+// RecordTimerTicksMissed записывает пропущенные тики таймера
+func (m *MockMetrics) RecordTimerTicksMissed(timerName string, count int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ticksMissed[timerName] += count
+}
+
+// RecordTimerQueueWait записывает время ожидания слота лимита запусков
+func (m *MockMetrics) RecordTimerQueueWait(timerName string, wait time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queueWaits[timerName] = append(m.queueWaits[timerName], wait)
+}
+
+// SetTimerRunsQueued устанавливает количество запусков, ожидающих слот
+func (m *MockMetrics) SetTimerRunsQueued(count int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runsQueued = count
+}
+
+// SetTimerHandlerPool устанавливает состояние пула обработчиков
+func (m *MockMetrics) SetTimerHandlerPool(queued, busy int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.poolQueued, m.poolBusy = queued, busy
+}
+
+// RecordTimerBudgetExceeded записывает запуск таймера сверх бюджета
+func (m *MockMetrics) RecordTimerBudgetExceeded(timerName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.budgetOver[timerName]++
+}
+
+// SetTimerThrottle устанавливает множитель интервала замедленного таймера
+func (m *MockMetrics) SetTimerThrottle(timerName string, factor int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.throttle[timerName] = factor
+}
+
+// RecordNamespaceRun записывает запуск таймера пространства имен
+func (m *MockMetrics) RecordNamespaceRun(namespace, result string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nsRuns[namespace+"/"+result]++
+}
+
+// SetNamespaceEnabled устанавливает состояние пространства имен
+func (m *MockMetrics) SetNamespaceEnabled(namespace string, enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nsEnabled[namespace] = enabled
+}
+
+// IncActiveTimers увеличивает счетчик активных таймеров
+func (m *MockMetrics) IncActiveTimers() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.activeTimers++
+}
+
+// DecActiveTimers уменьшает счетчик активных таймеров
+func (m *MockMetrics) DecActiveTimers() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.activeTimers--
+}
+
+// TimerRuns возвращает количество записанных выполнений таймера
+func (m *MockMetrics) TimerRuns(timerName string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.runs[timerName]
+}
+
+// TimerPanics возвращает количество записанных panic таймера
+func (m *MockMetrics) TimerPanics(timerName string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.panics[timerName]
+}
+
+// TimerDurations возвращает записанные длительности выполнения таймера
+func (m *MockMetrics) TimerDurations(timerName string) []time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]time.Duration(nil), m.durations[timerName]...)
+}
+
+// TimerTraceIDs возвращает идентификаторы трассировки exemplar для таймера
+// (пустая строка - запуск без трассировки)
+func (m *MockMetrics) TimerTraceIDs(timerName string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]string(nil), m.traceIDs[timerName]...)
+}
+
+// TimerTicksMissed возвращает количество записанных пропущенных тиков таймера
+func (m *MockMetrics) TimerTicksMissed(timerName string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.ticksMissed[timerName]
+}
+
+// TimerQueueWaits возвращает записанные времена ожидания слота таймером
+func (m *MockMetrics) TimerQueueWaits(timerName string) []time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]time.Duration(nil), m.queueWaits[timerName]...)
+}
+
+// RunsQueued возвращает последнее значение количества ожидающих запусков
+func (m *MockMetrics) RunsQueued() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.runsQueued
+}
+
+// HandlerPool возвращает последнее число запусков, ожидающих исполнителя,
+// и занятых исполнителей пула обработчиков
+func (m *MockMetrics) HandlerPool() (queued, busy int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.poolQueued, m.poolBusy
+}
+
+// TimerBudgetExceeded возвращает количество запусков таймера сверх бюджета
+func (m *MockMetrics) TimerBudgetExceeded(timerName string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.budgetOver[timerName]
+}
+
+// TimerThrottle возвращает последний множитель интервала таймера
+func (m *MockMetrics) TimerThrottle(timerName string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.throttle[timerName]
+}
+
+// NamespaceRuns возвращает количество запусков пространства имен с результатом
+func (m *MockMetrics) NamespaceRuns(namespace, result string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.nsRuns[namespace+"/"+result]
+}
+
+// NamespaceEnabled возвращает последнее состояние пространства имен
+func (m *MockMetrics) NamespaceEnabled(namespace string) (enabled, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	enabled, ok = m.nsEnabled[namespace]
+	return enabled, ok
+}
+
+// ActiveTimers возвращает текущее значение счетчика активных таймеров
+func (m *MockMetrics) ActiveTimers() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.activeTimers
+}
+
+// Reset сбрасывает все счетчики
+func (m *MockMetrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs = make(map[string]int)
+	m.panics = make(map[string]int)
+	m.durations = make(map[string][]time.Duration)
+	m.traceIDs = make(map[string][]string)
+	m.ticksMissed = make(map[string]int)
+	m.queueWaits = make(map[string][]time.Duration)
+	m.runsQueued = 0
+	m.poolQueued, m.poolBusy = 0, 0
+	m.budgetOver = make(map[string]int)
+	m.throttle = make(map[string]int)
+	m.nsRuns = make(map[string]int)
+	m.nsEnabled = make(map[string]bool)
+	m.activeTimers = 0
+}
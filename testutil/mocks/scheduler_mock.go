@@ -0,0 +1,304 @@
+package mocks
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"service-boilerplate/internal/scheduler"
+)
+
+// SchedulerCall описывает вызов метода мока планировщика
+type SchedulerCall struct {
+	Method string
+	Timer  string
+}
+
+// mockTimer таймер, зарегистрированный в моке
+type mockTimer struct {
+	interval time.Duration
+	handler  scheduler.Handler
+	paused   bool
+	runs     int
+	panics   int
+	lastRun  time.Time
+	// at время запуска однократного таймера, fired - таймер выполнен Tick
+	at    time.Time
+	fired bool
+}
+
+// MockScheduler мок планировщика для тестов. Реализует scheduler.Runner:
+// таймеры не запускаются по расписанию, а выполняются синхронно через
+// Trigger или Fire. Позволяет принудительно вызвать panic или задержку
+type MockScheduler struct {
+	mu      sync.Mutex
+	timers  map[string]*mockTimer
+	calls   []SchedulerCall
+	panics  map[string]interface{}
+	latency map[string]time.Duration
+}
+
+var _ scheduler.Runner = (*MockScheduler)(nil)
+
+// NewMockScheduler создает новый мок планировщика
+func NewMockScheduler() *MockScheduler {
+	return &MockScheduler{
+		timers:  make(map[string]*mockTimer),
+		panics:  make(map[string]interface{}),
+		latency: make(map[string]time.Duration),
+	}
+}
+
+// AddTimer регистрирует таймер
+func (m *MockScheduler) AddTimer(name string, interval time.Duration, handler scheduler.Handler) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, SchedulerCall{Method: "AddTimer", Timer: name})
+
+	if _, exists := m.timers[name]; exists {
+		return fmt.Errorf("timer %s already exists", name)
+	}
+	m.timers[name] = &mockTimer{interval: interval, handler: handler}
+	return nil
+}
+
+// AddOneShot регистрирует однократный таймер: Tick выполняет его один раз,
+// когда наступило время at
+func (m *MockScheduler) AddOneShot(name string, at time.Time, handler scheduler.Handler) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, SchedulerCall{Method: "AddOneShot", Timer: name})
+
+	if _, exists := m.timers[name]; exists {
+		return fmt.Errorf("timer %s already exists", name)
+	}
+	m.timers[name] = &mockTimer{at: at, handler: handler}
+	return nil
+}
+
+// RemoveTimer удаляет таймер
+func (m *MockScheduler) RemoveTimer(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, SchedulerCall{Method: "RemoveTimer", Timer: name})
+
+	if _, ok := m.timers[name]; !ok {
+		return fmt.Errorf("%w: %s", scheduler.ErrTimerNotFound, name)
+	}
+	delete(m.timers, name)
+	return nil
+}
+
+// Trigger синхронно выполняет таймер, как Scheduler.Trigger
+func (m *MockScheduler) Trigger(name string) error {
+	m.record("Trigger", name)
+	return m.Fire(context.Background(), name)
+}
+
+// Execute выполняет обработчик с защитой от panic, как Scheduler.Execute
+func (m *MockScheduler) Execute(ctx context.Context, name string, handler scheduler.Handler) error {
+	m.record("Execute", name)
+	return m.run(ctx, name, handler)
+}
+
+// Pause приостанавливает таймер: Tick пропускает его
+func (m *MockScheduler) Pause(name string) error {
+	return m.setPaused("Pause", name, true)
+}
+
+// Resume возобновляет таймер
+func (m *MockScheduler) Resume(name string) error {
+	return m.setPaused("Resume", name, false)
+}
+
+// ListTimers возвращает состояние таймеров, отсортированное по имени.
+// Выполненные однократные таймеры не возвращаются
+func (m *MockScheduler) ListTimers() []scheduler.TimerInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	infos := make([]scheduler.TimerInfo, 0, len(m.timers))
+	for name, t := range m.timers {
+		if t.fired {
+			continue
+		}
+		state := scheduler.StateIdle
+		if t.paused {
+			state = scheduler.StatePaused
+		}
+		infos = append(infos, scheduler.TimerInfo{
+			Name:       name,
+			Interval:   t.interval,
+			LastRun:    t.lastRun,
+			NextRun:    t.at,
+			PanicCount: t.panics,
+			State:      state,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Fire синхронно выполняет обработчик таймера name с контекстом ctx.
+// Panic обработчика перехватывается и возвращается как *scheduler.PanicError
+func (m *MockScheduler) Fire(ctx context.Context, name string) error {
+	m.mu.Lock()
+	t, ok := m.timers[name]
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", scheduler.ErrTimerNotFound, name)
+	}
+	return m.run(ctx, name, t.handler)
+}
+
+// Tick выполняет все не приостановленные таймеры, как если бы у каждого
+// сработал тикер, и возвращает ошибки запусков по именам таймеров.
+// Однократный таймер выполняется один раз, когда наступило его время
+func (m *MockScheduler) Tick(ctx context.Context) map[string]error {
+	m.mu.Lock()
+	now := time.Now()
+	names := make([]string, 0, len(m.timers))
+	for name, t := range m.timers {
+		if t.paused || t.fired || t.at.After(now) {
+			continue
+		}
+		t.fired = !t.at.IsZero()
+		names = append(names, name)
+	}
+	m.mu.Unlock()
+	sort.Strings(names)
+
+	errs := make(map[string]error)
+	for _, name := range names {
+		if err := m.Fire(ctx, name); err != nil {
+			errs[name] = err
+		}
+	}
+	return errs
+}
+
+// SetPanic заставляет следующие запуски таймера name завершаться panic
+// со значением value вместо вызова обработчика. nil отменяет
+func (m *MockScheduler) SetPanic(name string, value interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if value == nil {
+		delete(m.panics, name)
+		return
+	}
+	m.panics[name] = value
+}
+
+// SetLatency добавляет задержку d перед вызовом обработчика таймера name.
+// Задержка прерывается отменой контекста запуска
+func (m *MockScheduler) SetLatency(name string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latency[name] = d
+}
+
+// Handler возвращает обработчик таймера name
+func (m *MockScheduler) Handler(name string) (scheduler.Handler, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.timers[name]
+	if !ok {
+		return nil, false
+	}
+	return t.handler, true
+}
+
+// Runs возвращает количество запусков таймера name
+func (m *MockScheduler) Runs(name string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if t, ok := m.timers[name]; ok {
+		return t.runs
+	}
+	return 0
+}
+
+// Calls возвращает все записанные вызовы методов
+func (m *MockScheduler) Calls() []SchedulerCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	calls := make([]SchedulerCall, len(m.calls))
+	copy(calls, m.calls)
+	return calls
+}
+
+// HasCall проверяет наличие вызова method для таймера name
+func (m *MockScheduler) HasCall(method, name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, call := range m.calls {
+		if call.Method == method && call.Timer == name {
+			return true
+		}
+	}
+	return false
+}
+
+// run выполняет обработчик с задержкой, принудительным panic и восстановлением
+func (m *MockScheduler) run(ctx context.Context, name string, handler scheduler.Handler) (err error) {
+	m.mu.Lock()
+	forced, mustPanic := m.panics[name]
+	delay := m.latency[name]
+	t := m.timers[name]
+	if t != nil {
+		t.runs++
+		t.lastRun = time.Now()
+	}
+	m.mu.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			if t != nil {
+				m.mu.Lock()
+				t.panics++
+				m.mu.Unlock()
+			}
+			err = &scheduler.PanicError{Timer: name, Value: r, Stack: string(debug.Stack())}
+		}
+	}()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+	if mustPanic {
+		panic(forced)
+	}
+	handler(ctx)
+	return nil
+}
+
+// record записывает вызов метода
+func (m *MockScheduler) record(method, name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, SchedulerCall{Method: method, Timer: name})
+}
+
+// setPaused меняет признак паузы таймера
+func (m *MockScheduler) setPaused(method, name string, paused bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, SchedulerCall{Method: method, Timer: name})
+
+	t, ok := m.timers[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", scheduler.ErrTimerNotFound, name)
+	}
+	t.paused = paused
+	return nil
+}